@@ -0,0 +1,153 @@
+package vfs_test
+
+import (
+	"bytes"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"github.com/mwantia/vfs"
+	"github.com/mwantia/vfs/data"
+	"github.com/mwantia/vfs/log"
+	"github.com/mwantia/vfs/mount/backend/sqlite"
+)
+
+// TestChunks_SQLite verifies offset reads, writes and truncation of content spanning multiple chunks.
+func TestChunks_SQLite(t *testing.T) {
+	ctx := t.Context()
+	storage, err := sqlite.NewSQLiteBackend(filepath.Join(t.TempDir(), "vfs.db"))
+	if err != nil {
+		t.Fatalf("Failed to create sqlite backend: %v", err)
+	}
+	if err := storage.Open(ctx); err != nil {
+		t.Fatalf("Failed to open sqlite backend: %v", err)
+	}
+	defer storage.Close(ctx)
+
+	if _, err := storage.CreateObject(ctx, "", "file", 0644); err != nil {
+		t.Fatalf("CreateObject failed: %v", err)
+	}
+
+	// Content larger than the previous limit of 5 MB, written in unaligned pieces
+	expected := make([]byte, 6*1024*1024+123)
+	for i := range expected {
+		expected[i] = byte(i % 251)
+	}
+	for offset := 0; offset < len(expected); offset += 1000003 {
+		end := min(offset+1000003, len(expected))
+		if _, err := storage.WriteObject(ctx, "", "file", int64(offset), expected[offset:end]); err != nil {
+			t.Fatalf("WriteObject at %d failed: %v", offset, err)
+		}
+	}
+
+	// Overwrite a range crossing a chunk boundary
+	patch := bytes.Repeat([]byte{0xff}, 4096)
+	copy(expected[262144-2048:], patch)
+	if _, err := storage.WriteObject(ctx, "", "file", 262144-2048, patch); err != nil {
+		t.Fatalf("WriteObject across chunk boundary failed: %v", err)
+	}
+
+	buffer := make([]byte, 700000)
+	for _, offset := range []int64{0, 262144 - 4096, 5 * 1024 * 1024, int64(len(expected)) - 100} {
+		n, err := storage.ReadObject(ctx, "", "file", offset, buffer)
+		if err != nil {
+			t.Fatalf("ReadObject at %d failed: %v", offset, err)
+		}
+		if !bytes.Equal(buffer[:n], expected[offset:offset+int64(n)]) || n != min(len(buffer), len(expected)-int(offset)) {
+			t.Errorf("ReadObject at %d returned unexpected content (%d bytes)", offset, n)
+		}
+	}
+
+	// Shrinking drops trailing content, so growing again reads zeros
+	if err := storage.TruncateObject(ctx, "", "file", 300000); err != nil {
+		t.Fatalf("Truncate failed: %v", err)
+	}
+	if err := storage.TruncateObject(ctx, "", "file", 600000); err != nil {
+		t.Fatalf("Truncate failed: %v", err)
+	}
+	n, err := storage.ReadObject(ctx, "", "file", 299990, buffer[:20])
+	if err != nil || n != 20 {
+		t.Fatalf("ReadObject after truncate failed (n=%d): %v", n, err)
+	}
+	if !bytes.Equal(buffer[:10], expected[299990:300000]) || !bytes.Equal(buffer[10:20], make([]byte, 10)) {
+		t.Errorf("Expected zeros after the truncated content, got %v", buffer[:20])
+	}
+
+	// Sparse writes beyond the end read as zeros in between
+	if _, err := storage.WriteObject(ctx, "", "file", 2000000, []byte("end")); err != nil {
+		t.Fatalf("Sparse WriteObject failed: %v", err)
+	}
+	if n, err := storage.ReadObject(ctx, "", "file", 1999998, buffer[:5]); err != nil || n != 5 || !bytes.Equal(buffer[:5], []byte{0, 0, 'e', 'n', 'd'}) {
+		t.Errorf("Unexpected sparse content %v (n=%d): %v", buffer[:5], n, err)
+	}
+}
+
+// TestChunks_SQLiteMigration verifies that content of databases storing each object as a single blob is migrated into chunks.
+func TestChunks_SQLiteMigration(t *testing.T) {
+	ctx := t.Context()
+	path := filepath.Join(t.TempDir(), "legacy.db")
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatalf("Failed to open legacy database: %v", err)
+	}
+
+	content := bytes.Repeat([]byte("legacy"), 100000)
+	statements := []string{
+		`CREATE TABLE vfs_metadata (
+			id TEXT PRIMARY KEY, namespace TEXT NOT NULL DEFAULT '', key TEXT NOT NULL, mode INTEGER NOT NULL,
+			size INTEGER NOT NULL DEFAULT 0, uid INTEGER, gid INTEGER, modify_time INTEGER NOT NULL,
+			access_time INTEGER NOT NULL, create_time INTEGER NOT NULL, content_type TEXT, etag TEXT, attributes TEXT,
+			UNIQUE(namespace, key)
+		)`,
+		`CREATE TABLE vfs_data (
+			id TEXT PRIMARY KEY, content BLOB NOT NULL, size INTEGER NOT NULL CHECK(size >= 0),
+			ref_count INTEGER NOT NULL DEFAULT 0, created_at INTEGER NOT NULL, last_accessed INTEGER NOT NULL
+		)`,
+		`INSERT INTO vfs_metadata (id, key, mode, size, modify_time, access_time, create_time) VALUES ('legacy-id', 'legacy.txt', 420, 600000, 0, 0, 0)`,
+	}
+	for _, statement := range statements {
+		if _, err := db.ExecContext(ctx, statement); err != nil {
+			t.Fatalf("Failed to create legacy schema: %v", err)
+		}
+	}
+	if _, err := db.ExecContext(ctx, `INSERT INTO vfs_data (id, content, size, ref_count, created_at, last_accessed) VALUES ('legacy-id', ?, ?, 1, 0, 0)`, content, len(content)); err != nil {
+		t.Fatalf("Failed to insert legacy content: %v", err)
+	}
+	db.Close()
+
+	storage, err := sqlite.NewSQLiteBackend(path)
+	if err != nil {
+		t.Fatalf("Failed to migrate legacy database: %v", err)
+	}
+
+	fs, err := vfs.NewVirtualFileSystem(vfs.WithLogLevel(log.Error))
+	if err != nil {
+		t.Fatalf("Failed to initialize vfs: %v", err)
+	}
+	defer fs.Shutdown(ctx)
+
+	if err := fs.Mount(ctx, "/", storage); err != nil {
+		t.Fatalf("Failed to mount /: %v", err)
+	}
+
+	result, err := fs.ReadFile(ctx, "/legacy.txt", 0, int64(len(content)))
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	if !bytes.Equal(result, content) {
+		t.Errorf("Expected %d bytes of migrated content, got %d", len(content), len(result))
+	}
+
+	if _, err := fs.WriteFile(ctx, "/legacy.txt", 300000, []byte("migrated")); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	if result, err := fs.ReadFile(ctx, "/legacy.txt", 299998, 10); err != nil || !bytes.Equal(result, []byte("cymigrated")) {
+		t.Errorf("Unexpected content after write: %q (%v)", result, err)
+	}
+
+	stat, err := fs.StatMetadata(ctx, "/legacy.txt")
+	if err != nil || stat.Size != int64(len(content)) || stat.Mode != data.FileMode(0644) {
+		t.Errorf("Unexpected metadata after migration: %+v (%v)", stat, err)
+	}
+}
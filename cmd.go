@@ -8,16 +8,20 @@ import (
 	"github.com/mwantia/vfs/cmd"
 )
 
-func (vfs *virtualFileSystemImpl) RegisterCommand(cmd cmd.Command) error {
-	if cmd == nil {
+func (vfs *virtualFileSystemImpl) RegisterCommand(c cmd.Command) error {
+	if c == nil {
 		return fmt.Errorf("command cannot be nil")
 	}
 
-	name := cmd.Name()
+	name := c.Name()
 	if name == "" {
 		return fmt.Errorf("command name cannot be empty")
 	}
 
+	if err := c.GetFlags().Validate(); err != nil {
+		return fmt.Errorf("invalid flags for command %s: %w", name, err)
+	}
+
 	vfs.mu.Lock()
 	defer vfs.mu.Unlock()
 
@@ -25,7 +29,7 @@ func (vfs *virtualFileSystemImpl) RegisterCommand(cmd cmd.Command) error {
 		return fmt.Errorf("command already registered: %s", name)
 	}
 
-	vfs.cmds[name] = cmd
+	vfs.cmds[name] = c
 	return nil
 }
 
@@ -41,20 +45,39 @@ func (vfs *virtualFileSystemImpl) UnregisterCommand(name string) (bool, error) {
 	return true, nil
 }
 
-func (vfs *virtualFileSystemImpl) Execute(ctx context.Context, writer io.Writer, args ...string) (int, error) {
-	if len(args) == 0 {
-		return 1, fmt.Errorf("no command specified")
-	}
+// GetCommand returns the registered command with the specified name.
+func (vfs *virtualFileSystemImpl) GetCommand(name string) (cmd.Command, bool) {
+	vfs.mu.RLock()
+	defer vfs.mu.RUnlock()
 
+	c, exists := vfs.cmds[name]
+	return c, exists
+}
+
+// ListCommands returns all registered commands.
+func (vfs *virtualFileSystemImpl) ListCommands() []cmd.Command {
 	vfs.mu.RLock()
 	defer vfs.mu.RUnlock()
 
+	cmds := make([]cmd.Command, 0, len(vfs.cmds))
+	for _, c := range vfs.cmds {
+		cmds = append(cmds, c)
+	}
+
+	return cmds
+}
+
+func (vfs *virtualFileSystemImpl) Execute(ctx context.Context, writer io.Writer, args ...string) (int, error) {
+	if len(args) == 0 {
+		return cmd.ExitUsage, fmt.Errorf("no command specified")
+	}
+
 	name := args[0]
 	raw := args[1:]
-
-	c, exists := vfs.cmds[name]
+	// The lock is only held for the lookup, since commands may call back into the vfs
+	c, exists := vfs.GetCommand(name)
 	if !exists {
-		return 0, fmt.Errorf("command not found: %s", name)
+		return cmd.ExitNotFound, fmt.Errorf("command not found: %s%s", name, vfs.suggestCommand(name))
 	}
 
 	flagSet := c.GetFlags()
@@ -64,11 +87,47 @@ func (vfs *virtualFileSystemImpl) Execute(ctx context.Context, writer io.Writer,
 		}
 	}
 
+	if cmd.WantsHelp(raw, flagSet) {
+		fmt.Fprint(writer, cmd.FormatUsage(c))
+		return cmd.ExitSuccess, nil
+	}
+
 	parser := cmd.NewParser(flagSet)
 	parsedArgs, err := parser.Parse(raw)
 	if err != nil {
-		return 1, fmt.Errorf("parse error: %w", err)
+		return cmd.ExitUsage, fmt.Errorf("parse error: %w", err)
 	}
 
 	return c.Execute(ctx, vfs, parsedArgs, writer)
 }
+
+// ExecuteScript runs all commands read from the reader line by line.
+// See cmd.Script for the supported syntax.
+func (vfs *virtualFileSystemImpl) ExecuteScript(ctx context.Context, reader io.Reader, writer io.Writer) (int, error) {
+	vfs.log.Debug("ExecuteScript: executing script")
+
+	code, err := cmd.NewScript(vfs.Execute).Run(ctx, reader, writer)
+	if err != nil {
+		vfs.log.Error("ExecuteScript: script failed with code %d - %v", code, err)
+		return code, err
+	}
+
+	vfs.log.Debug("ExecuteScript: script completed successfully")
+	return code, nil
+}
+
+// suggestCommand returns a formatted hint with registered commands similar to name.
+func (vfs *virtualFileSystemImpl) suggestCommand(name string) string {
+	cmds := vfs.ListCommands()
+	names := make([]string, 0, len(cmds))
+	for _, c := range cmds {
+		names = append(names, c.Name())
+	}
+
+	suggestions := cmd.Suggest(name, names)
+	if len(suggestions) == 0 {
+		return ""
+	}
+
+	return fmt.Sprintf(" (did you mean '%s'?)", suggestions[0])
+}
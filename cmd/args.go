@@ -1,5 +1,18 @@
 package cmd
 
+import (
+	"fmt"
+	"sort"
+)
+
+// Supported flag value types
+const (
+	FlagTypeString      = "string"
+	FlagTypeBool        = "bool"
+	FlagTypeInt         = "int"
+	FlagTypeStringSlice = "stringSlice"
+)
+
 // CommandArgs contains parsed command arguments
 type CommandArgs struct {
 	// Positional arguments (command-specific)
@@ -27,3 +40,90 @@ type CommandFlag struct {
 	Description string `json:"description"`       // Help text
 	Multiple    bool   `json:"multiple"`          // Can be specified multiple times
 }
+
+// Validate checks the flag set for definitions the parser cannot handle,
+// like unknown types, duplicate shorthands or defaults of the wrong type.
+func (fs *CommandFlagSet) Validate() error {
+	if fs == nil {
+		return nil
+	}
+
+	shorts := make(map[string]string)
+	for _, name := range fs.Names() {
+		flag := fs.Flags[name]
+		if flag == nil {
+			return fmt.Errorf("flag '%s' has no definition", name)
+		}
+		if flag.Name == "" {
+			return fmt.Errorf("flag '%s' has no name", name)
+		}
+
+		switch flag.Type {
+		case FlagTypeString, FlagTypeBool, FlagTypeInt, FlagTypeStringSlice:
+		default:
+			return fmt.Errorf("flag '%s' has unsupported type '%s'", flag.Name, flag.Type)
+		}
+
+		if flag.Short != "" {
+			if len([]rune(flag.Short)) != 1 {
+				return fmt.Errorf("flag '%s' has invalid shorthand '%s'", flag.Name, flag.Short)
+			}
+			if other, exists := shorts[flag.Short]; exists {
+				return fmt.Errorf("flags '%s' and '%s' share shorthand '-%s'", other, flag.Name, flag.Short)
+			}
+			shorts[flag.Short] = flag.Name
+		}
+
+		if flag.Default != nil && !matchesFlagType(flag.Default, flag) {
+			return fmt.Errorf("flag '%s' has default of type %T, expected %s", flag.Name, flag.Default, flag.Type)
+		}
+	}
+
+	return nil
+}
+
+// Names returns all flag names of this set in sorted order.
+func (fs *CommandFlagSet) Names() []string {
+	if fs == nil {
+		return nil
+	}
+
+	names := make([]string, 0, len(fs.Flags))
+	for name := range fs.Flags {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+// matchesFlagType reports whether value can be used as a value for the flag.
+// Flags that can be specified multiple times may also use a slice of their type.
+func matchesFlagType(value any, flag *CommandFlag) bool {
+	switch flag.Type {
+	case FlagTypeString:
+		switch value.(type) {
+		case string:
+			return true
+		case []string:
+			return flag.Multiple
+		}
+		return false
+	case FlagTypeBool:
+		_, ok := value.(bool)
+		return ok
+	case FlagTypeInt:
+		switch value.(type) {
+		case int, int64:
+			return true
+		case []int64:
+			return flag.Multiple
+		}
+		return false
+	case FlagTypeStringSlice:
+		_, ok := value.([]string)
+		return ok
+	default:
+		return false
+	}
+}
@@ -0,0 +1,160 @@
+package builtin
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/mwantia/vfs/cmd"
+	"github.com/mwantia/vfs/data"
+)
+
+type ChmodCommand struct {
+}
+
+// Name returns the command identifier
+func (c *ChmodCommand) Name() string {
+	return "chmod"
+}
+
+// Description returns human-readable help text
+func (c *ChmodCommand) Description() string {
+	return "Change file mode bits"
+}
+
+// Usage returns a usage string for help (e.g. "ls -al [path]")
+func (c *ChmodCommand) Usage() string {
+	return "chmod [OPTIONS] MODE PATH..."
+}
+
+// Execute runs the command with parsed arguments
+// Returns exit code (0 = success) and error message
+func (c *ChmodCommand) Execute(ctx context.Context, api cmd.API, args *cmd.CommandArgs, writer io.Writer) (int, error) {
+	if len(args.Args) < 2 {
+		return cmd.ExitUsage, fmt.Errorf("missing operand")
+	}
+
+	mode := args.Args[0]
+	// Validate the mode once, before any file has been modified
+	if _, err := parseMode(mode, 0); err != nil {
+		return cmd.ExitUsage, err
+	}
+
+	recursive := getBoolFlag(args, "recursive")
+	verbose := getBoolFlag(args, "verbose")
+
+	failed := 0
+	for _, path := range args.Args[1:] {
+		failed += walkPath(ctx, api, path, recursive, c.Name(), writer, func(path string, meta *data.Metadata) error {
+			perm, err := parseMode(mode, meta.Mode.Perm())
+			if err != nil {
+				return err
+			}
+
+			if err := api.Chmod(ctx, path, perm); err != nil {
+				return err
+			}
+
+			if verbose {
+				fmt.Fprintf(writer, "mode of '%s' changed from %04o to %04o\n", path, meta.Mode.Perm(), perm)
+			}
+			return nil
+		})
+	}
+
+	if failed > 0 {
+		return cmd.ExitFailure, fmt.Errorf("failed to change mode of %d path(s)", failed)
+	}
+
+	return cmd.ExitSuccess, nil
+}
+
+// parseMode parses an octal (e.g. "755") or symbolic (e.g. "u+x,go-w") mode relative to current
+func parseMode(mode string, current data.FileMode) (data.FileMode, error) {
+	if mode == "" {
+		return 0, fmt.Errorf("invalid mode: '%s'", mode)
+	}
+
+	if value, err := strconv.ParseUint(mode, 8, 32); err == nil {
+		if data.FileMode(value)&^data.ModePerm != 0 {
+			return 0, fmt.Errorf("invalid mode: '%s'", mode)
+		}
+		return data.FileMode(value), nil
+	}
+
+	result := current.Perm()
+	for clause := range strings.SplitSeq(mode, ",") {
+		op := strings.IndexAny(clause, "+-=")
+		if op < 0 {
+			return 0, fmt.Errorf("invalid mode: '%s'", mode)
+		}
+
+		var who data.FileMode
+		for _, r := range clause[:op] {
+			switch r {
+			case 'u':
+				who |= 0700
+			case 'g':
+				who |= 0070
+			case 'o':
+				who |= 0007
+			case 'a':
+				who |= 0777
+			default:
+				return 0, fmt.Errorf("invalid mode: '%s'", mode)
+			}
+		}
+		if who == 0 {
+			who = 0777
+		}
+
+		var perm data.FileMode
+		for _, r := range clause[op+1:] {
+			switch r {
+			case 'r':
+				perm |= 0444
+			case 'w':
+				perm |= 0222
+			case 'x':
+				perm |= 0111
+			default:
+				return 0, fmt.Errorf("invalid mode: '%s'", mode)
+			}
+		}
+
+		switch clause[op] {
+		case '+':
+			result |= who & perm
+		case '-':
+			result &^= who & perm
+		case '=':
+			result = (result &^ who) | (who & perm)
+		}
+	}
+
+	return result, nil
+}
+
+// GetFlags returns the flag set for this command
+func (c *ChmodCommand) GetFlags() *cmd.CommandFlagSet {
+	return &cmd.CommandFlagSet{
+		Flags: map[string]*cmd.CommandFlag{
+			"recursive": {
+				Name:        "recursive",
+				Short:       "R",
+				Type:        cmd.FlagTypeBool,
+				Default:     false,
+				Description: "change files and directories recursively",
+			},
+			"verbose": {
+				Name:        "verbose",
+				Short:       "v",
+				Type:        cmd.FlagTypeBool,
+				Default:     false,
+				Description: "output a diagnostic for every processed file",
+			},
+		},
+	}
+}
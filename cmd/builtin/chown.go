@@ -0,0 +1,114 @@
+package builtin
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/mwantia/vfs/cmd"
+	"github.com/mwantia/vfs/data"
+)
+
+type ChownCommand struct {
+}
+
+// Name returns the command identifier
+func (c *ChownCommand) Name() string {
+	return "chown"
+}
+
+// Description returns human-readable help text
+func (c *ChownCommand) Description() string {
+	return "Change file owner and group"
+}
+
+// Usage returns a usage string for help (e.g. "ls -al [path]")
+func (c *ChownCommand) Usage() string {
+	return "chown [OPTIONS] [OWNER][:[GROUP]] PATH..."
+}
+
+// Execute runs the command with parsed arguments
+// Returns exit code (0 = success) and error message
+func (c *ChownCommand) Execute(ctx context.Context, api cmd.API, args *cmd.CommandArgs, writer io.Writer) (int, error) {
+	if len(args.Args) < 2 {
+		return cmd.ExitUsage, fmt.Errorf("missing operand")
+	}
+
+	uid, gid, err := parseOwner(args.Args[0])
+	if err != nil {
+		return cmd.ExitUsage, err
+	}
+
+	recursive := getBoolFlag(args, "recursive")
+	verbose := getBoolFlag(args, "verbose")
+
+	failed := 0
+	for _, path := range args.Args[1:] {
+		failed += walkPath(ctx, api, path, recursive, c.Name(), writer, func(path string, meta *data.Metadata) error {
+			if err := api.Chown(ctx, path, uid, gid); err != nil {
+				return err
+			}
+
+			if verbose {
+				fmt.Fprintf(writer, "ownership of '%s' changed from %d:%d to %s\n", path, meta.UID, meta.GID, args.Args[0])
+			}
+			return nil
+		})
+	}
+
+	if failed > 0 {
+		return cmd.ExitFailure, fmt.Errorf("failed to change ownership of %d path(s)", failed)
+	}
+
+	return cmd.ExitSuccess, nil
+}
+
+// parseOwner parses numeric "UID", "UID:GID", "UID:" or ":GID" (-1 means unchanged)
+func parseOwner(owner string) (int64, int64, error) {
+	user, group, _ := strings.Cut(owner, ":")
+	if user == "" && group == "" {
+		return 0, 0, fmt.Errorf("invalid owner: '%s'", owner)
+	}
+
+	uid, gid := int64(-1), int64(-1)
+	if user != "" {
+		value, err := strconv.ParseInt(user, 10, 64)
+		if err != nil || value < 0 {
+			return 0, 0, fmt.Errorf("invalid user: '%s'", user)
+		}
+		uid = value
+	}
+	if group != "" {
+		value, err := strconv.ParseInt(group, 10, 64)
+		if err != nil || value < 0 {
+			return 0, 0, fmt.Errorf("invalid group: '%s'", group)
+		}
+		gid = value
+	}
+
+	return uid, gid, nil
+}
+
+// GetFlags returns the flag set for this command
+func (c *ChownCommand) GetFlags() *cmd.CommandFlagSet {
+	return &cmd.CommandFlagSet{
+		Flags: map[string]*cmd.CommandFlag{
+			"recursive": {
+				Name:        "recursive",
+				Short:       "R",
+				Type:        cmd.FlagTypeBool,
+				Default:     false,
+				Description: "change files and directories recursively",
+			},
+			"verbose": {
+				Name:        "verbose",
+				Short:       "v",
+				Type:        cmd.FlagTypeBool,
+				Default:     false,
+				Description: "output a diagnostic for every processed file",
+			},
+		},
+	}
+}
@@ -0,0 +1,106 @@
+package builtin
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path"
+
+	"github.com/mwantia/vfs/cmd"
+	"github.com/mwantia/vfs/data"
+)
+
+type CpCommand struct {
+}
+
+// Name returns the command identifier
+func (c *CpCommand) Name() string {
+	return "cp"
+}
+
+// Description returns human-readable help text
+func (c *CpCommand) Description() string {
+	return "Copy files and directories"
+}
+
+// Usage returns a usage string for help (e.g. "ls -al [path]")
+func (c *CpCommand) Usage() string {
+	return "cp [OPTIONS] SOURCE... DEST"
+}
+
+// Execute runs the command with parsed arguments
+// Returns exit code (0 = success) and error message
+func (c *CpCommand) Execute(ctx context.Context, api cmd.API, args *cmd.CommandArgs, writer io.Writer) (int, error) {
+	if len(args.Args) < 2 {
+		return cmd.ExitUsage, fmt.Errorf("missing operand")
+	}
+
+	preserve := getBoolFlag(args, "preserve")
+	opts := &data.CopyOptions{
+		Recursive:           getBoolFlag(args, "recursive"),
+		Overwrite:           getBoolFlag(args, "force"),
+		PreserveMode:        preserve,
+		PreserveAttributes:  preserve,
+		PreserveContentType: preserve,
+	}
+
+	sources := args.Args[:len(args.Args)-1]
+	dest := args.Args[len(args.Args)-1]
+
+	// Copy into the destination, if it's an existing directory
+	isDir := false
+	if meta, err := api.StatMetadata(ctx, dest); err == nil {
+		isDir = meta.Mode.IsDir()
+	}
+	if len(sources) > 1 && !isDir {
+		return cmd.ExitFailure, fmt.Errorf("target '%s' is not a directory", dest)
+	}
+
+	failed := 0
+	for _, source := range sources {
+		target := dest
+		if isDir {
+			target = path.Join(dest, path.Base(source))
+		}
+
+		if err := api.Copy(ctx, source, target, opts); err != nil {
+			fmt.Fprintf(writer, "cp: cannot copy '%s' to '%s': %v\n", source, target, err)
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return cmd.ExitFailure, fmt.Errorf("failed to copy %d source(s)", failed)
+	}
+
+	return cmd.ExitSuccess, nil
+}
+
+// GetFlags returns the flag set for this command
+func (c *CpCommand) GetFlags() *cmd.CommandFlagSet {
+	return &cmd.CommandFlagSet{
+		Flags: map[string]*cmd.CommandFlag{
+			"recursive": {
+				Name:        "recursive",
+				Short:       "r",
+				Type:        cmd.FlagTypeBool,
+				Default:     false,
+				Description: "copy directories recursively",
+			},
+			"force": {
+				Name:        "force",
+				Short:       "f",
+				Type:        cmd.FlagTypeBool,
+				Default:     false,
+				Description: "overwrite existing destination files",
+			},
+			"preserve": {
+				Name:        "preserve",
+				Short:       "p",
+				Type:        cmd.FlagTypeBool,
+				Default:     false,
+				Description: "preserve mode, attributes and content type",
+			},
+		},
+	}
+}
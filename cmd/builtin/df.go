@@ -0,0 +1,138 @@
+package builtin
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+
+	"github.com/mwantia/vfs/cmd"
+	"github.com/mwantia/vfs/data"
+)
+
+type DfCommand struct {
+}
+
+// Name returns the command identifier
+func (d *DfCommand) Name() string {
+	return "df"
+}
+
+// Description returns human-readable help text
+func (d *DfCommand) Description() string {
+	return "Report storage usage and quotas of mounts"
+}
+
+// Usage returns a usage string for help (e.g. "ls -al [path]")
+func (d *DfCommand) Usage() string {
+	return "df [OPTIONS] [PATH]..."
+}
+
+// Execute runs the command with parsed arguments
+// Returns exit code (0 = success) and error message
+func (d *DfCommand) Execute(ctx context.Context, api cmd.API, args *cmd.CommandArgs, writer io.Writer) (int, error) {
+	humanReadable := getBoolFlag(args, "human-readable")
+	inodes := getBoolFlag(args, "inodes")
+	users := getBoolFlag(args, "users")
+
+	paths := args.Args
+	if len(paths) == 0 {
+		for _, entry := range api.DumpMountTable().Mounts {
+			paths = append(paths, entry.Path)
+		}
+	}
+
+	header := "Size"
+	if inodes {
+		header = "Inodes"
+	}
+	fmt.Fprintf(writer, "%-24s %10s %10s %10s %5s\n", "Mounted on", "Used", header, "Avail", "Use%")
+
+	failed := 0
+	for _, path := range paths {
+		usage, err := api.Usage(ctx, path)
+		if err != nil {
+			fmt.Fprintf(writer, "df: %s: %v\n", path, err)
+			failed++
+			continue
+		}
+
+		d.printUsage(writer, usage.Path, &usage.Mount, inodes, humanReadable)
+		if usage.NamespaceUsage != nil {
+			d.printUsage(writer, "  namespace="+usage.Namespace, usage.NamespaceUsage, inodes, humanReadable)
+		}
+		if users {
+			for _, uid := range slices.Sorted(maps.Keys(usage.Users)) {
+				d.printUsage(writer, fmt.Sprintf("  uid=%d", uid), usage.Users[uid], inodes, humanReadable)
+			}
+		}
+		if usage.Dedup != nil {
+			fmt.Fprintf(writer, "  dedup ratio %.2f (%s stored for %s in %d block(s))\n", usage.Dedup.Ratio(),
+				formatSize(usage.Dedup.PhysicalBytes, humanReadable), formatSize(usage.Dedup.LogicalBytes, humanReadable), usage.Dedup.Blocks)
+		}
+		if usage.Compression != nil {
+			fmt.Fprintf(writer, "  compression ratio %.2f (%s stored for %s, %d of %d file(s) compressed)\n", usage.Compression.Ratio(),
+				formatSize(usage.Compression.PhysicalBytes, humanReadable), formatSize(usage.Compression.LogicalBytes, humanReadable),
+				usage.Compression.Compressed, usage.Compression.Objects)
+		}
+	}
+
+	if failed > 0 {
+		return cmd.ExitFailure, fmt.Errorf("failed to report usage of %d path(s)", failed)
+	}
+
+	return cmd.ExitSuccess, nil
+}
+
+// printUsage writes a single row in the format "NAME USED LIMIT AVAIL USE%".
+// Unlimited quotas are printed as "-".
+func (d *DfCommand) printUsage(writer io.Writer, name string, usage *data.QuotaUsage, inodes, humanReadable bool) {
+	used, limit, avail := usage.Bytes, usage.Quota.MaxBytes, usage.AvailableBytes()
+	format := func(value int64) string {
+		return formatSize(value, humanReadable)
+	}
+	if inodes {
+		used, limit, avail = usage.Objects, usage.Quota.MaxObjects, usage.AvailableObjects()
+		format = func(value int64) string {
+			return fmt.Sprintf("%d", value)
+		}
+	}
+
+	if limit <= 0 {
+		fmt.Fprintf(writer, "%-24s %10s %10s %10s %5s\n", name, format(used), "-", "-", "-")
+		return
+	}
+
+	percent := used * 100 / limit
+	fmt.Fprintf(writer, "%-24s %10s %10s %10s %4d%%\n", name, format(used), format(limit), format(avail), percent)
+}
+
+// GetFlags returns the flag set for this command
+func (d *DfCommand) GetFlags() *cmd.CommandFlagSet {
+	return &cmd.CommandFlagSet{
+		Flags: map[string]*cmd.CommandFlag{
+			"human-readable": {
+				Name:        "human-readable",
+				Short:       "h",
+				Type:        cmd.FlagTypeBool,
+				Default:     false,
+				Description: "print sizes in human readable format (e.g., 1K, 234M, 2G)",
+			},
+			"inodes": {
+				Name:        "inodes",
+				Short:       "i",
+				Type:        cmd.FlagTypeBool,
+				Default:     false,
+				Description: "report the number of objects instead of bytes",
+			},
+			"users": {
+				Name:        "users",
+				Short:       "u",
+				Type:        cmd.FlagTypeBool,
+				Default:     false,
+				Description: "also report the usage of each owning UID",
+			},
+		},
+	}
+}
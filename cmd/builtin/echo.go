@@ -0,0 +1,58 @@
+package builtin
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/mwantia/vfs/cmd"
+)
+
+type EchoCommand struct {
+}
+
+// Name returns the command identifier
+func (e *EchoCommand) Name() string {
+	return "echo"
+}
+
+// Description returns human-readable help text
+func (e *EchoCommand) Description() string {
+	return "Display a line of text"
+}
+
+// Usage returns a usage string for help (e.g. "ls -al [path]")
+func (e *EchoCommand) Usage() string {
+	return "echo [OPTIONS] [STRING...]"
+}
+
+// Execute runs the command with parsed arguments
+// Returns exit code (0 = success) and error message
+func (e *EchoCommand) Execute(ctx context.Context, api cmd.API, args *cmd.CommandArgs, writer io.Writer) (int, error) {
+	text := strings.Join(args.Args, " ")
+	if !getBoolFlag(args, "no-newline") {
+		text += "\n"
+	}
+
+	if _, err := fmt.Fprint(writer, text); err != nil {
+		return cmd.ExitFailure, err
+	}
+
+	return cmd.ExitSuccess, nil
+}
+
+// GetFlags returns the flag set for this command
+func (e *EchoCommand) GetFlags() *cmd.CommandFlagSet {
+	return &cmd.CommandFlagSet{
+		Flags: map[string]*cmd.CommandFlag{
+			"no-newline": {
+				Name:        "no-newline",
+				Short:       "n",
+				Type:        cmd.FlagTypeBool,
+				Default:     false,
+				Description: "do not output the trailing newline",
+			},
+		},
+	}
+}
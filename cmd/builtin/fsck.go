@@ -0,0 +1,99 @@
+package builtin
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/mwantia/vfs/cmd"
+	"github.com/mwantia/vfs/data"
+)
+
+type FsckCommand struct {
+}
+
+// Name returns the command identifier
+func (f *FsckCommand) Name() string {
+	return "fsck"
+}
+
+// Description returns human-readable help text
+func (f *FsckCommand) Description() string {
+	return "Check and repair consistency between metadata and object storage"
+}
+
+// Usage returns a usage string for help (e.g. "ls -al [path]")
+func (f *FsckCommand) Usage() string {
+	return "fsck [OPTIONS] [PATH]..."
+}
+
+// Execute runs the command with parsed arguments
+// Returns exit code (0 = success) and error message
+func (f *FsckCommand) Execute(ctx context.Context, api cmd.API, args *cmd.CommandArgs, writer io.Writer) (int, error) {
+	opts := &data.FsckOptions{
+		Repair: getBoolFlag(args, "repair"),
+		Prune:  getBoolFlag(args, "prune"),
+	}
+
+	paths := args.Args
+	if len(paths) == 0 {
+		for _, entry := range api.DumpMountTable().Mounts {
+			paths = append(paths, entry.Path)
+		}
+	}
+
+	failed, unrepaired := 0, 0
+	for _, path := range paths {
+		report, err := api.Fsck(ctx, path, opts)
+		if err != nil {
+			fmt.Fprintf(writer, "fsck: %s: %v\n", path, err)
+			failed++
+			continue
+		}
+
+		for _, issue := range report.Issues {
+			fmt.Fprintf(writer, "  %-16s %s", issue.Type, issue.Key)
+			if issue.Metadata != "" || issue.Storage != "" {
+				fmt.Fprintf(writer, " (metadata=%s storage=%s)", issue.Metadata, issue.Storage)
+			}
+			if issue.Repaired {
+				fmt.Fprint(writer, " [repaired]")
+			}
+			fmt.Fprintln(writer)
+		}
+
+		fmt.Fprintf(writer, "%s: checked %d, %d issue(s), %d repaired\n", report.Path, report.Checked, len(report.Issues), report.Repaired)
+		unrepaired += len(report.Issues) - int(report.Repaired)
+	}
+
+	if failed > 0 {
+		return cmd.ExitFailure, fmt.Errorf("failed to check %d path(s)", failed)
+	}
+	if unrepaired > 0 {
+		return cmd.ExitFailure, fmt.Errorf("%d issue(s) remain unrepaired", unrepaired)
+	}
+
+	return cmd.ExitSuccess, nil
+}
+
+// GetFlags returns the flag set for this command
+func (f *FsckCommand) GetFlags() *cmd.CommandFlagSet {
+	return &cmd.CommandFlagSet{
+		Flags: map[string]*cmd.CommandFlag{
+			"repair": {
+				Name:        "repair",
+				Short:       "r",
+				Type:        cmd.FlagTypeBool,
+				Default:     false,
+				Description: "rebuild missing or drifted metadata from object storage",
+			},
+			"prune": {
+				Name:        "prune",
+				Short:       "p",
+				Type:        cmd.FlagTypeBool,
+				Default:     false,
+				Description: "remove metadata of entries no longer present in object storage",
+			},
+		},
+	}
+}
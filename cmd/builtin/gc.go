@@ -0,0 +1,148 @@
+package builtin
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/mwantia/vfs/cmd"
+	"github.com/mwantia/vfs/data"
+)
+
+type GcCommand struct {
+}
+
+// Name returns the command identifier
+func (g *GcCommand) Name() string {
+	return "gc"
+}
+
+// Description returns human-readable help text
+func (g *GcCommand) Description() string {
+	return "Remove content no longer referenced by any file"
+}
+
+// Usage returns a usage string for help (e.g. "ls -al [path]")
+func (g *GcCommand) Usage() string {
+	return "gc [OPTIONS] [PATH]..."
+}
+
+// Execute runs the command with parsed arguments
+// Returns exit code (0 = success) and error message
+func (g *GcCommand) Execute(ctx context.Context, api cmd.API, args *cmd.CommandArgs, writer io.Writer) (int, error) {
+	humanReadable := getBoolFlag(args, "human-readable")
+	verbose := getBoolFlag(args, "verbose")
+
+	// Orphans are only removed immediately with an explicit --grace 0
+	value := getStringFlag(args, "grace", data.DefaultGCGracePeriod.String())
+	grace, err := time.ParseDuration(value)
+	if err != nil || grace < 0 {
+		return cmd.ExitUsage, fmt.Errorf("invalid grace period: %s", value)
+	}
+
+	opts := &data.GCOptions{
+		DryRun:       getBoolFlag(args, "dry-run"),
+		GracePeriod:  grace,
+		Immediate:    grace == 0,
+		Vacuum:       getBoolFlag(args, "vacuum"),
+		SweepObjects: getBoolFlag(args, "objects"),
+	}
+
+	paths := args.Args
+	if len(paths) == 0 {
+		for _, entry := range api.DumpMountTable().Mounts {
+			if !entry.ReadOnly || opts.DryRun {
+				paths = append(paths, entry.Path)
+			}
+		}
+	}
+
+	action := "removed"
+	if opts.DryRun {
+		action = "would remove"
+	}
+
+	failed := 0
+	for _, path := range paths {
+		report, err := api.CollectGarbage(ctx, path, opts)
+		if err != nil {
+			fmt.Fprintf(writer, "gc: %s: %v\n", path, err)
+			failed++
+			continue
+		}
+
+		if verbose {
+			for _, key := range report.Keys {
+				fmt.Fprintf(writer, "  %s\n", key)
+			}
+		}
+
+		removed := report.Removed
+		if opts.DryRun {
+			removed = report.Orphans - report.Deferred
+		}
+		fmt.Fprintf(writer, "%s: scanned %d, %s %d orphan(s) using %s", report.Path, report.Scanned, action, removed, formatSize(report.Bytes, humanReadable))
+		if report.Deferred > 0 {
+			fmt.Fprintf(writer, ", %d deferred by grace period", report.Deferred)
+		}
+		if report.Vacuumed {
+			fmt.Fprint(writer, ", vacuumed")
+		}
+		fmt.Fprintln(writer)
+	}
+
+	if failed > 0 {
+		return cmd.ExitFailure, fmt.Errorf("failed to collect garbage of %d path(s)", failed)
+	}
+
+	return cmd.ExitSuccess, nil
+}
+
+// GetFlags returns the flag set for this command
+func (g *GcCommand) GetFlags() *cmd.CommandFlagSet {
+	return &cmd.CommandFlagSet{
+		Flags: map[string]*cmd.CommandFlag{
+			"dry-run": {
+				Name:        "dry-run",
+				Short:       "n",
+				Type:        cmd.FlagTypeBool,
+				Default:     false,
+				Description: "only report orphaned content without removing it",
+			},
+			"grace": {
+				Name:        "grace",
+				Short:       "g",
+				Type:        cmd.FlagTypeString,
+				Default:     data.DefaultGCGracePeriod.String(),
+				Description: "keep orphans modified within this duration, 0 removes them immediately (e.g., 10m, 1h)",
+			},
+			"vacuum": {
+				Name:        "vacuum",
+				Type:        cmd.FlagTypeBool,
+				Default:     false,
+				Description: "compact the underlying storage afterwards (e.g., SQLite VACUUM)",
+			},
+			"objects": {
+				Name:        "objects",
+				Type:        cmd.FlagTypeBool,
+				Default:     false,
+				Description: "also remove objects without metadata on mounts with a separate metadata backend",
+			},
+			"human-readable": {
+				Name:        "human-readable",
+				Short:       "h",
+				Type:        cmd.FlagTypeBool,
+				Default:     false,
+				Description: "print sizes in human readable format (e.g., 1K, 234M, 2G)",
+			},
+			"verbose": {
+				Name:        "verbose",
+				Short:       "v",
+				Type:        cmd.FlagTypeBool,
+				Default:     false,
+				Description: "list every orphan found",
+			},
+		},
+	}
+}
@@ -0,0 +1,94 @@
+package builtin
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/mwantia/vfs/cmd"
+	"github.com/mwantia/vfs/data"
+)
+
+type GetattrCommand struct {
+}
+
+// Name returns the command identifier
+func (g *GetattrCommand) Name() string {
+	return "getattr"
+}
+
+// Description returns human-readable help text
+func (g *GetattrCommand) Description() string {
+	return "Display extended attributes of files"
+}
+
+// Usage returns a usage string for help (e.g. "ls -al [path]")
+func (g *GetattrCommand) Usage() string {
+	return "getattr [OPTIONS] PATH..."
+}
+
+// Execute runs the command with parsed arguments
+// Returns exit code (0 = success) and error message
+func (g *GetattrCommand) Execute(ctx context.Context, api cmd.API, args *cmd.CommandArgs, writer io.Writer) (int, error) {
+	if len(args.Args) == 0 {
+		return cmd.ExitUsage, fmt.Errorf("missing operand")
+	}
+
+	name := getStringFlag(args, "name", "")
+	recursive := getBoolFlag(args, "recursive")
+
+	failed := 0
+	for _, path := range args.Args {
+		failed += walkPath(ctx, api, path, recursive, g.Name(), writer, func(path string, meta *data.Metadata) error {
+			if name != "" {
+				if !meta.HasAttribute(name) {
+					return fmt.Errorf("no such attribute '%s'", name)
+				}
+
+				fmt.Fprintf(writer, "# file: %s\n%s=%s\n\n", path, name, meta.Attributes[name])
+				return nil
+			}
+
+			fmt.Fprintf(writer, "# file: %s\n", path)
+			keys := make([]string, 0, len(meta.Attributes))
+			for key := range meta.Attributes {
+				keys = append(keys, key)
+			}
+			slices.Sort(keys)
+
+			for _, key := range keys {
+				fmt.Fprintf(writer, "%s=%s\n", key, meta.Attributes[key])
+			}
+			fmt.Fprintln(writer)
+			return nil
+		})
+	}
+
+	if failed > 0 {
+		return cmd.ExitFailure, fmt.Errorf("failed to read attributes of %d path(s)", failed)
+	}
+
+	return cmd.ExitSuccess, nil
+}
+
+// GetFlags returns the flag set for this command
+func (g *GetattrCommand) GetFlags() *cmd.CommandFlagSet {
+	return &cmd.CommandFlagSet{
+		Flags: map[string]*cmd.CommandFlag{
+			"name": {
+				Name:        "name",
+				Short:       "n",
+				Type:        cmd.FlagTypeString,
+				Description: "only display the attribute with this name",
+			},
+			"recursive": {
+				Name:        "recursive",
+				Short:       "R",
+				Type:        cmd.FlagTypeBool,
+				Default:     false,
+				Description: "display attributes of directories recursively",
+			},
+		},
+	}
+}
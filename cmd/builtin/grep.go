@@ -0,0 +1,166 @@
+package builtin
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+
+	"github.com/mwantia/vfs/cmd"
+	"github.com/mwantia/vfs/data"
+)
+
+// maxLineSize limits the size of a single line scanned by grep
+const maxLineSize = 1024 * 1024
+
+type GrepCommand struct {
+}
+
+// grepOptions contains the parsed flags of a grep execution
+type grepOptions struct {
+	pattern     *regexp.Regexp
+	listFiles   bool
+	lineNumbers bool
+	showNames   bool
+}
+
+// Name returns the command identifier
+func (g *GrepCommand) Name() string {
+	return "grep"
+}
+
+// Description returns human-readable help text
+func (g *GrepCommand) Description() string {
+	return "Print lines that match a regular expression"
+}
+
+// Usage returns a usage string for help (e.g. "ls -al [path]")
+func (g *GrepCommand) Usage() string {
+	return "grep [OPTIONS] PATTERN FILE..."
+}
+
+// Execute runs the command with parsed arguments
+// Returns exit code (0 = match found, 1 = no match) and error message
+func (g *GrepCommand) Execute(ctx context.Context, api cmd.API, args *cmd.CommandArgs, writer io.Writer) (int, error) {
+	if len(args.Args) < 2 {
+		return cmd.ExitUsage, fmt.Errorf("missing pattern or file operand")
+	}
+
+	expr := args.Args[0]
+	if getBoolFlag(args, "ignore-case") {
+		expr = "(?i)" + expr
+	}
+
+	pattern, err := regexp.Compile(expr)
+	if err != nil {
+		return cmd.ExitUsage, fmt.Errorf("invalid pattern: %w", err)
+	}
+
+	recursive := getBoolFlag(args, "recursive")
+	opts := &grepOptions{
+		pattern:     pattern,
+		listFiles:   getBoolFlag(args, "files-with-matches"),
+		lineNumbers: getBoolFlag(args, "line-number"),
+		showNames:   recursive || len(args.Args) > 2,
+	}
+
+	matched := false
+	failed := 0
+	for _, path := range args.Args[1:] {
+		failed += walkPath(ctx, api, path, recursive, g.Name(), writer, func(path string, meta *data.Metadata) error {
+			if meta.Mode.IsDir() {
+				if recursive {
+					return nil
+				}
+				return data.ErrIsDirectory
+			}
+			if !meta.Mode.IsRegular() {
+				return nil
+			}
+
+			found, err := g.grep(ctx, api, path, opts, writer)
+			matched = matched || found
+			return err
+		})
+	}
+
+	if failed > 0 {
+		return cmd.ExitUsage, fmt.Errorf("failed to search %d path(s)", failed)
+	}
+	if !matched {
+		return cmd.ExitFailure, nil
+	}
+
+	return cmd.ExitSuccess, nil
+}
+
+// grep scans a single file line by line and writes all matching lines
+func (g *GrepCommand) grep(ctx context.Context, api cmd.API, path string, opts *grepOptions, writer io.Writer) (bool, error) {
+	file, err := openFileReader(ctx, api, path)
+	if err != nil {
+		return false, err
+	}
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, readChunkSize), maxLineSize)
+
+	matched := false
+	for line := 1; scanner.Scan(); line++ {
+		if !opts.pattern.Match(scanner.Bytes()) {
+			continue
+		}
+
+		matched = true
+		if opts.listFiles {
+			fmt.Fprintln(writer, path)
+			return true, nil
+		}
+
+		if opts.showNames {
+			fmt.Fprintf(writer, "%s:", path)
+		}
+		if opts.lineNumbers {
+			fmt.Fprintf(writer, "%d:", line)
+		}
+		fmt.Fprintf(writer, "%s\n", scanner.Bytes())
+	}
+
+	return matched, scanner.Err()
+}
+
+// GetFlags returns the flag set for this command
+func (g *GrepCommand) GetFlags() *cmd.CommandFlagSet {
+	return &cmd.CommandFlagSet{
+		Flags: map[string]*cmd.CommandFlag{
+			"recursive": {
+				Name:        "recursive",
+				Short:       "r",
+				Type:        cmd.FlagTypeBool,
+				Default:     false,
+				Description: "search directories recursively",
+			},
+			"ignore-case": {
+				Name:        "ignore-case",
+				Short:       "i",
+				Type:        cmd.FlagTypeBool,
+				Default:     false,
+				Description: "ignore case distinctions in patterns and data",
+			},
+			"files-with-matches": {
+				Name:        "files-with-matches",
+				Short:       "l",
+				Type:        cmd.FlagTypeBool,
+				Default:     false,
+				Description: "print only names of files with matches",
+			},
+			"line-number": {
+				Name:        "line-number",
+				Short:       "n",
+				Type:        cmd.FlagTypeBool,
+				Default:     false,
+				Description: "prefix each line of output with its line number",
+			},
+		},
+	}
+}
@@ -0,0 +1,120 @@
+package builtin
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/mwantia/vfs/cmd"
+)
+
+type HeadCommand struct {
+}
+
+// Name returns the command identifier
+func (h *HeadCommand) Name() string {
+	return "head"
+}
+
+// Description returns human-readable help text
+func (h *HeadCommand) Description() string {
+	return "Output the first part of files"
+}
+
+// Usage returns a usage string for help (e.g. "ls -al [path]")
+func (h *HeadCommand) Usage() string {
+	return "head [OPTIONS] FILE..."
+}
+
+// Execute runs the command with parsed arguments
+// Returns exit code (0 = success) and error message
+func (h *HeadCommand) Execute(ctx context.Context, api cmd.API, args *cmd.CommandArgs, writer io.Writer) (int, error) {
+	if len(args.Args) == 0 {
+		return cmd.ExitUsage, fmt.Errorf("missing file operand")
+	}
+
+	lines := getIntFlag(args, "lines", 10)
+	bytes := getIntFlag(args, "bytes", -1)
+	if lines < 0 {
+		return cmd.ExitUsage, fmt.Errorf("invalid number of lines: %d", lines)
+	}
+	if _, exists := args.Flags["bytes"]; exists && bytes < 0 {
+		return cmd.ExitUsage, fmt.Errorf("invalid number of bytes: %d", bytes)
+	}
+
+	failed := 0
+	for i, path := range args.Args {
+		if len(args.Args) > 1 {
+			if i > 0 {
+				fmt.Fprintln(writer)
+			}
+			fmt.Fprintf(writer, "==> %s <==\n", path)
+		}
+
+		if err := h.head(ctx, api, path, lines, bytes, writer); err != nil {
+			fmt.Fprintf(writer, "head: %s: %v\n", path, err)
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return cmd.ExitFailure, fmt.Errorf("failed to read %d file(s)", failed)
+	}
+
+	return cmd.ExitSuccess, nil
+}
+
+// head writes either the first bytes (if set) or the first lines of path
+func (h *HeadCommand) head(ctx context.Context, api cmd.API, path string, lines, bytes int64, writer io.Writer) error {
+	file, err := openFileReader(ctx, api, path)
+	if err != nil {
+		return err
+	}
+
+	if bytes >= 0 {
+		if _, err := io.CopyN(writer, file, bytes); err != nil && err != io.EOF {
+			return err
+		}
+		return nil
+	}
+
+	reader := bufio.NewReaderSize(file, readChunkSize)
+	for range lines {
+		line, err := reader.ReadString('\n')
+		if len(line) > 0 {
+			if _, err := io.WriteString(writer, line); err != nil {
+				return err
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetFlags returns the flag set for this command
+func (h *HeadCommand) GetFlags() *cmd.CommandFlagSet {
+	return &cmd.CommandFlagSet{
+		Flags: map[string]*cmd.CommandFlag{
+			"lines": {
+				Name:        "lines",
+				Short:       "n",
+				Type:        cmd.FlagTypeInt,
+				Default:     int64(10),
+				Description: "print the first NUM lines",
+			},
+			"bytes": {
+				Name:        "bytes",
+				Short:       "c",
+				Type:        cmd.FlagTypeInt,
+				Description: "print the first NUM bytes instead of lines",
+			},
+		},
+	}
+}
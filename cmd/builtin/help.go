@@ -0,0 +1,64 @@
+package builtin
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/mwantia/vfs/cmd"
+)
+
+type HelpCommand struct {
+}
+
+// Name returns the command identifier
+func (h *HelpCommand) Name() string {
+	return "help"
+}
+
+// Description returns human-readable help text
+func (h *HelpCommand) Description() string {
+	return "Display information about builtin commands"
+}
+
+// Usage returns a usage string for help (e.g. "ls -al [path]")
+func (h *HelpCommand) Usage() string {
+	return "help [COMMAND]"
+}
+
+// Execute runs the command with parsed arguments
+// Returns exit code (0 = success) and error message
+func (h *HelpCommand) Execute(ctx context.Context, api cmd.API, args *cmd.CommandArgs, writer io.Writer) (int, error) {
+	if len(args.Args) == 0 {
+		fmt.Fprint(writer, cmd.FormatCommandList(api.ListCommands()))
+		return cmd.ExitSuccess, nil
+	}
+
+	if len(args.Args) > 1 {
+		return cmd.ExitUsage, fmt.Errorf("too many arguments")
+	}
+
+	name := args.Args[0]
+	c, exists := api.GetCommand(name)
+	if !exists {
+		names := make([]string, 0)
+		for _, other := range api.ListCommands() {
+			names = append(names, other.Name())
+		}
+
+		if suggestions := cmd.Suggest(name, names); len(suggestions) > 0 {
+			return cmd.ExitNotFound, fmt.Errorf("no help topic for '%s' (did you mean '%s'?)", name, suggestions[0])
+		}
+		return cmd.ExitNotFound, fmt.Errorf("no help topic for '%s'", name)
+	}
+
+	fmt.Fprint(writer, cmd.FormatUsage(c))
+	return cmd.ExitSuccess, nil
+}
+
+// GetFlags returns the flag set for this command
+func (h *HelpCommand) GetFlags() *cmd.CommandFlagSet {
+	return &cmd.CommandFlagSet{
+		Flags: map[string]*cmd.CommandFlag{},
+	}
+}
@@ -0,0 +1,131 @@
+package builtin
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path"
+
+	"github.com/mwantia/vfs/cmd"
+	"github.com/mwantia/vfs/data"
+)
+
+// readChunkSize defines the maximum amount of bytes requested per read from the api
+const readChunkSize = 32 * 1024
+
+// newFileReader creates a reader for path, which reads from offset until size is reached.
+// It doesn't depend on opened streamers, so offsets of other file handles stay untouched.
+func newFileReader(ctx context.Context, api cmd.API, path string, offset, size int64) *data.RangeReader {
+	return data.NewRangeReader(ctx, func(ctx context.Context, offset int64, p []byte) (int, error) {
+		buffer, err := api.ReadFile(ctx, path, offset, min(int64(len(p)), readChunkSize))
+		return copy(p, buffer), err
+	}, offset, size)
+}
+
+// openFileReader returns a reader for the current content of the file at path.
+// Streamers are shared per path, so files are never opened to avoid interfering with other handles.
+func openFileReader(ctx context.Context, api cmd.API, path string) (*data.RangeReader, error) {
+	meta, err := api.StatMetadata(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	if meta.Mode.IsDir() {
+		return nil, data.ErrIsDirectory
+	}
+
+	return newFileReader(ctx, api, path, 0, meta.Size), nil
+}
+
+// getStringFlag safely retrieves a string flag value
+func getStringFlag(args *cmd.CommandArgs, name string, defaultValue string) string {
+	if args.Flags == nil {
+		return defaultValue
+	}
+	if val, ok := args.Flags[name]; ok {
+		if strVal, ok := val.(string); ok {
+			return strVal
+		}
+	}
+	return defaultValue
+}
+
+// getIntFlag safely retrieves an integer flag value
+func getIntFlag(args *cmd.CommandArgs, name string, defaultValue int64) int64 {
+	if args.Flags == nil {
+		return defaultValue
+	}
+	if val, ok := args.Flags[name]; ok {
+		switch v := val.(type) {
+		case int64:
+			return v
+		case int:
+			return int64(v)
+		}
+	}
+	return defaultValue
+}
+
+// getStringSliceFlag safely retrieves a repeatable string flag value
+func getStringSliceFlag(args *cmd.CommandArgs, name string) []string {
+	if args.Flags == nil {
+		return nil
+	}
+	if val, ok := args.Flags[name]; ok {
+		switch v := val.(type) {
+		case []string:
+			return v
+		case string:
+			if v != "" {
+				return []string{v}
+			}
+		}
+	}
+	return nil
+}
+
+// walkFunc is called for every path visited by walkPath
+type walkFunc func(path string, meta *data.Metadata) error
+
+// walkPath calls fn for root and, if recursive, for every entry below it.
+// Mount points are never passed to fn and nested mount points are skipped,
+// so recursive operations never cross mounts.
+// Errors returned by fn are written to the writer and counted, but don't stop the walk.
+func walkPath(ctx context.Context, api cmd.API, root string, recursive bool, name string, writer io.Writer, fn walkFunc) int {
+	meta, err := api.StatMetadata(ctx, root)
+	if err != nil {
+		fmt.Fprintf(writer, "%s: cannot access '%s': %v\n", name, root, err)
+		return 1
+	}
+
+	failed := 0
+	// Mount points only provide virtual metadata, which can't be processed
+	if !meta.Mode.IsMount() {
+		if err := fn(root, meta); err != nil {
+			fmt.Fprintf(writer, "%s: %s: %v\n", name, root, err)
+			failed++
+		}
+	}
+
+	if !recursive || !meta.Mode.IsDir() {
+		return failed
+	}
+
+	entries, err := api.ReadDirectory(ctx, root)
+	if err != nil {
+		fmt.Fprintf(writer, "%s: cannot read directory '%s': %v\n", name, root, err)
+		return failed + 1
+	}
+
+	for _, entry := range entries {
+		if ctx.Err() != nil {
+			return failed + 1
+		}
+		if entry.Mode.IsMount() {
+			continue
+		}
+
+		failed += walkPath(ctx, api, path.Join(root, entry.Key), recursive, name, writer, fn)
+	}
+
+	return failed
+}
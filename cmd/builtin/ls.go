@@ -48,7 +48,7 @@ func (ls *LsCommand) Execute(ctx context.Context, api cmd.API, args *cmd.Command
 	// List each path
 	for i, path := range paths {
 		if err := ls.listPath(ctx, api, path, longFormat, showAll, humanReadable, recursive, 0, writer); err != nil {
-			return 1, fmt.Errorf("cannot access '%s': %w", path, err)
+			return cmd.ExitFailure, fmt.Errorf("cannot access '%s': %w", path, err)
 		}
 
 		// Add newline between multiple paths
@@ -57,7 +57,7 @@ func (ls *LsCommand) Execute(ctx context.Context, api cmd.API, args *cmd.Command
 		}
 	}
 
-	return 0, nil
+	return cmd.ExitSuccess, nil
 }
 
 // listPath lists a single path with the given options
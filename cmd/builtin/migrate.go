@@ -0,0 +1,125 @@
+package builtin
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/mwantia/vfs/cmd"
+	"github.com/mwantia/vfs/data"
+	"github.com/mwantia/vfs/data/errors"
+	"github.com/mwantia/vfs/mount/backend"
+)
+
+type MigrateCommand struct {
+}
+
+// Name returns the command identifier
+func (m *MigrateCommand) Name() string {
+	return "migrate"
+}
+
+// Description returns human-readable help text
+func (m *MigrateCommand) Description() string {
+	return "Move a mount to another backend while it remains in use"
+}
+
+// Usage returns a usage string for help (e.g. "ls -al [path]")
+func (m *MigrateCommand) Usage() string {
+	return "migrate [OPTIONS] URL PATH"
+}
+
+// Execute runs the command with parsed arguments
+// Returns exit code (0 = success) and error message
+func (m *MigrateCommand) Execute(ctx context.Context, api cmd.API, args *cmd.CommandArgs, writer io.Writer) (int, error) {
+	if len(args.Args) != 2 {
+		return cmd.ExitUsage, fmt.Errorf("expected backend url and path")
+	}
+
+	metadataURL := getStringFlag(args, "metadata-url", "")
+	if metadataURL != "" && getBoolFlag(args, "metadata") {
+		return cmd.ExitUsage, fmt.Errorf("--metadata and --metadata-url are mutually exclusive")
+	}
+
+	created, err := backend.CreateBackendFromURL(args.Args[0])
+	if err != nil {
+		return cmd.ExitFailure, err
+	}
+	storage, ok := created.(backend.ObjectStorageBackend)
+	if !ok {
+		return cmd.ExitFailure, errors.BackendIncompatible(nil, created.Name())
+	}
+
+	var metadata backend.MetadataBackend
+	switch {
+	case getBoolFlag(args, "metadata"):
+		if metadata, ok = created.(backend.MetadataBackend); !ok {
+			return cmd.ExitFailure, errors.BackendIncompatible(nil, created.Name())
+		}
+	case metadataURL != "":
+		ext, err := backend.CreateBackendFromURL(metadataURL)
+		if err != nil {
+			return cmd.ExitFailure, err
+		}
+		if metadata, ok = ext.(backend.MetadataBackend); !ok {
+			return cmd.ExitFailure, errors.BackendIncompatible(nil, ext.Name())
+		}
+	}
+
+	opts := &data.MigrateOptions{
+		Verify:    getBoolFlag(args, "verify"),
+		Checksum:  data.ChecksumAlgorithm(getStringFlag(args, "checksum", "")),
+		MaxRounds: int(getIntFlag(args, "rounds", data.DefaultMigrateRounds)),
+	}
+
+	report, err := api.Migrate(ctx, args.Args[1], storage, metadata, opts)
+	if err != nil {
+		return cmd.ExitFailure, fmt.Errorf("failed to migrate '%s': %w", args.Args[1], err)
+	}
+
+	fmt.Fprintf(writer, "%s: migrated from %s to %s\n", report.Path, report.Source, report.Target)
+	fmt.Fprintf(writer, "  copied %d file(s) and %d directories (%s)\n", report.Files, report.Directories, formatSize(report.Bytes, true))
+	fmt.Fprintf(writer, "  synced %d and removed %d changed entries in %d pass(es)\n", report.Synced, report.Removed, report.Rounds)
+	if opts.Verify {
+		fmt.Fprintf(writer, "  verified %d file(s)\n", report.Verified)
+	}
+
+	return cmd.ExitSuccess, nil
+}
+
+// GetFlags returns the flag set for this command
+func (m *MigrateCommand) GetFlags() *cmd.CommandFlagSet {
+	return &cmd.CommandFlagSet{
+		Flags: map[string]*cmd.CommandFlag{
+			"metadata": {
+				Name:        "metadata",
+				Short:       "m",
+				Type:        cmd.FlagTypeBool,
+				Default:     false,
+				Description: "use the backend for metadata as well",
+			},
+			"metadata-url": {
+				Name:        "metadata-url",
+				Type:        cmd.FlagTypeString,
+				Description: "url of a separate metadata backend",
+			},
+			"verify": {
+				Name:        "verify",
+				Type:        cmd.FlagTypeBool,
+				Default:     false,
+				Description: "compare checksums of all files before switching",
+			},
+			"checksum": {
+				Name:        "checksum",
+				Type:        cmd.FlagTypeString,
+				Description: "checksum algorithm used for verification",
+			},
+			"rounds": {
+				Name:        "rounds",
+				Type:        cmd.FlagTypeInt,
+				Default:     int64(data.DefaultMigrateRounds),
+				Description: "maximum number of passes syncing changes before switching",
+			},
+		},
+	}
+}
@@ -0,0 +1,103 @@
+package builtin
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/mwantia/vfs/cmd"
+	"github.com/mwantia/vfs/data"
+)
+
+type MkdirCommand struct {
+}
+
+// Name returns the command identifier
+func (m *MkdirCommand) Name() string {
+	return "mkdir"
+}
+
+// Description returns human-readable help text
+func (m *MkdirCommand) Description() string {
+	return "Create directories"
+}
+
+// Usage returns a usage string for help (e.g. "ls -al [path]")
+func (m *MkdirCommand) Usage() string {
+	return "mkdir [OPTIONS] DIRECTORY..."
+}
+
+// Execute runs the command with parsed arguments
+// Returns exit code (0 = success) and error message
+func (m *MkdirCommand) Execute(ctx context.Context, api cmd.API, args *cmd.CommandArgs, writer io.Writer) (int, error) {
+	if len(args.Args) == 0 {
+		return cmd.ExitUsage, fmt.Errorf("missing operand")
+	}
+
+	parents := getBoolFlag(args, "parents")
+	failed := 0
+	for _, dir := range args.Args {
+		var err error
+		if parents {
+			err = m.mkdirAll(ctx, api, dir)
+		} else {
+			err = api.CreateDirectory(ctx, dir)
+		}
+
+		if err != nil {
+			fmt.Fprintf(writer, "mkdir: cannot create directory '%s': %v\n", dir, err)
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return cmd.ExitFailure, fmt.Errorf("failed to create %d directories", failed)
+	}
+
+	return cmd.ExitSuccess, nil
+}
+
+// mkdirAll creates dir including all missing parents, ignoring existing directories
+func (m *MkdirCommand) mkdirAll(ctx context.Context, api cmd.API, dir string) error {
+	current := ""
+	for part := range strings.SplitSeq(path.Clean("/"+dir), "/") {
+		if part == "" {
+			continue
+		}
+		current += "/" + part
+
+		meta, err := api.StatMetadata(ctx, current)
+		if err == nil {
+			if !meta.Mode.IsDir() {
+				return data.ErrNotDirectory
+			}
+			continue
+		}
+		if err != data.ErrNotExist {
+			return err
+		}
+
+		if err := api.CreateDirectory(ctx, current); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetFlags returns the flag set for this command
+func (m *MkdirCommand) GetFlags() *cmd.CommandFlagSet {
+	return &cmd.CommandFlagSet{
+		Flags: map[string]*cmd.CommandFlag{
+			"parents": {
+				Name:        "parents",
+				Short:       "p",
+				Type:        cmd.FlagTypeBool,
+				Default:     false,
+				Description: "no error if existing, make parent directories as needed",
+			},
+		},
+	}
+}
@@ -0,0 +1,248 @@
+package builtin
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/mwantia/vfs/cmd"
+	"github.com/mwantia/vfs/fstab"
+	"github.com/mwantia/vfs/mount/backend"
+)
+
+type MountCommand struct {
+}
+
+// Name returns the command identifier
+func (m *MountCommand) Name() string {
+	return "mount"
+}
+
+// Description returns human-readable help text
+func (m *MountCommand) Description() string {
+	return "Mount a backend or list all mounts"
+}
+
+// Usage returns a usage string for help (e.g. "ls -al [path]")
+func (m *MountCommand) Usage() string {
+	return "mount [OPTIONS] [URL] [TARGET]"
+}
+
+// Execute runs the command with parsed arguments
+// Returns exit code (0 = success) and error message
+func (m *MountCommand) Execute(ctx context.Context, api cmd.API, args *cmd.CommandArgs, writer io.Writer) (int, error) {
+	if getBoolFlag(args, "types") {
+		m.listTypes(writer)
+		return cmd.ExitSuccess, nil
+	}
+
+	name := getStringFlag(args, "type", "")
+	switch {
+	case len(args.Args) == 0 && name == "":
+		m.listMounts(api, writer)
+		return cmd.ExitSuccess, nil
+	case len(args.Args) == 1 && name == "":
+		return cmd.ExitUsage, fmt.Errorf("missing backend url or --type")
+	case len(args.Args) == 2 && name != "":
+		return cmd.ExitUsage, fmt.Errorf("backend url and --type are mutually exclusive")
+	case len(args.Args) == 0 || len(args.Args) > 2:
+		return cmd.ExitUsage, fmt.Errorf("invalid number of arguments")
+	}
+
+	entry := &fstab.Entry{
+		Path:        args.Args[len(args.Args)-1],
+		Backend:     name,
+		Namespace:   getStringFlag(args, "namespace", ""),
+		PathPrefix:  getStringFlag(args, "prefix", ""),
+		ReadOnly:    getBoolFlag(args, "read-only"),
+		DenyNesting: getBoolFlag(args, "no-nesting"),
+
+		MandatoryLocks: getBoolFlag(args, "mandatory-locks"),
+		WatchBackend:   getBoolFlag(args, "watch"),
+	}
+	if len(args.Args) == 2 {
+		entry.URL = args.Args[0]
+	}
+
+	// Values may contain commas, so every parameter is passed with its own -o
+	for _, option := range getStringSliceFlag(args, "options") {
+		if option == "" {
+			continue
+		}
+		key, value, found := strings.Cut(option, "=")
+		if !found || key == "" {
+			return cmd.ExitUsage, fmt.Errorf("invalid option '%s', expected KEY=VALUE", option)
+		}
+		if entry.Params == nil {
+			entry.Params = make(backend.FactoryParams)
+		}
+		entry.Params[key] = value
+	}
+
+	if getBoolFlag(args, "metadata") {
+		entry.Extensions = append(entry.Extensions, &fstab.Extension{
+			Backend:      fstab.PrimaryBackend,
+			Capabilities: []backend.BackendCapability{backend.CapabilityMetadata},
+		})
+	}
+
+	if err := api.MountEntry(ctx, entry); err != nil {
+		return cmd.ExitFailure, fmt.Errorf("failed to mount '%s': %w", entry.Path, err)
+	}
+
+	return cmd.ExitSuccess, nil
+}
+
+// listMounts writes all current mounts in the format "SOURCE on PATH type BACKEND (OPTIONS)"
+func (m *MountCommand) listMounts(api cmd.API, writer io.Writer) {
+	for _, entry := range api.DumpMountTable().Mounts {
+		source, name := entry.Backend, entry.Backend
+		if entry.URL != "" {
+			source = backend.RedactURL(entry.URL)
+			if resolved, _, err := backend.ParseURL(entry.URL); err == nil {
+				name = resolved
+			}
+		}
+
+		options := []string{"rw"}
+		if entry.ReadOnly {
+			options[0] = "ro"
+		}
+		if entry.DenyNesting {
+			options = append(options, "nonesting")
+		}
+		if entry.MandatoryLocks {
+			options = append(options, "mand")
+		}
+		if entry.WatchBackend {
+			options = append(options, "watch")
+		}
+		if entry.Namespace != "" {
+			options = append(options, "namespace="+entry.Namespace)
+		}
+		if entry.PathPrefix != "" {
+			options = append(options, "prefix="+entry.PathPrefix)
+		}
+		for _, ext := range entry.Extensions {
+			extName := ext.Backend
+			if ext.URL != "" {
+				extName = backend.RedactURL(ext.URL)
+			}
+			for _, capability := range ext.Capabilities {
+				options = append(options, fmt.Sprintf("%s=%s", capability, extName))
+			}
+		}
+
+		fmt.Fprintf(writer, "%s on %s type %s (%s)\n", source, entry.Path, name, strings.Join(options, ","))
+	}
+}
+
+// listTypes writes all registered backends including their parameters
+func (m *MountCommand) listTypes(writer io.Writer) {
+	for _, name := range backend.ListFactories() {
+		registration, exists := backend.GetRegistration(name)
+		if !exists {
+			continue
+		}
+
+		fmt.Fprintf(writer, "%s", registration.Name)
+		if len(registration.Schemes) > 0 {
+			fmt.Fprintf(writer, " (%s)", strings.Join(registration.Schemes, ", "))
+		}
+		if registration.Description != "" {
+			fmt.Fprintf(writer, " - %s", registration.Description)
+		}
+		fmt.Fprintln(writer)
+
+		for _, spec := range registration.Params {
+			var details []string
+			if spec.Type != "" && spec.Type != backend.ParamTypeString {
+				details = append(details, string(spec.Type))
+			}
+			if spec.Required {
+				details = append(details, "required")
+			}
+			if spec.Secret {
+				details = append(details, "secret")
+			} else if spec.Default != "" {
+				details = append(details, fmt.Sprintf("default: %q", spec.Default))
+			}
+
+			line := fmt.Sprintf("  %-12s %s", spec.Name, spec.Description)
+			if len(details) > 0 {
+				line += fmt.Sprintf(" (%s)", strings.Join(details, ", "))
+			}
+			fmt.Fprintln(writer, line)
+		}
+	}
+}
+
+// GetFlags returns the flag set for this command
+func (m *MountCommand) GetFlags() *cmd.CommandFlagSet {
+	return &cmd.CommandFlagSet{
+		Flags: map[string]*cmd.CommandFlag{
+			"type": {
+				Name:        "type",
+				Short:       "t",
+				Type:        cmd.FlagTypeString,
+				Description: "name of the backend, if no url is provided",
+			},
+			"options": {
+				Name:        "options",
+				Short:       "o",
+				Type:        cmd.FlagTypeString,
+				Multiple:    true,
+				Description: "backend parameter (KEY=VALUE), repeat for multiple parameters",
+			},
+			"read-only": {
+				Name:        "read-only",
+				Short:       "r",
+				Type:        cmd.FlagTypeBool,
+				Default:     false,
+				Description: "mount the backend read-only",
+			},
+			"mandatory-locks": {
+				Name:        "mandatory-locks",
+				Type:        cmd.FlagTypeBool,
+				Default:     false,
+				Description: "deny opening files locked by other owners",
+			},
+			"watch": {
+				Name:        "watch",
+				Type:        cmd.FlagTypeBool,
+				Default:     false,
+				Description: "report changes made outside of the vfs to watchers",
+			},
+			"no-nesting": {
+				Name:        "no-nesting",
+				Type:        cmd.FlagTypeBool,
+				Default:     false,
+				Description: "deny nested mounts below the target",
+			},
+			"metadata": {
+				Name:        "metadata",
+				Short:       "m",
+				Type:        cmd.FlagTypeBool,
+				Default:     false,
+				Description: "use the backend for metadata as well",
+			},
+			"namespace": {
+				Name:        "namespace",
+				Type:        cmd.FlagTypeString,
+				Description: "namespace used for all objects",
+			},
+			"prefix": {
+				Name:        "prefix",
+				Type:        cmd.FlagTypeString,
+				Description: "path prefix used for all objects",
+			},
+			"types": {
+				Name:        "types",
+				Type:        cmd.FlagTypeBool,
+				Default:     false,
+				Description: "list all available backends and their parameters",
+			},
+		},
+	}
+}
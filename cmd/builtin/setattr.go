@@ -0,0 +1,99 @@
+package builtin
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/mwantia/vfs/cmd"
+	"github.com/mwantia/vfs/data"
+)
+
+type SetattrCommand struct {
+}
+
+// Name returns the command identifier
+func (s *SetattrCommand) Name() string {
+	return "setattr"
+}
+
+// Description returns human-readable help text
+func (s *SetattrCommand) Description() string {
+	return "Set or remove extended attributes of files"
+}
+
+// Usage returns a usage string for help (e.g. "ls -al [path]")
+func (s *SetattrCommand) Usage() string {
+	return "setattr [OPTIONS] PATH [KEY=VALUE...]"
+}
+
+// Execute runs the command with parsed arguments
+// Returns exit code (0 = success) and error message
+func (s *SetattrCommand) Execute(ctx context.Context, api cmd.API, args *cmd.CommandArgs, writer io.Writer) (int, error) {
+	if len(args.Args) == 0 {
+		return cmd.ExitUsage, fmt.Errorf("missing operand")
+	}
+
+	attributes := make(map[string]string)
+	for _, pair := range args.Args[1:] {
+		key, value, found := strings.Cut(pair, "=")
+		if !found || key == "" {
+			return cmd.ExitUsage, fmt.Errorf("invalid attribute '%s', expected KEY=VALUE", pair)
+		}
+		attributes[key] = value
+	}
+
+	removes := getStringSliceFlag(args, "remove")
+	if len(attributes) == 0 && len(removes) == 0 {
+		return cmd.ExitUsage, fmt.Errorf("no attributes specified")
+	}
+
+	recursive := getBoolFlag(args, "recursive")
+	failed := walkPath(ctx, api, args.Args[0], recursive, s.Name(), writer, func(path string, meta *data.Metadata) error {
+		if len(attributes) > 0 {
+			if err := api.SetAttributes(ctx, path, attributes); err != nil {
+				return err
+			}
+		}
+
+		for _, key := range removes {
+			// Missing attributes are only an error for the explicitly specified path
+			if !meta.HasAttribute(key) && recursive {
+				continue
+			}
+			if err := api.RemoveAttribute(ctx, path, key); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	if failed > 0 {
+		return cmd.ExitFailure, fmt.Errorf("failed to change attributes of %d path(s)", failed)
+	}
+
+	return cmd.ExitSuccess, nil
+}
+
+// GetFlags returns the flag set for this command
+func (s *SetattrCommand) GetFlags() *cmd.CommandFlagSet {
+	return &cmd.CommandFlagSet{
+		Flags: map[string]*cmd.CommandFlag{
+			"remove": {
+				Name:        "remove",
+				Short:       "x",
+				Type:        cmd.FlagTypeString,
+				Multiple:    true,
+				Description: "remove the attribute with this name",
+			},
+			"recursive": {
+				Name:        "recursive",
+				Short:       "R",
+				Type:        cmd.FlagTypeBool,
+				Default:     false,
+				Description: "change attributes of directories recursively",
+			},
+		},
+	}
+}
@@ -0,0 +1,121 @@
+package builtin
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/mwantia/vfs/cmd"
+	"github.com/mwantia/vfs/data"
+)
+
+type Sha256sumCommand struct {
+}
+
+// Name returns the command identifier
+func (s *Sha256sumCommand) Name() string {
+	return "sha256sum"
+}
+
+// Description returns human-readable help text
+func (s *Sha256sumCommand) Description() string {
+	return "Compute and check content checksums"
+}
+
+// Usage returns a usage string for help (e.g. "ls -al [path]")
+func (s *Sha256sumCommand) Usage() string {
+	return "sha256sum [OPTIONS] FILE..."
+}
+
+// Execute runs the command with parsed arguments
+// Returns exit code (0 = success) and error message
+func (s *Sha256sumCommand) Execute(ctx context.Context, api cmd.API, args *cmd.CommandArgs, writer io.Writer) (int, error) {
+	if len(args.Args) == 0 {
+		return cmd.ExitUsage, fmt.Errorf("missing file operand")
+	}
+
+	algorithm := data.ChecksumAlgorithm(getStringFlag(args, "algorithm", string(data.ChecksumSHA256)))
+	if !algorithm.IsValid() {
+		return cmd.ExitUsage, fmt.Errorf("%w: %s", data.ErrChecksumUnsupported, algorithm)
+	}
+
+	check := getBoolFlag(args, "check")
+	failed := 0
+
+	for _, path := range args.Args {
+		meta, err := api.StatMetadata(ctx, path)
+		if err != nil {
+			fmt.Fprintf(writer, "sha256sum: %s: %v\n", path, err)
+			failed++
+			continue
+		}
+		if meta.Mode.IsDir() {
+			fmt.Fprintf(writer, "sha256sum: %s: %v\n", path, data.ErrIsDirectory)
+			failed++
+			continue
+		}
+
+		if !check {
+			checksum, err := data.ComputeChecksum(newFileReader(ctx, api, path, 0, meta.Size), algorithm)
+			if err != nil {
+				fmt.Fprintf(writer, "sha256sum: %s: %v\n", path, err)
+				failed++
+				continue
+			}
+
+			fmt.Fprintf(writer, "%s  %s\n", checksum.Value, path)
+			continue
+		}
+
+		// Compare against the stored checksum with its own algorithm
+		stored, exists := meta.GetChecksum()
+		if !exists {
+			fmt.Fprintf(writer, "%s: MISSING\n", path)
+			failed++
+			continue
+		}
+
+		checksum, err := data.ComputeChecksum(newFileReader(ctx, api, path, 0, meta.Size), stored.Algorithm)
+		if err != nil {
+			fmt.Fprintf(writer, "sha256sum: %s: %v\n", path, err)
+			failed++
+			continue
+		}
+
+		if !checksum.Equal(stored) {
+			fmt.Fprintf(writer, "%s: FAILED\n", path)
+			failed++
+			continue
+		}
+
+		fmt.Fprintf(writer, "%s: OK\n", path)
+	}
+
+	if failed > 0 {
+		return cmd.ExitFailure, fmt.Errorf("%d of %d file(s) failed", failed, len(args.Args))
+	}
+
+	return cmd.ExitSuccess, nil
+}
+
+// GetFlags returns the flag set for this command
+func (s *Sha256sumCommand) GetFlags() *cmd.CommandFlagSet {
+	return &cmd.CommandFlagSet{
+		Flags: map[string]*cmd.CommandFlag{
+			"check": {
+				Name:        "check",
+				Short:       "c",
+				Type:        cmd.FlagTypeBool,
+				Default:     false,
+				Description: "compare against the stored checksum instead of printing",
+			},
+			"algorithm": {
+				Name:        "algorithm",
+				Short:       "a",
+				Type:        cmd.FlagTypeString,
+				Default:     string(data.ChecksumSHA256),
+				Description: "checksum algorithm (sha256, sha512, sha1, md5, crc32)",
+			},
+		},
+	}
+}
@@ -0,0 +1,80 @@
+package builtin
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/mwantia/vfs/cmd"
+	"github.com/mwantia/vfs/data"
+)
+
+type SourceCommand struct {
+}
+
+// Name returns the command identifier
+func (s *SourceCommand) Name() string {
+	return "source"
+}
+
+// Description returns human-readable help text
+func (s *SourceCommand) Description() string {
+	return "Execute commands from a script file"
+}
+
+// Usage returns a usage string for help (e.g. "ls -al [path]")
+func (s *SourceCommand) Usage() string {
+	return "source FILE [ARGUMENTS...]"
+}
+
+// Execute runs the command with parsed arguments
+// Returns exit code (0 = success) and error message
+func (s *SourceCommand) Execute(ctx context.Context, api cmd.API, args *cmd.CommandArgs, writer io.Writer) (int, error) {
+	if len(args.Args) == 0 {
+		return cmd.ExitUsage, fmt.Errorf("missing file operand")
+	}
+
+	path := args.Args[0]
+	meta, err := api.StatMetadata(ctx, path)
+	if err != nil {
+		return cmd.ExitFailure, fmt.Errorf("cannot access '%s': %w", path, err)
+	}
+	if meta.Mode.IsDir() {
+		return cmd.ExitFailure, fmt.Errorf("cannot access '%s': %w", path, data.ErrIsDirectory)
+	}
+
+	// Positional arguments are available as $0..$N and $#
+	variables := map[string]string{
+		"0": path,
+		"#": strconv.Itoa(len(args.Args) - 1),
+	}
+	for i, arg := range args.Args[1:] {
+		variables[strconv.Itoa(i+1)] = arg
+	}
+
+	ctx = cmd.WithScriptVariables(ctx, variables)
+	return api.ExecuteScript(ctx, newFileReader(ctx, api, path, 0, meta.Size), writer)
+}
+
+// GetFlags returns the flag set for this command
+func (s *SourceCommand) GetFlags() *cmd.CommandFlagSet {
+	return &cmd.CommandFlagSet{
+		Flags: map[string]*cmd.CommandFlag{},
+	}
+}
+
+// RunCommand is an alias for SourceCommand
+type RunCommand struct {
+	SourceCommand
+}
+
+// Name returns the command identifier
+func (r *RunCommand) Name() string {
+	return "run"
+}
+
+// Usage returns a usage string for help (e.g. "ls -al [path]")
+func (r *RunCommand) Usage() string {
+	return "run FILE [ARGUMENTS...]"
+}
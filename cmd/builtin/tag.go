@@ -0,0 +1,109 @@
+package builtin
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/mwantia/vfs/cmd"
+	"github.com/mwantia/vfs/data"
+)
+
+type TagCommand struct {
+}
+
+// Name returns the command identifier
+func (t *TagCommand) Name() string {
+	return "tag"
+}
+
+// Description returns human-readable help text
+func (t *TagCommand) Description() string {
+	return "List, add or remove tags of files"
+}
+
+// Usage returns a usage string for help (e.g. "ls -al [path]")
+func (t *TagCommand) Usage() string {
+	return "tag [OPTIONS] PATH [TAG...]"
+}
+
+// Execute runs the command with parsed arguments
+// Returns exit code (0 = success) and error message
+func (t *TagCommand) Execute(ctx context.Context, api cmd.API, args *cmd.CommandArgs, writer io.Writer) (int, error) {
+	if len(args.Args) == 0 {
+		return cmd.ExitUsage, fmt.Errorf("missing operand")
+	}
+
+	tags := args.Args[1:]
+	for _, tag := range tags {
+		if tag == "" || strings.Contains(tag, ",") {
+			return cmd.ExitUsage, fmt.Errorf("invalid tag '%s'", tag)
+		}
+	}
+
+	remove := getBoolFlag(args, "delete")
+	if remove && len(tags) == 0 {
+		return cmd.ExitUsage, fmt.Errorf("no tags specified")
+	}
+
+	recursive := getBoolFlag(args, "recursive")
+	failed := walkPath(ctx, api, args.Args[0], recursive, t.Name(), writer, func(path string, meta *data.Metadata) error {
+		current := meta.GetTags()
+		// Without tags the current tags are only listed
+		if len(tags) == 0 {
+			fmt.Fprintf(writer, "%s: %s\n", path, strings.Join(current, " "))
+			return nil
+		}
+
+		updated := slices.Clone(current)
+		for _, tag := range tags {
+			if remove {
+				updated = slices.DeleteFunc(updated, func(s string) bool { return s == tag })
+			} else if !slices.Contains(updated, tag) {
+				updated = append(updated, tag)
+			}
+		}
+		slices.Sort(updated)
+
+		if slices.Equal(current, updated) {
+			return nil
+		}
+		if len(updated) == 0 {
+			return api.RemoveAttribute(ctx, path, data.AttributeTags)
+		}
+
+		return api.SetAttributes(ctx, path, map[string]string{
+			data.AttributeTags: strings.Join(updated, ","),
+		})
+	})
+
+	if failed > 0 {
+		return cmd.ExitFailure, fmt.Errorf("failed to change tags of %d path(s)", failed)
+	}
+
+	return cmd.ExitSuccess, nil
+}
+
+// GetFlags returns the flag set for this command
+func (t *TagCommand) GetFlags() *cmd.CommandFlagSet {
+	return &cmd.CommandFlagSet{
+		Flags: map[string]*cmd.CommandFlag{
+			"delete": {
+				Name:        "delete",
+				Short:       "d",
+				Type:        cmd.FlagTypeBool,
+				Default:     false,
+				Description: "remove the specified tags instead of adding them",
+			},
+			"recursive": {
+				Name:        "recursive",
+				Short:       "R",
+				Type:        cmd.FlagTypeBool,
+				Default:     false,
+				Description: "change tags of directories recursively",
+			},
+		},
+	}
+}
@@ -0,0 +1,219 @@
+package builtin
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/mwantia/vfs/cmd"
+)
+
+type TailCommand struct {
+}
+
+// Name returns the command identifier
+func (t *TailCommand) Name() string {
+	return "tail"
+}
+
+// Description returns human-readable help text
+func (t *TailCommand) Description() string {
+	return "Output the last part of files"
+}
+
+// Usage returns a usage string for help (e.g. "ls -al [path]")
+func (t *TailCommand) Usage() string {
+	return "tail [OPTIONS] FILE..."
+}
+
+// Execute runs the command with parsed arguments
+// Returns exit code (0 = success) and error message
+func (t *TailCommand) Execute(ctx context.Context, api cmd.API, args *cmd.CommandArgs, writer io.Writer) (int, error) {
+	if len(args.Args) == 0 {
+		return cmd.ExitUsage, fmt.Errorf("missing file operand")
+	}
+
+	lines := getIntFlag(args, "lines", 10)
+	bytes := getIntFlag(args, "bytes", -1)
+	follow := getBoolFlag(args, "follow")
+	interval := getIntFlag(args, "interval", 1000)
+
+	if lines < 0 {
+		return cmd.ExitUsage, fmt.Errorf("invalid number of lines: %d", lines)
+	}
+	if _, exists := args.Flags["bytes"]; exists && bytes < 0 {
+		return cmd.ExitUsage, fmt.Errorf("invalid number of bytes: %d", bytes)
+	}
+	if interval <= 0 {
+		return cmd.ExitUsage, fmt.Errorf("invalid interval: %d", interval)
+	}
+	if follow && len(args.Args) > 1 {
+		return cmd.ExitUsage, fmt.Errorf("only a single file can be followed")
+	}
+
+	failed := 0
+	for i, path := range args.Args {
+		if len(args.Args) > 1 {
+			if i > 0 {
+				fmt.Fprintln(writer)
+			}
+			fmt.Fprintf(writer, "==> %s <==\n", path)
+		}
+
+		if err := t.tail(ctx, api, path, lines, bytes, follow, time.Duration(interval)*time.Millisecond, writer); err != nil {
+			fmt.Fprintf(writer, "tail: %s: %v\n", path, err)
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return cmd.ExitFailure, fmt.Errorf("failed to read %d file(s)", failed)
+	}
+
+	return cmd.ExitSuccess, nil
+}
+
+// tail writes either the last bytes (if set) or the last lines of path and optionally follows appends
+func (t *TailCommand) tail(ctx context.Context, api cmd.API, path string, lines, bytes int64, follow bool, interval time.Duration, writer io.Writer) error {
+	file, err := openFileReader(ctx, api, path)
+	if err != nil {
+		return err
+	}
+	size := file.Size()
+
+	var offset int64
+	if bytes >= 0 {
+		offset = max(0, size-bytes)
+	} else if offset, err = t.findLinesOffset(ctx, api, path, size, lines); err != nil {
+		return err
+	}
+
+	if err := t.copyRange(ctx, api, path, offset, size, writer); err != nil {
+		return err
+	}
+
+	if !follow {
+		return nil
+	}
+
+	return t.follow(ctx, api, path, size, interval, writer)
+}
+
+// findLinesOffset searches backwards from the end for the offset where the last lines start
+func (t *TailCommand) findLinesOffset(ctx context.Context, api cmd.API, path string, size, lines int64) (int64, error) {
+	if lines == 0 {
+		return size, nil
+	}
+
+	end := size
+	buffer := make([]byte, readChunkSize)
+	found := int64(0)
+
+	for end > 0 {
+		start := max(0, end-int64(len(buffer)))
+		chunk := buffer[:end-start]
+
+		if _, err := io.ReadFull(newFileReader(ctx, api, path, start, end), chunk); err != nil {
+			return 0, err
+		}
+
+		for i := len(chunk) - 1; i >= 0; i-- {
+			// A trailing newline doesn't start another line
+			if chunk[i] != '\n' || start+int64(i) == size-1 {
+				continue
+			}
+
+			found++
+			if found == lines {
+				return start + int64(i) + 1, nil
+			}
+		}
+
+		end = start
+	}
+
+	return 0, nil
+}
+
+// copyRange writes the content between offset and end to the writer
+func (t *TailCommand) copyRange(ctx context.Context, api cmd.API, path string, offset, end int64, writer io.Writer) error {
+	if offset >= end {
+		return nil
+	}
+
+	_, err := io.Copy(writer, newFileReader(ctx, api, path, offset, end))
+	return err
+}
+
+// follow polls the size of the file and writes appended content until the context is cancelled
+func (t *TailCommand) follow(ctx context.Context, api cmd.API, path string, offset int64, interval time.Duration, writer io.Writer) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		meta, err := api.StatMetadata(ctx, path)
+		if err != nil {
+			// Following ends once the context is cancelled, even during a poll
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+
+		size := meta.Size
+		if size < offset {
+			fmt.Fprintf(writer, "tail: %s: file truncated\n", path)
+			offset = 0
+		}
+
+		if err := t.copyRange(ctx, api, path, offset, size, writer); err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		offset = size
+	}
+}
+
+// GetFlags returns the flag set for this command
+func (t *TailCommand) GetFlags() *cmd.CommandFlagSet {
+	return &cmd.CommandFlagSet{
+		Flags: map[string]*cmd.CommandFlag{
+			"lines": {
+				Name:        "lines",
+				Short:       "n",
+				Type:        cmd.FlagTypeInt,
+				Default:     int64(10),
+				Description: "print the last NUM lines",
+			},
+			"bytes": {
+				Name:        "bytes",
+				Short:       "c",
+				Type:        cmd.FlagTypeInt,
+				Description: "print the last NUM bytes instead of lines",
+			},
+			"follow": {
+				Name:        "follow",
+				Short:       "f",
+				Type:        cmd.FlagTypeBool,
+				Default:     false,
+				Description: "output appended data as the file grows",
+			},
+			"interval": {
+				Name:        "interval",
+				Short:       "s",
+				Type:        cmd.FlagTypeInt,
+				Default:     int64(1000),
+				Description: "polling interval in milliseconds used with --follow",
+			},
+		},
+	}
+}
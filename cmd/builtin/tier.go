@@ -0,0 +1,98 @@
+package builtin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/mwantia/vfs/cmd"
+	"github.com/mwantia/vfs/data"
+)
+
+type TierCommand struct {
+}
+
+// Name returns the command identifier
+func (t *TierCommand) Name() string {
+	return "tier"
+}
+
+// Description returns human-readable help text
+func (t *TierCommand) Description() string {
+	return "Move files between storage tiers according to the tier policy"
+}
+
+// Usage returns a usage string for help (e.g. "ls -al [path]")
+func (t *TierCommand) Usage() string {
+	return "tier [OPTIONS] [PATH]..."
+}
+
+// Execute runs the command with parsed arguments
+// Returns exit code (0 = success) and error message
+func (t *TierCommand) Execute(ctx context.Context, api cmd.API, args *cmd.CommandArgs, writer io.Writer) (int, error) {
+	humanReadable := getBoolFlag(args, "human-readable")
+
+	opts := &data.TierOptions{
+		DryRun: getBoolFlag(args, "dry-run"),
+	}
+
+	// Without paths, all mounts are tiered, while mounts without tier policy are skipped
+	paths, explicit := args.Args, len(args.Args) > 0
+	if !explicit {
+		for _, entry := range api.DumpMountTable().Mounts {
+			if !entry.ReadOnly || opts.DryRun {
+				paths = append(paths, entry.Path)
+			}
+		}
+	}
+
+	action := "moved"
+	if opts.DryRun {
+		action = "would move"
+	}
+
+	failed := 0
+	for _, path := range paths {
+		report, err := api.Tier(ctx, path, opts)
+		if err != nil {
+			if !explicit && errors.Is(err, data.ErrNotSupported) {
+				continue
+			}
+			fmt.Fprintf(writer, "tier: %s: %v\n", path, err)
+			failed++
+			continue
+		}
+
+		fmt.Fprintf(writer, "%s: checked %d file(s), %s %d to hot and %d to cold tier (%s)\n",
+			report.Path, report.Checked, action, report.Promoted, report.Demoted, formatSize(report.Bytes, humanReadable))
+	}
+
+	if failed > 0 {
+		return cmd.ExitFailure, fmt.Errorf("failed to tier %d path(s)", failed)
+	}
+
+	return cmd.ExitSuccess, nil
+}
+
+// GetFlags returns the flag set for this command
+func (t *TierCommand) GetFlags() *cmd.CommandFlagSet {
+	return &cmd.CommandFlagSet{
+		Flags: map[string]*cmd.CommandFlag{
+			"dry-run": {
+				Name:        "dry-run",
+				Short:       "n",
+				Type:        cmd.FlagTypeBool,
+				Default:     false,
+				Description: "only report files, which would be moved between tiers",
+			},
+			"human-readable": {
+				Name:        "human-readable",
+				Short:       "h",
+				Type:        cmd.FlagTypeBool,
+				Default:     false,
+				Description: "print sizes in human readable format (e.g., 1K, 234M, 2G)",
+			},
+		},
+	}
+}
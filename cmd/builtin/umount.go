@@ -0,0 +1,65 @@
+package builtin
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/mwantia/vfs/cmd"
+)
+
+type UmountCommand struct {
+}
+
+// Name returns the command identifier
+func (u *UmountCommand) Name() string {
+	return "umount"
+}
+
+// Description returns human-readable help text
+func (u *UmountCommand) Description() string {
+	return "Unmount backends"
+}
+
+// Usage returns a usage string for help (e.g. "ls -al [path]")
+func (u *UmountCommand) Usage() string {
+	return "umount [OPTIONS] TARGET..."
+}
+
+// Execute runs the command with parsed arguments
+// Returns exit code (0 = success) and error message
+func (u *UmountCommand) Execute(ctx context.Context, api cmd.API, args *cmd.CommandArgs, writer io.Writer) (int, error) {
+	if len(args.Args) == 0 {
+		return cmd.ExitUsage, fmt.Errorf("missing operand")
+	}
+
+	force := getBoolFlag(args, "force")
+	failed := 0
+	for _, target := range args.Args {
+		if err := api.Unmount(ctx, target, force); err != nil {
+			fmt.Fprintf(writer, "umount: %s: %v\n", target, err)
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return cmd.ExitFailure, fmt.Errorf("failed to unmount %d target(s)", failed)
+	}
+
+	return cmd.ExitSuccess, nil
+}
+
+// GetFlags returns the flag set for this command
+func (u *UmountCommand) GetFlags() *cmd.CommandFlagSet {
+	return &cmd.CommandFlagSet{
+		Flags: map[string]*cmd.CommandFlag{
+			"force": {
+				Name:        "force",
+				Short:       "f",
+				Type:        cmd.FlagTypeBool,
+				Default:     false,
+				Description: "force unmount, even if files are still open",
+			},
+		},
+	}
+}
@@ -0,0 +1,188 @@
+package builtin
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path"
+
+	"github.com/mwantia/vfs/cmd"
+	"github.com/mwantia/vfs/data"
+)
+
+type VerifyCommand struct {
+}
+
+// verifyResult summarizes the outcome of a verify run
+type verifyResult struct {
+	verified  int
+	updated   int
+	corrupted int
+	missing   int
+	failed    int
+}
+
+// Name returns the command identifier
+func (v *VerifyCommand) Name() string {
+	return "verify"
+}
+
+// Description returns human-readable help text
+func (v *VerifyCommand) Description() string {
+	return "Verify stored content checksums of all files below a path and compute outdated ones"
+}
+
+// Usage returns a usage string for help (e.g. "ls -al [path]")
+func (v *VerifyCommand) Usage() string {
+	return "verify [OPTIONS] [PATH]"
+}
+
+// Execute runs the command with parsed arguments
+// Returns exit code (0 = success) and error message
+func (v *VerifyCommand) Execute(ctx context.Context, api cmd.API, args *cmd.CommandArgs, writer io.Writer) (int, error) {
+	if len(args.Args) > 1 {
+		return cmd.ExitUsage, fmt.Errorf("too many arguments")
+	}
+
+	root := "/"
+	if len(args.Args) == 1 {
+		root = args.Args[0]
+	}
+
+	verbose := getBoolFlag(args, "verbose")
+	strict := getBoolFlag(args, "strict")
+
+	meta, err := api.StatMetadata(ctx, root)
+	if err != nil {
+		return cmd.ExitFailure, fmt.Errorf("cannot access '%s': %w", root, err)
+	}
+
+	result := &verifyResult{}
+	if meta.Mode.IsDir() {
+		if err := v.verifyDirectory(ctx, api, root, verbose, result, writer); err != nil {
+			return cmd.ExitFailure, err
+		}
+	} else {
+		v.verifyFile(ctx, api, root, meta, verbose, result, writer)
+	}
+
+	fmt.Fprintf(writer, "%d verified, %d updated, %d corrupted, %d without checksum, %d unreadable\n",
+		result.verified, result.updated, result.corrupted, result.missing, result.failed)
+
+	if result.corrupted > 0 || result.failed > 0 {
+		return cmd.ExitFailure, fmt.Errorf("%d corrupted and %d unreadable object(s) found", result.corrupted, result.failed)
+	}
+	if strict && result.missing > 0 {
+		return cmd.ExitFailure, fmt.Errorf("%d object(s) without checksum found", result.missing)
+	}
+
+	return cmd.ExitSuccess, nil
+}
+
+// verifyDirectory recursively verifies all files within dir
+func (v *VerifyCommand) verifyDirectory(ctx context.Context, api cmd.API, dir string, verbose bool, result *verifyResult, writer io.Writer) error {
+	entries, err := api.ReadDirectory(ctx, dir)
+	if err != nil {
+		return fmt.Errorf("cannot read directory '%s': %w", dir, err)
+	}
+
+	for _, entry := range entries {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		entryPath := path.Join(dir, entry.Key)
+		if entry.Mode.IsDir() {
+			if err := v.verifyDirectory(ctx, api, entryPath, verbose, result, writer); err != nil {
+				return err
+			}
+			continue
+		}
+
+		// Directory listings may not contain attributes for every backend
+		meta, err := api.StatMetadata(ctx, entryPath)
+		if err != nil {
+			fmt.Fprintf(writer, "%s: ERROR %v\n", entryPath, err)
+			result.failed++
+			continue
+		}
+
+		v.verifyFile(ctx, api, entryPath, meta, verbose, result, writer)
+	}
+
+	return nil
+}
+
+// verifyFile recomputes the checksum of a single file and compares it with the stored one
+func (v *VerifyCommand) verifyFile(ctx context.Context, api cmd.API, filePath string, meta *data.Metadata, verbose bool, result *verifyResult, writer io.Writer) {
+	if meta.GetType() != data.FileTypeRegular {
+		return
+	}
+
+	stored, exists := meta.GetChecksum()
+	if !exists {
+		v.updateFile(ctx, api, filePath, verbose, result, writer)
+		return
+	}
+
+	checksum, err := data.ComputeChecksum(newFileReader(ctx, api, filePath, 0, meta.Size), stored.Algorithm)
+	if err != nil {
+		fmt.Fprintf(writer, "%s: ERROR %v\n", filePath, err)
+		result.failed++
+		return
+	}
+
+	if !checksum.Equal(stored) {
+		fmt.Fprintf(writer, "%s: FAILED (expected %s, got %s)\n", filePath, stored, checksum)
+		result.corrupted++
+		return
+	}
+
+	if verbose {
+		fmt.Fprintf(writer, "%s: OK\n", filePath)
+	}
+	result.verified++
+}
+
+// updateFile stores the checksum of a single file, which has been outdated by writes since it was last computed.
+// Files on mounts without checksums are counted as missing.
+func (v *VerifyCommand) updateFile(ctx context.Context, api cmd.API, filePath string, verbose bool, result *verifyResult, writer io.Writer) {
+	err := api.UpdateChecksum(ctx, filePath)
+	switch {
+	case err == data.ErrNotSupported || err == data.ErrReadOnly:
+		if verbose {
+			fmt.Fprintf(writer, "%s: MISSING\n", filePath)
+		}
+		result.missing++
+	case err != nil:
+		fmt.Fprintf(writer, "%s: ERROR %v\n", filePath, err)
+		result.failed++
+	default:
+		if verbose {
+			fmt.Fprintf(writer, "%s: UPDATED\n", filePath)
+		}
+		result.updated++
+	}
+}
+
+// GetFlags returns the flag set for this command
+func (v *VerifyCommand) GetFlags() *cmd.CommandFlagSet {
+	return &cmd.CommandFlagSet{
+		Flags: map[string]*cmd.CommandFlag{
+			"verbose": {
+				Name:        "verbose",
+				Short:       "v",
+				Type:        cmd.FlagTypeBool,
+				Default:     false,
+				Description: "print the result for every file",
+			},
+			"strict": {
+				Name:        "strict",
+				Short:       "s",
+				Type:        cmd.FlagTypeBool,
+				Default:     false,
+				Description: "fail if files without stored checksum are found",
+			},
+		},
+	}
+}
@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Exit codes returned by command executions
+const (
+	ExitSuccess  = 0   // Command completed successfully
+	ExitFailure  = 1   // Command failed during execution
+	ExitUsage    = 2   // Command was called with invalid flags or arguments
+	ExitNotFound = 127 // Command does not exist
+)
+
+// HelpFlag is the long flag reserved to print the usage of any command,
+// unless the command defines a flag with the same name itself.
+const HelpFlag = "help"
+
+// WantsHelp reports whether the raw arguments request the usage of a command.
+// Arguments after "--" and arguments consumed as flag values (e.g. "grep -e --help") are never treated as flags.
+func WantsHelp(raw []string, flagSet *CommandFlagSet) bool {
+	if flagSet == nil {
+		flagSet = &CommandFlagSet{
+			Flags: make(map[string]*CommandFlag),
+		}
+	}
+	if _, exists := flagSet.Flags[HelpFlag]; exists {
+		return false
+	}
+
+	parser := NewParser(flagSet)
+	for i := 0; i < len(raw); i++ {
+		arg := raw[i]
+		if arg == "--" {
+			return false
+		}
+		if arg == "--"+HelpFlag {
+			return true
+		}
+		// Skip the value of the flag, the same way it is consumed while parsing
+		if flag := parser.valueFlag(arg); flag != nil && i+1 < len(raw) && parser.isFlagValue(raw[i+1], flag.Type) {
+			i++
+		}
+	}
+
+	return false
+}
+
+// FormatUsage generates the formatted usage of a command based on its flag set.
+func FormatUsage(c Command) string {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "Usage: %s\n", c.Usage())
+	if desc := c.Description(); desc != "" {
+		fmt.Fprintf(&sb, "\n%s\n", desc)
+	}
+
+	flagSet := c.GetFlags()
+	names := flagSet.Names()
+	if len(names) == 0 {
+		return sb.String()
+	}
+
+	type row struct {
+		left  string
+		right string
+	}
+
+	rows := make([]row, 0, len(names))
+	width := 0
+	for _, name := range names {
+		flag := flagSet.Flags[name]
+
+		left := "    "
+		if flag.Short != "" {
+			left = fmt.Sprintf("-%s, ", flag.Short)
+		}
+		left += "--" + flag.Name
+		if flag.Type != FlagTypeBool {
+			left += fmt.Sprintf(" <%s>", flag.Type)
+		}
+
+		right := flag.Description
+		if flag.Default != nil && !isZeroDefault(flag.Default) {
+			right += fmt.Sprintf(" (default: %v)", flag.Default)
+		}
+		if flag.Required {
+			right += " (required)"
+		}
+		if flag.Multiple {
+			right += " (repeatable)"
+		}
+
+		width = max(width, len(left))
+		rows = append(rows, row{left: left, right: right})
+	}
+
+	sb.WriteString("\nOptions:\n")
+	for _, r := range rows {
+		fmt.Fprintf(&sb, "  %-*s  %s\n", width, r.left, strings.TrimSpace(r.right))
+	}
+
+	return sb.String()
+}
+
+// FormatCommandList generates a sorted overview of all commands with their descriptions.
+func FormatCommandList(commands []Command) string {
+	sorted := make([]Command, len(commands))
+	copy(sorted, commands)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Name() < sorted[j].Name()
+	})
+
+	width := 0
+	for _, c := range sorted {
+		width = max(width, len(c.Name()))
+	}
+
+	var sb strings.Builder
+	sb.WriteString("Available commands:\n")
+	for _, c := range sorted {
+		fmt.Fprintf(&sb, "  %-*s  %s\n", width, c.Name(), c.Description())
+	}
+	fmt.Fprintf(&sb, "\nUse 'help <command>' or '<command> --%s' for more information.\n", HelpFlag)
+
+	return sb.String()
+}
+
+// isZeroDefault reports whether a default value is not worth printing.
+func isZeroDefault(value any) bool {
+	switch v := value.(type) {
+	case bool:
+		return !v
+	case string:
+		return v == ""
+	case []string:
+		return len(v) == 0
+	case []int64:
+		return len(v) == 0
+	}
+	return false
+}
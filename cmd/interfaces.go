@@ -3,8 +3,10 @@ package cmd
 import (
 	"context"
 	"io"
+	"time"
 
 	"github.com/mwantia/vfs/data"
+	"github.com/mwantia/vfs/fstab"
 	"github.com/mwantia/vfs/mount"
 	"github.com/mwantia/vfs/mount/backend"
 )
@@ -12,14 +14,54 @@ import (
 // API is a simplified version of FileSystem.
 // It strips away all functions not required for command operations.
 type API interface {
+	// GetCommand returns the registered command with the specified name.
+	GetCommand(name string) (Command, bool)
+
+	// ListCommands returns all registered commands.
+	ListCommands() []Command
+
+	// ExecuteScript runs all commands read from the reader line by line, writing output to the provided writer.
+	// Failures are reported with their line number; "set -e" stops the script at the first failure.
+	ExecuteScript(ctx context.Context, reader io.Reader, writer io.Writer) (int, error)
+
 	// Mount attaches a filesystem handler at the specified path.
 	// Options can be used to configure the mount (e.g., read-only).
 	Mount(ctx context.Context, path string, primary backend.ObjectStorageBackend, opts ...mount.MountOption) error
 
+	// MountEntry creates the backends of a single mount table entry, mounts them and creates the directory skeleton.
+	// The entry is kept, so it's included in DumpMountTable including all backend parameters.
+	MountEntry(ctx context.Context, entry *fstab.Entry) error
+
 	// Unmount removes the filesystem handler at the specified path.
 	// Returns an error if the path is not mounted or has child mounts.
 	Unmount(ctx context.Context, path string, force bool) error
 
+	// DumpMountTable returns the current mounts in the format used by the mount table.
+	DumpMountTable() *fstab.Table
+
+	// Usage returns the storage used by the mount containing path together with its quotas.
+	Usage(ctx context.Context, path string) (*data.Usage, error)
+
+	// CollectGarbage removes content no longer referenced within the mount containing path.
+	// With opts.DryRun set, orphans are only reported. A nil opts removes all orphans older than data.DefaultGCGracePeriod.
+	CollectGarbage(ctx context.Context, path string, opts *data.GCOptions) (*data.GCReport, error)
+
+	// Fsck compares the metadata of the mount containing path against its object storage and reports every discrepancy.
+	// Discrepancies are repaired or pruned as requested by opts. A nil opts only reports them.
+	Fsck(ctx context.Context, path string, opts *data.FsckOptions) (*data.FsckReport, error)
+
+	// Reindex rebuilds the metadata of the mount containing path from its object storage.
+	// Existing attributes are preserved. A nil opts uses the default concurrency and batch size.
+	Reindex(ctx context.Context, path string, opts *data.ReindexOptions) (*data.ReindexReport, error)
+
+	// Migrate copies all objects and metadata of the mount containing path to storage and metadata (nil for mounts without metadata),
+	// syncs all changes made in the meantime and switches the mount to the new backends. The mount remains usable during the copy.
+	Migrate(ctx context.Context, path string, storage backend.ObjectStorageBackend, metadata backend.MetadataBackend, opts *data.MigrateOptions) (*data.MigrateReport, error)
+
+	// Tier moves every file of the mount containing path to the tier selected by the tier policy of the mount.
+	// With opts.DryRun set, files are only reported. A nil opts moves all files.
+	Tier(ctx context.Context, path string, opts *data.TierOptions) (*data.TierReport, error)
+
 	// OpenFile opens a file with the specified access mode flags and returns a file handle.
 	// The returned File must be closed by the caller. Use flags to control access.
 	OpenFile(ctx context.Context, path string, flags data.AccessMode) (mount.Streamer, error)
@@ -62,9 +104,37 @@ type API interface {
 
 	// Rename moves or renames a file or directory from oldPath to newPath.
 	// Returns an error if the operation cannot be completed.
-	// This implementation uses a copy-and-delete strategy which works across different mounts
-	// but is not atomic and may not be optimal for large files.
+	// Renames within a single mount are performed atomically by the backends, if supported.
+	// Otherwise a streamed copy-and-delete strategy is used, which is not atomic.
 	Rename(ctx context.Context, oldPath string, newPath string) error
+
+	// Copy copies the file or directory at src to dst, streaming the content in chunks.
+	// Server-side copies are used, if both paths share a backend that supports them.
+	// A nil opts copies a single file without preserving any metadata.
+	Copy(ctx context.Context, src string, dst string, opts *data.CopyOptions) error
+
+	// Chmod changes the permission bits of the file or directory at path.
+	// Type bits of the existing mode are always preserved.
+	Chmod(ctx context.Context, path string, mode data.FileMode) error
+
+	// Chown changes the user and group ownership of the file or directory at path.
+	// A negative uid or gid leaves the respective value unchanged.
+	Chown(ctx context.Context, path string, uid, gid int64) error
+
+	// SetAttributes merges the specified attributes into the existing attributes at path.
+	SetAttributes(ctx context.Context, path string, attributes map[string]string) error
+
+	// RemoveAttribute removes a single attribute from the file or directory at path.
+	// Returns an error if the attribute doesn't exist.
+	RemoveAttribute(ctx context.Context, path string, key string) error
+
+	// SetTimes changes the access and modification time of the file or directory at path.
+	// A zero time leaves the respective value unchanged.
+	SetTimes(ctx context.Context, path string, accessTime, modifyTime time.Time) error
+
+	// UpdateChecksum recomputes the content checksum of the file at path and stores it within metadata.
+	// Returns data.ErrNotSupported if the mount containing path doesn't store checksums.
+	UpdateChecksum(ctx context.Context, path string) error
 }
 
 // Command represents an executable command within the virtual filesystem.
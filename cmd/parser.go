@@ -8,13 +8,26 @@ import (
 
 // Parser parses user-defined arguments into flags
 type Parser struct {
-	flagSet *CommandFlagSet
+	flagSet     *CommandFlagSet
+	longToName  map[string]string
+	shortToName map[string]string
 }
 
 func NewParser(flagSet *CommandFlagSet) *Parser {
-	return &Parser{
-		flagSet: flagSet,
+	cp := &Parser{
+		flagSet:     flagSet,
+		longToName:  make(map[string]string),
+		shortToName: make(map[string]string),
 	}
+
+	for flagName, flag := range flagSet.Flags {
+		cp.longToName[flag.Name] = flagName
+		if flag.Short != "" {
+			cp.shortToName[flag.Short] = flagName
+		}
+	}
+
+	return cp
 }
 
 func (cp *Parser) Parse(raw []string) (*CommandArgs, error) {
@@ -29,14 +42,8 @@ func (cp *Parser) Parse(raw []string) (*CommandArgs, error) {
 		}
 	}
 
-	longToName := make(map[string]string)
-	shortToName := make(map[string]string)
-	for flagName, flag := range cp.flagSet.Flags {
-		longToName[flag.Name] = flagName
-		if flag.Short != "" {
-			shortToName[flag.Short] = flagName
-		}
-	}
+	// Tracks flags explicitly set, so repeated flags replace their default instead of appending to it
+	explicit := make(map[string]bool)
 
 	for i := 0; i < len(raw); i++ {
 		arg := raw[i]
@@ -48,21 +55,25 @@ func (cp *Parser) Parse(raw []string) (*CommandArgs, error) {
 
 		if strings.HasPrefix(arg, "--") {
 			key, value, hasValue := parseLongFlag(arg)
-			flagName, exists := longToName[key]
+			flagName, exists := cp.longToName[key]
 			if !exists {
-				return nil, fmt.Errorf("unknown flag: --%s", key)
+				return nil, fmt.Errorf("unknown flag: --%s%s", key, cp.suggestLong(key))
 			}
 
 			flag := cp.flagSet.Flags[flagName]
-			if flag.Type == "bool" {
-				args.Flags[flagName] = true
-			} else if hasValue {
-				args.Flags[flagName] = coerce(value, flag.Type)
-			} else if i+1 < len(raw) && !strings.HasPrefix(raw[i+1], "-") {
-				args.Flags[flagName] = coerce(raw[i+1], flag.Type)
-				i++
-			} else {
-				return nil, fmt.Errorf("flag %s requires a value", key)
+			if flag.Type == FlagTypeBool && !hasValue {
+				value, hasValue = "true", true
+			} else if !hasValue {
+				if i+1 < len(raw) && cp.isFlagValue(raw[i+1], flag.Type) {
+					value, hasValue = raw[i+1], true
+					i++
+				} else {
+					return nil, fmt.Errorf("flag --%s requires a value", key)
+				}
+			}
+
+			if err := cp.setFlag(args, explicit, flagName, flag, value); err != nil {
+				return nil, err
 			}
 			continue
 		}
@@ -72,29 +83,34 @@ func (cp *Parser) Parse(raw []string) (*CommandArgs, error) {
 
 			for j, shortChar := range shortFlags {
 				shortStr := string(shortChar)
-				flagName, exists := shortToName[shortStr]
+				flagName, exists := cp.shortToName[shortStr]
 				if !exists {
-					return nil, fmt.Errorf("unknown flag: -%s", shortStr)
+					return nil, fmt.Errorf("unknown flag: -%s in '%s'", shortStr, arg)
 				}
 
 				flag := cp.flagSet.Flags[flagName]
 
-				if flag.Type == "bool" {
-					args.Flags[flagName] = true
-				} else {
-					var value string
-					if j+1 < len(shortFlags) {
-						value = shortFlags[j+1:]
-						args.Flags[flagName] = coerce(value, flag.Type)
-						break
-					} else if i+1 < len(raw) && !strings.HasPrefix(raw[i+1], "-") {
-						value = raw[i+1]
-						args.Flags[flagName] = coerce(value, flag.Type)
-						i++
-					} else {
-						return nil, fmt.Errorf("flag -%s requires a value", shortStr)
+				if flag.Type == FlagTypeBool {
+					if err := cp.setFlag(args, explicit, flagName, flag, "true"); err != nil {
+						return nil, err
 					}
+					continue
+				}
+
+				var value string
+				if j+len(string(shortChar)) < len(shortFlags) {
+					value = shortFlags[j+len(string(shortChar)):]
+				} else if i+1 < len(raw) && cp.isFlagValue(raw[i+1], flag.Type) {
+					value = raw[i+1]
+					i++
+				} else {
+					return nil, fmt.Errorf("flag -%s requires a value", shortStr)
+				}
+
+				if err := cp.setFlag(args, explicit, flagName, flag, value); err != nil {
+					return nil, err
 				}
+				break
 			}
 			continue
 		}
@@ -117,6 +133,56 @@ func (cp *Parser) Parse(raw []string) (*CommandArgs, error) {
 	return args, nil
 }
 
+// setFlag converts the raw value into the flag type and stores it.
+// Values for slice and multiple flags are accumulated instead of replaced.
+func (cp *Parser) setFlag(args *CommandArgs, explicit map[string]bool, flagName string, flag *CommandFlag, value string) error {
+	converted, err := coerce(value, flag.Type)
+	if err != nil {
+		return fmt.Errorf("invalid value '%s' for flag --%s: %w", value, flag.Name, err)
+	}
+
+	switch {
+	case flag.Type == FlagTypeStringSlice:
+		var values []string
+		if explicit[flagName] {
+			values, _ = args.Flags[flagName].([]string)
+		}
+		args.Flags[flagName] = append(values, converted.([]string)...)
+	case flag.Multiple && flag.Type == FlagTypeString:
+		var values []string
+		if explicit[flagName] {
+			values, _ = args.Flags[flagName].([]string)
+		}
+		args.Flags[flagName] = append(values, converted.(string))
+	case flag.Multiple && flag.Type == FlagTypeInt:
+		var values []int64
+		if explicit[flagName] {
+			values, _ = args.Flags[flagName].([]int64)
+		}
+		args.Flags[flagName] = append(values, converted.(int64))
+	default:
+		args.Flags[flagName] = converted
+	}
+
+	explicit[flagName] = true
+	return nil
+}
+
+// suggestLong returns a formatted hint with similar long flag names.
+func (cp *Parser) suggestLong(key string) string {
+	candidates := make([]string, 0, len(cp.flagSet.Flags))
+	for _, flag := range cp.flagSet.Flags {
+		candidates = append(candidates, flag.Name)
+	}
+
+	suggestions := Suggest(key, candidates)
+	if len(suggestions) == 0 {
+		return ""
+	}
+
+	return fmt.Sprintf(" (did you mean --%s?)", suggestions[0])
+}
+
 func parseLongFlag(arg string) (key, value string, hasValue bool) {
 	arg = strings.TrimPrefix(arg, "--")
 	if idx := strings.Index(arg, "="); idx >= 0 {
@@ -125,16 +191,100 @@ func parseLongFlag(arg string) (key, value string, hasValue bool) {
 	return arg, "", false
 }
 
-func coerce(value string, typeStr string) interface{} {
+// isFlagValue reports whether the next argument can be consumed as flag value.
+// Arguments starting with '-' are treated as flags, except negative numbers for int flags
+// and arguments for string flags, which aren't flags of the command (e.g. "grep -e --help").
+func (cp *Parser) isFlagValue(next string, typeStr string) bool {
+	if !strings.HasPrefix(next, "-") {
+		return true
+	}
+
+	switch typeStr {
+	case FlagTypeInt:
+		_, err := strconv.ParseInt(next, 10, 64)
+		return err == nil
+	case FlagTypeString:
+		return next != "--" && !cp.isDefinedFlag(next)
+	}
+
+	return false
+}
+
+// isDefinedFlag reports whether arg refers to a flag of the command.
+func (cp *Parser) isDefinedFlag(arg string) bool {
+	if strings.HasPrefix(arg, "--") {
+		key, _, _ := parseLongFlag(arg)
+		_, exists := cp.longToName[key]
+		return exists
+	}
+
+	for _, shortChar := range strings.TrimPrefix(arg, "-") {
+		_, exists := cp.shortToName[string(shortChar)]
+		return exists
+	}
+
+	return false
+}
+
+// valueFlag returns the flag of arg, which reads its value from the following argument.
+// Returns nil if arg is no flag, an unknown flag or already contains its value.
+func (cp *Parser) valueFlag(arg string) *CommandFlag {
+	if strings.HasPrefix(arg, "--") {
+		key, _, hasValue := parseLongFlag(arg)
+		flagName, exists := cp.longToName[key]
+		if !exists || hasValue || cp.flagSet.Flags[flagName].Type == FlagTypeBool {
+			return nil
+		}
+		return cp.flagSet.Flags[flagName]
+	}
+
+	if !strings.HasPrefix(arg, "-") || arg == "-" {
+		return nil
+	}
+
+	shortFlags := arg[1:]
+	for j, shortChar := range shortFlags {
+		flagName, exists := cp.shortToName[string(shortChar)]
+		if !exists {
+			return nil
+		}
+
+		flag := cp.flagSet.Flags[flagName]
+		if flag.Type == FlagTypeBool {
+			continue
+		}
+		// The remaining characters are the value of the flag
+		if j+len(string(shortChar)) < len(shortFlags) {
+			return nil
+		}
+		return flag
+	}
+
+	return nil
+}
+
+func coerce(value string, typeStr string) (any, error) {
 	switch typeStr {
-	case "string":
-		return value
-	case "int":
-		v, _ := strconv.ParseInt(value, 10, 64)
-		return v
-	case "bool":
-		return value == "true" || value == "1" || value == "yes"
+	case FlagTypeString:
+		return value, nil
+	case FlagTypeInt:
+		v, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("expected an integer")
+		}
+		return v, nil
+	case FlagTypeBool:
+		switch strings.ToLower(value) {
+		case "true", "1", "yes":
+			return true, nil
+		case "false", "0", "no":
+			return false, nil
+		default:
+			return nil, fmt.Errorf("expected a boolean")
+		}
+	case FlagTypeStringSlice:
+		return strings.Split(value, ","), nil
 	default:
-		return value
+		return nil, fmt.Errorf("unsupported flag type '%s'", typeStr)
 	}
 }
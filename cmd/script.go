@@ -0,0 +1,452 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"strconv"
+	"strings"
+)
+
+// MaxScriptDepth limits how deep scripts may execute other scripts.
+const MaxScriptDepth = 16
+
+// maxScriptLineSize limits the size of a single (joined) script line.
+const maxScriptLineSize = 1024 * 1024
+
+// ExecuteFunc executes a single command with its arguments.
+type ExecuteFunc func(ctx context.Context, writer io.Writer, args ...string) (int, error)
+
+// ScriptError reports the failure of a single line within a script.
+type ScriptError struct {
+	Line    int    // Line number where the failed statement starts
+	Command string // Name of the failed command (empty for syntax errors)
+	Code    int    // Exit code of the failed statement
+	Err     error  // Underlying error
+}
+
+// Error returns the error message prefixed with the line number.
+func (se *ScriptError) Error() string {
+	if se.Command == "" {
+		return fmt.Sprintf("line %d: %v", se.Line, se.Err)
+	}
+	return fmt.Sprintf("line %d: %s: %v", se.Line, se.Command, se.Err)
+}
+
+// Unwrap returns the underlying error.
+func (se *ScriptError) Unwrap() error {
+	return se.Err
+}
+
+// Script executes command files line by line.
+//
+// Supported syntax:
+//   - Comments starting with '#' and empty lines
+//   - Single and double quotes, backslash escapes and line continuation with a trailing '\'
+//   - Variable assignments (NAME=value) and expansion ($NAME, ${NAME}, $1..$9, $#, $?)
+//   - "set -e" / "set +e" to enable or disable fail-fast and "set -x" / "set +x" for tracing
+type Script struct {
+	execute   ExecuteFunc
+	variables map[string]string
+	failFast  bool
+	trace     bool
+	lastCode  int
+}
+
+type scriptContextKey struct{}
+
+// scriptContext is passed to nested scripts to inherit variables and limit recursion.
+type scriptContext struct {
+	depth     int
+	variables map[string]string
+}
+
+// WithScriptVariables returns a context, which passes the variables to scripts executed with it.
+// Variables already inherited from a parent script are overwritten.
+func WithScriptVariables(ctx context.Context, variables map[string]string) context.Context {
+	parent, _ := ctx.Value(scriptContextKey{}).(*scriptContext)
+
+	sc := &scriptContext{
+		variables: make(map[string]string),
+	}
+	if parent != nil {
+		sc.depth = parent.depth
+		maps.Copy(sc.variables, parent.variables)
+	}
+	maps.Copy(sc.variables, variables)
+
+	return context.WithValue(ctx, scriptContextKey{}, sc)
+}
+
+// NewScript creates a new script, which uses execute to run each command.
+func NewScript(execute ExecuteFunc) *Script {
+	return &Script{
+		execute:   execute,
+		variables: make(map[string]string),
+	}
+}
+
+// Run reads and executes the script from the reader.
+// Without fail-fast all lines are executed and every failure is returned as joined ScriptError.
+// With fail-fast, execution stops at the first failure, which is returned as ScriptError.
+// The returned exit code is the code of the last failed statement or ExitSuccess.
+func (s *Script) Run(ctx context.Context, reader io.Reader, writer io.Writer) (int, error) {
+	depth := 0
+	if sc, ok := ctx.Value(scriptContextKey{}).(*scriptContext); ok {
+		depth = sc.depth
+		maps.Copy(s.variables, sc.variables)
+	}
+	if depth >= MaxScriptDepth {
+		return ExitFailure, fmt.Errorf("maximum script depth of %d exceeded", MaxScriptDepth)
+	}
+
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxScriptLineSize)
+
+	errs := make([]error, 0)
+	code := ExitSuccess
+
+	var pending strings.Builder
+	number, start := 0, 0
+	for scanner.Scan() {
+		number++
+		line := scanner.Text()
+		if pending.Len() == 0 {
+			start = number
+		}
+		// Join lines ending with a single backslash
+		if trimmed := strings.TrimRight(line, " \t"); strings.HasSuffix(trimmed, "\\") && !strings.HasSuffix(trimmed, "\\\\") {
+			pending.WriteString(trimmed[:len(trimmed)-1])
+			continue
+		}
+		pending.WriteString(line)
+
+		statement := pending.String()
+		pending.Reset()
+
+		if err := ctx.Err(); err != nil {
+			return ExitFailure, &ScriptError{Line: start, Code: ExitFailure, Err: err}
+		}
+
+		nested := context.WithValue(ctx, scriptContextKey{}, &scriptContext{
+			depth:     depth + 1,
+			variables: maps.Clone(s.variables),
+		})
+
+		name, lineCode, err := s.runStatement(nested, statement, writer)
+		s.lastCode = lineCode
+		if err == nil {
+			continue
+		}
+
+		serr := &ScriptError{Line: start, Command: name, Code: lineCode, Err: err}
+		code = lineCode
+		if s.failFast {
+			return code, serr
+		}
+		errs = append(errs, serr)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return ExitFailure, &ScriptError{Line: number + 1, Code: ExitFailure, Err: err}
+	}
+	if pending.Len() > 0 {
+		serr := &ScriptError{Line: start, Code: ExitUsage, Err: fmt.Errorf("unexpected end of script after line continuation")}
+		if s.failFast {
+			return ExitUsage, serr
+		}
+		errs = append(errs, serr)
+		code = ExitUsage
+	}
+
+	if len(errs) > 0 {
+		return code, errors.Join(errs...)
+	}
+
+	return ExitSuccess, nil
+}
+
+// runStatement executes a single (joined) line and returns the command name, exit code and error.
+func (s *Script) runStatement(ctx context.Context, statement string, writer io.Writer) (string, int, error) {
+	tokens, err := s.split(statement)
+	if err != nil {
+		return "", ExitUsage, err
+	}
+	if len(tokens) == 0 {
+		return "", ExitSuccess, nil
+	}
+
+	if tokens[0].assignment {
+		if len(tokens) > 1 {
+			return "", ExitUsage, fmt.Errorf("unexpected arguments after assignment to '%s'", tokens[0].name)
+		}
+
+		s.variables[tokens[0].name] = tokens[0].value
+		return "", ExitSuccess, nil
+	}
+
+	args := make([]string, len(tokens))
+	for i, t := range tokens {
+		args[i] = t.value
+	}
+
+	if s.trace {
+		fmt.Fprintf(writer, "+ %s\n", strings.Join(args, " "))
+	}
+
+	if args[0] == "set" {
+		code, err := s.set(args[1:])
+		return args[0], code, err
+	}
+
+	code, err := s.execute(ctx, writer, args...)
+	if err == nil && code != ExitSuccess {
+		err = fmt.Errorf("exited with code %d", code)
+	}
+
+	return args[0], code, err
+}
+
+// set changes script options like fail-fast (-e) and tracing (-x).
+func (s *Script) set(args []string) (int, error) {
+	if len(args) == 0 {
+		return ExitUsage, fmt.Errorf("missing option")
+	}
+
+	for _, arg := range args {
+		if len(arg) < 2 || (arg[0] != '-' && arg[0] != '+') {
+			return ExitUsage, fmt.Errorf("invalid option '%s'", arg)
+		}
+
+		enable := arg[0] == '-'
+		for _, option := range arg[1:] {
+			switch option {
+			case 'e':
+				s.failFast = enable
+			case 'x':
+				s.trace = enable
+			default:
+				return ExitUsage, fmt.Errorf("invalid option '%c'", option)
+			}
+		}
+	}
+
+	return ExitSuccess, nil
+}
+
+// lookup returns the value of a variable, including the special variables "?" and "#".
+func (s *Script) lookup(name string) string {
+	if name == "?" {
+		return strconv.Itoa(s.lastCode)
+	}
+	if name == "#" {
+		if value, exists := s.variables[name]; exists {
+			return value
+		}
+		return "0"
+	}
+
+	return s.variables[name]
+}
+
+// scriptToken is a single word of a statement after quote removal and expansion.
+type scriptToken struct {
+	value      string
+	name       string // Variable name if this token is an assignment
+	assignment bool
+}
+
+// split splits a statement into tokens, removes quotes and expands variables.
+func (s *Script) split(statement string) ([]scriptToken, error) {
+	runes := []rune(statement)
+	tokens := make([]scriptToken, 0)
+
+	var current strings.Builder
+	var token scriptToken
+	inToken := false
+	// Whether the token only consists of unquoted identifier characters
+	// Only the first token of a statement is a candidate for an assignment
+	plain := true
+
+	flush := func() {
+		if inToken {
+			token.value = current.String()
+			tokens = append(tokens, token)
+		}
+		current.Reset()
+		token = scriptToken{}
+		inToken = false
+		plain = true
+	}
+
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+
+		switch {
+		case r == ' ' || r == '\t':
+			flush()
+
+		case r == '#' && !inToken:
+			flush()
+			return tokens, nil
+
+		case r == '\\':
+			inToken, plain = true, false
+			if i+1 < len(runes) {
+				i++
+				current.WriteRune(runes[i])
+			}
+
+		case r == '\'':
+			inToken, plain = true, false
+			end := indexRune(runes, i+1, '\'')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated single quote")
+			}
+			current.WriteString(string(runes[i+1 : end]))
+			i = end
+
+		case r == '"':
+			inToken, plain = true, false
+			end, err := s.expandDoubleQuoted(runes, i+1, &current)
+			if err != nil {
+				return nil, err
+			}
+			i = end
+
+		case r == '$':
+			inToken, plain = true, false
+			next, err := s.expandVariable(runes, i, &current)
+			if err != nil {
+				return nil, err
+			}
+			i = next
+
+		case r == '=' && plain && inToken && len(tokens) == 0 && !token.assignment && isIdentifier(current.String()):
+			token.assignment = true
+			token.name = current.String()
+			current.Reset()
+			plain = false
+
+		default:
+			inToken = true
+			if plain && !isIdentifierRune(r, current.Len() == 0) {
+				plain = false
+			}
+			current.WriteRune(r)
+		}
+	}
+
+	flush()
+	return tokens, nil
+}
+
+// expandDoubleQuoted writes the content of a double quoted string starting at start.
+// Returns the index of the closing quote.
+func (s *Script) expandDoubleQuoted(runes []rune, start int, builder *strings.Builder) (int, error) {
+	for i := start; i < len(runes); i++ {
+		switch runes[i] {
+		case '"':
+			return i, nil
+		case '\\':
+			// Only a few characters can be escaped within double quotes
+			if i+1 < len(runes) && strings.ContainsRune("\"\\$", runes[i+1]) {
+				i++
+			}
+			builder.WriteRune(runes[i])
+		case '$':
+			next, err := s.expandVariable(runes, i, builder)
+			if err != nil {
+				return 0, err
+			}
+			i = next
+		default:
+			builder.WriteRune(runes[i])
+		}
+	}
+
+	return 0, fmt.Errorf("unterminated double quote")
+}
+
+// expandVariable writes the value of the variable starting with '$' at start.
+// Returns the index of the last rune belonging to the variable reference.
+func (s *Script) expandVariable(runes []rune, start int, builder *strings.Builder) (int, error) {
+	i := start + 1
+	if i >= len(runes) {
+		builder.WriteRune('$')
+		return start, nil
+	}
+
+	switch r := runes[i]; {
+	case r == '{':
+		end := indexRune(runes, i+1, '}')
+		if end < 0 {
+			return 0, fmt.Errorf("unterminated variable reference")
+		}
+
+		name := string(runes[i+1 : end])
+		if !isIdentifier(name) && !isPositional(name) && name != "?" && name != "#" {
+			return 0, fmt.Errorf("invalid variable name '%s'", name)
+		}
+
+		builder.WriteString(s.lookup(name))
+		return end, nil
+
+	case r == '?' || r == '#' || (r >= '0' && r <= '9'):
+		builder.WriteString(s.lookup(string(r)))
+		return i, nil
+
+	case isIdentifierRune(r, true):
+		end := i
+		for end+1 < len(runes) && isIdentifierRune(runes[end+1], false) {
+			end++
+		}
+
+		builder.WriteString(s.lookup(string(runes[i : end+1])))
+		return end, nil
+	}
+
+	// A single '$' without valid name is kept as is
+	builder.WriteRune('$')
+	return start, nil
+}
+
+// indexRune returns the index of the first r in runes starting at start, or -1.
+func indexRune(runes []rune, start int, r rune) int {
+	for i := start; i < len(runes); i++ {
+		if runes[i] == r {
+			return i
+		}
+	}
+	return -1
+}
+
+// isIdentifier checks if name is a valid variable name.
+func isIdentifier(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, r := range name {
+		if !isIdentifierRune(r, i == 0) {
+			return false
+		}
+	}
+	return true
+}
+
+// isIdentifierRune checks if r can be used within a variable name.
+func isIdentifierRune(r rune, first bool) bool {
+	if r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') {
+		return true
+	}
+	return !first && r >= '0' && r <= '9'
+}
+
+// isPositional checks if name references a positional argument.
+func isPositional(name string) bool {
+	_, err := strconv.Atoi(name)
+	return err == nil && !strings.HasPrefix(name, "-")
+}
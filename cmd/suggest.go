@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"sort"
+	"strings"
+)
+
+// Suggest returns candidates similar to input, ordered by similarity.
+// A candidate is similar if it starts with input or is within a small edit distance.
+func Suggest(input string, candidates []string) []string {
+	if input == "" {
+		return nil
+	}
+
+	type match struct {
+		name     string
+		distance int
+	}
+
+	threshold := max(1, len(input)/3)
+	matches := make([]match, 0)
+	for _, candidate := range candidates {
+		if candidate == input {
+			continue
+		}
+
+		distance := editDistance(strings.ToLower(input), strings.ToLower(candidate))
+		if distance <= threshold || strings.HasPrefix(candidate, input) {
+			matches = append(matches, match{name: candidate, distance: distance})
+		}
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].distance != matches[j].distance {
+			return matches[i].distance < matches[j].distance
+		}
+		return matches[i].name < matches[j].name
+	})
+
+	suggestions := make([]string, 0, len(matches))
+	for _, m := range matches {
+		suggestions = append(suggestions, m.name)
+	}
+
+	return suggestions
+}
+
+// editDistance calculates the optimal string alignment distance between a and b,
+// which counts insertions, deletions, substitutions and adjacent transpositions.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	d := make([][]int, len(ra)+1)
+	for i := range d {
+		d[i] = make([]int, len(rb)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+
+	return d[len(ra)][len(rb)]
+}
@@ -0,0 +1,612 @@
+package vfs_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mwantia/vfs"
+	"github.com/mwantia/vfs/cmd"
+	"github.com/mwantia/vfs/data"
+	"github.com/mwantia/vfs/log"
+	"github.com/mwantia/vfs/mount"
+	"github.com/mwantia/vfs/mount/backend"
+	"github.com/mwantia/vfs/mount/backend/ephemeral"
+	"github.com/mwantia/vfs/mount/backend/sqlite"
+)
+
+type testCountCommand struct {
+	args *cmd.CommandArgs
+}
+
+func (*testCountCommand) Name() string        { return "count" }
+func (*testCountCommand) Description() string { return "Count things" }
+func (*testCountCommand) Usage() string       { return "count [OPTIONS]" }
+
+func (tc *testCountCommand) Execute(ctx context.Context, api cmd.API, args *cmd.CommandArgs, writer io.Writer) (int, error) {
+	tc.args = args
+	return cmd.ExitSuccess, nil
+}
+
+func (*testCountCommand) GetFlags() *cmd.CommandFlagSet {
+	return &cmd.CommandFlagSet{
+		Flags: map[string]*cmd.CommandFlag{
+			"number": {
+				Name:        "number",
+				Short:       "n",
+				Type:        cmd.FlagTypeInt,
+				Default:     int64(10),
+				Description: "number of things",
+			},
+			"tag": {
+				Name:        "tag",
+				Type:        cmd.FlagTypeString,
+				Multiple:    true,
+				Description: "tag to apply",
+			},
+		},
+	}
+}
+
+// TestExecute_HelpAndValidation verifies help output, exit codes and flag validation of commands.
+func TestExecute_HelpAndValidation(t *testing.T) {
+	ctx := t.Context()
+	fs, err := vfs.NewVirtualFileSystem(vfs.WithLogLevel(log.Error))
+	if err != nil {
+		t.Fatalf("Failed to initialize vfs: %v", err)
+	}
+
+	count := &testCountCommand{}
+	if err := fs.RegisterCommand(count); err != nil {
+		t.Fatalf("Failed to register command: %v", err)
+	}
+
+	var buffer bytes.Buffer
+	if code, err := fs.Execute(ctx, &buffer, "help"); err != nil || code != cmd.ExitSuccess {
+		t.Fatalf("Expected help to succeed, got code=%d err=%v", code, err)
+	}
+	if !strings.Contains(buffer.String(), "count") || !strings.Contains(buffer.String(), "Count things") {
+		t.Errorf("Expected command list to contain 'count', got %q", buffer.String())
+	}
+
+	buffer.Reset()
+	if code, err := fs.Execute(ctx, &buffer, "count", "--help"); err != nil || code != cmd.ExitSuccess {
+		t.Fatalf("Expected --help to succeed, got code=%d err=%v", code, err)
+	}
+	if !strings.Contains(buffer.String(), "-n, --number <int>") || !strings.Contains(buffer.String(), "(default: 10)") {
+		t.Errorf("Expected usage with typed flag and default, got %q", buffer.String())
+	}
+
+	code, err := fs.Execute(ctx, &buffer, "cuont")
+	if code != cmd.ExitNotFound || err == nil || !strings.Contains(err.Error(), "did you mean 'count'") {
+		t.Errorf("Expected not-found with suggestion, got code=%d err=%v", code, err)
+	}
+
+	code, err = fs.Execute(ctx, &buffer, "count", "--nubmer", "5")
+	if code != cmd.ExitUsage || err == nil || !strings.Contains(err.Error(), "did you mean --number") {
+		t.Errorf("Expected usage error with flag suggestion, got code=%d err=%v", code, err)
+	}
+
+	if code, err := fs.Execute(ctx, &buffer, "count", "-n", "abc"); code != cmd.ExitUsage || err == nil {
+		t.Errorf("Expected usage error for invalid int value, got code=%d err=%v", code, err)
+	}
+
+	if _, err := fs.Execute(ctx, &buffer, "count", "-n", "-3", "--tag", "a", "--tag=b"); err != nil {
+		t.Fatalf("Expected valid flags to parse, got %v", err)
+	}
+	if count.args.Flags["number"] != int64(-3) {
+		t.Errorf("Expected number=-3, got %v", count.args.Flags["number"])
+	}
+	if tags, _ := count.args.Flags["tag"].([]string); len(tags) != 2 {
+		t.Errorf("Expected two tags, got %v", count.args.Flags["tag"])
+	}
+
+	// Values of string flags never request help, while flags of the command are never consumed as values
+	buffer.Reset()
+	if _, err := fs.Execute(ctx, &buffer, "count", "--tag", "--help"); err != nil {
+		t.Fatalf("Expected --help to be parsed as flag value, got %v", err)
+	}
+	if tags, _ := count.args.Flags["tag"].([]string); len(tags) != 1 || tags[0] != "--help" {
+		t.Errorf("Expected tag '--help', got %v", count.args.Flags["tag"])
+	}
+	if strings.Contains(buffer.String(), "Usage:") {
+		t.Errorf("Expected no usage for flag value '--help', got %q", buffer.String())
+	}
+	if code, err := fs.Execute(ctx, &buffer, "count", "--tag", "--number", "5"); code != cmd.ExitUsage || err == nil {
+		t.Errorf("Expected usage error for missing tag value, got code=%d err=%v", code, err)
+	}
+}
+
+// TestExecute_Checksums verifies that checksums are stored on close and detected as corrupted by verify.
+func TestExecute_Checksums(t *testing.T) {
+	ctx := t.Context()
+	fs, err := vfs.NewVirtualFileSystem(vfs.WithLogLevel(log.Error))
+	if err != nil {
+		t.Fatalf("Failed to initialize vfs: %v", err)
+	}
+
+	storage := ephemeral.NewEphemeralBackend()
+	if err := fs.Mount(ctx, "/", storage, mount.WithMetadata(storage)); err != nil {
+		t.Fatalf("Failed to mount: %v", err)
+	}
+	defer fs.Unmount(ctx, "/", false)
+
+	streamer, err := fs.OpenFile(ctx, "/hello.txt", data.AccessModeWrite|data.AccessModeCreate)
+	if err != nil {
+		t.Fatalf("Open for write failed: %v", err)
+	}
+	if _, err := streamer.Write([]byte("hello world")); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if err := streamer.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	const expected = "b94d27b9934d3e08a52e52d7da7dabfac484efe37a5380ee9088f7ace2efcde9"
+	meta, err := fs.StatMetadata(ctx, "/hello.txt")
+	if err != nil {
+		t.Fatalf("Stat failed: %v", err)
+	}
+	if checksum, exists := meta.GetChecksum(); !exists || checksum.Value != expected {
+		t.Fatalf("Expected stored checksum %s, got %q", expected, meta.GetAttribute(data.AttributeChecksum, ""))
+	}
+
+	var buffer bytes.Buffer
+	if code, err := fs.Execute(ctx, &buffer, "sha256sum", "/hello.txt"); err != nil || code != cmd.ExitSuccess {
+		t.Fatalf("Expected sha256sum to succeed, got code=%d err=%v", code, err)
+	}
+	if buffer.String() != expected+"  /hello.txt\n" {
+		t.Errorf("Unexpected sha256sum output %q", buffer.String())
+	}
+
+	if code, err := fs.Execute(ctx, &buffer, "verify", "/"); err != nil || code != cmd.ExitSuccess {
+		t.Fatalf("Expected verify to succeed, got code=%d err=%v", code, err)
+	}
+
+	// Writes without a streamer only mark the checksum as outdated, which is recomputed by verify
+	if _, err := fs.WriteFile(ctx, "/hello.txt", 6, []byte("there")); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	if meta, err := fs.StatMetadata(ctx, "/hello.txt"); err != nil || meta.HasAttribute(data.AttributeChecksum) {
+		t.Fatalf("Expected outdated checksum to be removed by WriteFile, got %v (%v)", meta, err)
+	}
+	for _, expected := range []string{"0 verified, 1 updated", "1 verified, 0 updated"} {
+		buffer.Reset()
+		if code, err := fs.Execute(ctx, &buffer, "verify", "/"); err != nil || code != cmd.ExitSuccess {
+			t.Fatalf("Expected verify to succeed after WriteFile, got code=%d err=%v", code, err)
+		}
+		if !strings.Contains(buffer.String(), expected) {
+			t.Errorf("Expected verify output to contain %q, got %q", expected, buffer.String())
+		}
+	}
+
+	// Modify content behind the back of the vfs to simulate corruption
+	if _, err := storage.WriteObject(ctx, "", "hello.txt", 0, []byte("j")); err != nil {
+		t.Fatalf("Failed to corrupt object: %v", err)
+	}
+
+	buffer.Reset()
+	code, err := fs.Execute(ctx, &buffer, "verify", "/")
+	if code != cmd.ExitFailure || err == nil {
+		t.Errorf("Expected verify to fail, got code=%d err=%v", code, err)
+	}
+	if !strings.Contains(buffer.String(), "/hello.txt: FAILED") {
+		t.Errorf("Expected corrupted file to be reported, got %q", buffer.String())
+	}
+}
+
+// TestExecute_MetadataCommands verifies chmod, chown, setattr, getattr and tag on backends with metadata.
+func TestExecute_MetadataCommands(t *testing.T) {
+	for _, name := range []string{"ephemeral", "sqlite"} {
+		t.Run(name, func(tst *testing.T) {
+			ctx := tst.Context()
+			fs, err := vfs.NewVirtualFileSystem(vfs.WithLogLevel(log.Error))
+			if err != nil {
+				tst.Fatalf("Failed to initialize vfs: %v", err)
+			}
+
+			switch name {
+			case "ephemeral":
+				storage := ephemeral.NewEphemeralBackend()
+				err = fs.Mount(ctx, "/", storage, mount.WithMetadata(storage))
+			case "sqlite":
+				storage, serr := sqlite.NewSQLiteBackend(":memory:")
+				if serr != nil {
+					tst.Fatalf("Failed to create backend: %v", serr)
+				}
+				err = fs.Mount(ctx, "/", storage, mount.WithMetadata(storage))
+			}
+			if err != nil {
+				tst.Fatalf("Failed to mount: %v", err)
+			}
+			defer fs.Unmount(ctx, "/", false)
+
+			if err := fs.CreateDirectory(ctx, "/docs"); err != nil {
+				tst.Fatalf("CreateDirectory failed: %v", err)
+			}
+			if _, err := fs.OpenFile(ctx, "/docs/a.txt", data.AccessModeWrite|data.AccessModeCreate); err != nil {
+				tst.Fatalf("OpenFile failed: %v", err)
+			}
+			if err := fs.CloseFile(ctx, "/docs/a.txt", false); err != nil {
+				tst.Fatalf("CloseFile failed: %v", err)
+			}
+
+			var buffer bytes.Buffer
+			for _, args := range [][]string{
+				{"chmod", "-R", "640", "/docs"},
+				{"chmod", "u+x,o+r", "/docs/a.txt"},
+				{"chown", "1000:100", "/docs/a.txt"},
+				{"setattr", "/docs/a.txt", "owner=alice", "team=core"},
+				{"setattr", "-x", "team", "/docs/a.txt"},
+				{"tag", "-R", "/docs", "important", "draft"},
+				{"tag", "-d", "/docs/a.txt", "draft"},
+			} {
+				if code, err := fs.Execute(ctx, &buffer, args...); err != nil || code != cmd.ExitSuccess {
+					tst.Fatalf("Expected %v to succeed, got code=%d err=%v output=%q", args, code, err, buffer.String())
+				}
+			}
+
+			meta, err := fs.StatMetadata(ctx, "/docs/a.txt")
+			if err != nil {
+				tst.Fatalf("Stat failed: %v", err)
+			}
+			if meta.Mode.Perm() != 0744 || !meta.Mode.IsRegular() {
+				tst.Errorf("Expected mode 0744, got %s", meta.Mode)
+			}
+			if meta.UID != 1000 || meta.GID != 100 {
+				tst.Errorf("Expected owner 1000:100, got %d:%d", meta.UID, meta.GID)
+			}
+			if meta.GetAttribute("owner", "") != "alice" || meta.HasAttribute("team") {
+				tst.Errorf("Unexpected attributes %v", meta.Attributes)
+			}
+			if tags := meta.GetTags(); len(tags) != 1 || tags[0] != "important" {
+				tst.Errorf("Expected tags [important], got %v", tags)
+			}
+
+			dir, err := fs.StatMetadata(ctx, "/docs")
+			if err != nil {
+				tst.Fatalf("Stat failed: %v", err)
+			}
+			if dir.Mode.Perm() != 0640 || !dir.Mode.IsDir() {
+				tst.Errorf("Expected directory mode 0640, got %s", dir.Mode)
+			}
+
+			buffer.Reset()
+			if _, err := fs.Execute(ctx, &buffer, "getattr", "-n", "owner", "/docs/a.txt"); err != nil {
+				tst.Fatalf("getattr failed: %v", err)
+			}
+			if !strings.Contains(buffer.String(), "owner=alice") {
+				tst.Errorf("Expected getattr output to contain owner, got %q", buffer.String())
+			}
+
+			modified := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+			if err := fs.SetTimes(ctx, "/docs/a.txt", time.Time{}, modified); err != nil {
+				tst.Fatalf("SetTimes failed: %v", err)
+			}
+			if meta, _ := fs.StatMetadata(ctx, "/docs/a.txt"); !meta.ModifyTime.Equal(modified) {
+				tst.Errorf("Expected modify time %v, got %v", modified, meta.ModifyTime)
+			}
+
+			if err := fs.RemoveAttribute(ctx, "/docs/a.txt", "missing"); err == nil {
+				tst.Errorf("Expected error when removing missing attribute")
+			}
+		})
+	}
+}
+
+// TestExecute_StreamCommands verifies head, tail (including follow) and grep output.
+func TestExecute_StreamCommands(t *testing.T) {
+	ctx := t.Context()
+	fs, err := vfs.NewVirtualFileSystem(vfs.WithLogLevel(log.Error))
+	if err != nil {
+		t.Fatalf("Failed to initialize vfs: %v", err)
+	}
+
+	storage := ephemeral.NewEphemeralBackend()
+	if err := fs.Mount(ctx, "/", storage, mount.WithMetadata(storage)); err != nil {
+		t.Fatalf("Failed to mount: %v", err)
+	}
+	defer fs.Unmount(ctx, "/", false)
+
+	if err := fs.CreateDirectory(ctx, "/logs"); err != nil {
+		t.Fatalf("CreateDirectory failed: %v", err)
+	}
+
+	content := "alpha\nBeta\ngamma\ndelta\nepsilon\n"
+	streamer, err := fs.OpenFile(ctx, "/logs/app.log", data.AccessModeWrite|data.AccessModeCreate)
+	if err != nil {
+		t.Fatalf("Open for write failed: %v", err)
+	}
+	if _, err := streamer.Write([]byte(content)); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if err := streamer.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	tests := []struct {
+		args     []string
+		code     int
+		expected string
+	}{
+		{[]string{"head", "-n", "2", "/logs/app.log"}, cmd.ExitSuccess, "alpha\nBeta\n"},
+		{[]string{"head", "-c", "3", "/logs/app.log"}, cmd.ExitSuccess, "alp"},
+		{[]string{"tail", "-n", "2", "/logs/app.log"}, cmd.ExitSuccess, "delta\nepsilon\n"},
+		{[]string{"tail", "-n", "10", "/logs/app.log"}, cmd.ExitSuccess, content},
+		{[]string{"tail", "-c", "4", "/logs/app.log"}, cmd.ExitSuccess, "lon\n"},
+		{[]string{"grep", "-n", "-i", "^b", "/logs/app.log"}, cmd.ExitSuccess, "2:Beta\n"},
+		{[]string{"grep", "-r", "-l", "ta$", "/"}, cmd.ExitSuccess, "/logs/app.log\n"},
+		{[]string{"grep", "zeta", "/logs/app.log"}, cmd.ExitFailure, ""},
+		{[]string{"grep", "(", "/logs/app.log"}, cmd.ExitUsage, ""},
+	}
+
+	for _, test := range tests {
+		var buffer bytes.Buffer
+		code, _ := fs.Execute(ctx, &buffer, test.args...)
+		if code != test.code {
+			t.Errorf("Expected %v to exit with %d, got %d", test.args, test.code, code)
+		}
+		if buffer.String() != test.expected {
+			t.Errorf("Expected %v to output %q, got %q", test.args, test.expected, buffer.String())
+		}
+	}
+
+	// Files held open by another handle can be read, without closing the shared streamer
+	writer, err := fs.OpenFile(ctx, "/logs/app.log", data.AccessModeWrite)
+	if err != nil {
+		t.Fatalf("Open for write failed: %v", err)
+	}
+	for _, args := range [][]string{
+		{"head", "-n", "1", "/logs/app.log"},
+		{"tail", "-n", "1", "/logs/app.log"},
+		{"grep", "alpha", "/logs/app.log"},
+	} {
+		var buffer bytes.Buffer
+		if code, err := fs.Execute(ctx, &buffer, args...); code != cmd.ExitSuccess {
+			t.Errorf("Expected %v to read the opened file, got %d: %v (%q)", args, code, err, buffer.String())
+		}
+	}
+	if _, err := writer.Write([]byte(content[:5])); err != nil {
+		t.Errorf("Expected the other handle to remain usable, got %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	// Follow the file while appending new content
+	followCtx, cancel := context.WithTimeout(ctx, 500*time.Millisecond)
+	defer cancel()
+
+	var buffer bytes.Buffer
+	done := make(chan error, 1)
+	go func() {
+		_, err := fs.Execute(followCtx, &buffer, "tail", "-f", "-s", "10", "-n", "1", "/logs/app.log")
+		done <- err
+	}()
+
+	time.Sleep(100 * time.Millisecond)
+	if _, err := fs.WriteFile(ctx, "/logs/app.log", int64(len(content)), []byte("zeta\n")); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+
+	if err := <-done; err != nil {
+		t.Fatalf("tail -f failed: %v (%q)", err, buffer.String())
+	}
+	if buffer.String() != "epsilon\nzeta\n" {
+		t.Errorf("Expected followed output, got %q", buffer.String())
+	}
+}
+
+func TestExecute_Script(t *testing.T) {
+	ctx := t.Context()
+	fs, err := vfs.NewVirtualFileSystem(vfs.WithLogLevel(log.Error))
+	if err != nil {
+		t.Fatalf("Failed to initialize vfs: %v", err)
+	}
+
+	storage := ephemeral.NewEphemeralBackend()
+	if err := fs.Mount(ctx, "/", storage, mount.WithMetadata(storage)); err != nil {
+		t.Fatalf("Failed to mount: %v", err)
+	}
+	defer fs.Unmount(ctx, "/", false)
+
+	script := `# Provision a new tenant
+TENANT=acme
+ROOT="/tenants/${TENANT}"
+
+mkdir -p $ROOT/data \
+	$ROOT/logs
+echo -n 'created $ROOT:' "$ROOT" # trailing comment
+echo
+`
+
+	var buffer bytes.Buffer
+	code, err := fs.ExecuteScript(ctx, strings.NewReader(script), &buffer)
+	if err != nil || code != cmd.ExitSuccess {
+		t.Fatalf("ExecuteScript failed with %d: %v", code, err)
+	}
+	if buffer.String() != "created $ROOT: /tenants/acme\n" {
+		t.Errorf("Unexpected script output %q", buffer.String())
+	}
+	for _, path := range []string{"/tenants/acme/data", "/tenants/acme/logs"} {
+		meta, err := fs.StatMetadata(ctx, path)
+		if err != nil || !meta.Mode.IsDir() {
+			t.Errorf("Expected directory %s to exist: %v", path, err)
+		}
+	}
+
+	// Fail fast must stop at the first failure and report its line
+	buffer.Reset()
+	code, err = fs.ExecuteScript(ctx, strings.NewReader("set -e\necho first\nmissing-command\necho never\n"), &buffer)
+	var serr *cmd.ScriptError
+	if !errors.As(err, &serr) || serr.Line != 3 {
+		t.Fatalf("Expected script error on line 3, got %v", err)
+	}
+	if code != cmd.ExitNotFound {
+		t.Errorf("Expected exit code %d, got %d", cmd.ExitNotFound, code)
+	}
+	if buffer.String() != "first\n" {
+		t.Errorf("Expected execution to stop after failure, got %q", buffer.String())
+	}
+
+	// Without fail fast all statements run and every failure is reported
+	buffer.Reset()
+	code, err = fs.ExecuteScript(ctx, strings.NewReader("mkdir /a/b\necho $?\nmkdir /c/d\necho done\n"), &buffer)
+	if err == nil || code != cmd.ExitFailure {
+		t.Fatalf("Expected script to fail, got %d: %v", code, err)
+	}
+	if !strings.Contains(err.Error(), "line 1") || !strings.Contains(err.Error(), "line 3") {
+		t.Errorf("Expected errors for line 1 and 3, got %v", err)
+	}
+	if !strings.Contains(buffer.String(), "\n1\n") {
+		t.Errorf("Expected $? to expand to the last exit code, got %q", buffer.String())
+	}
+	if !strings.HasSuffix(buffer.String(), "done\n") {
+		t.Errorf("Expected script to continue after failure, got %q", buffer.String())
+	}
+
+	// Source a script stored within the filesystem with positional arguments
+	streamer, err := fs.OpenFile(ctx, "/tenants/setup.sh", data.AccessModeWrite|data.AccessModeCreate)
+	if err != nil {
+		t.Fatalf("Open for write failed: %v", err)
+	}
+	if _, err := streamer.Write([]byte("set -e\nmkdir -p /tenants/$1\necho $# $1 $2\n")); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if err := streamer.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	for _, name := range []string{"source", "run"} {
+		buffer.Reset()
+		code, err = fs.Execute(ctx, &buffer, name, "/tenants/setup.sh", name+"-tenant", "extra")
+		if err != nil || code != cmd.ExitSuccess {
+			t.Fatalf("%s failed with %d: %v", name, code, err)
+		}
+		if buffer.String() != "2 "+name+"-tenant extra\n" {
+			t.Errorf("Unexpected %s output %q", name, buffer.String())
+		}
+		if _, err := fs.StatMetadata(ctx, "/tenants/"+name+"-tenant"); err != nil {
+			t.Errorf("Expected %s to create tenant directory: %v", name, err)
+		}
+	}
+}
+
+// registerSecretive registers the backend used to verify secret parameters only once, since tests may run repeatedly
+var (
+	registerSecretive sync.Once
+	secretiveParams   = make(chan backend.FactoryParams, 1)
+)
+
+func TestExecute_MountCommands(t *testing.T) {
+	ctx := t.Context()
+	dir := t.TempDir()
+
+	fs, err := vfs.NewVirtualFileSystem(vfs.WithLogLevel(log.Error))
+	if err != nil {
+		t.Fatalf("Failed to initialize vfs: %v", err)
+	}
+	defer fs.Shutdown(ctx)
+
+	var buffer bytes.Buffer
+	steps := [][]string{
+		{"mount", "-t", "ephemeral", "/"},
+		{"mount", "-m", "--namespace", "team", "sqlite::memory:", "/db"},
+		{"mount", "-r", "dir://" + dir, "/srv"},
+		{"mount", "-t", "sqlite", "-o", "path=:memory:", "--no-nesting", "/cache"},
+	}
+	for _, args := range steps {
+		if code, err := fs.Execute(ctx, &buffer, args...); err != nil || code != cmd.ExitSuccess {
+			t.Fatalf("Expected %v to succeed, got code %d: %v", args, code, err)
+		}
+	}
+
+	buffer.Reset()
+	if code, err := fs.Execute(ctx, &buffer, "mount"); err != nil || code != cmd.ExitSuccess {
+		t.Fatalf("Expected mount listing to succeed, got code %d: %v", code, err)
+	}
+	for _, expected := range []string{
+		"ephemeral on / type ephemeral (rw)",
+		"sqlite::memory: on /db type sqlite (rw,namespace=team,metadata=primary)",
+		"dir://" + dir + " on /srv type direct (ro)",
+		"sqlite on /cache type sqlite (rw,nonesting)",
+	} {
+		if !strings.Contains(buffer.String(), expected+"\n") {
+			t.Errorf("Expected mount listing to contain %q, got:\n%s", expected, buffer.String())
+		}
+	}
+
+	buffer.Reset()
+	if code, err := fs.Execute(ctx, &buffer, "mount", "--types"); err != nil || code != cmd.ExitSuccess {
+		t.Fatalf("Expected type listing to succeed, got code %d: %v", code, err)
+	}
+	if !strings.Contains(buffer.String(), "direct (dir, file)") || !strings.Contains(buffer.String(), "(required)") {
+		t.Errorf("Expected type listing to contain schemes and parameters, got:\n%s", buffer.String())
+	}
+
+	// Secret parameters are never displayed and values containing commas are passed unchanged
+	registerSecretive.Do(func() {
+		backend.Register(&backend.Registration{
+			Name: "secretive",
+			Params: []backend.ParamSpec{
+				{Name: "token", Description: "access token", Secret: true, Default: "fallback"},
+				{Name: "labels", Description: "comma-separated labels"},
+			},
+			Factory: func(params backend.FactoryParams) (backend.Backend, error) {
+				secretiveParams <- params
+				return ephemeral.NewEphemeralBackend(), nil
+			},
+		})
+	})
+	if code, err := fs.Execute(ctx, &buffer, "mount", "-o", "labels=a,b", "-o", "token=hunter2", "secretive://host?token=hunter2", "/secret"); err != nil || code != cmd.ExitSuccess {
+		t.Fatalf("Expected mount with secret to succeed, got code %d: %v", code, err)
+	}
+	received := <-secretiveParams
+	if received["labels"] != "a,b" || received["token"] != "hunter2" {
+		t.Errorf("Expected -o values to be passed unchanged, got %v", received)
+	}
+
+	buffer.Reset()
+	if code, err := fs.Execute(ctx, &buffer, "mount"); err != nil || code != cmd.ExitSuccess {
+		t.Fatalf("Expected mount listing to succeed, got code %d: %v", code, err)
+	}
+	if !strings.Contains(buffer.String(), "secretive://host?token="+backend.RedactedValue+" on /secret") || strings.Contains(buffer.String(), "hunter2") {
+		t.Errorf("Expected secret to be redacted from mount listing, got:\n%s", buffer.String())
+	}
+
+	buffer.Reset()
+	if code, err := fs.Execute(ctx, &buffer, "mount", "--types"); err != nil || code != cmd.ExitSuccess {
+		t.Fatalf("Expected type listing to succeed, got code %d: %v", code, err)
+	}
+	if !strings.Contains(buffer.String(), "access token (secret)") || strings.Contains(buffer.String(), "fallback") {
+		t.Errorf("Expected secret default to be hidden from type listing, got:\n%s", buffer.String())
+	}
+
+	failures := [][]string{
+		{"mount", "/missing"},
+		{"mount", "-t", "unknown", "/unknown"},
+		{"mount", "-t", "sqlite", "-o", "unknown=true", "/unknown"},
+		{"mount", "-t", "direct", "/unknown"},
+		{"mount", "-t", "sqlite", "-o", "invalid", "/unknown"},
+	}
+	for _, args := range failures {
+		if code, _ := fs.Execute(ctx, &buffer, args...); code == cmd.ExitSuccess {
+			t.Errorf("Expected %v to fail", args)
+		}
+	}
+
+	if code, err := fs.Execute(ctx, &buffer, "umount", "/db", "/srv", "/secret"); err != nil || code != cmd.ExitSuccess {
+		t.Fatalf("Expected umount to succeed, got code %d: %v", code, err)
+	}
+	if code, _ := fs.Execute(ctx, &buffer, "umount", "/db"); code != cmd.ExitFailure {
+		t.Errorf("Expected umount of unmounted path to fail, got code %d", code)
+	}
+	if mounts := fs.DumpMountTable().Mounts; len(mounts) != 2 {
+		t.Errorf("Expected 2 remaining mounts, got %d", len(mounts))
+	}
+}
@@ -0,0 +1,165 @@
+package vfs_test
+
+import (
+	"bytes"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/mwantia/vfs"
+	"github.com/mwantia/vfs/data"
+	"github.com/mwantia/vfs/log"
+	"github.com/mwantia/vfs/mount"
+	"github.com/mwantia/vfs/mount/backend/compress"
+	"github.com/mwantia/vfs/mount/backend/ephemeral"
+)
+
+// TestCompress_RandomAccess verifies random writes, reads and truncates against uncompressed content for all algorithms.
+func TestCompress_RandomAccess(t *testing.T) {
+	for _, algorithm := range []data.CompressionAlgorithm{data.CompressionZstd, data.CompressionGzip, data.CompressionNone} {
+		t.Run(string(algorithm), func(t *testing.T) {
+			ctx := t.Context()
+
+			inner := ephemeral.NewEphemeralBackend()
+			storage, err := compress.NewCompressBackend(inner, &compress.CompressBackendConfig{
+				Algorithm: algorithm,
+				FrameSize: 256,
+			})
+			if err != nil {
+				t.Fatalf("Failed to create compress backend: %v", err)
+			}
+			if err := storage.Open(ctx); err != nil {
+				t.Fatalf("Open failed: %v", err)
+			}
+			defer storage.Close(ctx)
+
+			if _, err := storage.CreateObject(ctx, "", "file.txt", 0644); err != nil {
+				t.Fatalf("CreateObject failed: %v", err)
+			}
+
+			random := rand.New(rand.NewSource(1))
+			var expected []byte
+			for i := 0; i < 200; i++ {
+				switch offset := random.Int63n(int64(len(expected)) + 300); random.Intn(5) {
+				case 0:
+					size := random.Int63n(int64(len(expected)) + 300)
+					if err := storage.TruncateObject(ctx, "", "file.txt", size); err != nil {
+						t.Fatalf("TruncateObject failed: %v", err)
+					}
+					expected = append(expected, make([]byte, max(size-int64(len(expected)), 0))...)[:size]
+				default:
+					content := bytes.Repeat([]byte{byte('a' + random.Intn(4))}, random.Intn(600)+1)
+					if _, err := storage.WriteObject(ctx, "", "file.txt", offset, content); err != nil {
+						t.Fatalf("WriteObject failed: %v", err)
+					}
+					if end := offset + int64(len(content)); end > int64(len(expected)) {
+						expected = append(expected, make([]byte, end-int64(len(expected)))...)
+					}
+					copy(expected[offset:], content)
+				}
+
+				stat, err := storage.HeadObject(ctx, "", "file.txt")
+				if err != nil || stat.Size != int64(len(expected)) {
+					t.Fatalf("Expected size %d after step %d, got %+v (%v)", len(expected), i, stat, err)
+				}
+				if len(expected) == 0 {
+					continue
+				}
+
+				offset := random.Int63n(int64(len(expected)))
+				buffer := make([]byte, random.Intn(100)+1)
+				n, err := storage.ReadObject(ctx, "", "file.txt", offset, buffer)
+				if (err != nil && n == 0) || !bytes.Equal(buffer[:n], expected[offset:min(offset+int64(len(buffer)), int64(len(expected)))]) {
+					t.Fatalf("Unexpected content at offset %d after step %d: %q (%v)", offset, i, buffer[:n], err)
+				}
+			}
+
+			encoding, err := storage.ContentEncoding(ctx, "", "file.txt")
+			if err != nil {
+				t.Fatalf("ContentEncoding failed: %v", err)
+			}
+			stats, err := storage.CompressionStats(ctx, "")
+			if err != nil || stats.Objects != 1 || stats.LogicalBytes != int64(len(expected)) {
+				t.Fatalf("Unexpected compression stats: %+v (%v)", stats, err)
+			}
+
+			physical, err := inner.HeadObject(ctx, "", "file.txt")
+			if err != nil {
+				t.Fatalf("HeadObject failed: %v", err)
+			}
+			if algorithm == data.CompressionNone {
+				if encoding != data.CompressionNone || physical.Size != int64(len(expected)) {
+					t.Errorf("Expected content to be stored as is, got %s with %d bytes", encoding, physical.Size)
+				}
+			} else if len(expected) > 0 && (encoding != algorithm || stats.Compressed != 1 || physical.Size >= int64(len(expected))) {
+				t.Errorf("Expected content compressed with %s, got %s with %d of %d bytes", algorithm, encoding, physical.Size, len(expected))
+			}
+		})
+	}
+}
+
+// TestCompress_Mount verifies compression as mount option, including content encoding and usage reporting.
+func TestCompress_Mount(t *testing.T) {
+	ctx := t.Context()
+	fs, err := vfs.NewVirtualFileSystem(vfs.WithLogLevel(log.Error))
+	if err != nil {
+		t.Fatalf("Failed to initialize vfs: %v", err)
+	}
+	defer fs.Shutdown(ctx)
+
+	storage := ephemeral.NewEphemeralBackend()
+	if err := fs.Mount(ctx, "/", storage, mount.WithMetadata(storage), mount.WithCompression(nil)); err == nil {
+		t.Fatal("Expected compression to be rejected, if metadata is stored by the same backend")
+	}
+	if err := fs.Mount(ctx, "/", storage, mount.WithMetadata(ephemeral.NewEphemeralBackend()), mount.WithCompression(nil)); err != nil {
+		t.Fatalf("Failed to mount /: %v", err)
+	}
+
+	text := []byte(strings.Repeat("compressible content ", 1000))
+	// Already compressed content types are stored as is
+	image := append([]byte("\x89PNG\r\n\x1a\n"), bytes.Repeat([]byte{0}, 1000)...)
+	writeTestFile(t, fs, "/text.txt", text)
+	writeTestFile(t, fs, "/image.png", image)
+
+	if result, err := fs.ReadFile(ctx, "/text.txt", 20000, 21); err != nil || !bytes.Equal(result, text[20000:20021]) {
+		t.Errorf("Unexpected content: %q (%v)", result, err)
+	}
+	if meta, err := fs.StatMetadata(ctx, "/text.txt"); err != nil || meta.Size != int64(len(text)) || meta.GetAttribute(data.AttributeContentEncoding, "") != "zstd" {
+		t.Errorf("Expected zstd content encoding with logical size, got %+v (%v)", meta, err)
+	}
+	if meta, err := fs.StatMetadata(ctx, "/image.png"); err != nil || meta.HasAttribute(data.AttributeContentEncoding) {
+		t.Errorf("Expected no content encoding for image, got %+v (%v)", meta, err)
+	}
+	if stat, err := storage.HeadObject(ctx, "", "image.png"); err != nil || stat.Size != int64(len(image)) {
+		t.Errorf("Expected image to be stored as is, got %+v (%v)", stat, err)
+	}
+
+	usage, err := fs.Usage(ctx, "/")
+	if err != nil {
+		t.Fatalf("Usage failed: %v", err)
+	}
+	if usage.Compression == nil || usage.Compression.Objects != 2 || usage.Compression.Compressed != 1 ||
+		usage.Compression.LogicalBytes != int64(len(text)+len(image)) || usage.Compression.Ratio() <= 1 {
+		t.Errorf("Unexpected compression stats: %+v", usage.Compression)
+	}
+
+	var buffer bytes.Buffer
+	if code, err := fs.Execute(ctx, &buffer, "df", "/"); err != nil || code != 0 {
+		t.Fatalf("df failed with %d: %v", code, err)
+	}
+	if output := buffer.String(); !strings.Contains(output, "1 of 2 file(s) compressed") {
+		t.Errorf("Unexpected df output: %q", output)
+	}
+	if report, err := fs.Fsck(ctx, "/", nil); err != nil || len(report.Issues) != 0 {
+		t.Errorf("Expected consistent mount, got %+v (%v)", report, err)
+	}
+
+	// Content written without a streamer records its content encoding as well
+	writeTestFile(t, fs, "/written.txt", nil)
+	if _, err := fs.WriteFile(ctx, "/written.txt", 0, text); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	if meta, err := fs.StatMetadata(ctx, "/written.txt"); err != nil || meta.GetAttribute(data.AttributeContentEncoding, "") != "zstd" {
+		t.Errorf("Expected zstd content encoding after WriteFile, got %+v (%v)", meta, err)
+	}
+}
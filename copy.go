@@ -0,0 +1,329 @@
+package vfs
+
+import (
+	"context"
+	"io"
+	"strings"
+
+	"github.com/mwantia/vfs/data"
+	"github.com/mwantia/vfs/mount"
+	"github.com/mwantia/vfs/mount/backend"
+)
+
+// Copy copies the file or directory at src to dst.
+// Content is streamed in chunks, so memory usage stays bounded regardless of the file size.
+// If both paths share the same backend and it supports server-side copies, the content
+// isn't streamed through the VFS at all. Directories require opts.Recursive.
+// A failed copy leaves the partial destination file, so it can be resumed via opts.Offset.
+func (vfs *virtualFileSystemImpl) Copy(ctx context.Context, src string, dst string, opts *data.CopyOptions) error {
+	op := vfs.newOperation(OperationCopy, src)
+	op.NewPath = dst
+	if absolute, err := vfs.toAbsolutePath(dst); err == nil {
+		op.NewPath = absolute
+	}
+
+	return vfs.interceptError(ctx, op, func(ctx context.Context, op *Operation) error {
+		return vfs.copy(ctx, op.Path, op.NewPath, opts)
+	})
+}
+
+// copy implements Copy, after the operation passed all interceptors.
+func (vfs *virtualFileSystemImpl) copy(ctx context.Context, src string, dst string, opts *data.CopyOptions) error {
+	srcAbsolute, err := vfs.toAbsolutePath(src)
+	if err != nil {
+		vfs.log.Error("Copy: failed to convert src to absolute: %s - %v", src, err)
+		return err
+	}
+
+	dstAbsolute, err := vfs.toAbsolutePath(dst)
+	if err != nil {
+		vfs.log.Error("Copy: failed to convert dst to absolute: %s - %v", dst, err)
+		return err
+	}
+
+	if opts == nil {
+		opts = &data.CopyOptions{}
+	}
+
+	vfs.log.Debug("Copy: copying %s to %s", srcAbsolute, dstAbsolute)
+
+	if srcAbsolute == dstAbsolute {
+		vfs.log.Error("Copy: source and destination %s are equal", srcAbsolute)
+		return data.ErrInvalid
+	}
+
+	srcStat, err := vfs.StatMetadata(ctx, srcAbsolute)
+	if err != nil {
+		vfs.log.Error("Copy: source path %s does not exist - %v", srcAbsolute, err)
+		return err
+	}
+
+	if srcStat.Mode.IsDir() {
+		if !opts.Recursive {
+			vfs.log.Error("Copy: cannot copy directory %s without recursive option", srcAbsolute)
+			return data.ErrIsDirectory
+		}
+		// Resuming is only possible for single files
+		if opts.Offset != 0 {
+			vfs.log.Error("Copy: cannot resume copy of directory %s", srcAbsolute)
+			return data.ErrInvalid
+		}
+		// Prevent copying a directory into its own subtree
+		if strings.HasPrefix(dstAbsolute, srcAbsolute+"/") {
+			vfs.log.Error("Copy: cannot copy %s into its own subtree %s", srcAbsolute, dstAbsolute)
+			return data.ErrInvalid
+		}
+
+		return vfs.copyDirectory(ctx, srcAbsolute, dstAbsolute, srcStat, opts)
+	}
+
+	return vfs.copyFile(ctx, srcAbsolute, dstAbsolute, srcStat, opts)
+}
+
+// copyFile copies a single file, either server-side or streamed in chunks.
+func (vfs *virtualFileSystemImpl) copyFile(ctx context.Context, src string, dst string, srcStat *data.Metadata, opts *data.CopyOptions) error {
+	if opts.Offset < 0 || opts.Offset > srcStat.Size {
+		vfs.log.Error("Copy: invalid offset %d for %s (size=%d)", opts.Offset, src, srcStat.Size)
+		return data.ErrInvalid
+	}
+
+	dstStat, err := vfs.StatMetadata(ctx, dst)
+	if err != nil && err != data.ErrNotExist {
+		vfs.log.Error("Copy: failed to stat destination %s - %v", dst, err)
+		return err
+	}
+
+	switch {
+	case dstStat != nil && dstStat.Mode.IsDir():
+		vfs.log.Error("Copy: destination %s is a directory", dst)
+		return data.ErrIsDirectory
+	case opts.Offset > 0:
+		// Resuming requires the partial destination of a previous copy
+		if dstStat == nil || dstStat.Size < opts.Offset {
+			vfs.log.Error("Copy: cannot resume copy to %s at offset %d", dst, opts.Offset)
+			return data.ErrInvalid
+		}
+	case dstStat != nil && !opts.Overwrite:
+		vfs.log.Error("Copy: destination path %s already exists", dst)
+		return data.ErrExist
+	case dstStat != nil:
+		vfs.log.Debug("Copy: replacing existing destination %s", dst)
+		if err := vfs.UnlinkFile(ctx, dst); err != nil {
+			return err
+		}
+	}
+
+	handled := false
+	if opts.Offset == 0 {
+		if handled, err = vfs.copyServerSide(ctx, src, dst, srcStat, opts); err != nil {
+			return err
+		}
+	}
+
+	if !handled {
+		vfs.log.Debug("Copy: streaming %s to %s from offset %d", src, dst, opts.Offset)
+		if err := vfs.copyContent(ctx, src, dst, opts.Offset, srcStat.Size, opts); err != nil {
+			return err
+		}
+	}
+
+	if err := vfs.preserveMetadata(ctx, dst, srcStat, opts); err != nil {
+		return err
+	}
+
+	vfs.log.Info("Copy: successfully copied file %s to %s", src, dst)
+	return nil
+}
+
+// copyServerSide copies the file using the backend, if source and destination share the same backend.
+// Returns false if the content has to be streamed instead.
+func (vfs *virtualFileSystemImpl) copyServerSide(ctx context.Context, src string, dst string, srcStat *data.Metadata, opts *data.CopyOptions) (bool, error) {
+	srcMnt, err := vfs.getMountFromPath(src)
+	if err != nil {
+		return false, err
+	}
+	dstMnt, err := vfs.getMountFromPath(dst)
+	if err != nil {
+		return false, err
+	}
+
+	if srcMnt.ObjectStorage != dstMnt.ObjectStorage || dstMnt.Options.IsReadOnly {
+		return false, nil
+	}
+	// Separate metadata backends wouldn't know about the copied object
+	if dstMnt.Metadata != nil && !dstMnt.IsDualMount {
+		return false, nil
+	}
+
+	copier, ok := dstMnt.ObjectStorage.(backend.CopyObjectBackend)
+	if !ok {
+		return false, nil
+	}
+
+	srcKey := vfs.getPrefixRelativePath(srcMnt, src)
+	dstKey := vfs.getPrefixRelativePath(dstMnt, dst)
+
+	// Copies are owned by UID 0 until their ownership is preserved
+	usage := mount.QuotaDelta{Bytes: srcStat.Size, Objects: 1}
+	if err := dstMnt.ChargeQuota(usage); err != nil {
+		vfs.log.Error("Copy: quota validation failed for %s - %v", dst, err)
+		return false, err
+	}
+
+	vfs.log.Debug("Copy: copying %s to %s server-side", src, dst)
+	if _, err := copier.CopyObject(ctx, srcMnt.Options.Namespace, srcKey, dstMnt.Options.Namespace, dstKey); err != nil {
+		dstMnt.RefundQuota(usage)
+		vfs.log.Error("Copy: server-side copy of %s to %s failed - %v", src, dst, err)
+		return false, err
+	}
+	if err := dstMnt.UpdateContentEncoding(ctx, dstKey); err != nil {
+		vfs.log.Warn("Copy: failed to update content encoding for %s - %v", dst, err)
+	}
+
+	dstMnt.Notify(data.EventCreate, dst, "")
+
+	if opts.Progress != nil {
+		opts.Progress(dst, srcStat.Size, srcStat.Size)
+	}
+
+	return true, nil
+}
+
+// copyContent streams the content of src to dst in chunks, starting at offset.
+// The destination is created if it doesn't exist yet.
+func (vfs *virtualFileSystemImpl) copyContent(ctx context.Context, src string, dst string, offset int64, total int64, opts *data.CopyOptions) error {
+	writer, err := vfs.OpenFile(ctx, dst, data.AccessModeCreate|data.AccessModeWrite)
+	if err != nil {
+		vfs.log.Error("Copy: failed to open destination file %s - %v", dst, err)
+		return err
+	}
+
+	if err := vfs.copyChunks(ctx, src, writer, dst, offset, total, opts); err != nil {
+		writer.Close()
+		return err
+	}
+
+	if err := writer.Close(); err != nil {
+		vfs.log.Error("Copy: failed to close destination file %s - %v", dst, err)
+		return err
+	}
+
+	return nil
+}
+
+// copyChunks copies all bytes between offset and total from src to writer.
+// The source is read without opening it, since streamers are shared with other handles of the same file.
+func (vfs *virtualFileSystemImpl) copyChunks(ctx context.Context, src string, writer io.WriteSeeker, dst string, offset int64, total int64, opts *data.CopyOptions) error {
+	if _, err := writer.Seek(offset, io.SeekStart); err != nil {
+		return err
+	}
+
+	chunkSize := min(opts.GetChunkSize(), max(total-offset, 1))
+	for copied := offset; copied < total; {
+		if err := ctx.Err(); err != nil {
+			vfs.log.Error("Copy: cancelled copy to %s at offset %d - %v", dst, copied, err)
+			return err
+		}
+
+		buffer, err := vfs.readFile(ctx, src, copied, min(chunkSize, total-copied))
+		if err != nil {
+			vfs.log.Error("Copy: failed to read source at offset %d - %v", copied, err)
+			return err
+		}
+		if len(buffer) == 0 {
+			vfs.log.Error("Copy: source ended unexpectedly at offset %d", copied)
+			return io.ErrUnexpectedEOF
+		}
+
+		if _, err := writer.Write(buffer); err != nil {
+			vfs.log.Error("Copy: failed to write to %s at offset %d - %v", dst, copied, err)
+			return err
+		}
+
+		copied += int64(len(buffer))
+		if opts.Progress != nil {
+			opts.Progress(dst, copied, total)
+		}
+	}
+
+	return nil
+}
+
+// copyDirectory creates dst and copies all entries of src into it.
+// Nested mount points are skipped, same as for Rename.
+func (vfs *virtualFileSystemImpl) copyDirectory(ctx context.Context, src string, dst string, srcStat *data.Metadata, opts *data.CopyOptions) error {
+	dstStat, err := vfs.StatMetadata(ctx, dst)
+	switch {
+	case err == data.ErrNotExist:
+		vfs.log.Debug("Copy: creating destination directory %s", dst)
+		if err := vfs.CreateDirectory(ctx, dst); err != nil {
+			vfs.log.Error("Copy: failed to create destination directory %s - %v", dst, err)
+			return err
+		}
+	case err != nil:
+		return err
+	case !dstStat.Mode.IsDir():
+		vfs.log.Error("Copy: destination %s is not a directory", dst)
+		return data.ErrNotDirectory
+	case !opts.Overwrite:
+		vfs.log.Error("Copy: destination path %s already exists", dst)
+		return data.ErrExist
+	}
+
+	entries, err := vfs.ReadDirectory(ctx, src)
+	if err != nil {
+		vfs.log.Error("Copy: failed to read source directory %s - %v", src, err)
+		return err
+	}
+
+	for _, entry := range entries {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		// Skip mount points
+		if entry.Mode&data.ModeMount != 0 {
+			vfs.log.Debug("Copy: skipping mount point %s", entry.Key)
+			continue
+		}
+
+		if err := vfs.Copy(ctx, src+"/"+entry.Key, dst+"/"+entry.Key, opts); err != nil {
+			vfs.log.Error("Copy: failed to copy entry %s - %v", entry.Key, err)
+			return err
+		}
+	}
+
+	if err := vfs.preserveMetadata(ctx, dst, srcStat, opts); err != nil {
+		return err
+	}
+
+	vfs.log.Info("Copy: successfully copied directory %s to %s", src, dst)
+	return nil
+}
+
+// preserveMetadata applies mode, attributes and content type of the source to dst, as requested by opts.
+func (vfs *virtualFileSystemImpl) preserveMetadata(ctx context.Context, dst string, srcStat *data.Metadata, opts *data.CopyOptions) error {
+	if !opts.PreserveMode && !opts.PreserveAttributes && !opts.PreserveContentType {
+		return nil
+	}
+
+	return vfs.updateMetadata(ctx, "Copy", dst, func(meta *data.Metadata) (*data.MetadataUpdate, error) {
+		update := &data.MetadataUpdate{
+			Metadata: &data.Metadata{
+				Mode:        (meta.Mode &^ data.ModePerm) | srcStat.Mode.Perm(),
+				Attributes:  srcStat.Attributes,
+				ContentType: srcStat.ContentType,
+			},
+		}
+		if opts.PreserveMode {
+			update.Mask |= data.MetadataUpdateMode
+		}
+		if opts.PreserveAttributes {
+			update.Mask |= data.MetadataUpdateAttributes
+		}
+		if opts.PreserveContentType {
+			update.Mask |= data.MetadataUpdateContentType
+		}
+
+		return update, nil
+	})
+}
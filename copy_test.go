@@ -0,0 +1,260 @@
+package vfs_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/mwantia/vfs"
+	"github.com/mwantia/vfs/cmd"
+	"github.com/mwantia/vfs/data"
+	"github.com/mwantia/vfs/log"
+	"github.com/mwantia/vfs/mount"
+	"github.com/mwantia/vfs/mount/backend/direct"
+	"github.com/mwantia/vfs/mount/backend/ephemeral"
+)
+
+func writeTestFile(t *testing.T, fs vfs.VirtualFileSystem, path string, content []byte) {
+	t.Helper()
+
+	streamer, err := fs.OpenFile(t.Context(), path, data.AccessModeWrite|data.AccessModeCreate)
+	if err != nil {
+		t.Fatalf("Open %s failed: %v", path, err)
+	}
+	if _, err := streamer.Write(content); err != nil {
+		t.Fatalf("Write %s failed: %v", path, err)
+	}
+	if err := streamer.Close(); err != nil {
+		t.Fatalf("Close %s failed: %v", path, err)
+	}
+}
+
+// TestCopy_AcrossMounts verifies streamed copies between mounts, including progress, cancellation and resume.
+func TestCopy_AcrossMounts(t *testing.T) {
+	ctx := t.Context()
+	fs, err := vfs.NewVirtualFileSystem(vfs.WithLogLevel(log.Error))
+	if err != nil {
+		t.Fatalf("Failed to initialize vfs: %v", err)
+	}
+	defer fs.Shutdown(ctx)
+
+	if err := fs.Mount(ctx, "/", ephemeral.NewEphemeralBackend()); err != nil {
+		t.Fatalf("Failed to mount /: %v", err)
+	}
+	storage, err := direct.NewDirectBackend(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create direct backend: %v", err)
+	}
+	if err := fs.Mount(ctx, "/ext", storage); err != nil {
+		t.Fatalf("Failed to mount /ext: %v", err)
+	}
+
+	content := bytes.Repeat([]byte("0123456789abcdef"), 4096)
+	writeTestFile(t, fs, "/large.bin", content)
+
+	var progress []int64
+	opts := &data.CopyOptions{
+		ChunkSize: 4096,
+		Progress: func(path string, copied, total int64) {
+			if path != "/ext/large.bin" || total != int64(len(content)) {
+				t.Errorf("Unexpected progress for %s with total %d", path, total)
+			}
+			progress = append(progress, copied)
+		},
+	}
+	if err := fs.Copy(ctx, "/large.bin", "/ext/large.bin", opts); err != nil {
+		t.Fatalf("Copy failed: %v", err)
+	}
+
+	if len(progress) != len(content)/4096 || progress[len(progress)-1] != int64(len(content)) {
+		t.Errorf("Expected %d progress reports ending at %d, got %v", len(content)/4096, len(content), progress)
+	}
+
+	got, err := fs.ReadFile(ctx, "/ext/large.bin", 0, int64(len(content)))
+	if err != nil || !bytes.Equal(got, content) {
+		t.Errorf("Expected copied content to match source: %v", err)
+	}
+	if _, err := fs.StatMetadata(ctx, "/large.bin"); err != nil {
+		t.Errorf("Expected source to remain after copy: %v", err)
+	}
+
+	// Cancel halfway through and resume from the partial destination
+	cancelCtx, cancel := context.WithCancel(ctx)
+	opts = &data.CopyOptions{
+		ChunkSize: 4096,
+		Progress: func(path string, copied, total int64) {
+			if copied >= total/2 {
+				cancel()
+			}
+		},
+	}
+	if err := fs.Copy(cancelCtx, "/large.bin", "/ext/resumed.bin", opts); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected cancelled copy, got %v", err)
+	}
+
+	partial, err := fs.StatMetadata(ctx, "/ext/resumed.bin")
+	if err != nil {
+		t.Fatalf("Expected partial destination to remain: %v", err)
+	}
+	if partial.Size == 0 || partial.Size >= int64(len(content)) {
+		t.Fatalf("Expected partial destination, got size %d", partial.Size)
+	}
+
+	if err := fs.Copy(ctx, "/large.bin", "/ext/resumed.bin", &data.CopyOptions{Offset: partial.Size}); err != nil {
+		t.Fatalf("Resumed copy failed: %v", err)
+	}
+	got, err = fs.ReadFile(ctx, "/ext/resumed.bin", 0, int64(len(content)))
+	if err != nil || !bytes.Equal(got, content) {
+		t.Errorf("Expected resumed content to match source: %v", err)
+	}
+
+	if err := fs.Copy(ctx, "/large.bin", "/ext/resumed.bin", &data.CopyOptions{Offset: int64(len(content)) + 1}); err != data.ErrInvalid {
+		t.Errorf("Expected ErrInvalid for offset beyond source, got %v", err)
+	}
+	// Sources held open by another handle are copied, without closing the shared streamer
+	writer, err := fs.OpenFile(ctx, "/large.bin", data.AccessModeWrite)
+	if err != nil {
+		t.Fatalf("Open for write failed: %v", err)
+	}
+	if err := fs.Copy(ctx, "/large.bin", "/ext/opened.bin", nil); err != nil {
+		t.Errorf("Expected copy of opened source to succeed, got %v", err)
+	}
+	if _, err := writer.Write([]byte("0123")); err != nil {
+		t.Errorf("Expected the other handle to remain usable, got %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+}
+
+// TestCopy_ServerSide verifies that copies between mounts sharing a backend don't stream their content.
+func TestCopy_ServerSide(t *testing.T) {
+	ctx := t.Context()
+	fs, err := vfs.NewVirtualFileSystem(vfs.WithLogLevel(log.Error))
+	if err != nil {
+		t.Fatalf("Failed to initialize vfs: %v", err)
+	}
+	defer fs.Shutdown(ctx)
+
+	storage := ephemeral.NewEphemeralBackend()
+	if err := fs.Mount(ctx, "/", storage); err != nil {
+		t.Fatalf("Failed to mount /: %v", err)
+	}
+	if err := fs.Mount(ctx, "/team", storage, mount.WithNamespace("team")); err != nil {
+		t.Fatalf("Failed to mount /team: %v", err)
+	}
+
+	content := bytes.Repeat([]byte("x"), 64*1024)
+	writeTestFile(t, fs, "/file.bin", content)
+
+	reports := 0
+	opts := &data.CopyOptions{
+		ChunkSize: 1024,
+		Progress: func(path string, copied, total int64) {
+			reports++
+		},
+	}
+	if err := fs.Copy(ctx, "/file.bin", "/team/file.bin", opts); err != nil {
+		t.Fatalf("Copy failed: %v", err)
+	}
+	if reports != 1 {
+		t.Errorf("Expected a single progress report for server-side copy, got %d", reports)
+	}
+
+	got, err := fs.ReadFile(ctx, "/team/file.bin", 0, int64(len(content)))
+	if err != nil || !bytes.Equal(got, content) {
+		t.Errorf("Expected copied content to match source: %v", err)
+	}
+
+	// Both copies are independent of each other
+	if _, err := fs.WriteFile(ctx, "/team/file.bin", 0, []byte("y")); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if got, _ := fs.ReadFile(ctx, "/file.bin", 0, 1); string(got) != "x" {
+		t.Errorf("Expected source to be unchanged, got %q", got)
+	}
+}
+
+// TestCopy_Options verifies recursive copies, overwrites and metadata preservation.
+func TestCopy_Options(t *testing.T) {
+	ctx := t.Context()
+	fs, err := vfs.NewVirtualFileSystem(vfs.WithLogLevel(log.Error))
+	if err != nil {
+		t.Fatalf("Failed to initialize vfs: %v", err)
+	}
+	defer fs.Shutdown(ctx)
+
+	storage := ephemeral.NewEphemeralBackend()
+	if err := fs.Mount(ctx, "/", storage, mount.WithMetadata(storage)); err != nil {
+		t.Fatalf("Failed to mount /: %v", err)
+	}
+	if err := fs.CreateDirectory(ctx, "/src"); err != nil {
+		t.Fatalf("MkDir failed: %v", err)
+	}
+	if err := fs.CreateDirectory(ctx, "/src/sub"); err != nil {
+		t.Fatalf("MkDir failed: %v", err)
+	}
+	writeTestFile(t, fs, "/src/a.txt", []byte("alpha"))
+	writeTestFile(t, fs, "/src/sub/b.json", []byte("{}"))
+
+	if err := fs.Chmod(ctx, "/src/a.txt", 0600); err != nil {
+		t.Fatalf("Chmod failed: %v", err)
+	}
+	if err := fs.SetAttributes(ctx, "/src/a.txt", map[string]string{"owner": "team"}); err != nil {
+		t.Fatalf("SetAttributes failed: %v", err)
+	}
+	source, err := fs.StatMetadata(ctx, "/src/a.txt")
+	if err != nil {
+		t.Fatalf("Stat failed: %v", err)
+	}
+
+	if err := fs.Copy(ctx, "/src", "/dst", nil); err != data.ErrIsDirectory {
+		t.Errorf("Expected ErrIsDirectory without recursive option, got %v", err)
+	}
+	if err := fs.Copy(ctx, "/src", "/src/sub/inner", &data.CopyOptions{Recursive: true}); err != data.ErrInvalid {
+		t.Errorf("Expected ErrInvalid for copy into own subtree, got %v", err)
+	}
+
+	opts := &data.CopyOptions{Recursive: true, PreserveMode: true, PreserveAttributes: true, PreserveContentType: true}
+	if err := fs.Copy(ctx, "/src", "/dst", opts); err != nil {
+		t.Fatalf("Recursive copy failed: %v", err)
+	}
+
+	copied, err := fs.StatMetadata(ctx, "/dst/a.txt")
+	if err != nil {
+		t.Fatalf("Stat copied file failed: %v", err)
+	}
+	if copied.Mode.Perm() != 0600 || copied.GetAttribute("owner", "") != "team" || copied.ContentType != source.ContentType {
+		t.Errorf("Expected mode, attributes and content type to be preserved, got %+v", copied)
+	}
+	if got, err := fs.ReadFile(ctx, "/dst/sub/b.json", 0, 2); err != nil || string(got) != "{}" {
+		t.Errorf("Expected nested file to be copied, got %q: %v", got, err)
+	}
+
+	// Existing destinations are only replaced with overwrite
+	writeTestFile(t, fs, "/other.txt", []byte("other"))
+	if err := fs.Copy(ctx, "/other.txt", "/dst/a.txt", nil); err != data.ErrExist {
+		t.Errorf("Expected ErrExist for existing destination, got %v", err)
+	}
+	if err := fs.Copy(ctx, "/other.txt", "/dst/a.txt", &data.CopyOptions{Overwrite: true}); err != nil {
+		t.Fatalf("Overwriting copy failed: %v", err)
+	}
+	if got, _ := fs.ReadFile(ctx, "/dst/a.txt", 0, 5); string(got) != "other" {
+		t.Errorf("Expected destination to be replaced, got %q", got)
+	}
+
+	// The cp command copies into existing directories
+	var buffer bytes.Buffer
+	if code, err := fs.Execute(ctx, &buffer, "cp", "-r", "/src", "/other.txt", "/dst"); err != nil || code != cmd.ExitSuccess {
+		t.Fatalf("Expected cp to succeed, got code %d: %v\n%s", code, err, buffer.String())
+	}
+	for _, path := range []string{"/dst/src/a.txt", "/dst/src/sub/b.json", "/dst/other.txt"} {
+		if _, err := fs.StatMetadata(ctx, path); err != nil {
+			t.Errorf("Expected %s to be copied by cp: %v", path, err)
+		}
+	}
+	if code, _ := fs.Execute(ctx, &buffer, "cp", "/src", "/copy"); code != cmd.ExitFailure {
+		t.Errorf("Expected cp of directory without -r to fail, got code %d", code)
+	}
+}
@@ -1,6 +1,10 @@
 package data
 
-import "time"
+import (
+	"slices"
+	"strings"
+	"time"
+)
 
 const (
 	// Encoding (gzip, etc.)
@@ -15,6 +19,12 @@ const (
 	AttributeEncrypted = "encrypted"
 	// Target to simlink
 	AttributeSymlinkTarget = "symlink-target"
+	// Comma-separated list of user defined tags
+	AttributeTags = "tags"
+	// Marks directories completed by a reindex, which has not finished yet
+	AttributeReindex = "reindex"
+	// Storage tier currently holding the content of tiered mounts
+	AttributeTier = "tier"
 )
 
 // GetAttribute safely retrieves the attribute with a default value.
@@ -57,3 +67,21 @@ func (m *Metadata) HasAttribute(key string) bool {
 	_, exists := m.Attributes[key]
 	return exists
 }
+
+// GetTags returns the sorted list of tags stored within the attributes.
+func (m *Metadata) GetTags() []string {
+	value := m.GetAttribute(AttributeTags, "")
+	if value == "" {
+		return []string{}
+	}
+
+	tags := make([]string, 0)
+	for tag := range strings.SplitSeq(value, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" && !slices.Contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+
+	slices.Sort(tags)
+	return tags
+}
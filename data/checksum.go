@@ -0,0 +1,145 @@
+package data
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+	"strings"
+)
+
+// ChecksumAlgorithm identifies the hash function used for content checksums.
+type ChecksumAlgorithm string
+
+const (
+	ChecksumSHA256 ChecksumAlgorithm = "sha256"
+	ChecksumSHA512 ChecksumAlgorithm = "sha512"
+	ChecksumSHA1   ChecksumAlgorithm = "sha1"
+	ChecksumMD5    ChecksumAlgorithm = "md5"
+	ChecksumCRC32  ChecksumAlgorithm = "crc32"
+
+	// DefaultChecksumAlgorithm is used when no algorithm has been configured.
+	DefaultChecksumAlgorithm = ChecksumSHA256
+)
+
+// ChecksumAlgorithms returns all supported checksum algorithms.
+func ChecksumAlgorithms() []ChecksumAlgorithm {
+	return []ChecksumAlgorithm{
+		ChecksumSHA256,
+		ChecksumSHA512,
+		ChecksumSHA1,
+		ChecksumMD5,
+		ChecksumCRC32,
+	}
+}
+
+// IsValid checks if the algorithm is supported.
+func (ca ChecksumAlgorithm) IsValid() bool {
+	_, err := ca.New()
+	return err == nil
+}
+
+// New creates a new hash for the algorithm.
+func (ca ChecksumAlgorithm) New() (hash.Hash, error) {
+	switch ca {
+	case ChecksumSHA256:
+		return sha256.New(), nil
+	case ChecksumSHA512:
+		return sha512.New(), nil
+	case ChecksumSHA1:
+		return sha1.New(), nil
+	case ChecksumMD5:
+		return md5.New(), nil
+	case ChecksumCRC32:
+		return crc32.NewIEEE(), nil
+	}
+
+	return nil, ErrChecksumUnsupported
+}
+
+// Checksum represents a content hash together with the algorithm used to create it.
+// It is stored as "<algorithm>:<hex>" within FileStat.Hash and AttributeChecksum.
+type Checksum struct {
+	Algorithm ChecksumAlgorithm
+	Value     string
+}
+
+// String returns the serialized form of the checksum.
+func (c Checksum) String() string {
+	if c.Value == "" {
+		return ""
+	}
+
+	return string(c.Algorithm) + ":" + c.Value
+}
+
+// IsEmpty checks if the checksum has no value.
+func (c Checksum) IsEmpty() bool {
+	return c.Value == ""
+}
+
+// Equal checks if both checksums were created by the same algorithm and have the same value.
+func (c Checksum) Equal(other Checksum) bool {
+	return c.Algorithm == other.Algorithm && strings.EqualFold(c.Value, other.Value)
+}
+
+// ParseChecksum parses a checksum in the form "<algorithm>:<hex>".
+// Values without algorithm prefix are treated as DefaultChecksumAlgorithm.
+func ParseChecksum(value string) (Checksum, error) {
+	algorithm, digest, found := strings.Cut(value, ":")
+	if !found {
+		algorithm, digest = string(DefaultChecksumAlgorithm), value
+	}
+
+	checksum := Checksum{
+		Algorithm: ChecksumAlgorithm(strings.ToLower(algorithm)),
+		Value:     strings.ToLower(digest),
+	}
+
+	if !checksum.Algorithm.IsValid() {
+		return Checksum{}, fmt.Errorf("%w: %s", ErrChecksumUnsupported, algorithm)
+	}
+
+	if _, err := hex.DecodeString(checksum.Value); err != nil || checksum.Value == "" {
+		return Checksum{}, fmt.Errorf("vfs: invalid checksum value '%s'", digest)
+	}
+
+	return checksum, nil
+}
+
+// ComputeChecksum reads everything from the reader and returns its checksum.
+func ComputeChecksum(reader io.Reader, algorithm ChecksumAlgorithm) (Checksum, error) {
+	h, err := algorithm.New()
+	if err != nil {
+		return Checksum{}, err
+	}
+
+	if _, err := io.Copy(h, reader); err != nil {
+		return Checksum{}, err
+	}
+
+	return Checksum{
+		Algorithm: algorithm,
+		Value:     hex.EncodeToString(h.Sum(nil)),
+	}, nil
+}
+
+// GetChecksum returns the stored content checksum, if available and valid.
+func (m *Metadata) GetChecksum() (Checksum, bool) {
+	value := m.GetAttribute(AttributeChecksum, "")
+	if value == "" {
+		return Checksum{}, false
+	}
+
+	checksum, err := ParseChecksum(value)
+	if err != nil {
+		return Checksum{}, false
+	}
+
+	return checksum, true
+}
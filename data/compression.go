@@ -0,0 +1,32 @@
+package data
+
+// CompressionAlgorithm identifies the algorithm used to compress stored content.
+type CompressionAlgorithm string
+
+const (
+	CompressionNone CompressionAlgorithm = "none" // Content is stored as is
+	CompressionGzip CompressionAlgorithm = "gzip"
+	CompressionZstd CompressionAlgorithm = "zstd"
+)
+
+// IsValid returns true, if the algorithm is supported.
+func (a CompressionAlgorithm) IsValid() bool {
+	return a == CompressionNone || a == CompressionGzip || a == CompressionZstd
+}
+
+// CompressionStats describes the storage saved by compressing content.
+type CompressionStats struct {
+	Objects       int64 `json:"objects"`        // Number of files
+	Compressed    int64 `json:"compressed"`     // Number of files stored compressed
+	LogicalBytes  int64 `json:"logical_bytes"`  // Size of all files
+	PhysicalBytes int64 `json:"physical_bytes"` // Bytes actually stored
+}
+
+// Ratio returns the ratio between logical and stored bytes (e.g. 2.0 if content is stored at half its size).
+func (cs *CompressionStats) Ratio() float64 {
+	if cs.PhysicalBytes <= 0 {
+		return 1
+	}
+
+	return float64(cs.LogicalBytes) / float64(cs.PhysicalBytes)
+}
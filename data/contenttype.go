@@ -1,6 +1,7 @@
 package data
 
 import (
+	"net/http"
 	"path/filepath"
 	"strings"
 )
@@ -86,6 +87,29 @@ var MIMEToExtension = map[ContentType]string{
 	ContentTypeApplicationXML:  ".xml",
 }
 
+// CompressedContentTypes contains all MIME types, which are already compressed by their format
+// and don't benefit from being compressed again.
+var CompressedContentTypes = map[ContentType]bool{
+	ContentTypeImageJPEG:       true,
+	ContentTypeImagePNG:        true,
+	ContentTypeImageGIF:        true,
+	ContentTypeImageWebP:       true,
+	ContentTypeAudioMpeg:       true,
+	ContentTypeAudioOGG:        true,
+	ContentTypeAudioWebM:       true,
+	ContentTypeVideoMP4:        true,
+	ContentTypeVideoWebM:       true,
+	ContentTypeVideoQuickTime:  true,
+	ContentTypeApplicationPDF:  true,
+	ContentTypeApplicationZip:  true,
+	ContentTypeApplicationGZip: true,
+}
+
+// IsCompressed returns true, if content of this MIME type is already compressed.
+func (ct ContentType) IsCompressed() bool {
+	return CompressedContentTypes[ct]
+}
+
 // GetMIMEType returns the MIME type for a file extension
 func GetMIMEType(path string) ContentType {
 	// Extract extension
@@ -99,6 +123,18 @@ func GetMIMEType(path string) ContentType {
 	return ContentTypeApplicationStream
 }
 
+// DetectContentType returns the MIME type for a file using its extension.
+// Files with unknown extensions are identified by sniffing the first bytes of their content.
+func DetectContentType(path string, head []byte) ContentType {
+	if mimeType := GetMIMEType(path); mimeType != ContentTypeApplicationStream || len(head) == 0 {
+		return mimeType
+	}
+
+	// Parameters like the charset are not part of the MIME types used here
+	mimeType, _, _ := strings.Cut(http.DetectContentType(head), ";")
+	return ContentType(mimeType)
+}
+
 // GetExtension returns the primary extension for a MIME type
 func GetExtension(mimeType ContentType) string {
 	if ext, exists := MIMEToExtension[mimeType]; exists {
@@ -0,0 +1,29 @@
+package data
+
+// DefaultCopyChunkSize defines the amount of bytes copied at once, if no chunk size is set.
+const DefaultCopyChunkSize = 1024 * 1024
+
+// CopyProgressFunc is called after each copied chunk with the absolute destination path,
+// the amount of bytes copied so far (including any resume offset) and the total size of the source.
+type CopyProgressFunc func(path string, copied, total int64)
+
+// CopyOptions controls how files and directories are copied.
+type CopyOptions struct {
+	Recursive           bool             // Copy directories including all nested entries
+	Overwrite           bool             // Replace existing destination files
+	PreserveMode        bool             // Copy the permission bits of the source
+	PreserveAttributes  bool             // Copy all attributes of the source
+	PreserveContentType bool             // Copy the content type of the source
+	Offset              int64            // Resume a previous copy of a single file at this byte offset
+	ChunkSize           int64            // Maximum amount of bytes copied at once (defaults to DefaultCopyChunkSize)
+	Progress            CopyProgressFunc // Optional callback to report the progress of each file
+}
+
+// GetChunkSize returns the configured chunk size or DefaultCopyChunkSize if unset.
+func (co *CopyOptions) GetChunkSize() int64 {
+	if co.ChunkSize <= 0 {
+		return DefaultCopyChunkSize
+	}
+
+	return co.ChunkSize
+}
@@ -0,0 +1,18 @@
+package data
+
+// DedupStats describes the storage saved by storing identical content only once.
+type DedupStats struct {
+	Blocks        int64 `json:"blocks"`         // Number of unique blocks stored
+	References    int64 `json:"references"`     // Number of references to all stored blocks
+	LogicalBytes  int64 `json:"logical_bytes"`  // Bytes referenced by all objects
+	PhysicalBytes int64 `json:"physical_bytes"` // Bytes actually stored
+}
+
+// Ratio returns the ratio between referenced and stored bytes (e.g. 2.0 if every block is stored for two objects).
+func (ds *DedupStats) Ratio() float64 {
+	if ds.PhysicalBytes <= 0 {
+		return 1
+	}
+
+	return float64(ds.LogicalBytes) / float64(ds.PhysicalBytes)
+}
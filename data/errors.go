@@ -26,4 +26,28 @@ var (
 	ErrBusy    = errors.New("vfs: file is busy")
 	ErrInvalid = errors.New("vfs: invalid argument")
 	ErrInUse   = errors.New("vfs: file already in use")
+
+	// Capability errors
+	ErrNotSupported = errors.New("vfs: operation not supported by backend")
+
+	// Transaction errors
+	ErrTransactionDone     = errors.New("vfs: transaction already committed or rolled back")
+	ErrTransactionConflict = errors.New("vfs: transaction conflicts with concurrent changes")
+	ErrCrossMount          = errors.New("vfs: path is outside of the transaction mount")
+
+	// Lock errors
+	ErrLocked      = errors.New("vfs: resource is locked")
+	ErrLockNotHeld = errors.New("vfs: lock is not held")
+
+	// Path errors
+	ErrInvalidPath = errors.New("vfs: invalid path")
+	ErrPathTooLong = errors.New("vfs: path too long")
+	ErrNameTooLong = errors.New("vfs: file name too long")
+
+	// Quota errors
+	ErrQuotaExceeded = errors.New("vfs: quota exceeded")
+
+	// Integrity errors
+	ErrChecksumUnsupported = errors.New("vfs: unsupported checksum algorithm")
+	ErrChecksumMismatch    = errors.New("vfs: checksum mismatch")
 )
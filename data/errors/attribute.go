@@ -0,0 +1,9 @@
+package errors
+
+func AttributeNotExist(err error, key string) error {
+	return newError(err, "attribute '%s' does not exist", key)
+}
+
+func AttributeInvalid(err error, key string) error {
+	return newError(err, "invalid attribute key '%s'", key)
+}
@@ -15,3 +15,23 @@ func BackendObjectTooSmall(err error, size, minSize int64) error {
 func BackendObjectTooLarge(err error, size, maxSize int64) error {
 	return newError(err, "object size %d bytes exceeds maximum allowed size %d bytes", size, maxSize)
 }
+
+func BackendUnknown(err error, name string) error {
+	return newError(err, "unknown backend '%s'", name)
+}
+
+func BackendParameterMissing(err error, name, param string) error {
+	return newError(err, "missing parameter '%s' for backend '%s'", param, name)
+}
+
+func BackendParameterInvalid(err error, name, param string) error {
+	return newError(err, "invalid parameter '%s' for backend '%s'", param, name)
+}
+
+func BackendParameterUnknown(err error, name, param string) error {
+	return newError(err, "unknown parameter '%s' for backend '%s'", param, name)
+}
+
+func BackendURLInvalid(err error, raw string) error {
+	return newError(err, "invalid backend url '%s'", raw)
+}
@@ -0,0 +1,129 @@
+package data
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// EventType identifies the kind of change reported by a filesystem event.
+// Event types are bit flags, so they can be combined to filter watched events.
+type EventType uint32
+
+const (
+	EventCreate     EventType = 1 << iota // File has been created
+	EventWrite                            // File content has been written
+	EventCloseWrite                       // File opened for writing has been closed
+	EventTruncate                         // File has been truncated
+	EventUnlink                           // File has been removed
+	EventMkdir                            // Directory has been created
+	EventRmdir                            // Directory has been removed
+	EventRename                           // File or directory has been moved from OldPath to Path
+	EventAttrib                           // Metadata (mode, owner, times, attributes) has changed
+	EventMount                            // Mount has been attached at Path
+	EventUnmount                          // Mount has been removed from Path
+	EventOverflow                         // Events have been dropped, since the watcher couldn't keep up
+
+	EventAll = ^EventType(0) // Matches all event types
+)
+
+var eventTypeNames = []struct {
+	eventType EventType
+	name      string
+}{
+	{EventCreate, "create"},
+	{EventWrite, "write"},
+	{EventCloseWrite, "close-write"},
+	{EventTruncate, "truncate"},
+	{EventUnlink, "unlink"},
+	{EventMkdir, "mkdir"},
+	{EventRmdir, "rmdir"},
+	{EventRename, "rename"},
+	{EventAttrib, "attrib"},
+	{EventMount, "mount"},
+	{EventUnmount, "unmount"},
+	{EventOverflow, "overflow"},
+}
+
+// String returns the names of all event types contained, separated by "|".
+func (et EventType) String() string {
+	if et == EventAll {
+		return "all"
+	}
+
+	names := make([]string, 0, 1)
+	for _, entry := range eventTypeNames {
+		if et&entry.eventType != 0 {
+			names = append(names, entry.name)
+		}
+	}
+
+	if len(names) == 0 {
+		return "none"
+	}
+
+	return strings.Join(names, "|")
+}
+
+// Matches checks if the event type is part of the filter.
+// An empty filter matches every event type.
+func (et EventType) Matches(filter EventType) bool {
+	return filter == 0 || et&filter != 0
+}
+
+// ParseEventType parses a comma or "|" separated list of event type names.
+func ParseEventType(value string) (EventType, error) {
+	var result EventType
+
+	fields := strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || r == '|'
+	})
+	for _, field := range fields {
+		field = strings.ToLower(strings.TrimSpace(field))
+		if field == "all" {
+			return EventAll, nil
+		}
+
+		found := false
+		for _, entry := range eventTypeNames {
+			if entry.name == field {
+				result |= entry.eventType
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return 0, fmt.Errorf("%w: unknown event type '%s'", ErrInvalid, field)
+		}
+	}
+
+	return result, nil
+}
+
+// Event describes a single change within the virtual filesystem.
+type Event struct {
+	Type    EventType `json:"type"`
+	Path    string    `json:"path"`               // Absolute path affected by the change
+	OldPath string    `json:"old_path,omitempty"` // Previous absolute path (only set for EventRename)
+	Mount   string    `json:"mount,omitempty"`    // Mount point the path belongs to
+	Time    time.Time `json:"time"`
+
+	// External is set for changes observed directly by the backend (e.g. made by another process),
+	// instead of being emitted by an operation of this VFS.
+	External bool `json:"external,omitempty"`
+	// Dropped contains the number of discarded events (only set for EventOverflow).
+	Dropped uint64 `json:"dropped,omitempty"`
+}
+
+// String returns a human-readable representation of the event.
+func (e Event) String() string {
+	switch {
+	case e.Type == EventOverflow:
+		return fmt.Sprintf("%s (%d dropped)", e.Type, e.Dropped)
+	case e.OldPath != "":
+		return fmt.Sprintf("%s %s -> %s", e.Type, e.OldPath, e.Path)
+	}
+
+	return fmt.Sprintf("%s %s", e.Type, e.Path)
+}
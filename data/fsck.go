@@ -0,0 +1,42 @@
+package data
+
+// FsckIssueType describes a single class of discrepancy between metadata and object storage.
+type FsckIssueType string
+
+const (
+	FsckMissingObject   FsckIssueType = "missing-object"   // File metadata without object
+	FsckOrphanDirectory FsckIssueType = "orphan-directory" // Directory metadata without object
+	FsckMissingMetadata FsckIssueType = "missing-metadata" // Object without metadata
+	FsckTypeMismatch    FsckIssueType = "type-mismatch"    // Directory in one layer, file in the other
+	FsckSizeMismatch    FsckIssueType = "size-mismatch"    // Size stored in metadata differs from the object
+	FsckETagMismatch    FsckIssueType = "etag-mismatch"    // ETag stored in metadata differs from the object
+)
+
+// FsckOptions controls which discrepancies are repaired by a consistency check.
+// Without any option set, all discrepancies are only reported.
+type FsckOptions struct {
+	Repair bool // Rebuild metadata of missing or drifted entries from object storage
+	Prune  bool // Remove metadata of entries no longer present in object storage
+}
+
+// FsckIssue describes a single discrepancy found by a consistency check.
+type FsckIssue struct {
+	Type     FsckIssueType `json:"type"`
+	Key      string        `json:"key"`
+	Metadata string        `json:"metadata,omitempty"` // Value stored in metadata (if any)
+	Storage  string        `json:"storage,omitempty"`  // Value reported by object storage (if any)
+	Repaired bool          `json:"repaired"`
+}
+
+// FsckReport lists all discrepancies found by a consistency check of a single mount.
+type FsckReport struct {
+	Path     string       `json:"path"`
+	Checked  int64        `json:"checked"`  // Number of distinct keys compared in both layers
+	Repaired int64        `json:"repaired"` // Number of issues repaired or pruned
+	Issues   []*FsckIssue `json:"issues,omitempty"`
+}
+
+// Clean checks if no discrepancies remain unrepaired.
+func (r *FsckReport) Clean() bool {
+	return r.Repaired == int64(len(r.Issues))
+}
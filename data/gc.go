@@ -0,0 +1,80 @@
+package data
+
+import "time"
+
+// DefaultGCGracePeriod is used, if garbage collection is requested without a grace period.
+// Orphans are only removed immediately, if requested explicitly with GCOptions.Immediate.
+const DefaultGCGracePeriod = 10 * time.Minute
+
+// GCOptions controls a single garbage collection run.
+type GCOptions struct {
+	DryRun      bool          // Only report orphaned content without removing it
+	GracePeriod time.Duration // Orphans modified within this period are kept, so in-flight writes are never removed (0 uses DefaultGCGracePeriod)
+	Immediate   bool          // Remove orphans regardless of their modification time, which may remove content of in-flight writes
+	Vacuum      bool          // Compact the underlying storage afterwards, if supported (e.g. SQLite VACUUM)
+
+	// SweepObjects also removes objects without metadata on mounts using a separate metadata backend.
+	// This is disabled by default, since such objects are otherwise adopted on first access (e.g. pre-existing S3 content).
+	SweepObjects bool
+}
+
+// GCReport describes the orphaned content found (and removed) by a garbage collection run.
+type GCReport struct {
+	Path     string   `json:"path,omitempty"`     // Mount the report belongs to
+	DryRun   bool     `json:"dry_run"`            // Whether orphans have only been reported
+	Scanned  int64    `json:"scanned"`            // Number of entries checked for references
+	Orphans  int64    `json:"orphans"`            // Number of unreferenced entries found
+	Bytes    int64    `json:"bytes"`              // Bytes used by all unreferenced entries found
+	Removed  int64    `json:"removed"`            // Number of unreferenced entries removed
+	Deferred int64    `json:"deferred"`           // Number of unreferenced entries kept due to the grace period
+	Vacuumed bool     `json:"vacuumed,omitempty"` // Whether the underlying storage has been compacted
+	Keys     []string `json:"keys,omitempty"`     // Identifiers of all unreferenced entries found
+}
+
+// Orphan records a single unreferenced entry with the specified size.
+// Entries are only counted as removed, if this isn't a dry run and they weren't deferred.
+func (r *GCReport) Orphan(key string, size int64, deferred bool) {
+	r.Orphans++
+	r.Bytes += size
+	r.Keys = append(r.Keys, key)
+
+	switch {
+	case deferred:
+		r.Deferred++
+	case !r.DryRun:
+		r.Removed++
+	}
+}
+
+// Merge adds all counters and keys of other to this report.
+func (r *GCReport) Merge(other *GCReport) {
+	if other == nil {
+		return
+	}
+
+	r.Scanned += other.Scanned
+	r.Orphans += other.Orphans
+	r.Bytes += other.Bytes
+	r.Removed += other.Removed
+	r.Deferred += other.Deferred
+	r.Vacuumed = r.Vacuumed || other.Vacuumed
+	r.Keys = append(r.Keys, other.Keys...)
+}
+
+// GetGracePeriod returns the configured grace period, DefaultGCGracePeriod if unset or 0 if orphans are removed immediately.
+func (opts *GCOptions) GetGracePeriod() time.Duration {
+	switch {
+	case opts.Immediate:
+		return 0
+	case opts.GracePeriod > 0:
+		return opts.GracePeriod
+	default:
+		return DefaultGCGracePeriod
+	}
+}
+
+// Expired checks if content last modified at modified is older than the grace period of opts.
+func (opts *GCOptions) Expired(modified time.Time) bool {
+	grace := opts.GetGracePeriod()
+	return grace == 0 || time.Since(modified) >= grace
+}
@@ -0,0 +1,148 @@
+package data
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DefaultLockLease is the lease used for locks, if no lease has been specified.
+// Locks which aren't refreshed within their lease expire and are released automatically.
+const DefaultLockLease = 30 * time.Second
+
+// LockType defines whether a lock can be shared with other owners.
+type LockType int
+
+const (
+	LockShared    LockType = iota + 1 // Shared (read) lock, compatible with other shared locks
+	LockExclusive                     // Exclusive (write) lock, incompatible with any other lock
+)
+
+// IsValid returns true if the lock type is known.
+func (lt LockType) IsValid() bool {
+	return lt == LockShared || lt == LockExclusive
+}
+
+// String returns the name of the lock type.
+func (lt LockType) String() string {
+	switch lt {
+	case LockShared:
+		return "shared"
+	case LockExclusive:
+		return "exclusive"
+	}
+
+	return "unknown"
+}
+
+// LockOptions configures the byte range, lease and owner of a lock.
+type LockOptions struct {
+	Offset int64         // Start of the locked byte range
+	Length int64         // Length of the locked byte range (0 locks until the end of the file)
+	Lease  time.Duration // Duration until the lock expires, unless refreshed (0 uses DefaultLockLease)
+	Owner  string        // Owner of the lock (empty uses the owner of the context or the lock itself)
+}
+
+// GetLease returns the configured lease or DefaultLockLease.
+func (lo *LockOptions) GetLease() time.Duration {
+	if lo == nil || lo.Lease <= 0 {
+		return DefaultLockLease
+	}
+
+	return lo.Lease
+}
+
+// LockInfo describes a single lock held on a key.
+type LockInfo struct {
+	ID      string    `json:"id"`
+	Key     string    `json:"key"`
+	Owner   string    `json:"owner"`
+	Type    LockType  `json:"type"`
+	Offset  int64     `json:"offset"`
+	Length  int64     `json:"length"`
+	Lease   int64     `json:"lease"` // Lease in nanoseconds
+	Expires time.Time `json:"expires"`
+}
+
+// NewLockInfo creates a new lock for key, which expires after the lease configured by opts.
+// Without owner, the lock is owned by itself, so only its own handle can release it.
+func NewLockInfo(key string, lockType LockType, owner string, opts *LockOptions) *LockInfo {
+	id := uuid.Must(uuid.NewV7()).String()
+	if owner == "" {
+		owner = id
+	}
+
+	lease := opts.GetLease()
+	lock := &LockInfo{
+		ID:      id,
+		Key:     key,
+		Owner:   owner,
+		Type:    lockType,
+		Lease:   int64(lease),
+		Expires: time.Now().Add(lease),
+	}
+	if opts != nil {
+		lock.Offset = opts.Offset
+		lock.Length = opts.Length
+	}
+
+	return lock
+}
+
+// Renew extends the expiry of the lock by its lease, starting at now.
+func (li *LockInfo) Renew(now time.Time) {
+	li.Expires = now.Add(time.Duration(li.Lease))
+}
+
+// IsExpired returns true if the lease of the lock has expired at now.
+func (li *LockInfo) IsExpired(now time.Time) bool {
+	return !li.Expires.IsZero() && !now.Before(li.Expires)
+}
+
+// Overlaps returns true if the byte range of the lock overlaps with offset and length.
+// A length of 0 covers everything until the end of the file.
+func (li *LockInfo) Overlaps(offset, length int64) bool {
+	if li.Length > 0 && offset >= li.Offset+li.Length {
+		return false
+	}
+	if length > 0 && li.Offset >= offset+length {
+		return false
+	}
+
+	return true
+}
+
+// Conflicts returns true if both locks can't be held at the same time.
+// Locks of the same owner never conflict with each other.
+func (li *LockInfo) Conflicts(other *LockInfo) bool {
+	if li.Owner == other.Owner || li.Key != other.Key {
+		return false
+	}
+	if li.Type == LockShared && other.Type == LockShared {
+		return false
+	}
+
+	return li.Overlaps(other.Offset, other.Length)
+}
+
+// Clone creates a copy of the lock.
+func (li *LockInfo) Clone() *LockInfo {
+	clone := *li
+	return &clone
+}
+
+// lockOwnerKey is the context key used to store the lock owner.
+type lockOwnerKey struct{}
+
+// WithLockOwner returns a context, whose locks and opened files belong to owner.
+// This ties locks to a session, so all operations of the session share the same locks.
+func WithLockOwner(ctx context.Context, owner string) context.Context {
+	return context.WithValue(ctx, lockOwnerKey{}, owner)
+}
+
+// LockOwner returns the lock owner stored in ctx or an empty string.
+func LockOwner(ctx context.Context) string {
+	owner, _ := ctx.Value(lockOwnerKey{}).(string)
+	return owner
+}
@@ -2,6 +2,7 @@ package data
 
 import (
 	"encoding/json"
+	"maps"
 	"time"
 )
 
@@ -64,6 +65,7 @@ func (m *Metadata) Unmarshal(data []byte) error {
 func (m *Metadata) ToStat() *FileStat {
 	return &FileStat{
 		Key:         m.Key,
+		Hash:        m.GetAttribute(AttributeChecksum, ""),
 		Mode:        m.Mode,
 		Size:        m.Size,
 		ModifyTime:  m.ModifyTime,
@@ -94,5 +96,8 @@ func (m *Metadata) GetType() FileType {
 // Clone creates a deep copy of the object info.
 func (m *Metadata) Clone() *Metadata {
 	clone := *m
+	if m.Attributes != nil {
+		clone.Attributes = maps.Clone(m.Attributes)
+	}
 	return &clone
 }
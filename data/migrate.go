@@ -0,0 +1,42 @@
+package data
+
+// DefaultMigrateRounds is the number of passes syncing changes made during a migration, if no limit is defined.
+const DefaultMigrateRounds = 3
+
+// MigrateProgressFunc is called after each migrated entry with its mount-relative key
+// and the number of entries migrated so far.
+type MigrateProgressFunc func(key string, migrated int64)
+
+// MigrateOptions controls the live migration of a mount to another set of backends.
+type MigrateOptions struct {
+	Verify    bool                // Compare the checksum of every file before switching to the new backends
+	Checksum  ChecksumAlgorithm   // Algorithm used for verification (defaults to the mount checksum or SHA-256)
+	MaxRounds int                 // Maximum number of passes syncing changes before the final switch (defaults to DefaultMigrateRounds)
+	Progress  MigrateProgressFunc // Optional callback to report the progress of the initial copy
+}
+
+// GetChecksum returns the configured algorithm or fallback, if unset.
+func (mo *MigrateOptions) GetChecksum(fallback ChecksumAlgorithm) ChecksumAlgorithm {
+	switch {
+	case mo.Checksum != "":
+		return mo.Checksum
+	case fallback != "":
+		return fallback
+	default:
+		return ChecksumSHA256
+	}
+}
+
+// MigrateReport describes a completed migration.
+type MigrateReport struct {
+	Path        string `json:"path"`
+	Source      string `json:"source"`      // Name of the previous object storage
+	Target      string `json:"target"`      // Name of the new object storage
+	Directories int64  `json:"directories"` // Number of directories copied
+	Files       int64  `json:"files"`       // Number of files copied
+	Bytes       int64  `json:"bytes"`       // Total size of all copied files
+	Synced      int64  `json:"synced"`      // Number of entries synced again after being changed during the migration
+	Removed     int64  `json:"removed"`     // Number of entries removed from the target after being removed during the migration
+	Rounds      int    `json:"rounds"`      // Number of passes syncing changes, including the final pass
+	Verified    int64  `json:"verified"`    // Number of files with matching checksums
+}
@@ -2,22 +2,147 @@ package data
 
 import (
 	"fmt"
+	"path"
 	"strings"
+	"unicode/utf8"
+)
 
-	"github.com/mwantia/vfs/data/errors"
+const (
+	DefaultMaxPathLength = 4096 // Default maximum length of a canonical path in bytes
+	DefaultMaxNameLength = 255  // Default maximum length of a single path segment in bytes
 )
 
-// ToAbsolutePath ensures the path always starts with a leading slash.
-func ToAbsolutePath(path string) (string, error) {
-	if len(path) == 0 {
-		return "", errors.InvalidPath(nil, path)
+// PathLimits defines the maximum lengths accepted when parsing paths.
+// A limit of zero or less disables the check.
+type PathLimits struct {
+	MaxPathLength int
+	MaxNameLength int
+}
+
+// DefaultPathLimits are the limits used by ParsePath and ToAbsolutePath.
+var DefaultPathLimits = PathLimits{
+	MaxPathLength: DefaultMaxPathLength,
+	MaxNameLength: DefaultMaxNameLength,
+}
+
+// Path is a canonical absolute path within the VFS.
+// It always starts with a slash, contains no empty, "." or ".." segments
+// and has no trailing slash, except for the root "/" itself.
+type Path string
+
+// RootPath is the canonical path of the VFS root.
+const RootPath Path = "/"
+
+// ParsePath converts raw into a canonical path using the default limits.
+func ParsePath(raw string) (Path, error) {
+	return DefaultPathLimits.Parse(raw)
+}
+
+// Parse converts raw into a canonical path.
+// Relative paths are treated as relative to the root, duplicate slashes and "."
+// segments are removed and ".." segments are resolved, but never beyond the root.
+// Returns ErrInvalidPath for empty paths, NUL bytes or invalid UTF-8, and
+// ErrPathTooLong or ErrNameTooLong if the canonical path exceeds the limits.
+func (pl PathLimits) Parse(raw string) (Path, error) {
+	if raw == "" {
+		return "", fmt.Errorf("%w: path is empty", ErrInvalidPath)
+	}
+	if strings.IndexByte(raw, 0) >= 0 {
+		return "", fmt.Errorf("%w: path %q contains a NUL byte", ErrInvalidPath, raw)
+	}
+	if !utf8.ValidString(raw) {
+		return "", fmt.Errorf("%w: path %q is not valid UTF-8", ErrInvalidPath, raw)
+	}
+
+	// Cleaning a rooted path drops all ".." segments that would leave the root
+	cleaned := path.Clean("/" + raw)
+	if pl.MaxPathLength > 0 && len(cleaned) > pl.MaxPathLength {
+		return "", fmt.Errorf("%w: path has %d bytes, limit is %d", ErrPathTooLong, len(cleaned), pl.MaxPathLength)
+	}
+	if pl.MaxNameLength > 0 {
+		for _, name := range strings.Split(cleaned[1:], "/") {
+			if len(name) > pl.MaxNameLength {
+				return "", fmt.Errorf("%w: name %q has %d bytes, limit is %d", ErrNameTooLong, name, len(name), pl.MaxNameLength)
+			}
+		}
+	}
+
+	return Path(cleaned), nil
+}
+
+// String returns the path as string.
+func (p Path) String() string {
+	return string(p)
+}
+
+// IsRoot checks if the path is the VFS root.
+func (p Path) IsRoot() bool {
+	return p == RootPath
+}
+
+// Name returns the last segment of the path or an empty string for the root.
+func (p Path) Name() string {
+	if p.IsRoot() {
+		return ""
+	}
+
+	return path.Base(string(p))
+}
+
+// Parent returns the parent of the path. The parent of the root is the root itself.
+func (p Path) Parent() Path {
+	return Path(path.Dir(string(p)))
+}
+
+// Segments returns all names of the path in order. The root has no segments.
+func (p Path) Segments() []string {
+	if p.IsRoot() {
+		return nil
 	}
 
-	if !strings.HasPrefix(path, "/") {
-		path = fmt.Sprintf("/%s", path)
+	return strings.Split(string(p)[1:], "/")
+}
+
+// Join appends elems to the path and parses the result using the default limits.
+// Elements containing ".." can never leave the root.
+func (p Path) Join(elems ...string) (Path, error) {
+	return ParsePath(string(p) + "/" + strings.Join(elems, "/"))
+}
+
+// HasPrefix checks if prefix equals the path or is one of its ancestors.
+// Unlike strings.HasPrefix, "/ab" doesn't have the prefix "/a".
+func (p Path) HasPrefix(prefix Path) bool {
+	if prefix.IsRoot() || p == prefix {
+		return true
+	}
+
+	return strings.HasPrefix(string(p), string(prefix)+"/")
+}
+
+// Relative returns the path relative to prefix without leading slash.
+// Returns false if prefix is not an ancestor of the path.
+func (p Path) Relative(prefix Path) (string, bool) {
+	if !p.HasPrefix(prefix) {
+		return "", false
+	}
+	if p == prefix {
+		return "", true
+	}
+	if prefix.IsRoot() {
+		return string(p)[1:], true
+	}
+
+	return string(p)[len(prefix)+1:], true
+}
+
+// ToAbsolutePath converts path into its canonical absolute form using the default limits.
+func ToAbsolutePath(path string) (string, error) {
+	canonical, err := ParsePath(path)
+	if err != nil {
+		return "", err
 	}
 
-	return path, nil
+	return canonical.String(), nil
 }
 
 // ToRelativePath removes the prefix from path.
@@ -0,0 +1,50 @@
+package data
+
+// Quota limits the storage used within a scope (mount, namespace or user).
+// Zero values are unlimited.
+type Quota struct {
+	MaxBytes   int64 `json:"max_bytes,omitempty"`   // Maximum total size of all objects in bytes
+	MaxObjects int64 `json:"max_objects,omitempty"` // Maximum number of files and directories
+}
+
+// IsUnlimited returns true, if neither bytes nor objects are limited.
+func (q Quota) IsUnlimited() bool {
+	return q.MaxBytes <= 0 && q.MaxObjects <= 0
+}
+
+// QuotaUsage describes the storage used within a scope together with its quota.
+type QuotaUsage struct {
+	Quota   Quota `json:"quota"`
+	Bytes   int64 `json:"bytes"`
+	Objects int64 `json:"objects"`
+}
+
+// AvailableBytes returns the number of bytes left until the quota is reached or -1 if unlimited.
+func (qu *QuotaUsage) AvailableBytes() int64 {
+	if qu.Quota.MaxBytes <= 0 {
+		return -1
+	}
+
+	return max(qu.Quota.MaxBytes-qu.Bytes, 0)
+}
+
+// AvailableObjects returns the number of objects left until the quota is reached or -1 if unlimited.
+func (qu *QuotaUsage) AvailableObjects() int64 {
+	if qu.Quota.MaxObjects <= 0 {
+		return -1
+	}
+
+	return max(qu.Quota.MaxObjects-qu.Objects, 0)
+}
+
+// Usage reports the storage used by a mount.
+type Usage struct {
+	Path      string `json:"path"`                // Path of the mount
+	Namespace string `json:"namespace,omitempty"` // Namespace of the mount
+
+	Mount          QuotaUsage            `json:"mount"`                     // Usage of the mount itself
+	NamespaceUsage *QuotaUsage           `json:"namespace_usage,omitempty"` // Usage of all mounts sharing the namespace quota (nil without quota)
+	Users          map[int64]*QuotaUsage `json:"users,omitempty"`           // Usage per owning UID
+	Dedup          *DedupStats           `json:"dedup,omitempty"`           // Storage saved by deduplication (nil if unsupported)
+	Compression    *CompressionStats     `json:"compression,omitempty"`     // Storage saved by compression (nil if unsupported)
+}
@@ -0,0 +1,62 @@
+package data
+
+import (
+	"context"
+	"io"
+)
+
+// ReadAtFunc reads up to len(p) bytes starting at offset.
+// Returning io.EOF together with the bytes read is allowed for the last part of the content.
+type ReadAtFunc func(ctx context.Context, offset int64, p []byte) (int, error)
+
+// RangeReader provides sequential read access to content between an offset and its end.
+// Content is read through the specified function, so it never depends on the offset of opened files.
+type RangeReader struct {
+	ctx    context.Context
+	read   ReadAtFunc
+	offset int64
+	end    int64
+}
+
+// NewRangeReader creates a reader, which reads using read from offset until end is reached.
+func NewRangeReader(ctx context.Context, read ReadAtFunc, offset, end int64) *RangeReader {
+	return &RangeReader{
+		ctx:    ctx,
+		read:   read,
+		offset: offset,
+		end:    end,
+	}
+}
+
+// Size returns the offset, at which the reader stops reading.
+func (rr *RangeReader) Size() int64 {
+	return rr.end
+}
+
+// Read reads up to len(p) bytes and advances the offset.
+// Content ending before the expected end is reported as io.ErrUnexpectedEOF.
+func (rr *RangeReader) Read(p []byte) (int, error) {
+	if rr.offset >= rr.end {
+		return 0, io.EOF
+	}
+
+	if err := rr.ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	p = p[:min(int64(len(p)), rr.end-rr.offset)]
+	n, err := rr.read(rr.ctx, rr.offset, p)
+	rr.offset += int64(n)
+
+	if err == io.EOF {
+		if n > 0 {
+			return n, nil
+		}
+		return 0, io.ErrUnexpectedEOF
+	}
+	if err == nil && n == 0 {
+		return 0, io.ErrUnexpectedEOF
+	}
+
+	return n, err
+}
@@ -0,0 +1,25 @@
+package data
+
+const (
+	// DefaultReindexConcurrency is the number of directories listed in parallel, if no concurrency is defined.
+	DefaultReindexConcurrency = 4
+	// DefaultReindexBatchSize is the number of entries stored at once, if no batch size is defined.
+	DefaultReindexBatchSize = 100
+)
+
+// ReindexOptions controls a full metadata reindex from object storage.
+type ReindexOptions struct {
+	Concurrency int  // Number of directories listed in parallel
+	BatchSize   int  // Number of entries stored within metadata at once
+	Checksums   bool // Compute content hashes using the checksum algorithm of the mount
+	Resume      bool // Skip all files within directories already completed by an interrupted run
+}
+
+// ReindexReport describes the entries indexed by a single reindex run.
+type ReindexReport struct {
+	Path        string `json:"path"`
+	Directories int64  `json:"directories"` // Number of directories listed
+	Created     int64  `json:"created"`     // Number of entries without previous metadata
+	Updated     int64  `json:"updated"`     // Number of entries with existing metadata
+	Skipped     int64  `json:"skipped"`     // Number of files skipped, since they were completed by a previous run
+}
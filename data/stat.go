@@ -31,7 +31,7 @@ type FileStat struct {
 
 // ToMetadata converts FileStat into a FileMetadata
 func (fs *FileStat) ToMetadata() *Metadata {
-	return &Metadata{
+	meta := &Metadata{
 		ID:          genMetadataID(),
 		Key:         fs.Key,
 		Mode:        fs.Mode,
@@ -43,6 +43,12 @@ func (fs *FileStat) ToMetadata() *Metadata {
 		Attributes:  make(map[string]string),
 		ETag:        fs.ETag,
 	}
+	// Content hashes are persisted as attribute within metadata
+	if fs.Hash != "" {
+		meta.Attributes[AttributeChecksum] = fs.Hash
+	}
+
+	return meta
 }
 
 // Marshal provides JSON serialization for Inode.
@@ -0,0 +1,109 @@
+package data
+
+import (
+	"fmt"
+	"time"
+)
+
+// DefaultTierAccessInterval is the minimum interval between access time updates on read, if no interval is defined.
+const DefaultTierAccessInterval = time.Hour
+
+// StorageTier identifies a tier of a tiered object storage.
+type StorageTier string
+
+const (
+	TierHot  StorageTier = "hot"  // Fast storage for frequently accessed content
+	TierCold StorageTier = "cold" // Slow storage for rarely accessed content
+)
+
+// IsValid returns true, if the tier is known.
+func (t StorageTier) IsValid() bool {
+	return t == TierHot || t == TierCold
+}
+
+// TierRule selects the tier of all files matching every condition set within the rule.
+type TierRule struct {
+	Tier       StorageTier       `json:"tier"`
+	MinIdle    time.Duration     `json:"min_idle,omitempty"`   // Matches files not accessed for at least this duration
+	MinSize    int64             `json:"min_size,omitempty"`   // Matches files of at least this size
+	MaxSize    int64             `json:"max_size,omitempty"`   // Matches files of at most this size (zero is unlimited)
+	Attributes map[string]string `json:"attributes,omitempty"` // Matches files with these attributes (an empty value matches any value)
+}
+
+// Matches returns true, if meta fulfills all conditions of this rule.
+func (r *TierRule) Matches(meta *Metadata, now time.Time) bool {
+	if r.MinIdle > 0 && now.Sub(meta.AccessTime) < r.MinIdle {
+		return false
+	}
+	if meta.Size < r.MinSize {
+		return false
+	}
+	if r.MaxSize > 0 && meta.Size > r.MaxSize {
+		return false
+	}
+	for key, value := range r.Attributes {
+		if !meta.HasAttribute(key) || (value != "" && meta.GetAttribute(key, "") != value) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// TierPolicy decides which tier holds the content of each file within a tiered mount.
+type TierPolicy struct {
+	Rules          []TierRule    `json:"rules"`                     // Rules evaluated in order, where the first matching rule wins
+	Default        StorageTier   `json:"default,omitempty"`         // Tier of files matching no rule (defaults to TierHot)
+	AccessInterval time.Duration `json:"access_interval,omitempty"` // Minimum interval between access time updates on read
+}
+
+// Validate returns an error, if any rule or the default refers to an unknown tier.
+func (p *TierPolicy) Validate() error {
+	if p.Default != "" && !p.Default.IsValid() {
+		return fmt.Errorf("%w: unknown tier '%s'", ErrInvalid, p.Default)
+	}
+	for i, rule := range p.Rules {
+		if !rule.Tier.IsValid() {
+			return fmt.Errorf("%w: unknown tier '%s' in rule %d", ErrInvalid, rule.Tier, i)
+		}
+	}
+
+	return nil
+}
+
+// Evaluate returns the tier selected for meta by the first matching rule or the default tier.
+func (p *TierPolicy) Evaluate(meta *Metadata, now time.Time) StorageTier {
+	for i := range p.Rules {
+		if p.Rules[i].Matches(meta, now) {
+			return p.Rules[i].Tier
+		}
+	}
+	if p.Default != "" {
+		return p.Default
+	}
+
+	return TierHot
+}
+
+// GetAccessInterval returns the configured access interval or DefaultTierAccessInterval if unset.
+func (p *TierPolicy) GetAccessInterval() time.Duration {
+	if p.AccessInterval <= 0 {
+		return DefaultTierAccessInterval
+	}
+
+	return p.AccessInterval
+}
+
+// TierOptions controls a single pass moving files between tiers.
+type TierOptions struct {
+	DryRun bool // Only report files, which would be moved
+}
+
+// TierReport describes the files moved by a single tiering pass.
+type TierReport struct {
+	Path     string `json:"path"`
+	Checked  int64  `json:"checked"`  // Number of files evaluated against the policy
+	Promoted int64  `json:"promoted"` // Number of files moved to the hot tier
+	Demoted  int64  `json:"demoted"`  // Number of files moved to the cold tier
+	Bytes    int64  `json:"bytes"`    // Total size of all moved files
+}
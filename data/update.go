@@ -11,13 +11,17 @@ type MetadataUpdateMask int
 
 const (
 	MetadataUpdateKey         MetadataUpdateMask = 1 << iota // Update Relative Path
-	MetadataUpdateStorageHash                                // Update Storage Hash
+	MetadataUpdateStorageHash                                // Update Storage Hash (AttributeChecksum only)
 	MetadataUpdateStorageKey                                 // Update Storage Key
 	MetadataUpdateMode                                       // Update File Mode (permissions)
 	MetadataUpdateSize                                       // Update Size
 	MetadataUpdateUID                                        // Update UID
 	MetadataUpdateGID                                        // Update GID
 	MetadataUpdateAttributes                                 // Update Attributes map
+	MetadataUpdateAccessTime                                 // Update Access Time
+	MetadataUpdateModifyTime                                 // Update Modify Time (explicitly)
+	MetadataUpdateContentType                                // Update Content Type
+	MetadataUpdateETag                                       // Update ETag
 
 	MetadataUpdateAll = ^MetadataUpdateMask(0) // Update all fields
 )
@@ -39,6 +43,19 @@ func (mu *MetadataUpdate) Apply(target *Metadata) (bool, error) {
 		modified = true
 	}
 
+	if mu.Mask&MetadataUpdateStorageHash != 0 {
+		// An empty checksum removes any stale hash from the target
+		if checksum := mu.Metadata.GetAttribute(AttributeChecksum, ""); checksum != "" {
+			if target.Attributes == nil {
+				target.Attributes = make(map[string]string)
+			}
+			target.Attributes[AttributeChecksum] = checksum
+		} else if target.Attributes != nil {
+			delete(target.Attributes, AttributeChecksum)
+		}
+		modified = true
+	}
+
 	if mu.Mask&MetadataUpdateMode != 0 {
 		target.Mode = mu.Metadata.Mode
 		modified = true
@@ -64,6 +81,27 @@ func (mu *MetadataUpdate) Apply(target *Metadata) (bool, error) {
 		modified = true
 	}
 
+	if mu.Mask&MetadataUpdateContentType != 0 {
+		target.ContentType = mu.Metadata.ContentType
+		modified = true
+	}
+
+	if mu.Mask&MetadataUpdateETag != 0 {
+		target.ETag = mu.Metadata.ETag
+		modified = true
+	}
+
+	if mu.Mask&MetadataUpdateAccessTime != 0 {
+		target.AccessTime = mu.Metadata.AccessTime
+		modified = true
+	}
+
+	// Explicit modify times take precedence over the implicit update below
+	if mu.Mask&MetadataUpdateModifyTime != 0 {
+		target.ModifyTime = mu.Metadata.ModifyTime
+		return true, nil
+	}
+
 	// Only update ModifyTime if any form of modification actually happened
 	if modified {
 		target.ModifyTime = time.Now()
@@ -0,0 +1,160 @@
+package vfs_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/mwantia/vfs"
+	"github.com/mwantia/vfs/data"
+	"github.com/mwantia/vfs/log"
+	"github.com/mwantia/vfs/mount/backend/cas"
+	"github.com/mwantia/vfs/mount/backend/ephemeral"
+)
+
+// TestDedup_Mount verifies that identical content written to different files is stored once and released on delete.
+func TestDedup_Mount(t *testing.T) {
+	ctx := t.Context()
+	fs, err := vfs.NewVirtualFileSystem(vfs.WithLogLevel(log.Error))
+	if err != nil {
+		t.Fatalf("Failed to initialize vfs: %v", err)
+	}
+	defer fs.Shutdown(ctx)
+
+	storage, err := cas.NewCASBackend(ephemeral.NewEphemeralBackend(), 16)
+	if err != nil {
+		t.Fatalf("Failed to create cas backend: %v", err)
+	}
+	if err := fs.Mount(ctx, "/", storage); err != nil {
+		t.Fatalf("Failed to mount /: %v", err)
+	}
+
+	content := bytes.Repeat([]byte("0123456789abcdef"), 4)
+	writeTestFile(t, fs, "/a.txt", content)
+	writeTestFile(t, fs, "/b.txt", content)
+
+	for _, path := range []string{"/a.txt", "/b.txt"} {
+		result, err := fs.ReadFile(ctx, path, 0, int64(len(content)))
+		if err != nil || !bytes.Equal(result, content) {
+			t.Errorf("Unexpected content of %s: %q (%v)", path, result, err)
+		}
+	}
+
+	usage, err := fs.Usage(ctx, "/")
+	if err != nil {
+		t.Fatalf("Usage failed: %v", err)
+	}
+	if usage.Dedup == nil {
+		t.Fatal("Expected dedup stats for cas mount")
+	}
+	// Both files consist of the same four blocks, which are identical themselves
+	if usage.Dedup.Blocks != 1 || usage.Dedup.References != 8 || usage.Dedup.Ratio() != 8 {
+		t.Errorf("Unexpected dedup stats: %+v (ratio %.2f)", usage.Dedup, usage.Dedup.Ratio())
+	}
+
+	// Partial writes only replace the affected block
+	if _, err := fs.WriteFile(ctx, "/b.txt", 20, []byte("XY")); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	if result, err := fs.ReadFile(ctx, "/b.txt", 16, 8); err != nil || string(result) != "0123XY67" {
+		t.Errorf("Unexpected content after write: %q (%v)", result, err)
+	}
+	if result, err := fs.ReadFile(ctx, "/a.txt", 16, 8); err != nil || string(result) != "01234567" {
+		t.Errorf("Write changed shared content: %q (%v)", result, err)
+	}
+
+	entries, err := fs.ReadDirectory(ctx, "/")
+	if err != nil {
+		t.Fatalf("ReadDirectory failed: %v", err)
+	}
+	for _, entry := range entries {
+		if entry.Key == ".cas" {
+			t.Error("Expected block storage to be hidden from listings")
+		}
+	}
+
+	for _, path := range []string{"/a.txt", "/b.txt"} {
+		if err := fs.UnlinkFile(ctx, path); err != nil {
+			t.Fatalf("UnlinkFile %s failed: %v", path, err)
+		}
+	}
+
+	stats, err := storage.DedupStats(ctx)
+	if err != nil {
+		t.Fatalf("DedupStats failed: %v", err)
+	}
+	if stats.Blocks != 0 || stats.PhysicalBytes != 0 {
+		t.Errorf("Expected all blocks to be released, got %+v", stats)
+	}
+}
+
+// TestDedup_Backend verifies reference counting across namespaces, truncation and copies.
+func TestDedup_Backend(t *testing.T) {
+	ctx := t.Context()
+	storage, err := cas.NewCASBackend(ephemeral.NewEphemeralBackend(), 8)
+	if err != nil {
+		t.Fatalf("Failed to create cas backend: %v", err)
+	}
+	if err := storage.Open(ctx); err != nil {
+		t.Fatalf("Failed to open cas backend: %v", err)
+	}
+	defer storage.Close(ctx)
+
+	content := []byte("shared--content!")
+	for _, namespace := range []string{"alpha", "beta"} {
+		if _, err := storage.CreateObject(ctx, namespace, "file", 0644); err != nil {
+			t.Fatalf("CreateObject in %s failed: %v", namespace, err)
+		}
+		if _, err := storage.WriteObject(ctx, namespace, "file", 0, content); err != nil {
+			t.Fatalf("WriteObject in %s failed: %v", namespace, err)
+		}
+	}
+
+	expect := func(blocks, references, physical int64) {
+		t.Helper()
+		stats, err := storage.DedupStats(ctx)
+		if err != nil {
+			t.Fatalf("DedupStats failed: %v", err)
+		}
+		if stats.Blocks != blocks || stats.References != references || stats.PhysicalBytes != physical {
+			t.Errorf("Expected %d blocks with %d references in %d bytes, got %+v", blocks, references, physical, stats)
+		}
+	}
+	expect(2, 4, 16)
+
+	if _, err := storage.CopyObject(ctx, "alpha", "file", "alpha", "copy"); err != nil {
+		t.Fatalf("CopyObject failed: %v", err)
+	}
+	expect(2, 6, 16)
+
+	// Truncating into the middle of a block stores the remaining content as a new block
+	if err := storage.TruncateObject(ctx, "beta", "file", 4); err != nil {
+		t.Fatalf("TruncateObject failed: %v", err)
+	}
+	expect(3, 5, 20)
+	if err := storage.TruncateObject(ctx, "beta", "file", 10); err != nil {
+		t.Fatalf("TruncateObject failed: %v", err)
+	}
+
+	buffer := make([]byte, 16)
+	n, err := storage.ReadObject(ctx, "beta", "file", 0, buffer)
+	if err != nil || n != 10 || !bytes.Equal(buffer[:n], append([]byte("shar"), make([]byte, 6)...)) {
+		t.Errorf("Unexpected content after truncate: %q (%v)", buffer[:n], err)
+	}
+
+	stat, err := storage.HeadObject(ctx, "alpha", "copy")
+	if err != nil || stat.Size != int64(len(content)) {
+		t.Errorf("Expected copy to report the logical size, got %+v (%v)", stat, err)
+	}
+
+	for _, target := range [][2]string{{"alpha", "file"}, {"alpha", "copy"}, {"beta", "file"}} {
+		if err := storage.DeleteObject(ctx, target[0], target[1], false); err != nil {
+			t.Fatalf("DeleteObject %s/%s failed: %v", target[0], target[1], err)
+		}
+	}
+	expect(0, 0, 0)
+
+	if _, err := storage.CreateObject(ctx, "", ".cas/blocks/forged", 0644); !errors.Is(err, data.ErrPermission) {
+		t.Errorf("Expected reserved keys to be rejected, got %v", err)
+	}
+}
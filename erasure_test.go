@@ -0,0 +1,201 @@
+package vfs_test
+
+import (
+	"bytes"
+	"errors"
+	"math/rand"
+	"testing"
+
+	"github.com/mwantia/vfs"
+	"github.com/mwantia/vfs/data"
+	"github.com/mwantia/vfs/log"
+	"github.com/mwantia/vfs/mount"
+	"github.com/mwantia/vfs/mount/backend"
+	"github.com/mwantia/vfs/mount/backend/ephemeral"
+	"github.com/mwantia/vfs/mount/backend/erasure"
+)
+
+// newErasureShards returns shards, which can be made unavailable individually.
+func newErasureShards(count int) ([]*flakyBackend, []backend.ObjectStorageBackend) {
+	flaky := make([]*flakyBackend, count)
+	shards := make([]backend.ObjectStorageBackend, count)
+	for i := range flaky {
+		flaky[i] = &flakyBackend{EphemeralBackend: ephemeral.NewEphemeralBackend()}
+		shards[i] = flaky[i]
+	}
+
+	return flaky, shards
+}
+
+// TestErasure_RandomAccess verifies random writes, reads and truncates against unencoded content with unavailable shards.
+func TestErasure_RandomAccess(t *testing.T) {
+	ctx := t.Context()
+
+	flaky, shards := newErasureShards(5)
+	storage, err := erasure.NewErasureBackend(shards, &erasure.ErasureBackendConfig{
+		ParityShards: 2,
+		ChunkSize:    16,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create erasure backend: %v", err)
+	}
+	if err := storage.Open(ctx); err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer storage.Close(ctx)
+
+	if _, err := storage.CreateObject(ctx, "", "file.bin", 0644); err != nil {
+		t.Fatalf("CreateObject failed: %v", err)
+	}
+
+	random := rand.New(rand.NewSource(1))
+	var expected []byte
+	for i := 0; i < 200; i++ {
+		// Writes reach the quorum of four shards with a single unavailable shard
+		down := random.Intn(len(flaky))
+		flaky[down].unavailable.Store(true)
+
+		switch offset := random.Int63n(int64(len(expected)) + 100); random.Intn(5) {
+		case 0:
+			size := random.Int63n(int64(len(expected)) + 100)
+			if err := storage.TruncateObject(ctx, "", "file.bin", size); err != nil {
+				t.Fatalf("TruncateObject failed: %v", err)
+			}
+			expected = append(expected, make([]byte, max(size-int64(len(expected)), 0))...)[:size]
+		default:
+			content := make([]byte, random.Intn(150)+1)
+			random.Read(content)
+			if _, err := storage.WriteObject(ctx, "", "file.bin", offset, content); err != nil {
+				t.Fatalf("WriteObject failed: %v", err)
+			}
+			if end := offset + int64(len(content)); end > int64(len(expected)) {
+				expected = append(expected, make([]byte, end-int64(len(expected)))...)
+			}
+			copy(expected[offset:], content)
+		}
+
+		// The shard missing the write is rebuilt, so the content can be read from any three shards
+		flaky[down].unavailable.Store(false)
+		if _, err := storage.Scrub(ctx); err != nil {
+			t.Fatalf("Scrub failed after step %d: %v", i, err)
+		}
+		for _, index := range random.Perm(len(flaky))[:2] {
+			flaky[index].unavailable.Store(true)
+		}
+
+		stat, err := storage.HeadObject(ctx, "", "file.bin")
+		if err != nil || stat.Size != int64(len(expected)) {
+			t.Fatalf("Expected size %d after step %d, got %+v (%v)", len(expected), i, stat, err)
+		}
+		if len(expected) > 0 {
+			offset := random.Int63n(int64(len(expected)))
+			buffer := make([]byte, random.Intn(100)+1)
+			n, err := storage.ReadObject(ctx, "", "file.bin", offset, buffer)
+			if (err != nil && n == 0) || !bytes.Equal(buffer[:n], expected[offset:min(offset+int64(len(buffer)), int64(len(expected)))]) {
+				t.Fatalf("Unexpected content at offset %d after step %d: %q (%v)", offset, i, buffer[:n], err)
+			}
+		}
+
+		for _, shard := range flaky {
+			shard.unavailable.Store(false)
+		}
+	}
+
+	// Writes require four shards, while reads fail with fewer than three shards
+	for _, shard := range flaky[:2] {
+		shard.unavailable.Store(true)
+	}
+	if _, err := storage.WriteObject(ctx, "", "file.bin", 0, []byte("content")); !errors.Is(err, erasure.ErrWriteQuorum) {
+		t.Errorf("Expected ErrWriteQuorum, got %v", err)
+	}
+	flaky[2].unavailable.Store(true)
+	if _, err := storage.ReadObject(ctx, "", "file.bin", 0, make([]byte, 1)); !errors.Is(err, erasure.ErrTooFewShards) {
+		t.Errorf("Expected ErrTooFewShards, got %v", err)
+	}
+}
+
+// TestErasure_Scrub verifies that missing, corrupt and outdated shards are detected and rebuilt by a scrub.
+func TestErasure_Scrub(t *testing.T) {
+	ctx := t.Context()
+	fs, err := vfs.NewVirtualFileSystem(vfs.WithLogLevel(log.Error))
+	if err != nil {
+		t.Fatalf("Failed to initialize vfs: %v", err)
+	}
+	defer fs.Shutdown(ctx)
+
+	flaky, shards := newErasureShards(5)
+	storage, err := erasure.NewErasureBackend(shards, &erasure.ErasureBackendConfig{
+		ParityShards: 2,
+		ChunkSize:    64,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create erasure backend: %v", err)
+	}
+	if err := fs.Mount(ctx, "/", storage, mount.WithMetadata(ephemeral.NewEphemeralBackend())); err != nil {
+		t.Fatalf("Failed to mount /: %v", err)
+	}
+
+	content := make([]byte, 1000)
+	rand.New(rand.NewSource(1)).Read(content)
+	if err := fs.CreateDirectory(ctx, "/docs"); err != nil {
+		t.Fatalf("CreateDirectory failed: %v", err)
+	}
+	writeTestFile(t, fs, "/docs/a.bin", content)
+	writeTestFile(t, fs, "/b.txt", []byte("written before the shard failed"))
+
+	// Each shard only stores its chunks and parity
+	if stat, err := flaky[0].HeadObject(ctx, "", "docs/a.bin"); err != nil || stat.Size >= int64(len(content)/2) {
+		t.Errorf("Expected shard to store a third of the content, got %+v (%v)", stat, err)
+	}
+
+	// Writes are missed by an unavailable shard
+	flaky[2].unavailable.Store(true)
+	copy(content[500:], "modified while degraded")
+	if _, err := fs.WriteFile(ctx, "/docs/a.bin", 500, []byte("modified while degraded")); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	flaky[2].unavailable.Store(false)
+
+	// Missing, corrupt and outdated shards are reconstructed on read
+	if err := flaky[0].DeleteObject(ctx, "", "docs/a.bin", false); err != nil {
+		t.Fatalf("DeleteObject failed: %v", err)
+	}
+	if result, err := fs.ReadFile(ctx, "/docs/a.bin", 0, int64(len(content))); err != nil || !bytes.Equal(result, content) {
+		t.Fatalf("Expected content reconstructed from remaining shards, got %d bytes (%v)", len(result), err)
+	}
+	if _, err := flaky[1].WriteObject(ctx, "", "b.txt", 40, []byte("corrupt")); err != nil {
+		t.Fatalf("WriteObject failed: %v", err)
+	}
+
+	// Remnants of objects held by a single shard are removed
+	if _, err := flaky[3].CreateObject(ctx, "", "extra.txt", 0644); err != nil {
+		t.Fatalf("CreateObject failed: %v", err)
+	}
+
+	report, err := storage.Scrub(ctx)
+	if err != nil {
+		t.Fatalf("Scrub failed: %v", err)
+	}
+	if report.Objects != 3 || report.Missing != 1 || report.Corrupt < 2 || report.Repaired != 3 || report.Removed != 1 || len(report.Lost) != 0 {
+		t.Errorf("Unexpected scrub report: %+v", report)
+	}
+	if _, err := flaky[3].HeadObject(ctx, "", "extra.txt"); err != data.ErrNotExist {
+		t.Errorf("Expected remnant to be removed, got %v", err)
+	}
+
+	// Repaired shards are sufficient to read all content
+	flaky[3].unavailable.Store(true)
+	flaky[4].unavailable.Store(true)
+	if result, err := fs.ReadFile(ctx, "/docs/a.bin", 0, int64(len(content))); err != nil || !bytes.Equal(result, content) {
+		t.Errorf("Expected content from repaired shards, got %d bytes (%v)", len(result), err)
+	}
+	if result, err := fs.ReadFile(ctx, "/b.txt", 0, 31); err != nil || string(result) != "written before the shard failed" {
+		t.Errorf("Unexpected content from repaired shards: %q (%v)", result, err)
+	}
+	flaky[3].unavailable.Store(false)
+	flaky[4].unavailable.Store(false)
+
+	if report, err := storage.Scrub(ctx); err != nil || report.Corrupt != 0 || report.Missing != 0 || report.Repaired != 0 {
+		t.Errorf("Expected no repairs for intact shards, got %+v (%v)", report, err)
+	}
+}
@@ -0,0 +1,51 @@
+package vfs
+
+import (
+	"context"
+
+	"github.com/mwantia/vfs/data"
+)
+
+// Fsck compares the metadata of the mount containing path against its object storage and reports every discrepancy.
+// A nil opts only reports discrepancies without repairing them.
+func (vfs *virtualFileSystemImpl) Fsck(ctx context.Context, path string, opts *data.FsckOptions) (*data.FsckReport, error) {
+	return intercept(ctx, vfs, vfs.newOperation(OperationFsck, path), func(ctx context.Context, op *Operation) (*data.FsckReport, error) {
+		return vfs.fsck(ctx, op.Path, opts)
+	})
+}
+
+// fsck implements Fsck, after the operation passed all interceptors.
+func (vfs *virtualFileSystemImpl) fsck(ctx context.Context, path string, opts *data.FsckOptions) (*data.FsckReport, error) {
+	if opts == nil {
+		opts = &data.FsckOptions{}
+	}
+
+	// Always start with an absolute path
+	absolute, err := vfs.toAbsolutePath(path)
+	if err != nil {
+		vfs.log.Error("Fsck: failed to convert path to absolute: %s - %v", path, err)
+		return nil, err
+	}
+
+	vfs.log.Debug("Fsck: path=%s repair=%v prune=%v", absolute, opts.Repair, opts.Prune)
+
+	mnt, err := vfs.getMountFromPath(absolute)
+	if err != nil {
+		vfs.log.Error("Fsck: no mount found for path: %s - %v", absolute, err)
+		return nil, err
+	}
+	if mnt.Options.IsReadOnly && (opts.Repair || opts.Prune) {
+		vfs.log.Error("Fsck: cannot repair read-only mount at %s", mnt.Path)
+		return nil, data.ErrReadOnly
+	}
+
+	report, err := mnt.Fsck(ctx, opts)
+	if err != nil {
+		vfs.log.Error("Fsck: failed to check %s - %v", mnt.Path, err)
+		return nil, err
+	}
+
+	vfs.log.Info("Fsck: checked %d entries in %s, found %d issue(s) and repaired %d",
+		report.Checked, mnt.Path, len(report.Issues), report.Repaired)
+	return report, nil
+}
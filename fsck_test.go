@@ -0,0 +1,110 @@
+package vfs_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/mwantia/vfs"
+	"github.com/mwantia/vfs/data"
+	"github.com/mwantia/vfs/log"
+	"github.com/mwantia/vfs/mount"
+	"github.com/mwantia/vfs/mount/backend/ephemeral"
+)
+
+// TestFsck_SplitMount verifies that every class of drift between metadata and object storage is reported and repaired.
+func TestFsck_SplitMount(t *testing.T) {
+	ctx := t.Context()
+	fs, err := vfs.NewVirtualFileSystem(vfs.WithLogLevel(log.Error))
+	if err != nil {
+		t.Fatalf("Failed to initialize vfs: %v", err)
+	}
+	defer fs.Shutdown(ctx)
+
+	storage, metadata := ephemeral.NewEphemeralBackend(), ephemeral.NewEphemeralBackend()
+	if err := fs.Mount(ctx, "/", storage, mount.WithMetadata(metadata)); err != nil {
+		t.Fatalf("Failed to mount /: %v", err)
+	}
+
+	if err := fs.CreateDirectory(ctx, "/dir"); err != nil {
+		t.Fatalf("CreateDirectory failed: %v", err)
+	}
+	writeTestFile(t, fs, "/dir/a.txt", []byte("consistent"))
+	writeTestFile(t, fs, "/deleted.txt", []byte("deleted"))
+	writeTestFile(t, fs, "/grown.txt", []byte("grown"))
+
+	report, err := fs.Fsck(ctx, "/", nil)
+	if err != nil {
+		t.Fatalf("Fsck failed: %v", err)
+	}
+	if len(report.Issues) != 0 || report.Checked != 4 {
+		t.Fatalf("Expected consistent mount with 4 entries, got %+v", report)
+	}
+
+	// Introduce drift directly within either layer
+	if err := storage.DeleteObject(ctx, "", "deleted.txt", false); err != nil {
+		t.Fatalf("DeleteObject failed: %v", err)
+	}
+	if _, err := storage.WriteObject(ctx, "", "grown.txt", 5, []byte("-more")); err != nil {
+		t.Fatalf("WriteObject failed: %v", err)
+	}
+	if _, err := storage.CreateObject(ctx, "", "untracked.txt", 0644); err != nil {
+		t.Fatalf("CreateObject failed: %v", err)
+	}
+	if _, err := storage.CreateObject(ctx, "", "typed", data.ModeDir|0755); err != nil {
+		t.Fatalf("CreateObject failed: %v", err)
+	}
+	if err := metadata.CreateMeta(ctx, "", data.NewFileMetadata("typed", 0, 0644)); err != nil {
+		t.Fatalf("CreateMeta failed: %v", err)
+	}
+	if err := metadata.CreateMeta(ctx, "", data.NewMetadata("ghost", data.ModeDir|0755, 0)); err != nil {
+		t.Fatalf("CreateMeta failed: %v", err)
+	}
+
+	var buffer bytes.Buffer
+	if code, err := fs.Execute(ctx, &buffer, "fsck", "/"); err == nil || code != 1 {
+		t.Errorf("Expected fsck to fail with unrepaired issues, got %d: %v", code, err)
+	}
+
+	expected := map[string]data.FsckIssueType{
+		"deleted.txt":   data.FsckMissingObject,
+		"grown.txt":     data.FsckSizeMismatch,
+		"untracked.txt": data.FsckMissingMetadata,
+		"typed":         data.FsckTypeMismatch,
+		"ghost":         data.FsckOrphanDirectory,
+	}
+	for key, issueType := range expected {
+		if !strings.Contains(buffer.String(), string(issueType)+strings.Repeat(" ", 16-len(issueType))+" "+key) {
+			t.Errorf("Expected %s to be reported for %s, got:\n%s", issueType, key, buffer.String())
+		}
+	}
+
+	// Repairing without pruning keeps stale entries
+	if report, err = fs.Fsck(ctx, "/", &data.FsckOptions{Repair: true}); err != nil {
+		t.Fatalf("Fsck failed: %v", err)
+	}
+	if len(report.Issues) != 5 || report.Repaired != 3 || report.Clean() {
+		t.Errorf("Unexpected report after repair: %+v", report)
+	}
+
+	if report, err = fs.Fsck(ctx, "/", &data.FsckOptions{Prune: true}); err != nil {
+		t.Fatalf("Fsck failed: %v", err)
+	}
+	if len(report.Issues) != 2 || !report.Clean() {
+		t.Errorf("Unexpected report after prune: %+v", report)
+	}
+
+	if report, err = fs.Fsck(ctx, "/", nil); err != nil || len(report.Issues) != 0 {
+		t.Errorf("Expected consistent mount after repair, got %+v (%v)", report, err)
+	}
+
+	if meta, err := fs.StatMetadata(ctx, "/grown.txt"); err != nil || meta.Size != 10 {
+		t.Errorf("Expected rebuilt size of 10, got %+v (%v)", meta, err)
+	}
+	if meta, err := fs.StatMetadata(ctx, "/typed"); err != nil || !meta.Mode.IsDir() {
+		t.Errorf("Expected rebuilt directory, got %+v (%v)", meta, err)
+	}
+	if exists, _ := metadata.ExistsMeta(ctx, "", "ghost"); exists {
+		t.Error("Expected orphan directory to be pruned")
+	}
+}
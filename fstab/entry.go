@@ -0,0 +1,105 @@
+package fstab
+
+import (
+	"fmt"
+	"maps"
+	"path"
+
+	"github.com/mwantia/vfs/mount"
+	"github.com/mwantia/vfs/mount/backend"
+)
+
+// CreateBackends creates the primary backend and all extensions of the entry using the backend registry.
+// The returned options configure the mount as described by the entry.
+func (e *Entry) CreateBackends() (backend.ObjectStorageBackend, []mount.MountOption, error) {
+	name, params, err := resolveBackend(e.Backend, e.URL, e.Params)
+	if err != nil {
+		return nil, nil, fmt.Errorf("fstab: failed to resolve backend for mount '%s': %w", e.Path, err)
+	}
+
+	primary, err := backend.CreateObjectStorageBackend(name, params)
+	if err != nil {
+		return nil, nil, fmt.Errorf("fstab: failed to create backend for mount '%s': %w", e.Path, err)
+	}
+
+	opts := e.MountOptions()
+	for _, ext := range e.Extensions {
+		if ext.Backend == PrimaryBackend {
+			opts = append(opts, mount.WithExtension(primary, ext.Capabilities...))
+			continue
+		}
+
+		name, params, err := resolveBackend(ext.Backend, ext.URL, ext.Params)
+		if err != nil {
+			return nil, nil, fmt.Errorf("fstab: failed to resolve extension for mount '%s': %w", e.Path, err)
+		}
+
+		created, err := backend.CreateBackend(name, params)
+		if err != nil {
+			return nil, nil, fmt.Errorf("fstab: failed to create extension for mount '%s': %w", e.Path, err)
+		}
+		opts = append(opts, mount.WithExtension(created, ext.Capabilities...))
+	}
+
+	return primary, opts, nil
+}
+
+// resolveBackend returns the backend name and parameters, either defined directly or by url.
+// Explicit parameters take precedence over parameters converted from the url.
+func resolveBackend(name, url string, params backend.FactoryParams) (string, backend.FactoryParams, error) {
+	if url == "" {
+		return name, params, nil
+	}
+
+	name, converted, err := backend.ParseURL(url)
+	if err != nil {
+		return "", nil, err
+	}
+	maps.Copy(converted, params)
+
+	return name, converted, nil
+}
+
+// MountOptions returns the options defined by the entry, excluding all extensions.
+func (e *Entry) MountOptions() []mount.MountOption {
+	var opts []mount.MountOption
+	if e.Namespace != "" {
+		opts = append(opts, mount.WithNamespace(e.Namespace))
+	}
+	if e.PathPrefix != "" {
+		opts = append(opts, mount.WithPathPrefix(e.PathPrefix))
+	}
+	if e.ReadOnly {
+		opts = append(opts, mount.IsReadOnly())
+	}
+	if e.DenyNesting {
+		opts = append(opts, mount.DisableMountNesting())
+	}
+	if e.AutoExtensions {
+		opts = append(opts, mount.EnableAutoExtensions())
+	}
+	if e.MandatoryLocks {
+		opts = append(opts, mount.WithMandatoryLocks())
+	}
+	if e.WatchBackend {
+		opts = append(opts, mount.WithBackendWatch())
+	}
+	if !e.Quota.IsUnlimited() {
+		opts = append(opts, mount.WithQuota(e.Quota))
+	}
+	for uid, quota := range e.UserQuotas {
+		opts = append(opts, mount.WithUserQuota(uid, quota))
+	}
+
+	return opts
+}
+
+// Skeleton returns the absolute paths of all directories, that are created after mounting.
+func (e *Entry) Skeleton() []string {
+	paths := make([]string, 0, len(e.Directories))
+	for _, dir := range e.Directories {
+		paths = append(paths, path.Join(e.Path, dir))
+	}
+
+	return paths
+}
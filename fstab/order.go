@@ -0,0 +1,75 @@
+package fstab
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/mwantia/vfs/data"
+)
+
+// Ordered returns all entries in dependency order, so every entry is placed after its
+// parent mounts and all mounts listed within DependsOn. Independent entries are sorted by path.
+// Dependencies not defined within the table are expected to be mounted already and are ignored.
+func (t *Table) Ordered() ([]*Entry, error) {
+	entries := slices.Clone(t.Mounts)
+	slices.SortFunc(entries, func(a, b *Entry) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+
+	dependencies := make(map[*Entry][]*Entry, len(entries))
+	for _, entry := range entries {
+		for _, other := range entries {
+			if other != entry && isParentMount(other.Path, entry.Path) {
+				dependencies[entry] = append(dependencies[entry], other)
+			}
+		}
+		for _, dependency := range entry.DependsOn {
+			if other, exists := t.Lookup(dependency); exists && other != entry {
+				dependencies[entry] = append(dependencies[entry], other)
+			}
+		}
+	}
+
+	ordered := make([]*Entry, 0, len(entries))
+	placed := make(map[*Entry]bool, len(entries))
+
+	for len(ordered) < len(entries) {
+		progress := false
+		for _, entry := range entries {
+			if placed[entry] {
+				continue
+			}
+
+			ready := !slices.ContainsFunc(dependencies[entry], func(dependency *Entry) bool {
+				return !placed[dependency]
+			})
+			if ready {
+				ordered = append(ordered, entry)
+				placed[entry] = true
+				progress = true
+			}
+		}
+
+		if !progress {
+			var pending []string
+			for _, entry := range entries {
+				if !placed[entry] {
+					pending = append(pending, entry.Path)
+				}
+			}
+			return nil, fmt.Errorf("%w: %s", data.ErrCircularReference, strings.Join(pending, ", "))
+		}
+	}
+
+	return ordered, nil
+}
+
+// isParentMount checks if child is nested below parent.
+func isParentMount(parent, child string) bool {
+	if parent == "/" {
+		return child != "/"
+	}
+
+	return strings.HasPrefix(child, parent+"/")
+}
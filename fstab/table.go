@@ -0,0 +1,167 @@
+// Package fstab describes a declarative mount table, which can be loaded from
+// and dumped to JSON and is used to populate a virtual filesystem.
+package fstab
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/mwantia/vfs/data"
+	"github.com/mwantia/vfs/mount/backend"
+)
+
+// PrimaryBackend can be used as extension backend to reuse the primary backend instance
+// (e.g. to use a sqlite backend as object storage and metadata at the same time).
+const PrimaryBackend = "primary"
+
+// Table contains all mounts of a virtual filesystem.
+type Table struct {
+	Mounts []*Entry `json:"mounts"`
+}
+
+// Entry describes a single mount within the table.
+type Entry struct {
+	Path    string                `json:"path"`
+	Backend string                `json:"backend,omitempty"`
+	URL     string                `json:"url,omitempty"` // Alternative to Backend (e.g. "sqlite:///var/vfs.db")
+	Params  backend.FactoryParams `json:"params,omitempty"`
+
+	Namespace      string `json:"namespace,omitempty"`
+	PathPrefix     string `json:"path_prefix,omitempty"`
+	ReadOnly       bool   `json:"read_only,omitempty"`
+	DenyNesting    bool   `json:"deny_nesting,omitempty"`
+	AutoExtensions bool   `json:"auto_extensions,omitempty"`
+	MandatoryLocks bool   `json:"mandatory_locks,omitempty"`
+	WatchBackend   bool   `json:"watch_backend,omitempty"` // Observe changes made outside of the VFS
+
+	Quota      data.Quota           `json:"quota,omitzero"`        // Quota of the whole mount
+	UserQuotas map[int64]data.Quota `json:"user_quotas,omitempty"` // Quotas per owning UID
+
+	Extensions  []*Extension `json:"extensions,omitempty"`
+	Directories []string     `json:"directories,omitempty"` // Skeleton created after mounting (relative to Path)
+	DependsOn   []string     `json:"depends_on,omitempty"`  // Mounts required before this mount (parents are implicit)
+}
+
+// Extension describes an additional backend providing capabilities for a mount.
+type Extension struct {
+	Backend      string                      `json:"backend,omitempty"`
+	URL          string                      `json:"url,omitempty"`
+	Params       backend.FactoryParams       `json:"params,omitempty"`
+	Capabilities []backend.BackendCapability `json:"capabilities"`
+}
+
+// Parse decodes a table from JSON and validates it.
+func Parse(reader io.Reader) (*Table, error) {
+	decoder := json.NewDecoder(reader)
+	decoder.DisallowUnknownFields()
+
+	table := &Table{}
+	if err := decoder.Decode(table); err != nil {
+		return nil, fmt.Errorf("fstab: failed to parse mount table: %w", err)
+	}
+
+	if err := table.Validate(); err != nil {
+		return nil, err
+	}
+
+	return table, nil
+}
+
+// Load reads and parses the table from the file at path.
+func Load(path string) (*Table, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("fstab: failed to open mount table: %w", err)
+	}
+	defer file.Close()
+
+	return Parse(file)
+}
+
+// Encode writes the table as indented JSON.
+func (t *Table) Encode(writer io.Writer) error {
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(t)
+}
+
+// Validate checks all entries for missing or conflicting values.
+// Paths are normalized to their absolute form.
+func (t *Table) Validate() error {
+	capabilities := backend.GetAllCapabilities()
+	paths := make(map[string]struct{}, len(t.Mounts))
+
+	for i, entry := range t.Mounts {
+		if entry == nil {
+			return fmt.Errorf("fstab: mount #%d is empty", i)
+		}
+		if entry.Path == "" {
+			return fmt.Errorf("fstab: mount #%d has no path", i)
+		}
+		if (entry.Backend == "") == (entry.URL == "") {
+			return fmt.Errorf("fstab: mount '%s' requires either backend or url", entry.Path)
+		}
+
+		absolute, err := data.ToAbsolutePath(entry.Path)
+		if err != nil {
+			return fmt.Errorf("fstab: mount '%s' has an invalid path: %w", entry.Path, err)
+		}
+		entry.Path = path.Clean(absolute)
+
+		if _, exists := paths[entry.Path]; exists {
+			return fmt.Errorf("fstab: mount '%s' is defined more than once", entry.Path)
+		}
+		paths[entry.Path] = struct{}{}
+
+		for _, ext := range entry.Extensions {
+			if ext == nil || (ext.Backend == "") == (ext.URL == "") {
+				return fmt.Errorf("fstab: mount '%s' has an extension without either backend or url", entry.Path)
+			}
+			if len(ext.Capabilities) == 0 {
+				return fmt.Errorf("fstab: extension of mount '%s' has no capabilities", entry.Path)
+			}
+			for _, capability := range ext.Capabilities {
+				if !capabilities.Contains(capability) {
+					return fmt.Errorf("fstab: extension of mount '%s' has unknown capability '%s'", entry.Path, capability)
+				}
+			}
+		}
+
+		if entry.ReadOnly && len(entry.Directories) > 0 {
+			return fmt.Errorf("fstab: read-only mount '%s' cannot create directories", entry.Path)
+		}
+		for _, dir := range entry.Directories {
+			if cleaned := path.Clean("/" + dir); cleaned == "/" || path.Clean(dir) != strings.TrimPrefix(cleaned, "/") {
+				return fmt.Errorf("fstab: mount '%s' has an invalid directory '%s'", entry.Path, dir)
+			}
+		}
+
+		for j, dependency := range entry.DependsOn {
+			absolute, err := data.ToAbsolutePath(dependency)
+			if err != nil {
+				return fmt.Errorf("fstab: mount '%s' has an invalid dependency: %w", entry.Path, err)
+			}
+			entry.DependsOn[j] = path.Clean(absolute)
+		}
+	}
+
+	return nil
+}
+
+// Lookup returns the entry mounted at path.
+func (t *Table) Lookup(path string) (*Entry, bool) {
+	index := slices.IndexFunc(t.Mounts, func(entry *Entry) bool {
+		return entry.Path == path
+	})
+	if index < 0 {
+		return nil, false
+	}
+
+	return t.Mounts[index], true
+}
@@ -0,0 +1,103 @@
+package vfs
+
+import (
+	"context"
+	"time"
+
+	"github.com/mwantia/vfs/data"
+)
+
+// CollectGarbage removes content no longer referenced within the mount containing path.
+// A nil opts removes all orphans older than data.DefaultGCGracePeriod.
+func (vfs *virtualFileSystemImpl) CollectGarbage(ctx context.Context, path string, opts *data.GCOptions) (*data.GCReport, error) {
+	return intercept(ctx, vfs, vfs.newOperation(OperationCollectGarbage, path), func(ctx context.Context, op *Operation) (*data.GCReport, error) {
+		return vfs.collectGarbage(ctx, op.Path, opts)
+	})
+}
+
+// collectGarbage implements CollectGarbage, after the operation passed all interceptors.
+func (vfs *virtualFileSystemImpl) collectGarbage(ctx context.Context, path string, opts *data.GCOptions) (*data.GCReport, error) {
+	if opts == nil {
+		opts = &data.GCOptions{}
+	}
+
+	// Always start with an absolute path
+	absolute, err := vfs.toAbsolutePath(path)
+	if err != nil {
+		vfs.log.Error("CollectGarbage: failed to convert path to absolute: %s - %v", path, err)
+		return nil, err
+	}
+
+	vfs.log.Debug("CollectGarbage: path=%s dryrun=%v grace=%s", absolute, opts.DryRun, opts.GetGracePeriod())
+
+	mnt, err := vfs.getMountFromPath(absolute)
+	if err != nil {
+		vfs.log.Error("CollectGarbage: no mount found for path: %s - %v", absolute, err)
+		return nil, err
+	}
+	if mnt.Options.IsReadOnly && !opts.DryRun {
+		vfs.log.Error("CollectGarbage: cannot remove content from read-only mount at %s", mnt.Path)
+		return nil, data.ErrReadOnly
+	}
+
+	report, err := mnt.CollectGarbage(ctx, opts)
+	if err != nil {
+		vfs.log.Error("CollectGarbage: failed to collect garbage of %s - %v", mnt.Path, err)
+		return nil, err
+	}
+
+	vfs.log.Info("CollectGarbage: found %d orphan(s) using %d bytes in %s (removed=%d deferred=%d)",
+		report.Orphans, report.Bytes, mnt.Path, report.Removed, report.Deferred)
+	return report, nil
+}
+
+// startGarbageCollector runs garbage collection for all writable mounts every interval until Shutdown is called.
+func (vfs *virtualFileSystemImpl) startGarbageCollector(interval time.Duration, opts data.GCOptions) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	vfs.gcCancel = cancel
+	vfs.gcDone = done
+
+	go func() {
+		defer close(done)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			vfs.mu.RLock()
+			var paths []string
+			for path, mnt := range vfs.mnts {
+				if !mnt.Options.IsReadOnly {
+					paths = append(paths, path)
+				}
+			}
+			vfs.mu.RUnlock()
+
+			for _, path := range paths {
+				// Failures are already logged and retried with the next run
+				vfs.CollectGarbage(ctx, path, &opts)
+			}
+		}
+	}()
+
+	vfs.log.Info("Garbage collection scheduled every %s (grace=%s)", interval, opts.GetGracePeriod())
+}
+
+// stopGarbageCollector stops the scheduled garbage collection and waits for a running collection to finish.
+func (vfs *virtualFileSystemImpl) stopGarbageCollector() {
+	if vfs.gcCancel == nil {
+		return
+	}
+
+	vfs.gcCancel()
+	<-vfs.gcDone
+	vfs.gcCancel = nil
+}
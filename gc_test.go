@@ -0,0 +1,247 @@
+package vfs_test
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mwantia/vfs"
+	"github.com/mwantia/vfs/data"
+	"github.com/mwantia/vfs/log"
+	"github.com/mwantia/vfs/mount"
+	"github.com/mwantia/vfs/mount/backend/ephemeral"
+	"github.com/mwantia/vfs/mount/backend/sqlite"
+)
+
+// TestGC_SQLite verifies that data rows and chunks without metadata are reported, deferred and removed.
+func TestGC_SQLite(t *testing.T) {
+	ctx := t.Context()
+	path := filepath.Join(t.TempDir(), "vfs.db")
+
+	storage, err := sqlite.NewSQLiteBackend(path)
+	if err != nil {
+		t.Fatalf("Failed to create sqlite backend: %v", err)
+	}
+
+	fs, err := vfs.NewVirtualFileSystem(vfs.WithLogLevel(log.Error))
+	if err != nil {
+		t.Fatalf("Failed to initialize vfs: %v", err)
+	}
+	defer fs.Shutdown(ctx)
+
+	if err := fs.Mount(ctx, "/", storage); err != nil {
+		t.Fatalf("Failed to mount /: %v", err)
+	}
+	writeTestFile(t, fs, "/kept.txt", []byte("still referenced"))
+
+	// Simulate content leaked by earlier crashes or other processes sharing the database
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	old, recent := time.Now().Add(-time.Hour).Unix(), time.Now().Unix()
+	statements := []struct {
+		query string
+		args  []any
+	}{
+		{"INSERT INTO vfs_data (id, size, ref_count, created_at, last_accessed) VALUES ('leaked', 5, 1, ?, ?)", []any{old, old}},
+		{"INSERT INTO vfs_chunks (data_id, chunk_index, content) VALUES ('leaked', 0, ?)", []any{[]byte("12345")}},
+		{"INSERT INTO vfs_data (id, size, ref_count, created_at, last_accessed) VALUES ('inflight', 3, 1, ?, ?)", []any{recent, recent}},
+		{"INSERT INTO vfs_chunks (data_id, chunk_index, content) VALUES ('inflight', 0, ?)", []any{[]byte("abc")}},
+		{"INSERT INTO vfs_chunks (data_id, chunk_index, content) VALUES ('dangling', 0, ?)", []any{[]byte("xy")}},
+	}
+	for _, statement := range statements {
+		if _, err := db.ExecContext(ctx, statement.query, statement.args...); err != nil {
+			t.Fatalf("Failed to insert orphan: %v", err)
+		}
+	}
+
+	opts := &data.GCOptions{DryRun: true, GracePeriod: time.Minute}
+	report, err := fs.CollectGarbage(ctx, "/", opts)
+	if err != nil {
+		t.Fatalf("CollectGarbage failed: %v", err)
+	}
+	if report.Orphans != 3 || report.Bytes != 10 || report.Deferred != 1 || report.Removed != 0 {
+		t.Errorf("Unexpected dry run report: %+v", report)
+	}
+
+	opts.DryRun = false
+	opts.Vacuum = true
+	if report, err = fs.CollectGarbage(ctx, "/", opts); err != nil {
+		t.Fatalf("CollectGarbage failed: %v", err)
+	}
+	if report.Removed != 2 || report.Deferred != 1 || !report.Vacuumed {
+		t.Errorf("Unexpected report: %+v", report)
+	}
+
+	var remaining int
+	if err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM vfs_chunks WHERE data_id IN ('leaked', 'dangling', 'inflight')").Scan(&remaining); err != nil {
+		t.Fatalf("Failed to count chunks: %v", err)
+	}
+	if remaining != 1 {
+		t.Errorf("Expected only the chunk within the grace period to remain, got %d", remaining)
+	}
+
+	if result, err := fs.ReadFile(ctx, "/kept.txt", 0, 16); err != nil || string(result) != "still referenced" {
+		t.Errorf("Referenced content changed by gc: %q (%v)", result, err)
+	}
+
+	// Without options, recent orphans are kept by the default grace period
+	if report, err = fs.CollectGarbage(ctx, "/", nil); err != nil {
+		t.Fatalf("CollectGarbage failed: %v", err)
+	}
+	if report.Orphans != 1 || report.Deferred != 1 || report.Removed != 0 {
+		t.Errorf("Expected default grace period to defer recent orphans, got %+v", report)
+	}
+}
+
+// TestGC_SeparateMetadata verifies that objects without metadata are only removed if requested.
+func TestGC_SeparateMetadata(t *testing.T) {
+	ctx := t.Context()
+	fs, err := vfs.NewVirtualFileSystem(vfs.WithLogLevel(log.Error))
+	if err != nil {
+		t.Fatalf("Failed to initialize vfs: %v", err)
+	}
+	defer fs.Shutdown(ctx)
+
+	storage := ephemeral.NewEphemeralBackend()
+	if err := fs.Mount(ctx, "/", storage, mount.WithMetadata(ephemeral.NewEphemeralBackend())); err != nil {
+		t.Fatalf("Failed to mount /: %v", err)
+	}
+	writeTestFile(t, fs, "/kept.txt", []byte("kept"))
+
+	// An object left behind by a failed delete
+	if _, err := storage.CreateObject(ctx, "", "orphan.txt", 0644); err != nil {
+		t.Fatalf("CreateObject failed: %v", err)
+	}
+	if _, err := storage.WriteObject(ctx, "", "orphan.txt", 0, []byte("orphaned")); err != nil {
+		t.Fatalf("WriteObject failed: %v", err)
+	}
+
+	report, err := fs.CollectGarbage(ctx, "/", nil)
+	if err != nil {
+		t.Fatalf("CollectGarbage failed: %v", err)
+	}
+	if report.Orphans != 0 {
+		t.Errorf("Expected objects to be kept without SweepObjects, got %+v", report)
+	}
+
+	if report, err = fs.CollectGarbage(ctx, "/", &data.GCOptions{SweepObjects: true, Immediate: true}); err != nil {
+		t.Fatalf("CollectGarbage failed: %v", err)
+	}
+	if report.Removed != 1 || report.Bytes != 8 || len(report.Keys) != 1 || report.Keys[0] != "orphan.txt" {
+		t.Errorf("Unexpected report: %+v", report)
+	}
+	if _, err := storage.HeadObject(ctx, "", "orphan.txt"); err != data.ErrNotExist {
+		t.Errorf("Expected orphan to be removed, got %v", err)
+	}
+	if _, err := storage.HeadObject(ctx, "", "kept.txt"); err != nil {
+		t.Errorf("Expected referenced object to be kept, got %v", err)
+	}
+}
+
+// TestGC_Command verifies the gc command including the default grace period.
+func TestGC_Command(t *testing.T) {
+	ctx := t.Context()
+	fs, err := vfs.NewVirtualFileSystem(vfs.WithLogLevel(log.Error))
+	if err != nil {
+		t.Fatalf("Failed to initialize vfs: %v", err)
+	}
+	defer fs.Shutdown(ctx)
+
+	storage := ephemeral.NewEphemeralBackend()
+	if err := fs.Mount(ctx, "/", storage, mount.WithMetadata(ephemeral.NewEphemeralBackend())); err != nil {
+		t.Fatalf("Failed to mount /: %v", err)
+	}
+	if _, err := storage.CreateObject(ctx, "", "orphan.txt", 0644); err != nil {
+		t.Fatalf("CreateObject failed: %v", err)
+	}
+
+	// Recently modified orphans are kept by default, since they might belong to in-flight writes
+	var buffer bytes.Buffer
+	if code, err := fs.Execute(ctx, &buffer, "gc", "-n", "--objects", "/"); err != nil || code != 0 {
+		t.Fatalf("gc failed with %d: %v", code, err)
+	}
+	if output := buffer.String(); !strings.Contains(output, "would remove 0 orphan(s)") || !strings.Contains(output, "1 deferred by grace period") {
+		t.Errorf("Unexpected gc output: %q", output)
+	}
+
+	buffer.Reset()
+	if code, err := fs.Execute(ctx, &buffer, "gc", "-n", "-v", "--objects", "--grace", "0", "/"); err != nil || code != 0 {
+		t.Fatalf("gc failed with %d: %v", code, err)
+	}
+	if output := buffer.String(); !strings.Contains(output, "orphan.txt") || !strings.Contains(output, "would remove 1 orphan(s)") {
+		t.Errorf("Unexpected gc output: %q", output)
+	}
+	if code, err := fs.Execute(ctx, &buffer, "gc", "--grace", "soon"); err == nil || code == 0 {
+		t.Error("Expected invalid grace period to fail")
+	}
+
+	buffer.Reset()
+	if code, err := fs.Execute(ctx, &buffer, "gc", "--objects", "--grace", "0", "/"); err != nil || code != 0 {
+		t.Fatalf("gc failed with %d: %v", code, err)
+	}
+	if _, err := storage.HeadObject(ctx, "", "orphan.txt"); err != data.ErrNotExist {
+		t.Errorf("Expected orphan to be removed with --grace 0, got %v", err)
+	}
+}
+
+// TestGC_Scheduled verifies that the scheduled collection keeps recent orphans unless removing them immediately is requested.
+func TestGC_Scheduled(t *testing.T) {
+	for _, immediate := range []bool{false, true} {
+		t.Run(fmt.Sprintf("immediate=%v", immediate), func(tst *testing.T) {
+			ctx := tst.Context()
+
+			// Reports of scheduled collections are observed, so no assertion depends on timing
+			reports := make(chan *data.GCReport, 1)
+			observe := func(ctx context.Context, op *vfs.Operation, next vfs.OperationHandler) (any, error) {
+				result, err := next(ctx, op)
+				if report, ok := result.(*data.GCReport); ok && op.Name == vfs.OperationCollectGarbage && err == nil {
+					select {
+					case reports <- report:
+					default:
+					}
+				}
+				return result, err
+			}
+
+			fs, err := vfs.NewVirtualFileSystem(vfs.WithLogLevel(log.Error), vfs.WithInterceptors(observe),
+				vfs.WithGarbageCollection(10*time.Millisecond, data.GCOptions{SweepObjects: true, Immediate: immediate}))
+			if err != nil {
+				tst.Fatalf("Failed to initialize vfs: %v", err)
+			}
+			defer fs.Shutdown(ctx)
+
+			storage := ephemeral.NewEphemeralBackend()
+			if _, err := storage.CreateObject(ctx, "", "orphan.txt", 0644); err != nil {
+				tst.Fatalf("CreateObject failed: %v", err)
+			}
+			if err := fs.Mount(ctx, "/", storage, mount.WithMetadata(ephemeral.NewEphemeralBackend())); err != nil {
+				tst.Fatalf("Failed to mount /: %v", err)
+			}
+
+			var report *data.GCReport
+			select {
+			case report = <-reports:
+			case <-time.After(5 * time.Second):
+				tst.Fatal("Expected scheduled garbage collection to run")
+			}
+
+			_, err = storage.HeadObject(ctx, "", "orphan.txt")
+			if immediate {
+				if report.Removed != 1 || err != data.ErrNotExist {
+					tst.Errorf("Expected orphan to be removed, got %+v (%v)", report, err)
+				}
+			} else if report.Deferred != 1 || report.Removed != 0 || err != nil {
+				tst.Errorf("Expected orphan to be kept by the default grace period, got %+v (%v)", report, err)
+			}
+		})
+	}
+}
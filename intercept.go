@@ -0,0 +1,137 @@
+package vfs
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mwantia/vfs/data"
+	"github.com/mwantia/vfs/mount"
+)
+
+// Names of all operations passing through the interceptor chain.
+const (
+	OperationMount           = "Mount"
+	OperationUnmount         = "Unmount"
+	OperationOpenFile        = "OpenFile"
+	OperationCloseFile       = "CloseFile"
+	OperationReadFile        = "ReadFile"
+	OperationWriteFile       = "WriteFile"
+	OperationStatMetadata    = "StatMetadata"
+	OperationLookupMetadata  = "LookupMetadata"
+	OperationReadDirectory   = "ReadDirectory"
+	OperationCreateDirectory = "CreateDirectory"
+	OperationRemoveDirectory = "RemoveDirectory"
+	OperationUnlinkFile      = "UnlinkFile"
+	OperationRename          = "Rename"
+	OperationCopy            = "Copy"
+	OperationChmod           = "Chmod"
+	OperationChown           = "Chown"
+	OperationSetAttributes   = "SetAttributes"
+	OperationRemoveAttribute = "RemoveAttribute"
+	OperationSetTimes        = "SetTimes"
+	OperationUpdateChecksum  = "UpdateChecksum"
+	OperationWatch           = "Watch"
+	OperationBegin           = "Begin"
+	OperationCommit          = "Commit"
+	OperationLock            = "Lock"
+	OperationListLocks       = "ListLocks"
+	OperationUsage           = "Usage"
+	OperationCollectGarbage  = "CollectGarbage"
+	OperationFsck            = "Fsck"
+	OperationReindex         = "Reindex"
+	OperationMigrate         = "Migrate"
+	OperationTier            = "Tier"
+)
+
+// gateKey marks contexts of operations holding the gate of the vfs.
+type gateKey struct{}
+
+// Operation describes a single VFS call passing through the interceptor chain.
+// Interceptors may rewrite Path and NewPath before calling the next handler.
+type Operation struct {
+	Name    string          // Name of the operation (e.g. OperationOpenFile)
+	Path    string          // Absolute path the operation is applied to
+	NewPath string          // Absolute destination path (only set for OperationRename and OperationCopy)
+	Flags   data.AccessMode // Access mode flags (only set for OperationOpenFile)
+	Mount   *mount.Mount    // Mount resolved for Path (nil if Path isn't mounted yet)
+}
+
+// OperationHandler executes the operation and returns its result.
+// The result type depends on the operation (e.g. []byte for ReadFile, nil for operations only returning an error).
+type OperationHandler func(ctx context.Context, op *Operation) (any, error)
+
+// Interceptor wraps every operation and decides whether to call next.
+// Returning without calling next short-circuits the operation with the returned result and error.
+type Interceptor func(ctx context.Context, op *Operation, next OperationHandler) (any, error)
+
+// newOperation creates the operation for path and resolves its mount.
+func (vfs *virtualFileSystemImpl) newOperation(name, path string) *Operation {
+	op := &Operation{
+		Name: name,
+		Path: path,
+	}
+	// Resolving the mount is only required for interceptors
+	if len(vfs.interceptors) == 0 {
+		return op
+	}
+
+	if absolute, err := vfs.toAbsolutePath(path); err == nil {
+		op.Path = absolute
+		op.Mount, _ = vfs.getMountFromPath(absolute)
+	}
+
+	return op
+}
+
+// chain combines all interceptors in registration order around handler.
+func (vfs *virtualFileSystemImpl) chain(handler OperationHandler) OperationHandler {
+	for i := len(vfs.interceptors) - 1; i >= 0; i-- {
+		interceptor, next := vfs.interceptors[i], handler
+		handler = func(ctx context.Context, op *Operation) (any, error) {
+			return interceptor(ctx, op, next)
+		}
+	}
+
+	return handler
+}
+
+// intercept passes the operation through all interceptors before calling the handler.
+// Without registered interceptors, the handler is called directly.
+func intercept[T any](ctx context.Context, vfs *virtualFileSystemImpl, op *Operation, handler func(ctx context.Context, op *Operation) (T, error)) (T, error) {
+	// Migrations pause all other operations themselves, while nested operations already hold the gate
+	if op.Name != OperationMigrate && ctx.Value(gateKey{}) == nil {
+		vfs.gate.RLock()
+		defer vfs.gate.RUnlock()
+		ctx = context.WithValue(ctx, gateKey{}, struct{}{})
+	}
+
+	if len(vfs.interceptors) == 0 {
+		return handler(ctx, op)
+	}
+
+	result, err := vfs.chain(func(ctx context.Context, op *Operation) (any, error) {
+		return handler(ctx, op)
+	})(ctx, op)
+
+	var zero T
+	if result == nil {
+		return zero, err
+	}
+
+	typed, ok := result.(T)
+	if !ok {
+		vfs.log.Error("%s: interceptor returned invalid result type %T for %s", op.Name, result, op.Path)
+		return zero, fmt.Errorf("%w: interceptor returned %T instead of %T", data.ErrInvalid, result, zero)
+	}
+
+	return typed, err
+}
+
+// interceptError passes operations without result through all interceptors.
+func (vfs *virtualFileSystemImpl) interceptError(ctx context.Context, op *Operation, handler func(ctx context.Context, op *Operation) error) error {
+	_, err := intercept(ctx, vfs, op, func(ctx context.Context, op *Operation) (any, error) {
+		return nil, handler(ctx, op)
+	})
+
+	return err
+}
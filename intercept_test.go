@@ -0,0 +1,155 @@
+package vfs_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/mwantia/vfs"
+	"github.com/mwantia/vfs/data"
+	"github.com/mwantia/vfs/log"
+	"github.com/mwantia/vfs/mount"
+	"github.com/mwantia/vfs/mount/backend/ephemeral"
+)
+
+func TestInterceptors_Chain(t *testing.T) {
+	ctx := t.Context()
+	errDenied := errors.New("denied")
+
+	var audit []string
+	var results []string
+
+	// Records every operation in the order it passes the chain
+	auditor := func(ctx context.Context, op *vfs.Operation, next vfs.OperationHandler) (any, error) {
+		mountPath := ""
+		if op.Mount != nil {
+			mountPath = op.Mount.Path
+		}
+		audit = append(audit, op.Name+" "+op.Path+" @"+mountPath)
+
+		result, err := next(ctx, op)
+		if content, ok := result.([]byte); ok {
+			results = append(results, string(content))
+		}
+		return result, err
+	}
+	// Denies all modifications below /secure
+	guard := func(ctx context.Context, op *vfs.Operation, next vfs.OperationHandler) (any, error) {
+		if strings.HasPrefix(op.Path, "/secure/") {
+			switch op.Name {
+			case vfs.OperationWriteFile, vfs.OperationUnlinkFile, vfs.OperationCreateDirectory:
+				return nil, errDenied
+			}
+		}
+		return next(ctx, op)
+	}
+	// Rewrites paths from /alias to /data
+	rewrite := func(ctx context.Context, op *vfs.Operation, next vfs.OperationHandler) (any, error) {
+		if after, found := strings.CutPrefix(op.Path, "/alias/"); found {
+			op.Path = "/data/" + after
+		}
+		return next(ctx, op)
+	}
+
+	fs, err := vfs.NewVirtualFileSystem(vfs.WithLogLevel(log.Error), vfs.WithInterceptors(auditor, guard), vfs.WithInterceptors(rewrite))
+	if err != nil {
+		t.Fatalf("Failed to initialize vfs: %v", err)
+	}
+
+	storage := ephemeral.NewEphemeralBackend()
+	if err := fs.Mount(ctx, "/", storage, mount.WithMetadata(storage)); err != nil {
+		t.Fatalf("Failed to mount: %v", err)
+	}
+	defer fs.Unmount(ctx, "/", false)
+
+	for _, dir := range []string{"/data", "/secure"} {
+		if err := fs.CreateDirectory(ctx, dir); err != nil {
+			t.Fatalf("CreateDirectory failed: %v", err)
+		}
+	}
+
+	if err := fs.CreateDirectory(ctx, "/secure/dir"); !errors.Is(err, errDenied) {
+		t.Errorf("Expected CreateDirectory to be denied, got %v", err)
+	}
+	if exists, _ := fs.LookupMetadata(ctx, "/secure/dir"); exists {
+		t.Errorf("Expected denied directory not to exist")
+	}
+
+	streamer, err := fs.OpenFile(ctx, "/alias/file.txt", data.AccessModeWrite|data.AccessModeCreate)
+	if err != nil {
+		t.Fatalf("OpenFile failed: %v", err)
+	}
+	if _, err := streamer.Write([]byte("content")); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if err := streamer.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	if _, err := fs.StatMetadata(ctx, "/data/file.txt"); err != nil {
+		t.Errorf("Expected rewritten file at /data/file.txt: %v", err)
+	}
+	if _, err := fs.ReadFile(ctx, "/alias/file.txt", 0, 7); err != nil {
+		t.Errorf("ReadFile failed: %v", err)
+	}
+
+	expected := []string{
+		"CreateDirectory /data @/",
+		"CreateDirectory /secure @/",
+		"CreateDirectory /secure/dir @/",
+		"LookupMetadata /secure/dir @/",
+		"OpenFile /alias/file.txt @/",
+		"StatMetadata /data/file.txt @/",
+		"ReadFile /alias/file.txt @/",
+	}
+	if !slices.Equal(audit[1:], expected) {
+		t.Errorf("Unexpected audit log:\n%s", strings.Join(audit, "\n"))
+	}
+	if audit[0] != "Mount / @" {
+		t.Errorf("Expected mount to be intercepted first, got %s", audit[0])
+	}
+	if !slices.Equal(results, []string{"content"}) {
+		t.Errorf("Expected interceptor to access read result, got %v", results)
+	}
+}
+
+func TestInterceptors_ShortCircuitResult(t *testing.T) {
+	ctx := t.Context()
+
+	// Serves cached content without reaching the backend
+	cache := func(ctx context.Context, op *vfs.Operation, next vfs.OperationHandler) (any, error) {
+		switch {
+		case op.Name == vfs.OperationReadFile && op.Path == "/cached":
+			return []byte("from cache"), nil
+		case op.Name == vfs.OperationStatMetadata && op.Path == "/invalid":
+			return "not metadata", nil
+		}
+		return next(ctx, op)
+	}
+
+	fs, err := vfs.NewVirtualFileSystem(vfs.WithLogLevel(log.Error), vfs.WithInterceptors(cache))
+	if err != nil {
+		t.Fatalf("Failed to initialize vfs: %v", err)
+	}
+
+	storage := ephemeral.NewEphemeralBackend()
+	if err := fs.Mount(ctx, "/", storage, mount.WithMetadata(storage)); err != nil {
+		t.Fatalf("Failed to mount: %v", err)
+	}
+	defer fs.Unmount(ctx, "/", false)
+
+	content, err := fs.ReadFile(ctx, "/cached", 0, 10)
+	if err != nil || string(content) != "from cache" {
+		t.Errorf("Expected cached content, got %q: %v", content, err)
+	}
+
+	if _, err := fs.StatMetadata(ctx, "/invalid"); !errors.Is(err, data.ErrInvalid) {
+		t.Errorf("Expected invalid result error, got %v", err)
+	}
+
+	if _, err := vfs.NewVirtualFileSystem(vfs.WithInterceptors(nil)); err == nil {
+		t.Errorf("Expected nil interceptor to be rejected")
+	}
+}
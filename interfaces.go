@@ -3,9 +3,11 @@ package vfs
 import (
 	"context"
 	"io"
+	"time"
 
 	"github.com/mwantia/vfs/cmd"
 	"github.com/mwantia/vfs/data"
+	"github.com/mwantia/vfs/fstab"
 	"github.com/mwantia/vfs/mount"
 	"github.com/mwantia/vfs/mount/backend"
 )
@@ -14,9 +16,13 @@ import (
 // file operations to the appropriate mount handlers. It provides a Unix-like filesystem
 // abstraction with support for nested mounts and thread-safe operations.
 type VirtualFileSystem interface {
-	// Populate
+	// Populate creates all mounts defined by the mount table in dependency order
+	// and creates their directory skeletons. Without mount table, nothing is mounted.
 	Populate(ctx context.Context) error
 
+	// DumpMountTable returns the current mounts in the format used by the mount table.
+	DumpMountTable() *fstab.Table
+
 	// Shutdown unmounts all mounted filesystems and releases all resources.
 	// This should be called when shutting down the VFS to ensure proper cleanup.
 	// Mounts are unmounted in reverse order (deepest first) to avoid dependency issues.
@@ -28,17 +34,74 @@ type VirtualFileSystem interface {
 	// UnregisterCommand
 	UnregisterCommand(name string) (bool, error)
 
+	// GetCommand returns the registered command with the specified name.
+	GetCommand(name string) (cmd.Command, bool)
+
+	// ListCommands returns all registered commands.
+	ListCommands() []cmd.Command
+
 	// Execute runs a command with the given arguments, writing output to the provided writer
 	Execute(ctx context.Context, writer io.Writer, args ...string) (int, error)
 
+	// ExecuteScript runs all commands read from the reader line by line, writing output to the provided writer.
+	// Failures are reported with their line number; "set -e" stops the script at the first failure.
+	ExecuteScript(ctx context.Context, reader io.Reader, writer io.Writer) (int, error)
+
 	// Mount attaches a filesystem handler at the specified path.
 	// Options can be used to configure the mount (e.g., read-only).
 	Mount(ctx context.Context, path string, primary backend.ObjectStorageBackend, opts ...mount.MountOption) error
 
+	// MountEntry creates the backends of a single mount table entry, mounts them and creates the directory skeleton.
+	// The entry is kept, so it's included in DumpMountTable including all backend parameters.
+	MountEntry(ctx context.Context, entry *fstab.Entry) error
+
 	// Unmount removes the filesystem handler at the specified path.
 	// Returns an error if the path is not mounted or has child mounts.
 	Unmount(ctx context.Context, path string, force bool) error
 
+	// Begin starts a new transaction for the mount at mountPath.
+	// All changes are staged until Commit applies them atomically, so readers never observe partial state.
+	// Returns data.ErrNotSupported if the backends of the mount can't apply changes atomically.
+	Begin(ctx context.Context, mountPath string) (Transaction, error)
+
+	// Lock acquires a shared or exclusive lock on the file or directory at path.
+	// Conflicting locks of other owners are waited for until timeout has passed (0 fails immediately).
+	// A nil opts locks the whole file with the default lease for the owner stored in ctx (see data.WithLockOwner).
+	// Returns data.ErrInvalid without owner on mounts with mandatory locks, since the file would be inaccessible for everyone.
+	Lock(ctx context.Context, path string, lockType data.LockType, timeout time.Duration, opts *data.LockOptions) (FileLock, error)
+
+	// ListLocks returns all unexpired locks held on the file or directory at path.
+	ListLocks(ctx context.Context, path string) ([]*data.LockInfo, error)
+
+	// Usage returns the storage used by the mount containing path together with its quotas.
+	// Usage is tracked incrementally for mounts with quotas, otherwise it's measured by walking the mount.
+	Usage(ctx context.Context, path string) (*data.Usage, error)
+
+	// CollectGarbage removes content no longer referenced within the mount containing path.
+	// With opts.DryRun set, orphans are only reported. A nil opts removes all orphans older than data.DefaultGCGracePeriod.
+	CollectGarbage(ctx context.Context, path string, opts *data.GCOptions) (*data.GCReport, error)
+
+	// Fsck compares the metadata of the mount containing path against its object storage and reports every discrepancy.
+	// Discrepancies are repaired or pruned as requested by opts. A nil opts only reports them.
+	Fsck(ctx context.Context, path string, opts *data.FsckOptions) (*data.FsckReport, error)
+
+	// Reindex rebuilds the metadata of the mount containing path from its object storage.
+	// Existing attributes are preserved. A nil opts uses the default concurrency and batch size.
+	Reindex(ctx context.Context, path string, opts *data.ReindexOptions) (*data.ReindexReport, error)
+
+	// Migrate copies all objects and metadata of the mount containing path to storage and metadata (nil for mounts without metadata),
+	// syncs all changes made in the meantime and switches the mount to the new backends. The mount remains usable during the copy.
+	Migrate(ctx context.Context, path string, storage backend.ObjectStorageBackend, metadata backend.MetadataBackend, opts *data.MigrateOptions) (*data.MigrateReport, error)
+
+	// Tier moves every file of the mount containing path to the tier selected by the tier policy of the mount.
+	// With opts.DryRun set, files are only reported. A nil opts moves all files.
+	Tier(ctx context.Context, path string, opts *data.TierOptions) (*data.TierReport, error)
+
+	// Watch returns a channel receiving all events for path, matching the filter (0 matches all events).
+	// Without recursive, only events for path itself and its direct children are reported.
+	// The channel is closed once ctx has been cancelled or the VFS has been shut down.
+	Watch(ctx context.Context, path string, recursive bool, filter data.EventType) (<-chan data.Event, error)
+
 	// OpenFile opens a file with the specified access mode flags and returns a file handle.
 	// The returned VirtualFile must be closed by the caller. Use flags to control access.
 	OpenFile(ctx context.Context, path string, flags data.AccessMode) (mount.Streamer, error)
@@ -81,7 +144,96 @@ type VirtualFileSystem interface {
 
 	// Rename moves or renames a file or directory from oldPath to newPath.
 	// Returns an error if the operation cannot be completed.
-	// This implementation uses a copy-and-delete strategy which works across different mounts
-	// but is not atomic and may not be optimal for large files.
+	// Renames within a single mount are performed atomically by the backends, if supported.
+	// Otherwise a streamed copy-and-delete strategy is used, which is not atomic.
 	Rename(ctx context.Context, oldPath string, newPath string) error
+
+	// Copy copies the file or directory at src to dst, streaming the content in chunks.
+	// Server-side copies are used, if both paths share a backend that supports them.
+	// A nil opts copies a single file without preserving any metadata.
+	Copy(ctx context.Context, src string, dst string, opts *data.CopyOptions) error
+
+	// Chmod changes the permission bits of the file or directory at path.
+	// Type bits of the existing mode are always preserved.
+	Chmod(ctx context.Context, path string, mode data.FileMode) error
+
+	// Chown changes the user and group ownership of the file or directory at path.
+	// A negative uid or gid leaves the respective value unchanged.
+	Chown(ctx context.Context, path string, uid, gid int64) error
+
+	// SetAttributes merges the specified attributes into the existing attributes at path.
+	SetAttributes(ctx context.Context, path string, attributes map[string]string) error
+
+	// RemoveAttribute removes a single attribute from the file or directory at path.
+	// Returns an error if the attribute doesn't exist.
+	RemoveAttribute(ctx context.Context, path string, key string) error
+
+	// SetTimes changes the access and modification time of the file or directory at path.
+	// A zero time leaves the respective value unchanged.
+	SetTimes(ctx context.Context, path string, accessTime, modifyTime time.Time) error
+
+	// UpdateChecksum recomputes the content checksum of the file at path and stores it within metadata.
+	// Returns data.ErrNotSupported if the mount containing path doesn't store checksums.
+	UpdateChecksum(ctx context.Context, path string) error
+}
+
+// Transaction is a view on a single mount, whose changes are committed or rolled back together.
+// Reads observe the staged changes of the transaction, while all other readers only observe committed state.
+// Changes are applied with the full staged content, so concurrent writes to the same keys are overwritten.
+type Transaction interface {
+	// Path returns the path of the mount this transaction belongs to.
+	Path() string
+
+	// StatMetadata returns the staged file information for the given path.
+	StatMetadata(ctx context.Context, path string) (*data.Metadata, error)
+
+	// ReadFile reads size bytes from the staged file at path starting at offset.
+	ReadFile(ctx context.Context, path string, offset, size int64) ([]byte, error)
+
+	// WriteFile stages writing data to the file at path starting at offset.
+	// The file is created, if it doesn't exist yet.
+	WriteFile(ctx context.Context, path string, offset int64, buffer []byte) (int, error)
+
+	// ReadDirectory returns the staged entries of the directory at path.
+	ReadDirectory(ctx context.Context, path string) ([]*data.Metadata, error)
+
+	// CreateDirectory stages creating a new directory at the specified path.
+	CreateDirectory(ctx context.Context, path string) error
+
+	// RemoveDirectory stages removing the empty directory at the specified path.
+	RemoveDirectory(ctx context.Context, path string) error
+
+	// UnlinkFile stages removing the file at the specified path.
+	UnlinkFile(ctx context.Context, path string) error
+
+	// Chmod stages changing the permission bits of the file or directory at path.
+	Chmod(ctx context.Context, path string, mode data.FileMode) error
+
+	// SetAttributes stages merging the specified attributes into the existing attributes at path.
+	SetAttributes(ctx context.Context, path string, attributes map[string]string) error
+
+	// Commit applies all staged changes atomically and ends the transaction.
+	// If applying fails, none of the changes are visible and the transaction is ended as well.
+	Commit(ctx context.Context) error
+
+	// Rollback discards all staged changes and ends the transaction.
+	// Returns data.ErrTransactionDone if the transaction has already been ended.
+	Rollback(ctx context.Context) error
+}
+
+// FileLock is the handle of a lock acquired with Lock.
+// The lock is released by Unlock or expires, if it isn't refreshed within its lease.
+type FileLock interface {
+	// Path returns the absolute path of the locked file or directory.
+	Path() string
+
+	// Info returns a copy of the lock description, including its current expiry.
+	Info() *data.LockInfo
+
+	// Refresh extends the lease of the lock, so it doesn't expire.
+	// Returns data.ErrLockNotHeld if the lock has already expired or been released.
+	Refresh(ctx context.Context) error
+
+	// Unlock releases the lock. Returns data.ErrLockNotHeld if the lock has already been released.
+	Unlock(ctx context.Context) error
 }
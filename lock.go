@@ -0,0 +1,191 @@
+package vfs
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/mwantia/vfs/data"
+	"github.com/mwantia/vfs/mount"
+)
+
+const (
+	// lockRetryMin is the initial delay between attempts to acquire a conflicting lock.
+	lockRetryMin = 10 * time.Millisecond
+	// lockRetryMax is the maximum delay between attempts to acquire a conflicting lock.
+	lockRetryMax = 250 * time.Millisecond
+)
+
+// fileLockImpl is the handle of a single acquired lock.
+type fileLockImpl struct {
+	mu       sync.Mutex
+	vfs      *virtualFileSystemImpl
+	mnt      *mount.Mount
+	path     string
+	info     *data.LockInfo
+	released bool
+}
+
+// Lock acquires a shared or exclusive lock on the file or directory at path.
+// Conflicting locks of other owners are waited for until timeout has passed (0 fails immediately).
+// A nil opts locks the whole file with the default lease for the owner stored in ctx.
+// Mounts with mandatory locks require an owner, so the owner can still access the locked file.
+func (vfs *virtualFileSystemImpl) Lock(ctx context.Context, path string, lockType data.LockType, timeout time.Duration, opts *data.LockOptions) (FileLock, error) {
+	return intercept(ctx, vfs, vfs.newOperation(OperationLock, path), func(ctx context.Context, op *Operation) (FileLock, error) {
+		return vfs.lock(ctx, op.Path, lockType, timeout, opts)
+	})
+}
+
+// lock implements Lock, after the operation passed all interceptors.
+func (vfs *virtualFileSystemImpl) lock(ctx context.Context, path string, lockType data.LockType, timeout time.Duration, opts *data.LockOptions) (FileLock, error) {
+	// Always start with an absolute path
+	absolute, err := vfs.toAbsolutePath(path)
+	if err != nil {
+		vfs.log.Error("Lock: failed to convert path to absolute: %s - %v", path, err)
+		return nil, err
+	}
+	if !lockType.IsValid() || (opts != nil && (opts.Offset < 0 || opts.Length < 0)) {
+		vfs.log.Error("Lock: invalid lock request for %s", absolute)
+		return nil, data.ErrInvalid
+	}
+
+	vfs.log.Debug("Lock: path=%s type=%s timeout=%s", absolute, lockType, timeout)
+
+	mnt, err := vfs.getMountFromPath(absolute)
+	if err != nil {
+		vfs.log.Error("Lock: no mount found for path: %s - %v", absolute, err)
+		return nil, err
+	}
+	// Only existing files and directories can be locked
+	if _, err := vfs.statMetadata(ctx, absolute); err != nil {
+		vfs.log.Error("Lock: failed to stat %s - %v", absolute, err)
+		return nil, err
+	}
+
+	owner := data.LockOwner(ctx)
+	if opts != nil && opts.Owner != "" {
+		owner = opts.Owner
+	}
+	// Without owner, no operation could ever access the file while the lock is held
+	if owner == "" && mnt.Options.MandatoryLocks {
+		vfs.log.Error("Lock: mandatory locks on %s require an owner", absolute)
+		return nil, data.ErrInvalid
+	}
+
+	relative := vfs.getPrefixRelativePath(mnt, absolute)
+	info := data.NewLockInfo(relative, lockType, owner, opts)
+
+	deadline := time.Now().Add(timeout)
+	delay := lockRetryMin
+	for {
+		err := mnt.AcquireLock(ctx, info)
+		if err == nil {
+			break
+		}
+		if err != data.ErrLocked {
+			vfs.log.Error("Lock: failed to acquire lock on %s - %v", absolute, err)
+			return nil, err
+		}
+
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			vfs.log.Error("Lock: %s is locked by another owner", absolute)
+			return nil, data.ErrLocked
+		}
+
+		timer := time.NewTimer(min(delay, remaining))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+
+		delay = min(delay*2, lockRetryMax)
+		// The lease starts once the lock has been acquired
+		info.Renew(time.Now())
+	}
+
+	vfs.log.Info("Lock: acquired %s lock on %s", lockType, absolute)
+	return &fileLockImpl{
+		vfs:  vfs,
+		mnt:  mnt,
+		path: absolute,
+		info: info,
+	}, nil
+}
+
+// ListLocks returns all unexpired locks held on the file or directory at path.
+func (vfs *virtualFileSystemImpl) ListLocks(ctx context.Context, path string) ([]*data.LockInfo, error) {
+	return intercept(ctx, vfs, vfs.newOperation(OperationListLocks, path), func(ctx context.Context, op *Operation) ([]*data.LockInfo, error) {
+		return vfs.listLocks(ctx, op.Path)
+	})
+}
+
+// listLocks implements ListLocks, after the operation passed all interceptors.
+func (vfs *virtualFileSystemImpl) listLocks(ctx context.Context, path string) ([]*data.LockInfo, error) {
+	// Always start with an absolute path
+	absolute, err := vfs.toAbsolutePath(path)
+	if err != nil {
+		vfs.log.Error("ListLocks: failed to convert path to absolute: %s - %v", path, err)
+		return nil, err
+	}
+
+	mnt, err := vfs.getMountFromPath(absolute)
+	if err != nil {
+		vfs.log.Error("ListLocks: no mount found for path: %s - %v", absolute, err)
+		return nil, err
+	}
+
+	return mnt.ListLocks(ctx, vfs.getPrefixRelativePath(mnt, absolute))
+}
+
+// Path returns the absolute path of the locked file or directory.
+func (fl *fileLockImpl) Path() string {
+	return fl.path
+}
+
+// Info returns a copy of the lock description, including its current expiry.
+func (fl *fileLockImpl) Info() *data.LockInfo {
+	fl.mu.Lock()
+	defer fl.mu.Unlock()
+
+	return fl.info.Clone()
+}
+
+// Refresh extends the lease of the lock, so it doesn't expire.
+// Returns data.ErrLockNotHeld if the lock has already expired or been released.
+func (fl *fileLockImpl) Refresh(ctx context.Context) error {
+	fl.mu.Lock()
+	defer fl.mu.Unlock()
+
+	if fl.released {
+		return data.ErrLockNotHeld
+	}
+
+	if err := fl.mnt.RefreshLock(ctx, fl.info); err != nil {
+		fl.vfs.log.Error("Lock: failed to refresh lock on %s - %v", fl.path, err)
+		return err
+	}
+
+	return nil
+}
+
+// Unlock releases the lock. Returns data.ErrLockNotHeld if the lock has already been released.
+func (fl *fileLockImpl) Unlock(ctx context.Context) error {
+	fl.mu.Lock()
+	defer fl.mu.Unlock()
+
+	if fl.released {
+		return data.ErrLockNotHeld
+	}
+	fl.released = true
+
+	if err := fl.mnt.ReleaseLock(ctx, fl.info); err != nil {
+		fl.vfs.log.Error("Lock: failed to release lock on %s - %v", fl.path, err)
+		return err
+	}
+
+	fl.vfs.log.Info("Lock: released lock on %s", fl.path)
+	return nil
+}
@@ -0,0 +1,317 @@
+package vfs_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mwantia/vfs"
+	"github.com/mwantia/vfs/data"
+	"github.com/mwantia/vfs/log"
+	"github.com/mwantia/vfs/mount"
+	"github.com/mwantia/vfs/mount/backend/ephemeral"
+)
+
+// TestLock_Conflicts verifies shared, exclusive and byte-range locks between different owners.
+func TestLock_Conflicts(t *testing.T) {
+	ctx := t.Context()
+	fs, err := vfs.NewVirtualFileSystem(vfs.WithLogLevel(log.Error))
+	if err != nil {
+		t.Fatalf("Failed to initialize vfs: %v", err)
+	}
+	defer fs.Shutdown(ctx)
+
+	if err := fs.Mount(ctx, "/", ephemeral.NewEphemeralBackend()); err != nil {
+		t.Fatalf("Failed to mount /: %v", err)
+	}
+	writeTestFile(t, fs, "/file.txt", []byte("content"))
+
+	alice := data.WithLockOwner(ctx, "alice")
+	bob := data.WithLockOwner(ctx, "bob")
+
+	if _, err := fs.Lock(ctx, "/missing.txt", data.LockShared, 0, nil); !errors.Is(err, data.ErrNotExist) {
+		t.Errorf("Expected ErrNotExist for missing file, got %v", err)
+	}
+
+	shared, err := fs.Lock(alice, "/file.txt", data.LockShared, 0, nil)
+	if err != nil {
+		t.Fatalf("Shared lock failed: %v", err)
+	}
+	other, err := fs.Lock(bob, "/file.txt", data.LockShared, 0, nil)
+	if err != nil {
+		t.Fatalf("Expected shared locks to be compatible: %v", err)
+	}
+	if _, err := fs.Lock(bob, "/file.txt", data.LockExclusive, 0, nil); !errors.Is(err, data.ErrLocked) {
+		t.Errorf("Expected ErrLocked for exclusive lock on shared file, got %v", err)
+	}
+
+	locks, err := fs.ListLocks(ctx, "/file.txt")
+	if err != nil || len(locks) != 2 {
+		t.Errorf("Expected 2 locks, got %d: %v", len(locks), err)
+	}
+
+	if err := shared.Unlock(ctx); err != nil {
+		t.Fatalf("Unlock failed: %v", err)
+	}
+	if err := shared.Unlock(ctx); !errors.Is(err, data.ErrLockNotHeld) {
+		t.Errorf("Expected ErrLockNotHeld for second unlock, got %v", err)
+	}
+
+	// Byte ranges only conflict if they overlap
+	if _, err := fs.Lock(alice, "/file.txt", data.LockExclusive, 0, &data.LockOptions{Offset: 100, Length: 10}); !errors.Is(err, data.ErrLocked) {
+		t.Errorf("Expected shared lock on the whole file to conflict, got %v", err)
+	}
+	if err := other.Unlock(ctx); err != nil {
+		t.Fatalf("Unlock failed: %v", err)
+	}
+	if _, err := fs.Lock(alice, "/file.txt", data.LockExclusive, 0, &data.LockOptions{Offset: 100, Length: 10}); err != nil {
+		t.Fatalf("Exclusive range lock failed: %v", err)
+	}
+	if _, err := fs.Lock(bob, "/file.txt", data.LockExclusive, 0, &data.LockOptions{Offset: 110, Length: 10}); err != nil {
+		t.Errorf("Expected adjacent range lock to succeed: %v", err)
+	}
+	if _, err := fs.Lock(bob, "/file.txt", data.LockShared, 0, &data.LockOptions{Offset: 105, Length: 10}); !errors.Is(err, data.ErrLocked) {
+		t.Errorf("Expected overlapping range lock to conflict, got %v", err)
+	}
+
+	if _, err := fs.Lock(alice, "/file.txt", data.LockShared, 0, &data.LockOptions{Offset: 100, Length: 5}); err != nil {
+		t.Errorf("Expected locks of the same owner to never conflict: %v", err)
+	}
+}
+
+// TestLock_WaitAndLease verifies waiting for released locks and the expiry of leases.
+func TestLock_WaitAndLease(t *testing.T) {
+	ctx := t.Context()
+	fs, err := vfs.NewVirtualFileSystem(vfs.WithLogLevel(log.Error))
+	if err != nil {
+		t.Fatalf("Failed to initialize vfs: %v", err)
+	}
+	defer fs.Shutdown(ctx)
+
+	if err := fs.Mount(ctx, "/", ephemeral.NewEphemeralBackend()); err != nil {
+		t.Fatalf("Failed to mount /: %v", err)
+	}
+	writeTestFile(t, fs, "/file.txt", []byte("content"))
+
+	first, err := fs.Lock(ctx, "/file.txt", data.LockExclusive, 0, nil)
+	if err != nil {
+		t.Fatalf("Exclusive lock failed: %v", err)
+	}
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		first.Unlock(ctx)
+	}()
+
+	// Without owner, every lock is owned by its own handle
+	second, err := fs.Lock(ctx, "/file.txt", data.LockExclusive, time.Second, &data.LockOptions{Lease: 100 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("Expected lock to be acquired after release: %v", err)
+	}
+	if err := second.Refresh(ctx); err != nil {
+		t.Errorf("Refresh failed: %v", err)
+	}
+
+	time.Sleep(150 * time.Millisecond)
+
+	if _, err := fs.Lock(ctx, "/file.txt", data.LockExclusive, 0, nil); err != nil {
+		t.Errorf("Expected expired lock to be released: %v", err)
+	}
+	if err := second.Refresh(ctx); !errors.Is(err, data.ErrLockNotHeld) {
+		t.Errorf("Expected ErrLockNotHeld for expired lock, got %v", err)
+	}
+}
+
+// TestLock_Mandatory verifies that OpenFile honors locks of other owners on mounts with mandatory locking.
+func TestLock_Mandatory(t *testing.T) {
+	ctx := t.Context()
+	fs, err := vfs.NewVirtualFileSystem(vfs.WithLogLevel(log.Error))
+	if err != nil {
+		t.Fatalf("Failed to initialize vfs: %v", err)
+	}
+	defer fs.Shutdown(ctx)
+
+	if err := fs.Mount(ctx, "/", ephemeral.NewEphemeralBackend(), mount.WithMandatoryLocks()); err != nil {
+		t.Fatalf("Failed to mount /: %v", err)
+	}
+	writeTestFile(t, fs, "/file.txt", []byte("content"))
+
+	alice := data.WithLockOwner(ctx, "alice")
+	bob := data.WithLockOwner(ctx, "bob")
+
+	lock, err := fs.Lock(alice, "/file.txt", data.LockShared, 0, nil)
+	if err != nil {
+		t.Fatalf("Shared lock failed: %v", err)
+	}
+
+	streamer, err := fs.OpenFile(bob, "/file.txt", data.AccessModeRead)
+	if err != nil {
+		t.Fatalf("Expected reading to be allowed with shared lock: %v", err)
+	}
+	streamer.Close()
+
+	if _, err := fs.OpenFile(bob, "/file.txt", data.AccessModeWrite); !errors.Is(err, data.ErrLocked) {
+		t.Errorf("Expected ErrLocked for writing with shared lock, got %v", err)
+	}
+
+	streamer, err = fs.OpenFile(alice, "/file.txt", data.AccessModeWrite)
+	if err != nil {
+		t.Fatalf("Expected the owner to write the locked file: %v", err)
+	}
+	streamer.Close()
+
+	if err := lock.Unlock(ctx); err != nil {
+		t.Fatalf("Unlock failed: %v", err)
+	}
+
+	streamer, err = fs.OpenFile(bob, "/file.txt", data.AccessModeWrite)
+	if err != nil {
+		t.Fatalf("Expected writing to be allowed after unlock: %v", err)
+	}
+	streamer.Close()
+}
+
+// TestLock_MandatoryOperations verifies that reads, writes and removals of other owners fail with mandatory locks.
+func TestLock_MandatoryOperations(t *testing.T) {
+	ctx := t.Context()
+	fs, err := vfs.NewVirtualFileSystem(vfs.WithLogLevel(log.Error))
+	if err != nil {
+		t.Fatalf("Failed to initialize vfs: %v", err)
+	}
+	defer fs.Shutdown(ctx)
+
+	if err := fs.Mount(ctx, "/", ephemeral.NewEphemeralBackend(), mount.WithMandatoryLocks()); err != nil {
+		t.Fatalf("Failed to mount /: %v", err)
+	}
+	if err := fs.CreateDirectory(ctx, "/docs"); err != nil {
+		t.Fatalf("CreateDirectory failed: %v", err)
+	}
+	writeTestFile(t, fs, "/docs/file.txt", []byte("content"))
+	writeTestFile(t, fs, "/target.txt", []byte("target"))
+
+	alice := data.WithLockOwner(ctx, "alice")
+	bob := data.WithLockOwner(ctx, "bob")
+
+	lock, err := fs.Lock(alice, "/docs/file.txt", data.LockExclusive, 0, nil)
+	if err != nil {
+		t.Fatalf("Exclusive lock failed: %v", err)
+	}
+	target, err := fs.Lock(alice, "/target.txt", data.LockShared, 0, nil)
+	if err != nil {
+		t.Fatalf("Shared lock failed: %v", err)
+	}
+
+	if _, err := fs.ReadFile(bob, "/docs/file.txt", 0, 7); !errors.Is(err, data.ErrLocked) {
+		t.Errorf("Expected ErrLocked for ReadFile, got %v", err)
+	}
+	if _, err := fs.WriteFile(bob, "/docs/file.txt", 0, []byte("xx")); !errors.Is(err, data.ErrLocked) {
+		t.Errorf("Expected ErrLocked for WriteFile, got %v", err)
+	}
+	if err := fs.UnlinkFile(bob, "/docs/file.txt"); !errors.Is(err, data.ErrLocked) {
+		t.Errorf("Expected ErrLocked for UnlinkFile, got %v", err)
+	}
+	if err := fs.Rename(bob, "/docs/file.txt", "/moved.txt"); !errors.Is(err, data.ErrLocked) {
+		t.Errorf("Expected ErrLocked for Rename of the locked file, got %v", err)
+	}
+	if err := fs.Rename(bob, "/docs", "/moved"); !errors.Is(err, data.ErrLocked) {
+		t.Errorf("Expected ErrLocked for Rename of the parent directory, got %v", err)
+	}
+	if err := fs.RemoveDirectory(bob, "/docs", true); !errors.Is(err, data.ErrLocked) {
+		t.Errorf("Expected ErrLocked for RemoveDirectory, got %v", err)
+	}
+
+	// Shared locks still allow reads, but prevent writes
+	if result, err := fs.ReadFile(bob, "/target.txt", 0, 6); err != nil || string(result) != "target" {
+		t.Errorf("Expected reading to be allowed with shared lock, got %q (%v)", result, err)
+	}
+	if _, err := fs.WriteFile(bob, "/target.txt", 0, []byte("T")); !errors.Is(err, data.ErrLocked) {
+		t.Errorf("Expected ErrLocked for WriteFile with shared lock, got %v", err)
+	}
+
+	// The owner is not affected by its own locks
+	if _, err := fs.WriteFile(alice, "/docs/file.txt", 0, []byte("CO")); err != nil {
+		t.Errorf("Expected the owner to write the locked file: %v", err)
+	}
+	if result, err := fs.ReadFile(alice, "/docs/file.txt", 0, 7); err != nil || string(result) != "COntent" {
+		t.Errorf("Expected the owner to read the locked file, got %q (%v)", result, err)
+	}
+
+	if err := lock.Unlock(ctx); err != nil {
+		t.Fatalf("Unlock failed: %v", err)
+	}
+	if err := target.Unlock(ctx); err != nil {
+		t.Fatalf("Unlock failed: %v", err)
+	}
+	if err := fs.UnlinkFile(bob, "/docs/file.txt"); err != nil {
+		t.Errorf("Expected UnlinkFile to be allowed after unlock: %v", err)
+	}
+}
+
+// TestLock_MandatoryRanges verifies that mandatory locks only protect their own byte range.
+func TestLock_MandatoryRanges(t *testing.T) {
+	ctx := t.Context()
+	fs, err := vfs.NewVirtualFileSystem(vfs.WithLogLevel(log.Error))
+	if err != nil {
+		t.Fatalf("Failed to initialize vfs: %v", err)
+	}
+	defer fs.Shutdown(ctx)
+
+	if err := fs.Mount(ctx, "/", ephemeral.NewEphemeralBackend(), mount.WithMandatoryLocks()); err != nil {
+		t.Fatalf("Failed to mount /: %v", err)
+	}
+	writeTestFile(t, fs, "/file.txt", []byte("0123456789"))
+
+	alice := data.WithLockOwner(ctx, "alice")
+	bob := data.WithLockOwner(ctx, "bob")
+
+	lock, err := fs.Lock(alice, "/file.txt", data.LockExclusive, 0, &data.LockOptions{Offset: 2, Length: 4})
+	if err != nil {
+		t.Fatalf("Range lock failed: %v", err)
+	}
+	defer lock.Unlock(ctx)
+
+	if _, err := fs.WriteFile(bob, "/file.txt", 6, []byte("XY")); err != nil {
+		t.Errorf("Expected writing outside of the locked range to be allowed: %v", err)
+	}
+	if result, err := fs.ReadFile(bob, "/file.txt", 0, 2); err != nil || string(result) != "01" {
+		t.Errorf("Expected reading outside of the locked range to be allowed, got %q (%v)", result, err)
+	}
+	if _, err := fs.WriteFile(bob, "/file.txt", 5, []byte("XY")); !errors.Is(err, data.ErrLocked) {
+		t.Errorf("Expected ErrLocked for writing into the locked range, got %v", err)
+	}
+	if _, err := fs.ReadFile(bob, "/file.txt", 1, 2); !errors.Is(err, data.ErrLocked) {
+		t.Errorf("Expected ErrLocked for reading from the locked range, got %v", err)
+	}
+	if err := fs.UnlinkFile(bob, "/file.txt"); !errors.Is(err, data.ErrLocked) {
+		t.Errorf("Expected ErrLocked for UnlinkFile, got %v", err)
+	}
+}
+
+// TestLock_MandatoryRequiresOwner verifies that mandatory locks can't be acquired without owner.
+func TestLock_MandatoryRequiresOwner(t *testing.T) {
+	ctx := t.Context()
+	fs, err := vfs.NewVirtualFileSystem(vfs.WithLogLevel(log.Error))
+	if err != nil {
+		t.Fatalf("Failed to initialize vfs: %v", err)
+	}
+	defer fs.Shutdown(ctx)
+
+	if err := fs.Mount(ctx, "/", ephemeral.NewEphemeralBackend(), mount.WithMandatoryLocks()); err != nil {
+		t.Fatalf("Failed to mount /: %v", err)
+	}
+	writeTestFile(t, fs, "/file.txt", []byte("content"))
+
+	if _, err := fs.Lock(ctx, "/file.txt", data.LockExclusive, 0, nil); !errors.Is(err, data.ErrInvalid) {
+		t.Fatalf("Expected ErrInvalid for lock without owner, got %v", err)
+	}
+
+	lock, err := fs.Lock(ctx, "/file.txt", data.LockExclusive, 0, &data.LockOptions{Owner: "alice"})
+	if err != nil {
+		t.Fatalf("Lock with explicit owner failed: %v", err)
+	}
+	defer lock.Unlock(ctx)
+
+	if _, err := fs.WriteFile(data.WithLockOwner(ctx, "alice"), "/file.txt", 0, []byte("C")); err != nil {
+		t.Errorf("Expected the owner to write the locked file: %v", err)
+	}
+}
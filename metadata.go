@@ -0,0 +1,278 @@
+package vfs
+
+import (
+	"context"
+	"maps"
+	"time"
+
+	"github.com/mwantia/vfs/data"
+	"github.com/mwantia/vfs/data/errors"
+	"github.com/mwantia/vfs/mount"
+)
+
+// Chmod changes the permission bits of the file or directory at path.
+// Type bits of the existing mode are always preserved.
+func (vfs *virtualFileSystemImpl) Chmod(ctx context.Context, path string, mode data.FileMode) error {
+	return vfs.interceptError(ctx, vfs.newOperation(OperationChmod, path), func(ctx context.Context, op *Operation) error {
+		return vfs.chmod(ctx, op.Path, mode)
+	})
+}
+
+// chmod implements Chmod, after the operation passed all interceptors.
+func (vfs *virtualFileSystemImpl) chmod(ctx context.Context, path string, mode data.FileMode) error {
+	return vfs.updateMetadata(ctx, "Chmod", path, func(meta *data.Metadata) (*data.MetadataUpdate, error) {
+		return &data.MetadataUpdate{
+			Mask: data.MetadataUpdateMode,
+			Metadata: &data.Metadata{
+				Mode: (meta.Mode &^ data.ModePerm) | mode.Perm(),
+			},
+		}, nil
+	})
+}
+
+// Chown changes the user and group ownership of the file or directory at path.
+// A negative uid or gid leaves the respective value unchanged.
+func (vfs *virtualFileSystemImpl) Chown(ctx context.Context, path string, uid, gid int64) error {
+	return vfs.interceptError(ctx, vfs.newOperation(OperationChown, path), func(ctx context.Context, op *Operation) error {
+		return vfs.chown(ctx, op.Path, uid, gid)
+	})
+}
+
+// chown implements Chown, after the operation passed all interceptors.
+func (vfs *virtualFileSystemImpl) chown(ctx context.Context, path string, uid, gid int64) error {
+	return vfs.updateMetadata(ctx, "Chown", path, func(meta *data.Metadata) (*data.MetadataUpdate, error) {
+		update := &data.MetadataUpdate{
+			Metadata: &data.Metadata{
+				UID: uid,
+				GID: gid,
+			},
+		}
+		if uid >= 0 {
+			update.Mask |= data.MetadataUpdateUID
+		}
+		if gid >= 0 {
+			update.Mask |= data.MetadataUpdateGID
+		}
+
+		return update, nil
+	})
+}
+
+// SetAttributes merges the specified attributes into the existing attributes at path.
+func (vfs *virtualFileSystemImpl) SetAttributes(ctx context.Context, path string, attributes map[string]string) error {
+	return vfs.interceptError(ctx, vfs.newOperation(OperationSetAttributes, path), func(ctx context.Context, op *Operation) error {
+		return vfs.setAttributes(ctx, op.Path, attributes)
+	})
+}
+
+// setAttributes implements SetAttributes, after the operation passed all interceptors.
+func (vfs *virtualFileSystemImpl) setAttributes(ctx context.Context, path string, attributes map[string]string) error {
+	for key := range attributes {
+		if key == "" {
+			return errors.AttributeInvalid(nil, key)
+		}
+	}
+
+	return vfs.updateMetadata(ctx, "SetAttributes", path, func(meta *data.Metadata) (*data.MetadataUpdate, error) {
+		merged := make(map[string]string, len(meta.Attributes)+len(attributes))
+		maps.Copy(merged, meta.Attributes)
+		maps.Copy(merged, attributes)
+
+		return &data.MetadataUpdate{
+			Mask: data.MetadataUpdateAttributes,
+			Metadata: &data.Metadata{
+				Attributes: merged,
+			},
+		}, nil
+	})
+}
+
+// RemoveAttribute removes a single attribute from the file or directory at path.
+// Returns an error if the attribute doesn't exist.
+func (vfs *virtualFileSystemImpl) RemoveAttribute(ctx context.Context, path string, key string) error {
+	return vfs.interceptError(ctx, vfs.newOperation(OperationRemoveAttribute, path), func(ctx context.Context, op *Operation) error {
+		return vfs.removeAttribute(ctx, op.Path, key)
+	})
+}
+
+// removeAttribute implements RemoveAttribute, after the operation passed all interceptors.
+func (vfs *virtualFileSystemImpl) removeAttribute(ctx context.Context, path string, key string) error {
+	return vfs.updateMetadata(ctx, "RemoveAttribute", path, func(meta *data.Metadata) (*data.MetadataUpdate, error) {
+		if !meta.HasAttribute(key) {
+			return nil, errors.AttributeNotExist(nil, key)
+		}
+
+		remaining := maps.Clone(meta.Attributes)
+		delete(remaining, key)
+
+		return &data.MetadataUpdate{
+			Mask: data.MetadataUpdateAttributes,
+			Metadata: &data.Metadata{
+				Attributes: remaining,
+			},
+		}, nil
+	})
+}
+
+// SetTimes changes the access and modification time of the file or directory at path.
+// A zero time leaves the respective value unchanged.
+func (vfs *virtualFileSystemImpl) SetTimes(ctx context.Context, path string, accessTime, modifyTime time.Time) error {
+	return vfs.interceptError(ctx, vfs.newOperation(OperationSetTimes, path), func(ctx context.Context, op *Operation) error {
+		return vfs.setTimes(ctx, op.Path, accessTime, modifyTime)
+	})
+}
+
+// setTimes implements SetTimes, after the operation passed all interceptors.
+func (vfs *virtualFileSystemImpl) setTimes(ctx context.Context, path string, accessTime, modifyTime time.Time) error {
+	return vfs.updateMetadata(ctx, "SetTimes", path, func(meta *data.Metadata) (*data.MetadataUpdate, error) {
+		update := &data.MetadataUpdate{
+			Metadata: &data.Metadata{
+				AccessTime: accessTime,
+				ModifyTime: modifyTime,
+			},
+		}
+		if !accessTime.IsZero() {
+			update.Mask |= data.MetadataUpdateAccessTime
+		}
+		// Keep the current modify time, since any update would otherwise refresh it
+		if modifyTime.IsZero() {
+			update.Metadata.ModifyTime = meta.ModifyTime
+		}
+		update.Mask |= data.MetadataUpdateModifyTime
+
+		return update, nil
+	})
+}
+
+// UpdateChecksum recomputes the content checksum of the file at path and stores it within metadata.
+// Writes without a streamer only mark the stored checksum as outdated, so it's recomputed on demand.
+// Returns data.ErrNotSupported if the mount containing path doesn't store checksums.
+func (vfs *virtualFileSystemImpl) UpdateChecksum(ctx context.Context, path string) error {
+	return vfs.interceptError(ctx, vfs.newOperation(OperationUpdateChecksum, path), func(ctx context.Context, op *Operation) error {
+		return vfs.updateChecksum(ctx, op.Path)
+	})
+}
+
+// updateChecksum implements UpdateChecksum, after the operation passed all interceptors.
+func (vfs *virtualFileSystemImpl) updateChecksum(ctx context.Context, path string) error {
+	// Always start with an absolute path
+	absolute, err := vfs.toAbsolutePath(path)
+	if err != nil {
+		vfs.log.Error("UpdateChecksum: failed to convert path to absolute: %s - %v", path, err)
+		return err
+	}
+
+	vfs.log.Debug("UpdateChecksum: path=%s", absolute)
+
+	mnt, err := vfs.getMountFromPath(absolute)
+	if err != nil {
+		vfs.log.Error("UpdateChecksum: no mount found for path: %s - %v", absolute, err)
+		return err
+	}
+	// Checksums are only stored with a metadata backend
+	if mnt.Options.Checksum == "" || mnt.Metadata == nil {
+		vfs.log.Error("UpdateChecksum: mount at %s doesn't store checksums", mnt.Path)
+		return data.ErrNotSupported
+	}
+	if mnt.Options.IsReadOnly {
+		vfs.log.Error("UpdateChecksum: cannot modify metadata on read-only mount at %s", mnt.Path)
+		return data.ErrReadOnly
+	}
+
+	relative := vfs.getPrefixRelativePath(mnt, absolute)
+	if err := mnt.UpdateChecksum(ctx, relative); err != nil {
+		vfs.log.Error("UpdateChecksum: failed to update checksum for %s - %v", absolute, err)
+		return err
+	}
+
+	mnt.Notify(data.EventAttrib, absolute, "")
+	return nil
+}
+
+// updateMetadata resolves the metadata at path and applies the update created by build.
+// Missing metadata is synced from object storage first, if the mount uses a separate metadata backend.
+func (vfs *virtualFileSystemImpl) updateMetadata(ctx context.Context, op string, path string, build func(meta *data.Metadata) (*data.MetadataUpdate, error)) error {
+	// Always start with an absolute path
+	absolute, err := vfs.toAbsolutePath(path)
+	if err != nil {
+		vfs.log.Error("%s: failed to convert path to absolute: %s - %v", op, path, err)
+		return err
+	}
+
+	vfs.log.Debug("%s: path=%s", op, absolute)
+
+	mnt, err := vfs.getMountFromPath(absolute)
+	if err != nil {
+		vfs.log.Error("%s: no mount found for path: %s - %v", op, absolute, err)
+		return err
+	}
+	// Fail if mount is readonly
+	if mnt.Options.IsReadOnly {
+		vfs.log.Error("%s: cannot modify metadata on read-only mount at %s", op, mnt.Path)
+		return data.ErrReadOnly
+	}
+	// Metadata can only be persisted with a metadata backend
+	if mnt.Metadata == nil {
+		vfs.log.Error("%s: mount at %s has no metadata backend", op, mnt.Path)
+		return errors.BackendUnsupported(nil, mnt.ObjectStorage.Name())
+	}
+
+	namespace := mnt.Options.Namespace
+	relative := vfs.getPrefixRelativePath(mnt, absolute)
+
+	meta, err := mnt.Metadata.ReadMeta(ctx, namespace, relative)
+	if err != nil {
+		// Fail if any error except NotExists
+		if err != data.ErrNotExist {
+			vfs.log.Error("%s: metadata read failed for %s - %v", op, absolute, err)
+			return err
+		}
+		vfs.log.Debug("%s: metadata not found, falling back to object storage for %s", op, absolute)
+		// Fallback to storage to read object stats
+		stat, err := mnt.ObjectStorage.HeadObject(ctx, namespace, relative)
+		if err != nil {
+			vfs.log.Error("%s: object storage HeadObject failed for %s - %v", op, absolute, err)
+			return err
+		}
+		// Convert object stats to metadata
+		meta = stat.ToMetadata()
+		if !mnt.IsDualMount {
+			vfs.log.Debug("%s: syncing object stat to metadata for %s", op, absolute)
+			// Write stat back into metadata
+			if err := mnt.Metadata.CreateMeta(ctx, namespace, meta); err != nil {
+				vfs.log.Error("%s: failed to sync metadata for %s - %v", op, absolute, err)
+				return err
+			}
+		}
+	}
+
+	update, err := build(meta)
+	if err != nil {
+		vfs.log.Error("%s: invalid update for %s - %v", op, absolute, err)
+		return err
+	}
+
+	// Changing the owner moves the usage of the object between user quotas
+	var usage []mount.QuotaDelta
+	if update.Mask&data.MetadataUpdateUID != 0 && update.Metadata.UID != meta.UID {
+		usage = []mount.QuotaDelta{
+			{UID: meta.UID, Bytes: -meta.Size, Objects: -1},
+			{UID: update.Metadata.UID, Bytes: meta.Size, Objects: 1},
+		}
+		if err := mnt.ChargeQuota(usage...); err != nil {
+			vfs.log.Error("%s: quota validation failed for %s - %v", op, absolute, err)
+			return err
+		}
+	}
+
+	if err := mnt.Metadata.UpdateMeta(ctx, namespace, relative, update); err != nil {
+		mnt.RefundQuota(usage...)
+		vfs.log.Error("%s: failed to update metadata for %s - %v", op, absolute, err)
+		return err
+	}
+
+	mnt.Notify(data.EventAttrib, absolute, "")
+
+	vfs.log.Info("%s: successfully updated metadata for %s", op, absolute)
+	return nil
+}
@@ -0,0 +1,88 @@
+package vfs
+
+import (
+	"context"
+
+	"github.com/mwantia/vfs/data"
+	"github.com/mwantia/vfs/mount/backend"
+)
+
+// Migrate copies all objects and metadata of the mount containing path to storage and metadata,
+// syncs all changes made in the meantime and switches the mount to the new backends.
+// All operations are only paused while the remaining changes are synced and the backends are switched.
+// A nil opts copies without verification, using the default number of passes.
+func (vfs *virtualFileSystemImpl) Migrate(ctx context.Context, path string, storage backend.ObjectStorageBackend, metadata backend.MetadataBackend, opts *data.MigrateOptions) (*data.MigrateReport, error) {
+	return intercept(ctx, vfs, vfs.newOperation(OperationMigrate, path), func(ctx context.Context, op *Operation) (*data.MigrateReport, error) {
+		return vfs.migrate(ctx, op.Path, storage, metadata, opts)
+	})
+}
+
+// migrate implements Migrate, after the operation passed all interceptors.
+func (vfs *virtualFileSystemImpl) migrate(ctx context.Context, path string, storage backend.ObjectStorageBackend, metadata backend.MetadataBackend, opts *data.MigrateOptions) (*data.MigrateReport, error) {
+	if opts == nil {
+		opts = &data.MigrateOptions{}
+	}
+
+	// Always start with an absolute path
+	absolute, err := vfs.toAbsolutePath(path)
+	if err != nil {
+		vfs.log.Error("Migrate: failed to convert path to absolute: %s - %v", path, err)
+		return nil, err
+	}
+
+	// Pausing all operations from within an operation would never succeed
+	if ctx.Value(gateKey{}) != nil {
+		vfs.log.Error("Migrate: cannot migrate %s from within another operation", absolute)
+		return nil, data.ErrBusy
+	}
+	if storage == nil {
+		vfs.log.Error("Migrate: no object storage provided for %s", absolute)
+		return nil, data.ErrInvalid
+	}
+
+	vfs.log.Debug("Migrate: path=%s storage=%s verify=%v", absolute, storage.Name(), opts.Verify)
+
+	mnt, err := vfs.getMountFromPath(absolute)
+	if err != nil {
+		vfs.log.Error("Migrate: no mount found for path: %s - %v", absolute, err)
+		return nil, err
+	}
+
+	migration, err := mnt.Migrate(ctx, storage, metadata, opts)
+	if err != nil {
+		vfs.log.Error("Migrate: failed to start migration of %s - %v", mnt.Path, err)
+		return nil, err
+	}
+
+	if err := migration.Copy(ctx); err != nil {
+		vfs.log.Error("Migrate: failed to copy %s - %v", mnt.Path, err)
+		migration.Abort(ctx)
+		return nil, err
+	}
+
+	// Waits until all running operations have completed and pauses all further operations
+	vfs.gate.Lock()
+	vfs.mu.Lock()
+	report, err := migration.Switch(ctx)
+	if err == nil {
+		if previous, exists := vfs.entries[mnt.Path]; exists {
+			// Describe the new backends, while keeping the parts of the entry unrelated to backends
+			entry := toMountTableEntry(mnt)
+			entry.Directories = previous.Directories
+			entry.DependsOn = previous.DependsOn
+			vfs.entries[mnt.Path] = entry
+		}
+	}
+	vfs.mu.Unlock()
+	vfs.gate.Unlock()
+
+	if err != nil {
+		vfs.log.Error("Migrate: failed to switch %s - %v", mnt.Path, err)
+		migration.Abort(ctx)
+		return nil, err
+	}
+
+	vfs.log.Info("Migrate: migrated %d file(s) and %d directories (%d bytes) of %s from %s to %s, synced %d change(s)",
+		report.Files, report.Directories, report.Bytes, mnt.Path, report.Source, report.Target, report.Synced+report.Removed)
+	return report, nil
+}
@@ -0,0 +1,193 @@
+package vfs_test
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mwantia/vfs"
+	"github.com/mwantia/vfs/data"
+	"github.com/mwantia/vfs/fstab"
+	"github.com/mwantia/vfs/log"
+	"github.com/mwantia/vfs/mount"
+	"github.com/mwantia/vfs/mount/backend"
+	"github.com/mwantia/vfs/mount/backend/ephemeral"
+	"github.com/mwantia/vfs/mount/backend/sqlite"
+)
+
+// TestMigrate_LiveChanges verifies that changes made during the copy are synced before the mount is switched.
+func TestMigrate_LiveChanges(t *testing.T) {
+	ctx := t.Context()
+	fs, err := vfs.NewVirtualFileSystem(vfs.WithLogLevel(log.Error))
+	if err != nil {
+		t.Fatalf("Failed to initialize vfs: %v", err)
+	}
+	defer fs.Shutdown(ctx)
+
+	source, err := sqlite.NewSQLiteBackend(filepath.Join(t.TempDir(), "vfs.db"))
+	if err != nil {
+		t.Fatalf("Failed to create sqlite backend: %v", err)
+	}
+	if err := fs.Mount(ctx, "/", source, mount.WithMetadata(source)); err != nil {
+		t.Fatalf("Failed to mount /: %v", err)
+	}
+
+	if err := fs.CreateDirectory(ctx, "/docs"); err != nil {
+		t.Fatalf("CreateDirectory failed: %v", err)
+	}
+	writeTestFile(t, fs, "/docs/a.txt", []byte("original"))
+	writeTestFile(t, fs, "/removed.txt", []byte("removed"))
+	if err := fs.SetAttributes(ctx, "/docs/a.txt", map[string]string{"user.tag": "kept"}); err != nil {
+		t.Fatalf("SetAttributes failed: %v", err)
+	}
+
+	// Kept open across the migration, so later writes have to reach the new storage
+	streamer, err := fs.OpenFile(ctx, "/open.txt", data.AccessModeWrite|data.AccessModeCreate)
+	if err != nil {
+		t.Fatalf("OpenFile failed: %v", err)
+	}
+
+	// Changes are made once the first entry has been copied
+	changed := false
+	opts := &data.MigrateOptions{
+		Verify: true,
+		Progress: func(key string, migrated int64) {
+			if changed {
+				return
+			}
+			changed = true
+
+			writeTestFile(t, fs, "/late.txt", []byte("written during migration"))
+			if _, err := fs.WriteFile(ctx, "/docs/a.txt", 0, []byte("modified")); err != nil {
+				t.Errorf("WriteFile failed: %v", err)
+			}
+			if err := fs.UnlinkFile(ctx, "/removed.txt"); err != nil {
+				t.Errorf("UnlinkFile failed: %v", err)
+			}
+		},
+	}
+
+	storage, metadata := ephemeral.NewEphemeralBackend(), ephemeral.NewEphemeralBackend()
+	report, err := fs.Migrate(ctx, "/", storage, metadata, opts)
+	if err != nil {
+		t.Fatalf("Migrate failed: %v", err)
+	}
+	if report.Source != "sqlite" || report.Target != "ephemeral" || report.Synced == 0 || report.Removed != 1 || report.Verified == 0 {
+		t.Errorf("Unexpected report: %+v", report)
+	}
+
+	if _, err := streamer.Write([]byte("after switch")); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if err := streamer.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	expected := map[string]string{
+		"docs/a.txt": "modified",
+		"late.txt":   "written during migration",
+		"open.txt":   "after switch",
+	}
+	for key, content := range expected {
+		buffer := make([]byte, len(content))
+		if n, err := storage.ReadObject(ctx, "", key, 0, buffer); err != nil || string(buffer[:n]) != content {
+			t.Errorf("Expected %q within new storage for %s, got %q (%v)", content, key, buffer[:n], err)
+		}
+	}
+	if _, err := storage.HeadObject(ctx, "", "removed.txt"); err != data.ErrNotExist {
+		t.Errorf("Expected removed file to be missing from new storage, got %v", err)
+	}
+
+	if meta, err := metadata.ReadMeta(ctx, "", "docs/a.txt"); err != nil || meta.GetAttribute("user.tag", "") != "kept" {
+		t.Errorf("Expected attributes to be migrated, got %+v (%v)", meta, err)
+	}
+	if report, err := fs.Fsck(ctx, "/", nil); err != nil || len(report.Issues) != 0 {
+		t.Errorf("Expected consistent mount after migration, got %+v (%v)", report, err)
+	}
+
+	table := fs.DumpMountTable()
+	if len(table.Mounts) != 1 || table.Mounts[0].Backend != "ephemeral" {
+		t.Errorf("Expected mount table to describe the new backend, got %+v", table.Mounts)
+	}
+}
+
+// TestMigrate_Command verifies the migrate command and that migrations are rejected without metadata.
+func TestMigrate_Command(t *testing.T) {
+	ctx := t.Context()
+	fs, err := vfs.NewVirtualFileSystem(vfs.WithLogLevel(log.Error))
+	if err != nil {
+		t.Fatalf("Failed to initialize vfs: %v", err)
+	}
+	defer fs.Shutdown(ctx)
+
+	source := ephemeral.NewEphemeralBackend()
+	if err := fs.Mount(ctx, "/", source, mount.WithMetadata(source)); err != nil {
+		t.Fatalf("Failed to mount /: %v", err)
+	}
+	if err := fs.CreateDirectory(ctx, "/dir"); err != nil {
+		t.Fatalf("CreateDirectory failed: %v", err)
+	}
+	writeTestFile(t, fs, "/dir/file.txt", []byte("content"))
+
+	var buffer bytes.Buffer
+	if code, err := fs.Execute(ctx, &buffer, "migrate", "mem://", "/"); err == nil || code == 0 {
+		t.Error("Expected migration without metadata to fail")
+	}
+
+	buffer.Reset()
+	if code, err := fs.Execute(ctx, &buffer, "migrate", "-m", "--verify", "mem://", "/"); err != nil || code != 0 {
+		t.Fatalf("migrate failed with %d: %v", code, err)
+	}
+	if output := buffer.String(); !strings.Contains(output, "copied 1 file(s) and 1 directories") || !strings.Contains(output, "verified 1 file(s)") {
+		t.Errorf("Unexpected migrate output: %q", output)
+	}
+
+	if result, err := fs.ReadFile(ctx, "/dir/file.txt", 0, 7); err != nil || string(result) != "content" {
+		t.Errorf("Unexpected content after migration: %q (%v)", result, err)
+	}
+}
+
+// TestMigrate_MountTableEntry verifies that migrated mounts of the mount table are dumped with their new backends.
+func TestMigrate_MountTableEntry(t *testing.T) {
+	ctx := t.Context()
+	fs, err := vfs.NewVirtualFileSystem(vfs.WithLogLevel(log.Error))
+	if err != nil {
+		t.Fatalf("Failed to initialize vfs: %v", err)
+	}
+	defer fs.Shutdown(ctx)
+
+	if err := fs.MountEntry(ctx, &fstab.Entry{
+		Path:    "/",
+		Backend: "ephemeral",
+		Extensions: []*fstab.Extension{
+			{Backend: fstab.PrimaryBackend, Capabilities: []backend.BackendCapability{backend.CapabilityMetadata}},
+		},
+		Directories: []string{"home"},
+	}); err != nil {
+		t.Fatalf("MountEntry failed: %v", err)
+	}
+	writeTestFile(t, fs, "/home/file.txt", []byte("content"))
+
+	target, err := sqlite.NewSQLiteBackend(filepath.Join(t.TempDir(), "vfs.db"))
+	if err != nil {
+		t.Fatalf("Failed to create sqlite backend: %v", err)
+	}
+	if _, err := fs.Migrate(ctx, "/", target, target, nil); err != nil {
+		t.Fatalf("Migrate failed: %v", err)
+	}
+
+	entry, exists := fs.DumpMountTable().Lookup("/")
+	if !exists {
+		t.Fatalf("Expected migrated mount within the mount table")
+	}
+	if entry.Backend != target.Name() {
+		t.Errorf("Expected entry to describe the new backend %s, got %s", target.Name(), entry.Backend)
+	}
+	if len(entry.Extensions) != 1 || entry.Extensions[0].Backend != fstab.PrimaryBackend {
+		t.Errorf("Expected metadata to be provided by the primary backend, got %+v", entry.Extensions)
+	}
+	if len(entry.Directories) != 1 || entry.Directories[0] != "home" {
+		t.Errorf("Expected skeleton directories to be kept, got %v", entry.Directories)
+	}
+}
@@ -14,8 +14,15 @@ import (
 // Mount attaches a filesystem handler at the specified path.
 // Options can be used to configure the mount (e.g., read-only).
 func (vfs *virtualFileSystemImpl) Mount(ctx context.Context, path string, primary backend.ObjectStorageBackend, opts ...mount.MountOption) error {
+	return vfs.interceptError(ctx, vfs.newOperation(OperationMount, path), func(ctx context.Context, op *Operation) error {
+		return vfs.mount(ctx, op.Path, primary, opts...)
+	})
+}
+
+// mount implements Mount, after the operation passed all interceptors.
+func (vfs *virtualFileSystemImpl) mount(ctx context.Context, path string, primary backend.ObjectStorageBackend, opts ...mount.MountOption) error {
 	// Always start with an absolute path
-	absolute, err := data.ToAbsolutePath(path)
+	absolute, err := vfs.toAbsolutePath(path)
 	if err != nil {
 		vfs.log.Error("Mount: failed to convert path to absolute: %s - %v", path, err)
 		return err
@@ -42,7 +49,7 @@ func (vfs *virtualFileSystemImpl) Mount(ctx context.Context, path string, primar
 	name := fmt.Sprintf("mount/%s", primary.Name())
 	log := vfs.log.Named(name)
 
-	mnt, err := mount.NewMountInfo(path, log, primary, opts...)
+	mnt, err := mount.NewMountInfo(absolute, log, primary, opts...)
 	if err != nil {
 		vfs.log.Error("Mount: failed to create mount info for %s - %v", absolute, err)
 		return err
@@ -53,6 +60,11 @@ func (vfs *virtualFileSystemImpl) Mount(ctx context.Context, path string, primar
 		return errors.PathAlreadyMounted(nil, absolute)
 	}
 
+	mnt.SetNotifier(vfs.watches.publish)
+	if counter, exists := vfs.quotas[mnt.Options.Namespace]; exists {
+		mnt.SetNamespaceQuota(counter)
+	}
+
 	vfs.log.Debug("Mount: initializing mount at %s (readonly=%v dual=%v)", absolute, mnt.Options.IsReadOnly, mnt.IsDualMount)
 	if err := mnt.Mount(ctx); err != nil {
 		vfs.log.Error("Mount: failed to mount %s backend at %s - %v", primary.Name(), absolute, err)
@@ -60,6 +72,8 @@ func (vfs *virtualFileSystemImpl) Mount(ctx context.Context, path string, primar
 	}
 
 	vfs.mnts[absolute] = mnt
+	mnt.Notify(data.EventMount, absolute, "")
+
 	vfs.log.Info("Mount: successfully mounted %s at %s", primary.Name(), absolute)
 	return nil
 }
@@ -67,8 +81,15 @@ func (vfs *virtualFileSystemImpl) Mount(ctx context.Context, path string, primar
 // Unmount removes the filesystem handler at the specified path.
 // Returns an error if the path is not mounted or has child mounts.
 func (vfs *virtualFileSystemImpl) Unmount(ctx context.Context, path string, force bool) error {
+	return vfs.interceptError(ctx, vfs.newOperation(OperationUnmount, path), func(ctx context.Context, op *Operation) error {
+		return vfs.unmount(ctx, op.Path, force)
+	})
+}
+
+// unmount implements Unmount, after the operation passed all interceptors.
+func (vfs *virtualFileSystemImpl) unmount(ctx context.Context, path string, force bool) error {
 	// Always start with an absolute path
-	absolute, err := data.ToAbsolutePath(path)
+	absolute, err := vfs.toAbsolutePath(path)
 	if err != nil {
 		vfs.log.Error("Unmount: failed to convert path to absolute: %s - %v", path, err)
 		return err
@@ -97,10 +118,23 @@ func (vfs *virtualFileSystemImpl) Unmount(ctx context.Context, path string, forc
 	}
 
 	delete(vfs.mnts, absolute)
+	delete(vfs.entries, absolute)
+	mnt.Notify(data.EventUnmount, absolute, "")
+
 	vfs.log.Info("Unmount: successfully unmounted %s", absolute)
 	return nil
 }
 
+// toAbsolutePath converts path into its canonical absolute form using the configured path limits.
+func (vfs *virtualFileSystemImpl) toAbsolutePath(path string) (string, error) {
+	canonical, err := vfs.limits.Parse(path)
+	if err != nil {
+		return "", err
+	}
+
+	return canonical.String(), nil
+}
+
 func (vfs *virtualFileSystemImpl) getPrefixRelativePath(mnt *mount.Mount, absolute string) string {
 	relative := data.ToRelativePath(absolute, mnt.Path)
 	// Update relative path if mount has been set with a path-prefix
@@ -147,7 +181,7 @@ func (vfs *virtualFileSystemImpl) getMountFromPath(path string) (*mount.Mount, e
 
 func (vfs *virtualFileSystemImpl) hasChildMounts(parent string) bool {
 	for mount := range vfs.mnts {
-		if mount != parent && data.HasPrefix(mount, parent) {
+		if mount != parent && data.Path(mount).HasPrefix(data.Path(parent)) {
 			return true
 		}
 	}
@@ -0,0 +1,14 @@
+// Package all registers the factories of all builtin backends.
+// Import it for its side effects, if backends should be created by name:
+//
+//	import _ "github.com/mwantia/vfs/mount/backend/all"
+package all
+
+import (
+	_ "github.com/mwantia/vfs/mount/backend/consul"
+	_ "github.com/mwantia/vfs/mount/backend/direct"
+	_ "github.com/mwantia/vfs/mount/backend/ephemeral"
+	_ "github.com/mwantia/vfs/mount/backend/postgres"
+	_ "github.com/mwantia/vfs/mount/backend/s3"
+	_ "github.com/mwantia/vfs/mount/backend/sqlite"
+)
@@ -0,0 +1,15 @@
+package backend
+
+import (
+	"context"
+
+	"github.com/mwantia/vfs/data"
+)
+
+// BatchMetaBackend is implemented by metadata backends, which are able to store many entries at once
+// (e.g. within a single transaction).
+type BatchMetaBackend interface {
+	// PutMetaBatch creates or replaces the metadata of all entries.
+	// Existing entries are matched by key and keep their id.
+	PutMetaBatch(ctx context.Context, namespace string, metas []*data.Metadata) error
+}
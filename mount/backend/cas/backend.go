@@ -0,0 +1,101 @@
+package cas
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/mwantia/vfs/data"
+	"github.com/mwantia/vfs/mount/backend"
+)
+
+const (
+	// DefaultBlockSize is the size content is split into, if no block size is defined.
+	DefaultBlockSize = 256 * 1024
+
+	// storeKey is the reserved key within the inner backend holding all blocks and their reference counts.
+	// Blocks are shared by all namespaces, so they are always stored within the empty namespace.
+	storeKey  = ".cas"
+	blocksKey = storeKey + "/blocks"
+	refsKey   = storeKey + "/refs"
+)
+
+// CASBackend wraps any object storage backend with content-addressed storage:
+//
+// Content of each object is split into blocks of a fixed size, which are stored within the inner backend
+// using the SHA-256 hash of their content as identifier. The object itself only stores a manifest
+// listing the hashes of its blocks. Identical blocks written to different keys or namespaces are
+// stored once and reference counted, so they are removed once no manifest refers to them anymore.
+type CASBackend struct {
+	mu        sync.RWMutex
+	inner     backend.ObjectStorageBackend
+	blockSize int
+}
+
+// NewCASBackend creates a content-addressed backend storing all blocks and manifests within inner.
+// The inner backend is owned by the returned backend and opened and closed together with it.
+// A blockSize of zero or less uses DefaultBlockSize.
+func NewCASBackend(inner backend.ObjectStorageBackend, blockSize int) (*CASBackend, error) {
+	if inner == nil {
+		return nil, fmt.Errorf("inner backend must not be nil")
+	}
+	if blockSize <= 0 {
+		blockSize = DefaultBlockSize
+	}
+
+	return &CASBackend{
+		inner:     inner,
+		blockSize: blockSize,
+	}, nil
+}
+
+// Returns the identifier name defined for this backend
+func (*CASBackend) Name() string {
+	return "cas"
+}
+
+// Open is part of the lifecycle behavious and gets called when opening this backend.
+func (cb *CASBackend) Open(ctx context.Context) error {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if err := cb.inner.Open(ctx); err != nil {
+		return err
+	}
+
+	// Ensure the reserved directories exist, before any block is stored
+	for _, key := range []string{storeKey, blocksKey, refsKey} {
+		if _, err := cb.inner.CreateObject(ctx, "", key, data.ModeDir|0755); err != nil && !errors.Is(err, data.ErrExist) {
+			return fmt.Errorf("failed to create '%s': %w", key, err)
+		}
+	}
+
+	return nil
+}
+
+// Close is part of the lifecycle behaviour and gets called when closing this backend.
+func (cb *CASBackend) Close(ctx context.Context) error {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	return cb.inner.Close(ctx)
+}
+
+// GetCapabilities returns a list of capabilities supported by this backend.
+func (cb *CASBackend) GetCapabilities() *backend.BackendCapabilities {
+	return &backend.BackendCapabilities{
+		Capabilities: []backend.BackendCapability{
+			backend.CapabilityObjectStorage,
+		},
+		// Objects are split into blocks, so only the manifest is limited by the inner backend
+		MaxObjectSize: 0,
+	}
+}
+
+// isReserved checks if key is located within the reserved block storage.
+func isReserved(key string) bool {
+	key = strings.TrimPrefix(key, "/")
+	return key == storeKey || strings.HasPrefix(key, storeKey+"/")
+}
@@ -0,0 +1,235 @@
+package cas
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/mwantia/vfs/data"
+	"github.com/mwantia/vfs/mount/backend"
+)
+
+// manifest lists the hashes of all blocks of a single object in order.
+// Holes are stored as empty hash and read as zeros, the same as bytes beyond the content of a block.
+type manifest struct {
+	Size   int64    `json:"size"`
+	Blocks []string `json:"blocks,omitempty"`
+}
+
+// blockAt returns the hash of the block with the specified index or an empty hash for holes.
+func (m *manifest) blockAt(index int64) string {
+	if index < int64(len(m.Blocks)) {
+		return m.Blocks[index]
+	}
+
+	return ""
+}
+
+// resize grows or shrinks the list of blocks to cover size bytes.
+func (m *manifest) resize(size int64, blockSize int) {
+	count := int((size + int64(blockSize) - 1) / int64(blockSize))
+	for len(m.Blocks) < count {
+		m.Blocks = append(m.Blocks, "")
+	}
+
+	m.Blocks = m.Blocks[:count]
+	m.Size = size
+}
+
+// readManifest reads the manifest stored as content of key. Empty objects have an empty manifest.
+func (cb *CASBackend) readManifest(ctx context.Context, namespace, key string) (*manifest, error) {
+	stat, err := cb.inner.HeadObject(ctx, namespace, key)
+	if err != nil {
+		return nil, err
+	}
+	if stat.Mode.IsDir() {
+		return nil, data.ErrIsDirectory
+	}
+
+	m := &manifest{}
+	if stat.Size == 0 {
+		return m, nil
+	}
+
+	content := make([]byte, stat.Size)
+	if err := readFull(ctx, cb.inner, namespace, key, content); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(content, m); err != nil {
+		return nil, fmt.Errorf("invalid manifest for '%s': %w", key, err)
+	}
+
+	return m, nil
+}
+
+// writeManifest replaces the content of key with the manifest.
+func (cb *CASBackend) writeManifest(ctx context.Context, namespace, key string, m *manifest) error {
+	// Trailing holes are implied by the size
+	for len(m.Blocks) > 0 && m.Blocks[len(m.Blocks)-1] == "" {
+		m.Blocks = m.Blocks[:len(m.Blocks)-1]
+	}
+
+	content, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	if err := cb.inner.TruncateObject(ctx, namespace, key, 0); err != nil {
+		return err
+	}
+
+	_, err = cb.inner.WriteObject(ctx, namespace, key, 0, content)
+	return err
+}
+
+// readBlock returns the content of the block with the specified hash.
+func (cb *CASBackend) readBlock(ctx context.Context, hash string) ([]byte, error) {
+	stat, err := cb.inner.HeadObject(ctx, "", blockKey(hash))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read block %s: %w", hash, err)
+	}
+
+	content := make([]byte, stat.Size)
+	if err := readFull(ctx, cb.inner, "", blockKey(hash), content); err != nil {
+		return nil, fmt.Errorf("failed to read block %s: %w", hash, err)
+	}
+
+	return content, nil
+}
+
+// retainBlock adds a reference to the block containing content and returns its hash.
+// The block is only stored, if no other object references identical content yet.
+// Empty content is never stored and returns an empty hash.
+func (cb *CASBackend) retainBlock(ctx context.Context, content []byte) (string, error) {
+	if len(content) == 0 {
+		return "", nil
+	}
+
+	sum := sha256.Sum256(content)
+	hash := hex.EncodeToString(sum[:])
+
+	refs, err := cb.readRefs(ctx, hash)
+	if err != nil {
+		return "", err
+	}
+	if refs == 0 {
+		if err := cb.writeObject(ctx, blockKey(hash), content); err != nil {
+			return "", fmt.Errorf("failed to store block %s: %w", hash, err)
+		}
+	}
+
+	return hash, cb.writeRefs(ctx, hash, refs+1)
+}
+
+// referenceBlock adds a reference to the already stored block with the specified hash.
+func (cb *CASBackend) referenceBlock(ctx context.Context, hash string) error {
+	if hash == "" {
+		return nil
+	}
+
+	refs, err := cb.readRefs(ctx, hash)
+	if err != nil {
+		return err
+	}
+	if refs == 0 {
+		return fmt.Errorf("%w: block %s", data.ErrNotExist, hash)
+	}
+
+	return cb.writeRefs(ctx, hash, refs+1)
+}
+
+// releaseBlock removes a reference from the block with the specified hash.
+// The block is deleted together with its reference count once it is no longer referenced.
+func (cb *CASBackend) releaseBlock(ctx context.Context, hash string) error {
+	if hash == "" {
+		return nil
+	}
+
+	refs, err := cb.readRefs(ctx, hash)
+	if err != nil {
+		return err
+	}
+	if refs > 1 {
+		return cb.writeRefs(ctx, hash, refs-1)
+	}
+
+	if err := cb.inner.DeleteObject(ctx, "", blockKey(hash), false); err != nil && !errors.Is(err, data.ErrNotExist) {
+		return fmt.Errorf("failed to delete block %s: %w", hash, err)
+	}
+	if err := cb.inner.DeleteObject(ctx, "", refKey(hash), false); err != nil && !errors.Is(err, data.ErrNotExist) {
+		return fmt.Errorf("failed to delete references of block %s: %w", hash, err)
+	}
+
+	return nil
+}
+
+// readRefs returns the number of references to the block with the specified hash.
+func (cb *CASBackend) readRefs(ctx context.Context, hash string) (int64, error) {
+	stat, err := cb.inner.HeadObject(ctx, "", refKey(hash))
+	if errors.Is(err, data.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	content := make([]byte, stat.Size)
+	if err := readFull(ctx, cb.inner, "", refKey(hash), content); err != nil {
+		return 0, err
+	}
+
+	refs, err := strconv.ParseInt(string(content), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid references of block %s: %w", hash, err)
+	}
+
+	return refs, nil
+}
+
+// writeRefs persists the number of references to the block with the specified hash.
+func (cb *CASBackend) writeRefs(ctx context.Context, hash string, refs int64) error {
+	return cb.writeObject(ctx, refKey(hash), []byte(strconv.FormatInt(refs, 10)))
+}
+
+// writeObject creates or replaces the object at key within the reserved storage.
+func (cb *CASBackend) writeObject(ctx context.Context, key string, content []byte) error {
+	if _, err := cb.inner.CreateObject(ctx, "", key, 0644); err != nil && !errors.Is(err, data.ErrExist) {
+		return err
+	}
+	if err := cb.inner.TruncateObject(ctx, "", key, 0); err != nil {
+		return err
+	}
+
+	_, err := cb.inner.WriteObject(ctx, "", key, 0, content)
+	return err
+}
+
+// blockKey returns the key of the block with the specified hash.
+func blockKey(hash string) string {
+	return blocksKey + "/" + hash
+}
+
+// refKey returns the key storing the number of references to the block with the specified hash.
+func refKey(hash string) string {
+	return refsKey + "/" + hash
+}
+
+// readFull reads exactly len(buf) bytes from the start of key.
+func readFull(ctx context.Context, storage backend.ObjectStorageBackend, namespace, key string, buf []byte) error {
+	for offset := 0; offset < len(buf); {
+		n, err := storage.ReadObject(ctx, namespace, key, int64(offset), buf[offset:])
+		offset += n
+		if err != nil && err != io.EOF {
+			return err
+		}
+		if n == 0 && offset < len(buf) {
+			return io.ErrUnexpectedEOF
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,52 @@
+package cas
+
+import (
+	"context"
+
+	"github.com/mwantia/vfs/data"
+)
+
+// CopyObject creates dstKey as copy of the file at srcKey.
+// Only references to the blocks of srcKey are added, so no content is copied at all.
+func (cb *CASBackend) CopyObject(ctx context.Context, srcNamespace, srcKey, dstNamespace, dstKey string) (*data.FileStat, error) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if isReserved(srcKey) || isReserved(dstKey) {
+		return nil, data.ErrPermission
+	}
+
+	stat, err := cb.inner.HeadObject(ctx, srcNamespace, srcKey)
+	if err != nil {
+		return nil, err
+	}
+	m, err := cb.readManifest(ctx, srcNamespace, srcKey)
+	if err != nil {
+		return nil, err
+	}
+
+	clone, err := cb.inner.CreateObject(ctx, dstNamespace, dstKey, stat.Mode)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, hash := range m.Blocks {
+		if err := cb.referenceBlock(ctx, hash); err != nil {
+			// Release all references added so far and remove the empty copy
+			for _, added := range m.Blocks[:i] {
+				cb.releaseBlock(ctx, added)
+			}
+			cb.inner.DeleteObject(ctx, dstNamespace, dstKey, false)
+			return nil, err
+		}
+	}
+	if err := cb.writeManifest(ctx, dstNamespace, dstKey, m); err != nil {
+		return nil, err
+	}
+
+	if clone == nil {
+		clone = &data.FileStat{Key: dstKey, Mode: stat.Mode}
+	}
+	clone.Size = m.Size
+	return clone, nil
+}
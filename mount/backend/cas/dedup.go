@@ -0,0 +1,40 @@
+package cas
+
+import (
+	"context"
+	"path"
+
+	"github.com/mwantia/vfs/data"
+)
+
+// DedupStats returns the number of bytes referenced by all objects compared to the bytes actually stored.
+func (cb *CASBackend) DedupStats(ctx context.Context) (*data.DedupStats, error) {
+	cb.mu.RLock()
+	defer cb.mu.RUnlock()
+
+	refs, err := cb.inner.ListObjects(ctx, "", refsKey)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &data.DedupStats{}
+	for _, ref := range refs {
+		hash := path.Base(ref.Key)
+
+		count, err := cb.readRefs(ctx, hash)
+		if err != nil {
+			return nil, err
+		}
+		block, err := cb.inner.HeadObject(ctx, "", blockKey(hash))
+		if err != nil {
+			return nil, err
+		}
+
+		stats.Blocks++
+		stats.References += count
+		stats.PhysicalBytes += block.Size
+		stats.LogicalBytes += count * block.Size
+	}
+
+	return stats, nil
+}
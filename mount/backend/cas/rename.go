@@ -0,0 +1,25 @@
+package cas
+
+import (
+	"context"
+
+	"github.com/mwantia/vfs/data"
+	"github.com/mwantia/vfs/mount/backend"
+)
+
+// RenameObject moves the object at oldKey to newKey, if the inner backend supports native renames.
+// Manifests are moved along with their objects, so no block is touched.
+func (cb *CASBackend) RenameObject(ctx context.Context, namespace, oldKey, newKey string) error {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	renamer, ok := cb.inner.(backend.RenameObjectBackend)
+	if !ok {
+		return data.ErrNotSupported
+	}
+	if isReserved(oldKey) || isReserved(newKey) {
+		return data.ErrPermission
+	}
+
+	return renamer.RenameObject(ctx, namespace, oldKey, newKey)
+}
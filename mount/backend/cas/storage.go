@@ -0,0 +1,308 @@
+package cas
+
+import (
+	"context"
+	"io"
+	"strings"
+
+	"github.com/mwantia/vfs/data"
+)
+
+func (cb *CASBackend) CreateObject(ctx context.Context, namespace, key string, mode data.FileMode) (*data.FileStat, error) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if isReserved(key) {
+		return nil, data.ErrPermission
+	}
+
+	return cb.inner.CreateObject(ctx, namespace, key, mode)
+}
+
+func (cb *CASBackend) ReadObject(ctx context.Context, namespace, key string, offset int64, dat []byte) (int, error) {
+	cb.mu.RLock()
+	defer cb.mu.RUnlock()
+
+	if isReserved(key) {
+		return 0, data.ErrNotExist
+	}
+
+	m, err := cb.readManifest(ctx, namespace, key)
+	if err != nil {
+		return 0, err
+	}
+	if offset >= m.Size {
+		return 0, io.EOF
+	}
+
+	end := min(offset+int64(len(dat)), m.Size)
+	n := int(end - offset)
+	clear(dat[:n])
+
+	blockSize := int64(cb.blockSize)
+	for index := offset / blockSize; index*blockSize < end; index++ {
+		hash := m.blockAt(index)
+		if hash == "" {
+			continue
+		}
+
+		content, err := cb.readBlock(ctx, hash)
+		if err != nil {
+			return 0, err
+		}
+
+		start := index * blockSize
+		from := max(offset, start)
+		to := min(end, start+int64(len(content)))
+		if from < to {
+			copy(dat[from-offset:to-offset], content[from-start:to-start])
+		}
+	}
+
+	return n, nil
+}
+
+func (cb *CASBackend) WriteObject(ctx context.Context, namespace, key string, offset int64, dat []byte) (int, error) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if isReserved(key) {
+		return 0, data.ErrPermission
+	}
+
+	m, err := cb.readManifest(ctx, namespace, key)
+	if err != nil {
+		return 0, err
+	}
+
+	end := offset + int64(len(dat))
+	m.resize(max(end, m.Size), cb.blockSize)
+
+	// Only the blocks covered by the write are replaced
+	blockSize := int64(cb.blockSize)
+	for index := offset / blockSize; index*blockSize < end; index++ {
+		start := index * blockSize
+		from := max(offset, start) - start
+		part := dat[max(offset, start)-offset : min(end, start+blockSize)-offset]
+
+		previous := m.Blocks[index]
+		var content []byte
+		if previous != "" {
+			if content, err = cb.readBlock(ctx, previous); err != nil {
+				return 0, err
+			}
+		}
+
+		if required := from + int64(len(part)); int64(len(content)) < required {
+			grown := make([]byte, required)
+			copy(grown, content)
+			content = grown
+		}
+		copy(content[from:], part)
+
+		// The new block is retained first, so unchanged blocks are never removed in between
+		hash, err := cb.retainBlock(ctx, content)
+		if err != nil {
+			return 0, err
+		}
+		if err := cb.releaseBlock(ctx, previous); err != nil {
+			return 0, err
+		}
+		m.Blocks[index] = hash
+	}
+
+	if err := cb.writeManifest(ctx, namespace, key, m); err != nil {
+		return 0, err
+	}
+
+	return len(dat), nil
+}
+
+func (cb *CASBackend) DeleteObject(ctx context.Context, namespace, key string, force bool) error {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if key == "" || isReserved(key) {
+		return data.ErrPermission
+	}
+
+	stat, err := cb.inner.HeadObject(ctx, namespace, key)
+	if err != nil {
+		return err
+	}
+	if stat.Mode.IsDir() && !force {
+		return data.ErrIsDirectory
+	}
+
+	// Manifests are collected first, so blocks are only released once the objects are gone
+	manifests, err := cb.collectManifests(ctx, namespace, key, stat)
+	if err != nil {
+		return err
+	}
+	if err := cb.inner.DeleteObject(ctx, namespace, key, force); err != nil {
+		return err
+	}
+
+	for _, m := range manifests {
+		for _, hash := range m.Blocks {
+			if err := cb.releaseBlock(ctx, hash); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (cb *CASBackend) ListObjects(ctx context.Context, namespace, key string) ([]*data.FileStat, error) {
+	cb.mu.RLock()
+	defer cb.mu.RUnlock()
+
+	if isReserved(key) {
+		return nil, data.ErrNotExist
+	}
+
+	stats, err := cb.inner.ListObjects(ctx, namespace, key)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*data.FileStat, 0, len(stats))
+	for _, stat := range stats {
+		child := childKey(key, stat.Key)
+		if isReserved(child) {
+			continue
+		}
+
+		clone := *stat
+		if !clone.Mode.IsDir() {
+			m, err := cb.readManifest(ctx, namespace, child)
+			if err != nil {
+				return nil, err
+			}
+			clone.Size = m.Size
+		}
+		result = append(result, &clone)
+	}
+
+	return result, nil
+}
+
+func (cb *CASBackend) HeadObject(ctx context.Context, namespace, key string) (*data.FileStat, error) {
+	cb.mu.RLock()
+	defer cb.mu.RUnlock()
+
+	if isReserved(key) {
+		return nil, data.ErrNotExist
+	}
+
+	stat, err := cb.inner.HeadObject(ctx, namespace, key)
+	if err != nil {
+		return nil, err
+	}
+	if stat.Mode.IsDir() {
+		return stat, nil
+	}
+
+	m, err := cb.readManifest(ctx, namespace, key)
+	if err != nil {
+		return nil, err
+	}
+
+	clone := *stat
+	clone.Size = m.Size
+	return &clone, nil
+}
+
+func (cb *CASBackend) TruncateObject(ctx context.Context, namespace, key string, size int64) error {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if isReserved(key) {
+		return data.ErrPermission
+	}
+
+	m, err := cb.readManifest(ctx, namespace, key)
+	if err != nil {
+		return err
+	}
+	if size == m.Size {
+		return nil
+	}
+
+	blockSize := int64(cb.blockSize)
+	if size < m.Size {
+		// Trailing content of the last block is dropped, so growing again reads zeros
+		last := size / blockSize
+		if keep := size - last*blockSize; keep > 0 && m.blockAt(last) != "" {
+			previous := m.Blocks[last]
+			content, err := cb.readBlock(ctx, previous)
+			if err != nil {
+				return err
+			}
+
+			if int64(len(content)) > keep {
+				hash, err := cb.retainBlock(ctx, content[:keep])
+				if err != nil {
+					return err
+				}
+				if err := cb.releaseBlock(ctx, previous); err != nil {
+					return err
+				}
+				m.Blocks[last] = hash
+			}
+		}
+
+		first := (size + blockSize - 1) / blockSize
+		for index := first; index < int64(len(m.Blocks)); index++ {
+			if err := cb.releaseBlock(ctx, m.Blocks[index]); err != nil {
+				return err
+			}
+		}
+	}
+
+	m.resize(size, cb.blockSize)
+	return cb.writeManifest(ctx, namespace, key, m)
+}
+
+// collectManifests returns the manifests of key and, for directories, of all nested files.
+func (cb *CASBackend) collectManifests(ctx context.Context, namespace, key string, stat *data.FileStat) ([]*manifest, error) {
+	if !stat.Mode.IsDir() {
+		m, err := cb.readManifest(ctx, namespace, key)
+		if err != nil {
+			return nil, err
+		}
+		return []*manifest{m}, nil
+	}
+
+	children, err := cb.inner.ListObjects(ctx, namespace, key)
+	if err != nil {
+		return nil, err
+	}
+
+	var manifests []*manifest
+	for _, child := range children {
+		nested, err := cb.collectManifests(ctx, namespace, childKey(key, child.Key), child)
+		if err != nil {
+			return nil, err
+		}
+		manifests = append(manifests, nested...)
+	}
+
+	return manifests, nil
+}
+
+// childKey returns the full key of an entry listed below key.
+// Backends return either the name or the full key of each entry.
+func childKey(key, listed string) string {
+	if listed == key {
+		return key
+	}
+
+	prefix := ""
+	if key != "" {
+		prefix = key + "/"
+	}
+
+	return prefix + strings.TrimPrefix(strings.TrimPrefix(listed, prefix), "/")
+}
@@ -0,0 +1,16 @@
+package backend
+
+import (
+	"context"
+
+	"github.com/mwantia/vfs/data"
+)
+
+// CompressBackend is an optional capability for object storage backends, which store content compressed.
+type CompressBackend interface {
+	// ContentEncoding returns the algorithm used to store the content of key (data.CompressionNone if stored as is).
+	ContentEncoding(ctx context.Context, namespace, key string) (data.CompressionAlgorithm, error)
+
+	// CompressionStats returns the size of all objects within namespace compared to the bytes actually stored.
+	CompressionStats(ctx context.Context, namespace string) (*data.CompressionStats, error)
+}
@@ -0,0 +1,189 @@
+package compress
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/klauspost/compress/gzip"
+	"github.com/klauspost/compress/zstd"
+	"github.com/mwantia/vfs/data"
+	"github.com/mwantia/vfs/mount/backend"
+)
+
+// DefaultFrameSize is the amount of content compressed into a single frame, if no frame size is defined.
+const DefaultFrameSize = 64 * 1024
+
+// CompressBackend wraps any object storage backend with transparent compression:
+//
+// Content is split into frames of a fixed size, which are compressed independently of each other,
+// so reads at random offsets only decompress the frames they cover. Each object stores its frames
+// followed by an index of their compressed lengths and a trailer describing the object. Empty objects,
+// objects of already compressed content types and objects written without compression are stored as is.
+type CompressBackend struct {
+	mu     sync.RWMutex
+	inner  backend.ObjectStorageBackend
+	config *CompressBackendConfig
+
+	encoder *zstd.Encoder
+	decoder *zstd.Decoder
+}
+
+// CompressBackendConfig contains configuration options for the compression backend
+type CompressBackendConfig struct {
+	// Algorithm used to compress new objects (default: zstd)
+	// Existing objects are always read with the algorithm they have been written with
+	Algorithm data.CompressionAlgorithm
+
+	// Compression level passed to the algorithm (default: default level of the algorithm)
+	Level int
+
+	// Amount of content compressed into a single frame (default: DefaultFrameSize)
+	FrameSize int
+}
+
+// NewCompressBackend creates a backend compressing all content stored within inner.
+// The inner backend is owned by the returned backend and opened and closed together with it.
+func NewCompressBackend(inner backend.ObjectStorageBackend, config *CompressBackendConfig) (*CompressBackend, error) {
+	if inner == nil {
+		return nil, fmt.Errorf("inner backend must not be nil")
+	}
+	if config == nil {
+		config = &CompressBackendConfig{}
+	}
+
+	// Set defaults
+	if config.Algorithm == "" {
+		config.Algorithm = data.CompressionZstd
+	}
+	if !config.Algorithm.IsValid() {
+		return nil, fmt.Errorf("%w: unknown compression algorithm '%s'", data.ErrInvalid, config.Algorithm)
+	}
+	if config.FrameSize <= 0 {
+		config.FrameSize = DefaultFrameSize
+	}
+	if config.FrameSize > maxFrameLength {
+		return nil, fmt.Errorf("%w: frame size %d exceeds %d", data.ErrInvalid, config.FrameSize, maxFrameLength)
+	}
+
+	level := zstd.SpeedDefault
+	if config.Algorithm == data.CompressionZstd && config.Level != 0 {
+		level = zstd.EncoderLevelFromZstd(config.Level)
+	}
+	if config.Algorithm == data.CompressionGzip && config.Level != 0 {
+		if config.Level < gzip.StatelessCompression || config.Level > gzip.BestCompression {
+			return nil, fmt.Errorf("%w: invalid gzip level %d", data.ErrInvalid, config.Level)
+		}
+	}
+
+	encoder, err := zstd.NewWriter(nil, zstd.WithEncoderLevel(level))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create zstd encoder: %w", err)
+	}
+	decoder, err := zstd.NewReader(nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create zstd decoder: %w", err)
+	}
+
+	return &CompressBackend{
+		inner:   inner,
+		config:  config,
+		encoder: encoder,
+		decoder: decoder,
+	}, nil
+}
+
+// Returns the identifier name defined for this backend
+func (*CompressBackend) Name() string {
+	return "compress"
+}
+
+// Open is part of the lifecycle behavious and gets called when opening this backend.
+func (cb *CompressBackend) Open(ctx context.Context) error {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	return cb.inner.Open(ctx)
+}
+
+// Close is part of the lifecycle behaviour and gets called when closing this backend.
+func (cb *CompressBackend) Close(ctx context.Context) error {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	return cb.inner.Close(ctx)
+}
+
+// GetCapabilities returns a list of capabilities supported by this backend.
+func (cb *CompressBackend) GetCapabilities() *backend.BackendCapabilities {
+	return &backend.BackendCapabilities{
+		Capabilities: []backend.BackendCapability{
+			backend.CapabilityObjectStorage,
+		},
+		// Compressed objects may still require their full size within the inner backend
+		MinObjectSize: cb.inner.GetCapabilities().MinObjectSize,
+		MaxObjectSize: cb.inner.GetCapabilities().MaxObjectSize,
+	}
+}
+
+// compress returns the compressed content of a single frame.
+func (cb *CompressBackend) compress(algorithm data.CompressionAlgorithm, content []byte) ([]byte, error) {
+	switch algorithm {
+	case data.CompressionZstd:
+		return cb.encoder.EncodeAll(content, nil), nil
+	case data.CompressionGzip:
+		level := gzip.DefaultCompression
+		if cb.config.Level != 0 {
+			level = cb.config.Level
+		}
+
+		var buffer bytes.Buffer
+		writer, err := gzip.NewWriterLevel(&buffer, level)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := writer.Write(content); err != nil {
+			return nil, err
+		}
+		if err := writer.Close(); err != nil {
+			return nil, err
+		}
+		return buffer.Bytes(), nil
+	}
+
+	return nil, fmt.Errorf("%w: unknown compression algorithm '%s'", data.ErrInvalid, algorithm)
+}
+
+// decompress decodes a single frame into dst, which has the length of the decompressed frame.
+func (cb *CompressBackend) decompress(algorithm data.CompressionAlgorithm, frame []byte, dst []byte) error {
+	var content []byte
+	switch algorithm {
+	case data.CompressionZstd:
+		decoded, err := cb.decoder.DecodeAll(frame, dst[:0])
+		if err != nil {
+			return err
+		}
+		content = decoded
+	case data.CompressionGzip:
+		reader, err := gzip.NewReader(bytes.NewReader(frame))
+		if err != nil {
+			return err
+		}
+		n, err := io.ReadFull(reader, dst)
+		if err != nil {
+			return err
+		}
+		content = dst[:n]
+	default:
+		return fmt.Errorf("%w: unknown compression algorithm '%s'", data.ErrInvalid, algorithm)
+	}
+
+	if len(content) != len(dst) {
+		return fmt.Errorf("vfs: corrupted frame with %d of %d bytes", len(content), len(dst))
+	}
+
+	copy(dst, content)
+	return nil
+}
@@ -0,0 +1,185 @@
+package compress
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/mwantia/vfs/data"
+)
+
+const (
+	// trailerMagic identifies objects stored in compressed frames.
+	trailerMagic   = "VFSZ"
+	trailerVersion = 1
+	trailerSize    = 32
+
+	// Each index entry stores the compressed length of a frame, where the highest bit marks frames stored as is.
+	indexEntrySize = 4
+	rawFrameFlag   = 1 << 31
+	maxFrameLength = rawFrameFlag - 1
+)
+
+// algorithmIDs maps compression algorithms to their identifier stored within the trailer.
+var algorithmIDs = map[data.CompressionAlgorithm]byte{
+	data.CompressionGzip: 1,
+	data.CompressionZstd: 2,
+}
+
+// object describes the layout of an object within the inner backend.
+//
+// Compressed objects are stored as frames, followed by the index and the trailer:
+//
+//	[frame 0] ... [frame n-1] [index: n x uint32] [trailer: 32 bytes]
+//
+// The trailer consists of the magic, version, algorithm, frame size, number of frames,
+// the uncompressed size and the offset of the index, all encoded as big endian.
+type object struct {
+	stat      *data.FileStat
+	framed    bool                      // Whether content is stored in compressed frames
+	algorithm data.CompressionAlgorithm // Algorithm used for all frames
+	frameSize int64
+	size      int64    // Uncompressed size of the content
+	entries   []uint32 // Index entries of all frames
+	offsets   []int64  // Offsets of all frames followed by the offset of the index
+}
+
+// load reads the layout of the object at key. Objects without valid trailer are treated as stored as is.
+func (cb *CompressBackend) load(ctx context.Context, namespace, key string) (*object, error) {
+	stat, err := cb.inner.HeadObject(ctx, namespace, key)
+	if err != nil {
+		return nil, err
+	}
+
+	obj := &object{
+		stat: stat,
+		size: stat.Size,
+	}
+	if stat.Mode.IsDir() || stat.Size < trailerSize {
+		return obj, nil
+	}
+
+	trailer := make([]byte, trailerSize)
+	if err := cb.readFull(ctx, namespace, key, stat.Size-trailerSize, trailer); err != nil {
+		return nil, err
+	}
+	if string(trailer[0:4]) != trailerMagic || trailer[4] != trailerVersion {
+		return obj, nil
+	}
+
+	var algorithm data.CompressionAlgorithm
+	for candidate, id := range algorithmIDs {
+		if id == trailer[5] {
+			algorithm = candidate
+		}
+	}
+	frameSize := int64(binary.BigEndian.Uint32(trailer[8:12]))
+	frames := int64(binary.BigEndian.Uint32(trailer[12:16]))
+	size := int64(binary.BigEndian.Uint64(trailer[16:24]))
+	indexOffset := int64(binary.BigEndian.Uint64(trailer[24:32]))
+
+	// Content that only happens to end with the magic is still stored as is
+	if algorithm == "" || frameSize <= 0 || frames != (size+frameSize-1)/frameSize ||
+		indexOffset < 0 || indexOffset+frames*indexEntrySize+trailerSize != stat.Size {
+		return obj, nil
+	}
+
+	index := make([]byte, frames*indexEntrySize)
+	if err := cb.readFull(ctx, namespace, key, indexOffset, index); err != nil {
+		return nil, err
+	}
+
+	entries := make([]uint32, frames)
+	offsets := make([]int64, frames+1)
+	for i := range entries {
+		entries[i] = binary.BigEndian.Uint32(index[i*indexEntrySize:])
+		offsets[i+1] = offsets[i] + int64(entries[i]&maxFrameLength)
+	}
+	if offsets[frames] != indexOffset {
+		return obj, nil
+	}
+
+	obj.framed = true
+	obj.algorithm = algorithm
+	obj.frameSize = frameSize
+	obj.size = size
+	obj.entries = entries
+	obj.offsets = offsets
+	return obj, nil
+}
+
+// frameLength returns the uncompressed length of frame i for content of size bytes.
+func frameLength(i, frameSize, size int64) int64 {
+	return max(min(frameSize, size-i*frameSize), 0)
+}
+
+// encodeIndex returns the index and trailer of an object with the specified frames.
+func encodeIndex(algorithm data.CompressionAlgorithm, frameSize, size int64, entries []uint32, indexOffset int64) []byte {
+	buffer := make([]byte, len(entries)*indexEntrySize+trailerSize)
+	for i, entry := range entries {
+		binary.BigEndian.PutUint32(buffer[i*indexEntrySize:], entry)
+	}
+
+	trailer := buffer[len(entries)*indexEntrySize:]
+	copy(trailer[0:4], trailerMagic)
+	trailer[4] = trailerVersion
+	trailer[5] = algorithmIDs[algorithm]
+	binary.BigEndian.PutUint32(trailer[8:12], uint32(frameSize))
+	binary.BigEndian.PutUint32(trailer[12:16], uint32(len(entries)))
+	binary.BigEndian.PutUint64(trailer[16:24], uint64(size))
+	binary.BigEndian.PutUint64(trailer[24:32], uint64(indexOffset))
+
+	return buffer
+}
+
+// readFrame returns the uncompressed content of frame i.
+func (cb *CompressBackend) readFrame(ctx context.Context, namespace, key string, obj *object, i int64) ([]byte, error) {
+	entry := obj.entries[i]
+	stored := make([]byte, entry&maxFrameLength)
+	if err := cb.readFull(ctx, namespace, key, obj.offsets[i], stored); err != nil {
+		return nil, err
+	}
+	if entry&rawFrameFlag != 0 {
+		return stored, nil
+	}
+
+	content := make([]byte, frameLength(i, obj.frameSize, obj.size))
+	if err := cb.decompress(obj.algorithm, stored, content); err != nil {
+		return nil, fmt.Errorf("failed to decompress frame %d of '%s': %w", i, key, err)
+	}
+
+	return content, nil
+}
+
+// encodeFrame compresses content into a frame, which is stored as is if compression doesn't reduce its size.
+func (cb *CompressBackend) encodeFrame(algorithm data.CompressionAlgorithm, content []byte) ([]byte, uint32, error) {
+	compressed, err := cb.compress(algorithm, content)
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(compressed) >= len(content) {
+		return content, uint32(len(content)) | rawFrameFlag, nil
+	}
+
+	return compressed, uint32(len(compressed)), nil
+}
+
+// readFull reads exactly len(dat) bytes at offset from the inner backend.
+func (cb *CompressBackend) readFull(ctx context.Context, namespace, key string, offset int64, dat []byte) error {
+	for read := 0; read < len(dat); {
+		n, err := cb.inner.ReadObject(ctx, namespace, key, offset+int64(read), dat[read:])
+		read += n
+		if read == len(dat) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			return fmt.Errorf("failed to read '%s': %w", key, io.ErrUnexpectedEOF)
+		}
+	}
+
+	return nil
+}
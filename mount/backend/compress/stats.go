@@ -0,0 +1,87 @@
+package compress
+
+import (
+	"context"
+	"strings"
+
+	"github.com/mwantia/vfs/data"
+)
+
+// ContentEncoding returns the algorithm used to store the content of key (data.CompressionNone if stored as is).
+func (cb *CompressBackend) ContentEncoding(ctx context.Context, namespace, key string) (data.CompressionAlgorithm, error) {
+	cb.mu.RLock()
+	defer cb.mu.RUnlock()
+
+	obj, err := cb.load(ctx, namespace, key)
+	if err != nil {
+		return "", err
+	}
+	if !obj.framed {
+		return data.CompressionNone, nil
+	}
+
+	return obj.algorithm, nil
+}
+
+// CompressionStats returns the size of all objects within namespace compared to the bytes actually stored.
+func (cb *CompressBackend) CompressionStats(ctx context.Context, namespace string) (*data.CompressionStats, error) {
+	cb.mu.RLock()
+	defer cb.mu.RUnlock()
+
+	stats := &data.CompressionStats{}
+	if err := cb.collectStats(ctx, namespace, "", stats); err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+// collectStats adds the sizes of all files nested below the directory key to stats.
+func (cb *CompressBackend) collectStats(ctx context.Context, namespace, key string, stats *data.CompressionStats) error {
+	children, err := cb.inner.ListObjects(ctx, namespace, key)
+	if err != nil {
+		if err == data.ErrNotExist && key == "" {
+			return nil
+		}
+		return err
+	}
+
+	prefix := ""
+	if key != "" {
+		prefix = key + "/"
+	}
+
+	for _, child := range children {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		// Backends return either the name or the full key of each entry
+		name := strings.TrimSuffix(strings.TrimPrefix(strings.TrimPrefix(child.Key, prefix), "/"), "/")
+		if name == "" || child.Key == key {
+			continue
+		}
+		childKey := prefix + name
+
+		if child.Mode.IsDir() {
+			if err := cb.collectStats(ctx, namespace, childKey, stats); err != nil {
+				return err
+			}
+			continue
+		}
+
+		obj, err := cb.load(ctx, namespace, childKey)
+		if err != nil {
+			return err
+		}
+
+		stats.Objects++
+		stats.LogicalBytes += obj.size
+		stats.PhysicalBytes += obj.stat.Size
+		if obj.framed {
+			stats.Compressed++
+		}
+	}
+
+	return nil
+}
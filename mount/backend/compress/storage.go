@@ -0,0 +1,221 @@
+package compress
+
+import (
+	"context"
+	"io"
+	"strings"
+
+	"github.com/mwantia/vfs/data"
+)
+
+func (cb *CompressBackend) CreateObject(ctx context.Context, namespace, key string, mode data.FileMode) (*data.FileStat, error) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	return cb.inner.CreateObject(ctx, namespace, key, mode)
+}
+
+func (cb *CompressBackend) ReadObject(ctx context.Context, namespace, key string, offset int64, dat []byte) (int, error) {
+	cb.mu.RLock()
+	defer cb.mu.RUnlock()
+
+	obj, err := cb.load(ctx, namespace, key)
+	if err != nil {
+		return 0, err
+	}
+	if !obj.framed {
+		return cb.inner.ReadObject(ctx, namespace, key, offset, dat)
+	}
+	if offset >= obj.size {
+		return 0, io.EOF
+	}
+
+	end := min(offset+int64(len(dat)), obj.size)
+	for i := offset / obj.frameSize; i*obj.frameSize < end; i++ {
+		content, err := cb.readFrame(ctx, namespace, key, obj, i)
+		if err != nil {
+			return 0, err
+		}
+
+		start := i * obj.frameSize
+		from := max(offset, start)
+		to := min(end, start+int64(len(content)))
+		copy(dat[from-offset:to-offset], content[from-start:to-start])
+	}
+
+	return int(end - offset), nil
+}
+
+func (cb *CompressBackend) WriteObject(ctx context.Context, namespace, key string, offset int64, dat []byte) (int, error) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	obj, err := cb.load(ctx, namespace, key)
+	if err != nil {
+		return 0, err
+	}
+	if obj.stat.Mode.IsDir() || len(dat) == 0 {
+		return cb.inner.WriteObject(ctx, namespace, key, offset, dat)
+	}
+
+	if !obj.framed {
+		// Objects keep the layout chosen by their first write
+		if obj.stat.Size > 0 || !cb.compressible(key, offset, dat) {
+			return cb.inner.WriteObject(ctx, namespace, key, offset, dat)
+		}
+
+		obj.framed = true
+		obj.algorithm = cb.config.Algorithm
+		obj.frameSize = int64(cb.config.FrameSize)
+		obj.offsets = []int64{0}
+	}
+
+	end := offset + int64(len(dat))
+	if err := cb.rewrite(ctx, namespace, key, obj, min(offset, obj.size), end, max(obj.size, end), offset, dat, true); err != nil {
+		return 0, err
+	}
+
+	return len(dat), nil
+}
+
+func (cb *CompressBackend) DeleteObject(ctx context.Context, namespace, key string, force bool) error {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	return cb.inner.DeleteObject(ctx, namespace, key, force)
+}
+
+func (cb *CompressBackend) ListObjects(ctx context.Context, namespace, key string) ([]*data.FileStat, error) {
+	cb.mu.RLock()
+	defer cb.mu.RUnlock()
+
+	stats, err := cb.inner.ListObjects(ctx, namespace, key)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := ""
+	if key != "" {
+		prefix = key + "/"
+	}
+
+	result := make([]*data.FileStat, 0, len(stats))
+	for _, stat := range stats {
+		if stat.Mode.IsDir() {
+			result = append(result, stat)
+			continue
+		}
+
+		// Backends return either the name or the full key of each entry
+		child := key
+		if stat.Key != key {
+			child = prefix + strings.TrimPrefix(strings.TrimPrefix(stat.Key, prefix), "/")
+		}
+		obj, err := cb.load(ctx, namespace, child)
+		if err != nil {
+			return nil, err
+		}
+
+		logical := *stat
+		logical.Size = obj.size
+		result = append(result, &logical)
+	}
+
+	return result, nil
+}
+
+func (cb *CompressBackend) HeadObject(ctx context.Context, namespace, key string) (*data.FileStat, error) {
+	cb.mu.RLock()
+	defer cb.mu.RUnlock()
+
+	obj, err := cb.load(ctx, namespace, key)
+	if err != nil {
+		return nil, err
+	}
+
+	stat := *obj.stat
+	stat.Size = obj.size
+	return &stat, nil
+}
+
+func (cb *CompressBackend) TruncateObject(ctx context.Context, namespace, key string, size int64) error {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	obj, err := cb.load(ctx, namespace, key)
+	if err != nil {
+		return err
+	}
+	// Empty objects are stored as is, so their next write decides about compression again
+	if !obj.framed || size <= 0 {
+		return cb.inner.TruncateObject(ctx, namespace, key, size)
+	}
+
+	return cb.rewrite(ctx, namespace, key, obj, min(size, obj.size), size, size, 0, nil, false)
+}
+
+// compressible returns true, if content written to the empty object at key should be compressed.
+func (cb *CompressBackend) compressible(key string, offset int64, dat []byte) bool {
+	if cb.config.Algorithm == data.CompressionNone {
+		return false
+	}
+
+	var head []byte
+	if offset == 0 {
+		head = dat[:min(len(dat), 512)]
+	}
+	return !data.DetectContentType(key, head).IsCompressed()
+}
+
+// rewrite replaces all frames covering the range from start to end with their content overlaid by dat at offset
+// and stores the object with size bytes. Frames after the range are moved behind the replaced frames if keepTail is set,
+// otherwise they are dropped. Frames before the range remain untouched.
+func (cb *CompressBackend) rewrite(ctx context.Context, namespace, key string, obj *object, start, end, size, offset int64, dat []byte, keepTail bool) error {
+	frameSize := obj.frameSize
+	frames := int64(len(obj.entries))
+	first, last := start/frameSize, (end+frameSize-1)/frameSize
+
+	entries := append([]uint32(nil), obj.entries[:first]...)
+	var buffer []byte
+	for i := first; i < last; i++ {
+		content := make([]byte, frameLength(i, frameSize, size))
+		if i < frames {
+			previous, err := cb.readFrame(ctx, namespace, key, obj, i)
+			if err != nil {
+				return err
+			}
+			copy(content, previous)
+		}
+
+		frameStart := i * frameSize
+		from := max(offset, frameStart)
+		to := min(offset+int64(len(dat)), frameStart+int64(len(content)))
+		if from < to {
+			copy(content[from-frameStart:], dat[from-offset:to-offset])
+		}
+
+		frame, entry, err := cb.encodeFrame(obj.algorithm, content)
+		if err != nil {
+			return err
+		}
+		buffer = append(buffer, frame...)
+		entries = append(entries, entry)
+	}
+
+	if keepTail && last < frames {
+		tail := make([]byte, obj.offsets[frames]-obj.offsets[last])
+		if err := cb.readFull(ctx, namespace, key, obj.offsets[last], tail); err != nil {
+			return err
+		}
+		buffer = append(buffer, tail...)
+		entries = append(entries, obj.entries[last:]...)
+	}
+
+	physical := obj.offsets[first]
+	buffer = append(buffer, encodeIndex(obj.algorithm, frameSize, size, entries, physical+int64(len(buffer)))...)
+	if _, err := cb.inner.WriteObject(ctx, namespace, key, physical, buffer); err != nil {
+		return err
+	}
+
+	return cb.inner.TruncateObject(ctx, namespace, key, physical+int64(len(buffer)))
+}
@@ -45,6 +45,10 @@ type ConsulBackendConfig struct {
 	// Prefix for all keys in Consul KV (default: "/")
 	// This allows mounting the backend at a specific path
 	Prefix string
+
+	// Prefix for all lock entries in Consul KV (default: "vfs-locks")
+	// Lock entries are never listed as objects, even if located below Prefix
+	LockPrefix string
 }
 
 // NewConsulBackend creates a new Consul-backed object storage backend
@@ -62,6 +66,10 @@ func NewConsulBackend(config *ConsulBackendConfig) (*ConsulBackend, error) {
 		config.Prefix = "/"
 	}
 
+	if config.LockPrefix == "" {
+		config.LockPrefix = "vfs-locks"
+	}
+
 	// Create Consul client
 	clientConfig := api.DefaultConfig()
 	clientConfig.Address = config.Address
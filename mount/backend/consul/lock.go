@@ -0,0 +1,204 @@
+package consul
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/mwantia/vfs/data"
+	"github.com/mwantia/vfs/mount/backend"
+)
+
+const (
+	// lockSessionMinTTL is the minimum TTL accepted by Consul for sessions
+	lockSessionMinTTL = 10 * time.Second
+	// lockMaxAttempts is the number of check-and-set attempts before giving up on a contended entry
+	lockMaxAttempts = 8
+)
+
+// consulLock is a single lock stored within the lock entry of a key.
+// Each lock is bound to its own Consul session, so locks of crashed processes expire with their session.
+type consulLock struct {
+	*data.LockInfo
+	Session string `json:"session"`
+}
+
+// AcquireLock tries to acquire lock once, without waiting for conflicting locks to be released.
+// A Consul session with the lease as TTL is created for the lock, while all locks of a key
+// are stored within a single entry, which is only modified using check-and-set.
+func (cb *ConsulBackend) AcquireLock(ctx context.Context, namespace string, lock *data.LockInfo) error {
+	session, _, err := cb.client.Session().CreateNoChecks(&api.SessionEntry{
+		Name: "vfs-lock-" + lock.ID,
+		TTL:  max(time.Duration(lock.Lease), lockSessionMinTTL).String(),
+	}, (&api.WriteOptions{}).WithContext(ctx))
+	if err != nil {
+		return err
+	}
+
+	err = cb.modifyLocks(ctx, namespace, lock.Key, func(locks []*consulLock) ([]*consulLock, error) {
+		for _, held := range locks {
+			if held.Conflicts(lock) {
+				return nil, data.ErrLocked
+			}
+		}
+		return append(locks, &consulLock{LockInfo: lock.Clone(), Session: session}), nil
+	})
+	if err != nil {
+		cb.client.Session().Destroy(session, nil)
+		return err
+	}
+
+	return nil
+}
+
+// RefreshLock renews the session of lock and extends its expiry.
+func (cb *ConsulBackend) RefreshLock(ctx context.Context, namespace string, lock *data.LockInfo) error {
+	return cb.modifyLocks(ctx, namespace, lock.Key, func(locks []*consulLock) ([]*consulLock, error) {
+		index := slices.IndexFunc(locks, func(held *consulLock) bool {
+			return held.ID == lock.ID
+		})
+		if index < 0 {
+			return nil, data.ErrLockNotHeld
+		}
+
+		entry, _, err := cb.client.Session().Renew(locks[index].Session, (&api.WriteOptions{}).WithContext(ctx))
+		if err != nil {
+			return nil, err
+		}
+		if entry == nil {
+			return nil, data.ErrLockNotHeld
+		}
+
+		locks[index].Renew(time.Now())
+		lock.Expires = locks[index].Expires
+		return locks, nil
+	})
+}
+
+// ReleaseLock removes lock from the entry of its key and destroys its session.
+func (cb *ConsulBackend) ReleaseLock(ctx context.Context, namespace string, lock *data.LockInfo) error {
+	var session string
+	err := cb.modifyLocks(ctx, namespace, lock.Key, func(locks []*consulLock) ([]*consulLock, error) {
+		return slices.DeleteFunc(locks, func(held *consulLock) bool {
+			if held.ID == lock.ID {
+				session = held.Session
+				return true
+			}
+			return false
+		}), nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if session != "" {
+		if _, err := cb.client.Session().Destroy(session, (&api.WriteOptions{}).WithContext(ctx)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ListLocks returns all unexpired locks held on key.
+func (cb *ConsulBackend) ListLocks(ctx context.Context, namespace string, key string) ([]*data.LockInfo, error) {
+	locks, _, err := cb.readLocks(ctx, namespace, key)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*data.LockInfo, 0, len(locks))
+	for _, lock := range locks {
+		result = append(result, lock.Clone())
+	}
+
+	return result, nil
+}
+
+// modifyLocks applies modify to the unexpired locks of key within namespace and writes the result using check-and-set.
+// The modification is retried, if the entry has been changed concurrently.
+func (cb *ConsulBackend) modifyLocks(ctx context.Context, namespace, key string, modify func(locks []*consulLock) ([]*consulLock, error)) error {
+	for range lockMaxAttempts {
+		locks, pair, err := cb.readLocks(ctx, namespace, key)
+		if err != nil {
+			return err
+		}
+
+		locks, err = modify(locks)
+		if err != nil {
+			return err
+		}
+
+		options := (&api.WriteOptions{}).WithContext(ctx)
+		if pair == nil {
+			pair = &api.KVPair{Key: cb.buildLockKey(namespace, key)}
+		}
+
+		var ok bool
+		if len(locks) == 0 {
+			if pair.ModifyIndex == 0 {
+				return nil
+			}
+			ok, _, err = cb.kv.DeleteCAS(pair, options)
+		} else {
+			if pair.Value, err = json.Marshal(locks); err != nil {
+				return err
+			}
+			ok, _, err = cb.kv.CAS(pair, options)
+		}
+		if err != nil {
+			return err
+		}
+		if ok {
+			return nil
+		}
+	}
+
+	return data.ErrBusy
+}
+
+// readLocks returns all unexpired locks of key within namespace together with the entry they were read from.
+// Locks are expired once their lease has passed or their session has been invalidated.
+func (cb *ConsulBackend) readLocks(ctx context.Context, namespace, key string) ([]*consulLock, *api.KVPair, error) {
+	pair, _, err := cb.kv.Get(cb.buildLockKey(namespace, key), (&api.QueryOptions{}).WithContext(ctx))
+	if err != nil || pair == nil {
+		return nil, nil, err
+	}
+
+	var locks []*consulLock
+	if err := json.Unmarshal(pair.Value, &locks); err != nil {
+		return nil, nil, err
+	}
+
+	now := time.Now()
+	active := make([]*consulLock, 0, len(locks))
+	for _, lock := range locks {
+		if lock.LockInfo == nil || lock.IsExpired(now) {
+			continue
+		}
+
+		entry, _, err := cb.client.Session().Info(lock.Session, (&api.QueryOptions{}).WithContext(ctx))
+		if err != nil {
+			return nil, nil, err
+		}
+		if entry != nil {
+			active = append(active, lock)
+		}
+	}
+
+	return active, pair, nil
+}
+
+// buildLockKey constructs the Consul KV key storing all locks of the object key within namespace.
+func (cb *ConsulBackend) buildLockKey(namespace, key string) string {
+	return strings.TrimSuffix(strings.Trim(cb.config.LockPrefix, "/")+"/"+backend.NamespacedKey(namespace, strings.Trim(key, "/")), "/")
+}
+
+// isLockKey returns true, if the Consul KV key belongs to a lock entry.
+func (cb *ConsulBackend) isLockKey(consulKey string) bool {
+	prefix := strings.Trim(cb.config.LockPrefix, "/")
+	return consulKey == prefix || consulKey == prefix+"/" || strings.HasPrefix(consulKey, prefix+"/")
+}
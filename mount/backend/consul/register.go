@@ -0,0 +1,44 @@
+package consul
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/mwantia/vfs/mount/backend"
+)
+
+func init() {
+	backend.Register(&backend.Registration{
+		Name:        "consul",
+		Description: "Consul key-value store",
+		Params: []backend.ParamSpec{
+			{Name: "address", Description: "address of the Consul server", Default: "127.0.0.1:8500"},
+			{Name: "token", Description: "ACL token", Secret: true},
+			{Name: "datacenter", Description: "datacenter to use"},
+			{Name: "namespace", Description: "namespace (Consul Enterprise)"},
+			{Name: "prefix", Description: "key prefix for all objects"},
+			{Name: "lock_prefix", Description: "key prefix for all locks", Default: "vfs-locks"},
+		},
+		Factory: func(params backend.FactoryParams) (backend.Backend, error) {
+			return NewConsulBackend(&ConsulBackendConfig{
+				Address:    params.String("address", ""),
+				Token:      params.String("token", ""),
+				Datacenter: params.String("datacenter", ""),
+				Namespace:  params.String("namespace", ""),
+				Prefix:     params.String("prefix", ""),
+				LockPrefix: params.String("lock_prefix", ""),
+			})
+		},
+		// consul://127.0.0.1:8500/vfs/data?token=...&datacenter=dc1
+		ParseURL: func(u *url.URL) (backend.FactoryParams, error) {
+			params := backend.QueryParams(u)
+			if u.Host != "" {
+				params["address"] = u.Host
+			}
+			if prefix := strings.Trim(u.Path, "/"); prefix != "" {
+				params["prefix"] = prefix
+			}
+			return params, nil
+		},
+	})
+}
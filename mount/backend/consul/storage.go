@@ -283,6 +283,10 @@ func (cb *ConsulBackend) ListObjects(ctx context.Context, namespace, key string)
 	result := make([]*data.FileStat, 0, len(consulKeys))
 
 	for _, consulKey := range consulKeys {
+		// Lock entries may be located below the prefix, but are never objects
+		if cb.isLockKey(consulKey) {
+			continue
+		}
 		// Check if this is a virtual directory (Consul adds trailing / for prefixes)
 		hasTrailingSlash := len(consulKey) > 0 && consulKey[len(consulKey)-1] == '/'
 
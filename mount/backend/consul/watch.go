@@ -0,0 +1,107 @@
+package consul
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/mwantia/vfs/data"
+)
+
+const (
+	// watchWaitTime is the maximum duration of a single blocking query
+	watchWaitTime = 5 * time.Minute
+	// watchRetryInterval is the delay before a failed blocking query is retried
+	watchRetryInterval = time.Second
+)
+
+// WatchObjects observes all keys below the configured prefix using Consul blocking queries.
+// Every returned index is compared against the previous state to detect created, written and deleted keys.
+// Objects aren't separated by namespace, so changes can't be observed for a single namespace.
+func (cb *ConsulBackend) WatchObjects(ctx context.Context, namespace string, notify func(event data.Event)) (<-chan struct{}, error) {
+	if namespace != "" {
+		return nil, data.ErrNotSupported
+	}
+
+	prefix := cb.buildKey("")
+	if prefix != "" && !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+
+	// The initial query only captures the current state
+	pairs, meta, err := cb.kv.List(prefix, (&api.QueryOptions{}).WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		cb.watchPrefix(ctx, prefix, meta.LastIndex, cb.toModifyIndexes(prefix, pairs), notify)
+	}()
+
+	return done, nil
+}
+
+// watchPrefix runs blocking queries until ctx is cancelled and reports all differences to notify.
+func (cb *ConsulBackend) watchPrefix(ctx context.Context, prefix string, index uint64, known map[string]uint64, notify func(event data.Event)) {
+	for ctx.Err() == nil {
+		options := &api.QueryOptions{
+			WaitIndex: index,
+			WaitTime:  watchWaitTime,
+		}
+
+		pairs, meta, err := cb.kv.List(prefix, options.WithContext(ctx))
+		if err != nil {
+			select {
+			case <-ctx.Done():
+			case <-time.After(watchRetryInterval):
+			}
+			continue
+		}
+
+		// Reset the index if it went backwards (e.g. after a snapshot restore)
+		if meta.LastIndex < index {
+			index = 0
+			continue
+		}
+		if meta.LastIndex == index {
+			continue
+		}
+		index = meta.LastIndex
+
+		current := cb.toModifyIndexes(prefix, pairs)
+		for key, modifyIndex := range current {
+			previous, exists := known[key]
+			switch {
+			case !exists:
+				notify(data.Event{Type: data.EventCreate, Path: key})
+			case previous != modifyIndex:
+				notify(data.Event{Type: data.EventWrite, Path: key})
+			}
+		}
+		for key := range known {
+			if _, exists := current[key]; !exists {
+				notify(data.Event{Type: data.EventUnlink, Path: key})
+			}
+		}
+
+		known = current
+	}
+}
+
+// toModifyIndexes maps all object keys to the index of their last modification.
+func (cb *ConsulBackend) toModifyIndexes(prefix string, pairs api.KVPairs) map[string]uint64 {
+	indexes := make(map[string]uint64, len(pairs))
+	for _, pair := range pairs {
+		// Keys with trailing slash are folder markers and not treated as objects
+		if strings.HasSuffix(pair.Key, "/") || cb.isLockKey(pair.Key) {
+			continue
+		}
+		indexes[strings.TrimPrefix(pair.Key, prefix)] = pair.ModifyIndex
+	}
+
+	return indexes
+}
@@ -0,0 +1,17 @@
+package backend
+
+import (
+	"context"
+
+	"github.com/mwantia/vfs/data"
+)
+
+// CopyObjectBackend is an optional capability for object storage backends,
+// which are able to copy a single object without streaming its content through the VFS.
+// Source and destination may be located in different namespaces of the same backend.
+type CopyObjectBackend interface {
+	// CopyObject creates dstKey as copy of the file at srcKey.
+	// Only the content is copied, any further metadata is applied by the caller.
+	// Returns data.ErrExist if dstKey already exists and data.ErrIsDirectory if srcKey is a directory.
+	CopyObject(ctx context.Context, srcNamespace, srcKey, dstNamespace, dstKey string) (*data.FileStat, error)
+}
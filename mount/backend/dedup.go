@@ -0,0 +1,14 @@
+package backend
+
+import (
+	"context"
+
+	"github.com/mwantia/vfs/data"
+)
+
+// DedupBackend is an optional capability for object storage backends,
+// which store identical content shared by multiple objects only once.
+type DedupBackend interface {
+	// DedupStats returns the number of bytes referenced by all objects compared to the bytes actually stored.
+	DedupStats(ctx context.Context) (*data.DedupStats, error)
+}
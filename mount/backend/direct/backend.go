@@ -5,7 +5,9 @@ import (
 	"errors"
 	"io/fs"
 	"os"
+	"path"
 	"path/filepath"
+	"strings"
 	"sync"
 
 	"github.com/mwantia/vfs/data"
@@ -15,6 +17,7 @@ import (
 type DirectBackend struct {
 	mu   sync.RWMutex
 	path string
+	root *os.Root // Confines all access to path, including symlinks pointing outside of it
 }
 
 func NewDirectBackend(path string) (*DirectBackend, error) {
@@ -51,13 +54,28 @@ func (db *DirectBackend) Open(ctx context.Context) error {
 		return data.ErrNotDirectory
 	}
 
+	root, err := os.OpenRoot(db.path)
+	if err != nil {
+		return toDataError(err)
+	}
+
+	db.root = root
 	return nil
 }
 
 // Close is part of the lifecycle behaviour and gets called when closing this backend.
 func (db *DirectBackend) Close(ctx context.Context) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
 	// The underlying filesystem persists independently
-	return nil
+	if db.root == nil {
+		return nil
+	}
+
+	err := db.root.Close()
+	db.root = nil
+	return err
 }
 
 // GetCapabilities returns a list of capabilities supported by this backend.
@@ -72,9 +90,23 @@ func (db *DirectBackend) GetCapabilities() *backend.BackendCapabilities {
 	}
 }
 
-// resolvePath joins the backend path with the relative path.
-func (db *DirectBackend) resolvePath(path string) string {
-	return filepath.Join(db.path, filepath.Clean(path))
+// resolveKey converts key into a name relative to the backend root.
+// Keys are cleaned and ".." segments are clamped at the root, while db.root
+// rejects any symlink that resolves outside of the root directory.
+func (db *DirectBackend) resolveKey(key string) (string, error) {
+	if db.root == nil {
+		return "", data.ErrClosed
+	}
+	if strings.IndexByte(key, 0) >= 0 {
+		return "", data.ErrInvalid
+	}
+
+	name := strings.TrimPrefix(path.Clean("/"+key), "/")
+	if name == "" {
+		return ".", nil
+	}
+
+	return filepath.FromSlash(name), nil
 }
 
 // toVirtualFileStat converts os.FileInfo to a VirtualFileStat.
@@ -0,0 +1,34 @@
+package direct
+
+import (
+	"net/url"
+
+	"github.com/mwantia/vfs/mount/backend"
+)
+
+func init() {
+	backend.Register(&backend.Registration{
+		Name:        "direct",
+		Description: "Directory on the local filesystem",
+		Schemes:     []string{"dir", "file"},
+		Params: []backend.ParamSpec{
+			{Name: "path", Description: "root directory on the local filesystem", Required: true},
+		},
+		Factory: func(params backend.FactoryParams) (backend.Backend, error) {
+			path, err := params.Required("direct", "path")
+			if err != nil {
+				return nil, err
+			}
+
+			return NewDirectBackend(path)
+		},
+		// dir:///srv/data or dir://relative/data
+		ParseURL: func(u *url.URL) (backend.FactoryParams, error) {
+			params := backend.QueryParams(u)
+			if path := backend.URLPath(u); path != "" {
+				params["path"] = path
+			}
+			return params, nil
+		},
+	})
+}
@@ -0,0 +1,62 @@
+package direct
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"strings"
+
+	"github.com/mwantia/vfs/data"
+)
+
+// RenameObject moves the file or directory at oldKey to newKey within the backend root.
+// Unlike os.Rename, an existing destination is never replaced.
+func (db *DirectBackend) RenameObject(ctx context.Context, namespace, oldKey, newKey string) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	oldName, err := db.resolveKey(oldKey)
+	if err != nil {
+		return err
+	}
+	newName, err := db.resolveKey(newKey)
+	if err != nil {
+		return err
+	}
+
+	sep := string(filepath.Separator)
+	if oldName == "." || newName == "." || strings.HasPrefix(newName, oldName+sep) {
+		return data.ErrInvalid
+	}
+
+	if _, err := db.root.Lstat(oldName); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return data.ErrNotExist
+		}
+		return err
+	}
+	if _, err := db.root.Lstat(newName); err == nil {
+		return data.ErrExist
+	}
+
+	parent, err := db.root.Stat(filepath.Dir(newName))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return data.ErrNotExist
+		}
+		return err
+	}
+	if !parent.IsDir() {
+		return data.ErrNotDirectory
+	}
+
+	if err := db.root.Rename(oldName, newName); err != nil {
+		if errors.Is(err, fs.ErrPermission) {
+			return data.ErrPermission
+		}
+		return err
+	}
+
+	return nil
+}
@@ -6,7 +6,7 @@ import (
 	"io"
 	"io/fs"
 	"os"
-	"path/filepath"
+	"path"
 	"time"
 
 	"github.com/mwantia/vfs/data"
@@ -16,19 +16,22 @@ func (db *DirectBackend) CreateObject(ctx context.Context, namespace, key string
 	db.mu.Lock()
 	defer db.mu.Unlock()
 
-	fullPath := db.resolvePath(key)
+	name, err := db.resolveKey(key)
+	if err != nil {
+		return nil, err
+	}
 
-	if _, err := os.Stat(fullPath); err == nil {
+	if _, err := db.root.Lstat(name); err == nil {
 		return nil, data.ErrExist
 	}
 
 	if mode.IsDir() {
-		return nil, os.Mkdir(fullPath, 0755)
+		return nil, toDataError(db.root.Mkdir(name, 0755))
 	}
 
-	file, err := os.Create(fullPath)
+	file, err := db.root.Create(name)
 	if err != nil {
-		return nil, err
+		return nil, toDataError(err)
 	}
 
 	now := time.Now()
@@ -47,9 +50,12 @@ func (db *DirectBackend) ReadObject(ctx context.Context, namespace, key string,
 	db.mu.RLock()
 	defer db.mu.RUnlock()
 
-	fullPath := db.resolvePath(key)
+	name, err := db.resolveKey(key)
+	if err != nil {
+		return 0, err
+	}
 
-	file, err := os.Open(fullPath)
+	file, err := db.root.Open(name)
 	if err != nil {
 		if errors.Is(err, fs.ErrNotExist) {
 			return 0, data.ErrNotExist
@@ -80,10 +86,13 @@ func (db *DirectBackend) WriteObject(ctx context.Context, namespace, key string,
 	db.mu.Lock()
 	defer db.mu.Unlock()
 
-	fullPath := db.resolvePath(key)
+	name, err := db.resolveKey(key)
+	if err != nil {
+		return 0, err
+	}
 
 	// Open file for writing (O_RDWR to support both read and write)
-	file, err := os.OpenFile(fullPath, os.O_RDWR, 0644)
+	file, err := db.root.OpenFile(name, os.O_RDWR, 0644)
 	if err != nil {
 		if errors.Is(err, fs.ErrNotExist) {
 			return 0, data.ErrNotExist
@@ -108,9 +117,12 @@ func (db *DirectBackend) DeleteObject(ctx context.Context, namespace, key string
 	db.mu.Lock()
 	defer db.mu.Unlock()
 
-	fullPath := db.resolvePath(key)
+	name, err := db.resolveKey(key)
+	if err != nil {
+		return err
+	}
 
-	info, err := os.Stat(fullPath)
+	info, err := db.root.Stat(name)
 	if err != nil {
 		if errors.Is(err, fs.ErrNotExist) {
 			return data.ErrNotExist
@@ -124,19 +136,22 @@ func (db *DirectBackend) DeleteObject(ctx context.Context, namespace, key string
 			return data.ErrIsDirectory
 		}
 
-		return os.RemoveAll(fullPath)
+		return toDataError(db.root.RemoveAll(name))
 	}
 
-	return os.Remove(fullPath)
+	return toDataError(db.root.Remove(name))
 }
 
 func (db *DirectBackend) ListObjects(ctx context.Context, namespace, key string) ([]*data.FileStat, error) {
 	db.mu.RLock()
 	defer db.mu.RUnlock()
 
-	fullPath := db.resolvePath(key)
+	name, err := db.resolveKey(key)
+	if err != nil {
+		return nil, err
+	}
 
-	info, err := os.Stat(fullPath)
+	info, err := db.root.Stat(name)
 	if err != nil {
 		if errors.Is(err, fs.ErrNotExist) {
 			return nil, data.ErrNotExist
@@ -153,7 +168,13 @@ func (db *DirectBackend) ListObjects(ctx context.Context, namespace, key string)
 		}, nil
 	}
 
-	entries, err := os.ReadDir(fullPath)
+	dir, err := db.root.Open(name)
+	if err != nil {
+		return nil, toDataError(err)
+	}
+	defer dir.Close()
+
+	entries, err := dir.ReadDir(-1)
 	if err != nil {
 		return nil, err
 	}
@@ -165,7 +186,7 @@ func (db *DirectBackend) ListObjects(ctx context.Context, namespace, key string)
 			continue
 		}
 
-		childKey := filepath.Join(key, entry.Name())
+		childKey := path.Join(key, entry.Name())
 		stats = append(stats, db.toFileStat(childKey, childInfo))
 	}
 
@@ -176,9 +197,12 @@ func (db *DirectBackend) HeadObject(ctx context.Context, namespace, key string)
 	db.mu.RLock()
 	defer db.mu.RUnlock()
 
-	fullPath := db.resolvePath(key)
+	name, err := db.resolveKey(key)
+	if err != nil {
+		return nil, err
+	}
 
-	info, err := os.Stat(fullPath)
+	info, err := db.root.Stat(name)
 	if err != nil {
 		if errors.Is(err, fs.ErrNotExist) {
 			return nil, data.ErrNotExist
@@ -197,6 +221,15 @@ func (db *DirectBackend) TruncateObject(ctx context.Context, namespace, key stri
 	db.mu.Lock()
 	defer db.mu.Unlock()
 
-	fullPath := db.resolvePath(key)
-	return os.Truncate(fullPath, size)
+	name, err := db.resolveKey(key)
+	if err != nil {
+		return err
+	}
+	file, err := db.root.OpenFile(name, os.O_WRONLY, 0)
+	if err != nil {
+		return toDataError(err)
+	}
+	defer file.Close()
+
+	return file.Truncate(size)
 }
@@ -0,0 +1,234 @@
+package direct
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/mwantia/vfs/data"
+	"github.com/mwantia/vfs/mount/backend"
+)
+
+// stagingPrefix is the name prefix of the temporary directories used while applying changes.
+const stagingPrefix = ".vfs-tx-"
+
+// isStagingKey returns true, if the key is located within a temporary staging directory.
+func isStagingKey(key string) bool {
+	first, _, _ := strings.Cut(strings.TrimPrefix(key, "/"), "/")
+	return strings.HasPrefix(first, stagingPrefix)
+}
+
+// ApplyChanges applies all changes while holding the write lock, so readers never observe partial state.
+// All contents are written into a staging directory first and then moved into place, so a failure
+// while writing never touches the tree. Replaced or deleted files are kept in the staging directory
+// until all changes were applied, so they can be restored if a later change fails.
+func (db *DirectBackend) ApplyChanges(ctx context.Context, namespace string, changes []*backend.Change) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if db.root == nil {
+		return data.ErrClosed
+	}
+
+	err := backend.CheckBases(changes, func(key string) (*data.Metadata, error) {
+		name, err := db.resolveKey(key)
+		if err != nil {
+			return nil, err
+		}
+		info, err := db.root.Stat(name)
+		if err != nil {
+			return nil, toDataError(err)
+		}
+		return db.toFileStat(key, info).ToMetadata(), nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// Staging is created as direct child, so all further access can go through db.root
+	dir, err := os.MkdirTemp(db.path, stagingPrefix)
+	if err != nil {
+		if errors.Is(err, fs.ErrPermission) {
+			return data.ErrPermission
+		}
+		return err
+	}
+	staging := filepath.Base(dir)
+	defer db.root.RemoveAll(staging)
+
+	// Stage all contents before modifying anything
+	staged := make(map[*backend.Change]string)
+	for i, change := range changes {
+		if change.Type != backend.ChangeWrite {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		mode := fs.FileMode(0644)
+		if change.Metadata != nil && change.Metadata.Mode.Perm() != 0 {
+			mode = fs.FileMode(change.Metadata.Mode.Perm())
+		}
+
+		file := filepath.Join(staging, "write-"+strconv.Itoa(i))
+		if err := db.root.WriteFile(file, change.Content, mode); err != nil {
+			return err
+		}
+		staged[change] = file
+	}
+
+	reverts := make([]func(), 0, len(changes))
+	for i, change := range changes {
+		revert, err := db.applyChangeUnsafe(staging, i, change, staged[change])
+		if err != nil {
+			for j := len(reverts) - 1; j >= 0; j-- {
+				reverts[j]()
+			}
+			return err
+		}
+
+		reverts = append(reverts, revert)
+	}
+
+	return nil
+}
+
+// applyChangeUnsafe applies a single change and returns the function to revert it.
+// MUST be called while holding a write lock.
+func (db *DirectBackend) applyChangeUnsafe(staging string, index int, change *backend.Change, content string) (func(), error) {
+	if change.Key == "" || isStagingKey(change.Key) {
+		return nil, data.ErrInvalid
+	}
+
+	target, err := db.resolveKey(change.Key)
+	if err != nil {
+		return nil, err
+	}
+	if target == "." {
+		return nil, data.ErrInvalid
+	}
+
+	info, err := db.root.Lstat(target)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	exists := err == nil
+
+	backup := filepath.Join(staging, "backup-"+strconv.Itoa(index))
+
+	switch change.Type {
+	case backend.ChangeWrite:
+		if exists && info.IsDir() {
+			return nil, data.ErrIsDirectory
+		}
+		if !exists {
+			if err := db.checkParentUnsafe(target); err != nil {
+				return nil, err
+			}
+			if err := db.root.Rename(content, target); err != nil {
+				return nil, toDataError(err)
+			}
+			return func() { db.root.Remove(target) }, nil
+		}
+
+		// Keep the mode of the replaced file
+		if err := db.root.Chmod(content, info.Mode().Perm()); err != nil {
+			return nil, toDataError(err)
+		}
+		if err := db.root.Rename(target, backup); err != nil {
+			return nil, toDataError(err)
+		}
+		if err := db.root.Rename(content, target); err != nil {
+			db.root.Rename(backup, target)
+			return nil, toDataError(err)
+		}
+		return func() { db.root.Rename(backup, target) }, nil
+
+	case backend.ChangeMkdir:
+		if exists {
+			return nil, data.ErrExist
+		}
+		if err := db.checkParentUnsafe(target); err != nil {
+			return nil, err
+		}
+
+		mode := fs.FileMode(0755)
+		if change.Metadata != nil && change.Metadata.Mode.Perm() != 0 {
+			mode = fs.FileMode(change.Metadata.Mode.Perm())
+		}
+		if err := db.root.Mkdir(target, mode); err != nil {
+			return nil, toDataError(err)
+		}
+		return func() { db.root.Remove(target) }, nil
+
+	case backend.ChangeDelete:
+		if !exists {
+			return nil, data.ErrNotExist
+		}
+		if info.IsDir() {
+			dir, err := db.root.Open(target)
+			if err != nil {
+				return nil, toDataError(err)
+			}
+			entries, err := dir.ReadDir(1)
+			dir.Close()
+			if err != nil && err != io.EOF {
+				return nil, toDataError(err)
+			}
+			if len(entries) > 0 {
+				return nil, data.ErrDirectoryNotEmpty
+			}
+			if err := db.root.Remove(target); err != nil {
+				return nil, toDataError(err)
+			}
+			return func() { db.root.Mkdir(target, info.Mode().Perm()) }, nil
+		}
+
+		if err := db.root.Rename(target, backup); err != nil {
+			return nil, toDataError(err)
+		}
+		return func() { db.root.Rename(backup, target) }, nil
+
+	case backend.ChangeUpdate:
+		// Metadata is derived from the filesystem and can't be updated
+		return nil, data.ErrNotSupported
+	}
+
+	return nil, data.ErrInvalid
+}
+
+// checkParentUnsafe verifies that the parent of target exists and is a directory.
+func (db *DirectBackend) checkParentUnsafe(target string) error {
+	parent, err := db.root.Stat(filepath.Dir(target))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return data.ErrNotExist
+		}
+		return err
+	}
+	if !parent.IsDir() {
+		return data.ErrNotDirectory
+	}
+
+	return nil
+}
+
+// toDataError maps common filesystem errors to their data equivalent.
+func toDataError(err error) error {
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return data.ErrNotExist
+	case errors.Is(err, fs.ErrExist):
+		return data.ErrExist
+	case errors.Is(err, fs.ErrPermission):
+		return data.ErrPermission
+	}
+
+	return err
+}
@@ -0,0 +1,262 @@
+//go:build linux
+
+package direct
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/mwantia/vfs/data"
+	"golang.org/x/sys/unix"
+)
+
+const (
+	// inotifyMask contains all inotify events observed for each watched directory
+	inotifyMask = unix.IN_CREATE | unix.IN_MODIFY | unix.IN_CLOSE_WRITE | unix.IN_DELETE |
+		unix.IN_ATTRIB | unix.IN_MOVED_FROM | unix.IN_MOVED_TO | unix.IN_EXCL_UNLINK
+	// inotifyPollTimeout defines how often (in milliseconds) the context is checked for cancellation
+	inotifyPollTimeout = 250
+)
+
+// WatchObjects observes the backend directory tree using inotify and reports all changes to notify.
+// New directories are watched automatically, once they have been created or moved into the tree.
+func (db *DirectBackend) WatchObjects(ctx context.Context, namespace string, notify func(event data.Event)) (<-chan struct{}, error) {
+	fd, err := unix.InotifyInit1(unix.IN_CLOEXEC | unix.IN_NONBLOCK)
+	if err != nil {
+		return nil, err
+	}
+
+	iw := &inotifyWatcher{
+		fd:      fd,
+		root:    db.path,
+		notify:  notify,
+		watches: make(map[int32]string),
+	}
+
+	if err := iw.addRecursive(""); err != nil {
+		unix.Close(fd)
+		return nil, err
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer unix.Close(fd)
+
+		iw.run(ctx)
+	}()
+
+	return done, nil
+}
+
+// run reads and handles inotify events until ctx is cancelled or reading fails.
+func (iw *inotifyWatcher) run(ctx context.Context) {
+	buffer := make([]byte, 64*(unix.SizeofInotifyEvent+unix.NAME_MAX+1))
+	fds := []unix.PollFd{{Fd: int32(iw.fd), Events: unix.POLLIN}}
+
+	for ctx.Err() == nil {
+		n, err := unix.Poll(fds, inotifyPollTimeout)
+		if err != nil {
+			if errors.Is(err, unix.EINTR) {
+				continue
+			}
+			return
+		}
+		if n == 0 {
+			continue
+		}
+
+		n, err = unix.Read(iw.fd, buffer)
+		if err != nil {
+			if errors.Is(err, unix.EAGAIN) || errors.Is(err, unix.EINTR) {
+				continue
+			}
+			return
+		}
+
+		iw.handle(buffer[:n])
+	}
+}
+
+// inotifyWatcher keeps track of all watched directories and pending renames.
+type inotifyWatcher struct {
+	fd      int
+	root    string
+	notify  func(event data.Event)
+	watches map[int32]string // Watch descriptor to directory key
+	pending *inotifyMove     // Unmatched IN_MOVED_FROM waiting for its IN_MOVED_TO
+}
+
+// inotifyMove describes the source of a rename, identified by its cookie.
+type inotifyMove struct {
+	cookie uint32
+	key    string
+	isDir  bool
+}
+
+// addRecursive adds watches for the directory key and all of its subdirectories.
+func (iw *inotifyWatcher) addRecursive(key string) error {
+	return filepath.WalkDir(filepath.Join(iw.root, key), func(full string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			// Directories may be removed while walking
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if !entry.IsDir() {
+			return nil
+		}
+
+		relative, err := filepath.Rel(iw.root, full)
+		if err != nil {
+			return err
+		}
+		if relative == "." {
+			relative = ""
+		}
+
+		wd, err := unix.InotifyAddWatch(iw.fd, full, inotifyMask|unix.IN_ONLYDIR)
+		if err != nil {
+			if errors.Is(err, unix.ENOENT) {
+				return nil
+			}
+			return &os.PathError{Op: "inotify_add_watch", Path: full, Err: err}
+		}
+
+		iw.watches[int32(wd)] = filepath.ToSlash(relative)
+		return nil
+	})
+}
+
+// moveWatches updates all watched directories after a directory has been renamed.
+func (iw *inotifyWatcher) moveWatches(oldKey, newKey string) {
+	for wd, key := range iw.watches {
+		if key == oldKey {
+			iw.watches[wd] = newKey
+		} else if strings.HasPrefix(key, oldKey+"/") {
+			iw.watches[wd] = newKey + key[len(oldKey):]
+		}
+	}
+}
+
+// handle parses all raw inotify events within the buffer.
+func (iw *inotifyWatcher) handle(buffer []byte) {
+	for offset := 0; offset+unix.SizeofInotifyEvent <= len(buffer); {
+		wd := int32(binary.NativeEndian.Uint32(buffer[offset:]))
+		mask := binary.NativeEndian.Uint32(buffer[offset+4:])
+		cookie := binary.NativeEndian.Uint32(buffer[offset+8:])
+		length := int(binary.NativeEndian.Uint32(buffer[offset+12:]))
+
+		start := offset + unix.SizeofInotifyEvent
+		offset = start + length
+		if offset > len(buffer) {
+			break
+		}
+
+		name := strings.TrimRight(string(buffer[start:offset]), "\x00")
+		iw.handleEvent(wd, mask, cookie, name)
+	}
+
+	// Moves without matching destination have left the watched tree
+	iw.flushPending()
+}
+
+// handleEvent converts a single inotify event into vfs events.
+func (iw *inotifyWatcher) handleEvent(wd int32, mask, cookie uint32, name string) {
+	if mask&unix.IN_Q_OVERFLOW != 0 {
+		iw.notify(data.Event{Type: data.EventOverflow})
+		return
+	}
+
+	dir, exists := iw.watches[wd]
+	if !exists {
+		return
+	}
+	if mask&unix.IN_IGNORED != 0 {
+		delete(iw.watches, wd)
+		return
+	}
+
+	key := path.Join(dir, name)
+	isDir := mask&unix.IN_ISDIR != 0
+	// Staging directories of transactions are never part of the tree
+	if isStagingKey(key) {
+		return
+	}
+
+	if mask&unix.IN_MOVED_TO == 0 {
+		iw.flushPending()
+	}
+
+	switch {
+	case mask&unix.IN_CREATE != 0:
+		if isDir {
+			iw.addRecursive(key)
+			iw.notify(data.Event{Type: data.EventMkdir, Path: key})
+		} else {
+			iw.notify(data.Event{Type: data.EventCreate, Path: key})
+		}
+	case mask&unix.IN_DELETE != 0:
+		if isDir {
+			iw.notify(data.Event{Type: data.EventRmdir, Path: key})
+		} else {
+			iw.notify(data.Event{Type: data.EventUnlink, Path: key})
+		}
+	case mask&unix.IN_MODIFY != 0:
+		iw.notify(data.Event{Type: data.EventWrite, Path: key})
+	case mask&unix.IN_CLOSE_WRITE != 0:
+		iw.notify(data.Event{Type: data.EventCloseWrite, Path: key})
+	case mask&unix.IN_ATTRIB != 0:
+		iw.notify(data.Event{Type: data.EventAttrib, Path: key})
+	case mask&unix.IN_MOVED_FROM != 0:
+		iw.pending = &inotifyMove{cookie: cookie, key: key, isDir: isDir}
+	case mask&unix.IN_MOVED_TO != 0:
+		if iw.pending != nil && iw.pending.cookie == cookie {
+			if isDir {
+				iw.moveWatches(iw.pending.key, key)
+			}
+			iw.notify(data.Event{Type: data.EventRename, Path: key, OldPath: iw.pending.key})
+			iw.pending = nil
+			return
+		}
+
+		iw.flushPending()
+		// Moved into the watched tree from somewhere else
+		if isDir {
+			iw.addRecursive(key)
+			iw.notify(data.Event{Type: data.EventMkdir, Path: key})
+		} else {
+			iw.notify(data.Event{Type: data.EventCreate, Path: key})
+		}
+	}
+}
+
+// flushPending reports an unmatched move as removal, since its destination is outside of the tree.
+func (iw *inotifyWatcher) flushPending() {
+	if iw.pending == nil {
+		return
+	}
+
+	move := iw.pending
+	iw.pending = nil
+
+	if move.isDir {
+		for wd, key := range iw.watches {
+			if key == move.key || strings.HasPrefix(key, move.key+"/") {
+				unix.InotifyRmWatch(iw.fd, uint32(wd))
+				delete(iw.watches, wd)
+			}
+		}
+		iw.notify(data.Event{Type: data.EventRmdir, Path: move.key})
+		return
+	}
+
+	iw.notify(data.Event{Type: data.EventUnlink, Path: move.key})
+}
@@ -0,0 +1,53 @@
+package ephemeral
+
+import (
+	"bytes"
+	"context"
+	"path"
+
+	"github.com/mwantia/vfs/data"
+	"github.com/mwantia/vfs/mount/backend"
+)
+
+// CopyObject creates dstKey as copy of the file at srcKey by cloning its buffer.
+func (eb *EphemeralBackend) CopyObject(ctx context.Context, srcNamespace, srcKey, dstNamespace, dstKey string) (*data.FileStat, error) {
+	eb.mu.Lock()
+	defer eb.mu.Unlock()
+
+	meta, err := eb.readMetaUnsafe(ctx, srcNamespace, srcKey)
+	if err != nil {
+		return nil, err
+	}
+	if meta.Mode.IsDir() {
+		return nil, data.ErrIsDirectory
+	}
+
+	if _, exists := eb.keys.Get(backend.NamespacedKey(dstNamespace, dstKey)); exists {
+		return nil, data.ErrExist
+	}
+	// Verify parent directory exists
+	if parentKey := path.Dir(dstKey); parentKey != "." && parentKey != "" {
+		parentMeta, err := eb.readMetaUnsafe(ctx, dstNamespace, parentKey)
+		if err != nil {
+			return nil, data.ErrNotExist
+		}
+		if !parentMeta.Mode.IsDir() {
+			return nil, data.ErrNotDirectory
+		}
+	}
+
+	// Only the content is copied, so the checksum remains valid
+	clone := data.NewFileMetadata(dstKey, meta.Size, meta.Mode)
+	if checksum := meta.GetAttribute(data.AttributeChecksum, ""); checksum != "" {
+		clone.Attributes[data.AttributeChecksum] = checksum
+	}
+
+	if err := eb.createMetaUnsafe(ctx, dstNamespace, clone); err != nil {
+		return nil, err
+	}
+	if buffer, exists := eb.datas[meta.ID]; exists {
+		eb.datas[clone.ID] = bytes.Clone(buffer)
+	}
+
+	return clone.ToStat(), nil
+}
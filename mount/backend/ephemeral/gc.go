@@ -0,0 +1,53 @@
+package ephemeral
+
+import (
+	"context"
+
+	"github.com/mwantia/vfs/data"
+)
+
+// CollectGarbage removes metadata no longer referenced by any key and buffers no longer referenced by any metadata.
+// Buffers carry no timestamps, but are only ever written while holding the lock, so they can't be in-flight.
+func (eb *EphemeralBackend) CollectGarbage(ctx context.Context, opts *data.GCOptions) (*data.GCReport, error) {
+	eb.mu.Lock()
+	defer eb.mu.Unlock()
+
+	report := &data.GCReport{DryRun: opts.DryRun}
+
+	// Mark all ids still referenced by a key (including hard links)
+	marked := make(map[string]bool, len(eb.metadata))
+	eb.keys.Scan(func(_ string, id string) bool {
+		marked[id] = true
+		return true
+	})
+
+	for id, meta := range eb.metadata {
+		report.Scanned++
+		if marked[id] {
+			continue
+		}
+
+		// The buffer is handled along with its metadata
+		marked[id] = true
+		deferred := !opts.Expired(meta.ModifyTime)
+		report.Orphan("metadata/"+id, int64(len(eb.datas[id])), deferred)
+		if !deferred && !opts.DryRun {
+			delete(eb.metadata, id)
+			delete(eb.datas, id)
+		}
+	}
+
+	for id, buffer := range eb.datas {
+		report.Scanned++
+		if marked[id] {
+			continue
+		}
+
+		report.Orphan("data/"+id, int64(len(buffer)), false)
+		if !opts.DryRun {
+			delete(eb.datas, id)
+		}
+	}
+
+	return report, nil
+}
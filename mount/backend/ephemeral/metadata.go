@@ -18,7 +18,13 @@ func (mb *EphemeralBackend) CreateMeta(ctx context.Context, namespace string, me
 func (mb *EphemeralBackend) ReadMeta(ctx context.Context, namespace string, key string) (*data.Metadata, error) {
 	mb.mu.RLock()
 	defer mb.mu.RUnlock()
-	return mb.readMetaUnsafe(ctx, namespace, key)
+
+	meta, err := mb.readMetaUnsafe(ctx, namespace, key)
+	if err != nil {
+		return nil, err
+	}
+	// Return a copy, since the stored metadata is modified by concurrent writes
+	return meta.Clone(), nil
 }
 
 func (mb *EphemeralBackend) UpdateMeta(ctx context.Context, namespace string, key string, update *data.MetadataUpdate) error {
@@ -0,0 +1,15 @@
+package ephemeral
+
+import "github.com/mwantia/vfs/mount/backend"
+
+func init() {
+	backend.Register(&backend.Registration{
+		Name:        "ephemeral",
+		Description: "In-memory storage, discarded once unmounted",
+		Schemes:     []string{"mem"},
+		Params:      []backend.ParamSpec{},
+		Factory: func(params backend.FactoryParams) (backend.Backend, error) {
+			return NewEphemeralBackend(), nil
+		},
+	})
+}
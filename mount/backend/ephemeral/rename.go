@@ -0,0 +1,86 @@
+package ephemeral
+
+import (
+	"context"
+	"path"
+	"strings"
+
+	"github.com/mwantia/vfs/data"
+	"github.com/mwantia/vfs/mount/backend"
+)
+
+// RenameObject moves the object at oldKey including all nested objects to newKey by swapping their map entries.
+func (eb *EphemeralBackend) RenameObject(ctx context.Context, namespace, oldKey, newKey string) error {
+	eb.mu.Lock()
+	defer eb.mu.Unlock()
+
+	return eb.renameUnsafe(ctx, namespace, oldKey, newKey)
+}
+
+// RenameMeta moves the metadata at oldKey including all nested entries to newKey.
+// Since objects and metadata share the same entries, this is equal to RenameObject.
+func (eb *EphemeralBackend) RenameMeta(ctx context.Context, namespace, oldKey, newKey string) error {
+	eb.mu.Lock()
+	defer eb.mu.Unlock()
+
+	return eb.renameUnsafe(ctx, namespace, oldKey, newKey)
+}
+
+// renameUnsafe moves all keys equal to or nested below oldKey to newKey.
+// MUST be called while holding a write lock.
+func (eb *EphemeralBackend) renameUnsafe(ctx context.Context, namespace, oldKey, newKey string) error {
+	if oldKey == "" || newKey == "" || strings.HasPrefix(newKey, oldKey+"/") {
+		return data.ErrInvalid
+	}
+	if oldKey == newKey {
+		return nil
+	}
+
+	if _, err := eb.readMetaUnsafe(ctx, namespace, oldKey); err != nil {
+		return err
+	}
+	if exists, _ := eb.existsMetaUnsafe(ctx, namespace, newKey); exists {
+		return data.ErrExist
+	}
+	// Verify parent directory exists
+	if parentKey := path.Dir(newKey); parentKey != "." && parentKey != "" {
+		parentMeta, err := eb.readMetaUnsafe(ctx, namespace, parentKey)
+		if err != nil {
+			return data.ErrNotExist
+		}
+		if !parentMeta.Mode.IsDir() {
+			return data.ErrNotDirectory
+		}
+	}
+
+	// Collect all affected keys first, since the tree can't be modified while scanning
+	renamed := make(map[string]string)
+	nsPrefix := backend.NamespacedKey(namespace, "")
+	eb.keys.Ascend(backend.NamespacedKey(namespace, oldKey), func(nsKey string, id string) bool {
+		key := strings.TrimPrefix(nsKey, nsPrefix)
+		if newChildKey, affected := backend.RenamedKey(key, oldKey, newKey); affected {
+			renamed[key] = newChildKey
+			return true
+		}
+		// Keys are ordered, so all nested keys are located directly after oldKey
+		// (except keys with characters sorted before "/", like "-" or ".")
+		return strings.HasPrefix(key, oldKey)
+	})
+
+	for key, newChildKey := range renamed {
+		nsKey := backend.NamespacedKey(namespace, key)
+		id, _ := eb.keys.Get(nsKey)
+
+		eb.keys.Delete(nsKey)
+		eb.unindexKeyUnsafe(namespace, key)
+
+		eb.keys.Set(backend.NamespacedKey(namespace, newChildKey), id)
+		eb.indexKeyUnsafe(namespace, newChildKey)
+
+		if meta, exists := eb.metadata[id]; exists {
+			meta.Key = newChildKey
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,152 @@
+package ephemeral
+
+import (
+	"bytes"
+	"context"
+	"path"
+	"time"
+
+	"github.com/mwantia/vfs/data"
+	"github.com/mwantia/vfs/mount/backend"
+)
+
+// ApplyChanges applies all changes while holding the write lock, so readers never observe partial state.
+// Every applied change records how to revert it, so a failing change restores the previous state.
+func (eb *EphemeralBackend) ApplyChanges(ctx context.Context, namespace string, changes []*backend.Change) error {
+	eb.mu.Lock()
+	defer eb.mu.Unlock()
+
+	err := backend.CheckBases(changes, func(key string) (*data.Metadata, error) {
+		return eb.readMetaUnsafe(ctx, namespace, key)
+	})
+	if err != nil {
+		return err
+	}
+
+	reverts := make([]func(), 0, len(changes))
+	for _, change := range changes {
+		revert, err := eb.applyChangeUnsafe(ctx, namespace, change)
+		if err != nil {
+			for i := len(reverts) - 1; i >= 0; i-- {
+				reverts[i]()
+			}
+			return err
+		}
+
+		reverts = append(reverts, revert)
+	}
+
+	return nil
+}
+
+// applyChangeUnsafe applies a single change and returns the function to revert it.
+// MUST be called while holding a write lock.
+func (eb *EphemeralBackend) applyChangeUnsafe(ctx context.Context, namespace string, change *backend.Change) (func(), error) {
+	meta, err := eb.readMetaUnsafe(ctx, namespace, change.Key)
+	if err != nil && err != data.ErrNotExist {
+		return nil, err
+	}
+
+	switch change.Type {
+	case backend.ChangeWrite:
+		if meta == nil {
+			return eb.createUnsafe(ctx, namespace, change)
+		}
+		if meta.Mode.IsDir() {
+			return nil, data.ErrIsDirectory
+		}
+
+		previous := meta.Clone()
+		buffer, buffered := eb.datas[meta.ID]
+
+		eb.datas[meta.ID] = bytes.Clone(change.Content)
+		meta.Size = int64(len(change.Content))
+		meta.ModifyTime = time.Now()
+
+		return func() {
+			*meta = *previous
+			if buffered {
+				eb.datas[meta.ID] = buffer
+			} else {
+				delete(eb.datas, meta.ID)
+			}
+		}, nil
+
+	case backend.ChangeMkdir:
+		if meta != nil {
+			return nil, data.ErrExist
+		}
+		return eb.createUnsafe(ctx, namespace, change)
+
+	case backend.ChangeDelete:
+		if meta == nil {
+			return nil, data.ErrNotExist
+		}
+		if meta.Mode.IsDir() {
+			if _, exists := eb.directories[backend.NamespacedKey(namespace, change.Key+"/")]; exists {
+				return nil, data.ErrDirectoryNotEmpty
+			}
+		}
+
+		buffer, buffered := eb.datas[meta.ID]
+		if err := eb.deleteMetaUnsafe(ctx, namespace, change.Key); err != nil {
+			return nil, err
+		}
+
+		return func() {
+			eb.createMetaUnsafe(ctx, namespace, meta)
+			if buffered {
+				eb.datas[meta.ID] = buffer
+			}
+		}, nil
+
+	case backend.ChangeUpdate:
+		if meta == nil {
+			return nil, data.ErrNotExist
+		}
+
+		previous := meta.Clone()
+		if err := eb.updateMetaUnsafe(ctx, namespace, change.Key, change.Update); err != nil {
+			return nil, err
+		}
+
+		return func() {
+			*meta = *previous
+		}, nil
+	}
+
+	return nil, data.ErrInvalid
+}
+
+// createUnsafe creates the file or directory described by change.
+// MUST be called while holding a write lock.
+func (eb *EphemeralBackend) createUnsafe(ctx context.Context, namespace string, change *backend.Change) (func(), error) {
+	if change.Metadata == nil {
+		return nil, data.ErrInvalid
+	}
+	// Verify parent directory exists
+	if parentKey := path.Dir(change.Key); parentKey != "." && parentKey != "" {
+		parentMeta, err := eb.readMetaUnsafe(ctx, namespace, parentKey)
+		if err != nil {
+			return nil, data.ErrNotExist
+		}
+		if !parentMeta.Mode.IsDir() {
+			return nil, data.ErrNotDirectory
+		}
+	}
+
+	meta := change.Metadata.Clone()
+	meta.Key = change.Key
+	meta.Size = int64(len(change.Content))
+
+	if err := eb.createMetaUnsafe(ctx, namespace, meta); err != nil {
+		return nil, err
+	}
+	if change.Type == backend.ChangeWrite {
+		eb.datas[meta.ID] = bytes.Clone(change.Content)
+	}
+
+	return func() {
+		eb.deleteMetaUnsafe(ctx, namespace, change.Key)
+	}, nil
+}
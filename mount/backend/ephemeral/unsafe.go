@@ -32,20 +32,47 @@ func (mb *EphemeralBackend) createMetaUnsafe(ctx context.Context, namespace stri
 	mb.keys.Set(nsKey, meta.ID)
 	mb.metadata[meta.ID] = meta
 
-	// Update directory index for fast lookups
+	mb.indexKeyUnsafe(namespace, meta.Key)
+	return nil
+}
+
+// indexKeyUnsafe adds the key to the directory index of its parent for fast lookups.
+// MUST be called while holding a write lock.
+func (mb *EphemeralBackend) indexKeyUnsafe(namespace string, key string) {
 	// Extract parent directory from key
-	if idx := strings.LastIndex(meta.Key, "/"); idx >= 0 {
+	if idx := strings.LastIndex(key, "/"); idx >= 0 {
 		// Parent directory path (everything before last /)
-		parentDir := meta.Key[:idx+1]
+		parentDir := key[:idx+1]
 		nsParentDir := backend.NamespacedKey(namespace, parentDir)
 		// Add this key to parent's children list
 		if mb.directories[nsParentDir] == nil {
 			mb.directories[nsParentDir] = make([]string, 0)
 		}
-		mb.directories[nsParentDir] = append(mb.directories[nsParentDir], nsKey)
+		mb.directories[nsParentDir] = append(mb.directories[nsParentDir], backend.NamespacedKey(namespace, key))
 	}
+}
 
-	return nil
+// unindexKeyUnsafe removes the key from the directory index of its parent.
+// MUST be called while holding a write lock.
+func (mb *EphemeralBackend) unindexKeyUnsafe(namespace string, key string) {
+	if idx := strings.LastIndex(key, "/"); idx >= 0 {
+		nsKey := backend.NamespacedKey(namespace, key)
+		parentDir := key[:idx+1]
+		nsParentDir := backend.NamespacedKey(namespace, parentDir)
+		if children, ok := mb.directories[nsParentDir]; ok {
+			// Find and remove this key from children list
+			for i, child := range children {
+				if child == nsKey {
+					mb.directories[nsParentDir] = append(children[:i], children[i+1:]...)
+					break
+				}
+			}
+			// Clean up empty directory entries
+			if len(mb.directories[nsParentDir]) == 0 {
+				delete(mb.directories, nsParentDir)
+			}
+		}
+	}
 }
 
 // readMetaUnsafe reads metadata without acquiring locks.
@@ -62,8 +89,6 @@ func (mb *EphemeralBackend) readMetaUnsafe(ctx context.Context, namespace string
 		return nil, data.ErrNotExist
 	}
 
-	// Update access time (acceptable race condition for performance)
-	meta.AccessTime = time.Now()
 	return meta, nil
 }
 
@@ -115,24 +140,7 @@ func (mb *EphemeralBackend) deleteMetaUnsafe(ctx context.Context, namespace stri
 		}
 
 		// Update directory index - remove this key from parent's children
-		if idx := strings.LastIndex(key, "/"); idx >= 0 {
-			parentDir := key[:idx+1]
-			nsParentDir := backend.NamespacedKey(namespace, parentDir)
-			if children, ok := mb.directories[nsParentDir]; ok {
-				// Find and remove this key from children list
-				for i, child := range children {
-					if child == nsKey {
-						// Remove by swapping with last element and truncating
-						mb.directories[nsParentDir] = append(children[:i], children[i+1:]...)
-						break
-					}
-				}
-				// Clean up empty directory entries
-				if len(mb.directories[nsParentDir]) == 0 {
-					delete(mb.directories, nsParentDir)
-				}
-			}
-		}
+		mb.unindexKeyUnsafe(namespace, key)
 	}
 
 	return nil
@@ -0,0 +1,194 @@
+package erasure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/mwantia/vfs/mount/backend"
+)
+
+// DefaultChunkSize is the amount of content stored by each shard per stripe, if no chunk size is defined.
+const DefaultChunkSize = 4096
+
+var (
+	// ErrWriteQuorum is returned if a write has been acknowledged by fewer shards than required.
+	ErrWriteQuorum = errors.New("erasure: write quorum not reached")
+
+	// ErrTooFewShards is returned if content can't be reconstructed, since fewer shards than data shards are available.
+	ErrTooFewShards = errors.New("erasure: too few shards available")
+)
+
+// ErasureBackend distributes objects across multiple object storage backends (e.g. several directories or buckets)
+// with a reed-solomon erasure code:
+//
+// Content is split into stripes, where each stripe consists of one chunk per data shard and one parity chunk
+// per parity shard. Every shard backend stores its chunks of an object within an object of the same key,
+// so the content remains readable as long as any data shards of every stripe are intact. Directories are
+// mirrored to all shards. Missing, corrupt or outdated chunks are detected by checksums and generations
+// and rebuilt from the remaining shards by Scrub.
+type ErasureBackend struct {
+	mu     sync.RWMutex
+	shards []backend.ObjectStorageBackend
+	config *ErasureBackendConfig
+	codec  *codec
+
+	nsMu       sync.Mutex
+	namespaces map[string]struct{} // Namespaces accessed since opening, which are covered by the scrub
+
+	scrubMu     sync.Mutex // Serializes explicit and background scrubs
+	scrubCancel context.CancelFunc
+	scrubDone   <-chan struct{}
+}
+
+// ErasureBackendConfig contains configuration options for the erasure coded backend
+type ErasureBackendConfig struct {
+	// Number of parity shards, which may be lost without losing any content (default: 1)
+	// All remaining shards are used as data shards
+	ParityShards int
+
+	// Amount of content stored by each shard per stripe (default: DefaultChunkSize)
+	// Existing objects are always read with the chunk size they have been written with
+	ChunkSize int
+
+	// Number of shards required to acknowledge a write (default: data shards + 1, at most all shards)
+	WriteQuorum int
+
+	// Interval of the background scrub, which is disabled by default since it reads all shards entirely
+	ScrubInterval time.Duration
+
+	// Namespaces covered by the scrub in addition to all namespaces accessed since opening
+	Namespaces []string
+}
+
+// NewErasureBackend creates a backend distributing all objects across shards.
+// The shards are owned by the returned backend and opened and closed together with it.
+// The order of shards must never change, since each shard stores a different part of every object.
+func NewErasureBackend(shards []backend.ObjectStorageBackend, config *ErasureBackendConfig) (*ErasureBackend, error) {
+	if config == nil {
+		config = &ErasureBackendConfig{}
+	}
+
+	// Set defaults
+	if config.ParityShards == 0 {
+		config.ParityShards = 1
+	}
+	if config.ChunkSize == 0 {
+		config.ChunkSize = DefaultChunkSize
+	}
+
+	dataShards := len(shards) - config.ParityShards
+	if config.ParityShards < 1 || dataShards < 1 {
+		return nil, fmt.Errorf("%d shards are insufficient for %d parity shards", len(shards), config.ParityShards)
+	}
+	if config.ChunkSize < 1 || config.ChunkSize > maxChunkSize {
+		return nil, fmt.Errorf("chunk size %d must be between 1 and %d", config.ChunkSize, maxChunkSize)
+	}
+	if config.WriteQuorum == 0 {
+		config.WriteQuorum = min(dataShards+1, len(shards))
+	}
+	if config.WriteQuorum < dataShards || config.WriteQuorum > len(shards) {
+		return nil, fmt.Errorf("write quorum %d must be between %d and %d", config.WriteQuorum, dataShards, len(shards))
+	}
+	for i, storage := range shards {
+		if storage == nil {
+			return nil, fmt.Errorf("shard %d must not be nil", i)
+		}
+	}
+
+	codec, err := newCodec(dataShards, config.ParityShards)
+	if err != nil {
+		return nil, err
+	}
+
+	eb := &ErasureBackend{
+		shards:     shards,
+		config:     config,
+		codec:      codec,
+		namespaces: map[string]struct{}{"": {}},
+	}
+	for _, namespace := range config.Namespaces {
+		eb.namespaces[namespace] = struct{}{}
+	}
+
+	return eb, nil
+}
+
+// Returns the identifier name defined for this backend
+func (*ErasureBackend) Name() string {
+	return "erasure"
+}
+
+// Open is part of the lifecycle behavious and gets called when opening this backend.
+// Shards failing to open are tolerated, as long as the write quorum can still be reached.
+func (eb *ErasureBackend) Open(ctx context.Context) error {
+	eb.mu.Lock()
+	defer eb.mu.Unlock()
+
+	var errs []error
+	for _, storage := range eb.shards {
+		if err := storage.Open(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("failed to open shard '%s': %w", storage.Name(), err))
+		}
+	}
+	if opened := len(eb.shards) - len(errs); opened < eb.config.WriteQuorum {
+		return fmt.Errorf("%w: %d of %d shards opened: %w", ErrWriteQuorum, opened, len(eb.shards), errors.Join(errs...))
+	}
+
+	if eb.config.ScrubInterval > 0 {
+		eb.startScrub(eb.config.ScrubInterval)
+	}
+	return nil
+}
+
+// Close is part of the lifecycle behaviour and gets called when closing this backend.
+func (eb *ErasureBackend) Close(ctx context.Context) error {
+	// The scrub requires the lock, so it has to be stopped first
+	eb.stopScrub()
+
+	eb.mu.Lock()
+	defer eb.mu.Unlock()
+
+	var errs []error
+	for _, storage := range eb.shards {
+		if err := storage.Close(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("failed to close shard '%s': %w", storage.Name(), err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// GetCapabilities returns a list of capabilities supported by this backend.
+func (eb *ErasureBackend) GetCapabilities() *backend.BackendCapabilities {
+	caps := &backend.BackendCapabilities{
+		Capabilities: []backend.BackendCapability{
+			backend.CapabilityObjectStorage,
+		},
+	}
+	// Every shard only stores its chunks of each stripe
+	chunkSize := int64(eb.config.ChunkSize)
+	for _, storage := range eb.shards {
+		shardCaps := storage.GetCapabilities()
+		if shardCaps.MaxObjectSize <= 0 {
+			continue
+		}
+
+		size := max(shardCaps.MaxObjectSize-headerSize, 0) / (chunkSize + blockTrailerSize) * chunkSize * int64(eb.codec.dataShards)
+		if caps.MaxObjectSize == 0 || size < caps.MaxObjectSize {
+			caps.MaxObjectSize = max(size, 1)
+		}
+	}
+
+	return caps
+}
+
+// trackNamespace adds namespace to the namespaces covered by the scrub.
+func (eb *ErasureBackend) trackNamespace(namespace string) {
+	eb.nsMu.Lock()
+	defer eb.nsMu.Unlock()
+
+	eb.namespaces[namespace] = struct{}{}
+}
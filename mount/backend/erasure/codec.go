@@ -0,0 +1,191 @@
+package erasure
+
+import (
+	"errors"
+	"fmt"
+)
+
+// errSingularMatrix is returned when inverting a matrix without inverse, which never happens for valid encoding matrices.
+var errSingularMatrix = errors.New("erasure: matrix is singular")
+
+// matrix is a matrix of elements within GF(2^8), stored by rows.
+type matrix [][]byte
+
+func newMatrix(rows, cols int) matrix {
+	m := make(matrix, rows)
+	for r := range m {
+		m[r] = make([]byte, cols)
+	}
+
+	return m
+}
+
+// identity returns the identity matrix of the specified size.
+func identity(size int) matrix {
+	m := newMatrix(size, size)
+	for i := range m {
+		m[i][i] = 1
+	}
+
+	return m
+}
+
+// vandermonde returns a matrix where each element is its row raised to the power of its column.
+// Any cols rows of this matrix are linearly independent, as long as rows doesn't exceed the field size.
+func vandermonde(rows, cols int) matrix {
+	m := newMatrix(rows, cols)
+	for r := range m {
+		for c := range m[r] {
+			m[r][c] = gfExp(byte(r), c)
+		}
+	}
+
+	return m
+}
+
+// multiply returns the product of m and other.
+func (m matrix) multiply(other matrix) matrix {
+	result := newMatrix(len(m), len(other[0]))
+	for r := range result {
+		for i, value := range m[r] {
+			gfMulAdd(value, other[i], result[r])
+		}
+	}
+
+	return result
+}
+
+// rows returns a matrix consisting of the specified rows of m.
+func (m matrix) rows(indices []int) matrix {
+	result := make(matrix, 0, len(indices))
+	for _, i := range indices {
+		result = append(result, append([]byte(nil), m[i]...))
+	}
+
+	return result
+}
+
+// invert returns the inverse of the square matrix m by gauss-jordan elimination.
+func (m matrix) invert() (matrix, error) {
+	size := len(m)
+	unit := identity(size)
+	work := make(matrix, 0, size)
+	for r := range m {
+		// Each row is extended by the identity, which turns into the inverse during the elimination
+		work = append(work, append(append([]byte(nil), m[r]...), unit[r]...))
+	}
+
+	for col := 0; col < size; col++ {
+		pivot := col
+		for pivot < size && work[pivot][col] == 0 {
+			pivot++
+		}
+		if pivot == size {
+			return nil, errSingularMatrix
+		}
+		work[col], work[pivot] = work[pivot], work[col]
+
+		if scale := work[col][col]; scale != 1 {
+			for c := range work[col] {
+				work[col][c] = gfDiv(work[col][c], scale)
+			}
+		}
+		for r := range work {
+			if r != col && work[r][col] != 0 {
+				gfMulAdd(work[r][col], work[col], work[r])
+			}
+		}
+	}
+
+	inverse := make(matrix, size)
+	for r := range work {
+		inverse[r] = work[r][size:]
+	}
+
+	return inverse, nil
+}
+
+// codec encodes data shards into parity shards with a systematic reed-solomon code,
+// so the content of any data shards can be reconstructed from any combination of data and parity shards.
+type codec struct {
+	dataShards   int
+	parityShards int
+	matrix       matrix // Encoding matrix, where the upper rows form the identity for the data shards
+}
+
+// newCodec creates a codec for the specified number of data and parity shards.
+func newCodec(dataShards, parityShards int) (*codec, error) {
+	if dataShards < 1 || parityShards < 0 || dataShards+parityShards > 256 {
+		return nil, fmt.Errorf("invalid number of %d data and %d parity shards", dataShards, parityShards)
+	}
+
+	// Multiplying with the inverse of the upper rows keeps every combination of rows invertible,
+	// while the data shards are stored as is
+	total := vandermonde(dataShards+parityShards, dataShards)
+	top, err := total[:dataShards].invert()
+	if err != nil {
+		return nil, err
+	}
+
+	return &codec{
+		dataShards:   dataShards,
+		parityShards: parityShards,
+		matrix:       total.multiply(top),
+	}, nil
+}
+
+// encode computes all parity shards from the data shards, where all shards have the same length.
+func (c *codec) encode(shards [][]byte) {
+	for p := c.dataShards; p < len(shards); p++ {
+		clear(shards[p])
+		for d := 0; d < c.dataShards; d++ {
+			gfMulAdd(c.matrix[p][d], shards[d], shards[p])
+		}
+	}
+}
+
+// reconstruct rebuilds all missing shards, which are nil, from the remaining shards of length size.
+// Returns ErrTooFewShards if fewer shards than data shards remain.
+func (c *codec) reconstruct(shards [][]byte, size int) error {
+	present := make([]int, 0, c.dataShards)
+	missing := false
+	for i, shard := range shards {
+		if shard == nil {
+			missing = true
+		} else if len(present) < c.dataShards {
+			present = append(present, i)
+		}
+	}
+	if !missing {
+		return nil
+	}
+	if len(present) < c.dataShards {
+		return fmt.Errorf("%w: %d of %d shards required", ErrTooFewShards, len(present), c.dataShards)
+	}
+
+	decode, err := c.matrix.rows(present).invert()
+	if err != nil {
+		return err
+	}
+	for d := 0; d < c.dataShards; d++ {
+		if shards[d] != nil {
+			continue
+		}
+		shards[d] = make([]byte, size)
+		for i, index := range present {
+			gfMulAdd(decode[d][i], shards[index], shards[d])
+		}
+	}
+
+	for p := c.dataShards; p < len(shards); p++ {
+		if shards[p] != nil {
+			continue
+		}
+		shards[p] = make([]byte, size)
+		for d := 0; d < c.dataShards; d++ {
+			gfMulAdd(c.matrix[p][d], shards[d], shards[p])
+		}
+	}
+
+	return nil
+}
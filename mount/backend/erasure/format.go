@@ -0,0 +1,345 @@
+package erasure
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"slices"
+	"sync"
+
+	"github.com/mwantia/vfs/data"
+	"github.com/mwantia/vfs/mount/backend"
+)
+
+const (
+	// headerMagic identifies objects stored as shard of an erasure coded object.
+	headerMagic   = "VFSE"
+	headerVersion = 1
+	headerSize    = 32
+
+	// Each block stores a chunk followed by the generation of the write and a checksum of both.
+	blockTrailerSize = 12
+	maxChunkSize     = 1 << 30
+)
+
+// header describes an object stored by a single shard.
+//
+// Every shard stores the header followed by one block per stripe:
+//
+//	[header: 32 bytes] [block 0] ... [block n-1]
+//
+// The header consists of the magic, version, shard index, number of data and parity shards, chunk size,
+// content size and generation of the object followed by a checksum of all previous fields, all encoded as big endian.
+type header struct {
+	index        int
+	dataShards   int
+	parityShards int
+	chunkSize    int64
+	size         int64  // Size of the content across all data shards
+	generation   uint64 // Incremented by every write, so outdated headers and blocks can be detected
+}
+
+func (h *header) encode() []byte {
+	buffer := make([]byte, headerSize)
+	copy(buffer[0:4], headerMagic)
+	buffer[4] = headerVersion
+	buffer[5] = byte(h.index)
+	buffer[6] = byte(h.dataShards)
+	buffer[7] = byte(h.parityShards)
+	binary.BigEndian.PutUint32(buffer[8:12], uint32(h.chunkSize))
+	binary.BigEndian.PutUint64(buffer[12:20], uint64(h.size))
+	binary.BigEndian.PutUint64(buffer[20:28], h.generation)
+	binary.BigEndian.PutUint32(buffer[28:32], crc32.ChecksumIEEE(buffer[:28]))
+
+	return buffer
+}
+
+// decodeHeader returns the header stored within buffer or nil, if it is corrupt.
+func decodeHeader(buffer []byte) *header {
+	if len(buffer) != headerSize || string(buffer[0:4]) != headerMagic || buffer[4] != headerVersion ||
+		binary.BigEndian.Uint32(buffer[28:32]) != crc32.ChecksumIEEE(buffer[:28]) {
+		return nil
+	}
+
+	h := &header{
+		index:        int(buffer[5]),
+		dataShards:   int(buffer[6]),
+		parityShards: int(buffer[7]),
+		chunkSize:    int64(binary.BigEndian.Uint32(buffer[8:12])),
+		size:         int64(binary.BigEndian.Uint64(buffer[12:20])),
+		generation:   binary.BigEndian.Uint64(buffer[20:28]),
+	}
+	if h.chunkSize < 1 || h.chunkSize > maxChunkSize || h.size < 0 {
+		return nil
+	}
+
+	return h
+}
+
+// stripeSize returns the amount of content stored by a single stripe.
+func (h *header) stripeSize() int64 {
+	return h.chunkSize * int64(h.dataShards)
+}
+
+// stripes returns the number of stripes required to store content of size bytes.
+func (h *header) stripes(size int64) int64 {
+	return (size + h.stripeSize() - 1) / h.stripeSize()
+}
+
+// blockOffset returns the offset of the block of stripe within each shard.
+func (h *header) blockOffset(stripe int64) int64 {
+	return headerSize + stripe*(h.chunkSize+blockTrailerSize)
+}
+
+// encodeBlock appends the block of chunk written with generation to buffer.
+func encodeBlock(buffer, chunk []byte, generation uint64) []byte {
+	start := len(buffer)
+	buffer = append(buffer, chunk...)
+	buffer = binary.BigEndian.AppendUint64(buffer, generation)
+
+	return binary.BigEndian.AppendUint32(buffer, crc32.ChecksumIEEE(buffer[start:]))
+}
+
+// decodeBlock returns the chunk and generation stored within block, unless its checksum doesn't match.
+func decodeBlock(block []byte) ([]byte, uint64, bool) {
+	if len(block) < blockTrailerSize {
+		return nil, 0, false
+	}
+
+	checksum := len(block) - 4
+	if binary.BigEndian.Uint32(block[checksum:]) != crc32.ChecksumIEEE(block[:checksum]) {
+		return nil, 0, false
+	}
+
+	chunk := len(block) - blockTrailerSize
+	return block[:chunk], binary.BigEndian.Uint64(block[chunk:checksum]), true
+}
+
+// shard describes the object at a key within a single shard backend.
+type shard struct {
+	stat   *data.FileStat
+	header *header // Header of files, which is nil if missing, corrupt or written for other shards
+	err    error   // Failure of the shard backend, which excludes it from the operation
+}
+
+// object describes an object across all shards.
+type object struct {
+	stat   *data.FileStat // Stat of the object with the size of its content
+	header *header        // Latest header across all shards, which is nil for directories
+	shards []shard
+}
+
+// load reads the object at key from all shards. Objects are only considered existing, if they are held by
+// at least as many shards as there are data shards, so remnants on few shards are ignored.
+func (eb *ErasureBackend) load(ctx context.Context, namespace, key string) (*object, error) {
+	obj := &object{
+		shards: make([]shard, len(eb.shards)),
+	}
+	eb.fanout(func(i int, storage backend.ObjectStorageBackend) error {
+		s := &obj.shards[i]
+		s.stat, s.err = storage.HeadObject(ctx, namespace, key)
+		if s.err != nil || s.stat.Mode.IsDir() {
+			return nil
+		}
+
+		buffer := make([]byte, headerSize)
+		if err := readFull(ctx, storage, namespace, key, 0, buffer); err != nil {
+			if isFailure(err) && !errors.Is(err, io.ErrUnexpectedEOF) {
+				s.err = err
+			}
+			return nil
+		}
+		if h := decodeHeader(buffer); h != nil && h.index == i &&
+			h.dataShards == eb.codec.dataShards && h.parityShards == eb.codec.parityShards {
+			s.header = h
+		}
+		return nil
+	})
+
+	dirs, files, failures := 0, 0, 0
+	var failure error
+	for i, s := range obj.shards {
+		switch {
+		case s.err == nil && s.stat.Mode.IsDir():
+			dirs++
+			if obj.stat == nil {
+				obj.stat = s.stat
+			}
+		case s.err == nil && s.header != nil:
+			files++
+			if obj.header == nil || s.header.generation > obj.header.generation {
+				obj.header = s.header
+				obj.stat = obj.shards[i].stat
+			}
+		case s.err != nil && s.err != data.ErrNotExist:
+			failures++
+			failure = s.err
+		}
+	}
+
+	k := eb.codec.dataShards
+	switch {
+	case files >= k && files >= dirs:
+		stat := *obj.stat
+		stat.Size = obj.header.size
+		stat.ETag = ""
+		obj.stat = &stat
+		return obj, nil
+	case dirs >= k:
+		obj.header = nil
+		return obj, nil
+	case max(files, dirs)+failures >= k && failure != nil:
+		return nil, fmt.Errorf("%w: failed to stat '%s': %w", ErrTooFewShards, key, failure)
+	}
+
+	return nil, data.ErrNotExist
+}
+
+// stripe contains the chunks of a single stripe across all shards.
+type stripe struct {
+	chunks     [][]byte // Chunks of all shards, where missing chunks have been reconstructed
+	intact     []bool   // Whether the block of each shard has been read with a valid checksum and the latest generation
+	generation uint64
+}
+
+// readStripe reads the block of stripe i from all shards holding the object and reconstructs missing chunks.
+// The latest generation written to at least as many shards as there are data shards is used for the stripe.
+func (eb *ErasureBackend) readStripe(ctx context.Context, namespace, key string, obj *object, i int64) (*stripe, error) {
+	h := obj.header
+	blocks := make([][]byte, len(eb.shards))
+	generations := make([]uint64, len(eb.shards))
+	eb.fanout(func(index int, storage backend.ObjectStorageBackend) error {
+		if s := obj.shards[index]; s.err != nil || s.stat.Mode.IsDir() {
+			return nil
+		}
+
+		block := make([]byte, h.chunkSize+blockTrailerSize)
+		if err := readFull(ctx, storage, namespace, key, h.blockOffset(i), block); err != nil {
+			return nil
+		}
+		if chunk, generation, ok := decodeBlock(block); ok {
+			blocks[index], generations[index] = chunk, generation
+		}
+		return nil
+	})
+
+	counts := make(map[uint64]int)
+	for index, block := range blocks {
+		if block != nil {
+			counts[generations[index]]++
+		}
+	}
+	candidates := make([]uint64, 0, len(counts))
+	for generation, count := range counts {
+		if count >= eb.codec.dataShards {
+			candidates = append(candidates, generation)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("%w: stripe %d of '%s' has too few intact blocks", ErrTooFewShards, i, key)
+	}
+
+	s := &stripe{
+		chunks:     make([][]byte, len(eb.shards)),
+		intact:     make([]bool, len(eb.shards)),
+		generation: slices.Max(candidates),
+	}
+	for index, block := range blocks {
+		if block != nil && generations[index] == s.generation {
+			s.chunks[index] = block
+			s.intact[index] = true
+		}
+	}
+	if err := eb.codec.reconstruct(s.chunks, int(h.chunkSize)); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// fanout applies fn to all shards in parallel and returns the error of every shard.
+func (eb *ErasureBackend) fanout(fn func(i int, storage backend.ObjectStorageBackend) error) []error {
+	errs := make([]error, len(eb.shards))
+	var wg sync.WaitGroup
+	for i, storage := range eb.shards {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = fn(i, storage)
+		}()
+	}
+	wg.Wait()
+
+	return errs
+}
+
+// acknowledge returns nil, if the write has been acknowledged by the write quorum.
+// Errors describing the object itself are returned as is, if no shard has acknowledged the write.
+func (eb *ErasureBackend) acknowledge(errs []error) error {
+	acks := 0
+	var rejected, failure error
+	for _, err := range errs {
+		switch {
+		case err == nil:
+			acks++
+		case isFailure(err):
+			failure = err
+		case rejected == nil:
+			rejected = err
+		}
+	}
+
+	if acks >= eb.config.WriteQuorum {
+		return nil
+	}
+	if acks == 0 && rejected != nil {
+		return rejected
+	}
+	if failure != nil {
+		return fmt.Errorf("%w: acknowledged by %d of %d shards: %w", ErrWriteQuorum, acks, eb.config.WriteQuorum, failure)
+	}
+	return fmt.Errorf("%w: acknowledged by %d of %d shards", ErrWriteQuorum, acks, eb.config.WriteQuorum)
+}
+
+// isFailure returns false for errors describing the requested object, which every shard is expected to return alike.
+// All other errors are considered a failure of the shard itself.
+func isFailure(err error) bool {
+	switch {
+	case err == nil:
+		return false
+	case errors.Is(err, data.ErrNotExist),
+		errors.Is(err, data.ErrExist),
+		errors.Is(err, data.ErrIsDirectory),
+		errors.Is(err, data.ErrNotDirectory),
+		errors.Is(err, data.ErrDirectoryNotEmpty),
+		errors.Is(err, data.ErrPermission),
+		errors.Is(err, data.ErrInvalid),
+		errors.Is(err, context.Canceled),
+		errors.Is(err, context.DeadlineExceeded):
+		return false
+	}
+
+	return true
+}
+
+// readFull reads exactly len(dat) bytes at offset from storage.
+func readFull(ctx context.Context, storage backend.ObjectStorageBackend, namespace, key string, offset int64, dat []byte) error {
+	for read := 0; read < len(dat); {
+		n, err := storage.ReadObject(ctx, namespace, key, offset+int64(read), dat[read:])
+		read += n
+		if read == len(dat) {
+			return nil
+		}
+		if err == io.EOF || (err == nil && n == 0) {
+			return fmt.Errorf("failed to read '%s': %w", key, io.ErrUnexpectedEOF)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
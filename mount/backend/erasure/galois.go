@@ -0,0 +1,79 @@
+package erasure
+
+// Arithmetic within the galois field GF(2^8), which is generated by the polynomial x^8 + x^4 + x^3 + x^2 + 1.
+// Addition and subtraction are both xor, while multiplication and division use logarithm tables.
+
+const fieldPolynomial = 0x11d
+
+var (
+	// expTable contains every power of the generator twice, so sums of two logarithms never need a modulo
+	expTable [510]byte
+	logTable [256]byte
+)
+
+func init() {
+	x := 1
+	for i := 0; i < 255; i++ {
+		expTable[i] = byte(x)
+		expTable[i+255] = byte(x)
+		logTable[x] = byte(i)
+
+		x <<= 1
+		if x&0x100 != 0 {
+			x ^= fieldPolynomial
+		}
+	}
+}
+
+// gfMul returns the product of a and b.
+func gfMul(a, b byte) byte {
+	if a == 0 || b == 0 {
+		return 0
+	}
+
+	return expTable[int(logTable[a])+int(logTable[b])]
+}
+
+// gfDiv returns the quotient of a and b, where b must not be zero.
+func gfDiv(a, b byte) byte {
+	if b == 0 {
+		panic("erasure: division by zero")
+	}
+	if a == 0 {
+		return 0
+	}
+
+	return expTable[int(logTable[a])+255-int(logTable[b])]
+}
+
+// gfExp returns a raised to the power of n.
+func gfExp(a byte, n int) byte {
+	if n == 0 {
+		return 1
+	}
+	if a == 0 {
+		return 0
+	}
+
+	return expTable[int(logTable[a])*n%255]
+}
+
+// gfMulAdd adds the product of c and every byte of in to the corresponding byte of out.
+func gfMulAdd(c byte, in, out []byte) {
+	switch c {
+	case 0:
+		return
+	case 1:
+		for i, b := range in {
+			out[i] ^= b
+		}
+		return
+	}
+
+	logC := int(logTable[c])
+	for i, b := range in {
+		if b != 0 {
+			out[i] ^= expTable[logC+int(logTable[b])]
+		}
+	}
+}
@@ -0,0 +1,309 @@
+package erasure
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mwantia/vfs/data"
+	"github.com/mwantia/vfs/mount/backend"
+)
+
+// ScrubReport summarizes a single scrub of all shards.
+type ScrubReport struct {
+	Objects     int      `json:"objects"`               // Objects and directories verified across all shards
+	Stripes     int      `json:"stripes"`               // Stripes verified across all objects
+	Missing     int      `json:"missing"`               // Objects and directories, which were missing from a shard
+	Corrupt     int      `json:"corrupt"`               // Blocks and headers, which were corrupt or outdated
+	Repaired    int      `json:"repaired"`              // Objects and directories rebuilt within a shard
+	Removed     int      `json:"removed"`               // Remnants removed from shards, which were deleted from the other shards
+	Lost        []string `json:"lost,omitempty"`        // Objects with too few intact blocks to be reconstructed
+	Unavailable []string `json:"unavailable,omitempty"` // Shards, which failed and were excluded from the scrub
+}
+
+// scrub contains the state of a single scrub.
+type scrub struct {
+	eb     *ErasureBackend
+	broken []bool
+	report *ScrubReport
+}
+
+// Scrub verifies every block and header of all objects within all shards and rebuilds missing, corrupt
+// or outdated ones from the remaining shards. Remnants of objects held by fewer shards than data shards are removed.
+func (eb *ErasureBackend) Scrub(ctx context.Context) (*ScrubReport, error) {
+	eb.scrubMu.Lock()
+	defer eb.scrubMu.Unlock()
+
+	s := &scrub{
+		eb:     eb,
+		broken: make([]bool, len(eb.shards)),
+		report: &ScrubReport{},
+	}
+
+	eb.nsMu.Lock()
+	namespaces := make([]string, 0, len(eb.namespaces))
+	for namespace := range eb.namespaces {
+		namespaces = append(namespaces, namespace)
+	}
+	eb.nsMu.Unlock()
+	slices.Sort(namespaces)
+
+	for _, namespace := range namespaces {
+		if err := s.scrubDirectory(ctx, namespace, ""); err != nil {
+			return s.report, err
+		}
+	}
+
+	return s.report, nil
+}
+
+// scrubDirectory scrubs all children of the directory key listed by any shard.
+func (s *scrub) scrubDirectory(ctx context.Context, namespace, key string) error {
+	listings := make([][]*data.FileStat, len(s.eb.shards))
+	errs := s.eb.fanout(func(i int, storage backend.ObjectStorageBackend) error {
+		if s.broken[i] {
+			return nil
+		}
+
+		var err error
+		listings[i], err = storage.ListObjects(ctx, namespace, key)
+		return err
+	})
+
+	prefix := ""
+	if key != "" {
+		prefix = key + "/"
+	}
+
+	seen := make(map[string]bool)
+	var children []string
+	for i, stats := range listings {
+		if isFailure(errs[i]) {
+			s.fail(i, errs[i])
+			continue
+		}
+		for _, stat := range stats {
+			// Backends return either the name or the full key of each entry
+			name := strings.TrimSuffix(strings.TrimPrefix(strings.TrimPrefix(stat.Key, prefix), "/"), "/")
+			if name == "" || stat.Key == key || seen[name] {
+				continue
+			}
+			seen[name] = true
+			children = append(children, prefix+name)
+		}
+	}
+	slices.Sort(children)
+
+	for _, child := range children {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		dir, err := s.scrubEntry(ctx, namespace, child)
+		if err != nil {
+			return err
+		}
+		if dir {
+			if err := s.scrubDirectory(ctx, namespace, child); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// scrubEntry verifies key within all shards and returns true, if it is a directory.
+// All writes are paused, so the entry cannot change during the verification.
+func (s *scrub) scrubEntry(ctx context.Context, namespace, key string) (bool, error) {
+	s.eb.mu.Lock()
+	defer s.eb.mu.Unlock()
+
+	obj, err := s.eb.load(ctx, namespace, key)
+	if err == data.ErrNotExist {
+		s.removeRemnants(ctx, namespace, key)
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to load '%s': %w", key, err)
+	}
+
+	s.report.Objects++
+	for i, current := range obj.shards {
+		if isFailure(current.err) {
+			s.fail(i, current.err)
+		}
+	}
+
+	if obj.header == nil {
+		for i, current := range obj.shards {
+			if s.broken[i] || (current.err == nil && current.stat.Mode.IsDir()) {
+				continue
+			}
+
+			s.report.Missing++
+			if _, err := s.eb.replace(ctx, s.eb.shards[i], namespace, key, obj.stat.Mode); err != nil {
+				s.fail(i, err)
+				continue
+			}
+			s.report.Repaired++
+		}
+		return true, nil
+	}
+
+	return false, s.repairObject(ctx, namespace, key, obj)
+}
+
+// repairObject rewrites all blocks and headers of key, which are missing, corrupt or outdated within a shard.
+func (s *scrub) repairObject(ctx context.Context, namespace, key string, obj *object) error {
+	h := obj.header
+	repaired := make([]bool, len(s.eb.shards))
+	for i, current := range obj.shards {
+		if s.broken[i] || (current.err == nil && !current.stat.Mode.IsDir()) {
+			continue
+		}
+
+		// Shards missing the object are recreated and rebuilt entirely
+		s.report.Missing++
+		stat, err := s.eb.replace(ctx, s.eb.shards[i], namespace, key, obj.stat.Mode)
+		if err != nil {
+			s.fail(i, err)
+			continue
+		}
+		obj.shards[i] = shard{stat: stat}
+		repaired[i] = true
+	}
+
+	stripes := h.stripes(h.size)
+	s.report.Stripes += int(stripes)
+	for j := int64(0); j < stripes; j++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		stripe, err := s.eb.readStripe(ctx, namespace, key, obj, j)
+		if err != nil {
+			s.report.Lost = append(s.report.Lost, key)
+			return nil
+		}
+
+		for i, intact := range stripe.intact {
+			if intact || s.broken[i] || obj.shards[i].err != nil {
+				continue
+			}
+			if !repaired[i] {
+				s.report.Corrupt++
+			}
+
+			block := encodeBlock(nil, stripe.chunks[i], stripe.generation)
+			if _, err := s.eb.shards[i].WriteObject(ctx, namespace, key, h.blockOffset(j), block); err != nil {
+				s.fail(i, err)
+				continue
+			}
+			repaired[i] = true
+		}
+	}
+
+	for i, current := range obj.shards {
+		if s.broken[i] || current.err != nil {
+			continue
+		}
+
+		if current.header == nil || current.header.generation != h.generation || current.header.size != h.size || current.header.chunkSize != h.chunkSize {
+			if !repaired[i] {
+				s.report.Corrupt++
+			}
+			if err := writeHeader(ctx, s.eb.shards[i], namespace, key, h, i); err != nil {
+				s.fail(i, err)
+				continue
+			}
+			repaired[i] = true
+		}
+
+		// Blocks of stripes beyond the size are left behind by truncates missed by the shard
+		if length := h.blockOffset(stripes); current.stat.Size > length {
+			if err := s.eb.shards[i].TruncateObject(ctx, namespace, key, length); err != nil {
+				s.fail(i, err)
+				continue
+			}
+			repaired[i] = true
+		}
+	}
+
+	for i := range repaired {
+		if repaired[i] && !s.broken[i] {
+			s.report.Repaired++
+		}
+	}
+	return nil
+}
+
+// removeRemnants removes key from all shards still holding it, since it has been deleted from the other shards.
+func (s *scrub) removeRemnants(ctx context.Context, namespace, key string) {
+	for i, storage := range s.eb.shards {
+		if s.broken[i] {
+			continue
+		}
+
+		if _, err := storage.HeadObject(ctx, namespace, key); err != nil {
+			if isFailure(err) {
+				s.fail(i, err)
+			}
+			continue
+		}
+		if err := storage.DeleteObject(ctx, namespace, key, true); err != nil && err != data.ErrNotExist {
+			s.fail(i, err)
+			continue
+		}
+		s.report.Removed++
+	}
+}
+
+// fail excludes the shard with index i from the remaining scrub.
+func (s *scrub) fail(i int, err error) {
+	if s.broken[i] {
+		return
+	}
+
+	s.broken[i] = true
+	s.report.Unavailable = append(s.report.Unavailable, fmt.Sprintf("%s: %v", s.eb.shards[i].Name(), err))
+}
+
+// startScrub starts the background scrub, which runs until stopScrub is called.
+func (eb *ErasureBackend) startScrub(interval time.Duration) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	eb.scrubCancel, eb.scrubDone = cancel, done
+
+	go func() {
+		defer close(done)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				// Lost objects and failing shards are reported by explicit scrubs
+				eb.Scrub(ctx)
+			}
+		}
+	}()
+}
+
+// stopScrub stops the background scrub and waits until a running scrub has been cancelled.
+func (eb *ErasureBackend) stopScrub() {
+	eb.mu.Lock()
+	cancel, done := eb.scrubCancel, eb.scrubDone
+	eb.scrubCancel, eb.scrubDone = nil, nil
+	eb.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+		<-done
+	}
+}
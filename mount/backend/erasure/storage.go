@@ -0,0 +1,397 @@
+package erasure
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/mwantia/vfs/data"
+	"github.com/mwantia/vfs/mount/backend"
+)
+
+func (eb *ErasureBackend) CreateObject(ctx context.Context, namespace, key string, mode data.FileMode) (*data.FileStat, error) {
+	eb.mu.Lock()
+	defer eb.mu.Unlock()
+
+	eb.trackNamespace(namespace)
+
+	if _, err := eb.load(ctx, namespace, key); err != data.ErrNotExist {
+		if err != nil {
+			return nil, err
+		}
+		return nil, data.ErrExist
+	}
+
+	h := eb.newHeader()
+	stats := make([]*data.FileStat, len(eb.shards))
+	errs := eb.fanout(func(i int, storage backend.ObjectStorageBackend) error {
+		stat, err := eb.replace(ctx, storage, namespace, key, mode)
+		if err != nil {
+			return err
+		}
+		if !mode.IsDir() {
+			if err := writeHeader(ctx, storage, namespace, key, h, i); err != nil {
+				return err
+			}
+		}
+
+		stats[i] = stat
+		return nil
+	})
+	if err := eb.acknowledge(errs); err != nil {
+		return nil, err
+	}
+
+	for _, stat := range stats {
+		if stat != nil {
+			result := *stat
+			result.Size = 0
+			result.ETag = ""
+			return &result, nil
+		}
+	}
+	return nil, data.ErrNotExist
+}
+
+func (eb *ErasureBackend) ReadObject(ctx context.Context, namespace, key string, offset int64, dat []byte) (int, error) {
+	eb.mu.RLock()
+	defer eb.mu.RUnlock()
+
+	eb.trackNamespace(namespace)
+
+	obj, err := eb.load(ctx, namespace, key)
+	if err != nil {
+		return 0, err
+	}
+	if obj.header == nil {
+		return 0, data.ErrIsDirectory
+	}
+	if offset >= obj.header.size {
+		return 0, io.EOF
+	}
+
+	h := obj.header
+	stripeSize := h.stripeSize()
+	end := min(offset+int64(len(dat)), h.size)
+	for i := offset / stripeSize; i*stripeSize < end; i++ {
+		s, err := eb.readStripe(ctx, namespace, key, obj, i)
+		if err != nil {
+			return 0, err
+		}
+
+		for d := int64(0); d < int64(eb.codec.dataShards); d++ {
+			start := i*stripeSize + d*h.chunkSize
+			from := max(offset, start)
+			to := min(end, start+h.chunkSize)
+			if from < to {
+				copy(dat[from-offset:to-offset], s.chunks[d][from-start:to-start])
+			}
+		}
+	}
+
+	return int(end - offset), nil
+}
+
+func (eb *ErasureBackend) WriteObject(ctx context.Context, namespace, key string, offset int64, dat []byte) (int, error) {
+	eb.mu.Lock()
+	defer eb.mu.Unlock()
+
+	eb.trackNamespace(namespace)
+
+	obj, err := eb.load(ctx, namespace, key)
+	if err != nil {
+		return 0, err
+	}
+	if obj.header == nil {
+		return 0, data.ErrIsDirectory
+	}
+	if offset < 0 {
+		return 0, data.ErrInvalid
+	}
+	if len(dat) == 0 {
+		return 0, nil
+	}
+
+	h := obj.header
+	end := offset + int64(len(dat))
+	if err := eb.rewrite(ctx, namespace, key, obj, min(offset, h.size)/h.stripeSize(), h.stripes(end), max(h.size, end), offset, dat); err != nil {
+		return 0, err
+	}
+
+	return len(dat), nil
+}
+
+func (eb *ErasureBackend) DeleteObject(ctx context.Context, namespace, key string, force bool) error {
+	eb.mu.Lock()
+	defer eb.mu.Unlock()
+
+	eb.trackNamespace(namespace)
+
+	obj, err := eb.load(ctx, namespace, key)
+	if err != nil {
+		return err
+	}
+
+	// All shards are checked first, so a directory is never removed from only some of them
+	if obj.header == nil && !force {
+		children, err := eb.listObjects(ctx, namespace, key)
+		if err != nil {
+			return err
+		}
+		if len(children) > 0 {
+			return data.ErrDirectoryNotEmpty
+		}
+	}
+
+	errs := eb.fanout(func(i int, storage backend.ObjectStorageBackend) error {
+		// Remnants of children only held by few shards are removed as well
+		if err := storage.DeleteObject(ctx, namespace, key, true); err != nil && err != data.ErrNotExist {
+			return err
+		}
+		return nil
+	})
+
+	return eb.acknowledge(errs)
+}
+
+func (eb *ErasureBackend) ListObjects(ctx context.Context, namespace, key string) ([]*data.FileStat, error) {
+	eb.mu.RLock()
+	defer eb.mu.RUnlock()
+
+	eb.trackNamespace(namespace)
+
+	return eb.listObjects(ctx, namespace, key)
+}
+
+func (eb *ErasureBackend) HeadObject(ctx context.Context, namespace, key string) (*data.FileStat, error) {
+	eb.mu.RLock()
+	defer eb.mu.RUnlock()
+
+	eb.trackNamespace(namespace)
+
+	obj, err := eb.load(ctx, namespace, key)
+	if err != nil {
+		return nil, err
+	}
+
+	return obj.stat, nil
+}
+
+func (eb *ErasureBackend) TruncateObject(ctx context.Context, namespace, key string, size int64) error {
+	eb.mu.Lock()
+	defer eb.mu.Unlock()
+
+	eb.trackNamespace(namespace)
+
+	obj, err := eb.load(ctx, namespace, key)
+	if err != nil {
+		return err
+	}
+	if obj.header == nil {
+		return data.ErrIsDirectory
+	}
+	if size < 0 {
+		return data.ErrInvalid
+	}
+
+	h := obj.header
+	if size == h.size {
+		return nil
+	}
+	if size < h.size {
+		// Only the last remaining stripe is rewritten, so its content beyond the new size is cleared
+		return eb.rewrite(ctx, namespace, key, obj, size/h.stripeSize(), h.stripes(size), size, 0, nil)
+	}
+	// Content beyond the size is always cleared, so only new stripes need to be written
+	return eb.rewrite(ctx, namespace, key, obj, h.stripes(h.size), h.stripes(size), size, 0, nil)
+}
+
+// listObjects merges the entries of key listed by all shards, which are held by at least as many shards as data shards.
+func (eb *ErasureBackend) listObjects(ctx context.Context, namespace, key string) ([]*data.FileStat, error) {
+	listings := make([][]*data.FileStat, len(eb.shards))
+	errs := eb.fanout(func(i int, storage backend.ObjectStorageBackend) error {
+		var err error
+		listings[i], err = storage.ListObjects(ctx, namespace, key)
+		return err
+	})
+
+	prefix := ""
+	if key != "" {
+		prefix = key + "/"
+	}
+
+	available := 0
+	var failure error
+	counts := make(map[string]int)
+	entries := make(map[string]*data.FileStat)
+	var children []string
+	for i, stats := range listings {
+		if errs[i] != nil {
+			if isFailure(errs[i]) {
+				failure = errs[i]
+			}
+			continue
+		}
+
+		available++
+		for _, stat := range stats {
+			// Backends return either the name or the full key of each entry
+			child := key
+			if stat.Key != key {
+				child = prefix + strings.TrimSuffix(strings.TrimPrefix(strings.TrimPrefix(stat.Key, prefix), "/"), "/")
+			}
+			if counts[child] == 0 {
+				entries[child] = stat
+				children = append(children, child)
+			}
+			counts[child]++
+		}
+	}
+
+	if available < eb.codec.dataShards {
+		if failure != nil {
+			return nil, fmt.Errorf("%w: failed to list '%s': %w", ErrTooFewShards, key, failure)
+		}
+		return nil, data.ErrNotExist
+	}
+
+	stats := make([]*data.FileStat, 0, len(children))
+	for _, child := range children {
+		if counts[child] < eb.codec.dataShards {
+			continue
+		}
+
+		stat := entries[child]
+		if !stat.Mode.IsDir() {
+			obj, err := eb.load(ctx, namespace, child)
+			if err != nil {
+				if err == data.ErrNotExist {
+					continue
+				}
+				return nil, err
+			}
+
+			logical := *stat
+			logical.Size = obj.stat.Size
+			logical.ETag = ""
+			stat = &logical
+		}
+		stats = append(stats, stat)
+	}
+
+	return stats, nil
+}
+
+// rewrite replaces the stripes from first to last with their content overlaid by dat at offset
+// and updates the header of all shards to size bytes. Stripes beyond the size are removed from all shards.
+func (eb *ErasureBackend) rewrite(ctx context.Context, namespace, key string, obj *object, first, last, size, offset int64, dat []byte) error {
+	h := obj.header
+	generation := h.generation + 1
+	stripeSize := h.stripeSize()
+	stripes := h.stripes(h.size)
+
+	content := make([]byte, stripeSize)
+	chunks := make([][]byte, len(eb.shards))
+	blocks := make([][]byte, len(eb.shards))
+	for i := first; i < last; i++ {
+		clear(content)
+		if i < stripes {
+			s, err := eb.readStripe(ctx, namespace, key, obj, i)
+			if err != nil {
+				return err
+			}
+			for d := 0; d < eb.codec.dataShards; d++ {
+				copy(content[int64(d)*h.chunkSize:], s.chunks[d])
+			}
+		}
+
+		start := i * stripeSize
+		from := max(offset, start)
+		to := min(offset+int64(len(dat)), start+stripeSize)
+		if from < to {
+			copy(content[from-start:], dat[from-offset:to-offset])
+		}
+		// Content beyond the size is always cleared, so growing the object never exposes previous content
+		if size < start+stripeSize {
+			clear(content[max(size-start, 0):])
+		}
+
+		for d := 0; d < eb.codec.dataShards; d++ {
+			chunks[d] = content[int64(d)*h.chunkSize : int64(d+1)*h.chunkSize]
+		}
+		for p := eb.codec.dataShards; p < len(chunks); p++ {
+			if chunks[p] == nil {
+				chunks[p] = make([]byte, h.chunkSize)
+			}
+		}
+		eb.codec.encode(chunks)
+
+		for index, chunk := range chunks {
+			blocks[index] = encodeBlock(blocks[index], chunk, generation)
+		}
+	}
+
+	updated := *h
+	updated.size = size
+	updated.generation = generation
+	errs := eb.fanout(func(i int, storage backend.ObjectStorageBackend) error {
+		if s := obj.shards[i]; s.err != nil {
+			return s.err
+		} else if s.stat.Mode.IsDir() {
+			return data.ErrIsDirectory
+		}
+
+		if len(blocks[i]) > 0 {
+			if _, err := storage.WriteObject(ctx, namespace, key, h.blockOffset(first), blocks[i]); err != nil {
+				return err
+			}
+		}
+		if err := writeHeader(ctx, storage, namespace, key, &updated, i); err != nil {
+			return err
+		}
+		if remaining := updated.stripes(size); remaining < stripes {
+			return storage.TruncateObject(ctx, namespace, key, updated.blockOffset(remaining))
+		}
+		return nil
+	})
+
+	return eb.acknowledge(errs)
+}
+
+// replace creates the object at key within storage and replaces remnants of an object deleted from other shards.
+func (eb *ErasureBackend) replace(ctx context.Context, storage backend.ObjectStorageBackend, namespace, key string, mode data.FileMode) (*data.FileStat, error) {
+	stat, err := storage.HeadObject(ctx, namespace, key)
+	switch {
+	case err == data.ErrNotExist:
+	case err != nil:
+		return nil, err
+	case stat.Mode.IsDir() && mode.IsDir():
+		return stat, nil
+	default:
+		if err := storage.DeleteObject(ctx, namespace, key, true); err != nil {
+			return nil, err
+		}
+	}
+
+	return storage.CreateObject(ctx, namespace, key, mode)
+}
+
+// newHeader returns the header of an empty object created with the current configuration.
+func (eb *ErasureBackend) newHeader() *header {
+	return &header{
+		dataShards:   eb.codec.dataShards,
+		parityShards: eb.codec.parityShards,
+		chunkSize:    int64(eb.config.ChunkSize),
+		generation:   1,
+	}
+}
+
+// writeHeader writes h as header of the shard with index i.
+func writeHeader(ctx context.Context, storage backend.ObjectStorageBackend, namespace, key string, h *header, i int) error {
+	shardHeader := *h
+	shardHeader.index = i
+
+	_, err := storage.WriteObject(ctx, namespace, key, 0, shardHeader.encode())
+	return err
+}
@@ -0,0 +1,15 @@
+package backend
+
+import (
+	"context"
+
+	"github.com/mwantia/vfs/data"
+)
+
+// GarbageCollectBackend is an optional capability for backends, which keep content separate from the
+// entries referencing it (e.g. data rows referenced by metadata) and may therefore leak unreferenced content.
+type GarbageCollectBackend interface {
+	// CollectGarbage marks all content still referenced and sweeps everything else.
+	// Nothing is removed if opts.DryRun is set; the returned report lists all orphans either way.
+	CollectGarbage(ctx context.Context, opts *data.GCOptions) (*data.GCReport, error)
+}
@@ -0,0 +1,25 @@
+package backend
+
+import (
+	"context"
+
+	"github.com/mwantia/vfs/data"
+)
+
+// LockBackend is an optional capability for backends, which are able to share locks between multiple processes.
+// Mounts without a LockBackend fall back to in-process locks.
+type LockBackend interface {
+	// AcquireLock tries to acquire lock once, without waiting for conflicting locks to be released.
+	// Returns data.ErrLocked if any unexpired lock of another owner conflicts with lock.
+	AcquireLock(ctx context.Context, namespace string, lock *data.LockInfo) error
+
+	// RefreshLock extends the lease of lock, so it doesn't expire.
+	// Returns data.ErrLockNotHeld if the lock has already expired or been released.
+	RefreshLock(ctx context.Context, namespace string, lock *data.LockInfo) error
+
+	// ReleaseLock releases lock. Releasing an expired lock is not an error.
+	ReleaseLock(ctx context.Context, namespace string, lock *data.LockInfo) error
+
+	// ListLocks returns all unexpired locks held on key.
+	ListLocks(ctx context.Context, namespace string, key string) ([]*data.LockInfo, error)
+}
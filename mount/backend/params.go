@@ -0,0 +1,49 @@
+package backend
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// ParamType defines how the value of a parameter is interpreted.
+type ParamType string
+
+const (
+	ParamTypeString   ParamType = "string"
+	ParamTypeBool     ParamType = "bool"
+	ParamTypeInt      ParamType = "int"
+	ParamTypeDuration ParamType = "duration"
+)
+
+// RedactedValue replaces the values of secret parameters before they are displayed.
+const RedactedValue = "xxxxx"
+
+// ParamSpec describes a single parameter accepted by a backend factory.
+type ParamSpec struct {
+	Name        string    // Name of the parameter (e.g. "path")
+	Description string    // Human-readable description
+	Type        ParamType // Type of the value (empty is treated as string)
+	Required    bool      // Whether the parameter must be set
+	Default     string    // Value used if the parameter is not set
+	Secret      bool      // Whether the value contains credentials and should not be displayed
+}
+
+// validate checks if value can be parsed as type.
+func (pt ParamType) validate(value string) error {
+	switch pt {
+	case "", ParamTypeString:
+		return nil
+	case ParamTypeBool:
+		_, err := strconv.ParseBool(value)
+		return err
+	case ParamTypeInt:
+		_, err := strconv.ParseInt(value, 10, 64)
+		return err
+	case ParamTypeDuration:
+		_, err := time.ParseDuration(value)
+		return err
+	default:
+		return fmt.Errorf("unsupported parameter type '%s'", pt)
+	}
+}
@@ -15,7 +15,7 @@ import (
 //
 // Layer 1: In-memory B-tree for fast key → ID lookups (keys map)
 // Layer 2: PostgreSQL metadata table (vfs_metadata) for filesystem metadata
-// Layer 3: PostgreSQL data table (vfs_data) for file content with reference counting
+// Layer 3: PostgreSQL data tables (vfs_data, vfs_chunks) for chunked file content with reference counting
 //
 // This architecture enables:
 // - Fast path lookups via B-tree (O(log n))
@@ -89,13 +89,29 @@ func (pb *PostgresBackend) initSchema(ctx context.Context) error {
 		`CREATE INDEX IF NOT EXISTS idx_vfs_metadata_attributes ON vfs_metadata USING GIN(attributes)`,
 		`CREATE TABLE IF NOT EXISTS vfs_data (
 			id TEXT PRIMARY KEY,
-			content BYTEA NOT NULL,
 			size BIGINT NOT NULL CHECK(size >= 0),
 			ref_count INTEGER NOT NULL DEFAULT 0,
 			created_at BIGINT NOT NULL,
 			last_accessed BIGINT NOT NULL
 		)`,
 		`CREATE INDEX IF NOT EXISTS idx_vfs_data_ref_count ON vfs_data(ref_count)`,
+		`CREATE TABLE IF NOT EXISTS vfs_chunks (
+			data_id TEXT NOT NULL REFERENCES vfs_data(id) ON DELETE CASCADE,
+			chunk_index BIGINT NOT NULL CHECK(chunk_index >= 0),
+			content BYTEA NOT NULL,
+			PRIMARY KEY(data_id, chunk_index)
+		)`,
+		`CREATE TABLE IF NOT EXISTS vfs_locks (
+			id TEXT PRIMARY KEY,
+			key TEXT NOT NULL,
+			owner TEXT NOT NULL,
+			type INTEGER NOT NULL,
+			lock_offset BIGINT NOT NULL DEFAULT 0,
+			lock_length BIGINT NOT NULL DEFAULT 0,
+			lease BIGINT NOT NULL,
+			expires BIGINT NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_vfs_locks_key ON vfs_locks(key)`,
 	}
 
 	conn, err := pb.pool.Acquire(ctx)
@@ -111,7 +127,7 @@ func (pb *PostgresBackend) initSchema(ctx context.Context) error {
 		}
 	}
 
-	return nil
+	return migrateChunks(ctx, pb.pool)
 }
 
 // Name returns the identifier name defined for this backend
@@ -170,6 +186,6 @@ func (pb *PostgresBackend) GetCapabilities() *backend.BackendCapabilities {
 			backend.CapabilityObjectStorage,
 			backend.CapabilityMetadata,
 		},
-		MaxObjectSize: 5242880, // 5 MB
+		MaxObjectSize: 1099511627776, // 1 TB
 	}
 }
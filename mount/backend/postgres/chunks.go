@@ -0,0 +1,215 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// chunkSize is the maximum size of a single content chunk in vfs_chunks.
+// Chunks may be shorter or missing entirely, in which case the gap reads as zeros.
+const chunkSize = 256 * 1024
+
+// chunkIndex returns the index of the chunk containing offset.
+func chunkIndex(offset int64) int64 {
+	return offset / chunkSize
+}
+
+// ensureData creates the data row for id, if it doesn't exist yet.
+func ensureData(ctx context.Context, q querier, id string) error {
+	now := time.Now().Unix()
+	if _, err := q.Exec(ctx, `
+		INSERT INTO vfs_data (id, size, ref_count, created_at, last_accessed)
+		VALUES ($1, 0, 1, $2, $3)
+		ON CONFLICT (id) DO NOTHING
+	`, id, now, now); err != nil {
+		return fmt.Errorf("failed to create data: %w", err)
+	}
+
+	return nil
+}
+
+// setDataSize updates the size stored within the data row for id.
+func setDataSize(ctx context.Context, q querier, id string, size int64) error {
+	if _, err := q.Exec(ctx, `
+		UPDATE vfs_data SET size = $1, last_accessed = $2 WHERE id = $3
+	`, size, time.Now().Unix(), id); err != nil {
+		return fmt.Errorf("failed to update data size: %w", err)
+	}
+
+	return nil
+}
+
+// releaseData decrements the reference count of id and removes all content that is no longer referenced.
+func releaseData(ctx context.Context, q querier, id string) error {
+	if _, err := q.Exec(ctx, "UPDATE vfs_data SET ref_count = ref_count - 1 WHERE id = $1", id); err != nil {
+		return fmt.Errorf("failed to update ref count: %w", err)
+	}
+	// Chunks are removed by cascade
+	if _, err := q.Exec(ctx, "DELETE FROM vfs_data WHERE ref_count <= 0"); err != nil {
+		return fmt.Errorf("failed to cleanup data: %w", err)
+	}
+
+	return nil
+}
+
+// readChunks reads the content of id at offset into dat, limited to size.
+// Only the chunks overlapping the requested range are loaded.
+func readChunks(ctx context.Context, q querier, id string, offset int64, dat []byte, size int64) (int, error) {
+	end := min(offset+int64(len(dat)), size)
+	if end <= offset {
+		return 0, nil
+	}
+
+	n := int(end - offset)
+	clear(dat[:n])
+
+	rows, err := q.Query(ctx, `
+		SELECT chunk_index, content FROM vfs_chunks
+		WHERE data_id = $1 AND chunk_index BETWEEN $2 AND $3
+	`, id, chunkIndex(offset), chunkIndex(end-1))
+	if err != nil {
+		return 0, fmt.Errorf("failed to query chunks: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var index int64
+		var content []byte
+		if err := rows.Scan(&index, &content); err != nil {
+			return 0, fmt.Errorf("failed to scan chunk: %w", err)
+		}
+
+		start := index * chunkSize
+		from := max(offset, start)
+		to := min(end, start+int64(len(content)))
+		if from < to {
+			copy(dat[from-offset:to-offset], content[from-start:to-start])
+		}
+	}
+
+	return n, rows.Err()
+}
+
+// writeChunks writes dat at offset into the content of id.
+// Only the chunks overlapping the written range are read and replaced.
+func writeChunks(ctx context.Context, q querier, id string, offset int64, dat []byte) error {
+	end := offset + int64(len(dat))
+	for index := chunkIndex(offset); index*chunkSize < end; index++ {
+		start := index * chunkSize
+		from := max(offset, start) - start
+		part := dat[max(offset, start)-offset : min(end, start+chunkSize)-offset]
+
+		var content []byte
+		err := q.QueryRow(ctx,
+			"SELECT content FROM vfs_chunks WHERE data_id = $1 AND chunk_index = $2",
+			id, index).Scan(&content)
+		if err != nil && err != pgx.ErrNoRows {
+			return fmt.Errorf("failed to query chunk: %w", err)
+		}
+
+		if required := from + int64(len(part)); int64(len(content)) < required {
+			grown := make([]byte, required)
+			copy(grown, content)
+			content = grown
+		}
+		copy(content[from:], part)
+
+		if _, err := q.Exec(ctx, `
+			INSERT INTO vfs_chunks (data_id, chunk_index, content) VALUES ($1, $2, $3)
+			ON CONFLICT (data_id, chunk_index) DO UPDATE SET content = EXCLUDED.content
+		`, id, index, content); err != nil {
+			return fmt.Errorf("failed to write chunk: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// truncateChunks drops all content of id beyond size.
+// Growing content requires no changes, since missing chunks read as zeros.
+func truncateChunks(ctx context.Context, q querier, id string, size int64) error {
+	if size <= 0 {
+		if _, err := q.Exec(ctx, "DELETE FROM vfs_chunks WHERE data_id = $1", id); err != nil {
+			return fmt.Errorf("failed to delete chunks: %w", err)
+		}
+		return nil
+	}
+
+	last := chunkIndex(size - 1)
+	if _, err := q.Exec(ctx,
+		"DELETE FROM vfs_chunks WHERE data_id = $1 AND chunk_index > $2",
+		id, last); err != nil {
+		return fmt.Errorf("failed to delete chunks: %w", err)
+	}
+
+	keep := size - last*chunkSize
+	if _, err := q.Exec(ctx, `
+		UPDATE vfs_chunks SET content = substring(content FROM 1 FOR $1)
+		WHERE data_id = $2 AND chunk_index = $3 AND length(content) > $1
+	`, keep, id, last); err != nil {
+		return fmt.Errorf("failed to truncate chunk: %w", err)
+	}
+
+	return nil
+}
+
+// writeContent replaces the complete content stored for id.
+func writeContent(ctx context.Context, q querier, id string, content []byte) error {
+	if err := ensureData(ctx, q, id); err != nil {
+		return err
+	}
+	if _, err := q.Exec(ctx, "DELETE FROM vfs_chunks WHERE data_id = $1", id); err != nil {
+		return fmt.Errorf("failed to delete chunks: %w", err)
+	}
+	if err := writeChunks(ctx, q, id, 0, content); err != nil {
+		return err
+	}
+
+	return setDataSize(ctx, q, id, int64(len(content)))
+}
+
+// migrateChunks moves the content of databases created before content was chunked
+// from the content column of vfs_data into vfs_chunks and drops the column afterwards.
+func migrateChunks(ctx context.Context, pool *pgxpool.Pool) error {
+	var legacy bool
+	if err := pool.QueryRow(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM information_schema.columns
+			WHERE table_schema = current_schema() AND table_name = 'vfs_data' AND column_name = 'content'
+		)
+	`).Scan(&legacy); err != nil {
+		return fmt.Errorf("failed to inspect schema: %w", err)
+	}
+	if !legacy {
+		return nil
+	}
+
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Chunks are sliced within PostgreSQL, so no content has to be loaded into memory
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO vfs_chunks (data_id, chunk_index, content)
+		SELECT id, chunk_index, substring(content FROM (chunk_index * $1 + 1)::int FOR $1)
+		FROM vfs_data, generate_series(0, (size - 1) / $1) AS chunk_index
+		WHERE size > 0
+	`, int64(chunkSize)); err != nil {
+		return fmt.Errorf("failed to migrate content: %w", err)
+	}
+	if _, err := tx.Exec(ctx, "ALTER TABLE vfs_data DROP COLUMN content"); err != nil {
+		return fmt.Errorf("failed to drop content column: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit migration: %w", err)
+	}
+
+	return nil
+}
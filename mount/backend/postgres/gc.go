@@ -0,0 +1,74 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/mwantia/vfs/data"
+)
+
+// CollectGarbage removes data rows no longer referenced by any metadata (or with a reference count of zero).
+// Chunks are removed by cascade. Data rows accessed within the grace period are kept,
+// since other processes may share the database. VACUUM is run afterwards if opts.Vacuum is set.
+func (pb *PostgresBackend) CollectGarbage(ctx context.Context, opts *data.GCOptions) (*data.GCReport, error) {
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+
+	report := &data.GCReport{DryRun: opts.DryRun}
+	if err := pb.pool.QueryRow(ctx, "SELECT COUNT(*) FROM vfs_data").Scan(&report.Scanned); err != nil {
+		return nil, fmt.Errorf("failed to count data: %w", err)
+	}
+
+	// Mark: every data row is referenced by the metadata sharing its id
+	rows, err := pb.pool.Query(ctx, `
+		SELECT d.id, COALESCE(SUM(length(c.content)), 0)::BIGINT, d.last_accessed
+		FROM vfs_data d LEFT JOIN vfs_chunks c ON c.data_id = d.id
+		WHERE d.ref_count <= 0 OR NOT EXISTS (SELECT 1 FROM vfs_metadata m WHERE m.id = d.id)
+		GROUP BY d.id, d.last_accessed
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query orphaned data: %w", err)
+	}
+
+	var expired []string
+	for rows.Next() {
+		var id string
+		var size, accessed int64
+		if err := rows.Scan(&id, &size, &accessed); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan orphaned data: %w", err)
+		}
+
+		deferred := !opts.Expired(time.Unix(accessed, 0))
+		report.Orphan("vfs_data/"+id, size, deferred)
+		if !deferred {
+			expired = append(expired, id)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query orphaned data: %w", err)
+	}
+
+	if opts.DryRun {
+		return report, nil
+	}
+
+	// Sweep
+	if len(expired) > 0 {
+		if _, err := pb.pool.Exec(ctx, "DELETE FROM vfs_data WHERE id = ANY($1)", expired); err != nil {
+			return nil, fmt.Errorf("failed to delete orphaned data: %w", err)
+		}
+	}
+
+	if opts.Vacuum {
+		// VACUUM can't run inside a transaction, so it's executed directly on the pool
+		if _, err := pb.pool.Exec(ctx, "VACUUM vfs_data, vfs_chunks"); err != nil {
+			return nil, fmt.Errorf("failed to vacuum: %w", err)
+		}
+		report.Vacuumed = true
+	}
+
+	return report, nil
+}
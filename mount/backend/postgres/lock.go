@@ -0,0 +1,131 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/mwantia/vfs/data"
+	"github.com/mwantia/vfs/mount/backend"
+)
+
+// AcquireLock tries to acquire lock once, without waiting for conflicting locks to be released.
+// All lock changes of a key are serialized using a transaction-scoped advisory lock on the key,
+// so locks are shared between all processes using the same database.
+// Keys are stored with their namespace, so mounts of different namespaces never conflict.
+func (pb *PostgresBackend) AcquireLock(ctx context.Context, namespace string, lock *data.LockInfo) error {
+	key := backend.NamespacedKey(namespace, lock.Key)
+	return pb.withKeyLock(ctx, key, func(tx pgx.Tx) error {
+		locks, err := queryLocks(ctx, tx, namespace, lock.Key)
+		if err != nil {
+			return err
+		}
+		for _, held := range locks {
+			if held.Conflicts(lock) {
+				return data.ErrLocked
+			}
+		}
+
+		_, err = tx.Exec(ctx, `
+			INSERT INTO vfs_locks (id, key, owner, type, lock_offset, lock_length, lease, expires)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		`, lock.ID, key, lock.Owner, int(lock.Type), lock.Offset, lock.Length, lock.Lease, lock.Expires.UnixNano())
+		return err
+	})
+}
+
+// RefreshLock extends the expiry of lock, if it hasn't expired yet.
+func (pb *PostgresBackend) RefreshLock(ctx context.Context, namespace string, lock *data.LockInfo) error {
+	return pb.withKeyLock(ctx, backend.NamespacedKey(namespace, lock.Key), func(tx pgx.Tx) error {
+		now := time.Now()
+		expires := now.Add(time.Duration(lock.Lease))
+
+		tag, err := tx.Exec(ctx, `
+			UPDATE vfs_locks SET expires = $1 WHERE id = $2 AND expires > $3
+		`, expires.UnixNano(), lock.ID, now.UnixNano())
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return data.ErrLockNotHeld
+		}
+
+		lock.Expires = expires
+		return nil
+	})
+}
+
+// ReleaseLock removes lock. Releasing an expired lock is not an error.
+func (pb *PostgresBackend) ReleaseLock(ctx context.Context, namespace string, lock *data.LockInfo) error {
+	_, err := pb.pool.Exec(ctx, `DELETE FROM vfs_locks WHERE id = $1`, lock.ID)
+	return err
+}
+
+// ListLocks returns all unexpired locks held on key.
+func (pb *PostgresBackend) ListLocks(ctx context.Context, namespace string, key string) ([]*data.LockInfo, error) {
+	return queryLocks(ctx, pb.pool, namespace, key)
+}
+
+// withKeyLock runs fn within a transaction holding the advisory lock of the namespaced key.
+// Expired locks of key are removed before fn is called.
+func (pb *PostgresBackend) withKeyLock(ctx context.Context, key string, fn func(tx pgx.Tx) error) error {
+	tx, err := pb.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext('vfs_locks:' || $1))`, key); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM vfs_locks WHERE key = $1 AND expires <= $2`, key, time.Now().UnixNano()); err != nil {
+		return err
+	}
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// lockQuerier is implemented by both connection pools and transactions.
+type lockQuerier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
+// queryLocks returns all unexpired locks of key within namespace.
+func queryLocks(ctx context.Context, q lockQuerier, namespace, key string) ([]*data.LockInfo, error) {
+	rows, err := q.Query(ctx, `
+		SELECT id, key, owner, type, lock_offset, lock_length, lease, expires
+		FROM vfs_locks WHERE key = $1 AND expires > $2
+	`, backend.NamespacedKey(namespace, key), time.Now().UnixNano())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var locks []*data.LockInfo
+	for rows.Next() {
+		var lockType int
+		var expires int64
+
+		lock := &data.LockInfo{}
+		if err := rows.Scan(&lock.ID, &lock.Key, &lock.Owner, &lockType, &lock.Offset, &lock.Length, &lock.Lease, &expires); err != nil {
+			return nil, err
+		}
+
+		// The stored key includes the namespace, which is not part of the lock itself
+		lock.Key = key
+		lock.Type = data.LockType(lockType)
+		lock.Expires = time.Unix(0, expires)
+		locks = append(locks, lock)
+	}
+
+	return locks, rows.Err()
+}
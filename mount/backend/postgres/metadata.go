@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/mwantia/vfs/data"
 	"github.com/mwantia/vfs/mount/backend"
 )
@@ -30,34 +31,14 @@ func (pb *PostgresBackend) CreateMeta(ctx context.Context, meta *data.Metadata)
 		meta.CreateTime = time.Now()
 	}
 
-	// Serialize attributes to JSONB
-	var attributesJSON []byte
-	if len(meta.Attributes) > 0 {
-		var err error
-		attributesJSON, err = json.Marshal(meta.Attributes)
-		if err != nil {
-			return fmt.Errorf("failed to marshal attributes: %w", err)
-		}
-	}
-
 	conn, err := pb.pool.Acquire(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to acquire connection: %w", err)
 	}
 	defer conn.Release()
 
-	contentType := string(meta.ContentType)
-	// Insert into database
-	_, err = conn.Exec(ctx, `
-		INSERT INTO vfs_metadata (id, key, mode, size, uid, gid, modify_time, access_time, create_time, content_type, etag, attributes)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
-	`, meta.ID, meta.Key, int(meta.Mode), meta.Size,
-		nullInt64(meta.UID), nullInt64(meta.GID),
-		meta.ModifyTime.Unix(), meta.AccessTime.Unix(), meta.CreateTime.Unix(),
-		nullString(contentType), nullString(meta.ETag), attributesJSON)
-
-	if err != nil {
-		return fmt.Errorf("failed to insert metadata: %w", err)
+	if err := insertMeta(ctx, conn, meta); err != nil {
+		return err
 	}
 
 	// Update B-tree
@@ -78,59 +59,12 @@ func (pb *PostgresBackend) ReadMeta(ctx context.Context, key string) (*data.Meta
 	}
 	defer conn.Release()
 
-	// Query database
-	var meta data.Metadata
-	var uid, gid *int64
-	var contentType, etag *string
-	var attributesJSON []byte
-	var modifyTime, accessTime, createTime int64
-
-	err = conn.QueryRow(ctx, `
-		SELECT id, key, mode, size, uid, gid, modify_time, access_time, create_time, content_type, etag, attributes
-		FROM vfs_metadata WHERE id = $1
-	`, id).Scan(&meta.ID, &meta.Key, &meta.Mode, &meta.Size,
-		&uid, &gid, &modifyTime, &accessTime, &createTime,
-		&contentType, &etag, &attributesJSON)
-
-	if err == pgx.ErrNoRows {
-		return nil, data.ErrNotExist
-	}
+	meta, err := readMetaByID(ctx, conn, id)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query metadata: %w", err)
-	}
-
-	// Convert timestamps
-	meta.ModifyTime = time.Unix(modifyTime, 0)
-	meta.AccessTime = time.Unix(accessTime, 0)
-	meta.CreateTime = time.Unix(createTime, 0)
-
-	// Convert nullable fields
-	if uid != nil {
-		meta.UID = *uid
-	}
-	if gid != nil {
-		meta.GID = *gid
-	}
-	if contentType != nil {
-		meta.ContentType = data.ContentType(*contentType)
-	}
-	if etag != nil {
-		meta.ETag = *etag
-	}
-
-	// Deserialize attributes
-	if len(attributesJSON) > 0 {
-		if err := json.Unmarshal(attributesJSON, &meta.Attributes); err != nil {
-			meta.Attributes = make(map[string]string)
-		}
-	} else {
-		meta.Attributes = make(map[string]string)
+		return nil, err
 	}
 
-	// Update access time (in memory only for performance)
-	meta.AccessTime = time.Now()
-
-	return &meta, nil
+	return meta, nil
 }
 
 func (pb *PostgresBackend) UpdateMeta(ctx context.Context, key string, update *data.MetadataUpdate) error {
@@ -152,38 +86,13 @@ func (pb *PostgresBackend) UpdateMeta(ctx context.Context, key string, update *d
 		return fmt.Errorf("failed to apply update: %w", err)
 	}
 
-	// Serialize attributes to JSONB
-	var attributesJSON []byte
-	if len(meta.Attributes) > 0 {
-		attributesJSON, err = json.Marshal(meta.Attributes)
-		if err != nil {
-			return fmt.Errorf("failed to marshal attributes: %w", err)
-		}
-	}
-
 	conn, err := pb.pool.Acquire(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to acquire connection: %w", err)
 	}
 	defer conn.Release()
 
-	contentType := string(meta.ContentType)
-	// Update database
-	_, err = conn.Exec(ctx, `
-		UPDATE vfs_metadata
-		SET mode = $1, size = $2, uid = $3, gid = $4,
-		    modify_time = $5, access_time = $6, content_type = $7, etag = $8, attributes = $9
-		WHERE id = $10
-	`, int(meta.Mode), meta.Size,
-		nullInt64(meta.UID), nullInt64(meta.GID),
-		meta.ModifyTime.Unix(), meta.AccessTime.Unix(),
-		nullString(contentType), nullString(meta.ETag), attributesJSON, id)
-
-	if err != nil {
-		return fmt.Errorf("failed to update metadata: %w", err)
-	}
-
-	return nil
+	return updateMetaRow(ctx, conn, id, meta)
 }
 
 func (pb *PostgresBackend) DeleteMeta(ctx context.Context, key string) error {
@@ -212,20 +121,9 @@ func (pb *PostgresBackend) DeleteMeta(ctx context.Context, key string) error {
 		return fmt.Errorf("failed to delete metadata: %w", err)
 	}
 
-	// Decrement ref count for associated data
-	_, err = tx.Exec(ctx, `
-		UPDATE vfs_data
-		SET ref_count = ref_count - 1
-		WHERE id = $1
-	`, id)
-	if err != nil && err != pgx.ErrNoRows {
-		return fmt.Errorf("failed to update ref count: %w", err)
-	}
-
-	// Delete data with ref_count = 0
-	_, err = tx.Exec(ctx, "DELETE FROM vfs_data WHERE ref_count <= 0")
-	if err != nil {
-		return fmt.Errorf("failed to cleanup data: %w", err)
+	// Decrement ref count for associated data and remove it, if no longer referenced
+	if err := releaseData(ctx, tx, id); err != nil {
+		return err
 	}
 
 	// Commit transaction
@@ -414,3 +312,123 @@ func nullString(val string) *string {
 	}
 	return &val
 }
+
+// querier is implemented by both pooled connections and transactions, so statements can be shared by transactions.
+type querier interface {
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// insertMeta inserts the metadata row for meta without updating the B-tree.
+func insertMeta(ctx context.Context, q querier, meta *data.Metadata) error {
+	// Serialize attributes to JSONB
+	var attributesJSON []byte
+	if len(meta.Attributes) > 0 {
+		var err error
+		attributesJSON, err = json.Marshal(meta.Attributes)
+		if err != nil {
+			return fmt.Errorf("failed to marshal attributes: %w", err)
+		}
+	}
+
+	contentType := string(meta.ContentType)
+	// Insert into database
+	_, err := q.Exec(ctx, `
+		INSERT INTO vfs_metadata (id, key, mode, size, uid, gid, modify_time, access_time, create_time, content_type, etag, attributes)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+	`, meta.ID, meta.Key, int(meta.Mode), meta.Size,
+		nullInt64(meta.UID), nullInt64(meta.GID),
+		meta.ModifyTime.Unix(), meta.AccessTime.Unix(), meta.CreateTime.Unix(),
+		nullString(contentType), nullString(meta.ETag), attributesJSON)
+
+	if err != nil {
+		return fmt.Errorf("failed to insert metadata: %w", err)
+	}
+
+	return nil
+}
+
+// readMetaByID reads the metadata row with the specified id.
+func readMetaByID(ctx context.Context, q querier, id string) (*data.Metadata, error) {
+	var meta data.Metadata
+	var uid, gid *int64
+	var contentType, etag *string
+	var attributesJSON []byte
+	var modifyTime, accessTime, createTime int64
+
+	err := q.QueryRow(ctx, `
+		SELECT id, key, mode, size, uid, gid, modify_time, access_time, create_time, content_type, etag, attributes
+		FROM vfs_metadata WHERE id = $1
+	`, id).Scan(&meta.ID, &meta.Key, &meta.Mode, &meta.Size,
+		&uid, &gid, &modifyTime, &accessTime, &createTime,
+		&contentType, &etag, &attributesJSON)
+
+	if err == pgx.ErrNoRows {
+		return nil, data.ErrNotExist
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query metadata: %w", err)
+	}
+
+	// Convert timestamps
+	meta.ModifyTime = time.Unix(modifyTime, 0)
+	meta.AccessTime = time.Unix(accessTime, 0)
+	meta.CreateTime = time.Unix(createTime, 0)
+
+	// Convert nullable fields
+	if uid != nil {
+		meta.UID = *uid
+	}
+	if gid != nil {
+		meta.GID = *gid
+	}
+	if contentType != nil {
+		meta.ContentType = data.ContentType(*contentType)
+	}
+	if etag != nil {
+		meta.ETag = *etag
+	}
+
+	// Deserialize attributes
+	if len(attributesJSON) > 0 {
+		if err := json.Unmarshal(attributesJSON, &meta.Attributes); err != nil {
+			meta.Attributes = make(map[string]string)
+		}
+	} else {
+		meta.Attributes = make(map[string]string)
+	}
+
+	return &meta, nil
+}
+
+// updateMetaRow writes all mutable fields of meta into the metadata row with the specified id.
+func updateMetaRow(ctx context.Context, q querier, id string, meta *data.Metadata) error {
+	// Serialize attributes to JSONB
+	var attributesJSON []byte
+	if len(meta.Attributes) > 0 {
+		var err error
+		attributesJSON, err = json.Marshal(meta.Attributes)
+		if err != nil {
+			return fmt.Errorf("failed to marshal attributes: %w", err)
+		}
+	}
+
+	contentType := string(meta.ContentType)
+	// Update database
+	_, err := q.Exec(ctx, `
+		UPDATE vfs_metadata
+		SET mode = $1, size = $2, uid = $3, gid = $4,
+		    modify_time = $5, access_time = $6, content_type = $7, etag = $8, attributes = $9
+		WHERE id = $10
+	`, int(meta.Mode), meta.Size,
+		nullInt64(meta.UID), nullInt64(meta.GID),
+		meta.ModifyTime.Unix(), meta.AccessTime.Unix(),
+		nullString(contentType), nullString(meta.ETag), attributesJSON, id)
+
+	if err != nil {
+		return fmt.Errorf("failed to update metadata: %w", err)
+	}
+
+	return nil
+}
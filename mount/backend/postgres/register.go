@@ -0,0 +1,30 @@
+package postgres
+
+import (
+	"net/url"
+
+	"github.com/mwantia/vfs/mount/backend"
+)
+
+func init() {
+	backend.Register(&backend.Registration{
+		Name:        "postgres",
+		Description: "PostgreSQL database storing objects and metadata",
+		Schemes:     []string{"postgresql"},
+		Params: []backend.ParamSpec{
+			{Name: "dsn", Description: "connection string or URL", Required: true, Secret: true},
+		},
+		Factory: func(params backend.FactoryParams) (backend.Backend, error) {
+			dsn, err := params.Required("postgres", "dsn")
+			if err != nil {
+				return nil, err
+			}
+
+			return NewPostgresBackend(dsn)
+		},
+		// The URL is passed unchanged as connection string, including all query parameters
+		ParseURL: func(u *url.URL) (backend.FactoryParams, error) {
+			return backend.FactoryParams{"dsn": u.String()}, nil
+		},
+	})
+}
@@ -0,0 +1,79 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/mwantia/vfs/data"
+	"github.com/mwantia/vfs/mount/backend"
+)
+
+// RenameObject moves the object at oldKey including all nested objects to newKey within a single transaction.
+// Content is referenced by id, so it's never copied.
+func (pb *PostgresBackend) RenameObject(ctx context.Context, namespace, oldKey, newKey string) error {
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+
+	if oldKey == "" || newKey == "" || strings.HasPrefix(newKey, oldKey+"/") {
+		return data.ErrInvalid
+	}
+	if oldKey == newKey {
+		return nil
+	}
+
+	if _, exists := pb.keys.Get(oldKey); !exists {
+		return data.ErrNotExist
+	}
+	if _, exists := pb.keys.Get(newKey); exists {
+		return data.ErrExist
+	}
+	// Verify parent directory exists
+	if parentKey := path.Dir(newKey); parentKey != "." && parentKey != "" {
+		parentMeta, err := pb.ReadMeta(ctx, parentKey)
+		if err != nil {
+			return data.ErrNotExist
+		}
+		if !parentMeta.Mode.IsDir() {
+			return data.ErrNotDirectory
+		}
+	}
+
+	// Collect all affected keys by their id
+	renamed := make(map[string]string)
+	ids := make(map[string]string)
+	pb.keys.Ascend(oldKey, func(key string, id string) bool {
+		if newChildKey, affected := backend.RenamedKey(key, oldKey, newKey); affected {
+			renamed[key] = newChildKey
+			ids[key] = id
+			return true
+		}
+		// Keys are ordered, so all nested keys follow shortly after oldKey
+		return strings.HasPrefix(key, oldKey)
+	})
+
+	tx, err := pb.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	for key, newChildKey := range renamed {
+		if _, err := tx.Exec(ctx, "UPDATE vfs_metadata SET key = $1 WHERE id = $2", newChildKey, ids[key]); err != nil {
+			return fmt.Errorf("failed to rename metadata: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	// Only update the B-tree, once the transaction has been committed
+	for key, newChildKey := range renamed {
+		pb.keys.Delete(key)
+		pb.keys.Set(newChildKey, ids[key])
+	}
+
+	return nil
+}
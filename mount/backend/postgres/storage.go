@@ -8,7 +8,6 @@ import (
 	"strings"
 	"time"
 
-	"github.com/jackc/pgx/v5"
 	"github.com/mwantia/vfs/data"
 	"github.com/mwantia/vfs/data/errors"
 )
@@ -64,27 +63,7 @@ func (pb *PostgresBackend) ReadObject(ctx context.Context, namespace, key string
 	}
 	defer conn.Release()
 
-	// Query data from database
-	var content []byte
-	err = conn.QueryRow(ctx,
-		"SELECT content FROM vfs_data WHERE id = $1",
-		meta.ID).Scan(&content)
-
-	if err == pgx.ErrNoRows {
-		// No data stored yet (empty file)
-		return 0, nil
-	}
-	if err != nil {
-		return 0, fmt.Errorf("failed to query data: %w", err)
-	}
-
-	// Calculate how many bytes we can actually read
-	available := meta.Size - offset
-	toRead := min(int64(len(dat)), available)
-
-	// Copy data from content
-	n := copy(dat, content[offset:offset+toRead])
-	return n, nil
+	return readChunks(ctx, conn, meta.ID, offset, dat, meta.Size)
 }
 
 func (pb *PostgresBackend) WriteObject(ctx context.Context, namespace, key string, offset int64, dat []byte) (int, error) {
@@ -115,49 +94,15 @@ func (pb *PostgresBackend) WriteObject(ctx context.Context, namespace, key strin
 	}
 	defer tx.Rollback(ctx)
 
-	// Get existing content or create new
-	var content []byte
-	var refCount int
-	err = tx.QueryRow(ctx,
-		"SELECT content, ref_count FROM vfs_data WHERE id = $1",
-		meta.ID).Scan(&content, &refCount)
-
-	if err != nil && err != pgx.ErrNoRows {
-		return 0, fmt.Errorf("failed to query existing data: %w", err)
-	}
-
-	// Determine the new required size
-	newSize := max(writeEnd, meta.Size)
-
-	// Expand buffer if needed
-	if int64(len(content)) < newSize {
-		newBuffer := make([]byte, newSize)
-		copy(newBuffer, content)
-		content = newBuffer
+	// Only the chunks covered by the write are replaced
+	if err := ensureData(ctx, tx, meta.ID); err != nil {
+		return 0, err
 	}
-
-	// Write the data
-	copy(content[offset:], dat)
-
-	now := time.Now().Unix()
-
-	// Insert or update content
-	if err == pgx.ErrNoRows {
-		// Insert new data
-		_, err = tx.Exec(ctx, `
-			INSERT INTO vfs_data (id, content, size, ref_count, created_at, last_accessed)
-			VALUES ($1, $2, $3, 1, $4, $5)
-		`, meta.ID, content, newSize, now, now)
-	} else {
-		// Update existing data
-		_, err = tx.Exec(ctx, `
-			UPDATE vfs_data SET content = $1, size = $2, last_accessed = $3
-			WHERE id = $4
-		`, content, newSize, now, meta.ID)
+	if err := writeChunks(ctx, tx, meta.ID, offset, dat); err != nil {
+		return 0, err
 	}
-
-	if err != nil {
-		return 0, fmt.Errorf("failed to write data: %w", err)
+	if err := setDataSize(ctx, tx, meta.ID, max(writeEnd, meta.Size)); err != nil {
+		return 0, err
 	}
 
 	// Update metadata size in transaction
@@ -355,42 +300,15 @@ func (pb *PostgresBackend) TruncateObject(ctx context.Context, namespace, key st
 	}
 	defer tx.Rollback(ctx)
 
-	// Get existing content
-	var content []byte
-	err = tx.QueryRow(ctx,
-		"SELECT content FROM vfs_data WHERE id = $1",
-		meta.ID).Scan(&content)
-
-	now := time.Now().Unix()
-
-	if err == pgx.ErrNoRows {
-		// No existing data - create new empty content of specified size
-		content = make([]byte, size)
-		_, err = tx.Exec(ctx, `
-			INSERT INTO vfs_data (id, content, size, ref_count, created_at, last_accessed)
-			VALUES ($1, $2, $3, 1, $4, $5)
-		`, meta.ID, content, size, now, now)
-	} else if err != nil {
-		return fmt.Errorf("failed to query existing data: %w", err)
-	} else {
-		// Adjust content size
-		if size < int64(len(content)) {
-			content = content[:size]
-		} else {
-			newData := make([]byte, size)
-			copy(newData, content)
-			content = newData
-		}
-
-		// Update existing data
-		_, err = tx.Exec(ctx, `
-			UPDATE vfs_data SET content = $1, size = $2, last_accessed = $3
-			WHERE id = $4
-		`, content, size, now, meta.ID)
+	// Trailing chunks are dropped, while growing content is read as zeros
+	if err := ensureData(ctx, tx, meta.ID); err != nil {
+		return err
 	}
-
-	if err != nil {
-		return fmt.Errorf("failed to write data: %w", err)
+	if err := truncateChunks(ctx, tx, meta.ID, size); err != nil {
+		return err
+	}
+	if err := setDataSize(ctx, tx, meta.ID, size); err != nil {
+		return err
 	}
 
 	// Update metadata size in transaction
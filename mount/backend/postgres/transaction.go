@@ -0,0 +1,176 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/mwantia/vfs/data"
+	"github.com/mwantia/vfs/mount/backend"
+	"github.com/tidwall/btree"
+)
+
+// ApplyChanges applies all changes within a single SQL transaction over vfs_metadata and vfs_data.
+// The B-tree is only replaced after the commit succeeded, while the write lock keeps readers from observing partial state.
+func (pb *PostgresBackend) ApplyChanges(ctx context.Context, namespace string, changes []*backend.Change) error {
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+
+	tx, err := pb.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	err = backend.CheckBases(changes, func(key string) (*data.Metadata, error) {
+		id, exists := pb.keys.Get(key)
+		if !exists {
+			return nil, data.ErrNotExist
+		}
+		return readMetaByID(ctx, tx, id)
+	})
+	if err != nil {
+		return err
+	}
+
+	// Changes are applied to a copy, so a failed transaction leaves the B-tree untouched
+	keys := pb.keys.Copy()
+	for _, change := range changes {
+		if err := applyChange(ctx, tx, keys, change); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	pb.keys = keys
+	return nil
+}
+
+// applyChange applies a single change within tx and updates keys accordingly.
+func applyChange(ctx context.Context, tx pgx.Tx, keys *btree.Map[string, string], change *backend.Change) error {
+	id, exists := keys.Get(change.Key)
+
+	var meta *data.Metadata
+	if exists {
+		var err error
+		if meta, err = readMetaByID(ctx, tx, id); err != nil {
+			return err
+		}
+	}
+
+	now := time.Now()
+	switch change.Type {
+	case backend.ChangeWrite:
+		if meta == nil {
+			return createKey(ctx, tx, keys, change)
+		}
+		if meta.Mode.IsDir() {
+			return data.ErrIsDirectory
+		}
+
+		if err := writeContent(ctx, tx, id, change.Content); err != nil {
+			return err
+		}
+
+		meta.Size = int64(len(change.Content))
+		meta.ModifyTime = now
+		return updateMetaRow(ctx, tx, id, meta)
+
+	case backend.ChangeMkdir:
+		if meta != nil {
+			return data.ErrExist
+		}
+		return createKey(ctx, tx, keys, change)
+
+	case backend.ChangeDelete:
+		if meta == nil {
+			return data.ErrNotExist
+		}
+		if meta.Mode.IsDir() && hasChildren(keys, change.Key) {
+			return data.ErrDirectoryNotEmpty
+		}
+
+		if _, err := tx.Exec(ctx, "DELETE FROM vfs_metadata WHERE id = $1", id); err != nil {
+			return fmt.Errorf("failed to delete metadata: %w", err)
+		}
+		if err := releaseData(ctx, tx, id); err != nil {
+			return err
+		}
+
+		keys.Delete(change.Key)
+		return nil
+
+	case backend.ChangeUpdate:
+		if meta == nil {
+			return data.ErrNotExist
+		}
+
+		meta.ModifyTime = now
+		if _, err := change.Update.Apply(meta); err != nil {
+			return fmt.Errorf("failed to apply update: %w", err)
+		}
+		return updateMetaRow(ctx, tx, id, meta)
+	}
+
+	return data.ErrInvalid
+}
+
+// createKey creates the file or directory described by change within tx.
+func createKey(ctx context.Context, tx pgx.Tx, keys *btree.Map[string, string], change *backend.Change) error {
+	if change.Metadata == nil {
+		return data.ErrInvalid
+	}
+	// Verify parent directory exists
+	if parentKey := path.Dir(change.Key); parentKey != "." && parentKey != "" {
+		parentID, exists := keys.Get(parentKey)
+		if !exists {
+			return data.ErrNotExist
+		}
+		parentMeta, err := readMetaByID(ctx, tx, parentID)
+		if err != nil {
+			return err
+		}
+		if !parentMeta.Mode.IsDir() {
+			return data.ErrNotDirectory
+		}
+	}
+
+	meta := change.Metadata.Clone()
+	meta.Key = change.Key
+	meta.Size = int64(len(change.Content))
+	if meta.ID == "" {
+		meta.ID = data.NewMetadata(meta.Key, meta.Mode, meta.Size).ID
+	}
+	if meta.CreateTime.IsZero() {
+		meta.CreateTime = time.Now()
+	}
+
+	if err := insertMeta(ctx, tx, meta); err != nil {
+		return err
+	}
+	if change.Type == backend.ChangeWrite {
+		if err := writeContent(ctx, tx, meta.ID, change.Content); err != nil {
+			return err
+		}
+	}
+
+	keys.Set(change.Key, meta.ID)
+	return nil
+}
+
+// hasChildren checks if any key is nested below key.
+func hasChildren(keys *btree.Map[string, string], key string) bool {
+	found := false
+	keys.Ascend(key+"/", func(child string, _ string) bool {
+		found = strings.HasPrefix(child, key+"/")
+		return false
+	})
+
+	return found
+}
@@ -0,0 +1,281 @@
+package backend
+
+import (
+	"fmt"
+	"maps"
+	"net/url"
+	"slices"
+	"strconv"
+	"sync"
+
+	"github.com/mwantia/vfs/data/errors"
+)
+
+// Factory creates a new backend instance from the specified parameters.
+// Parameters have already been validated against the registered schema.
+type Factory func(params FactoryParams) (Backend, error)
+
+// FactoryParams contains the parameters passed to a backend factory.
+type FactoryParams map[string]string
+
+// URLParser converts a backend URL into factory parameters.
+type URLParser func(u *url.URL) (FactoryParams, error)
+
+// Registration describes a backend made available by the registry.
+type Registration struct {
+	Name        string      // Unique name of the backend (also accepted as URL scheme)
+	Description string      // Human-readable description
+	Schemes     []string    // Additional URL schemes resolved to this backend
+	Params      []ParamSpec // Schema of all supported parameters (nil accepts any parameter)
+	Factory     Factory     // Creates the backend from validated parameters
+	ParseURL    URLParser   // Converts URLs into parameters (nil only uses the query parameters)
+}
+
+var (
+	registryMu    sync.RWMutex
+	registrations = make(map[string]*Registration)
+	schemes       = make(map[string]*Registration)
+)
+
+// Register makes a backend available by its name and URL schemes.
+// Backends usually register themselves within init(), so importing the package is enough.
+// Panics if the registration is incomplete or its name or schemes are registered twice.
+func Register(registration *Registration) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if registration == nil || registration.Name == "" || registration.Factory == nil {
+		panic("backend: register with empty name or nil factory")
+	}
+	if _, exists := registrations[registration.Name]; exists {
+		panic(fmt.Sprintf("backend: register called twice for '%s'", registration.Name))
+	}
+
+	for _, scheme := range append([]string{registration.Name}, registration.Schemes...) {
+		if _, exists := schemes[scheme]; exists {
+			panic(fmt.Sprintf("backend: register called twice for scheme '%s'", scheme))
+		}
+		schemes[scheme] = registration
+	}
+
+	registrations[registration.Name] = registration
+}
+
+// RegisterFactory makes a backend factory available by the provided name, without parameter schema.
+func RegisterFactory(name string, factory Factory) {
+	Register(&Registration{
+		Name:    name,
+		Factory: factory,
+	})
+}
+
+// GetRegistration returns the backend registered with the provided name.
+func GetRegistration(name string) (*Registration, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	registration, exists := registrations[name]
+	return registration, exists
+}
+
+// ListFactories returns the sorted names of all registered backends.
+func ListFactories() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	return slices.Sorted(maps.Keys(registrations))
+}
+
+// CreateBackend creates a new backend instance using the factory registered with the provided name.
+func CreateBackend(name string, params FactoryParams) (Backend, error) {
+	registration, exists := GetRegistration(name)
+	if !exists {
+		return nil, errors.BackendUnknown(nil, name)
+	}
+
+	validated, err := registration.Validate(params)
+	if err != nil {
+		return nil, err
+	}
+
+	return registration.Factory(validated)
+}
+
+// CreateObjectStorageBackend creates a new backend instance, that must be usable as object storage.
+func CreateObjectStorageBackend(name string, params FactoryParams) (ObjectStorageBackend, error) {
+	created, err := CreateBackend(name, params)
+	if err != nil {
+		return nil, err
+	}
+
+	storage, ok := created.(ObjectStorageBackend)
+	if !ok {
+		return nil, errors.BackendIncompatible(nil, name)
+	}
+
+	return storage, nil
+}
+
+// ParseURL resolves the backend registered for the URL scheme and converts the URL into its parameters
+// (e.g. "sqlite:///var/vfs.db" or "s3://bucket?endpoint=localhost:9000").
+func ParseURL(raw string) (string, FactoryParams, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", nil, errors.BackendURLInvalid(err, raw)
+	}
+	if u.Scheme == "" {
+		return "", nil, errors.BackendURLInvalid(nil, u.Redacted())
+	}
+
+	registryMu.RLock()
+	registration, exists := schemes[u.Scheme]
+	registryMu.RUnlock()
+
+	if !exists {
+		return "", nil, errors.BackendUnknown(nil, u.Scheme)
+	}
+
+	if registration.ParseURL == nil {
+		return registration.Name, QueryParams(u), nil
+	}
+
+	params, err := registration.ParseURL(u)
+	if err != nil {
+		return "", nil, errors.BackendURLInvalid(err, u.Redacted())
+	}
+
+	return registration.Name, params, nil
+}
+
+// RedactURL returns the URL with its password and the values of all secret query parameters removed,
+// so it can be displayed (e.g. "consul://127.0.0.1:8500?token=xxxxx").
+func RedactURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return raw
+	}
+
+	registryMu.RLock()
+	registration, exists := schemes[u.Scheme]
+	registryMu.RUnlock()
+
+	if exists && u.RawQuery != "" {
+		query, redacted := u.Query(), false
+		for _, spec := range registration.Params {
+			if spec.Secret && query.Has(spec.Name) {
+				query.Set(spec.Name, RedactedValue)
+				redacted = true
+			}
+		}
+		// Only re-encode if required, since encoding sorts the query parameters
+		if redacted {
+			u.RawQuery = query.Encode()
+		}
+	}
+
+	return u.Redacted()
+}
+
+// CreateBackendFromURL creates a new backend instance from the URL.
+func CreateBackendFromURL(raw string) (Backend, error) {
+	name, params, err := ParseURL(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	return CreateBackend(name, params)
+}
+
+// QueryParams converts all query parameters of the URL into factory parameters.
+// Only the last value is used for repeated parameters.
+func QueryParams(u *url.URL) FactoryParams {
+	params := FactoryParams{}
+	for key, values := range u.Query() {
+		if len(values) > 0 {
+			params[key] = values[len(values)-1]
+		}
+	}
+
+	return params
+}
+
+// URLPath returns the path of the URL, including the host for relative paths
+// (e.g. "x.db" for "sqlite://x.db" and "/var/x.db" for "sqlite:///var/x.db").
+func URLPath(u *url.URL) string {
+	if u.Opaque != "" {
+		return u.Opaque
+	}
+
+	return u.Host + u.Path
+}
+
+// Validate checks params against the parameter schema and returns a copy including all defaults.
+func (r *Registration) Validate(params FactoryParams) (FactoryParams, error) {
+	validated := maps.Clone(params)
+	if validated == nil {
+		validated = FactoryParams{}
+	}
+	// Registrations without schema accept any parameter
+	if r.Params == nil {
+		return validated, nil
+	}
+
+	for key := range validated {
+		if !slices.ContainsFunc(r.Params, func(spec ParamSpec) bool { return spec.Name == key }) {
+			return nil, errors.BackendParameterUnknown(nil, r.Name, key)
+		}
+	}
+
+	for _, spec := range r.Params {
+		value, exists := validated[spec.Name]
+		if !exists || value == "" {
+			if spec.Required {
+				return nil, errors.BackendParameterMissing(nil, r.Name, spec.Name)
+			}
+			if spec.Default != "" {
+				validated[spec.Name] = spec.Default
+			}
+			continue
+		}
+
+		if err := spec.Type.validate(value); err != nil {
+			return nil, errors.BackendParameterInvalid(err, r.Name, spec.Name)
+		}
+	}
+
+	return validated, nil
+}
+
+// String returns the parameter value or defaultValue, if the parameter is not set.
+func (fp FactoryParams) String(key, defaultValue string) string {
+	if value, exists := fp[key]; exists && value != "" {
+		return value
+	}
+
+	return defaultValue
+}
+
+// Required returns the parameter value or an error, if the parameter is not set.
+func (fp FactoryParams) Required(backend, key string) (string, error) {
+	value, exists := fp[key]
+	if !exists || value == "" {
+		return "", errors.BackendParameterMissing(nil, backend, key)
+	}
+
+	return value, nil
+}
+
+// Bool returns the parameter value parsed as boolean or defaultValue, if the parameter is not set.
+func (fp FactoryParams) Bool(backend, key string, defaultValue bool) (bool, error) {
+	value, exists := fp[key]
+	if !exists || value == "" {
+		return defaultValue, nil
+	}
+
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, errors.BackendParameterInvalid(err, backend, key)
+	}
+
+	return parsed, nil
+}
@@ -0,0 +1,34 @@
+package backend
+
+import (
+	"context"
+	"strings"
+)
+
+// RenameObjectBackend is implemented by object storage backends, which are able to rename objects natively
+// (e.g. without copying their content). Renaming a directory also moves all nested objects.
+type RenameObjectBackend interface {
+	// RenameObject atomically moves the object at oldKey to newKey.
+	// Returns data.ErrExist if newKey already exists and data.ErrNotExist if oldKey or the parent of newKey doesn't exist.
+	RenameObject(ctx context.Context, namespace string, oldKey, newKey string) error
+}
+
+// RenameMetaBackend is implemented by metadata backends, which are able to rename metadata entries natively.
+// Renaming a directory also moves the metadata of all nested entries.
+type RenameMetaBackend interface {
+	// RenameMeta atomically moves the metadata at oldKey to newKey.
+	// Returns data.ErrExist if newKey already exists and data.ErrNotExist if oldKey doesn't exist.
+	RenameMeta(ctx context.Context, namespace string, oldKey, newKey string) error
+}
+
+// RenamedKey returns the new key after moving oldKey to newKey, if key is equal to or nested below oldKey.
+func RenamedKey(key, oldKey, newKey string) (string, bool) {
+	if key == oldKey {
+		return newKey, true
+	}
+	if suffix, found := strings.CutPrefix(key, oldKey+"/"); found {
+		return newKey + "/" + suffix, true
+	}
+
+	return "", false
+}
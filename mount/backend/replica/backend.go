@@ -0,0 +1,176 @@
+package replica
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/mwantia/vfs/mount/backend"
+)
+
+// DefaultResyncInterval is the interval of the background resync, if no interval is defined.
+const DefaultResyncInterval = 5 * time.Minute
+
+// ErrWriteQuorum is returned if a write has been acknowledged by fewer healthy replicas than required.
+var ErrWriteQuorum = errors.New("replica: write quorum not reached")
+
+// ReplicaBackend mirrors objects to multiple object storage backends (e.g. direct and s3):
+//
+// Writes are applied to all replicas in parallel and succeed once the write quorum has been acknowledged
+// by healthy replicas. Reads are served by the fastest healthy replica, falling back to the next one on error.
+// Replicas failing or missing a write are marked degraded and no longer serve reads, until the background
+// resync has repaired all diverged objects by comparing them against a healthy replica.
+type ReplicaBackend struct {
+	mu       sync.RWMutex
+	replicas []*replica
+	config   *ReplicaBackendConfig
+
+	nsMu       sync.Mutex
+	namespaces map[string]struct{} // Namespaces accessed since opening, which are covered by the resync
+	hashes     map[hashKey]string  // Content hashes of replicas cached by etag
+
+	resyncMu     sync.Mutex // Serializes explicit and background resyncs
+	resyncCancel context.CancelFunc
+	resyncDone   <-chan struct{}
+}
+
+// ReplicaBackendConfig contains configuration options for the replicated backend
+type ReplicaBackendConfig struct {
+	// Number of healthy replicas required to acknowledge a write (default: majority of all replicas)
+	WriteQuorum int
+
+	// Interval of the background resync (default: DefaultResyncInterval)
+	// A negative interval disables the background resync, so Resync has to be called explicitly
+	ResyncInterval time.Duration
+
+	// Namespaces covered by the resync in addition to all namespaces accessed since opening
+	Namespaces []string
+}
+
+// NewReplicaBackend creates a backend mirroring all objects to replicas, which are listed by priority.
+// The replicas are owned by the returned backend and opened and closed together with it.
+func NewReplicaBackend(replicas []backend.ObjectStorageBackend, config *ReplicaBackendConfig) (*ReplicaBackend, error) {
+	if len(replicas) == 0 {
+		return nil, fmt.Errorf("at least one replica is required")
+	}
+	if config == nil {
+		config = &ReplicaBackendConfig{}
+	}
+
+	// Set defaults
+	if config.WriteQuorum == 0 {
+		config.WriteQuorum = len(replicas)/2 + 1
+	}
+	if config.WriteQuorum < 1 || config.WriteQuorum > len(replicas) {
+		return nil, fmt.Errorf("write quorum %d must be between 1 and %d", config.WriteQuorum, len(replicas))
+	}
+	if config.ResyncInterval == 0 {
+		config.ResyncInterval = DefaultResyncInterval
+	}
+
+	rb := &ReplicaBackend{
+		config:     config,
+		namespaces: map[string]struct{}{"": {}},
+		hashes:     make(map[hashKey]string),
+	}
+	for _, namespace := range config.Namespaces {
+		rb.namespaces[namespace] = struct{}{}
+	}
+	for i, storage := range replicas {
+		if storage == nil {
+			return nil, fmt.Errorf("replica %d must not be nil", i)
+		}
+		rb.replicas = append(rb.replicas, &replica{
+			index:   i,
+			storage: storage,
+		})
+	}
+
+	return rb, nil
+}
+
+// Returns the identifier name defined for this backend
+func (*ReplicaBackend) Name() string {
+	return "replica"
+}
+
+// Open is part of the lifecycle behavious and gets called when opening this backend.
+// Replicas failing to open are marked degraded, as long as the write quorum can still be reached.
+func (rb *ReplicaBackend) Open(ctx context.Context) error {
+	rb.mu.Lock()
+	defer rb.mu.Unlock()
+
+	healthy := 0
+	for _, r := range rb.replicas {
+		if err := r.storage.Open(ctx); err != nil {
+			r.fail(err)
+			continue
+		}
+		healthy++
+	}
+	if healthy < rb.config.WriteQuorum {
+		return fmt.Errorf("%w: %d of %d replicas opened", ErrWriteQuorum, healthy, len(rb.replicas))
+	}
+
+	if rb.config.ResyncInterval > 0 {
+		rb.startResync(rb.config.ResyncInterval)
+	}
+	return nil
+}
+
+// Close is part of the lifecycle behaviour and gets called when closing this backend.
+func (rb *ReplicaBackend) Close(ctx context.Context) error {
+	// The resync requires the lock, so it has to be stopped first
+	rb.stopResync()
+
+	rb.mu.Lock()
+	defer rb.mu.Unlock()
+
+	var errs []error
+	for _, r := range rb.replicas {
+		if err := r.storage.Close(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("failed to close replica '%s': %w", r.storage.Name(), err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// GetCapabilities returns a list of capabilities supported by this backend.
+func (rb *ReplicaBackend) GetCapabilities() *backend.BackendCapabilities {
+	caps := &backend.BackendCapabilities{
+		Capabilities: []backend.BackendCapability{
+			backend.CapabilityObjectStorage,
+		},
+	}
+	// Objects have to fit into every replica
+	for _, r := range rb.replicas {
+		replicaCaps := r.storage.GetCapabilities()
+		caps.MinObjectSize = max(caps.MinObjectSize, replicaCaps.MinObjectSize)
+		if replicaCaps.MaxObjectSize > 0 && (caps.MaxObjectSize == 0 || replicaCaps.MaxObjectSize < caps.MaxObjectSize) {
+			caps.MaxObjectSize = replicaCaps.MaxObjectSize
+		}
+	}
+
+	return caps
+}
+
+// Status returns the current health of all replicas, ordered by priority.
+func (rb *ReplicaBackend) Status() []ReplicaStatus {
+	statuses := make([]ReplicaStatus, 0, len(rb.replicas))
+	for _, r := range rb.replicas {
+		statuses = append(statuses, r.status())
+	}
+
+	return statuses
+}
+
+// trackNamespace adds namespace to the namespaces covered by the resync.
+func (rb *ReplicaBackend) trackNamespace(namespace string) {
+	rb.nsMu.Lock()
+	defer rb.nsMu.Unlock()
+
+	rb.namespaces[namespace] = struct{}{}
+}
@@ -0,0 +1,156 @@
+package replica
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"io"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/mwantia/vfs/data"
+	"github.com/mwantia/vfs/mount/backend"
+)
+
+// ReplicaStatus describes the current health of a single replica.
+type ReplicaStatus struct {
+	Name     string        `json:"name"`
+	Degraded bool          `json:"degraded"`
+	Error    string        `json:"error,omitempty"`
+	Latency  time.Duration `json:"latency"`
+}
+
+// replica wraps a child backend together with its health.
+type replica struct {
+	index   int
+	storage backend.ObjectStorageBackend
+
+	mu       sync.Mutex
+	degraded bool
+	failures uint64        // Number of failures, used to detect failures during a resync
+	lastErr  error         // Last failure, which caused the replica to be degraded
+	latency  time.Duration // Moving average of the operation latency
+}
+
+// healthy returns true, if the replica is allowed to serve reads and acknowledge writes.
+func (r *replica) healthy() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return !r.degraded
+}
+
+// fail marks the replica as degraded, until it has been repaired by a resync.
+func (r *replica) fail(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.degraded = true
+	r.failures++
+	r.lastErr = err
+}
+
+// recover marks the replica as healthy again, unless it failed again since the resync started.
+func (r *replica) recover(failures uint64) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.failures != failures {
+		return false
+	}
+
+	r.degraded = false
+	r.lastErr = nil
+	return true
+}
+
+// failureCount returns the number of failures of this replica.
+func (r *replica) failureCount() uint64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.failures
+}
+
+// observe adds the duration of an operation to the moving average latency.
+func (r *replica) observe(duration time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.latency == 0 {
+		r.latency = duration
+		return
+	}
+	r.latency = (r.latency*7 + duration) / 8
+}
+
+func (r *replica) status() ReplicaStatus {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	status := ReplicaStatus{
+		Name:     r.storage.Name(),
+		Degraded: r.degraded,
+		Latency:  r.latency,
+	}
+	if r.lastErr != nil {
+		status.Error = r.lastErr.Error()
+	}
+
+	return status
+}
+
+// isFailure returns false for errors describing the requested object, which every replica is expected to return alike.
+// All other errors are considered a failure of the replica itself.
+func isFailure(err error) bool {
+	switch {
+	case err == nil:
+		return false
+	case errors.Is(err, data.ErrNotExist),
+		errors.Is(err, data.ErrExist),
+		errors.Is(err, data.ErrIsDirectory),
+		errors.Is(err, data.ErrNotDirectory),
+		errors.Is(err, data.ErrDirectoryNotEmpty),
+		errors.Is(err, data.ErrPermission),
+		errors.Is(err, data.ErrInvalid),
+		errors.Is(err, io.EOF),
+		errors.Is(err, context.Canceled),
+		errors.Is(err, context.DeadlineExceeded):
+		return false
+	}
+
+	return true
+}
+
+// byLatency returns the replicas ordered by their health and latency, while keeping the priority for equal latencies.
+func (rb *ReplicaBackend) byLatency() []*replica {
+	type candidate struct {
+		r       *replica
+		healthy bool
+		latency time.Duration
+	}
+
+	candidates := make([]candidate, 0, len(rb.replicas))
+	for _, r := range rb.replicas {
+		status := r.status()
+		candidates = append(candidates, candidate{r, !status.Degraded, status.Latency})
+	}
+
+	slices.SortStableFunc(candidates, func(a, b candidate) int {
+		if a.healthy != b.healthy {
+			if a.healthy {
+				return -1
+			}
+			return 1
+		}
+		return cmp.Compare(a.latency, b.latency)
+	})
+
+	ordered := make([]*replica, 0, len(candidates))
+	for _, c := range candidates {
+		ordered = append(ordered, c.r)
+	}
+
+	return ordered
+}
@@ -0,0 +1,386 @@
+package replica
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mwantia/vfs/data"
+	"github.com/mwantia/vfs/mount/backend"
+)
+
+// ResyncReport summarizes a single resync of all replicas.
+type ResyncReport struct {
+	Reference string   `json:"reference"` // Replica used as source of truth
+	Objects   int      `json:"objects"`   // Objects compared between all replicas
+	Repaired  int      `json:"repaired"`  // Objects copied to replicas, which were missing or diverged
+	Removed   int      `json:"removed"`   // Objects removed from replicas, which were missing from the reference
+	Recovered []string `json:"recovered"` // Replicas marked healthy again
+}
+
+// hashKey identifies a content hash of an object, which stays valid as long as the object remains unchanged.
+type hashKey struct {
+	replica   int
+	namespace string
+	key       string
+	etag      string
+	size      int64
+	modified  int64
+}
+
+// resync contains the state of a single resync.
+type resync struct {
+	rb        *ReplicaBackend
+	reference *replica
+	targets   []*replica
+	broken    map[*replica]bool
+	hashes    map[hashKey]string
+	buffer    []byte
+	report    *ResyncReport
+}
+
+// Resync compares all objects of every replica against the first healthy replica and repairs diverged objects.
+// Objects are compared by type and size and by etag or content hash, if the size matches.
+// Degraded replicas are marked healthy again, once all of their objects have been repaired without new failures.
+func (rb *ReplicaBackend) Resync(ctx context.Context) (*ResyncReport, error) {
+	rb.resyncMu.Lock()
+	defer rb.resyncMu.Unlock()
+
+	s := &resync{
+		rb:     rb,
+		broken: make(map[*replica]bool),
+		hashes: make(map[hashKey]string),
+		report: &ResyncReport{},
+	}
+
+	failures := make(map[*replica]uint64)
+	for _, r := range rb.replicas {
+		if s.reference == nil && r.healthy() {
+			s.reference = r
+			continue
+		}
+		failures[r] = r.failureCount()
+		s.targets = append(s.targets, r)
+	}
+	if s.reference == nil {
+		return nil, fmt.Errorf("%w: no healthy replica to resync from", ErrWriteQuorum)
+	}
+	s.report.Reference = s.reference.storage.Name()
+
+	rb.nsMu.Lock()
+	namespaces := make([]string, 0, len(rb.namespaces))
+	for namespace := range rb.namespaces {
+		namespaces = append(namespaces, namespace)
+	}
+	rb.nsMu.Unlock()
+	slices.Sort(namespaces)
+
+	for _, namespace := range namespaces {
+		if err := s.syncDirectory(ctx, namespace, ""); err != nil {
+			return s.report, err
+		}
+	}
+
+	for _, r := range s.targets {
+		if !s.broken[r] && !r.healthy() && r.recover(failures[r]) {
+			s.report.Recovered = append(s.report.Recovered, r.storage.Name())
+		}
+	}
+
+	// Only hashes of objects seen during this resync are kept
+	rb.hashes = s.hashes
+	return s.report, nil
+}
+
+// syncDirectory syncs all children of the directory key and removes children missing from the reference.
+func (s *resync) syncDirectory(ctx context.Context, namespace, key string) error {
+	children, err := list(ctx, s.reference.storage, namespace, key)
+	if err != nil {
+		return fmt.Errorf("failed to list '%s' of reference: %w", key, err)
+	}
+
+	expected := make(map[string]bool, len(children))
+	for _, child := range children {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		expected[child.Key] = true
+		stat, err := s.syncEntry(ctx, namespace, child.Key)
+		if err != nil {
+			return err
+		}
+		if stat != nil && stat.Mode.IsDir() {
+			if err := s.syncDirectory(ctx, namespace, child.Key); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, target := range s.targets {
+		if s.broken[target] {
+			continue
+		}
+
+		existing, err := list(ctx, target.storage, namespace, key)
+		if err != nil {
+			s.fail(target, err)
+			continue
+		}
+		for _, child := range existing {
+			if !expected[child.Key] {
+				if err := s.removeEntry(ctx, target, namespace, child.Key); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// syncEntry compares key of all replicas against the reference and repairs diverged replicas.
+// All writes are paused, so the entry cannot change during the comparison.
+func (s *resync) syncEntry(ctx context.Context, namespace, key string) (*data.FileStat, error) {
+	s.rb.mu.Lock()
+	defer s.rb.mu.Unlock()
+
+	stat, err := s.reference.storage.HeadObject(ctx, namespace, key)
+	if err != nil {
+		if err == data.ErrNotExist {
+			// Removed since listing the directory
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to stat '%s' of reference: %w", key, err)
+	}
+
+	s.report.Objects++
+	for _, target := range s.targets {
+		if s.broken[target] {
+			continue
+		}
+		if err := s.repair(ctx, target, namespace, key, stat); err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			s.fail(target, err)
+		}
+	}
+
+	return stat, nil
+}
+
+// repair replaces the entry key of target, unless it already matches the reference.
+func (s *resync) repair(ctx context.Context, target *replica, namespace, key string, stat *data.FileStat) error {
+	current, err := target.storage.HeadObject(ctx, namespace, key)
+	if err != nil {
+		if err != data.ErrNotExist {
+			return err
+		}
+		current = nil
+	}
+
+	if current != nil && current.Mode.IsDir() != stat.Mode.IsDir() {
+		if err := target.storage.DeleteObject(ctx, namespace, key, true); err != nil {
+			return err
+		}
+		current = nil
+	}
+
+	if stat.Mode.IsDir() {
+		if current == nil {
+			if _, err := target.storage.CreateObject(ctx, namespace, key, stat.Mode); err != nil {
+				return err
+			}
+			s.report.Repaired++
+		}
+		return nil
+	}
+
+	if current != nil {
+		equal, err := s.equal(ctx, target, namespace, key, stat, current)
+		if err != nil || equal {
+			return err
+		}
+	} else if _, err := target.storage.CreateObject(ctx, namespace, key, stat.Mode); err != nil {
+		return err
+	}
+
+	if err := s.copy(ctx, target, namespace, key, stat.Size); err != nil {
+		return err
+	}
+
+	s.report.Repaired++
+	return nil
+}
+
+// removeEntry removes key from target, if it is still missing from the reference.
+func (s *resync) removeEntry(ctx context.Context, target *replica, namespace, key string) error {
+	s.rb.mu.Lock()
+	defer s.rb.mu.Unlock()
+
+	if _, err := s.reference.storage.HeadObject(ctx, namespace, key); err != data.ErrNotExist {
+		if err != nil {
+			return fmt.Errorf("failed to stat '%s' of reference: %w", key, err)
+		}
+		// Created since listing the directory
+		return nil
+	}
+
+	if err := target.storage.DeleteObject(ctx, namespace, key, true); err != nil && err != data.ErrNotExist {
+		s.fail(target, err)
+		return nil
+	}
+
+	s.report.Removed++
+	return nil
+}
+
+// equal returns true, if the content of both objects matches.
+func (s *resync) equal(ctx context.Context, target *replica, namespace, key string, stat, current *data.FileStat) (bool, error) {
+	if stat.Size != current.Size {
+		return false, nil
+	}
+	if stat.ETag != "" && stat.ETag == current.ETag {
+		return true, nil
+	}
+
+	expected, err := s.hash(ctx, s.reference, namespace, key, stat)
+	if err != nil {
+		return false, fmt.Errorf("failed to hash '%s' of reference: %w", key, err)
+	}
+	actual, err := s.hash(ctx, target, namespace, key, current)
+	if err != nil {
+		return false, err
+	}
+
+	return expected == actual, nil
+}
+
+// hash returns the content hash of key, which is cached until the object has been modified.
+func (s *resync) hash(ctx context.Context, r *replica, namespace, key string, stat *data.FileStat) (string, error) {
+	cacheKey := hashKey{
+		replica:   r.index,
+		namespace: namespace,
+		key:       key,
+		etag:      stat.ETag,
+		size:      stat.Size,
+		modified:  stat.ModifyTime.UnixNano(),
+	}
+	if hash, ok := s.rb.hashes[cacheKey]; ok {
+		s.hashes[cacheKey] = hash
+		return hash, nil
+	}
+
+	reader := data.NewRangeReader(ctx, func(ctx context.Context, offset int64, p []byte) (int, error) {
+		return r.storage.ReadObject(ctx, namespace, key, offset, p)
+	}, 0, stat.Size)
+	checksum, err := data.ComputeChecksum(reader, data.ChecksumSHA256)
+	if err != nil {
+		return "", err
+	}
+
+	s.hashes[cacheKey] = checksum.Value
+	return checksum.Value, nil
+}
+
+// copy overwrites the content of key within target with the content of the reference.
+func (s *resync) copy(ctx context.Context, target *replica, namespace, key string, size int64) error {
+	if s.buffer == nil {
+		s.buffer = make([]byte, data.DefaultCopyChunkSize)
+	}
+
+	for offset := int64(0); offset < size; {
+		chunk := s.buffer[:min(int64(len(s.buffer)), size-offset)]
+		n, err := s.reference.storage.ReadObject(ctx, namespace, key, offset, chunk)
+		if err != nil && !(err == io.EOF && n > 0) {
+			return fmt.Errorf("failed to read '%s' of reference: %w", key, err)
+		}
+		if n == 0 {
+			return fmt.Errorf("failed to read '%s' of reference: %w", key, io.ErrUnexpectedEOF)
+		}
+		if _, err := target.storage.WriteObject(ctx, namespace, key, offset, chunk[:n]); err != nil {
+			return err
+		}
+		offset += int64(n)
+	}
+
+	return target.storage.TruncateObject(ctx, namespace, key, size)
+}
+
+// fail marks target as degraded and excludes it from the remaining resync.
+func (s *resync) fail(target *replica, err error) {
+	target.fail(err)
+	s.broken[target] = true
+}
+
+// startResync starts the background resync, which runs until stopResync is called.
+func (rb *ReplicaBackend) startResync(interval time.Duration) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	rb.resyncCancel, rb.resyncDone = cancel, done
+
+	go func() {
+		defer close(done)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				// Failures are reflected by the status of the replicas
+				rb.Resync(ctx)
+			}
+		}
+	}()
+}
+
+// stopResync stops the background resync and waits until a running resync has been cancelled.
+func (rb *ReplicaBackend) stopResync() {
+	rb.mu.Lock()
+	cancel, done := rb.resyncCancel, rb.resyncDone
+	rb.resyncCancel, rb.resyncDone = nil, nil
+	rb.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+		<-done
+	}
+}
+
+// list returns the children of the directory key with their full keys.
+func list(ctx context.Context, storage backend.ObjectStorageBackend, namespace, key string) ([]*data.FileStat, error) {
+	stats, err := storage.ListObjects(ctx, namespace, key)
+	if err != nil {
+		if err == data.ErrNotExist && key == "" {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	prefix := ""
+	if key != "" {
+		prefix = key + "/"
+	}
+
+	children := make([]*data.FileStat, 0, len(stats))
+	for _, stat := range stats {
+		// Backends return either the name or the full key of each entry
+		name := strings.TrimPrefix(strings.TrimPrefix(stat.Key, prefix), "/")
+		if name == "" || stat.Key == key {
+			continue
+		}
+
+		child := *stat
+		child.Key = prefix + strings.TrimSuffix(name, "/")
+		children = append(children, &child)
+	}
+
+	return children, nil
+}
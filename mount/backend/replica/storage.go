@@ -0,0 +1,158 @@
+package replica
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/mwantia/vfs/data"
+	"github.com/mwantia/vfs/mount/backend"
+)
+
+func (rb *ReplicaBackend) CreateObject(ctx context.Context, namespace, key string, mode data.FileMode) (*data.FileStat, error) {
+	return write(rb, namespace, func(storage backend.ObjectStorageBackend) (*data.FileStat, error) {
+		return storage.CreateObject(ctx, namespace, key, mode)
+	})
+}
+
+func (rb *ReplicaBackend) ReadObject(ctx context.Context, namespace, key string, offset int64, dat []byte) (int, error) {
+	return read(rb, namespace, func(storage backend.ObjectStorageBackend) (int, error) {
+		return storage.ReadObject(ctx, namespace, key, offset, dat)
+	})
+}
+
+func (rb *ReplicaBackend) WriteObject(ctx context.Context, namespace, key string, offset int64, dat []byte) (int, error) {
+	return write(rb, namespace, func(storage backend.ObjectStorageBackend) (int, error) {
+		return storage.WriteObject(ctx, namespace, key, offset, dat)
+	})
+}
+
+func (rb *ReplicaBackend) DeleteObject(ctx context.Context, namespace, key string, force bool) error {
+	_, err := write(rb, namespace, func(storage backend.ObjectStorageBackend) (struct{}, error) {
+		return struct{}{}, storage.DeleteObject(ctx, namespace, key, force)
+	})
+	return err
+}
+
+func (rb *ReplicaBackend) ListObjects(ctx context.Context, namespace, key string) ([]*data.FileStat, error) {
+	return read(rb, namespace, func(storage backend.ObjectStorageBackend) ([]*data.FileStat, error) {
+		return storage.ListObjects(ctx, namespace, key)
+	})
+}
+
+func (rb *ReplicaBackend) HeadObject(ctx context.Context, namespace, key string) (*data.FileStat, error) {
+	return read(rb, namespace, func(storage backend.ObjectStorageBackend) (*data.FileStat, error) {
+		return storage.HeadObject(ctx, namespace, key)
+	})
+}
+
+func (rb *ReplicaBackend) TruncateObject(ctx context.Context, namespace, key string, size int64) error {
+	_, err := write(rb, namespace, func(storage backend.ObjectStorageBackend) (struct{}, error) {
+		return struct{}{}, storage.TruncateObject(ctx, namespace, key, size)
+	})
+	return err
+}
+
+// read tries the healthy replicas ordered by latency until one succeeds.
+// Degraded replicas are only used once all healthy replicas have failed.
+func read[T any](rb *ReplicaBackend, namespace string, fn func(backend.ObjectStorageBackend) (T, error)) (T, error) {
+	rb.mu.RLock()
+	defer rb.mu.RUnlock()
+
+	rb.trackNamespace(namespace)
+
+	var result T
+	var err error
+	for _, r := range rb.byLatency() {
+		healthy := r.healthy()
+
+		start := time.Now()
+		result, err = fn(r.storage)
+		r.observe(time.Since(start))
+
+		if !isFailure(err) {
+			return result, err
+		}
+		if healthy {
+			r.fail(err)
+		}
+	}
+
+	return result, fmt.Errorf("no replica available: %w", err)
+}
+
+// write applies fn to all replicas in parallel and returns the result of the replica with the highest priority,
+// once the write quorum has been acknowledged by healthy replicas.
+// Every replica failing the write or disagreeing with the acknowledged result is marked degraded.
+func write[T any](rb *ReplicaBackend, namespace string, fn func(backend.ObjectStorageBackend) (T, error)) (T, error) {
+	rb.mu.RLock()
+	defer rb.mu.RUnlock()
+
+	rb.trackNamespace(namespace)
+
+	type outcome struct {
+		result  T
+		err     error
+		healthy bool
+	}
+
+	// Writes are attempted on degraded replicas as well, so they keep up as much as possible
+	outcomes := make([]outcome, len(rb.replicas))
+	var wg sync.WaitGroup
+	for i, r := range rb.replicas {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			outcomes[i].healthy = r.healthy()
+
+			start := time.Now()
+			outcomes[i].result, outcomes[i].err = fn(r.storage)
+			r.observe(time.Since(start))
+		}()
+	}
+	wg.Wait()
+
+	acks, first := 0, -1
+	for i, o := range outcomes {
+		if o.healthy && o.err == nil {
+			acks++
+			if first < 0 {
+				first = i
+			}
+		}
+	}
+
+	var zero T
+	if acks == 0 {
+		// Healthy replicas rejecting the write alike keep their health, since they still agree with each other
+		var rejected error
+		for i, o := range outcomes {
+			if !o.healthy {
+				continue
+			}
+			if isFailure(o.err) {
+				rb.replicas[i].fail(o.err)
+			} else if rejected == nil {
+				rejected = o.err
+			}
+		}
+		if rejected != nil {
+			return zero, rejected
+		}
+		return zero, fmt.Errorf("%w: acknowledged by 0 of %d replicas", ErrWriteQuorum, rb.config.WriteQuorum)
+	}
+
+	// Replicas have diverged from the acknowledged result and need to be repaired by a resync
+	for i, o := range outcomes {
+		if o.err != nil {
+			rb.replicas[i].fail(o.err)
+		}
+	}
+	if acks < rb.config.WriteQuorum {
+		return zero, fmt.Errorf("%w: acknowledged by %d of %d replicas", ErrWriteQuorum, acks, rb.config.WriteQuorum)
+	}
+
+	return outcomes[first].result, nil
+}
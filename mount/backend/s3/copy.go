@@ -0,0 +1,46 @@
+package s3
+
+import (
+	"context"
+	"strings"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/mwantia/vfs/data"
+)
+
+// CopyObject creates dstKey as server-side copy of the file at srcKey.
+func (sb *S3Backend) CopyObject(ctx context.Context, srcNamespace, srcKey, dstNamespace, dstKey string) (*data.FileStat, error) {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+
+	objInfo, err := sb.client.StatObject(ctx, sb.bucketName, srcKey, minio.StatObjectOptions{})
+	if err != nil {
+		if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+			return nil, data.ErrNotExist
+		}
+		return nil, err
+	}
+	if strings.HasSuffix(objInfo.Key, "/") || objInfo.ContentType == "application/x-directory" {
+		return nil, data.ErrIsDirectory
+	}
+
+	if sb.existsUnsafe(ctx, dstKey) {
+		return nil, data.ErrExist
+	}
+
+	info, err := sb.client.CopyObject(ctx,
+		minio.CopyDestOptions{Bucket: sb.bucketName, Object: dstKey},
+		minio.CopySrcOptions{Bucket: sb.bucketName, Object: srcKey})
+	if err != nil {
+		return nil, err
+	}
+
+	return &data.FileStat{
+		Key:        dstKey,
+		Size:       info.Size,
+		Mode:       data.FileMode(0644),
+		ModifyTime: info.LastModified,
+		CreateTime: info.LastModified,
+		ETag:       info.ETag,
+	}, nil
+}
@@ -0,0 +1,49 @@
+package s3
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/mwantia/vfs/mount/backend"
+)
+
+func init() {
+	backend.Register(&backend.Registration{
+		Name:        "s3",
+		Description: "S3-compatible object storage bucket",
+		Params: []backend.ParamSpec{
+			{Name: "endpoint", Description: "endpoint without scheme (e.g. localhost:9000)", Required: true},
+			{Name: "bucket", Description: "name of the bucket", Required: true},
+			{Name: "access_key", Description: "static access key", Secret: true},
+			{Name: "secret_key", Description: "static secret key", Secret: true},
+			{Name: "ssl", Description: "whether to use TLS", Type: backend.ParamTypeBool, Default: "true"},
+		},
+		Factory: func(params backend.FactoryParams) (backend.Backend, error) {
+			ssl, err := params.Bool("s3", "ssl", true)
+			if err != nil {
+				return nil, err
+			}
+
+			return NewS3Backend(params.String("endpoint", ""), params.String("bucket", ""), params.String("access_key", ""), params.String("secret_key", ""), ssl)
+		},
+		// s3://access:secret@bucket?endpoint=localhost:9000&ssl=false
+		ParseURL: func(u *url.URL) (backend.FactoryParams, error) {
+			if strings.Trim(u.Path, "/") != "" {
+				return nil, fmt.Errorf("path '%s' is not supported, use the mount path prefix instead", u.Path)
+			}
+
+			params := backend.QueryParams(u)
+			if u.Host != "" {
+				params["bucket"] = u.Host
+			}
+			if u.User != nil {
+				params["access_key"] = u.User.Username()
+				if secret, exists := u.User.Password(); exists {
+					params["secret_key"] = secret
+				}
+			}
+			return params, nil
+		},
+	})
+}
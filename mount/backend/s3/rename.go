@@ -0,0 +1,86 @@
+package s3
+
+import (
+	"context"
+	"strings"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/mwantia/vfs/data"
+	"github.com/mwantia/vfs/data/errors"
+)
+
+// RenameObject moves the object at oldKey including all nested objects to newKey.
+// Objects are copied server-side first and only deleted once all copies succeeded;
+// if any copy fails, all copies created so far are removed again.
+func (sb *S3Backend) RenameObject(ctx context.Context, namespace, oldKey, newKey string) error {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+
+	if oldKey == "" || newKey == "" || strings.HasPrefix(newKey, oldKey+"/") {
+		return data.ErrInvalid
+	}
+
+	if sb.existsUnsafe(ctx, newKey) {
+		return data.ErrExist
+	}
+
+	// Collect the source objects (directories are stored with trailing slash)
+	var sources []string
+	if _, err := sb.client.StatObject(ctx, sb.bucketName, oldKey, minio.StatObjectOptions{}); err == nil {
+		sources = append(sources, oldKey)
+	}
+
+	prefix := oldKey + "/"
+	for object := range sb.client.ListObjects(ctx, sb.bucketName, minio.ListObjectsOptions{
+		Prefix:    prefix,
+		Recursive: true,
+	}) {
+		if object.Err != nil {
+			return object.Err
+		}
+		sources = append(sources, object.Key)
+	}
+
+	if len(sources) == 0 {
+		return data.ErrNotExist
+	}
+
+	copied := make([]string, 0, len(sources))
+	for _, source := range sources {
+		target := newKey + strings.TrimPrefix(source, oldKey)
+
+		_, err := sb.client.CopyObject(ctx,
+			minio.CopyDestOptions{Bucket: sb.bucketName, Object: target},
+			minio.CopySrcOptions{Bucket: sb.bucketName, Object: source})
+		if err != nil {
+			// Roll back all copies, so the source stays the only version
+			for _, target := range copied {
+				sb.client.RemoveObject(ctx, sb.bucketName, target, minio.RemoveObjectOptions{})
+			}
+			return err
+		}
+
+		copied = append(copied, target)
+	}
+
+	errs := errors.Errors{}
+	for _, source := range sources {
+		if err := sb.client.RemoveObject(ctx, sb.bucketName, source, minio.RemoveObjectOptions{}); err != nil {
+			errs.Add(err)
+		}
+	}
+
+	return errs.Errors()
+}
+
+// existsUnsafe checks if key exists either as object or as directory.
+func (sb *S3Backend) existsUnsafe(ctx context.Context, key string) bool {
+	if _, err := sb.client.StatObject(ctx, sb.bucketName, key, minio.StatObjectOptions{}); err == nil {
+		return true
+	}
+	if _, err := sb.client.StatObject(ctx, sb.bucketName, key+"/", minio.StatObjectOptions{}); err == nil {
+		return true
+	}
+
+	return false
+}
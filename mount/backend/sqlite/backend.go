@@ -14,7 +14,7 @@ import (
 //
 // Layer 1: In-memory B-tree for fast key → ID lookups (keys map)
 // Layer 2: SQLite metadata table (vfs_metadata) for filesystem metadata
-// Layer 3: SQLite data table (vfs_data) for file content with reference counting
+// Layer 3: SQLite data tables (vfs_data, vfs_chunks) for chunked file content with reference counting
 //
 // This architecture enables:
 // - Fast path lookups via B-tree (O(log n))
@@ -43,6 +43,13 @@ func NewSQLiteBackend(dbPath string) (*SQLiteBackend, error) {
 		return nil, err
 	}
 
+	// Free pages are returned by incremental vacuum after garbage collection
+	// This only applies to new databases, existing ones switch with the next full VACUUM
+	if _, err := db.Exec("PRAGMA auto_vacuum = INCREMENTAL"); err != nil {
+		db.Close()
+		return nil, err
+	}
+
 	// Enable WAL mode for better concurrency
 	if _, err := db.Exec("PRAGMA journal_mode = WAL"); err != nil {
 		db.Close()
@@ -87,17 +94,27 @@ func (sb *SQLiteBackend) initSchema() error {
 	-- Content storage with reference counting
 	CREATE TABLE IF NOT EXISTS vfs_data (
 		id TEXT PRIMARY KEY,
-		content BLOB NOT NULL,
 		size INTEGER NOT NULL CHECK(size >= 0),
 		ref_count INTEGER NOT NULL DEFAULT 0,
 		created_at INTEGER NOT NULL,
 		last_accessed INTEGER NOT NULL
 	);
 	CREATE INDEX IF NOT EXISTS idx_vfs_data_ref_count ON vfs_data(ref_count);
+
+	-- Content split into fixed-size chunks, so writes only touch the affected chunks
+	CREATE TABLE IF NOT EXISTS vfs_chunks (
+		data_id TEXT NOT NULL REFERENCES vfs_data(id) ON DELETE CASCADE,
+		chunk_index INTEGER NOT NULL CHECK(chunk_index >= 0),
+		content BLOB NOT NULL,
+		PRIMARY KEY(data_id, chunk_index)
+	) WITHOUT ROWID;
 	`
 
-	_, err := sb.db.Exec(schema)
-	return err
+	if _, err := sb.db.Exec(schema); err != nil {
+		return err
+	}
+
+	return migrateChunks(context.Background(), sb.db)
 }
 
 // Returns the identifier name defined for this backend
@@ -150,6 +167,6 @@ func (sb *SQLiteBackend) GetCapabilities() *backend.BackendCapabilities {
 			backend.CapabilityObjectStorage,
 			backend.CapabilityMetadata,
 		},
-		MaxObjectSize: 5242880, // 5 MB
+		MaxObjectSize: 1099511627776, // 1 TB
 	}
 }
@@ -0,0 +1,52 @@
+package sqlite
+
+import (
+	"context"
+	"time"
+
+	"github.com/mwantia/vfs/data"
+	"github.com/mwantia/vfs/mount/backend"
+)
+
+// PutMetaBatch creates or replaces the metadata of all entries within a single transaction.
+func (sb *SQLiteBackend) PutMetaBatch(ctx context.Context, namespace string, metas []*data.Metadata) error {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+
+	tx, err := sb.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, meta := range metas {
+		if id, exists := sb.keys.Get(backend.NamespacedKey(namespace, meta.Key)); exists {
+			meta.ID = id
+			if err := updateMetaRow(ctx, tx, id, meta); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if meta.ID == "" {
+			meta.ID = data.NewMetadata(meta.Key, meta.Mode, meta.Size).ID
+		}
+		if meta.CreateTime.IsZero() {
+			meta.CreateTime = time.Now()
+		}
+		if err := insertMeta(ctx, tx, namespace, meta); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	// Keys are only visible once the transaction has been committed
+	for _, meta := range metas {
+		sb.keys.Set(backend.NamespacedKey(namespace, meta.Key), meta.ID)
+	}
+
+	return nil
+}
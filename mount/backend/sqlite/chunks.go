@@ -0,0 +1,203 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// chunkSize is the maximum size of a single content chunk in vfs_chunks.
+// Chunks may be shorter or missing entirely, in which case the gap reads as zeros.
+const chunkSize = 256 * 1024
+
+// chunkIndex returns the index of the chunk containing offset.
+func chunkIndex(offset int64) int64 {
+	return offset / chunkSize
+}
+
+// ensureData creates the data row for id, if it doesn't exist yet.
+func ensureData(ctx context.Context, q querier, id string) error {
+	now := time.Now().Unix()
+	_, err := q.ExecContext(ctx, `
+		INSERT INTO vfs_data (id, size, ref_count, created_at, last_accessed)
+		VALUES (?, 0, 1, ?, ?)
+		ON CONFLICT(id) DO NOTHING
+	`, id, now, now)
+
+	return err
+}
+
+// setDataSize updates the size stored within the data row for id.
+func setDataSize(ctx context.Context, q querier, id string, size int64) error {
+	_, err := q.ExecContext(ctx, `
+		UPDATE vfs_data SET size = ?, last_accessed = ? WHERE id = ?
+	`, size, time.Now().Unix(), id)
+
+	return err
+}
+
+// releaseData decrements the reference count of id and removes all content that is no longer referenced.
+// Chunks are removed explicitly, since foreign keys are only enforced on the connection enabling them.
+func releaseData(ctx context.Context, q querier, id string) error {
+	if _, err := q.ExecContext(ctx, "UPDATE vfs_data SET ref_count = ref_count - 1 WHERE id = ?", id); err != nil {
+		return err
+	}
+	if _, err := q.ExecContext(ctx, `
+		DELETE FROM vfs_chunks WHERE data_id IN (SELECT id FROM vfs_data WHERE ref_count <= 0)
+	`); err != nil {
+		return err
+	}
+
+	_, err := q.ExecContext(ctx, "DELETE FROM vfs_data WHERE ref_count <= 0")
+	return err
+}
+
+// readChunks reads the content of id at offset into dat, limited to size.
+// Only the chunks overlapping the requested range are loaded.
+func readChunks(ctx context.Context, q querier, id string, offset int64, dat []byte, size int64) (int, error) {
+	end := min(offset+int64(len(dat)), size)
+	if end <= offset {
+		return 0, nil
+	}
+
+	n := int(end - offset)
+	clear(dat[:n])
+
+	rows, err := q.QueryContext(ctx, `
+		SELECT chunk_index, content FROM vfs_chunks
+		WHERE data_id = ? AND chunk_index BETWEEN ? AND ?
+	`, id, chunkIndex(offset), chunkIndex(end-1))
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var index int64
+		var content []byte
+		if err := rows.Scan(&index, &content); err != nil {
+			return 0, err
+		}
+
+		start := index * chunkSize
+		from := max(offset, start)
+		to := min(end, start+int64(len(content)))
+		if from < to {
+			copy(dat[from-offset:to-offset], content[from-start:to-start])
+		}
+	}
+
+	return n, rows.Err()
+}
+
+// writeChunks writes dat at offset into the content of id.
+// Only the chunks overlapping the written range are read and replaced.
+func writeChunks(ctx context.Context, q querier, id string, offset int64, dat []byte) error {
+	end := offset + int64(len(dat))
+	for index := chunkIndex(offset); index*chunkSize < end; index++ {
+		start := index * chunkSize
+		from := max(offset, start) - start
+		part := dat[max(offset, start)-offset : min(end, start+chunkSize)-offset]
+
+		var content []byte
+		err := q.QueryRowContext(ctx,
+			"SELECT content FROM vfs_chunks WHERE data_id = ? AND chunk_index = ?",
+			id, index).Scan(&content)
+		if err != nil && err != sql.ErrNoRows {
+			return err
+		}
+
+		if required := from + int64(len(part)); int64(len(content)) < required {
+			grown := make([]byte, required)
+			copy(grown, content)
+			content = grown
+		}
+		copy(content[from:], part)
+
+		if _, err := q.ExecContext(ctx, `
+			INSERT INTO vfs_chunks (data_id, chunk_index, content) VALUES (?, ?, ?)
+			ON CONFLICT(data_id, chunk_index) DO UPDATE SET content = excluded.content
+		`, id, index, content); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// truncateChunks drops all content of id beyond size.
+// Growing content requires no changes, since missing chunks read as zeros.
+func truncateChunks(ctx context.Context, q querier, id string, size int64) error {
+	if size <= 0 {
+		_, err := q.ExecContext(ctx, "DELETE FROM vfs_chunks WHERE data_id = ?", id)
+		return err
+	}
+
+	last := chunkIndex(size - 1)
+	if _, err := q.ExecContext(ctx,
+		"DELETE FROM vfs_chunks WHERE data_id = ? AND chunk_index > ?",
+		id, last); err != nil {
+		return err
+	}
+
+	keep := size - last*chunkSize
+	_, err := q.ExecContext(ctx, `
+		UPDATE vfs_chunks SET content = substr(content, 1, ?)
+		WHERE data_id = ? AND chunk_index = ? AND length(content) > ?
+	`, keep, id, last, keep)
+
+	return err
+}
+
+// writeContent replaces the complete content stored for id.
+func writeContent(ctx context.Context, q querier, id string, content []byte) error {
+	if err := ensureData(ctx, q, id); err != nil {
+		return err
+	}
+	if _, err := q.ExecContext(ctx, "DELETE FROM vfs_chunks WHERE data_id = ?", id); err != nil {
+		return err
+	}
+	if err := writeChunks(ctx, q, id, 0, content); err != nil {
+		return err
+	}
+
+	return setDataSize(ctx, q, id, int64(len(content)))
+}
+
+// migrateChunks moves the content of databases created before content was chunked
+// from the content column of vfs_data into vfs_chunks and drops the column afterwards.
+func migrateChunks(ctx context.Context, db *sql.DB) error {
+	var legacy int
+	if err := db.QueryRowContext(ctx,
+		"SELECT COUNT(*) FROM pragma_table_info('vfs_data') WHERE name = 'content'").Scan(&legacy); err != nil {
+		return err
+	}
+	if legacy == 0 {
+		return nil
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Chunks are sliced within SQLite, so no content has to be loaded into memory
+	if _, err := tx.ExecContext(ctx, `
+		WITH RECURSIVE chunks(data_id, chunk_index, size) AS (
+			SELECT id, 0, size FROM vfs_data WHERE size > 0
+			UNION ALL
+			SELECT data_id, chunk_index + 1, size FROM chunks WHERE (chunk_index + 1) * ? < size
+		)
+		INSERT INTO vfs_chunks (data_id, chunk_index, content)
+		SELECT chunks.data_id, chunks.chunk_index, substr(vfs_data.content, chunks.chunk_index * ? + 1, ?)
+		FROM chunks JOIN vfs_data ON vfs_data.id = chunks.data_id
+	`, chunkSize, chunkSize, chunkSize); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, "ALTER TABLE vfs_data DROP COLUMN content"); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
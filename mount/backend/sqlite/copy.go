@@ -0,0 +1,81 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"path"
+
+	"github.com/mwantia/vfs/data"
+	"github.com/mwantia/vfs/mount/backend"
+)
+
+// CopyObject creates dstKey as copy of the file at srcKey.
+// The content is copied within the database, without being loaded into memory.
+func (sb *SQLiteBackend) CopyObject(ctx context.Context, srcNamespace, srcKey, dstNamespace, dstKey string) (*data.FileStat, error) {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+
+	meta, err := sb.readMetaUnsafe(ctx, srcNamespace, srcKey)
+	if err != nil {
+		return nil, err
+	}
+	if meta.Mode.IsDir() {
+		return nil, data.ErrIsDirectory
+	}
+
+	if _, exists := sb.keys.Get(backend.NamespacedKey(dstNamespace, dstKey)); exists {
+		return nil, data.ErrExist
+	}
+	// Verify parent directory exists
+	if parentKey := path.Dir(dstKey); parentKey != "." && parentKey != "" {
+		parentMeta, err := sb.readMetaUnsafe(ctx, dstNamespace, parentKey)
+		if err != nil {
+			return nil, data.ErrNotExist
+		}
+		if !parentMeta.Mode.IsDir() {
+			return nil, data.ErrNotDirectory
+		}
+	}
+
+	// Only the content is copied, so the checksum remains valid
+	clone := data.NewFileMetadata(dstKey, meta.Size, meta.Mode)
+	if checksum := meta.GetAttribute(data.AttributeChecksum, ""); checksum != "" {
+		clone.Attributes[data.AttributeChecksum] = checksum
+	}
+
+	if err := sb.createMetaUnsafe(ctx, dstNamespace, clone); err != nil {
+		return nil, err
+	}
+
+	if err := copyData(ctx, sb.db, meta.ID, clone.ID, clone.CreateTime.Unix()); err != nil {
+		// Remove the metadata again, so no empty copy remains
+		sb.deleteMetaUnsafe(ctx, dstNamespace, dstKey)
+		return nil, err
+	}
+
+	return clone.ToStat(), nil
+}
+
+// copyData copies the data row and all chunks of srcID to dstID within a single transaction.
+func copyData(ctx context.Context, db *sql.DB, srcID, dstID string, now int64) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO vfs_data (id, size, ref_count, created_at, last_accessed)
+		SELECT ?, size, 1, ?, ? FROM vfs_data WHERE id = ?
+	`, dstID, now, now, srcID); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO vfs_chunks (data_id, chunk_index, content)
+		SELECT ?, chunk_index, content FROM vfs_chunks WHERE data_id = ?
+	`, dstID, srcID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,132 @@
+package sqlite
+
+import (
+	"context"
+	"time"
+
+	"github.com/mwantia/vfs/data"
+)
+
+// CollectGarbage removes data rows no longer referenced by any metadata (or with a reference count of zero)
+// together with their chunks, as well as chunks left behind without any data row.
+// Data rows accessed within the grace period are kept, since other processes may share the database.
+// The database is compacted using incremental vacuum afterwards or a full VACUUM if opts.Vacuum is set.
+func (sb *SQLiteBackend) CollectGarbage(ctx context.Context, opts *data.GCOptions) (*data.GCReport, error) {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+
+	report := &data.GCReport{DryRun: opts.DryRun}
+	if err := sb.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM vfs_data").Scan(&report.Scanned); err != nil {
+		return nil, err
+	}
+
+	// Mark: every data row is referenced by the metadata sharing its id
+	rows, err := sb.db.QueryContext(ctx, `
+		SELECT d.id, COALESCE(SUM(length(c.content)), 0), d.last_accessed
+		FROM vfs_data d LEFT JOIN vfs_chunks c ON c.data_id = d.id
+		WHERE d.ref_count <= 0 OR NOT EXISTS (SELECT 1 FROM vfs_metadata m WHERE m.id = d.id)
+		GROUP BY d.id, d.last_accessed
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	var expired []string
+	for rows.Next() {
+		var id string
+		var size, accessed int64
+		if err := rows.Scan(&id, &size, &accessed); err != nil {
+			rows.Close()
+			return nil, err
+		}
+
+		deferred := !opts.Expired(time.Unix(accessed, 0))
+		report.Orphan("vfs_data/"+id, size, deferred)
+		if !deferred {
+			expired = append(expired, id)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Chunks without data row carry no timestamp, but are never written without creating the row first
+	rows, err = sb.db.QueryContext(ctx, `
+		SELECT c.data_id, SUM(length(c.content)) FROM vfs_chunks c
+		WHERE NOT EXISTS (SELECT 1 FROM vfs_data d WHERE d.id = c.data_id)
+		GROUP BY c.data_id
+	`)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var id string
+		var size int64
+		if err := rows.Scan(&id, &size); err != nil {
+			rows.Close()
+			return nil, err
+		}
+
+		report.Scanned++
+		report.Orphan("vfs_chunks/"+id, size, false)
+		expired = append(expired, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if opts.DryRun {
+		return report, nil
+	}
+
+	// Sweep
+	tx, err := sb.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	for _, id := range expired {
+		if _, err := tx.ExecContext(ctx, "DELETE FROM vfs_chunks WHERE data_id = ?", id); err != nil {
+			return nil, err
+		}
+		if _, err := tx.ExecContext(ctx, "DELETE FROM vfs_data WHERE id = ?", id); err != nil {
+			return nil, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	if err := sb.vacuum(ctx, opts.Vacuum); err != nil {
+		return nil, err
+	}
+	report.Vacuumed = opts.Vacuum
+
+	return report, nil
+}
+
+// vacuum returns pages freed by deleted content to the file system.
+// A full VACUUM rebuilds the database file and switches existing databases to incremental vacuum.
+func (sb *SQLiteBackend) vacuum(ctx context.Context, full bool) error {
+	// Pragmas only apply to a single connection, so both statements have to share it
+	conn, err := sb.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if !full {
+		_, err := conn.ExecContext(ctx, "PRAGMA incremental_vacuum")
+		return err
+	}
+
+	if _, err := conn.ExecContext(ctx, "PRAGMA auto_vacuum = INCREMENTAL"); err != nil {
+		return err
+	}
+
+	_, err = conn.ExecContext(ctx, "VACUUM")
+	return err
+}
@@ -0,0 +1,28 @@
+package sqlite
+
+import (
+	"net/url"
+
+	"github.com/mwantia/vfs/mount/backend"
+)
+
+func init() {
+	backend.Register(&backend.Registration{
+		Name:        "sqlite",
+		Description: "SQLite database storing objects and metadata",
+		Params: []backend.ParamSpec{
+			{Name: "path", Description: "database file", Default: ":memory:"},
+		},
+		Factory: func(params backend.FactoryParams) (backend.Backend, error) {
+			return NewSQLiteBackend(params.String("path", ":memory:"))
+		},
+		// sqlite:///var/vfs.db, sqlite://vfs.db or sqlite::memory:
+		ParseURL: func(u *url.URL) (backend.FactoryParams, error) {
+			params := backend.QueryParams(u)
+			if path := backend.URLPath(u); path != "" {
+				params["path"] = path
+			}
+			return params, nil
+		},
+	})
+}
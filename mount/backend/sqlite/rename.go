@@ -0,0 +1,95 @@
+package sqlite
+
+import (
+	"context"
+	"path"
+	"strings"
+
+	"github.com/mwantia/vfs/data"
+	"github.com/mwantia/vfs/mount/backend"
+)
+
+// RenameObject moves the object at oldKey including all nested objects to newKey within a single transaction.
+// Content is referenced by id, so it's never copied.
+func (sb *SQLiteBackend) RenameObject(ctx context.Context, namespace, oldKey, newKey string) error {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+
+	return sb.renameUnsafe(ctx, namespace, oldKey, newKey)
+}
+
+// RenameMeta moves the metadata at oldKey including all nested entries to newKey.
+// Since objects and metadata share the same rows, this is equal to RenameObject.
+func (sb *SQLiteBackend) RenameMeta(ctx context.Context, namespace, oldKey, newKey string) error {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+
+	return sb.renameUnsafe(ctx, namespace, oldKey, newKey)
+}
+
+// renameUnsafe moves all keys equal to or nested below oldKey to newKey.
+// MUST be called while holding a write lock.
+func (sb *SQLiteBackend) renameUnsafe(ctx context.Context, namespace, oldKey, newKey string) error {
+	if oldKey == "" || newKey == "" || strings.HasPrefix(newKey, oldKey+"/") {
+		return data.ErrInvalid
+	}
+	if oldKey == newKey {
+		return nil
+	}
+
+	if _, exists := sb.keys.Get(backend.NamespacedKey(namespace, oldKey)); !exists {
+		return data.ErrNotExist
+	}
+	if _, exists := sb.keys.Get(backend.NamespacedKey(namespace, newKey)); exists {
+		return data.ErrExist
+	}
+	// Verify parent directory exists
+	if parentKey := path.Dir(newKey); parentKey != "." && parentKey != "" {
+		parentMeta, err := sb.readMetaUnsafe(ctx, namespace, parentKey)
+		if err != nil {
+			return data.ErrNotExist
+		}
+		if !parentMeta.Mode.IsDir() {
+			return data.ErrNotDirectory
+		}
+	}
+
+	// Collect all affected keys by their id
+	renamed := make(map[string]string)
+	ids := make(map[string]string)
+	nsPrefix := backend.NamespacedKey(namespace, "")
+	sb.keys.Ascend(backend.NamespacedKey(namespace, oldKey), func(nsKey string, id string) bool {
+		key := strings.TrimPrefix(nsKey, nsPrefix)
+		if newChildKey, affected := backend.RenamedKey(key, oldKey, newKey); affected {
+			renamed[key] = newChildKey
+			ids[key] = id
+			return true
+		}
+		// Keys are ordered, so all nested keys follow shortly after oldKey
+		return strings.HasPrefix(key, oldKey)
+	})
+
+	tx, err := sb.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for key, newChildKey := range renamed {
+		if _, err := tx.ExecContext(ctx, "UPDATE vfs_metadata SET key = ? WHERE id = ?", newChildKey, ids[key]); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	// Only update the B-tree, once the transaction has been committed
+	for key, newChildKey := range renamed {
+		sb.keys.Delete(backend.NamespacedKey(namespace, key))
+		sb.keys.Set(backend.NamespacedKey(namespace, newChildKey), ids[key])
+	}
+
+	return nil
+}
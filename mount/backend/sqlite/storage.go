@@ -2,7 +2,6 @@ package sqlite
 
 import (
 	"context"
-	"database/sql"
 	"io"
 	"path"
 	"strings"
@@ -59,27 +58,7 @@ func (sb *SQLiteBackend) ReadObject(ctx context.Context, namespace, key string,
 		return 0, io.EOF
 	}
 
-	// Query data from database
-	var content []byte
-	err = sb.db.QueryRowContext(ctx,
-		"SELECT content FROM vfs_data WHERE id = ?",
-		meta.ID).Scan(&content)
-
-	if err == sql.ErrNoRows {
-		// No data stored yet (empty file)
-		return 0, nil
-	}
-	if err != nil {
-		return 0, err
-	}
-
-	// Calculate how many bytes we can actually read
-	available := meta.Size - offset
-	toRead := min(int64(len(dat)), available)
-
-	// Copy data from content
-	n := copy(dat, content[offset:offset+toRead])
-	return n, nil
+	return readChunks(ctx, sb.db, meta.ID, offset, dat, meta.Size)
 }
 
 func (sb *SQLiteBackend) WriteObject(ctx context.Context, namespace, key string, offset int64, dat []byte) (int, error) {
@@ -104,48 +83,14 @@ func (sb *SQLiteBackend) WriteObject(ctx context.Context, namespace, key string,
 	}
 	defer tx.Rollback()
 
-	// Get existing content or create new
-	var content []byte
-	var refCount int
-	err = tx.QueryRowContext(ctx,
-		"SELECT content, ref_count FROM vfs_data WHERE id = ?",
-		meta.ID).Scan(&content, &refCount)
-
-	if err != nil && err != sql.ErrNoRows {
+	// Only the chunks covered by the write are replaced
+	if err := ensureData(ctx, tx, meta.ID); err != nil {
 		return 0, err
 	}
-
-	// Determine the new required size
-	newSize := max(writeEnd, meta.Size)
-
-	// Expand buffer if needed
-	if int64(len(content)) < newSize {
-		newBuffer := make([]byte, newSize)
-		copy(newBuffer, content)
-		content = newBuffer
-	}
-
-	// Write the data
-	copy(content[offset:], dat)
-
-	now := time.Now().Unix()
-
-	// Insert or update content
-	if err == sql.ErrNoRows {
-		// Insert new data
-		_, err = tx.ExecContext(ctx, `
-			INSERT INTO vfs_data (id, content, size, ref_count, created_at, last_accessed)
-			VALUES (?, ?, ?, 1, ?, ?)
-		`, meta.ID, content, newSize, now, now)
-	} else {
-		// Update existing data
-		_, err = tx.ExecContext(ctx, `
-			UPDATE vfs_data SET content = ?, size = ?, last_accessed = ?
-			WHERE id = ?
-		`, content, newSize, now, meta.ID)
+	if err := writeChunks(ctx, tx, meta.ID, offset, dat); err != nil {
+		return 0, err
 	}
-
-	if err != nil {
+	if err := setDataSize(ctx, tx, meta.ID, max(writeEnd, meta.Size)); err != nil {
 		return 0, err
 	}
 
@@ -342,41 +287,14 @@ func (sb *SQLiteBackend) TruncateObject(ctx context.Context, namespace, key stri
 	}
 	defer tx.Rollback()
 
-	// Get existing content
-	var content []byte
-	err = tx.QueryRowContext(ctx,
-		"SELECT content FROM vfs_data WHERE id = ?",
-		meta.ID).Scan(&content)
-
-	now := time.Now().Unix()
-
-	if err == sql.ErrNoRows {
-		// No existing data - create new empty content of specified size
-		content = make([]byte, size)
-		_, err = tx.ExecContext(ctx, `
-			INSERT INTO vfs_data (id, content, size, ref_count, created_at, last_accessed)
-			VALUES (?, ?, ?, 1, ?, ?)
-		`, meta.ID, content, size, now, now)
-	} else if err != nil {
+	// Trailing chunks are dropped, while growing content is read as zeros
+	if err := ensureData(ctx, tx, meta.ID); err != nil {
 		return err
-	} else {
-		// Adjust content size
-		if size < int64(len(content)) {
-			content = content[:size]
-		} else {
-			newData := make([]byte, size)
-			copy(newData, content)
-			content = newData
-		}
-
-		// Update existing data
-		_, err = tx.ExecContext(ctx, `
-			UPDATE vfs_data SET content = ?, size = ?, last_accessed = ?
-			WHERE id = ?
-		`, content, size, now, meta.ID)
 	}
-
-	if err != nil {
+	if err := truncateChunks(ctx, tx, meta.ID, size); err != nil {
+		return err
+	}
+	if err := setDataSize(ctx, tx, meta.ID, size); err != nil {
 		return err
 	}
 
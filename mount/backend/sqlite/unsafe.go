@@ -36,6 +36,113 @@ func (sb *SQLiteBackend) createMetaUnsafe(ctx context.Context, namespace string,
 		meta.CreateTime = time.Now()
 	}
 
+	if err := insertMeta(ctx, sb.db, namespace, meta); err != nil {
+		return err
+	}
+
+	// Update B-tree with namespaced key
+	sb.keys.Set(nsKey, meta.ID)
+	return nil
+}
+
+// readMetaUnsafe reads metadata without acquiring locks.
+// MUST be called while holding at least a read lock.
+func (sb *SQLiteBackend) readMetaUnsafe(ctx context.Context, namespace string, key string) (*data.Metadata, error) {
+	// Check B-tree first
+	nsKey := backend.NamespacedKey(namespace, key)
+	id, exists := sb.keys.Get(nsKey)
+	if !exists {
+		return nil, data.ErrNotExist
+	}
+
+	meta, err := readMetaByID(ctx, sb.db, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return meta, nil
+}
+
+// updateMetaUnsafe updates metadata without acquiring locks.
+// MUST be called while holding a write lock.
+func (sb *SQLiteBackend) updateMetaUnsafe(ctx context.Context, namespace string, key string, update *data.MetadataUpdate) error {
+	// Check if key exists
+	nsKey := backend.NamespacedKey(namespace, key)
+	id, exists := sb.keys.Get(nsKey)
+	if !exists {
+		return data.ErrNotExist
+	}
+
+	// Read current metadata
+	meta, err := sb.readMetaUnsafe(ctx, namespace, key)
+	if err != nil {
+		return err
+	}
+
+	// Apply update
+	meta.ModifyTime = time.Now()
+	if _, err := update.Apply(meta); err != nil {
+		return err
+	}
+
+	return updateMetaRow(ctx, sb.db, id, meta)
+}
+
+// deleteMetaUnsafe deletes metadata without acquiring locks.
+// MUST be called while holding a write lock.
+func (sb *SQLiteBackend) deleteMetaUnsafe(ctx context.Context, namespace string, key string) error {
+	// Check if key exists
+	nsKey := backend.NamespacedKey(namespace, key)
+	id, exists := sb.keys.Get(nsKey)
+	if !exists {
+		return data.ErrNotExist
+	}
+
+	// Start transaction
+	tx, err := sb.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Delete metadata
+	_, err = tx.ExecContext(ctx, "DELETE FROM vfs_metadata WHERE id = ?", id)
+	if err != nil {
+		return err
+	}
+
+	// Decrement the ref count of associated data and remove it, if no longer referenced
+	if err := releaseData(ctx, tx, id); err != nil {
+		return err
+	}
+
+	// Commit transaction
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	// Remove from B-tree using namespaced key
+	sb.keys.Delete(nsKey)
+	return nil
+}
+
+// existsMetaUnsafe checks if metadata exists without acquiring locks.
+// MUST be called while holding at least a read lock.
+func (sb *SQLiteBackend) existsMetaUnsafe(ctx context.Context, namespace string, key string) (bool, error) {
+	nsKey := backend.NamespacedKey(namespace, key)
+	_, exists := sb.keys.Get(nsKey)
+	return exists, nil
+}
+
+// querier is implemented by both *sql.DB and *sql.Tx, so statements can be shared by transactions.
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// insertMeta inserts the metadata row for meta without updating the B-tree.
+func insertMeta(ctx context.Context, q querier, namespace string, meta *data.Metadata) error {
 	// Serialize attributes to JSON
 	var attributesJSON sql.NullString
 	if len(meta.Attributes) > 0 {
@@ -48,7 +155,7 @@ func (sb *SQLiteBackend) createMetaUnsafe(ctx context.Context, namespace string,
 
 	contentType := string(meta.ContentType)
 	// Insert into database
-	_, err := sb.db.ExecContext(ctx, `
+	_, err := q.ExecContext(ctx, `
 		INSERT INTO vfs_metadata (id, namespace, key, mode, size, uid, gid, modify_time, access_time, create_time, content_type, etag, attributes)
 		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`, meta.ID, namespace, meta.Key, int(meta.Mode), meta.Size,
@@ -56,33 +163,18 @@ func (sb *SQLiteBackend) createMetaUnsafe(ctx context.Context, namespace string,
 		meta.ModifyTime.Unix(), meta.AccessTime.Unix(), meta.CreateTime.Unix(),
 		nullString(contentType), nullString(meta.ETag), attributesJSON)
 
-	if err != nil {
-		return err
-	}
-
-	// Update B-tree with namespaced key
-	sb.keys.Set(nsKey, meta.ID)
-	return nil
+	return err
 }
 
-// readMetaUnsafe reads metadata without acquiring locks.
-// MUST be called while holding at least a read lock.
-func (sb *SQLiteBackend) readMetaUnsafe(ctx context.Context, namespace string, key string) (*data.Metadata, error) {
-	// Check B-tree first
-	nsKey := backend.NamespacedKey(namespace, key)
-	id, exists := sb.keys.Get(nsKey)
-	if !exists {
-		return nil, data.ErrNotExist
-	}
-
-	// Query database
+// readMetaByID reads the metadata row with the specified id.
+func readMetaByID(ctx context.Context, q querier, id string) (*data.Metadata, error) {
 	var meta data.Metadata
 	var uid, gid sql.NullInt64
 	var contentType, etag sql.NullString
 	var attributesJSON sql.NullString
 	var modifyTime, accessTime, createTime int64
 
-	err := sb.db.QueryRowContext(ctx, `
+	err := q.QueryRowContext(ctx, `
 		SELECT id, key, mode, size, uid, gid, modify_time, access_time, create_time, content_type, etag, attributes
 		FROM vfs_metadata WHERE id = ?
 	`, id).Scan(&meta.ID, &meta.Key, &meta.Mode, &meta.Size,
@@ -124,34 +216,11 @@ func (sb *SQLiteBackend) readMetaUnsafe(ctx context.Context, namespace string, k
 		meta.Attributes = make(map[string]string)
 	}
 
-	// Update access time (in memory only for performance)
-	meta.AccessTime = time.Now()
-
 	return &meta, nil
 }
 
-// updateMetaUnsafe updates metadata without acquiring locks.
-// MUST be called while holding a write lock.
-func (sb *SQLiteBackend) updateMetaUnsafe(ctx context.Context, namespace string, key string, update *data.MetadataUpdate) error {
-	// Check if key exists
-	nsKey := backend.NamespacedKey(namespace, key)
-	id, exists := sb.keys.Get(nsKey)
-	if !exists {
-		return data.ErrNotExist
-	}
-
-	// Read current metadata
-	meta, err := sb.readMetaUnsafe(ctx, namespace, key)
-	if err != nil {
-		return err
-	}
-
-	// Apply update
-	meta.ModifyTime = time.Now()
-	if _, err := update.Apply(meta); err != nil {
-		return err
-	}
-
+// updateMetaRow writes all mutable fields of meta into the metadata row with the specified id.
+func updateMetaRow(ctx context.Context, q querier, id string, meta *data.Metadata) error {
 	// Serialize attributes to JSON
 	var attributesJSON sql.NullString
 	if len(meta.Attributes) > 0 {
@@ -164,7 +233,7 @@ func (sb *SQLiteBackend) updateMetaUnsafe(ctx context.Context, namespace string,
 
 	contentType := string(meta.ContentType)
 	// Update database
-	_, err = sb.db.ExecContext(ctx, `
+	_, err := q.ExecContext(ctx, `
 		UPDATE vfs_metadata
 		SET mode = ?, size = ?, uid = ?, gid = ?,
 		    modify_time = ?, access_time = ?, content_type = ?, etag = ?, attributes = ?
@@ -176,60 +245,3 @@ func (sb *SQLiteBackend) updateMetaUnsafe(ctx context.Context, namespace string,
 
 	return err
 }
-
-// deleteMetaUnsafe deletes metadata without acquiring locks.
-// MUST be called while holding a write lock.
-func (sb *SQLiteBackend) deleteMetaUnsafe(ctx context.Context, namespace string, key string) error {
-	// Check if key exists
-	nsKey := backend.NamespacedKey(namespace, key)
-	id, exists := sb.keys.Get(nsKey)
-	if !exists {
-		return data.ErrNotExist
-	}
-
-	// Start transaction
-	tx, err := sb.db.BeginTx(ctx, nil)
-	if err != nil {
-		return err
-	}
-	defer tx.Rollback()
-
-	// Delete metadata
-	_, err = tx.ExecContext(ctx, "DELETE FROM vfs_metadata WHERE id = ?", id)
-	if err != nil {
-		return err
-	}
-
-	// Check if there's associated data and decrement ref count
-	_, err = tx.ExecContext(ctx, `
-		UPDATE vfs_data
-		SET ref_count = ref_count - 1
-		WHERE id = (SELECT id FROM vfs_data WHERE id = ?)
-	`, id)
-	if err != nil && err != sql.ErrNoRows {
-		return err
-	}
-
-	// Delete data with ref_count = 0
-	_, err = tx.ExecContext(ctx, "DELETE FROM vfs_data WHERE ref_count = 0")
-	if err != nil {
-		return err
-	}
-
-	// Commit transaction
-	if err := tx.Commit(); err != nil {
-		return err
-	}
-
-	// Remove from B-tree using namespaced key
-	sb.keys.Delete(nsKey)
-	return nil
-}
-
-// existsMetaUnsafe checks if metadata exists without acquiring locks.
-// MUST be called while holding at least a read lock.
-func (sb *SQLiteBackend) existsMetaUnsafe(ctx context.Context, namespace string, key string) (bool, error) {
-	nsKey := backend.NamespacedKey(namespace, key)
-	_, exists := sb.keys.Get(nsKey)
-	return exists, nil
-}
@@ -0,0 +1,20 @@
+package backend
+
+import (
+	"context"
+
+	"github.com/mwantia/vfs/data"
+)
+
+// TieringBackend is implemented by object storage backends, which place objects on storage tiers of different speed
+// (e.g. a fast local disk and a slow remote bucket). Objects remain accessible with the same key on every tier.
+type TieringBackend interface {
+	ObjectStorageBackend
+
+	// GetTier returns the tier currently holding the object at key.
+	GetTier(ctx context.Context, namespace, key string) (data.StorageTier, error)
+
+	// SetTier moves the object at key to tier. Moving an object to its current tier is a no-op.
+	// Returns data.ErrInvalid for unknown tiers and data.ErrNotExist if key doesn't exist.
+	SetTier(ctx context.Context, namespace, key string, tier data.StorageTier) error
+}
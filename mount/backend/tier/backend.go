@@ -0,0 +1,118 @@
+package tier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/mwantia/vfs/data"
+	"github.com/mwantia/vfs/mount/backend"
+)
+
+// TierBackend combines a fast and a slow object storage backend into a single tiered backend:
+//
+// Every file is stored by exactly one tier, while directories exist on both tiers, so files can be moved freely.
+// New files are always created on the hot tier and remain on their tier until moved by SetTier.
+// Which tier should hold a file is decided by the tier policy of the mount, not by the backend itself.
+type TierBackend struct {
+	mu   sync.RWMutex
+	hot  backend.ObjectStorageBackend
+	cold backend.ObjectStorageBackend
+}
+
+// NewTierBackend creates a tiered backend storing frequently accessed files within hot (e.g. ephemeral or direct)
+// and rarely accessed files within cold (e.g. s3 or sqlite).
+// Both backends are owned by the returned backend and opened and closed together with it.
+func NewTierBackend(hot, cold backend.ObjectStorageBackend) (*TierBackend, error) {
+	if hot == nil || cold == nil {
+		return nil, fmt.Errorf("hot and cold backend must not be nil")
+	}
+	if hot == cold {
+		return nil, fmt.Errorf("hot and cold backend must be different")
+	}
+
+	return &TierBackend{
+		hot:  hot,
+		cold: cold,
+	}, nil
+}
+
+// Returns the identifier name defined for this backend
+func (*TierBackend) Name() string {
+	return "tier"
+}
+
+// Open is part of the lifecycle behavious and gets called when opening this backend.
+func (tb *TierBackend) Open(ctx context.Context) error {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+
+	if err := tb.hot.Open(ctx); err != nil {
+		return fmt.Errorf("failed to open hot tier '%s': %w", tb.hot.Name(), err)
+	}
+	if err := tb.cold.Open(ctx); err != nil {
+		tb.hot.Close(ctx)
+		return fmt.Errorf("failed to open cold tier '%s': %w", tb.cold.Name(), err)
+	}
+
+	return nil
+}
+
+// Close is part of the lifecycle behaviour and gets called when closing this backend.
+func (tb *TierBackend) Close(ctx context.Context) error {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+
+	return errors.Join(tb.hot.Close(ctx), tb.cold.Close(ctx))
+}
+
+// GetCapabilities returns a list of capabilities supported by this backend.
+func (tb *TierBackend) GetCapabilities() *backend.BackendCapabilities {
+	caps := &backend.BackendCapabilities{
+		Capabilities: []backend.BackendCapability{
+			backend.CapabilityObjectStorage,
+		},
+	}
+	// Files have to fit into both tiers, so they can be moved at any time
+	for _, storage := range []backend.ObjectStorageBackend{tb.hot, tb.cold} {
+		tierCaps := storage.GetCapabilities()
+		caps.MinObjectSize = max(caps.MinObjectSize, tierCaps.MinObjectSize)
+		if tierCaps.MaxObjectSize > 0 && (caps.MaxObjectSize == 0 || tierCaps.MaxObjectSize < caps.MaxObjectSize) {
+			caps.MaxObjectSize = tierCaps.MaxObjectSize
+		}
+	}
+
+	return caps
+}
+
+// tier returns the backend of tier.
+func (tb *TierBackend) tier(tier data.StorageTier) (backend.ObjectStorageBackend, error) {
+	switch tier {
+	case data.TierHot:
+		return tb.hot, nil
+	case data.TierCold:
+		return tb.cold, nil
+	}
+
+	return nil, fmt.Errorf("%w: unknown tier '%s'", data.ErrInvalid, tier)
+}
+
+// locate returns the tier holding key together with its stat.
+// Directories are always located on the hot tier, unless they are missing from it.
+func (tb *TierBackend) locate(ctx context.Context, namespace, key string) (data.StorageTier, *data.FileStat, error) {
+	stat, err := tb.hot.HeadObject(ctx, namespace, key)
+	if err == nil {
+		return data.TierHot, stat, nil
+	}
+	if err != data.ErrNotExist {
+		return "", nil, err
+	}
+
+	stat, err = tb.cold.HeadObject(ctx, namespace, key)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return data.TierCold, stat, nil
+}
@@ -0,0 +1,81 @@
+package tier
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/mwantia/vfs/data"
+	"github.com/mwantia/vfs/mount/backend"
+)
+
+// GetTier returns the tier currently holding the object at key.
+// Directories exist on both tiers and are always reported as hot.
+func (tb *TierBackend) GetTier(ctx context.Context, namespace, key string) (data.StorageTier, error) {
+	tb.mu.RLock()
+	defer tb.mu.RUnlock()
+
+	tier, _, err := tb.locate(ctx, namespace, key)
+	return tier, err
+}
+
+// SetTier moves the content of the file at key to tier. Moving directories is a no-op, since they exist on both tiers.
+// All other operations are paused while the content is copied, so the file never exists on both tiers at once.
+func (tb *TierBackend) SetTier(ctx context.Context, namespace, key string, tier data.StorageTier) error {
+	target, err := tb.tier(tier)
+	if err != nil {
+		return err
+	}
+
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+
+	current, stat, err := tb.locate(ctx, namespace, key)
+	if err != nil {
+		return err
+	}
+	if current == tier || stat.Mode.IsDir() {
+		return nil
+	}
+	source, _ := tb.tier(current)
+
+	if err := ensureDirectory(ctx, source, target, namespace, parent(key)); err != nil {
+		return fmt.Errorf("failed to create parent of '%s' on %s tier: %w", key, tier, err)
+	}
+	if _, err := target.CreateObject(ctx, namespace, key, stat.Mode); err != nil {
+		return fmt.Errorf("failed to create '%s' on %s tier: %w", key, tier, err)
+	}
+
+	if err := copyContent(ctx, source, target, namespace, key, stat.Size); err != nil {
+		// The file remains on its current tier
+		target.DeleteObject(ctx, namespace, key, true)
+		return fmt.Errorf("failed to copy '%s' to %s tier: %w", key, tier, err)
+	}
+
+	return source.DeleteObject(ctx, namespace, key, false)
+}
+
+// copyContent copies size bytes of key from source to target in chunks.
+func copyContent(ctx context.Context, source, target backend.ObjectStorageBackend, namespace, key string, size int64) error {
+	buffer := make([]byte, min(size, data.DefaultCopyChunkSize))
+	for offset := int64(0); offset < size; {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		chunk := buffer[:min(int64(len(buffer)), size-offset)]
+		n, err := source.ReadObject(ctx, namespace, key, offset, chunk)
+		if err != nil && !(err == io.EOF && n > 0) {
+			return err
+		}
+		if n == 0 {
+			return io.ErrUnexpectedEOF
+		}
+		if _, err := target.WriteObject(ctx, namespace, key, offset, chunk[:n]); err != nil {
+			return err
+		}
+		offset += int64(n)
+	}
+
+	return target.TruncateObject(ctx, namespace, key, size)
+}
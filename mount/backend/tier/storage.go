@@ -0,0 +1,192 @@
+package tier
+
+import (
+	"context"
+	"path"
+	"strings"
+
+	"github.com/mwantia/vfs/data"
+	"github.com/mwantia/vfs/mount/backend"
+)
+
+func (tb *TierBackend) CreateObject(ctx context.Context, namespace, key string, mode data.FileMode) (*data.FileStat, error) {
+	tb.mu.RLock()
+	defer tb.mu.RUnlock()
+
+	if mode.IsDir() {
+		stat, err := tb.hot.CreateObject(ctx, namespace, key, mode)
+		if err != nil {
+			return nil, err
+		}
+		// Directories exist on both tiers, so files can be moved without creating their parents
+		if err := ensureDirectory(ctx, tb.hot, tb.cold, namespace, key); err != nil {
+			return nil, err
+		}
+		return stat, nil
+	}
+
+	if _, err := tb.cold.HeadObject(ctx, namespace, key); err != data.ErrNotExist {
+		if err != nil {
+			return nil, err
+		}
+		return nil, data.ErrExist
+	}
+
+	return tb.hot.CreateObject(ctx, namespace, key, mode)
+}
+
+func (tb *TierBackend) ReadObject(ctx context.Context, namespace, key string, offset int64, dat []byte) (int, error) {
+	tb.mu.RLock()
+	defer tb.mu.RUnlock()
+
+	tier, _, err := tb.locate(ctx, namespace, key)
+	if err != nil {
+		return 0, err
+	}
+	storage, _ := tb.tier(tier)
+
+	return storage.ReadObject(ctx, namespace, key, offset, dat)
+}
+
+func (tb *TierBackend) WriteObject(ctx context.Context, namespace, key string, offset int64, dat []byte) (int, error) {
+	tb.mu.RLock()
+	defer tb.mu.RUnlock()
+
+	tier, _, err := tb.locate(ctx, namespace, key)
+	if err != nil {
+		return 0, err
+	}
+	storage, _ := tb.tier(tier)
+
+	return storage.WriteObject(ctx, namespace, key, offset, dat)
+}
+
+func (tb *TierBackend) DeleteObject(ctx context.Context, namespace, key string, force bool) error {
+	tb.mu.RLock()
+	defer tb.mu.RUnlock()
+
+	tier, stat, err := tb.locate(ctx, namespace, key)
+	if err != nil {
+		return err
+	}
+	if !stat.Mode.IsDir() {
+		storage, _ := tb.tier(tier)
+		return storage.DeleteObject(ctx, namespace, key, force)
+	}
+
+	// Both tiers are checked first, so a directory is never removed from only one of them
+	if !force {
+		children, err := tb.listObjects(ctx, namespace, key)
+		if err != nil {
+			return err
+		}
+		if len(children) > 0 {
+			return data.ErrDirectoryNotEmpty
+		}
+	}
+
+	for _, storage := range []backend.ObjectStorageBackend{tb.cold, tb.hot} {
+		if err := storage.DeleteObject(ctx, namespace, key, force); err != nil && err != data.ErrNotExist {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (tb *TierBackend) ListObjects(ctx context.Context, namespace, key string) ([]*data.FileStat, error) {
+	tb.mu.RLock()
+	defer tb.mu.RUnlock()
+
+	return tb.listObjects(ctx, namespace, key)
+}
+
+func (tb *TierBackend) HeadObject(ctx context.Context, namespace, key string) (*data.FileStat, error) {
+	tb.mu.RLock()
+	defer tb.mu.RUnlock()
+
+	_, stat, err := tb.locate(ctx, namespace, key)
+	return stat, err
+}
+
+func (tb *TierBackend) TruncateObject(ctx context.Context, namespace, key string, size int64) error {
+	tb.mu.RLock()
+	defer tb.mu.RUnlock()
+
+	tier, _, err := tb.locate(ctx, namespace, key)
+	if err != nil {
+		return err
+	}
+	storage, _ := tb.tier(tier)
+
+	return storage.TruncateObject(ctx, namespace, key, size)
+}
+
+// listObjects merges the entries of key listed by both tiers, where entries of the hot tier take precedence.
+func (tb *TierBackend) listObjects(ctx context.Context, namespace, key string) ([]*data.FileStat, error) {
+	hot, hotErr := tb.hot.ListObjects(ctx, namespace, key)
+	if hotErr != nil && hotErr != data.ErrNotExist {
+		return nil, hotErr
+	}
+	cold, coldErr := tb.cold.ListObjects(ctx, namespace, key)
+	if coldErr != nil && coldErr != data.ErrNotExist {
+		return nil, coldErr
+	}
+	if hotErr != nil && coldErr != nil {
+		return nil, hotErr
+	}
+
+	prefix := ""
+	if key != "" {
+		prefix = key + "/"
+	}
+
+	seen := make(map[string]bool, len(hot))
+	stats := make([]*data.FileStat, 0, len(hot)+len(cold))
+	for _, stat := range append(hot, cold...) {
+		// Backends return either the name or the full key of each entry
+		name := strings.TrimSuffix(strings.TrimPrefix(strings.TrimPrefix(stat.Key, prefix), "/"), "/")
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		stats = append(stats, stat)
+	}
+
+	return stats, nil
+}
+
+// ensureDirectory creates the directory key and all of its parents within target, which are missing from it.
+// The mode of each directory is taken from source.
+func ensureDirectory(ctx context.Context, source, target backend.ObjectStorageBackend, namespace, key string) error {
+	if key == "" || key == "." || key == "/" {
+		return nil
+	}
+
+	if _, err := target.HeadObject(ctx, namespace, key); err != data.ErrNotExist {
+		return err
+	}
+	if err := ensureDirectory(ctx, source, target, namespace, parent(key)); err != nil {
+		return err
+	}
+
+	mode := data.ModeDir | 0755
+	if stat, err := source.HeadObject(ctx, namespace, key); err == nil {
+		mode = stat.Mode
+	}
+	if _, err := target.CreateObject(ctx, namespace, key, mode); err != nil && err != data.ErrExist {
+		return err
+	}
+
+	return nil
+}
+
+// parent returns the key of the parent directory of key or an empty string for the root.
+func parent(key string) string {
+	dir := path.Dir(strings.TrimSuffix(key, "/"))
+	if dir == "." || dir == "/" {
+		return ""
+	}
+
+	return dir
+}
@@ -0,0 +1,75 @@
+package backend
+
+import (
+	"context"
+
+	"github.com/mwantia/vfs/data"
+)
+
+// ChangeType defines the kind of a single change applied within a transaction.
+type ChangeType int
+
+const (
+	ChangeWrite  ChangeType = iota // Create a file or replace its content
+	ChangeMkdir                    // Create a directory
+	ChangeDelete                   // Delete a file or an empty directory
+	ChangeUpdate                   // Apply a metadata update to an existing key
+)
+
+// Change describes a single staged modification of a key.
+type Change struct {
+	Type     ChangeType
+	Key      string
+	Metadata *data.Metadata       // Metadata used when creating files or directories
+	Content  []byte               // Complete content of the file (ChangeWrite only)
+	Update   *data.MetadataUpdate // Update applied to existing keys (ChangeUpdate only)
+	Base     *ChangeBase          // State of the key the change has been staged on (nil applies the change unconditionally)
+}
+
+// ChangeBase describes the state of a key, which a change has been staged on.
+type ChangeBase struct {
+	Metadata *data.Metadata // Metadata of the key while staging (nil if the key didn't exist)
+}
+
+// Matches checks if current still describes the same key as the base.
+// Directories are only compared by existence, since their modification time changes with their children.
+func (cb *ChangeBase) Matches(current *data.Metadata) bool {
+	base := cb.Metadata
+	if base == nil || current == nil {
+		return base == nil && current == nil
+	}
+	if base.Mode.IsDir() || current.Mode.IsDir() {
+		return base.Mode.IsDir() == current.Mode.IsDir()
+	}
+
+	return base.Size == current.Size && base.ModifyTime.Equal(current.ModifyTime) && base.ETag == current.ETag
+}
+
+// CheckBases verifies that no changed key has been modified since its change has been staged.
+// read returns the current metadata of a key and must be called within the same critical section the changes
+// are applied in, so concurrent changes are either detected or applied afterwards.
+// Returns data.ErrTransactionConflict for the first key no longer matching its base.
+func CheckBases(changes []*Change, read func(key string) (*data.Metadata, error)) error {
+	for _, change := range changes {
+		if change.Base == nil {
+			continue
+		}
+
+		current, err := read(change.Key)
+		if err != nil && err != data.ErrNotExist {
+			return err
+		}
+		if !change.Base.Matches(current) {
+			return data.ErrTransactionConflict
+		}
+	}
+
+	return nil
+}
+
+// TransactionBackend is an optional capability for backends, which are able to apply multiple changes atomically.
+// Either all changes are applied or none, and concurrent readers never observe a partially applied set of changes.
+type TransactionBackend interface {
+	// ApplyChanges applies all changes in order within a single transaction.
+	ApplyChanges(ctx context.Context, namespace string, changes []*Change) error
+}
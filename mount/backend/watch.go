@@ -0,0 +1,16 @@
+package backend
+
+import (
+	"context"
+
+	"github.com/mwantia/vfs/data"
+)
+
+// WatchBackend is implemented by object storage backends, which are able to observe changes
+// made outside of the VFS (e.g. by other processes or other VFS instances sharing the storage).
+type WatchBackend interface {
+	// WatchObjects starts observing changes and returns once the watch has been established.
+	// Every observed change is reported to notify until ctx is cancelled, after which the returned
+	// channel is closed. Paths within reported events are object keys, like any other key.
+	WatchObjects(ctx context.Context, namespace string, notify func(event data.Event)) (<-chan struct{}, error)
+}
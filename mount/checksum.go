@@ -0,0 +1,58 @@
+package mount
+
+import (
+	"context"
+
+	"github.com/mwantia/vfs/data"
+	"github.com/mwantia/vfs/mount/backend"
+)
+
+// NewObjectReader creates a reader for the object key, starting at offset and ending at size.
+// It is independent of any streamer, so it never interferes with offsets of opened files.
+func NewObjectReader(ctx context.Context, storage backend.ObjectStorageBackend, namespace, key string, offset, size int64) *data.RangeReader {
+	return data.NewRangeReader(ctx, func(ctx context.Context, offset int64, p []byte) (int, error) {
+		return storage.ReadObject(ctx, namespace, key, offset, p)
+	}, offset, size)
+}
+
+// ComputeChecksum computes the content hash of the object at path using the specified algorithm.
+func (m *Mount) ComputeChecksum(ctx context.Context, path string, algorithm data.ChecksumAlgorithm) (data.Checksum, error) {
+	namespace := m.Options.Namespace
+
+	stat, err := m.ObjectStorage.HeadObject(ctx, namespace, path)
+	if err != nil {
+		return data.Checksum{}, err
+	}
+
+	if stat.Mode.IsDir() {
+		return data.Checksum{}, data.ErrIsDirectory
+	}
+
+	reader := NewObjectReader(ctx, m.ObjectStorage, namespace, path, 0, stat.Size)
+	return data.ComputeChecksum(reader, algorithm)
+}
+
+// UpdateChecksum computes the content hash of the object at path and stores it within metadata.
+// This is a no-op if checksums are disabled or the mount has no metadata backend.
+func (m *Mount) UpdateChecksum(ctx context.Context, path string) error {
+	if m.Options.Checksum == "" || m.Metadata == nil {
+		return nil
+	}
+
+	checksum, err := m.ComputeChecksum(ctx, path, m.Options.Checksum)
+	if err != nil {
+		return err
+	}
+
+	m.log.Debug("UpdateChecksum: storing checksum %s for %s", checksum, path)
+	update := &data.MetadataUpdate{
+		Mask: data.MetadataUpdateStorageHash,
+		Metadata: &data.Metadata{
+			Attributes: map[string]string{
+				data.AttributeChecksum: checksum.String(),
+			},
+		},
+	}
+
+	return m.Metadata.UpdateMeta(ctx, m.Options.Namespace, path, update)
+}
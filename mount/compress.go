@@ -0,0 +1,55 @@
+package mount
+
+import (
+	"context"
+	"maps"
+
+	"github.com/mwantia/vfs/data"
+	"github.com/mwantia/vfs/mount/backend"
+)
+
+// UpdateContentEncoding stores the algorithm used to compress the object at path as content encoding within metadata.
+// The attribute is removed for objects stored as is. This is a no-op for mounts without compression or metadata backend.
+func (m *Mount) UpdateContentEncoding(ctx context.Context, path string) error {
+	compress, ok := m.ObjectStorage.(backend.CompressBackend)
+	if !ok || m.Metadata == nil {
+		return nil
+	}
+
+	algorithm, err := compress.ContentEncoding(ctx, m.Options.Namespace, path)
+	if err != nil {
+		return err
+	}
+
+	meta, err := m.Metadata.ReadMeta(ctx, m.Options.Namespace, path)
+	if err != nil {
+		return err
+	}
+
+	encoding := string(algorithm)
+	if algorithm == data.CompressionNone {
+		encoding = ""
+	}
+	if meta.GetAttribute(data.AttributeContentEncoding, "") == encoding {
+		return nil
+	}
+
+	attributes := maps.Clone(meta.Attributes)
+	if attributes == nil {
+		attributes = make(map[string]string)
+	}
+	if encoding != "" {
+		attributes[data.AttributeContentEncoding] = encoding
+	} else {
+		delete(attributes, data.AttributeContentEncoding)
+	}
+
+	m.log.Debug("UpdateContentEncoding: storing content encoding '%s' for %s", encoding, path)
+	return m.Metadata.UpdateMeta(ctx, m.Options.Namespace, path, &data.MetadataUpdate{
+		Mask: data.MetadataUpdateAttributes | data.MetadataUpdateModifyTime,
+		Metadata: &data.Metadata{
+			ModifyTime: meta.ModifyTime,
+			Attributes: attributes,
+		},
+	})
+}
@@ -0,0 +1,207 @@
+package mount
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/mwantia/vfs/data"
+	"github.com/mwantia/vfs/mount/backend"
+)
+
+// Fsck compares the metadata of this mount against its object storage and reports every discrepancy.
+// Discrepancies are repaired by rebuilding metadata from object storage or pruning stale metadata, if requested.
+// Files currently opened through this mount are skipped, since their metadata is only synced on close.
+// Returns data.ErrNotSupported for mounts without metadata backend.
+func (m *Mount) Fsck(ctx context.Context, opts *data.FsckOptions) (*data.FsckReport, error) {
+	report := &data.FsckReport{
+		Path: m.Path,
+	}
+
+	if m.Metadata == nil {
+		return nil, data.ErrNotSupported
+	}
+	// Both layers are stored by the same backend, so they can't drift apart
+	if m.IsDualMount {
+		return report, nil
+	}
+
+	namespace := m.Options.Namespace
+	result, err := m.Metadata.QueryMeta(ctx, namespace, &backend.MetadataQuery{})
+	if err != nil {
+		return nil, err
+	}
+
+	metas := make(map[string]*data.Metadata, len(result.Candidates))
+	for _, meta := range result.Candidates {
+		if meta.Key != "" {
+			metas[meta.Key] = meta
+		}
+	}
+
+	stats := make(map[string]*data.FileStat)
+	if err := m.walkObjects(ctx, "", func(key string, stat *data.FileStat) error {
+		stats[key] = stat
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(metas)+len(stats))
+	for key := range metas {
+		keys = append(keys, key)
+	}
+	for key := range stats {
+		if _, exists := metas[key]; !exists {
+			keys = append(keys, key)
+		}
+	}
+	// Parents are always checked before their children, so missing parents are rebuilt first
+	slices.Sort(keys)
+
+	var pruned []*data.FsckIssue
+	for _, key := range keys {
+		if _, open := m.GetStreamer(key); open {
+			continue
+		}
+
+		report.Checked++
+		meta, stat := metas[key], stats[key]
+		issue := checkEntry(key, meta, stat)
+		if issue == nil {
+			continue
+		}
+		report.Issues = append(report.Issues, issue)
+
+		switch issue.Type {
+		case data.FsckMissingObject, data.FsckOrphanDirectory:
+			if opts.Prune {
+				pruned = append(pruned, issue)
+			}
+		default:
+			if opts.Repair {
+				if err := m.repairEntry(ctx, issue, meta, stat); err != nil {
+					return nil, fmt.Errorf("failed to repair '%s': %w", key, err)
+				}
+				issue.Repaired = true
+				report.Repaired++
+			}
+		}
+	}
+
+	// Children are pruned before their parents
+	for _, issue := range slices.Backward(pruned) {
+		if err := m.Metadata.DeleteMeta(ctx, namespace, issue.Key); err != nil && err != data.ErrNotExist {
+			return nil, fmt.Errorf("failed to prune '%s': %w", issue.Key, err)
+		}
+		issue.Repaired = true
+		report.Repaired++
+	}
+
+	return report, nil
+}
+
+// checkEntry compares the metadata and object stat of key and returns the discrepancy found (nil if consistent).
+func checkEntry(key string, meta *data.Metadata, stat *data.FileStat) *data.FsckIssue {
+	switch {
+	case stat == nil && meta.Mode.IsDir():
+		return &data.FsckIssue{Type: data.FsckOrphanDirectory, Key: key}
+	case stat == nil:
+		return &data.FsckIssue{Type: data.FsckMissingObject, Key: key}
+	case meta == nil:
+		return &data.FsckIssue{Type: data.FsckMissingMetadata, Key: key}
+	case meta.Mode.IsDir() != stat.Mode.IsDir():
+		return &data.FsckIssue{Type: data.FsckTypeMismatch, Key: key, Metadata: string(meta.GetType()), Storage: string(stat.ToMetadata().GetType())}
+	case meta.Mode.IsDir():
+		return nil
+	case meta.Size != stat.Size:
+		return &data.FsckIssue{Type: data.FsckSizeMismatch, Key: key, Metadata: fmt.Sprint(meta.Size), Storage: fmt.Sprint(stat.Size)}
+	case meta.ETag != "" && stat.ETag != "" && meta.ETag != stat.ETag:
+		return &data.FsckIssue{Type: data.FsckETagMismatch, Key: key, Metadata: meta.ETag, Storage: stat.ETag}
+	}
+
+	return nil
+}
+
+// repairEntry rebuilds the metadata of a single entry from its object stat.
+// Existing attributes and ownership are preserved, unless the type of the entry changed.
+func (m *Mount) repairEntry(ctx context.Context, issue *data.FsckIssue, meta *data.Metadata, stat *data.FileStat) error {
+	namespace := m.Options.Namespace
+
+	rebuilt := stat.ToMetadata()
+	rebuilt.Key = issue.Key
+
+	switch issue.Type {
+	case data.FsckTypeMismatch:
+		if err := m.Metadata.DeleteMeta(ctx, namespace, issue.Key); err != nil && err != data.ErrNotExist {
+			return err
+		}
+		return m.Metadata.CreateMeta(ctx, namespace, rebuilt)
+	case data.FsckMissingMetadata:
+		return m.Metadata.CreateMeta(ctx, namespace, rebuilt)
+	}
+
+	return m.Metadata.UpdateMeta(ctx, namespace, issue.Key, &data.MetadataUpdate{
+		Mask:     data.MetadataUpdateSize | data.MetadataUpdateETag,
+		Metadata: rebuilt,
+	})
+}
+
+// walkObjects lists key recursively within object storage and calls fn for every file and directory below key.
+func (m *Mount) walkObjects(ctx context.Context, key string, fn func(key string, stat *data.FileStat) error) error {
+	children, err := m.listObjects(ctx, key)
+	if err != nil {
+		return err
+	}
+
+	for _, child := range children {
+		if err := fn(child.Key, child); err != nil {
+			return err
+		}
+		if child.Mode.IsDir() {
+			if err := m.walkObjects(ctx, child.Key, fn); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// listObjects returns the direct children of key within object storage.
+// The key of each returned stat is normalized to the full mount-relative key without trailing slash.
+func (m *Mount) listObjects(ctx context.Context, key string) ([]*data.FileStat, error) {
+	return listStorage(ctx, m.ObjectStorage, m.Options.Namespace, key)
+}
+
+// listStorage returns the direct children of key within storage, normalized like listObjects.
+func listStorage(ctx context.Context, storage backend.ObjectStorageBackend, namespace, key string) ([]*data.FileStat, error) {
+	stats, err := storage.ListObjects(ctx, namespace, key)
+	if err != nil {
+		if err == data.ErrNotExist && key == "" {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	prefix := ""
+	if key != "" {
+		prefix = key + "/"
+	}
+
+	children := make([]*data.FileStat, 0, len(stats))
+	for _, stat := range stats {
+		// Backends return either the name or the full mount-relative key of each entry
+		name := strings.TrimPrefix(strings.TrimPrefix(stat.Key, prefix), "/")
+		if name == "" || stat.Key == key {
+			continue
+		}
+
+		child := *stat
+		child.Key = prefix + strings.TrimSuffix(name, "/")
+		children = append(children, &child)
+	}
+
+	return children, nil
+}
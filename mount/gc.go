@@ -0,0 +1,84 @@
+package mount
+
+import (
+	"context"
+
+	"github.com/mwantia/vfs/data"
+	"github.com/mwantia/vfs/mount/backend"
+)
+
+// CollectGarbage removes content no longer referenced within this mount.
+// Backends implementing backend.GarbageCollectBackend sweep their own storage. On mounts with a separate
+// metadata backend, objects without metadata are removed as well if opts.SweepObjects is set.
+func (m *Mount) CollectGarbage(ctx context.Context, opts *data.GCOptions) (*data.GCReport, error) {
+	report := &data.GCReport{
+		Path:   m.Path,
+		DryRun: opts.DryRun,
+	}
+
+	if gc, ok := m.ObjectStorage.(backend.GarbageCollectBackend); ok {
+		result, err := gc.CollectGarbage(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+		report.Merge(result)
+	}
+
+	if m.Metadata == nil || m.IsDualMount {
+		return report, nil
+	}
+
+	if gc, ok := m.Metadata.(backend.GarbageCollectBackend); ok {
+		result, err := gc.CollectGarbage(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+		report.Merge(result)
+	}
+
+	if opts.SweepObjects {
+		if err := m.sweepObjects(ctx, opts, report); err != nil {
+			return nil, err
+		}
+	}
+
+	return report, nil
+}
+
+// sweepObjects removes all files without metadata within object storage.
+// Files currently opened through this mount are never removed, regardless of the grace period.
+func (m *Mount) sweepObjects(ctx context.Context, opts *data.GCOptions, report *data.GCReport) error {
+	namespace := m.Options.Namespace
+
+	return m.walkObjects(ctx, "", func(key string, stat *data.FileStat) error {
+		if stat.Mode.IsDir() {
+			return nil
+		}
+
+		report.Scanned++
+		if exists, err := m.Metadata.ExistsMeta(ctx, namespace, key); exists {
+			return nil
+		} else if err != nil && err != data.ErrNotExist {
+			return err
+		}
+		if _, open := m.GetStreamer(key); open {
+			return nil
+		}
+
+		deferred := !opts.Expired(stat.ModifyTime)
+		report.Orphan(key, stat.Size, deferred)
+		if deferred || opts.DryRun {
+			return nil
+		}
+
+		m.log.Debug("CollectGarbage: removing object %s without metadata from mount %s", key, m.Path)
+		if err := m.ObjectStorage.DeleteObject(ctx, namespace, key, false); err != nil && err != data.ErrNotExist {
+			return err
+		}
+		if m.HasQuotas() {
+			m.RefundQuota(QuotaDelta{Bytes: stat.Size, Objects: 1})
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,263 @@
+package mount
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/mwantia/vfs/data"
+	"github.com/mwantia/vfs/mount/backend"
+)
+
+// IsLockShared checks if locks of this mount are shared with other processes through the backend.
+func (m *Mount) IsLockShared() bool {
+	_, ok := m.ObjectStorage.(backend.LockBackend)
+	return ok
+}
+
+// getLockBackend returns the backend used for locks, falling back to in-process locks.
+func (m *Mount) getLockBackend() backend.LockBackend {
+	if locks, ok := m.ObjectStorage.(backend.LockBackend); ok {
+		return locks
+	}
+
+	return m.locks
+}
+
+// AcquireLock tries to acquire lock once. Returns data.ErrLocked if a conflicting lock is held.
+func (m *Mount) AcquireLock(ctx context.Context, lock *data.LockInfo) error {
+	if err := m.getLockBackend().AcquireLock(ctx, m.Options.Namespace, lock); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	m.held[lock.ID] = lock
+	m.mu.Unlock()
+
+	m.log.Debug("AcquireLock: acquired %s lock %s on %s for %s", lock.Type, lock.ID, lock.Key, lock.Owner)
+	return nil
+}
+
+// RefreshLock extends the lease of lock. Returns data.ErrLockNotHeld if the lock has expired.
+func (m *Mount) RefreshLock(ctx context.Context, lock *data.LockInfo) error {
+	if err := m.getLockBackend().RefreshLock(ctx, m.Options.Namespace, lock); err != nil {
+		if err == data.ErrLockNotHeld {
+			m.forgetLock(lock)
+		}
+		return err
+	}
+
+	return nil
+}
+
+// ReleaseLock releases lock.
+func (m *Mount) ReleaseLock(ctx context.Context, lock *data.LockInfo) error {
+	m.forgetLock(lock)
+
+	if err := m.getLockBackend().ReleaseLock(ctx, m.Options.Namespace, lock); err != nil {
+		return err
+	}
+
+	m.log.Debug("ReleaseLock: released lock %s on %s", lock.ID, lock.Key)
+	return nil
+}
+
+// ListLocks returns all unexpired locks held on key.
+func (m *Mount) ListLocks(ctx context.Context, key string) ([]*data.LockInfo, error) {
+	return m.getLockBackend().ListLocks(ctx, m.Options.Namespace, key)
+}
+
+// CheckMandatoryLocks verifies that owner can access the byte range of key with flags, if mandatory locking is enabled.
+// Writing conflicts with any overlapping lock of another owner, while reading only conflicts with exclusive locks.
+// A length of 0 covers everything from offset until the end of the file.
+func (m *Mount) CheckMandatoryLocks(ctx context.Context, key string, owner string, flags data.AccessMode, offset, length int64) error {
+	if !m.Options.MandatoryLocks {
+		return nil
+	}
+
+	locks, err := m.ListLocks(ctx, key)
+	if err != nil {
+		return err
+	}
+
+	for _, lock := range locks {
+		if (owner != "" && lock.Owner == owner) || !lock.Overlaps(offset, length) {
+			continue
+		}
+		if flags&(data.AccessModeWrite|data.AccessModeAppend|data.AccessModeTrunc) != 0 || lock.Type == data.LockExclusive {
+			m.log.Error("CheckMandatoryLocks: %s is locked by %s", key, lock.Owner)
+			return data.ErrLocked
+		}
+	}
+
+	return nil
+}
+
+// CheckMandatoryLocksRecursive verifies CheckMandatoryLocks for the whole key and, if key is a directory, all of its descendants.
+func (m *Mount) CheckMandatoryLocksRecursive(ctx context.Context, key string, owner string, flags data.AccessMode) error {
+	if !m.Options.MandatoryLocks {
+		return nil
+	}
+	if err := m.CheckMandatoryLocks(ctx, key, owner, flags, 0, 0); err != nil {
+		return err
+	}
+
+	if m.Metadata != nil {
+		result, err := m.Metadata.QueryMeta(ctx, m.Options.Namespace, &backend.MetadataQuery{
+			Prefix: key + "/",
+		})
+		if err != nil {
+			return err
+		}
+		for _, meta := range result.Candidates {
+			if err := m.CheckMandatoryLocks(ctx, meta.Key, owner, flags, 0, 0); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if key != "" {
+		stat, err := m.ObjectStorage.HeadObject(ctx, m.Options.Namespace, key)
+		if err != nil {
+			// Missing paths are reported by the operation itself
+			if err == data.ErrNotExist {
+				return nil
+			}
+			return err
+		}
+		if !stat.Mode.IsDir() {
+			return nil
+		}
+	}
+
+	children, err := m.listObjects(ctx, key)
+	if err != nil {
+		return err
+	}
+	for _, child := range children {
+		if err := m.CheckMandatoryLocksRecursive(ctx, child.Key, owner, flags); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// releaseLocks releases all locks acquired through this mount.
+func (m *Mount) releaseLocks(ctx context.Context) {
+	m.mu.Lock()
+	held := make([]*data.LockInfo, 0, len(m.held))
+	for _, lock := range m.held {
+		held = append(held, lock)
+	}
+	m.held = make(map[string]*data.LockInfo)
+	m.mu.Unlock()
+
+	for _, lock := range held {
+		if err := m.getLockBackend().ReleaseLock(ctx, m.Options.Namespace, lock); err != nil {
+			m.log.Warn("Unmount: failed to release lock %s on %s - %v", lock.ID, lock.Key, err)
+		}
+	}
+}
+
+// forgetLock removes lock from the locks acquired through this mount.
+func (m *Mount) forgetLock(lock *data.LockInfo) {
+	m.mu.Lock()
+	delete(m.held, lock.ID)
+	m.mu.Unlock()
+}
+
+// localLocks implements in-process locks for backends without their own lock support.
+type localLocks struct {
+	mu    sync.Mutex
+	locks map[string]map[string]*data.LockInfo // Locks keyed by namespaced key and lock id
+}
+
+func newLocalLocks() *localLocks {
+	return &localLocks{
+		locks: make(map[string]map[string]*data.LockInfo),
+	}
+}
+
+// AcquireLock tries to acquire lock once, without waiting for conflicting locks to be released.
+func (ll *localLocks) AcquireLock(ctx context.Context, namespace string, lock *data.LockInfo) error {
+	ll.mu.Lock()
+	defer ll.mu.Unlock()
+
+	key := backend.NamespacedKey(namespace, lock.Key)
+	for _, held := range ll.activeUnsafe(key) {
+		if held.Conflicts(lock) {
+			return data.ErrLocked
+		}
+	}
+
+	if ll.locks[key] == nil {
+		ll.locks[key] = make(map[string]*data.LockInfo)
+	}
+	ll.locks[key][lock.ID] = lock.Clone()
+
+	return nil
+}
+
+// RefreshLock extends the lease of lock, so it doesn't expire.
+func (ll *localLocks) RefreshLock(ctx context.Context, namespace string, lock *data.LockInfo) error {
+	ll.mu.Lock()
+	defer ll.mu.Unlock()
+
+	key := backend.NamespacedKey(namespace, lock.Key)
+	ll.activeUnsafe(key)
+
+	held, exists := ll.locks[key][lock.ID]
+	if !exists {
+		return data.ErrLockNotHeld
+	}
+
+	held.Renew(time.Now())
+	lock.Expires = held.Expires
+	return nil
+}
+
+// ReleaseLock releases lock. Releasing an expired lock is not an error.
+func (ll *localLocks) ReleaseLock(ctx context.Context, namespace string, lock *data.LockInfo) error {
+	ll.mu.Lock()
+	defer ll.mu.Unlock()
+
+	key := backend.NamespacedKey(namespace, lock.Key)
+	delete(ll.locks[key], lock.ID)
+	if len(ll.locks[key]) == 0 {
+		delete(ll.locks, key)
+	}
+
+	return nil
+}
+
+// ListLocks returns all unexpired locks held on key.
+func (ll *localLocks) ListLocks(ctx context.Context, namespace string, key string) ([]*data.LockInfo, error) {
+	ll.mu.Lock()
+	defer ll.mu.Unlock()
+
+	active := ll.activeUnsafe(backend.NamespacedKey(namespace, key))
+	locks := make([]*data.LockInfo, 0, len(active))
+	for _, lock := range active {
+		locks = append(locks, lock.Clone())
+	}
+
+	return locks, nil
+}
+
+// activeUnsafe removes all expired locks of key and returns the remaining locks.
+// MUST be called while holding the lock.
+func (ll *localLocks) activeUnsafe(key string) map[string]*data.LockInfo {
+	now := time.Now()
+	for id, lock := range ll.locks[key] {
+		if lock.IsExpired(now) {
+			delete(ll.locks[key], id)
+		}
+	}
+	if len(ll.locks[key]) == 0 {
+		delete(ll.locks, key)
+	}
+
+	return ll.locks[key]
+}
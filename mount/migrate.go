@@ -0,0 +1,631 @@
+package mount
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/mwantia/vfs/data"
+	"github.com/mwantia/vfs/mount/backend"
+)
+
+// changeLog collects all paths changed by operations while a migration is running.
+type changeLog struct {
+	mu       sync.Mutex
+	paths    map[string]struct{}
+	overflow bool // Events have been dropped, so everything has to be synced again
+}
+
+// record adds the paths changed by event.
+func (cl *changeLog) record(event data.Event) {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+
+	switch event.Type {
+	case data.EventMount, data.EventUnmount:
+		return
+	case data.EventOverflow:
+		cl.overflow = true
+		return
+	}
+
+	cl.paths[event.Path] = struct{}{}
+	if event.OldPath != "" {
+		cl.paths[event.OldPath] = struct{}{}
+	}
+}
+
+// drain returns and removes all recorded paths.
+func (cl *changeLog) drain() ([]string, bool) {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+
+	paths := make([]string, 0, len(cl.paths))
+	for path := range cl.paths {
+		paths = append(paths, path)
+	}
+	overflow := cl.overflow
+
+	clear(cl.paths)
+	cl.overflow = false
+	return paths, overflow
+}
+
+// pending checks if any change has been recorded since the last drain.
+func (cl *changeLog) pending() bool {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+
+	return len(cl.paths) > 0 || cl.overflow
+}
+
+// contains checks if path has been changed since the last drain.
+func (cl *changeLog) contains(path string) bool {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+
+	_, exists := cl.paths[path]
+	return exists || cl.overflow
+}
+
+// Migration copies all objects and metadata of a mount to a new set of backends, while the mount remains in use.
+// Changes made during the copy are recorded through the events of the mount and synced in further passes,
+// until Switch replaces the backends of the mount. Either Switch or Abort must be called to release the new backends.
+type Migration struct {
+	mnt      *Mount
+	opts     *data.MigrateOptions
+	changes  *changeLog
+	report   *data.MigrateReport
+	storage  backend.ObjectStorageBackend
+	metadata backend.MetadataBackend
+	isDual   bool
+	root     string // Key of the mount root within object storage (path prefix)
+}
+
+// Migrate starts the migration of this mount to storage and metadata (nil for mounts without metadata).
+// Both backends are opened and all changes are recorded from now on.
+// Returns data.ErrBusy if another migration is already running for this mount.
+func (m *Mount) Migrate(ctx context.Context, storage backend.ObjectStorageBackend, metadata backend.MetadataBackend, opts *data.MigrateOptions) (*Migration, error) {
+	if storage == nil || backend.Backend(storage) == backend.Backend(m.ObjectStorage) {
+		return nil, data.ErrInvalid
+	}
+	// Metadata can't be dropped, since it holds ownership and attributes not stored within objects
+	if m.Metadata != nil && metadata == nil {
+		return nil, fmt.Errorf("%w: mount at %s requires a metadata backend", data.ErrInvalid, m.Path)
+	}
+
+	mg := &Migration{
+		mnt:  m,
+		opts: opts,
+		changes: &changeLog{
+			paths: make(map[string]struct{}),
+		},
+		report: &data.MigrateReport{
+			Path:   m.Path,
+			Source: m.ObjectStorage.Name(),
+			Target: storage.Name(),
+		},
+		storage:  storage,
+		metadata: metadata,
+		root:     strings.Trim(m.Options.PathPrefix, "/"),
+	}
+	if primary, ok := storage.(backend.MetadataBackend); ok && primary == metadata {
+		mg.isDual = true
+	}
+
+	for _, vb := range mg.getBackends() {
+		m.log.Debug("Migrate: opening backend %s", vb.Name())
+		if err := vb.Open(ctx); err != nil {
+			mg.closeBackends(ctx, nil)
+			return nil, err
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.changes != nil {
+		mg.closeBackends(ctx, nil)
+		return nil, data.ErrBusy
+	}
+	m.changes = mg.changes
+
+	m.log.Info("Migrate: migrating from %s to %s", mg.report.Source, mg.report.Target)
+	return mg, nil
+}
+
+// Copy copies all entries of the mount into the new backends and syncs all changes made in the meantime,
+// until either no changes are left or the maximum number of passes has been reached.
+// If requested, the checksum of every file is verified afterwards.
+func (mg *Migration) Copy(ctx context.Context) error {
+	m := mg.mnt
+	namespace := m.Options.Namespace
+
+	// Parents of the path prefix are created first, since they are not part of the mount itself
+	if mg.root != "" {
+		parts := strings.Split(mg.root, "/")
+		for i := range parts {
+			key := strings.Join(parts[:i+1], "/")
+			stat, err := m.ObjectStorage.HeadObject(ctx, namespace, key)
+			if err != nil {
+				return err
+			}
+			if err := mg.copyEntry(ctx, key, stat); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := mg.syncTree(ctx, mg.root); err != nil {
+		return err
+	}
+
+	rounds := mg.opts.MaxRounds
+	if rounds <= 0 {
+		rounds = data.DefaultMigrateRounds
+	}
+	for range rounds {
+		if !mg.changes.pending() {
+			break
+		}
+		if _, err := mg.syncChanges(ctx); err != nil {
+			return err
+		}
+	}
+
+	if mg.opts.Verify {
+		return mg.verify(ctx, []string{mg.root})
+	}
+
+	return nil
+}
+
+// Switch syncs all remaining changes and replaces the backends of the mount with the new backends.
+// The previous backends are closed, unless they are still used by the mount (e.g. as extension).
+// Must be called while no operation can access the mount, so no change can be missed.
+// Returns data.ErrBusy if locks are held, which are stored within the previous object storage.
+func (mg *Migration) Switch(ctx context.Context) (*data.MigrateReport, error) {
+	m := mg.mnt
+
+	m.mu.RLock()
+	streamers := make([]*MountStreamer, 0, len(m.streamers))
+	for _, streamer := range m.streamers {
+		streamers = append(streamers, streamer)
+	}
+	held := len(m.held)
+	m.mu.RUnlock()
+
+	_, previousLocks := m.ObjectStorage.(backend.LockBackend)
+	_, targetLocks := mg.storage.(backend.LockBackend)
+	if held > 0 && (previousLocks || targetLocks) {
+		m.log.Error("Switch: %d lock(s) are held within %s", held, mg.report.Source)
+		return nil, data.ErrBusy
+	}
+
+	// Opened files keep accessing the backends of the mount, so they are paused as well
+	for _, streamer := range streamers {
+		streamer.mu.Lock()
+		defer streamer.mu.Unlock()
+	}
+
+	m.stopBackendWatch()
+	synced, err := mg.syncChanges(ctx)
+	if err == nil && mg.opts.Verify {
+		err = mg.verify(ctx, synced)
+	}
+	if err != nil {
+		m.startBackendWatch()
+		return nil, err
+	}
+
+	previous := m.getUniqueBackends()
+
+	m.mu.Lock()
+	m.ObjectStorage = mg.storage
+	m.Metadata = mg.metadata
+	m.IsDualMount = mg.isDual
+	if mg.metadata != nil {
+		m.Options.Backends[backend.CapabilityMetadata] = mg.metadata
+	} else {
+		delete(m.Options.Backends, backend.CapabilityMetadata)
+	}
+	m.changes = nil
+	m.mu.Unlock()
+
+	m.log.Info("Switch: switched from %s to %s after %d pass(es)", mg.report.Source, mg.report.Target, mg.report.Rounds)
+
+	current := m.getUniqueBackends()
+	for _, vb := range previous {
+		if slices.Contains(current, vb) {
+			continue
+		}
+		// The mount already uses the new backends, so failures are only reported
+		m.log.Debug("Switch: closing previous backend %s", vb.Name())
+		if err := vb.Close(ctx); err != nil {
+			m.log.Warn("Switch: failed to close previous backend %s - %v", vb.Name(), err)
+		}
+	}
+
+	m.startBackendWatch()
+	return mg.report, nil
+}
+
+// Abort stops recording changes and closes the new backends, which keep all content copied so far.
+func (mg *Migration) Abort(ctx context.Context) {
+	m := mg.mnt
+
+	m.mu.Lock()
+	if m.changes == mg.changes {
+		m.changes = nil
+	}
+	m.mu.Unlock()
+
+	mg.closeBackends(ctx, m.getUniqueBackends())
+	m.log.Info("Abort: aborted migration from %s to %s", mg.report.Source, mg.report.Target)
+}
+
+// getBackends returns the new backends without duplicates.
+func (mg *Migration) getBackends() []backend.Backend {
+	backends := []backend.Backend{mg.storage}
+	if mg.metadata != nil && !mg.isDual {
+		backends = append(backends, mg.metadata)
+	}
+
+	return backends
+}
+
+// closeBackends closes all new backends, except those contained by used.
+func (mg *Migration) closeBackends(ctx context.Context, used []backend.Backend) {
+	for _, vb := range mg.getBackends() {
+		if slices.Contains(used, vb) {
+			continue
+		}
+		if err := vb.Close(ctx); err != nil {
+			mg.mnt.log.Warn("Migrate: failed to close backend %s - %v", vb.Name(), err)
+		}
+	}
+}
+
+// toKey converts an absolute path of this mount into its object key, reverting ToAbsolutePath.
+func (mg *Migration) toKey(absolute string) string {
+	relative := data.ToRelativePath(absolute, mg.mnt.Path)
+	return strings.Trim(path.Join(mg.root, relative), "/")
+}
+
+// isChanged checks if key has been changed since the last pass.
+func (mg *Migration) isChanged(key string) bool {
+	absolute, ok := mg.mnt.ToAbsolutePath(key)
+	return ok && mg.changes.contains(absolute)
+}
+
+// syncChanges syncs all entries changed since the last pass and returns their keys.
+func (mg *Migration) syncChanges(ctx context.Context) ([]string, error) {
+	m := mg.mnt
+	namespace := m.Options.Namespace
+
+	paths, overflow := mg.changes.drain()
+	mg.report.Rounds++
+	if overflow {
+		m.log.Debug("Migrate: syncing all entries, since changes have been dropped")
+		return []string{mg.root}, mg.syncTree(ctx, mg.root)
+	}
+
+	keys := make([]string, 0, len(paths))
+	for _, path := range paths {
+		keys = append(keys, mg.toKey(path))
+	}
+	// Parents are always synced before their children
+	slices.Sort(keys)
+
+	m.log.Debug("Migrate: syncing %d changed entries", len(keys))
+	for _, key := range keys {
+		stat, err := m.ObjectStorage.HeadObject(ctx, namespace, key)
+		if err == data.ErrNotExist {
+			removed, err := mg.removeEntry(ctx, key)
+			if err != nil {
+				return nil, err
+			}
+			if removed {
+				mg.report.Removed++
+			}
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		mg.report.Synced++
+		if stat.Mode.IsDir() {
+			err = mg.syncTree(ctx, key)
+		} else {
+			err = mg.copyEntry(ctx, key, stat)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return keys, nil
+}
+
+// syncTree copies the directory at key including all descendants and removes entries no longer present.
+func (mg *Migration) syncTree(ctx context.Context, key string) error {
+	m := mg.mnt
+	namespace := m.Options.Namespace
+
+	if key != "" && key != mg.root {
+		stat, err := m.ObjectStorage.HeadObject(ctx, namespace, key)
+		if err != nil {
+			return err
+		}
+		if err := mg.copyEntry(ctx, key, stat); err != nil {
+			return err
+		}
+	}
+
+	children, err := m.listObjects(ctx, key)
+	if err != nil {
+		return err
+	}
+	existing, err := listStorage(ctx, mg.storage, namespace, key)
+	if err != nil && err != data.ErrNotExist {
+		return err
+	}
+
+	present := make(map[string]struct{}, len(children))
+	for _, child := range children {
+		present[child.Key] = struct{}{}
+	}
+	for _, stat := range existing {
+		if _, exists := present[stat.Key]; exists {
+			continue
+		}
+		if _, err := mg.removeEntry(ctx, stat.Key); err != nil {
+			return err
+		}
+	}
+
+	for _, child := range children {
+		if child.Mode.IsDir() {
+			err = mg.syncTree(ctx, child.Key)
+		} else {
+			err = mg.copyEntry(ctx, child.Key, child)
+		}
+		// Entries removed in the meantime are synced by the next pass
+		if err == data.ErrNotExist && mg.isChanged(child.Key) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// copyEntry copies the object at key and its metadata into the new backends, replacing any previous copy.
+func (mg *Migration) copyEntry(ctx context.Context, key string, stat *data.FileStat) error {
+	m := mg.mnt
+	namespace := m.Options.Namespace
+
+	existing, err := mg.storage.HeadObject(ctx, namespace, key)
+	switch {
+	case err == data.ErrNotExist:
+		existing = nil
+	case err != nil:
+		return err
+	case existing.Mode.IsDir() != stat.Mode.IsDir():
+		if _, err := mg.removeEntry(ctx, key); err != nil {
+			return err
+		}
+		existing = nil
+	}
+
+	if stat.Mode.IsDir() {
+		if existing == nil {
+			if _, err := mg.storage.CreateObject(ctx, namespace, key, stat.Mode); err != nil {
+				return err
+			}
+		}
+		mg.report.Directories++
+	} else {
+		if existing == nil {
+			_, err = mg.storage.CreateObject(ctx, namespace, key, stat.Mode)
+		} else {
+			err = mg.storage.TruncateObject(ctx, namespace, key, 0)
+		}
+		if err != nil {
+			return err
+		}
+
+		size, err := mg.copyContent(ctx, key, stat.Size)
+		if err != nil {
+			return err
+		}
+		mg.report.Files++
+		mg.report.Bytes += size
+	}
+
+	if err := mg.copyMeta(ctx, key, stat); err != nil {
+		return err
+	}
+
+	if mg.opts.Progress != nil {
+		mg.opts.Progress(key, mg.report.Directories+mg.report.Files)
+	}
+	return nil
+}
+
+// copyContent copies size bytes of the object at key in chunks and returns the amount of bytes copied.
+func (mg *Migration) copyContent(ctx context.Context, key string, size int64) (int64, error) {
+	m := mg.mnt
+	namespace := m.Options.Namespace
+
+	reader := NewObjectReader(ctx, m.ObjectStorage, namespace, key, 0, size)
+	buffer := make([]byte, min(size, data.DefaultCopyChunkSize))
+
+	var offset int64
+	for offset < size {
+		n, err := reader.Read(buffer)
+		if err != nil {
+			return offset, err
+		}
+		if _, err := mg.storage.WriteObject(ctx, namespace, key, offset, buffer[:n]); err != nil {
+			return offset, err
+		}
+		offset += int64(n)
+	}
+
+	return offset, nil
+}
+
+// copyMeta copies the metadata of key into the new metadata backend.
+// Objects without metadata are adopted, while size and etag always describe the object within the new storage.
+func (mg *Migration) copyMeta(ctx context.Context, key string, stat *data.FileStat) error {
+	if mg.metadata == nil {
+		return nil
+	}
+
+	m := mg.mnt
+	namespace := m.Options.Namespace
+
+	var meta *data.Metadata
+	if m.Metadata != nil {
+		previous, err := m.Metadata.ReadMeta(ctx, namespace, key)
+		if err != nil && err != data.ErrNotExist {
+			return err
+		}
+		meta = previous
+	}
+	if meta == nil {
+		meta = stat.ToMetadata()
+		meta.Key = key
+	}
+
+	target, err := mg.storage.HeadObject(ctx, namespace, key)
+	if err != nil {
+		return err
+	}
+	meta.Size, meta.ETag = target.Size, target.ETag
+
+	// Backends report missing metadata either as false or as data.ErrNotExist
+	exists, err := mg.metadata.ExistsMeta(ctx, namespace, key)
+	if err != nil && err != data.ErrNotExist {
+		return err
+	}
+	if !exists {
+		return mg.metadata.CreateMeta(ctx, namespace, meta)
+	}
+
+	return mg.metadata.UpdateMeta(ctx, namespace, key, &data.MetadataUpdate{
+		Mask:     data.MetadataUpdateAll &^ data.MetadataUpdateKey,
+		Metadata: meta,
+	})
+}
+
+// removeEntry removes key including all descendants from the new backends.
+// Returns false if the entry didn't exist within the new backends.
+func (mg *Migration) removeEntry(ctx context.Context, key string) (bool, error) {
+	namespace := mg.mnt.Options.Namespace
+
+	removed := false
+	if err := mg.storage.DeleteObject(ctx, namespace, key, true); err != nil && err != data.ErrNotExist {
+		return false, err
+	} else if err == nil {
+		removed = true
+	}
+
+	if mg.metadata == nil || mg.isDual {
+		return removed, nil
+	}
+
+	result, err := mg.metadata.QueryMeta(ctx, namespace, &backend.MetadataQuery{
+		Prefix: key + "/",
+	})
+	if err != nil {
+		return false, err
+	}
+
+	keys := []string{key}
+	for _, meta := range result.Candidates {
+		keys = append(keys, meta.Key)
+	}
+	// Children are always removed before their parents
+	slices.Sort(keys)
+	for _, key := range slices.Backward(keys) {
+		if err := mg.metadata.DeleteMeta(ctx, namespace, key); err != nil && err != data.ErrNotExist {
+			return false, err
+		}
+	}
+
+	return removed, nil
+}
+
+// verify compares the checksum of every file at or below keys between both object storages.
+// Files changed since the last pass are skipped, since they are synced and verified again.
+// Returns data.ErrChecksumMismatch for the first file with differing content.
+func (mg *Migration) verify(ctx context.Context, keys []string) error {
+	m := mg.mnt
+	namespace := m.Options.Namespace
+	algorithm := mg.opts.GetChecksum(m.Options.Checksum)
+
+	checksum := func(storage backend.ObjectStorageBackend, key string) (data.Checksum, error) {
+		stat, err := storage.HeadObject(ctx, namespace, key)
+		if err != nil {
+			return data.Checksum{}, err
+		}
+
+		return data.ComputeChecksum(NewObjectReader(ctx, storage, namespace, key, 0, stat.Size), algorithm)
+	}
+
+	check := func(key string, stat *data.FileStat) error {
+		if stat.Mode.IsDir() {
+			return nil
+		}
+
+		expected, err := checksum(m.ObjectStorage, key)
+		if err != nil {
+			return err
+		}
+		actual, err := checksum(mg.storage, key)
+		if err != nil && err != data.ErrNotExist {
+			return err
+		}
+
+		if err != nil || !expected.Equal(actual) {
+			if mg.isChanged(key) {
+				return nil
+			}
+			return fmt.Errorf("%w: %s", data.ErrChecksumMismatch, key)
+		}
+
+		mg.report.Verified++
+		return nil
+	}
+
+	for _, key := range keys {
+		stat := &data.FileStat{Mode: data.ModeDir}
+		if key != "" {
+			var err error
+			if stat, err = m.ObjectStorage.HeadObject(ctx, namespace, key); err == data.ErrNotExist {
+				continue
+			} else if err != nil {
+				return err
+			}
+		}
+
+		if err := check(key, stat); err != nil {
+			return err
+		}
+		if stat.Mode.IsDir() {
+			if err := m.walkObjects(ctx, key, check); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -3,6 +3,7 @@ package mount
 import (
 	"context"
 	"fmt"
+	"maps"
 	"sync"
 	"time"
 
@@ -10,6 +11,7 @@ import (
 	"github.com/mwantia/vfs/data/errors"
 	"github.com/mwantia/vfs/log"
 	"github.com/mwantia/vfs/mount/backend"
+	"github.com/mwantia/vfs/mount/backend/compress"
 	"github.com/mwantia/vfs/mount/extension/acl"
 	"github.com/mwantia/vfs/mount/extension/cache"
 	"github.com/mwantia/vfs/mount/extension/encrypt"
@@ -24,6 +26,14 @@ type Mount struct {
 	mu        sync.RWMutex
 	log       *log.Logger
 	streamers map[string]*MountStreamer
+	locks     *localLocks
+	held      map[string]*data.LockInfo // Locks acquired through this mount keyed by id
+	quota     *quotaState               // Tracked usage (nil if no quota applies)
+
+	notifier    Notifier
+	watchCancel context.CancelFunc
+	watchDone   <-chan struct{}
+	changes     *changeLog // Paths changed during a running migration (nil otherwise)
 
 	Path        string
 	Options     *MountOptions
@@ -53,6 +63,9 @@ func NewMountInfo(path string, log *log.Logger, primary backend.ObjectStorageBac
 	mnt := &Mount{
 		log:       log,
 		streamers: make(map[string]*MountStreamer),
+		locks:     newLocalLocks(),
+		held:      make(map[string]*data.LockInfo),
+		quota:     newQuotaState(options),
 
 		Path:          path,
 		Options:       options,
@@ -200,6 +213,12 @@ func NewMountInfo(path string, log *log.Logger, primary backend.ObjectStorageBac
 		mnt.Versioning = versioning
 	}
 
+	if options.TierPolicy != nil {
+		if _, ok := primary.(backend.TieringBackend); !ok {
+			return nil, fmt.Errorf("backend '%s' does not support tiering", primary.Name())
+		}
+	}
+
 	if !mnt.IsDualMount {
 		primaryAsMetadata, ok := primary.(backend.MetadataBackend)
 		// Additional fallback check and validation
@@ -209,19 +228,33 @@ func NewMountInfo(path string, log *log.Logger, primary backend.ObjectStorageBac
 		}
 	}
 
+	// Metadata keeps describing the uncompressed content, so it can't be stored by the compressed backend
+	if options.Compression != nil {
+		if mnt.IsDualMount {
+			return nil, fmt.Errorf("compression requires a separate metadata backend")
+		}
+
+		storage, err := compress.NewCompressBackend(primary, options.Compression)
+		if err != nil {
+			return nil, err
+		}
+		mnt.ObjectStorage = storage
+	}
+
 	return mnt, nil
 }
 
 func (m *Mount) Mount(ctx context.Context) error {
 	m.mu.RLock()
-	defer m.mu.RUnlock()
+	backends := m.getUniqueBackends()
+	m.mu.RUnlock()
 
 	m.log.Info("Mount: initializing mount")
-	m.log.Debug("Mount: opening %d unique backend(s)", len(m.getUniqueBackends()))
+	m.log.Debug("Mount: opening %d unique backend(s)", len(backends))
 
 	errs := errors.Errors{}
 	// Open all backends/extensions set to this mount
-	for _, vb := range m.getUniqueBackends() {
+	for _, vb := range backends {
 		m.log.Debug("Mount: opening backend %s", vb.Name())
 		if err := vb.Open(ctx); err != nil {
 			m.log.Error("Mount: failed to open backend %s - %v", vb.Name(), err)
@@ -236,20 +269,31 @@ func (m *Mount) Mount(ctx context.Context) error {
 		return errs.Errors()
 	}
 
+	// Usage is measured once and maintained incrementally afterwards
+	if err := m.initQuotas(ctx); err != nil {
+		m.log.Error("Mount: failed to measure usage - %v", err)
+		return err
+	}
+
+	m.startBackendWatch()
+
 	m.log.Info("Mount: mount initialized successfully")
 	return nil
 }
 
 func (m *Mount) Unmount(ctx context.Context, force bool) error {
+	// Streamers are collected first, since closing them requires the mount lock
 	m.mu.RLock()
-	defer m.mu.RUnlock()
+	streamers := make(map[string]*MountStreamer, len(m.streamers))
+	maps.Copy(streamers, m.streamers)
+	m.mu.RUnlock()
 
 	m.log.Info("Unmount: unmounting (force=%v)", force)
-	m.log.Debug("Unmount: checking %d active streamer(s)", len(m.streamers))
+	m.log.Debug("Unmount: checking %d active streamer(s)", len(streamers))
 
 	if !force {
 		// Initial check, to see if we have any busy streamers
-		for path, streamer := range m.streamers {
+		for path, streamer := range streamers {
 			if streamer.IsBusy() {
 				m.log.Error("Unmount: streamer for %s is busy, cannot unmount", path)
 				// Fail, since we shouldn't unmount busy backends
@@ -259,7 +303,7 @@ func (m *Mount) Unmount(ctx context.Context, force bool) error {
 	}
 
 	closedStreamers := 0
-	for path, streamer := range m.streamers {
+	for path, streamer := range streamers {
 		if streamer.IsBusy() && !force {
 			m.log.Error("Unmount: streamer for %s is busy, cannot unmount", path)
 			return data.ErrBusy
@@ -278,6 +322,13 @@ func (m *Mount) Unmount(ctx context.Context, force bool) error {
 		m.log.Debug("Unmount: closed %d streamer(s)", closedStreamers)
 	}
 
+	// Stop observing the backend before it gets closed
+	m.stopBackendWatch()
+	// Locks shared through the backend would otherwise remain until their lease expires
+	m.releaseLocks(ctx)
+	// Usage of this mount no longer counts towards the namespace quota
+	m.releaseQuotas()
+
 	m.log.Debug("Unmount: closing %d unique backend(s)", len(m.getUniqueBackends()))
 	errs := errors.Errors{}
 	// Open all backends/extensions set to this mount
@@ -330,12 +381,11 @@ func (m *Mount) OpenStreamer(ctx context.Context, path string, offset int64, fla
 }
 
 func (m *Mount) CloseStreamer(ctx context.Context, path string, force bool) error {
-	m.mu.Lock()
-	defer m.mu.Unlock()
-
 	m.log.Debug("CloseStreamer: closing streamer for %s (force=%v)", path, force)
-
+	// The lock is only held for the lookup, since closing unregisters the streamer
+	m.mu.RLock()
 	streamer, exists := m.streamers[path]
+	m.mu.RUnlock()
 	if !exists {
 		m.log.Error("CloseStreamer: no streamer found for %s", path)
 		return data.ErrNotExist
@@ -351,10 +401,20 @@ func (m *Mount) CloseStreamer(ctx context.Context, path string, force bool) erro
 		return err
 	}
 
-	m.log.Debug("CloseStreamer: streamer closed successfully (remaining active=%d)", len(m.streamers)-1)
+	m.log.Debug("CloseStreamer: streamer closed successfully for %s", path)
 	return nil
 }
 
+// removeStreamer unregisters the streamer for path, unless it has already been replaced.
+func (m *Mount) removeStreamer(path string, streamer *MountStreamer) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if current, exists := m.streamers[path]; exists && current == streamer {
+		delete(m.streamers, path)
+	}
+}
+
 // getUniqueBackends returns a list of unique backends without duplicates
 func (m *Mount) getUniqueBackends() []backend.Backend {
 	// Create list of all available backends
@@ -3,7 +3,9 @@ package mount
 import (
 	"fmt"
 
+	"github.com/mwantia/vfs/data"
 	"github.com/mwantia/vfs/mount/backend"
+	"github.com/mwantia/vfs/mount/backend/compress"
 )
 
 type MountOptions struct {
@@ -16,6 +18,18 @@ type MountOptions struct {
 	CacheWrites    bool // Buffer writes before upload
 	IsReadOnly     bool // Whether the mount is read-only.
 	AllowNesting   bool // Whether the mount allows for nested mountpoints.
+
+	Checksum     data.ChecksumAlgorithm // Algorithm used to hash content on write close (empty disables hashing).
+	WatchBackend bool                   // Whether changes made outside of the VFS are observed through the backend.
+
+	MandatoryLocks bool // Whether opening files honors locks held by other owners.
+
+	Quota      data.Quota           // Quota of the whole mount (zero is unlimited).
+	UserQuotas map[int64]data.Quota // Quotas per owning UID within this mount.
+
+	TierPolicy *data.TierPolicy // Policy moving files between tiers (requires a tiering backend).
+
+	Compression *compress.CompressBackendConfig // Compresses all content stored within the object storage (nil disables compression).
 }
 
 type MountOption func(*MountOptions) error
@@ -25,6 +39,7 @@ func newDefaultMountOptions() *MountOptions {
 	return &MountOptions{
 		Backends:     make(map[backend.BackendCapability]backend.Backend),
 		AllowNesting: true,
+		Checksum:     data.DefaultChecksumAlgorithm,
 	}
 }
 
@@ -105,3 +120,95 @@ func IsReadOnly() MountOption {
 		return nil
 	}
 }
+
+// WithChecksum specifies the algorithm used to store content hashes when written files are closed.
+func WithChecksum(algorithm data.ChecksumAlgorithm) MountOption {
+	return func(vmo *MountOptions) error {
+		if !algorithm.IsValid() {
+			return fmt.Errorf("%w: %s", data.ErrChecksumUnsupported, algorithm)
+		}
+
+		vmo.Checksum = algorithm
+		return nil
+	}
+}
+
+// DisableChecksums specifies, that no content hashes are computed for this mount.
+func DisableChecksums() MountOption {
+	return func(vmo *MountOptions) error {
+		vmo.Checksum = ""
+		return nil
+	}
+}
+
+// WithBackendWatch specifies, that changes made outside of the VFS are observed through the backend.
+// Backends also report changes made by the VFS itself, so every operation is reported twice to watchers.
+func WithBackendWatch() MountOption {
+	return func(vmo *MountOptions) error {
+		vmo.WatchBackend = true
+		return nil
+	}
+}
+
+// WithMandatoryLocks specifies, that opening files fails while conflicting locks are held by other owners.
+func WithMandatoryLocks() MountOption {
+	return func(vmo *MountOptions) error {
+		vmo.MandatoryLocks = true
+		return nil
+	}
+}
+
+// WithQuota limits the total size and number of objects stored within this mount.
+func WithQuota(quota data.Quota) MountOption {
+	return func(vmo *MountOptions) error {
+		if quota.MaxBytes < 0 || quota.MaxObjects < 0 {
+			return fmt.Errorf("%w: negative quota", data.ErrInvalid)
+		}
+
+		vmo.Quota = quota
+		return nil
+	}
+}
+
+// WithUserQuota limits the total size and number of objects owned by uid within this mount.
+func WithUserQuota(uid int64, quota data.Quota) MountOption {
+	return func(vmo *MountOptions) error {
+		if uid < 0 || quota.MaxBytes < 0 || quota.MaxObjects < 0 {
+			return fmt.Errorf("%w: negative quota", data.ErrInvalid)
+		}
+
+		if vmo.UserQuotas == nil {
+			vmo.UserQuotas = make(map[int64]data.Quota)
+		}
+		vmo.UserQuotas[uid] = quota
+		return nil
+	}
+}
+
+// WithTierPolicy specifies the policy deciding which tier holds each file, if the mount uses a tiering backend.
+func WithTierPolicy(policy *data.TierPolicy) MountOption {
+	return func(vmo *MountOptions) error {
+		if policy == nil {
+			return fmt.Errorf("%w: missing tier policy", data.ErrInvalid)
+		}
+		if err := policy.Validate(); err != nil {
+			return err
+		}
+
+		vmo.TierPolicy = policy
+		return nil
+	}
+}
+
+// WithCompression specifies, that all content is compressed before being stored within the object storage.
+// A nil config uses zstd with the default frame size.
+func WithCompression(config *compress.CompressBackendConfig) MountOption {
+	return func(vmo *MountOptions) error {
+		if config == nil {
+			config = &compress.CompressBackendConfig{}
+		}
+
+		vmo.Compression = config
+		return nil
+	}
+}
@@ -0,0 +1,422 @@
+package mount
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/mwantia/vfs/data"
+	"github.com/mwantia/vfs/mount/backend"
+)
+
+// quotaMu guards all quota usage, since namespace counters are shared between mounts.
+var quotaMu sync.Mutex
+
+// QuotaDelta describes a change of the storage used by objects of a single owner.
+type QuotaDelta struct {
+	UID     int64
+	Bytes   int64
+	Objects int64
+}
+
+// QuotaCounter tracks the usage of all mounts sharing the same namespace quota.
+type QuotaCounter struct {
+	usage data.QuotaUsage
+}
+
+// quotaState tracks the usage of a single mount and the counters it contributes to.
+type quotaState struct {
+	mount     data.QuotaUsage
+	users     map[int64]*data.QuotaUsage
+	namespace *QuotaCounter
+}
+
+// NewQuotaCounter creates a counter for the namespace quota, which is shared between mounts.
+func NewQuotaCounter(quota data.Quota) *QuotaCounter {
+	return &QuotaCounter{
+		usage: data.QuotaUsage{Quota: quota},
+	}
+}
+
+// Usage returns a snapshot of the usage tracked by the counter.
+func (qc *QuotaCounter) Usage() data.QuotaUsage {
+	quotaMu.Lock()
+	defer quotaMu.Unlock()
+
+	return qc.usage
+}
+
+// newQuotaState creates the quota state for the configured quotas or nil, if no quota is configured.
+func newQuotaState(options *MountOptions) *quotaState {
+	if options.Quota.IsUnlimited() && len(options.UserQuotas) == 0 {
+		return nil
+	}
+
+	return &quotaState{
+		mount: data.QuotaUsage{Quota: options.Quota},
+		users: make(map[int64]*data.QuotaUsage),
+	}
+}
+
+// SetNamespaceQuota adds this mount to the shared usage of its namespace.
+// Must be called before the mount is initialized.
+func (m *Mount) SetNamespaceQuota(counter *QuotaCounter) {
+	if m.quota == nil {
+		m.quota = &quotaState{
+			users: make(map[int64]*data.QuotaUsage),
+		}
+	}
+
+	m.quota.namespace = counter
+}
+
+// HasQuotas checks if any quota applies to this mount, which requires usage to be tracked.
+func (m *Mount) HasQuotas() bool {
+	return m.quota != nil
+}
+
+// ChargeQuota adds deltas to the tracked usage, if no quota would be exceeded by them.
+// Only growing usage is validated, so objects can always be shrunk or removed.
+// Returns data.ErrQuotaExceeded without changing the usage if any quota would be exceeded.
+func (m *Mount) ChargeQuota(deltas ...QuotaDelta) error {
+	if m.quota == nil {
+		return nil
+	}
+
+	quotaMu.Lock()
+	defer quotaMu.Unlock()
+
+	var total QuotaDelta
+	users := make(map[int64]*QuotaDelta)
+	for _, delta := range deltas {
+		total.Bytes += delta.Bytes
+		total.Objects += delta.Objects
+
+		user, exists := users[delta.UID]
+		if !exists {
+			user = &QuotaDelta{UID: delta.UID}
+			users[delta.UID] = user
+		}
+		user.Bytes += delta.Bytes
+		user.Objects += delta.Objects
+	}
+
+	if err := checkQuota("mount", &m.quota.mount, total); err != nil {
+		return err
+	}
+	if m.quota.namespace != nil {
+		if err := checkQuota(fmt.Sprintf("namespace '%s'", m.Options.Namespace), &m.quota.namespace.usage, total); err != nil {
+			return err
+		}
+	}
+	for uid, delta := range users {
+		if err := checkQuota(fmt.Sprintf("user %d", uid), m.getUserUsage(uid), *delta); err != nil {
+			return err
+		}
+	}
+
+	m.applyQuota(total, users)
+	return nil
+}
+
+// RefundQuota removes deltas from the tracked usage, e.g. after a failed operation was charged.
+func (m *Mount) RefundQuota(deltas ...QuotaDelta) {
+	if m.quota == nil {
+		return
+	}
+
+	quotaMu.Lock()
+	defer quotaMu.Unlock()
+
+	var total QuotaDelta
+	users := make(map[int64]*QuotaDelta)
+	for _, delta := range deltas {
+		total.Bytes -= delta.Bytes
+		total.Objects -= delta.Objects
+
+		user, exists := users[delta.UID]
+		if !exists {
+			user = &QuotaDelta{UID: delta.UID}
+			users[delta.UID] = user
+		}
+		user.Bytes -= delta.Bytes
+		user.Objects -= delta.Objects
+	}
+
+	m.applyQuota(total, users)
+}
+
+// Usage returns the storage used by this mount.
+// Tracked usage is returned if quotas are configured, otherwise the usage is measured by walking the mount.
+func (m *Mount) Usage(ctx context.Context) (*data.Usage, error) {
+	usage := &data.Usage{
+		Path:      m.Path,
+		Namespace: m.Options.Namespace,
+		Users:     make(map[int64]*data.QuotaUsage),
+	}
+
+	if dedup, ok := m.ObjectStorage.(backend.DedupBackend); ok {
+		stats, err := dedup.DedupStats(ctx)
+		if err != nil {
+			return nil, err
+		}
+		usage.Dedup = stats
+	}
+
+	if compress, ok := m.ObjectStorage.(backend.CompressBackend); ok {
+		stats, err := compress.CompressionStats(ctx, m.Options.Namespace)
+		if err != nil {
+			return nil, err
+		}
+		usage.Compression = stats
+	}
+
+	if m.quota == nil {
+		deltas, err := m.MeasureUsage(ctx, "")
+		if err != nil {
+			return nil, err
+		}
+
+		for _, delta := range deltas {
+			usage.Mount.Bytes += delta.Bytes
+			usage.Mount.Objects += delta.Objects
+			usage.Users[delta.UID] = &data.QuotaUsage{
+				Bytes:   delta.Bytes,
+				Objects: delta.Objects,
+			}
+		}
+
+		return usage, nil
+	}
+
+	quotaMu.Lock()
+	defer quotaMu.Unlock()
+
+	usage.Mount = m.quota.mount
+	if m.quota.namespace != nil {
+		shared := m.quota.namespace.usage
+		usage.NamespaceUsage = &shared
+	}
+	for uid, user := range m.quota.users {
+		clone := *user
+		usage.Users[uid] = &clone
+	}
+	// Users with quota but without objects are reported as well
+	for uid, quota := range m.Options.UserQuotas {
+		if _, exists := usage.Users[uid]; !exists {
+			usage.Users[uid] = &data.QuotaUsage{Quota: quota}
+		}
+	}
+
+	return usage, nil
+}
+
+// MeasureUsage walks key and all of its descendants and returns the storage used per owner.
+// The mount root itself is never counted as an object.
+func (m *Mount) MeasureUsage(ctx context.Context, key string) ([]QuotaDelta, error) {
+	usage := make(map[int64]*QuotaDelta)
+	add := func(uid, size int64) {
+		delta, exists := usage[uid]
+		if !exists {
+			delta = &QuotaDelta{UID: uid}
+			usage[uid] = delta
+		}
+		delta.Bytes += size
+		delta.Objects++
+	}
+
+	var err error
+	if m.Metadata != nil && m.IsDualMount {
+		err = m.measureMetadata(ctx, key, add)
+	} else {
+		err = m.measureStorage(ctx, key, add)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	deltas := make([]QuotaDelta, 0, len(usage))
+	for _, delta := range usage {
+		deltas = append(deltas, *delta)
+	}
+
+	return deltas, nil
+}
+
+// measureMetadata measures key using a single recursive metadata query.
+func (m *Mount) measureMetadata(ctx context.Context, key string, add func(uid, size int64)) error {
+	namespace := m.Options.Namespace
+
+	prefix := ""
+	if key != "" {
+		meta, err := m.Metadata.ReadMeta(ctx, namespace, key)
+		if err != nil {
+			return err
+		}
+
+		add(meta.UID, meta.Size)
+		if !meta.Mode.IsDir() {
+			return nil
+		}
+		prefix = key + "/"
+	}
+
+	result, err := m.Metadata.QueryMeta(ctx, namespace, &backend.MetadataQuery{
+		Prefix: prefix,
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, meta := range result.Candidates {
+		if meta.Key == "" || meta.Key == key {
+			continue
+		}
+		add(meta.UID, meta.Size)
+	}
+
+	return nil
+}
+
+// measureStorage measures key by listing object storage recursively.
+// Owners are resolved using a separate metadata backend, if available.
+func (m *Mount) measureStorage(ctx context.Context, key string, add func(uid, size int64)) error {
+	namespace := m.Options.Namespace
+
+	owner := func(key string) int64 {
+		if m.Metadata == nil {
+			return 0
+		}
+		if meta, err := m.Metadata.ReadMeta(ctx, namespace, key); err == nil {
+			return meta.UID
+		}
+		return 0
+	}
+
+	if key != "" {
+		stat, err := m.ObjectStorage.HeadObject(ctx, namespace, key)
+		if err != nil {
+			return err
+		}
+
+		add(owner(key), stat.Size)
+		if !stat.Mode.IsDir() {
+			return nil
+		}
+	}
+
+	stats, err := m.ObjectStorage.ListObjects(ctx, namespace, key)
+	if err != nil {
+		if err == data.ErrNotExist && key == "" {
+			return nil
+		}
+		return err
+	}
+
+	prefix := ""
+	if key != "" {
+		prefix = key + "/"
+	}
+
+	for _, stat := range stats {
+		// Backends return either the name or the full mount-relative key of each entry
+		name := strings.TrimPrefix(strings.TrimPrefix(stat.Key, prefix), "/")
+		if name == "" || stat.Key == key {
+			continue
+		}
+
+		child := prefix + name
+		if stat.Mode.IsDir() {
+			if err := m.measureStorage(ctx, child, add); err != nil {
+				return err
+			}
+			continue
+		}
+
+		add(owner(child), stat.Size)
+	}
+
+	return nil
+}
+
+// initQuotas measures the current usage of the mount, once all backends have been opened.
+func (m *Mount) initQuotas(ctx context.Context) error {
+	if m.quota == nil {
+		return nil
+	}
+
+	deltas, err := m.MeasureUsage(ctx, "")
+	if err != nil {
+		return err
+	}
+
+	quotaMu.Lock()
+	defer quotaMu.Unlock()
+
+	var total QuotaDelta
+	users := make(map[int64]*QuotaDelta, len(deltas))
+	for _, delta := range deltas {
+		total.Bytes += delta.Bytes
+		total.Objects += delta.Objects
+		users[delta.UID] = &delta
+	}
+
+	m.applyQuota(total, users)
+	m.log.Debug("Mount: measured usage of %d bytes in %d object(s)", total.Bytes, total.Objects)
+	return nil
+}
+
+// releaseQuotas removes the usage of this mount from the shared namespace usage.
+func (m *Mount) releaseQuotas() {
+	if m.quota == nil || m.quota.namespace == nil {
+		return
+	}
+
+	quotaMu.Lock()
+	defer quotaMu.Unlock()
+
+	m.quota.namespace.usage.Bytes -= m.quota.mount.Bytes
+	m.quota.namespace.usage.Objects -= m.quota.mount.Objects
+}
+
+// applyQuota adds the total and per user deltas to the usage. Must be called with quotaMu held.
+func (m *Mount) applyQuota(total QuotaDelta, users map[int64]*QuotaDelta) {
+	m.quota.mount.Bytes += total.Bytes
+	m.quota.mount.Objects += total.Objects
+
+	if m.quota.namespace != nil {
+		m.quota.namespace.usage.Bytes += total.Bytes
+		m.quota.namespace.usage.Objects += total.Objects
+	}
+
+	for uid, delta := range users {
+		user := m.getUserUsage(uid)
+		user.Bytes += delta.Bytes
+		user.Objects += delta.Objects
+	}
+}
+
+// getUserUsage returns the usage of uid, which is created on first use. Must be called with quotaMu held.
+func (m *Mount) getUserUsage(uid int64) *data.QuotaUsage {
+	user, exists := m.quota.users[uid]
+	if !exists {
+		user = &data.QuotaUsage{Quota: m.Options.UserQuotas[uid]}
+		m.quota.users[uid] = user
+	}
+
+	return user
+}
+
+// checkQuota validates that delta doesn't grow usage beyond its quota.
+func checkQuota(scope string, usage *data.QuotaUsage, delta QuotaDelta) error {
+	quota := usage.Quota
+	if delta.Bytes > 0 && quota.MaxBytes > 0 && usage.Bytes+delta.Bytes > quota.MaxBytes {
+		return fmt.Errorf("%w: %s exceeds %d bytes", data.ErrQuotaExceeded, scope, quota.MaxBytes)
+	}
+	if delta.Objects > 0 && quota.MaxObjects > 0 && usage.Objects+delta.Objects > quota.MaxObjects {
+		return fmt.Errorf("%w: %s exceeds %d objects", data.ErrQuotaExceeded, scope, quota.MaxObjects)
+	}
+
+	return nil
+}
@@ -0,0 +1,327 @@
+package mount
+
+import (
+	"context"
+	"io"
+	"maps"
+	"sync"
+
+	"github.com/mwantia/vfs/data"
+	"github.com/mwantia/vfs/mount/backend"
+)
+
+// sniffSize is the number of bytes read to detect the content type of files with unknown extensions.
+const sniffSize = 512
+
+// Reindex rebuilds the metadata of all entries within object storage, e.g. after pairing existing content
+// with a fresh metadata backend. Directories are listed concurrently and entries are stored in batches.
+// Existing attributes and ownership are preserved. Completed directories are marked until the run has finished,
+// so an interrupted run can be resumed using opts.Resume. Returns data.ErrNotSupported for mounts without metadata backend.
+func (m *Mount) Reindex(ctx context.Context, opts *data.ReindexOptions) (*data.ReindexReport, error) {
+	if m.Metadata == nil {
+		return nil, data.ErrNotSupported
+	}
+
+	report := &data.ReindexReport{
+		Path: m.Path,
+	}
+	// Metadata is always written together with the objects themselves
+	if m.IsDualMount {
+		return report, nil
+	}
+
+	concurrency, batchSize := opts.Concurrency, opts.BatchSize
+	if concurrency <= 0 {
+		concurrency = data.DefaultReindexConcurrency
+	}
+	if batchSize <= 0 {
+		batchSize = data.DefaultReindexBatchSize
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	r := &reindexer{
+		mnt:       m,
+		opts:      opts,
+		report:    report,
+		batchSize: batchSize,
+		slots:     make(chan struct{}, concurrency),
+		cancel:    cancel,
+	}
+
+	r.visit(ctx, "")
+	r.wg.Wait()
+	if r.err != nil {
+		// Markers are kept, so the run can be resumed
+		return nil, r.err
+	}
+
+	// Markers are only required while the run is incomplete
+	for _, key := range r.completed {
+		if err := m.setReindexMarker(ctx, key, false); err != nil {
+			return nil, err
+		}
+	}
+
+	return report, nil
+}
+
+// reindexer holds the state shared by all directories indexed concurrently during a single run.
+type reindexer struct {
+	mu        sync.Mutex
+	wg        sync.WaitGroup
+	mnt       *Mount
+	opts      *data.ReindexOptions
+	report    *data.ReindexReport
+	batchSize int
+	slots     chan struct{} // Limits the number of directories indexed in parallel
+	completed []string      // Directories marked as completed
+	err       error
+	cancel    context.CancelFunc
+}
+
+// visit indexes the directory at key in the background and continues with all of its subdirectories.
+// Slots are only held while indexing a single directory, so subdirectories never wait for their parents.
+func (r *reindexer) visit(ctx context.Context, key string) {
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+
+		select {
+		case r.slots <- struct{}{}:
+		case <-ctx.Done():
+			r.fail(ctx.Err())
+			return
+		}
+
+		dirs, err := r.indexDirectory(ctx, key)
+		<-r.slots
+		if err != nil {
+			r.fail(err)
+			return
+		}
+
+		for _, dir := range dirs {
+			r.visit(ctx, dir)
+		}
+	}()
+}
+
+// fail stores the first error and stops all remaining directories.
+func (r *reindexer) fail(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.err == nil {
+		r.err = err
+		r.cancel()
+	}
+}
+
+// indexDirectory stores the metadata of all direct children of key and returns the keys of all subdirectories.
+// Files are skipped if the directory has already been completed by an interrupted run.
+func (r *reindexer) indexDirectory(ctx context.Context, key string) ([]string, error) {
+	m := r.mnt
+	namespace := m.Options.Namespace
+
+	completed := false
+	if key != "" && r.opts.Resume {
+		meta, err := m.Metadata.ReadMeta(ctx, namespace, key)
+		if err != nil && err != data.ErrNotExist {
+			return nil, err
+		}
+		completed = meta != nil && meta.HasAttribute(data.AttributeReindex)
+	}
+
+	children, err := m.listObjects(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	var dirs []string
+	var skipped, created, updated int64
+	batch := make([]*data.Metadata, 0, r.batchSize)
+	existing := make(map[string]bool, r.batchSize)
+
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := m.putMetaBatch(ctx, batch, existing); err != nil {
+			return err
+		}
+
+		for _, meta := range batch {
+			if existing[meta.Key] {
+				updated++
+			} else {
+				created++
+			}
+		}
+		batch = batch[:0]
+		clear(existing)
+		return nil
+	}
+
+	for _, stat := range children {
+		if stat.Mode.IsDir() {
+			dirs = append(dirs, stat.Key)
+		}
+		if completed {
+			if !stat.Mode.IsDir() {
+				skipped++
+			}
+			continue
+		}
+
+		previous, err := m.Metadata.ReadMeta(ctx, namespace, stat.Key)
+		if err != nil && err != data.ErrNotExist {
+			return nil, err
+		}
+
+		meta, err := r.rebuild(ctx, stat, previous)
+		if err != nil {
+			return nil, err
+		}
+
+		batch = append(batch, meta)
+		existing[meta.Key] = previous != nil
+		if len(batch) >= r.batchSize {
+			if err := flush(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	r.report.Directories++
+	r.report.Created += created
+	r.report.Updated += updated
+	r.report.Skipped += skipped
+	if key != "" {
+		r.completed = append(r.completed, key)
+	}
+	r.mu.Unlock()
+
+	if key != "" && !completed {
+		if err := m.setReindexMarker(ctx, key, true); err != nil {
+			return nil, err
+		}
+	}
+
+	return dirs, nil
+}
+
+// rebuild creates the metadata for stat, preserving the id, ownership, permissions and attributes of previous.
+func (r *reindexer) rebuild(ctx context.Context, stat *data.FileStat, previous *data.Metadata) (*data.Metadata, error) {
+	m := r.mnt
+	meta := stat.ToMetadata()
+
+	if previous != nil {
+		meta.ID = previous.ID
+		meta.UID = previous.UID
+		meta.GID = previous.GID
+		meta.CreateTime = previous.CreateTime
+		if previous.Mode.IsDir() == meta.Mode.IsDir() {
+			meta.Mode = previous.Mode
+		}
+		if meta.ContentType == "" {
+			meta.ContentType = previous.ContentType
+		}
+
+		attributes := maps.Clone(previous.Attributes)
+		if attributes == nil {
+			attributes = make(map[string]string)
+		}
+		maps.Copy(attributes, meta.Attributes)
+		meta.Attributes = attributes
+	}
+
+	if meta.Mode.IsDir() {
+		return meta, nil
+	}
+
+	if meta.ContentType == "" {
+		head := make([]byte, min(stat.Size, sniffSize))
+		if len(head) > 0 {
+			n, err := m.ObjectStorage.ReadObject(ctx, m.Options.Namespace, stat.Key, 0, head)
+			if err != nil && err != io.EOF {
+				return nil, err
+			}
+			head = head[:n]
+		}
+		meta.ContentType = data.DetectContentType(stat.Key, head)
+	}
+
+	if r.opts.Checksums && m.Options.Checksum != "" {
+		checksum, err := m.ComputeChecksum(ctx, stat.Key, m.Options.Checksum)
+		if err != nil {
+			return nil, err
+		}
+		meta.Attributes[data.AttributeChecksum] = checksum.String()
+	}
+
+	return meta, nil
+}
+
+// putMetaBatch stores all entries within metadata, using a single batch if supported by the backend.
+func (m *Mount) putMetaBatch(ctx context.Context, metas []*data.Metadata, existing map[string]bool) error {
+	namespace := m.Options.Namespace
+	if batch, ok := m.Metadata.(backend.BatchMetaBackend); ok {
+		return batch.PutMetaBatch(ctx, namespace, metas)
+	}
+
+	for _, meta := range metas {
+		if !existing[meta.Key] {
+			if err := m.Metadata.CreateMeta(ctx, namespace, meta); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := m.Metadata.UpdateMeta(ctx, namespace, meta.Key, &data.MetadataUpdate{
+			Mask:     data.MetadataUpdateAll &^ data.MetadataUpdateKey,
+			Metadata: meta,
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// setReindexMarker adds or removes the marker of a directory completed by the current reindex run.
+// The modify time of the directory is preserved.
+func (m *Mount) setReindexMarker(ctx context.Context, key string, completed bool) error {
+	namespace := m.Options.Namespace
+
+	meta, err := m.Metadata.ReadMeta(ctx, namespace, key)
+	if err != nil {
+		return err
+	}
+	if meta.HasAttribute(data.AttributeReindex) == completed {
+		return nil
+	}
+
+	attributes := maps.Clone(meta.Attributes)
+	if attributes == nil {
+		attributes = make(map[string]string)
+	}
+	if completed {
+		attributes[data.AttributeReindex] = "completed"
+	} else {
+		delete(attributes, data.AttributeReindex)
+	}
+
+	return m.Metadata.UpdateMeta(ctx, namespace, key, &data.MetadataUpdate{
+		Mask: data.MetadataUpdateAttributes | data.MetadataUpdateModifyTime,
+		Metadata: &data.Metadata{
+			ModifyTime: meta.ModifyTime,
+			Attributes: attributes,
+		},
+	})
+}
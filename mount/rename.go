@@ -0,0 +1,78 @@
+package mount
+
+import (
+	"context"
+
+	"github.com/mwantia/vfs/data"
+	"github.com/mwantia/vfs/mount/backend"
+)
+
+// CanRename checks if all backends of this mount support native renames.
+func (m *Mount) CanRename() bool {
+	if _, ok := m.ObjectStorage.(backend.RenameObjectBackend); !ok {
+		return false
+	}
+	if m.Metadata != nil && !m.IsDualMount {
+		if _, ok := m.Metadata.(backend.RenameMetaBackend); !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Rename moves oldKey including all nested keys to newKey, using the native rename capabilities of all backends.
+// If renaming the metadata fails, the object storage is reverted to keep both backends consistent.
+// Returns data.ErrNotSupported if any backend doesn't support native renames and data.ErrBusy
+// if a streamer is still open for any of the affected keys.
+func (m *Mount) Rename(ctx context.Context, oldKey, newKey string) error {
+	if !m.CanRename() {
+		return data.ErrNotSupported
+	}
+
+	if m.isRenameBusy(oldKey) {
+		m.log.Error("Rename: open streamer found for %s", oldKey)
+		return data.ErrBusy
+	}
+
+	namespace := m.Options.Namespace
+	objects := m.ObjectStorage.(backend.RenameObjectBackend)
+
+	m.log.Debug("Rename: renaming objects from %s to %s", oldKey, newKey)
+	if err := objects.RenameObject(ctx, namespace, oldKey, newKey); err != nil {
+		m.log.Error("Rename: failed to rename objects from %s to %s - %v", oldKey, newKey, err)
+		return err
+	}
+
+	if m.Metadata == nil || m.IsDualMount {
+		return nil
+	}
+
+	metas := m.Metadata.(backend.RenameMetaBackend)
+
+	m.log.Debug("Rename: renaming metadata from %s to %s", oldKey, newKey)
+	if err := metas.RenameMeta(ctx, namespace, oldKey, newKey); err != nil {
+		m.log.Error("Rename: failed to rename metadata from %s to %s - %v", oldKey, newKey, err)
+		// Revert the objects, so both backends stay consistent
+		if err := objects.RenameObject(ctx, namespace, newKey, oldKey); err != nil {
+			m.log.Error("Rename: failed to revert objects from %s to %s - %v", newKey, oldKey, err)
+		}
+		return err
+	}
+
+	return nil
+}
+
+// isRenameBusy checks if any streamer is open for key or any key nested below.
+func (m *Mount) isRenameBusy(key string) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for path := range m.streamers {
+		if _, affected := backend.RenamedKey(path, key, key); affected {
+			return true
+		}
+	}
+
+	return false
+}
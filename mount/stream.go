@@ -40,6 +40,8 @@ type MountStreamer struct {
 	offset int64
 	flags  data.AccessMode
 	closed bool
+
+	accessed bool // Whether the access has been recorded by the first read
 }
 
 func newMountStreamer(ctx context.Context, log *log.Logger, mnt *Mount, path string, offset int64, flags data.AccessMode) *MountStreamer {
@@ -106,6 +108,13 @@ func (ms *MountStreamer) Read(p []byte) (n int, err error) {
 	if n > 0 {
 		ms.offset += int64(n)
 		ms.log.Debug("Read: read %d bytes from %s, new offset=%d", n, ms.path, ms.offset)
+
+		if !ms.accessed {
+			ms.accessed = true
+			if err := ms.mnt.RecordAccess(ms.ctx, ms.path); err != nil {
+				ms.log.Warn("Read: failed to record access of %s - %v", ms.path, err)
+			}
+		}
 	}
 
 	if err != nil && err != io.EOF {
@@ -143,8 +152,8 @@ func (ms *MountStreamer) Write(p []byte) (n int, err error) {
 		return 0, ms.ctx.Err()
 	default:
 	}
-	// Get current file size for validation
-	var currentSize int64
+	// Get current file size and owner for validation
+	var currentSize, uid int64
 	// Validate using metadata first if available
 	if ms.mnt.Metadata != nil {
 		ms.log.Debug("Write: validating file using metadata for %s", ms.path)
@@ -174,6 +183,7 @@ func (ms *MountStreamer) Write(p []byte) (n int, err error) {
 			return 0, data.ErrIsDirectory
 		}
 		currentSize = meta.Size
+		uid = meta.UID
 	} else {
 		// No metadata backend - get size from object storage
 		ms.log.Debug("Write: getting file size from object storage for %s", ms.path)
@@ -213,9 +223,20 @@ func (ms *MountStreamer) Write(p []byte) (n int, err error) {
 		return 0, errors.BackendObjectTooLarge(nil, newSize, caps.MaxObjectSize)
 	}
 
+	// Growth is charged against all quotas before anything is written
+	growth := newSize - currentSize
+	if err := ms.mnt.ChargeQuota(QuotaDelta{UID: uid, Bytes: growth}); err != nil {
+		ms.log.Error("Write: %v for %s", err, ms.path)
+		return 0, err
+	}
+
 	// Write to storage backend
 	ms.log.Debug("Write: writing to object storage for %s", ms.path)
 	n, err = ms.mnt.ObjectStorage.WriteObject(ms.ctx, namespace, ms.path, ms.offset, p)
+	// Bytes that haven't been written don't count towards the quotas
+	if written := max(ms.offset+int64(n), currentSize) - currentSize; written < growth {
+		ms.mnt.RefundQuota(QuotaDelta{UID: uid, Bytes: growth - written})
+	}
 	if err != nil {
 		ms.log.Error("Write: failed to write to object storage for %s - %v", ms.path, err)
 		return n, err
@@ -226,11 +247,14 @@ func (ms *MountStreamer) Write(p []byte) (n int, err error) {
 		ms.log.Debug("Write: wrote %d bytes to %s, new offset=%d", n, ms.path, ms.offset)
 		// Update metadata if available
 		if ms.mnt.Metadata != nil {
-			ms.log.Debug("Write: updating metadata size for %s (new_size=%d)", ms.path, ms.offset)
+			// Writing in the middle of a file doesn't shrink it
+			size := max(ms.offset, currentSize)
+			ms.log.Debug("Write: updating metadata size for %s (new_size=%d)", ms.path, size)
+			// Stored checksums are outdated until the streamer is closed
 			update := &data.MetadataUpdate{
-				Mask: data.MetadataUpdateSize,
+				Mask: data.MetadataUpdateSize | data.MetadataUpdateStorageHash,
 				Metadata: &data.Metadata{
-					Size: ms.offset,
+					Size: size,
 				},
 			}
 
@@ -239,6 +263,8 @@ func (ms *MountStreamer) Write(p []byte) (n int, err error) {
 				return 0, err
 			}
 		}
+
+		ms.mnt.notifyKey(data.EventWrite, ms.path, "")
 	}
 
 	return n, err
@@ -314,6 +340,7 @@ func (ms *MountStreamer) Seek(offset int64, whence int) (int64, error) {
 }
 
 // Close marks the file stream as closed and unregisters it from the VFS.
+// Streams opened with write access update the stored content hash before closing.
 func (ms *MountStreamer) Close() error {
 	ms.mu.Lock()
 	defer ms.mu.Unlock()
@@ -325,11 +352,21 @@ func (ms *MountStreamer) Close() error {
 
 	ms.log.Debug("Close: closing streamer for %s", ms.path)
 
-	ms.mnt.mu.Lock()
-	defer ms.mnt.mu.Unlock()
+	if ms.CanWrite() {
+		// Content has already been written, so the stored checksum only stays outdated on failure
+		ms.log.Debug("Close: updating checksum for %s", ms.path)
+		if err := ms.mnt.UpdateChecksum(ms.ctx, ms.path); err != nil {
+			ms.log.Warn("Close: failed to update checksum for %s - %v", ms.path, err)
+		}
+		if err := ms.mnt.UpdateContentEncoding(ms.ctx, ms.path); err != nil {
+			ms.log.Warn("Close: failed to update content encoding for %s - %v", ms.path, err)
+		}
+
+		ms.mnt.notifyKey(data.EventCloseWrite, ms.path, "")
+	}
 
 	ms.closed = true
-	delete(ms.mnt.streamers, ms.path)
+	ms.mnt.removeStreamer(ms.path, ms)
 
 	ms.log.Debug("Close: streamer closed for %s", ms.path)
 	return nil
@@ -0,0 +1,155 @@
+package mount
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mwantia/vfs/data"
+	"github.com/mwantia/vfs/mount/backend"
+)
+
+// Tier moves the content of every file within this mount to the tier selected by the tier policy
+// and stores the tier of each file as attribute. Files currently opened through this mount are skipped.
+// Returns data.ErrNotSupported for mounts without tier policy, tiering backend or metadata backend.
+func (m *Mount) Tier(ctx context.Context, opts *data.TierOptions) (*data.TierReport, error) {
+	report := &data.TierReport{
+		Path: m.Path,
+	}
+
+	tiering, policy, ok := m.tiering()
+	if !ok {
+		return nil, data.ErrNotSupported
+	}
+
+	namespace := m.Options.Namespace
+	result, err := m.Metadata.QueryMeta(ctx, namespace, &backend.MetadataQuery{})
+	if err != nil {
+		return nil, err
+	}
+	metas := result.Candidates
+	slices.SortFunc(metas, func(a, b *data.Metadata) int {
+		return strings.Compare(a.Key, b.Key)
+	})
+
+	now := time.Now()
+	for _, meta := range metas {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if meta.Key == "" || meta.Mode.IsDir() {
+			continue
+		}
+		if _, open := m.GetStreamer(meta.Key); open {
+			continue
+		}
+
+		current, err := tiering.GetTier(ctx, namespace, meta.Key)
+		if err != nil {
+			if err == data.ErrNotExist {
+				// Metadata without object is reported by fsck
+				continue
+			}
+			return nil, err
+		}
+
+		report.Checked++
+		tier := policy.Evaluate(meta, now)
+		if tier != current {
+			m.log.Debug("Tier: moving %s from %s to %s tier", meta.Key, current, tier)
+			if tier == data.TierHot {
+				report.Promoted++
+			} else {
+				report.Demoted++
+			}
+			report.Bytes += meta.Size
+		}
+		if opts.DryRun {
+			continue
+		}
+
+		if tier != current {
+			if err := tiering.SetTier(ctx, namespace, meta.Key, tier); err != nil {
+				return nil, err
+			}
+		}
+		if err := m.setTierAttribute(ctx, meta, tier, time.Time{}); err != nil {
+			return nil, err
+		}
+	}
+
+	return report, nil
+}
+
+// RecordAccess refreshes the access time of the file at path after it has been read and moves it to the tier
+// selected by the tier policy, which transparently promotes files demoted for being idle.
+// Metadata is only updated once within the access interval of the policy, unless the file has to be moved.
+// This is a no-op for mounts without tier policy.
+func (m *Mount) RecordAccess(ctx context.Context, path string) error {
+	tiering, policy, ok := m.tiering()
+	if !ok || m.Options.IsReadOnly {
+		return nil
+	}
+
+	namespace := m.Options.Namespace
+	meta, err := m.Metadata.ReadMeta(ctx, namespace, path)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	recent := now.Sub(meta.AccessTime) < policy.GetAccessInterval()
+	meta.AccessTime = now
+
+	tier := policy.Evaluate(meta, now)
+	if recent && meta.GetAttribute(data.AttributeTier, "") == string(tier) {
+		return nil
+	}
+	if err := tiering.SetTier(ctx, namespace, path, tier); err != nil {
+		return err
+	}
+
+	m.log.Debug("RecordAccess: recorded access of %s on %s tier", path, tier)
+	return m.setTierAttribute(ctx, meta, tier, now)
+}
+
+// tiering returns the tiering backend and policy of this mount, if the mount is tiered and has metadata.
+func (m *Mount) tiering() (backend.TieringBackend, *data.TierPolicy, bool) {
+	policy := m.Options.TierPolicy
+	if policy == nil || m.Metadata == nil {
+		return nil, nil, false
+	}
+
+	tiering, ok := m.ObjectStorage.(backend.TieringBackend)
+	return tiering, policy, ok
+}
+
+// setTierAttribute stores tier as attribute of meta and updates its access time, unless accessTime is zero.
+// The modify time of the file is preserved.
+func (m *Mount) setTierAttribute(ctx context.Context, meta *data.Metadata, tier data.StorageTier, accessTime time.Time) error {
+	if meta.GetAttribute(data.AttributeTier, "") == string(tier) && accessTime.IsZero() {
+		return nil
+	}
+
+	attributes := maps.Clone(meta.Attributes)
+	if attributes == nil {
+		attributes = make(map[string]string)
+	}
+	attributes[data.AttributeTier] = string(tier)
+
+	update := &data.MetadataUpdate{
+		Mask: data.MetadataUpdateAttributes | data.MetadataUpdateModifyTime,
+		Metadata: &data.Metadata{
+			ModifyTime: meta.ModifyTime,
+			AccessTime: accessTime,
+			Attributes: attributes,
+		},
+	}
+	if !accessTime.IsZero() {
+		update.Mask |= data.MetadataUpdateAccessTime
+	}
+
+	return m.Metadata.UpdateMeta(ctx, m.Options.Namespace, meta.Key, update)
+}
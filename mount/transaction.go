@@ -0,0 +1,60 @@
+package mount
+
+import (
+	"context"
+
+	"github.com/mwantia/vfs/data"
+	"github.com/mwantia/vfs/mount/backend"
+)
+
+// CanTransact checks if this mount is able to apply multiple changes atomically.
+// This requires a single backend, since changes can't be committed atomically across separate backends.
+func (m *Mount) CanTransact() bool {
+	if _, ok := m.ObjectStorage.(backend.TransactionBackend); !ok {
+		return false
+	}
+
+	return m.Metadata == nil || m.IsDualMount
+}
+
+// ApplyChanges applies all changes atomically, using the transaction capabilities of the backend.
+// Returns data.ErrNotSupported if the mount can't apply changes atomically, data.ErrBusy
+// if a streamer is still open for any of the affected keys and data.ErrTransactionConflict
+// if any key has been modified since its change has been staged.
+func (m *Mount) ApplyChanges(ctx context.Context, changes []*backend.Change) error {
+	if !m.CanTransact() {
+		return data.ErrNotSupported
+	}
+	if m.Options.IsReadOnly {
+		return data.ErrReadOnly
+	}
+
+	// The lock is held until all changes have been applied, since opening streamers requires the write lock
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if key, busy := m.isTransactionBusyUnsafe(changes); busy {
+		m.log.Error("ApplyChanges: open streamer found for %s", key)
+		return data.ErrBusy
+	}
+
+	m.log.Debug("ApplyChanges: applying %d change(s)", len(changes))
+	if err := m.ObjectStorage.(backend.TransactionBackend).ApplyChanges(ctx, m.Options.Namespace, changes); err != nil {
+		m.log.Error("ApplyChanges: failed to apply changes - %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// isTransactionBusyUnsafe checks if any streamer is open for any of the changed keys.
+// MUST be called while holding at least a read lock.
+func (m *Mount) isTransactionBusyUnsafe(changes []*backend.Change) (string, bool) {
+	for _, change := range changes {
+		if _, exists := m.streamers[change.Key]; exists {
+			return change.Key, true
+		}
+	}
+
+	return "", false
+}
@@ -0,0 +1,162 @@
+package mount
+
+import (
+	"context"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/mwantia/vfs/data"
+	"github.com/mwantia/vfs/mount/backend"
+)
+
+// Notifier receives all events emitted by operations on a mount or observed by its backend.
+type Notifier func(event data.Event)
+
+// SetNotifier defines the receiver for all events of this mount.
+func (m *Mount) SetNotifier(notifier Notifier) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.notifier = notifier
+}
+
+// Notify emits an event for the absolute path (and previous absolute path, if renamed).
+func (m *Mount) Notify(eventType data.EventType, path, oldPath string) {
+	m.publish(data.Event{
+		Type:    eventType,
+		Path:    path,
+		OldPath: oldPath,
+		Time:    time.Now(),
+	})
+}
+
+// ToAbsolutePath converts an object key of this mount back into an absolute path.
+// Returns false if the key is outside of the configured path prefix.
+func (m *Mount) ToAbsolutePath(key string) (string, bool) {
+	key = strings.Trim(key, "/")
+
+	if m.Options.PathPrefix != "" {
+		prefix := strings.Trim(m.Options.PathPrefix, "/")
+		switch {
+		case key == prefix:
+			key = ""
+		case strings.HasPrefix(key, prefix+"/"):
+			key = key[len(prefix)+1:]
+		default:
+			return "", false
+		}
+	}
+
+	return path.Join("/", m.Path, key), true
+}
+
+// notifyKey emits an event for an object key (and previous object key, if renamed).
+func (m *Mount) notifyKey(eventType data.EventType, key, oldKey string) {
+	absolute, ok := m.ToAbsolutePath(key)
+	if !ok {
+		return
+	}
+
+	event := data.Event{
+		Type: eventType,
+		Path: absolute,
+		Time: time.Now(),
+	}
+	if oldKey != "" {
+		if event.OldPath, ok = m.ToAbsolutePath(oldKey); !ok {
+			return
+		}
+	}
+
+	m.publish(event)
+}
+
+// publish records the event for a running migration and forwards it to the notifier, if one has been defined.
+func (m *Mount) publish(event data.Event) {
+	m.mu.RLock()
+	notifier, changes := m.notifier, m.changes
+	m.mu.RUnlock()
+
+	if changes != nil {
+		changes.record(event)
+	}
+
+	if notifier == nil {
+		return
+	}
+
+	event.Mount = m.Path
+	notifier(event)
+}
+
+// startBackendWatch starts observing external changes, if supported by the object storage.
+func (m *Mount) startBackendWatch() {
+	watcher, ok := m.ObjectStorage.(backend.WatchBackend)
+	if !ok || !m.Options.WatchBackend {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	m.log.Debug("startBackendWatch: observing changes of backend %s", m.ObjectStorage.Name())
+	done, err := watcher.WatchObjects(ctx, m.Options.Namespace, m.handleBackendEvent)
+	if err != nil {
+		// External changes are optional, so the mount itself stays usable
+		m.log.Warn("startBackendWatch: unable to observe backend %s - %v", m.ObjectStorage.Name(), err)
+		cancel()
+		return
+	}
+
+	m.mu.Lock()
+	m.watchCancel = cancel
+	m.watchDone = done
+	m.mu.Unlock()
+}
+
+// stopBackendWatch stops observing external changes and waits until the watch has returned.
+func (m *Mount) stopBackendWatch() {
+	m.mu.Lock()
+	cancel, done := m.watchCancel, m.watchDone
+	m.watchCancel, m.watchDone = nil, nil
+	m.mu.Unlock()
+
+	if cancel == nil {
+		return
+	}
+
+	m.log.Debug("stopBackendWatch: stop observing changes of backend %s", m.ObjectStorage.Name())
+	cancel()
+	<-done
+}
+
+// handleBackendEvent converts object keys reported by the backend into absolute paths.
+func (m *Mount) handleBackendEvent(event data.Event) {
+	event.External = true
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+
+	if event.Type != data.EventOverflow {
+		absolute, ok := m.ToAbsolutePath(event.Path)
+		oldAbsolute, oldOk := "", false
+		if event.OldPath != "" {
+			oldAbsolute, oldOk = m.ToAbsolutePath(event.OldPath)
+		}
+
+		switch {
+		case ok && event.OldPath != "" && !oldOk:
+			// Moved into the visible part of the backend
+			event.Type, event.Path, event.OldPath = data.EventCreate, absolute, ""
+		case !ok && oldOk:
+			// Moved out of the visible part of the backend
+			event.Type, event.Path, event.OldPath = data.EventUnlink, oldAbsolute, ""
+		case ok:
+			event.Path, event.OldPath = absolute, oldAbsolute
+		default:
+			return
+		}
+	}
+
+	m.publish(event)
+}
@@ -17,8 +17,18 @@ import (
 // OpenFile opens a file with the specified access mode flags and returns a file handle.
 // The returned VirtualFile must be closed by the caller. Use flags to control access.
 func (vfs *virtualFileSystemImpl) OpenFile(ctx context.Context, path string, flags data.AccessMode) (mount.Streamer, error) {
+	op := vfs.newOperation(OperationOpenFile, path)
+	op.Flags = flags
+
+	return intercept(ctx, vfs, op, func(ctx context.Context, op *Operation) (mount.Streamer, error) {
+		return vfs.openFile(ctx, op.Path, flags)
+	})
+}
+
+// openFile implements OpenFile, after the operation passed all interceptors.
+func (vfs *virtualFileSystemImpl) openFile(ctx context.Context, path string, flags data.AccessMode) (mount.Streamer, error) {
 	// Always start with an absolute path
-	absolute, err := data.ToAbsolutePath(path)
+	absolute, err := vfs.toAbsolutePath(path)
 	if err != nil {
 		vfs.log.Error("Failed to convert path to absolute: %s - %v", path, err)
 		return nil, err
@@ -36,6 +46,11 @@ func (vfs *virtualFileSystemImpl) OpenFile(ctx context.Context, path string, fla
 	relative := vfs.getPrefixRelativePath(mnt, absolute)
 
 	vfs.log.Debug("OpenFile: resolved to mount at '%s' with namespace '%s'", mnt.Path, namespace)
+	// Fail if the file is locked by another owner and the mount enforces locks
+	if err := mnt.CheckMandatoryLocks(ctx, relative, data.LockOwner(ctx), flags, 0, 0); err != nil {
+		vfs.log.Error("OpenFile: mandatory lock check failed for %s - %v", absolute, err)
+		return nil, err
+	}
 	// Check if any previous streamer exists
 	streamer, exists := mnt.GetStreamer(relative)
 	if exists {
@@ -51,6 +66,9 @@ func (vfs *virtualFileSystemImpl) OpenFile(ctx context.Context, path string, fla
 	}
 	// Determine initial offset
 	offset := int64(0)
+	created := false
+	// Current size and owner are required to release the usage on truncation
+	var size, uid int64
 	// We need to determine if the file exists
 	if mnt.Metadata != nil {
 		vfs.log.Debug("OpenFile: using metadata backend for %s", absolute)
@@ -77,11 +95,12 @@ func (vfs *virtualFileSystemImpl) OpenFile(ctx context.Context, path string, fla
 					return nil, err
 				}
 				vfs.log.Info("OpenFile: creating new file %s in object storage", absolute)
-				stat, err = mnt.ObjectStorage.CreateObject(ctx, namespace, relative, 0x777)
+				stat, err = vfs.createObject(ctx, mnt, relative, 0x777)
 				if err != nil {
 					vfs.log.Error("OpenFile: failed to create object in storage for %s - %v", absolute, err)
 					return nil, err
 				}
+				created = true
 			}
 			// Convert object stats to metadata
 			meta = stat.ToMetadata()
@@ -106,6 +125,7 @@ func (vfs *virtualFileSystemImpl) OpenFile(ctx context.Context, path string, fla
 			vfs.log.Error("OpenFile: cannot open directory %s as stream", absolute)
 			return nil, data.ErrIsDirectory
 		}
+		size, uid = meta.Size, meta.UID
 		// Read filesize from metadata if append
 		if flags.HasAppend() {
 			offset = meta.Size
@@ -127,11 +147,12 @@ func (vfs *virtualFileSystemImpl) OpenFile(ctx context.Context, path string, fla
 				return nil, err
 			}
 			vfs.log.Info("OpenFile: creating new file %s in object storage", absolute)
-			stat, err = mnt.ObjectStorage.CreateObject(ctx, namespace, relative, 0x777)
+			stat, err = vfs.createObject(ctx, mnt, relative, 0x777)
 			if err != nil {
 				vfs.log.Error("OpenFile: failed to create object in storage for %s - %v", absolute, err)
 				return nil, err
 			}
+			created = true
 		}
 		// File exists - check EXCL flag
 		if flags.HasExcl() && flags.HasCreate() {
@@ -143,12 +164,16 @@ func (vfs *virtualFileSystemImpl) OpenFile(ctx context.Context, path string, fla
 			vfs.log.Error("OpenFile: cannot open directory %s as stream", absolute)
 			return nil, data.ErrIsDirectory
 		}
+		size = stat.Size
 		// Read filesize from metadata if append
 		if flags.HasAppend() {
 			offset = stat.Size
 			vfs.log.Debug("OpenFile: APPEND mode, setting offset to %d for %s", offset, absolute)
 		}
 	}
+	if created {
+		mnt.Notify(data.EventCreate, absolute, "")
+	}
 	// Only truncate if TRUNC flag is set and we have write access
 	if flags.HasTrunc() && (flags.IsWriteOnly() || flags.IsReadWrite()) {
 		vfs.log.Debug("OpenFile: truncating file %s", absolute)
@@ -156,6 +181,19 @@ func (vfs *virtualFileSystemImpl) OpenFile(ctx context.Context, path string, fla
 			vfs.log.Error("OpenFile: failed to truncate file %s - %v", absolute, err)
 			return nil, err
 		}
+		mnt.RefundQuota(mount.QuotaDelta{UID: uid, Bytes: size})
+		// Sync truncated size to metadata (only if separate metadata backend)
+		if mnt.Metadata != nil && !mnt.IsDualMount && size > 0 {
+			update := &data.MetadataUpdate{
+				Mask:     data.MetadataUpdateSize,
+				Metadata: &data.Metadata{Size: 0},
+			}
+			if err := mnt.Metadata.UpdateMeta(ctx, namespace, relative, update); err != nil {
+				vfs.log.Error("OpenFile: failed to sync truncated size for %s - %v", absolute, err)
+				return nil, err
+			}
+		}
+		mnt.Notify(data.EventTruncate, absolute, "")
 	}
 
 	vfs.log.Info("OpenFile: successfully opened %s with offset=%d", absolute, offset)
@@ -165,8 +203,15 @@ func (vfs *virtualFileSystemImpl) OpenFile(ctx context.Context, path string, fla
 // CloseFile closes an open file handle at the given path.
 // This may be a no-op for implementations that don't maintain file handles.
 func (vfs *virtualFileSystemImpl) CloseFile(ctx context.Context, path string, force bool) error {
+	return vfs.interceptError(ctx, vfs.newOperation(OperationCloseFile, path), func(ctx context.Context, op *Operation) error {
+		return vfs.closeFile(ctx, op.Path, force)
+	})
+}
+
+// closeFile implements CloseFile, after the operation passed all interceptors.
+func (vfs *virtualFileSystemImpl) closeFile(ctx context.Context, path string, force bool) error {
 	// Always start with an absolute path
-	absolute, err := data.ToAbsolutePath(path)
+	absolute, err := vfs.toAbsolutePath(path)
 	if err != nil {
 		vfs.log.Error("CloseFile: failed to convert path to absolute: %s - %v", path, err)
 		return err
@@ -194,8 +239,15 @@ func (vfs *virtualFileSystemImpl) CloseFile(ctx context.Context, path string, fo
 // Read reads size bytes from the file at path starting at offset.
 // Returns the data read or an error if the operation fails.
 func (vfs *virtualFileSystemImpl) ReadFile(ctx context.Context, path string, offset, size int64) ([]byte, error) {
+	return intercept(ctx, vfs, vfs.newOperation(OperationReadFile, path), func(ctx context.Context, op *Operation) ([]byte, error) {
+		return vfs.readFile(ctx, op.Path, offset, size)
+	})
+}
+
+// readFile implements ReadFile, after the operation passed all interceptors.
+func (vfs *virtualFileSystemImpl) readFile(ctx context.Context, path string, offset, size int64) ([]byte, error) {
 	// Always start with an absolute path
-	absolute, err := data.ToAbsolutePath(path)
+	absolute, err := vfs.toAbsolutePath(path)
 	if err != nil {
 		vfs.log.Error("ReadFile: failed to convert path to absolute: %s - %v", path, err)
 		return nil, err
@@ -216,6 +268,11 @@ func (vfs *virtualFileSystemImpl) ReadFile(ctx context.Context, path string, off
 
 	namespace := mnt.Options.Namespace
 	relative := vfs.getPrefixRelativePath(mnt, absolute)
+	// Fail if the file is locked by another owner and the mount enforces locks
+	if err := mnt.CheckMandatoryLocks(ctx, relative, data.LockOwner(ctx), data.AccessModeRead, offset, size); err != nil {
+		vfs.log.Error("ReadFile: mandatory lock check failed for %s - %v", absolute, err)
+		return nil, err
+	}
 	// If metadata exists, validate if size and offset matches
 	if mnt.Metadata != nil {
 		vfs.log.Debug("ReadFile: validating size using metadata for %s", absolute)
@@ -281,6 +338,10 @@ func (vfs *virtualFileSystemImpl) ReadFile(ctx context.Context, path string, off
 		vfs.log.Error("ReadFile: object storage ReadObject failed for %s - %v", absolute, err)
 		return nil, err
 	}
+	// Reads keep files on the tier of frequently accessed content
+	if err := mnt.RecordAccess(ctx, relative); err != nil {
+		vfs.log.Warn("ReadFile: failed to record access of %s - %v", absolute, err)
+	}
 
 	vfs.log.Debug("ReadFile: successfully read %d bytes from %s", n, absolute)
 	return buffer[:n], nil
@@ -306,11 +367,35 @@ func (vfs *virtualFileSystemImpl) validateObjectSize(mnt *mount.Mount, size int6
 	return nil
 }
 
+// createObject creates a new object in storage, after charging it against the quotas of the mount.
+// New objects are owned by UID 0 until their ownership is changed.
+func (vfs *virtualFileSystemImpl) createObject(ctx context.Context, mnt *mount.Mount, relative string, mode data.FileMode) (*data.FileStat, error) {
+	delta := mount.QuotaDelta{Objects: 1}
+	if err := mnt.ChargeQuota(delta); err != nil {
+		return nil, err
+	}
+
+	stat, err := mnt.ObjectStorage.CreateObject(ctx, mnt.Options.Namespace, relative, mode)
+	if err != nil {
+		mnt.RefundQuota(delta)
+		return nil, err
+	}
+
+	return stat, nil
+}
+
 // Write writes data to the file at path starting at offset.
 // Returns the number of bytes written or an error if the operation fails.
 func (vfs *virtualFileSystemImpl) WriteFile(ctx context.Context, path string, offset int64, buffer []byte) (int, error) {
+	return intercept(ctx, vfs, vfs.newOperation(OperationWriteFile, path), func(ctx context.Context, op *Operation) (int, error) {
+		return vfs.writeFile(ctx, op.Path, offset, buffer)
+	})
+}
+
+// writeFile implements WriteFile, after the operation passed all interceptors.
+func (vfs *virtualFileSystemImpl) writeFile(ctx context.Context, path string, offset int64, buffer []byte) (int, error) {
 	// Always start with an absolute path
-	absolute, err := data.ToAbsolutePath(path)
+	absolute, err := vfs.toAbsolutePath(path)
 	if err != nil {
 		vfs.log.Error("WriteFile: failed to convert path to absolute: %s - %v", path, err)
 		return 0, err
@@ -331,8 +416,13 @@ func (vfs *virtualFileSystemImpl) WriteFile(ctx context.Context, path string, of
 
 	namespace := mnt.Options.Namespace
 	relative := vfs.getPrefixRelativePath(mnt, absolute)
+	// Fail if the file is locked by another owner and the mount enforces locks
+	if err := mnt.CheckMandatoryLocks(ctx, relative, data.LockOwner(ctx), data.AccessModeWrite, offset, int64(len(buffer))); err != nil {
+		vfs.log.Error("WriteFile: mandatory lock check failed for %s - %v", absolute, err)
+		return 0, err
+	}
 
-	var currentSize int64
+	var currentSize, uid int64
 	// Read metadata info if available
 	if mnt.Metadata != nil {
 		vfs.log.Debug("WriteFile: validating file using metadata for %s", absolute)
@@ -367,6 +457,7 @@ func (vfs *virtualFileSystemImpl) WriteFile(ctx context.Context, path string, of
 			return 0, data.ErrIsDirectory
 		}
 		currentSize = meta.Size
+		uid = meta.UID
 	} else {
 		vfs.log.Debug("WriteFile: validating file using object storage for %s", absolute)
 		// Fallback to storage to read object stats
@@ -397,28 +488,52 @@ func (vfs *virtualFileSystemImpl) WriteFile(ctx context.Context, path string, of
 		return 0, err
 	}
 
+	// Growth is charged against all quotas before anything is written
+	growth := newSize - currentSize
+	if err := mnt.ChargeQuota(mount.QuotaDelta{UID: uid, Bytes: growth}); err != nil {
+		vfs.log.Error("WriteFile: quota validation failed for %s - %v", absolute, err)
+		return 0, err
+	}
+
 	vfs.log.Debug("WriteFile: writing to object storage for %s", absolute)
 	n, err := mnt.ObjectStorage.WriteObject(ctx, namespace, relative, offset, buffer)
+	// Bytes that haven't been written don't count towards the quotas
+	if written := max(offset+int64(n), currentSize) - currentSize; written < growth {
+		mnt.RefundQuota(mount.QuotaDelta{UID: uid, Bytes: growth - written})
+	}
 	if err != nil {
 		vfs.log.Error("WriteFile: object storage WriteObject failed for %s - %v", absolute, err)
 		return 0, err
 	}
 
 	// Sync metadata information after successfull write
-	if mnt.Metadata != nil && !mnt.IsDualMount {
-		vfs.log.Debug("WriteFile: syncing updated size to metadata for %s (new_size=%d)", absolute, offset+int64(n))
+	if mnt.Metadata != nil {
+		// Stored checksums are outdated until they are recomputed (e.g. by verify)
+		mask := data.MetadataUpdateStorageHash
+		if !mnt.IsDualMount {
+			vfs.log.Debug("WriteFile: syncing updated size to metadata for %s (new_size=%d)", absolute, max(offset+int64(n), currentSize))
+			mask |= data.MetadataUpdateSize
+		}
 		update := &data.MetadataUpdate{
-			Mask: data.MetadataUpdateSize,
+			Mask: mask,
 			Metadata: &data.Metadata{
-				Size: offset + int64(n),
+				// Writing in the middle of a file doesn't shrink it
+				Size: max(offset+int64(n), currentSize),
 			},
 		}
 		if err := mnt.Metadata.UpdateMeta(ctx, namespace, relative, update); err != nil {
 			vfs.log.Warn("WriteFile: failed to update metadata for %s - %v", absolute, err)
 			return n, err
 		}
+
+		if err := mnt.UpdateContentEncoding(ctx, relative); err != nil {
+			vfs.log.Warn("WriteFile: failed to update content encoding for %s - %v", absolute, err)
+			return n, err
+		}
 	}
 
+	mnt.Notify(data.EventWrite, absolute, "")
+
 	vfs.log.Info("WriteFile: successfully wrote %d bytes to %s at offset %d", n, absolute, offset)
 	return n, err
 }
@@ -426,8 +541,15 @@ func (vfs *virtualFileSystemImpl) WriteFile(ctx context.Context, path string, of
 // Stat returns file information for the given path.
 // Returns an error if the path doesn't exist.
 func (vfs *virtualFileSystemImpl) StatMetadata(ctx context.Context, path string) (*data.Metadata, error) {
+	return intercept(ctx, vfs, vfs.newOperation(OperationStatMetadata, path), func(ctx context.Context, op *Operation) (*data.Metadata, error) {
+		return vfs.statMetadata(ctx, op.Path)
+	})
+}
+
+// statMetadata implements StatMetadata, after the operation passed all interceptors.
+func (vfs *virtualFileSystemImpl) statMetadata(ctx context.Context, path string) (*data.Metadata, error) {
 	// Always start with an absolute path
-	absolute, err := data.ToAbsolutePath(path)
+	absolute, err := vfs.toAbsolutePath(path)
 	if err != nil {
 		vfs.log.Error("StatMetadata: failed to convert path to absolute: %s - %v", path, err)
 		return nil, err
@@ -521,7 +643,14 @@ func (vfs *virtualFileSystemImpl) StatMetadata(ctx context.Context, path string)
 // Lookup checks if a file or directory exists at the given path.
 // Returns true if the path exists, false otherwise.
 func (vfs *virtualFileSystemImpl) LookupMetadata(ctx context.Context, path string) (bool, error) {
-	meta, err := vfs.StatMetadata(ctx, path)
+	return intercept(ctx, vfs, vfs.newOperation(OperationLookupMetadata, path), func(ctx context.Context, op *Operation) (bool, error) {
+		return vfs.lookupMetadata(ctx, op.Path)
+	})
+}
+
+// lookupMetadata implements LookupMetadata, after the operation passed all interceptors.
+func (vfs *virtualFileSystemImpl) lookupMetadata(ctx context.Context, path string) (bool, error) {
+	meta, err := vfs.statMetadata(ctx, path)
 	if err != nil {
 		return false, nil
 	}
@@ -532,8 +661,15 @@ func (vfs *virtualFileSystemImpl) LookupMetadata(ctx context.Context, path strin
 // ReadDirectory returns a list of entries in the directory at path.
 // Returns an error if the path is not a directory or doesn't exist.
 func (vfs *virtualFileSystemImpl) ReadDirectory(ctx context.Context, path string) ([]*data.Metadata, error) {
+	return intercept(ctx, vfs, vfs.newOperation(OperationReadDirectory, path), func(ctx context.Context, op *Operation) ([]*data.Metadata, error) {
+		return vfs.readDirectory(ctx, op.Path)
+	})
+}
+
+// readDirectory implements ReadDirectory, after the operation passed all interceptors.
+func (vfs *virtualFileSystemImpl) readDirectory(ctx context.Context, path string) ([]*data.Metadata, error) {
 	// Always start with an absolute path
-	absolute, err := data.ToAbsolutePath(path)
+	absolute, err := vfs.toAbsolutePath(path)
 	if err != nil {
 		vfs.log.Error("ReadDirectory: failed to convert path to absolute: %s - %v", path, err)
 		return nil, err
@@ -619,11 +755,12 @@ func (vfs *virtualFileSystemImpl) ReadDirectory(ctx context.Context, path string
 			for _, stat := range stats {
 				meta := stat.ToMetadata()
 
-				// The stat.Key from storage is relative to the listed directory
+				// Backends return either the name or the full mount-relative key of each entry
+				name := strings.TrimPrefix(stat.Key, prefix)
 				// We need to prepend the directory path to get the full mount-relative key for metadata
-				fullKey := stat.Key
+				fullKey := name
 				if relative != "" {
-					fullKey = relative + "/" + stat.Key
+					fullKey = relative + "/" + name
 				}
 
 				// Sync to metadata if available AND it's a separate backend instance
@@ -639,7 +776,7 @@ func (vfs *virtualFileSystemImpl) ReadDirectory(ctx context.Context, path string
 				}
 
 				// Use the relative key (just the name) for the directory listing result
-				relativeKey := stat.Key
+				relativeKey := name
 				relativeMeta := meta.Clone()
 				relativeMeta.Key = relativeKey
 				metaMap[relativeKey] = relativeMeta
@@ -705,8 +842,15 @@ func (vfs *virtualFileSystemImpl) ReadDirectory(ctx context.Context, path string
 // CreateDirectory creates a new directory at the specified path.
 // Returns an error if the directory already exists or cannot be created.
 func (vfs *virtualFileSystemImpl) CreateDirectory(ctx context.Context, path string) error {
+	return vfs.interceptError(ctx, vfs.newOperation(OperationCreateDirectory, path), func(ctx context.Context, op *Operation) error {
+		return vfs.createDirectory(ctx, op.Path)
+	})
+}
+
+// createDirectory implements CreateDirectory, after the operation passed all interceptors.
+func (vfs *virtualFileSystemImpl) createDirectory(ctx context.Context, path string) error {
 	// Always start with an absolute path
-	absolute, err := data.ToAbsolutePath(path)
+	absolute, err := vfs.toAbsolutePath(path)
 	if err != nil {
 		vfs.log.Error("CreateDirectory: failed to convert path to absolute: %s - %v", path, err)
 		return err
@@ -737,7 +881,7 @@ func (vfs *virtualFileSystemImpl) CreateDirectory(ctx context.Context, path stri
 
 	// Create folder in object storage
 	vfs.log.Debug("CreateDirectory: creating directory in object storage for %s", absolute)
-	stat, err := mnt.ObjectStorage.CreateObject(ctx, namespace, relative, data.ModeDir|0x777)
+	stat, err := vfs.createObject(ctx, mnt, relative, data.ModeDir|0x777)
 	if err != nil {
 		// Fail if any error except Exists
 		if err != data.ErrExist {
@@ -764,6 +908,8 @@ func (vfs *virtualFileSystemImpl) CreateDirectory(ctx context.Context, path stri
 		}
 	}
 
+	mnt.Notify(data.EventMkdir, absolute, "")
+
 	vfs.log.Info("CreateDirectory: successfully created directory %s", absolute)
 	return nil
 }
@@ -771,8 +917,15 @@ func (vfs *virtualFileSystemImpl) CreateDirectory(ctx context.Context, path stri
 // RemoveDirectory removes an empty directory at the specified path.
 // Returns an error if the directory is not empty or doesn't exist.
 func (vfs *virtualFileSystemImpl) RemoveDirectory(ctx context.Context, path string, force bool) error {
+	return vfs.interceptError(ctx, vfs.newOperation(OperationRemoveDirectory, path), func(ctx context.Context, op *Operation) error {
+		return vfs.removeDirectory(ctx, op.Path, force)
+	})
+}
+
+// removeDirectory implements RemoveDirectory, after the operation passed all interceptors.
+func (vfs *virtualFileSystemImpl) removeDirectory(ctx context.Context, path string, force bool) error {
 	// Always start with an absolute path
-	absolute, err := data.ToAbsolutePath(path)
+	absolute, err := vfs.toAbsolutePath(path)
 	if err != nil {
 		vfs.log.Error("RemoveDirectory: failed to convert path to absolute: %s - %v", path, err)
 		return err
@@ -803,8 +956,14 @@ func (vfs *virtualFileSystemImpl) RemoveDirectory(ctx context.Context, path stri
 
 	namespace := mnt.Options.Namespace
 	relative := vfs.getPrefixRelativePath(mnt, absolute)
+	// Fail if the directory or any descendant is locked by another owner and the mount enforces locks
+	if err := mnt.CheckMandatoryLocksRecursive(ctx, relative, data.LockOwner(ctx), data.AccessModeWrite); err != nil {
+		vfs.log.Error("RemoveDirectory: mandatory lock check failed for %s - %v", absolute, err)
+		return err
+	}
 
 	var stat *data.FileStat
+	var uid int64
 	// Check if path exists in metadata
 	if mnt.Metadata != nil {
 		vfs.log.Debug("RemoveDirectory: checking directory existence using metadata for %s", absolute)
@@ -815,6 +974,7 @@ func (vfs *virtualFileSystemImpl) RemoveDirectory(ctx context.Context, path stri
 		}
 
 		stat = meta.ToStat()
+		uid = meta.UID
 		vfs.log.Debug("RemoveDirectory: found directory in metadata (mode=%s)", stat.Mode)
 	} else {
 		vfs.log.Debug("RemoveDirectory: checking directory existence using object storage for %s", absolute)
@@ -869,6 +1029,15 @@ func (vfs *virtualFileSystemImpl) RemoveDirectory(ctx context.Context, path stri
 		vfs.log.Debug("RemoveDirectory: force flag set, skipping empty directory check for %s", absolute)
 	}
 
+	// Usage of the directory and all removed descendants is released after deletion
+	usage := []mount.QuotaDelta{{UID: uid, Objects: 1}}
+	if force && mnt.HasQuotas() {
+		if usage, err = mnt.MeasureUsage(ctx, relative); err != nil {
+			vfs.log.Error("RemoveDirectory: failed to measure usage of %s - %v", absolute, err)
+			return err
+		}
+	}
+
 	// Delete directory from object storage
 	// Force to specifically delete directories
 	vfs.log.Debug("RemoveDirectory: deleting directory from object storage for %s", absolute)
@@ -876,6 +1045,7 @@ func (vfs *virtualFileSystemImpl) RemoveDirectory(ctx context.Context, path stri
 		vfs.log.Error("RemoveDirectory: failed to delete directory from object storage for %s - %v", absolute, err)
 		return err
 	}
+	mnt.RefundQuota(usage...)
 	// Sync deletion to metadata if available
 	if mnt.Metadata != nil && !mnt.IsDualMount {
 		vfs.log.Debug("RemoveDirectory: syncing deletion to metadata for %s", absolute)
@@ -886,6 +1056,8 @@ func (vfs *virtualFileSystemImpl) RemoveDirectory(ctx context.Context, path stri
 		}
 	}
 
+	mnt.Notify(data.EventRmdir, absolute, "")
+
 	vfs.log.Info("RemoveDirectory: successfully removed directory %s", absolute)
 	return nil
 }
@@ -893,8 +1065,15 @@ func (vfs *virtualFileSystemImpl) RemoveDirectory(ctx context.Context, path stri
 // UnlinkFile removes a file at the specified path.
 // Returns an error if the path is a directory or doesn't exist.
 func (vfs *virtualFileSystemImpl) UnlinkFile(ctx context.Context, path string) error {
+	return vfs.interceptError(ctx, vfs.newOperation(OperationUnlinkFile, path), func(ctx context.Context, op *Operation) error {
+		return vfs.unlinkFile(ctx, op.Path)
+	})
+}
+
+// unlinkFile implements UnlinkFile, after the operation passed all interceptors.
+func (vfs *virtualFileSystemImpl) unlinkFile(ctx context.Context, path string) error {
 	// Always start with an absolute path
-	absolute, err := data.ToAbsolutePath(path)
+	absolute, err := vfs.toAbsolutePath(path)
 	if err != nil {
 		vfs.log.Error("UnlinkFile: failed to convert path to absolute: %s - %v", path, err)
 		return err
@@ -925,8 +1104,14 @@ func (vfs *virtualFileSystemImpl) UnlinkFile(ctx context.Context, path string) e
 
 	namespace := mnt.Options.Namespace
 	relative := vfs.getPrefixRelativePath(mnt, absolute)
+	// Fail if the file is locked by another owner and the mount enforces locks
+	if err := mnt.CheckMandatoryLocks(ctx, relative, data.LockOwner(ctx), data.AccessModeWrite, 0, 0); err != nil {
+		vfs.log.Error("UnlinkFile: mandatory lock check failed for %s - %v", absolute, err)
+		return err
+	}
 
 	var stat *data.FileStat
+	var uid int64
 	// Check if path exists in metadata
 	if mnt.Metadata != nil {
 		vfs.log.Debug("UnlinkFile: checking file existence using metadata for %s", absolute)
@@ -937,6 +1122,7 @@ func (vfs *virtualFileSystemImpl) UnlinkFile(ctx context.Context, path string) e
 		}
 
 		stat = meta.ToStat()
+		uid = meta.UID
 		vfs.log.Debug("UnlinkFile: found file in metadata (size=%d mode=%s)", stat.Size, stat.Mode)
 	} else {
 		vfs.log.Debug("UnlinkFile: checking file existence using object storage for %s", absolute)
@@ -959,6 +1145,7 @@ func (vfs *virtualFileSystemImpl) UnlinkFile(ctx context.Context, path string) e
 		vfs.log.Error("UnlinkFile: failed to delete file from object storage for %s - %v", absolute, err)
 		return err
 	}
+	mnt.RefundQuota(mount.QuotaDelta{UID: uid, Bytes: stat.Size, Objects: 1})
 	// Sync deletion to metadata if available
 	if mnt.Metadata != nil && !mnt.IsDualMount {
 		vfs.log.Debug("UnlinkFile: syncing deletion to metadata for %s", absolute)
@@ -968,23 +1155,40 @@ func (vfs *virtualFileSystemImpl) UnlinkFile(ctx context.Context, path string) e
 		}
 	}
 
+	mnt.Notify(data.EventUnlink, absolute, "")
+
 	vfs.log.Info("UnlinkFile: successfully unlinked file %s", absolute)
 	return nil
 }
 
 // Rename moves or renames a file or directory from oldPath to newPath.
 // Returns an error if the operation cannot be completed.
-// This implementation uses a copy-and-delete strategy which works across different mounts
-// but is not atomic and may not be optimal for large files.
+// If both paths are located on the same mount and all backends support native renames,
+// the rename is pushed down to the backends and performed atomically.
+// Otherwise a streamed copy-and-delete strategy is used, which works across different mounts
+// and rolls back already copied entries on partial failure.
 func (vfs *virtualFileSystemImpl) Rename(ctx context.Context, oldPath string, newPath string) error {
+	op := vfs.newOperation(OperationRename, oldPath)
+	op.NewPath = newPath
+	if absolute, err := vfs.toAbsolutePath(newPath); err == nil {
+		op.NewPath = absolute
+	}
+
+	return vfs.interceptError(ctx, op, func(ctx context.Context, op *Operation) error {
+		return vfs.rename(ctx, op.Path, op.NewPath)
+	})
+}
+
+// rename implements Rename, after the operation passed all interceptors.
+func (vfs *virtualFileSystemImpl) rename(ctx context.Context, oldPath string, newPath string) error {
 	// Convert to absolute paths
-	oldAbsolute, err := data.ToAbsolutePath(oldPath)
+	oldAbsolute, err := vfs.toAbsolutePath(oldPath)
 	if err != nil {
 		vfs.log.Error("Rename: failed to convert oldPath to absolute: %s - %v", oldPath, err)
 		return err
 	}
 
-	newAbsolute, err := data.ToAbsolutePath(newPath)
+	newAbsolute, err := vfs.toAbsolutePath(newPath)
 	if err != nil {
 		vfs.log.Error("Rename: failed to convert newPath to absolute: %s - %v", newPath, err)
 		return err
@@ -1015,13 +1219,55 @@ func (vfs *virtualFileSystemImpl) Rename(ctx context.Context, oldPath string, ne
 		return data.ErrExist
 	}
 
+	// Prevent moving a directory into its own subtree
+	if strings.HasPrefix(newAbsolute, oldAbsolute+"/") {
+		vfs.log.Error("Rename: cannot move %s into its own subtree %s", oldAbsolute, newAbsolute)
+		return data.ErrInvalid
+	}
+
+	// Fail if the source, any of its descendants or the destination is locked by another owner
+	for _, absolute := range []string{oldAbsolute, newAbsolute} {
+		mnt, err := vfs.getMountFromPath(absolute)
+		if err != nil {
+			vfs.log.Error("Rename: no mount found for path: %s - %v", absolute, err)
+			return err
+		}
+		relative := vfs.getPrefixRelativePath(mnt, absolute)
+		if err := mnt.CheckMandatoryLocksRecursive(ctx, relative, data.LockOwner(ctx), data.AccessModeWrite); err != nil {
+			vfs.log.Error("Rename: mandatory lock check failed for %s - %v", absolute, err)
+			return err
+		}
+	}
+
+	// Prefer the native rename of the backends, if both paths share the same mount
+	handled, err := vfs.renameNative(ctx, oldAbsolute, newAbsolute)
+	if err != nil {
+		return err
+	}
+	if handled {
+		if mnt, err := vfs.getMountFromPath(newAbsolute); err == nil {
+			mnt.Notify(data.EventRename, newAbsolute, oldAbsolute)
+		}
+		return nil
+	}
+
 	// Handle based on type
 	if oldStat.Mode.IsDir() {
 		vfs.log.Debug("Rename: renaming directory %s to %s", oldAbsolute, newAbsolute)
-		return vfs.renameDirectory(ctx, oldAbsolute, newAbsolute)
+		err = vfs.renameDirectory(ctx, oldAbsolute, newAbsolute)
+	} else {
+		// Handle file rename
+		vfs.log.Debug("Rename: renaming file %s to %s (size=%d)", oldAbsolute, newAbsolute, oldStat.Size)
+		err = vfs.renameFile(ctx, oldAbsolute, newAbsolute, oldStat)
+	}
+
+	if err != nil {
+		return err
 	}
 
-	// Handle file rename
-	vfs.log.Debug("Rename: renaming file %s to %s (size=%d)", oldAbsolute, newAbsolute, oldStat.Size)
-	return vfs.renameFile(ctx, oldAbsolute, newAbsolute, oldStat)
+	if mnt, err := vfs.getMountFromPath(newAbsolute); err == nil {
+		mnt.Notify(data.EventRename, newAbsolute, oldAbsolute)
+	}
+
+	return nil
 }
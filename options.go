@@ -1,18 +1,35 @@
 package vfs
 
-import "github.com/mwantia/vfs/log"
+import (
+	"fmt"
+	"time"
+
+	"github.com/mwantia/vfs/data"
+	"github.com/mwantia/vfs/fstab"
+	"github.com/mwantia/vfs/log"
+)
 
 type VirtualFileSystemOptions struct {
-	LogLevel      log.LogLevel
-	LogFile       string
-	NoTerminalLog bool
+	LogLevel        log.LogLevel
+	LogFile         string
+	NoTerminalLog   bool
+	WatchBufferSize int // Number of events buffered per watcher before overflowing
+
+	Interceptors    []Interceptor         // Ordered chain wrapping every operation
+	MountTable      *fstab.Table          // Declarative mounts created by Populate
+	NamespaceQuotas map[string]data.Quota // Quotas shared by all mounts of a namespace
+	PathLimits      data.PathLimits       // Maximum path and name lengths accepted by operations
+	GCInterval      time.Duration         // Interval of the scheduled garbage collection (0 disables it)
+	GCOptions       data.GCOptions        // Options used by the scheduled garbage collection
 }
 
 type VirtualFileSystemOption func(*VirtualFileSystemOptions) error
 
 func newDefaultVirtualFileSystemOptions() *VirtualFileSystemOptions {
 	return &VirtualFileSystemOptions{
-		LogLevel: log.Info,
+		LogLevel:        log.Info,
+		WatchBufferSize: 256,
+		PathLimits:      data.DefaultPathLimits,
 	}
 }
 
@@ -36,3 +53,109 @@ func WithLogFile(logFile string) VirtualFileSystemOption {
 		return nil
 	}
 }
+
+// WithWatchBufferSize defines the number of events buffered for each watcher.
+// Events exceeding the buffer are dropped and reported with a single overflow event.
+func WithWatchBufferSize(size int) VirtualFileSystemOption {
+	return func(opts *VirtualFileSystemOptions) error {
+		if size <= 0 {
+			return fmt.Errorf("watch buffer size must be greater than zero")
+		}
+
+		opts.WatchBufferSize = size
+		return nil
+	}
+}
+
+// WithInterceptors appends interceptors to the chain wrapping every operation.
+// Interceptors are called in the order they have been added.
+func WithInterceptors(interceptors ...Interceptor) VirtualFileSystemOption {
+	return func(opts *VirtualFileSystemOptions) error {
+		for _, interceptor := range interceptors {
+			if interceptor == nil {
+				return fmt.Errorf("interceptor must not be nil")
+			}
+		}
+
+		opts.Interceptors = append(opts.Interceptors, interceptors...)
+		return nil
+	}
+}
+
+// WithMountTable defines the mounts created by Populate.
+// Backends referenced by the table must be registered (e.g. by importing mount/backend/all).
+func WithMountTable(table *fstab.Table) VirtualFileSystemOption {
+	return func(opts *VirtualFileSystemOptions) error {
+		if table == nil {
+			return fmt.Errorf("mount table must not be nil")
+		}
+		if err := table.Validate(); err != nil {
+			return err
+		}
+
+		opts.MountTable = table
+		return nil
+	}
+}
+
+// WithMountTableFile loads the mounts created by Populate from a JSON file.
+func WithMountTableFile(path string) VirtualFileSystemOption {
+	return func(opts *VirtualFileSystemOptions) error {
+		table, err := fstab.Load(path)
+		if err != nil {
+			return err
+		}
+
+		opts.MountTable = table
+		return nil
+	}
+}
+
+// WithNamespaceQuota limits the total size and number of objects stored by all mounts of namespace.
+func WithNamespaceQuota(namespace string, quota data.Quota) VirtualFileSystemOption {
+	return func(opts *VirtualFileSystemOptions) error {
+		if quota.MaxBytes < 0 || quota.MaxObjects < 0 {
+			return fmt.Errorf("quota for namespace '%s' must not be negative", namespace)
+		}
+
+		if opts.NamespaceQuotas == nil {
+			opts.NamespaceQuotas = make(map[string]data.Quota)
+		}
+		opts.NamespaceQuotas[namespace] = quota
+		return nil
+	}
+}
+
+// WithPathLimits defines the maximum length in bytes of paths and of single names within paths.
+// A limit of zero disables the respective check.
+func WithPathLimits(maxPathLength, maxNameLength int) VirtualFileSystemOption {
+	return func(opts *VirtualFileSystemOptions) error {
+		if maxPathLength < 0 || maxNameLength < 0 {
+			return fmt.Errorf("path limits must not be negative")
+		}
+
+		opts.PathLimits = data.PathLimits{
+			MaxPathLength: maxPathLength,
+			MaxNameLength: maxNameLength,
+		}
+		return nil
+	}
+}
+
+// WithGarbageCollection schedules garbage collection of all writable mounts every interval.
+// The grace period should exceed the duration of the longest write, so in-flight content is never removed.
+// A zero grace period uses data.DefaultGCGracePeriod, unless opts.Immediate is set.
+func WithGarbageCollection(interval time.Duration, opts data.GCOptions) VirtualFileSystemOption {
+	return func(vfsOpts *VirtualFileSystemOptions) error {
+		if interval <= 0 {
+			return fmt.Errorf("garbage collection interval must be greater than zero")
+		}
+		if opts.GracePeriod < 0 {
+			return fmt.Errorf("garbage collection grace period must not be negative")
+		}
+
+		vfsOpts.GCInterval = interval
+		vfsOpts.GCOptions = opts
+		return nil
+	}
+}
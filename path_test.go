@@ -0,0 +1,190 @@
+package vfs_test
+
+import (
+	"errors"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/mwantia/vfs"
+	"github.com/mwantia/vfs/data"
+	"github.com/mwantia/vfs/log"
+	"github.com/mwantia/vfs/mount/backend/direct"
+)
+
+// TestPath_Parse verifies that paths are converted into their canonical form or rejected.
+func TestPath_Parse(t *testing.T) {
+	tests := []struct {
+		raw      string
+		expected data.Path
+		err      error
+	}{
+		{raw: "/", expected: "/"},
+		{raw: "a/b", expected: "/a/b"},
+		{raw: "/a/../../etc", expected: "/etc"},
+		{raw: "//x", expected: "/x"},
+		{raw: "/a/./b", expected: "/a/b"},
+		{raw: "/a/b/", expected: "/a/b"},
+		{raw: "/..", expected: "/"},
+		{raw: "", err: data.ErrInvalidPath},
+		{raw: "/a\x00b", err: data.ErrInvalidPath},
+		{raw: "/\xff", err: data.ErrInvalidPath},
+		{raw: "/" + strings.Repeat("a", data.DefaultMaxNameLength+1), err: data.ErrNameTooLong},
+		{raw: strings.Repeat("/abc", data.DefaultMaxPathLength/4+1), err: data.ErrPathTooLong},
+	}
+
+	for _, test := range tests {
+		canonical, err := data.ParsePath(test.raw)
+		if test.err != nil {
+			if !errors.Is(err, test.err) {
+				t.Errorf("ParsePath(%q): expected %v, got %v", test.raw, test.err, err)
+			}
+			continue
+		}
+		if err != nil || canonical != test.expected {
+			t.Errorf("ParsePath(%q): expected %q, got %q (%v)", test.raw, test.expected, canonical, err)
+		}
+	}
+
+	canonical := data.Path("/a/b")
+	if canonical.Name() != "b" || canonical.Parent() != "/a" || !canonical.HasPrefix("/a") || data.Path("/ab").HasPrefix("/a") {
+		t.Errorf("Unexpected path helpers for %q", canonical)
+	}
+	if relative, ok := canonical.Relative("/"); !ok || relative != "a/b" {
+		t.Errorf("Expected relative path 'a/b', got %q", relative)
+	}
+	if joined, err := canonical.Join("..", "..", "..", "c"); err != nil || joined != "/c" {
+		t.Errorf("Expected joined path '/c', got %q (%v)", joined, err)
+	}
+}
+
+// TestPath_Operations verifies that operations use canonical paths and enforce configured limits.
+func TestPath_Operations(t *testing.T) {
+	ctx := t.Context()
+	fs, err := vfs.NewVirtualFileSystem(vfs.WithLogLevel(log.Error), vfs.WithPathLimits(64, 8))
+	if err != nil {
+		t.Fatalf("Failed to initialize vfs: %v", err)
+	}
+	defer fs.Shutdown(ctx)
+
+	root := t.TempDir()
+	storage, err := direct.NewDirectBackend(filepath.Join(root, "mnt"))
+	if err != nil {
+		t.Fatalf("Failed to create direct backend: %v", err)
+	}
+	if err := os.Mkdir(filepath.Join(root, "mnt"), 0755); err != nil {
+		t.Fatalf("Failed to create mount directory: %v", err)
+	}
+	if err := fs.Mount(ctx, "/", storage); err != nil {
+		t.Fatalf("Failed to mount /: %v", err)
+	}
+
+	writeTestFile(t, fs, "/../../esc.txt", []byte("data"))
+	if _, err := os.Stat(filepath.Join(root, "esc.txt")); err == nil {
+		t.Errorf("Expected file to stay within the backend root")
+	}
+	if _, err := fs.StatMetadata(ctx, "//esc.txt/"); err != nil {
+		t.Errorf("Expected file to be created at /esc.txt: %v", err)
+	}
+
+	if err := fs.CreateDirectory(ctx, "/toolongname"); !errors.Is(err, data.ErrNameTooLong) {
+		t.Errorf("Expected ErrNameTooLong, got %v", err)
+	}
+	if err := fs.CreateDirectory(ctx, strings.Repeat("/abcdefg", 9)); !errors.Is(err, data.ErrPathTooLong) {
+		t.Errorf("Expected ErrPathTooLong, got %v", err)
+	}
+	if err := fs.CreateDirectory(ctx, "/a\x00b"); !errors.Is(err, data.ErrInvalidPath) {
+		t.Errorf("Expected ErrInvalidPath, got %v", err)
+	}
+}
+
+// FuzzParsePath verifies that canonical paths are always rooted, clean and stable.
+func FuzzParsePath(f *testing.F) {
+	for _, seed := range []string{"/", "a", "/a/../../etc", "//x", "/a/./b/", "..", "/a\x00", "\xff/.."} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, raw string) {
+		canonical, err := data.ParsePath(raw)
+		if err != nil {
+			return
+		}
+
+		value := canonical.String()
+		if !strings.HasPrefix(value, "/") || path.Clean(value) != value {
+			t.Fatalf("ParsePath(%q) returned non-canonical path %q", raw, value)
+		}
+		if slices.Contains(canonical.Segments(), "..") {
+			t.Fatalf("ParsePath(%q) returned path %q containing '..'", raw, value)
+		}
+		if again, err := data.ParsePath(value); err != nil || again != canonical {
+			t.Fatalf("ParsePath(%q) is not idempotent: %q != %q (%v)", raw, again, canonical, err)
+		}
+	})
+}
+
+// FuzzDirectBackend_Confinement verifies that no key can create, modify or remove
+// anything outside of the direct backend root, even through host symlinks.
+func FuzzDirectBackend_Confinement(f *testing.F) {
+	for _, seed := range []string{"a", "../outside/secret", "/../../outside/x", "escape/secret", "absolute/new", "escape/../../outside", "./a/../escape"} {
+		f.Add(seed, "moved")
+	}
+
+	base := f.TempDir()
+	root := filepath.Join(base, "root")
+	outside := filepath.Join(base, "outside")
+	for _, dir := range []string{root, outside} {
+		if err := os.Mkdir(dir, 0755); err != nil {
+			f.Fatalf("Failed to create %s: %v", dir, err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(outside, "secret"), []byte("secret"), 0644); err != nil {
+		f.Fatalf("Failed to create secret: %v", err)
+	}
+
+	storage, err := direct.NewDirectBackend(root)
+	if err != nil {
+		f.Fatalf("Failed to create direct backend: %v", err)
+	}
+	if err := storage.Open(f.Context()); err != nil {
+		f.Fatalf("Failed to open direct backend: %v", err)
+	}
+	defer storage.Close(f.Context())
+
+	f.Fuzz(func(t *testing.T, key string, newKey string) {
+		ctx := t.Context()
+
+		// Symlinks may have been renamed or removed by previous inputs
+		for name, target := range map[string]string{"escape": "../outside", "absolute": outside} {
+			os.Remove(filepath.Join(root, name))
+			if err := os.Symlink(target, filepath.Join(root, name)); err != nil {
+				t.Fatalf("Failed to create symlink %s: %v", name, err)
+			}
+		}
+
+		storage.CreateObject(ctx, "", key, data.ModeDir|0755)
+		storage.CreateObject(ctx, "", key, 0644)
+		storage.WriteObject(ctx, "", key, 0, []byte("fuzz"))
+		storage.TruncateObject(ctx, "", key, 0)
+		storage.RenameObject(ctx, "", key, newKey)
+		storage.DeleteObject(ctx, "", newKey, false)
+		storage.DeleteObject(ctx, "", key, true)
+
+		entries, err := os.ReadDir(outside)
+		if err != nil {
+			t.Fatalf("Failed to read outside directory: %v", err)
+		}
+		if len(entries) != 1 || entries[0].Name() != "secret" {
+			t.Fatalf("Key %q escaped the backend root: outside contains %v", key, entries)
+		}
+		if content, err := os.ReadFile(filepath.Join(outside, "secret")); err != nil || string(content) != "secret" {
+			t.Fatalf("Key %q modified a file outside of the backend root: %q (%v)", key, content, err)
+		}
+		if _, err := os.Stat(filepath.Join(base, "moved")); err == nil {
+			t.Fatalf("Key %q was moved outside of the backend root", key)
+		}
+	})
+}
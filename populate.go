@@ -0,0 +1,195 @@
+package vfs
+
+import (
+	"context"
+	"maps"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/mwantia/vfs/data"
+	"github.com/mwantia/vfs/fstab"
+	"github.com/mwantia/vfs/mount"
+	"github.com/mwantia/vfs/mount/backend"
+)
+
+// Populate creates all mounts defined by the mount table in dependency order
+// and creates their directory skeletons. If any mount fails, all mounts created
+// by this call are unmounted again in reverse order.
+func (vfs *virtualFileSystemImpl) Populate(ctx context.Context) error {
+	if vfs.table == nil {
+		vfs.log.Debug("Populate: no mount table defined, nothing to populate")
+		return nil
+	}
+
+	entries, err := vfs.table.Ordered()
+	if err != nil {
+		vfs.log.Error("Populate: failed to order mount table - %v", err)
+		return err
+	}
+
+	vfs.log.Info("Populate: populating %d mount(s) from mount table", len(entries))
+
+	mounted := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if err := vfs.mountEntry(ctx, entry); err != nil {
+			vfs.log.Error("Populate: failed to populate mount %s - %v", entry.Path, err)
+			vfs.rollbackPopulate(ctx, mounted)
+			return err
+		}
+
+		mounted = append(mounted, entry.Path)
+	}
+
+	vfs.log.Info("Populate: successfully populated %d mount(s)", len(mounted))
+	return nil
+}
+
+// MountEntry creates the backends of a single mount table entry, mounts them and creates the directory skeleton.
+// The entry is kept, so it's included in DumpMountTable including all backend parameters.
+func (vfs *virtualFileSystemImpl) MountEntry(ctx context.Context, entry *fstab.Entry) error {
+	table := &fstab.Table{
+		Mounts: []*fstab.Entry{entry},
+	}
+	if err := table.Validate(); err != nil {
+		vfs.log.Error("MountEntry: invalid mount table entry - %v", err)
+		return err
+	}
+
+	if err := vfs.mountEntry(ctx, entry); err != nil {
+		vfs.log.Error("MountEntry: failed to mount %s - %v", entry.Path, err)
+		return err
+	}
+
+	return nil
+}
+
+// mountEntry creates the backends of entry, mounts them and creates the directory skeleton.
+func (vfs *virtualFileSystemImpl) mountEntry(ctx context.Context, entry *fstab.Entry) error {
+	primary, opts, err := entry.CreateBackends()
+	if err != nil {
+		return err
+	}
+
+	if err := vfs.Mount(ctx, entry.Path, primary, opts...); err != nil {
+		return err
+	}
+
+	vfs.mu.Lock()
+	vfs.entries[entry.Path] = entry
+	vfs.mu.Unlock()
+
+	for _, dir := range entry.Skeleton() {
+		if err := vfs.createSkeleton(ctx, entry.Path, dir); err != nil {
+			vfs.log.Error("MountEntry: failed to create skeleton directory %s - %v", dir, err)
+			if err := vfs.Unmount(ctx, entry.Path, true); err != nil {
+				vfs.log.Error("MountEntry: failed to roll back mount %s - %v", entry.Path, err)
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
+// createSkeleton creates dir including all missing parents below the mount path.
+func (vfs *virtualFileSystemImpl) createSkeleton(ctx context.Context, mountPath, dir string) error {
+	current := mountPath
+	for part := range strings.SplitSeq(strings.TrimPrefix(dir, mountPath), "/") {
+		if part == "" {
+			continue
+		}
+		current = path.Join(current, part)
+
+		meta, err := vfs.StatMetadata(ctx, current)
+		if err == nil {
+			if !meta.Mode.IsDir() {
+				return data.ErrNotDirectory
+			}
+			continue
+		}
+		if err != data.ErrNotExist {
+			return err
+		}
+
+		vfs.log.Debug("MountEntry: creating skeleton directory %s", current)
+		if err := vfs.CreateDirectory(ctx, current); err != nil && err != data.ErrExist {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// rollbackPopulate unmounts all paths in reverse order.
+func (vfs *virtualFileSystemImpl) rollbackPopulate(ctx context.Context, paths []string) {
+	for _, path := range slices.Backward(paths) {
+		vfs.log.Debug("Populate: rolling back mount %s", path)
+		if err := vfs.Unmount(ctx, path, true); err != nil {
+			vfs.log.Error("Populate: failed to roll back mount %s - %v", path, err)
+		}
+	}
+}
+
+// DumpMountTable returns the current mounts in the format used by the mount table.
+// Mounts created by Populate or MountEntry are returned as defined; for all other mounts, the entry is
+// derived from the mount itself, which can't include backend parameters. The same applies to the backends
+// of migrated mounts, while their skeleton directories and dependencies are kept.
+func (vfs *virtualFileSystemImpl) DumpMountTable() *fstab.Table {
+	vfs.mu.RLock()
+	defer vfs.mu.RUnlock()
+
+	table := &fstab.Table{
+		Mounts: make([]*fstab.Entry, 0, len(vfs.mnts)),
+	}
+	for _, path := range slices.Sorted(maps.Keys(vfs.mnts)) {
+		if entry, exists := vfs.entries[path]; exists {
+			table.Mounts = append(table.Mounts, entry)
+			continue
+		}
+
+		table.Mounts = append(table.Mounts, toMountTableEntry(vfs.mnts[path]))
+	}
+
+	return table
+}
+
+// toMountTableEntry derives the mount table entry from an existing mount.
+func toMountTableEntry(mnt *mount.Mount) *fstab.Entry {
+	entry := &fstab.Entry{
+		Path:           mnt.Path,
+		Backend:        mnt.ObjectStorage.Name(),
+		Namespace:      mnt.Options.Namespace,
+		PathPrefix:     mnt.Options.PathPrefix,
+		ReadOnly:       mnt.Options.IsReadOnly,
+		DenyNesting:    !mnt.Options.AllowNesting,
+		AutoExtensions: mnt.Options.AutoExtensions,
+		MandatoryLocks: mnt.Options.MandatoryLocks,
+		WatchBackend:   mnt.Options.WatchBackend,
+		Quota:          mnt.Options.Quota,
+		UserQuotas:     maps.Clone(mnt.Options.UserQuotas),
+	}
+
+	// Group all capabilities provided by the same backend instance into one extension
+	extensions := make(map[backend.Backend]*fstab.Extension)
+	for _, capability := range slices.Sorted(maps.Keys(mnt.Options.Backends)) {
+		ext := mnt.Options.Backends[capability]
+		if existing, exists := extensions[ext]; exists {
+			existing.Capabilities = append(existing.Capabilities, capability)
+			continue
+		}
+
+		name := ext.Name()
+		if ext == backend.Backend(mnt.ObjectStorage) {
+			name = fstab.PrimaryBackend
+		}
+
+		extensions[ext] = &fstab.Extension{
+			Backend:      name,
+			Capabilities: []backend.BackendCapability{capability},
+		}
+		entry.Extensions = append(entry.Extensions, extensions[ext])
+	}
+
+	return entry
+}
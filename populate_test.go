@@ -0,0 +1,238 @@
+package vfs_test
+
+import (
+	"bytes"
+	"errors"
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mwantia/vfs"
+	"github.com/mwantia/vfs/data"
+	"github.com/mwantia/vfs/fstab"
+	"github.com/mwantia/vfs/log"
+	"github.com/mwantia/vfs/mount/backend"
+	_ "github.com/mwantia/vfs/mount/backend/all"
+	"github.com/mwantia/vfs/mount/backend/ephemeral"
+)
+
+const testMountTable = `{
+  "mounts": [
+    {
+      "path": "/data/cache",
+      "backend": "ephemeral",
+      "deny_nesting": true,
+      "directories": ["tmp"]
+    },
+    {
+      "path": "/data",
+      "backend": "sqlite",
+      "params": { "path": ":memory:" },
+      "namespace": "team",
+      "extensions": [
+        { "backend": "primary", "capabilities": ["metadata"] }
+      ],
+      "directories": ["logs/app", "uploads"],
+      "depends_on": ["/"]
+    },
+    {
+      "path": "/",
+      "url": "mem://",
+      "directories": ["home"]
+    }
+  ]
+}`
+
+func TestPopulate_MountTable(t *testing.T) {
+	ctx := t.Context()
+
+	table, err := fstab.Parse(strings.NewReader(testMountTable))
+	if err != nil {
+		t.Fatalf("Failed to parse mount table: %v", err)
+	}
+
+	ordered, err := table.Ordered()
+	if err != nil {
+		t.Fatalf("Failed to order mount table: %v", err)
+	}
+	var order []string
+	for _, entry := range ordered {
+		order = append(order, entry.Path)
+	}
+	if got := strings.Join(order, " "); got != "/ /data /data/cache" {
+		t.Errorf("Expected mounts ordered by dependency, got %q", got)
+	}
+
+	fs, err := vfs.NewVirtualFileSystem(vfs.WithLogLevel(log.Error), vfs.WithMountTable(table))
+	if err != nil {
+		t.Fatalf("Failed to initialize vfs: %v", err)
+	}
+	defer fs.Shutdown(ctx)
+
+	if err := fs.Populate(ctx); err != nil {
+		t.Fatalf("Failed to populate vfs: %v", err)
+	}
+
+	for _, dir := range []string{"/home", "/data/logs", "/data/logs/app", "/data/uploads", "/data/cache/tmp"} {
+		meta, err := fs.StatMetadata(ctx, dir)
+		if err != nil {
+			t.Errorf("Expected skeleton directory %s: %v", dir, err)
+			continue
+		}
+		if !meta.Mode.IsDir() {
+			t.Errorf("Expected %s to be a directory", dir)
+		}
+	}
+
+	// Flags from the table are applied to the mount
+	if err := fs.Mount(ctx, "/data/cache/nested", ephemeral.NewEphemeralBackend()); err == nil {
+		t.Errorf("Expected nesting below /data/cache to be denied")
+	}
+
+	// Mounts created outside of the table are dumped as well
+	if err := fs.Mount(ctx, "/extra", ephemeral.NewEphemeralBackend()); err != nil {
+		t.Fatalf("Failed to mount /extra: %v", err)
+	}
+
+	var buffer bytes.Buffer
+	if err := fs.DumpMountTable().Encode(&buffer); err != nil {
+		t.Fatalf("Failed to encode mount table: %v", err)
+	}
+
+	dumped, err := fstab.Parse(&buffer)
+	if err != nil {
+		t.Fatalf("Failed to parse dumped mount table: %v", err)
+	}
+	if len(dumped.Mounts) != 4 {
+		t.Fatalf("Expected 4 dumped mounts, got %d", len(dumped.Mounts))
+	}
+
+	entry, exists := dumped.Lookup("/data")
+	if !exists {
+		t.Fatalf("Expected /data within dumped mount table")
+	}
+	if entry.Backend != "sqlite" || entry.Namespace != "team" || entry.Params["path"] != ":memory:" {
+		t.Errorf("Expected /data to be dumped as defined, got %+v", entry)
+	}
+	if len(entry.Extensions) != 1 || entry.Extensions[0].Backend != fstab.PrimaryBackend {
+		t.Errorf("Expected /data to use the primary backend as extension, got %+v", entry.Extensions)
+	}
+
+	if entry, exists := dumped.Lookup("/extra"); !exists || entry.Backend != "ephemeral" {
+		t.Errorf("Expected /extra to be derived from its mount, got %+v", entry)
+	}
+
+	// Unmounted entries are no longer dumped
+	if err := fs.Unmount(ctx, "/extra", false); err != nil {
+		t.Fatalf("Failed to unmount /extra: %v", err)
+	}
+	if _, exists := fs.DumpMountTable().Lookup("/extra"); exists {
+		t.Errorf("Expected /extra to be removed from dumped mount table")
+	}
+}
+
+func TestPopulate_Errors(t *testing.T) {
+	ctx := t.Context()
+
+	tests := map[string]string{
+		"unknown field":      `{"mounts": [{"path": "/", "backend": "ephemeral", "unknown": true}]}`,
+		"missing backend":    `{"mounts": [{"path": "/"}]}`,
+		"duplicate path":     `{"mounts": [{"path": "/", "backend": "ephemeral"}, {"path": "/", "backend": "sqlite"}]}`,
+		"unknown capability": `{"mounts": [{"path": "/", "backend": "sqlite", "extensions": [{"backend": "primary", "capabilities": ["unknown"]}]}]}`,
+		"escaping directory": `{"mounts": [{"path": "/data", "backend": "ephemeral", "directories": ["../etc"]}]}`,
+	}
+	for name, content := range tests {
+		if _, err := fstab.Parse(strings.NewReader(content)); err == nil {
+			t.Errorf("Expected parse error for %s", name)
+		}
+	}
+
+	cyclic, err := fstab.Parse(strings.NewReader(`{"mounts": [
+		{"path": "/a", "backend": "ephemeral", "depends_on": ["/b"]},
+		{"path": "/b", "backend": "ephemeral", "depends_on": ["/a"]}
+	]}`))
+	if err != nil {
+		t.Fatalf("Failed to parse mount table: %v", err)
+	}
+	if _, err := cyclic.Ordered(); !errors.Is(err, data.ErrCircularReference) {
+		t.Errorf("Expected circular reference error, got %v", err)
+	}
+
+	// A failing mount rolls back all mounts created before
+	file := filepath.Join(t.TempDir(), "fstab.json")
+	content := `{"mounts": [
+		{"path": "/", "backend": "ephemeral"},
+		{"path": "/data", "backend": "ephemeral"},
+		{"path": "/data/remote", "backend": "unknown"}
+	]}`
+	if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write mount table: %v", err)
+	}
+
+	fs, err := vfs.NewVirtualFileSystem(vfs.WithLogLevel(log.Error), vfs.WithMountTableFile(file))
+	if err != nil {
+		t.Fatalf("Failed to initialize vfs: %v", err)
+	}
+	defer fs.Shutdown(ctx)
+
+	if err := fs.Populate(ctx); err == nil {
+		t.Fatalf("Expected populate to fail for unknown backend")
+	}
+	if mounts := fs.DumpMountTable().Mounts; len(mounts) != 0 {
+		t.Errorf("Expected all mounts to be rolled back, got %d", len(mounts))
+	}
+}
+
+func TestRegistry_ParseURL(t *testing.T) {
+	tests := []struct {
+		url      string
+		name     string
+		expected backend.FactoryParams
+	}{
+		{"sqlite:///var/vfs.db", "sqlite", backend.FactoryParams{"path": "/var/vfs.db"}},
+		{"sqlite://vfs.db", "sqlite", backend.FactoryParams{"path": "vfs.db"}},
+		{"sqlite::memory:", "sqlite", backend.FactoryParams{"path": ":memory:"}},
+		{"dir:///srv/data", "direct", backend.FactoryParams{"path": "/srv/data"}},
+		{"mem://", "ephemeral", backend.FactoryParams{}},
+		{"s3://access:secret@bucket?endpoint=localhost:9000&ssl=false", "s3", backend.FactoryParams{
+			"bucket": "bucket", "endpoint": "localhost:9000", "ssl": "false", "access_key": "access", "secret_key": "secret",
+		}},
+		{"consul://127.0.0.1:8500/vfs/data?datacenter=dc1", "consul", backend.FactoryParams{
+			"address": "127.0.0.1:8500", "prefix": "vfs/data", "datacenter": "dc1",
+		}},
+		{"postgresql://user@localhost/vfs?sslmode=disable", "postgres", backend.FactoryParams{
+			"dsn": "postgresql://user@localhost/vfs?sslmode=disable",
+		}},
+	}
+	for _, test := range tests {
+		name, params, err := backend.ParseURL(test.url)
+		if err != nil {
+			t.Errorf("Failed to parse %s: %v", test.url, err)
+			continue
+		}
+		if name != test.name || !maps.Equal(params, test.expected) {
+			t.Errorf("Expected %s to resolve to %s %v, got %s %v", test.url, test.name, test.expected, name, params)
+		}
+	}
+
+	for _, raw := range []string{"/no/scheme", "unknown:///x", "s3://bucket/prefix?endpoint=localhost"} {
+		if _, _, err := backend.ParseURL(raw); err == nil {
+			t.Errorf("Expected %s to fail", raw)
+		}
+	}
+
+	// Parameters are validated against the schema, including their types and defaults
+	registration, exists := backend.GetRegistration("s3")
+	if !exists {
+		t.Fatalf("Expected s3 to be registered")
+	}
+	if _, err := registration.Validate(backend.FactoryParams{"endpoint": "localhost", "bucket": "b", "ssl": "maybe"}); err == nil {
+		t.Errorf("Expected invalid boolean to fail validation")
+	}
+	validated, err := registration.Validate(backend.FactoryParams{"endpoint": "localhost", "bucket": "b"})
+	if err != nil || validated["ssl"] != "true" {
+		t.Errorf("Expected default ssl=true, got %v: %v", validated, err)
+	}
+}
@@ -0,0 +1,206 @@
+package vfs_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/mwantia/vfs"
+	"github.com/mwantia/vfs/cmd"
+	"github.com/mwantia/vfs/data"
+	"github.com/mwantia/vfs/log"
+	"github.com/mwantia/vfs/mount"
+	"github.com/mwantia/vfs/mount/backend/ephemeral"
+)
+
+// TestQuota_Mount verifies that byte and object quotas of a mount are enforced and maintained incrementally.
+func TestQuota_Mount(t *testing.T) {
+	ctx := t.Context()
+	fs, err := vfs.NewVirtualFileSystem(vfs.WithLogLevel(log.Error))
+	if err != nil {
+		t.Fatalf("Failed to initialize vfs: %v", err)
+	}
+	defer fs.Shutdown(ctx)
+
+	if err := fs.Mount(ctx, "/", ephemeral.NewEphemeralBackend(), mount.WithQuota(data.Quota{MaxBytes: 100, MaxObjects: 4})); err != nil {
+		t.Fatalf("Failed to mount /: %v", err)
+	}
+
+	writeTestFile(t, fs, "/a.txt", bytes.Repeat([]byte("a"), 60))
+	if _, err := fs.WriteFile(ctx, "/a.txt", 60, bytes.Repeat([]byte("a"), 50)); !errors.Is(err, data.ErrQuotaExceeded) {
+		t.Errorf("Expected ErrQuotaExceeded for WriteFile, got %v", err)
+	}
+	// Overwriting existing content doesn't grow the usage
+	if _, err := fs.WriteFile(ctx, "/a.txt", 0, bytes.Repeat([]byte("b"), 60)); err != nil {
+		t.Errorf("Expected overwrite within the quota to succeed: %v", err)
+	}
+
+	streamer, err := fs.OpenFile(ctx, "/b.txt", data.AccessModeWrite|data.AccessModeCreate)
+	if err != nil {
+		t.Fatalf("Open /b.txt failed: %v", err)
+	}
+	if _, err := streamer.Write(bytes.Repeat([]byte("b"), 50)); !errors.Is(err, data.ErrQuotaExceeded) {
+		t.Errorf("Expected ErrQuotaExceeded for streamer write, got %v", err)
+	}
+	if _, err := streamer.Write(bytes.Repeat([]byte("b"), 40)); err != nil {
+		t.Errorf("Expected write within the quota to succeed: %v", err)
+	}
+	streamer.Close()
+
+	if err := fs.CreateDirectory(ctx, "/dir"); err != nil {
+		t.Fatalf("CreateDirectory failed: %v", err)
+	}
+	writeTestFile(t, fs, "/dir/c.txt", nil)
+	if err := fs.CreateDirectory(ctx, "/other"); !errors.Is(err, data.ErrQuotaExceeded) {
+		t.Errorf("Expected ErrQuotaExceeded for object quota, got %v", err)
+	}
+
+	usage, err := fs.Usage(ctx, "/dir/c.txt")
+	if err != nil {
+		t.Fatalf("Usage failed: %v", err)
+	}
+	if usage.Path != "/" || usage.Mount.Bytes != 100 || usage.Mount.Objects != 4 {
+		t.Errorf("Expected 100 bytes in 4 objects, got %d bytes in %d objects", usage.Mount.Bytes, usage.Mount.Objects)
+	}
+	if usage.Mount.AvailableBytes() != 0 || usage.Mount.AvailableObjects() != 0 {
+		t.Errorf("Expected no space available, got %d bytes and %d objects", usage.Mount.AvailableBytes(), usage.Mount.AvailableObjects())
+	}
+
+	// Removing objects releases their usage
+	if err := fs.UnlinkFile(ctx, "/a.txt"); err != nil {
+		t.Fatalf("UnlinkFile failed: %v", err)
+	}
+	if err := fs.RemoveDirectory(ctx, "/dir", true); err != nil {
+		t.Fatalf("RemoveDirectory failed: %v", err)
+	}
+	if usage, err = fs.Usage(ctx, "/"); err != nil || usage.Mount.Bytes != 40 || usage.Mount.Objects != 1 {
+		t.Errorf("Expected 40 bytes in 1 object after removal, got %+v: %v", usage, err)
+	}
+
+	// Truncating releases the previous size
+	streamer, err = fs.OpenFile(ctx, "/b.txt", data.AccessModeWrite|data.AccessModeTrunc)
+	if err != nil {
+		t.Fatalf("Open with truncate failed: %v", err)
+	}
+	streamer.Close()
+	if usage, err = fs.Usage(ctx, "/"); err != nil || usage.Mount.Bytes != 0 {
+		t.Errorf("Expected 0 bytes after truncation, got %+v: %v", usage, err)
+	}
+}
+
+// TestQuota_NamespaceAndUsers verifies quotas shared by all mounts of a namespace and quotas per owning UID.
+func TestQuota_NamespaceAndUsers(t *testing.T) {
+	ctx := t.Context()
+	fs, err := vfs.NewVirtualFileSystem(vfs.WithLogLevel(log.Error), vfs.WithNamespaceQuota("shared", data.Quota{MaxBytes: 100}))
+	if err != nil {
+		t.Fatalf("Failed to initialize vfs: %v", err)
+	}
+	defer fs.Shutdown(ctx)
+
+	if err := fs.Mount(ctx, "/", ephemeral.NewEphemeralBackend()); err != nil {
+		t.Fatalf("Failed to mount /: %v", err)
+	}
+	for _, path := range []string{"/one", "/two"} {
+		if err := fs.CreateDirectory(ctx, path); err != nil {
+			t.Fatalf("CreateDirectory %s failed: %v", path, err)
+		}
+		if err := fs.Mount(ctx, path, ephemeral.NewEphemeralBackend(), mount.WithNamespace("shared")); err != nil {
+			t.Fatalf("Failed to mount %s: %v", path, err)
+		}
+	}
+
+	writeTestFile(t, fs, "/one/a.txt", bytes.Repeat([]byte("a"), 60))
+	writeTestFile(t, fs, "/two/b.txt", nil)
+	if _, err := fs.WriteFile(ctx, "/two/b.txt", 0, bytes.Repeat([]byte("b"), 50)); !errors.Is(err, data.ErrQuotaExceeded) {
+		t.Errorf("Expected ErrQuotaExceeded for shared namespace quota, got %v", err)
+	}
+
+	usage, err := fs.Usage(ctx, "/two")
+	if err != nil {
+		t.Fatalf("Usage failed: %v", err)
+	}
+	if usage.NamespaceUsage == nil || usage.NamespaceUsage.Bytes != 60 || usage.Mount.Bytes != 0 {
+		t.Errorf("Expected 60 bytes used by the namespace and none by the mount, got %+v", usage)
+	}
+
+	// Unmounting releases the usage of the mount from the namespace
+	if err := fs.Unmount(ctx, "/one", true); err != nil {
+		t.Fatalf("Unmount failed: %v", err)
+	}
+	if _, err := fs.WriteFile(ctx, "/two/b.txt", 0, bytes.Repeat([]byte("b"), 50)); err != nil {
+		t.Errorf("Expected write to succeed after unmount: %v", err)
+	}
+
+	if err := fs.CreateDirectory(ctx, "/users"); err != nil {
+		t.Fatalf("CreateDirectory failed: %v", err)
+	}
+	if err := fs.Mount(ctx, "/users", ephemeral.NewEphemeralBackend(), mount.EnableAutoExtensions(), mount.WithUserQuota(1000, data.Quota{MaxBytes: 50})); err != nil {
+		t.Fatalf("Failed to mount /users: %v", err)
+	}
+
+	writeTestFile(t, fs, "/users/a.txt", bytes.Repeat([]byte("a"), 40))
+	writeTestFile(t, fs, "/users/b.txt", bytes.Repeat([]byte("b"), 20))
+	if err := fs.Chown(ctx, "/users/a.txt", 1000, -1); err != nil {
+		t.Fatalf("Chown failed: %v", err)
+	}
+	if err := fs.Chown(ctx, "/users/b.txt", 1000, -1); !errors.Is(err, data.ErrQuotaExceeded) {
+		t.Errorf("Expected ErrQuotaExceeded for chown, got %v", err)
+	}
+	if _, err := fs.WriteFile(ctx, "/users/a.txt", 40, bytes.Repeat([]byte("a"), 20)); !errors.Is(err, data.ErrQuotaExceeded) {
+		t.Errorf("Expected ErrQuotaExceeded for user quota, got %v", err)
+	}
+
+	if usage, err = fs.Usage(ctx, "/users"); err != nil {
+		t.Fatalf("Usage failed: %v", err)
+	}
+	if user := usage.Users[1000]; user == nil || user.Bytes != 40 || user.Objects != 1 {
+		t.Errorf("Expected 40 bytes in 1 object for uid 1000, got %+v", user)
+	}
+	if user := usage.Users[0]; user == nil || user.Bytes != 20 {
+		t.Errorf("Expected 20 bytes for uid 0, got %+v", user)
+	}
+}
+
+// TestQuota_Df verifies that usage is measured for mounts without quotas and reported by df.
+func TestQuota_Df(t *testing.T) {
+	ctx := t.Context()
+	fs, err := vfs.NewVirtualFileSystem(vfs.WithLogLevel(log.Error))
+	if err != nil {
+		t.Fatalf("Failed to initialize vfs: %v", err)
+	}
+	defer fs.Shutdown(ctx)
+
+	if err := fs.Mount(ctx, "/", ephemeral.NewEphemeralBackend()); err != nil {
+		t.Fatalf("Failed to mount /: %v", err)
+	}
+	if err := fs.CreateDirectory(ctx, "/dir"); err != nil {
+		t.Fatalf("CreateDirectory failed: %v", err)
+	}
+	writeTestFile(t, fs, "/dir/a.txt", []byte("hello"))
+	writeTestFile(t, fs, "/b.txt", []byte("world!"))
+
+	usage, err := fs.Usage(ctx, "/")
+	if err != nil {
+		t.Fatalf("Usage failed: %v", err)
+	}
+	if usage.Mount.Bytes != 11 || usage.Mount.Objects != 3 || usage.Mount.AvailableBytes() != -1 {
+		t.Errorf("Expected 11 bytes in 3 objects without limit, got %+v", usage.Mount)
+	}
+
+	var buffer bytes.Buffer
+	if code, err := fs.Execute(ctx, &buffer, "df"); err != nil || code != cmd.ExitSuccess {
+		t.Fatalf("df failed (code=%d): %v", code, err)
+	}
+	if fields := strings.Fields(strings.Split(buffer.String(), "\n")[1]); len(fields) < 2 || fields[0] != "/" || fields[1] != "11" {
+		t.Errorf("Unexpected df output:\n%s", buffer.String())
+	}
+
+	buffer.Reset()
+	if code, err := fs.Execute(ctx, &buffer, "df", "-i", "/dir"); err != nil || code != cmd.ExitSuccess {
+		t.Fatalf("df -i failed (code=%d): %v", code, err)
+	}
+	if fields := strings.Fields(strings.Split(buffer.String(), "\n")[1]); len(fields) < 2 || fields[1] != "3" {
+		t.Errorf("Unexpected df -i output:\n%s", buffer.String())
+	}
+}
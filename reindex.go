@@ -0,0 +1,51 @@
+package vfs
+
+import (
+	"context"
+
+	"github.com/mwantia/vfs/data"
+)
+
+// Reindex rebuilds the metadata of the mount containing path from its object storage.
+// A nil opts uses the default concurrency and batch size.
+func (vfs *virtualFileSystemImpl) Reindex(ctx context.Context, path string, opts *data.ReindexOptions) (*data.ReindexReport, error) {
+	return intercept(ctx, vfs, vfs.newOperation(OperationReindex, path), func(ctx context.Context, op *Operation) (*data.ReindexReport, error) {
+		return vfs.reindex(ctx, op.Path, opts)
+	})
+}
+
+// reindex implements Reindex, after the operation passed all interceptors.
+func (vfs *virtualFileSystemImpl) reindex(ctx context.Context, path string, opts *data.ReindexOptions) (*data.ReindexReport, error) {
+	if opts == nil {
+		opts = &data.ReindexOptions{}
+	}
+
+	// Always start with an absolute path
+	absolute, err := vfs.toAbsolutePath(path)
+	if err != nil {
+		vfs.log.Error("Reindex: failed to convert path to absolute: %s - %v", path, err)
+		return nil, err
+	}
+
+	vfs.log.Debug("Reindex: path=%s concurrency=%d checksums=%v resume=%v", absolute, opts.Concurrency, opts.Checksums, opts.Resume)
+
+	mnt, err := vfs.getMountFromPath(absolute)
+	if err != nil {
+		vfs.log.Error("Reindex: no mount found for path: %s - %v", absolute, err)
+		return nil, err
+	}
+	if mnt.Options.IsReadOnly {
+		vfs.log.Error("Reindex: cannot reindex read-only mount at %s", mnt.Path)
+		return nil, data.ErrReadOnly
+	}
+
+	report, err := mnt.Reindex(ctx, opts)
+	if err != nil {
+		vfs.log.Error("Reindex: failed to reindex %s - %v", mnt.Path, err)
+		return nil, err
+	}
+
+	vfs.log.Info("Reindex: listed %d directories in %s, created %d and updated %d entries, skipped %d",
+		report.Directories, mnt.Path, report.Created, report.Updated, report.Skipped)
+	return report, nil
+}
@@ -0,0 +1,137 @@
+package vfs_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/mwantia/vfs"
+	"github.com/mwantia/vfs/data"
+	"github.com/mwantia/vfs/log"
+	"github.com/mwantia/vfs/mount"
+	"github.com/mwantia/vfs/mount/backend/ephemeral"
+	"github.com/mwantia/vfs/mount/backend/sqlite"
+)
+
+// TestReindex_SplitMount verifies that metadata is rebuilt from object storage while existing attributes are preserved.
+func TestReindex_SplitMount(t *testing.T) {
+	ctx := t.Context()
+	fs, err := vfs.NewVirtualFileSystem(vfs.WithLogLevel(log.Error))
+	if err != nil {
+		t.Fatalf("Failed to initialize vfs: %v", err)
+	}
+	defer fs.Shutdown(ctx)
+
+	metadata, err := sqlite.NewSQLiteBackend(filepath.Join(t.TempDir(), "meta.db"))
+	if err != nil {
+		t.Fatalf("Failed to create sqlite backend: %v", err)
+	}
+
+	// Content written without any metadata, e.g. restored from a backup
+	storage := ephemeral.NewEphemeralBackend()
+	objects := map[string][]byte{
+		"readme.txt":       []byte("hello"),
+		"a/image.bin":      []byte("\x89PNG\r\n\x1a\n0000"),
+		"a/b/notes":        []byte("plain text without extension"),
+		"a/b/c/deep.json":  []byte("{}"),
+		"a/b/c/other.json": []byte("[]"),
+	}
+	for _, dir := range []string{"a", "a/b", "a/b/c"} {
+		if _, err := storage.CreateObject(ctx, "", dir, data.ModeDir|0755); err != nil {
+			t.Fatalf("CreateObject %s failed: %v", dir, err)
+		}
+	}
+	for key, content := range objects {
+		if _, err := storage.CreateObject(ctx, "", key, 0644); err != nil {
+			t.Fatalf("CreateObject %s failed: %v", key, err)
+		}
+		if _, err := storage.WriteObject(ctx, "", key, 0, content); err != nil {
+			t.Fatalf("WriteObject %s failed: %v", key, err)
+		}
+	}
+
+	if err := fs.Mount(ctx, "/", storage, mount.WithMetadata(metadata), mount.WithChecksum(data.ChecksumSHA256)); err != nil {
+		t.Fatalf("Failed to mount /: %v", err)
+	}
+
+	// Metadata of a single file survived and is expected to keep its attributes
+	existing := data.NewFileMetadata("readme.txt", 0, 0600)
+	existing.UID = 1000
+	existing.Attributes = map[string]string{"user.tag": "keep"}
+	if err := metadata.CreateMeta(ctx, "", existing); err != nil {
+		t.Fatalf("CreateMeta failed: %v", err)
+	}
+
+	report, err := fs.Reindex(ctx, "/", &data.ReindexOptions{Concurrency: 2, BatchSize: 2, Checksums: true})
+	if err != nil {
+		t.Fatalf("Reindex failed: %v", err)
+	}
+	if report.Directories != 4 || report.Created != 7 || report.Updated != 1 || report.Skipped != 0 {
+		t.Errorf("Unexpected report: %+v", report)
+	}
+
+	meta, err := fs.StatMetadata(ctx, "/readme.txt")
+	if err != nil {
+		t.Fatalf("StatMetadata failed: %v", err)
+	}
+	if meta.Size != 5 || meta.UID != 1000 || meta.Mode.Perm() != 0600 || meta.GetAttribute("user.tag", "") != "keep" {
+		t.Errorf("Expected existing metadata to be preserved, got %+v", meta)
+	}
+	if meta.GetAttribute(data.AttributeChecksum, "") == "" {
+		t.Error("Expected checksum to be computed")
+	}
+
+	if meta, err := fs.StatMetadata(ctx, "/a/image.bin"); err != nil || meta.ContentType != data.ContentTypeImagePNG {
+		t.Errorf("Expected sniffed png content type, got %+v (%v)", meta, err)
+	}
+	if meta, err := fs.StatMetadata(ctx, "/a/b/c"); err != nil || !meta.Mode.IsDir() || meta.HasAttribute(data.AttributeReindex) {
+		t.Errorf("Expected directory without reindex marker, got %+v (%v)", meta, err)
+	}
+
+	if report, err := fs.Fsck(ctx, "/", nil); err != nil || len(report.Issues) != 0 {
+		t.Errorf("Expected consistent mount after reindex, got %+v (%v)", report, err)
+	}
+}
+
+// TestReindex_Resume verifies that directories completed by an interrupted run are skipped.
+func TestReindex_Resume(t *testing.T) {
+	ctx := t.Context()
+	fs, err := vfs.NewVirtualFileSystem(vfs.WithLogLevel(log.Error))
+	if err != nil {
+		t.Fatalf("Failed to initialize vfs: %v", err)
+	}
+	defer fs.Shutdown(ctx)
+
+	storage, metadata := ephemeral.NewEphemeralBackend(), ephemeral.NewEphemeralBackend()
+	if err := fs.Mount(ctx, "/", storage, mount.WithMetadata(metadata)); err != nil {
+		t.Fatalf("Failed to mount /: %v", err)
+	}
+
+	if err := fs.CreateDirectory(ctx, "/done"); err != nil {
+		t.Fatalf("CreateDirectory failed: %v", err)
+	}
+	writeTestFile(t, fs, "/done/a.txt", []byte("a"))
+	writeTestFile(t, fs, "/done/b.txt", []byte("b"))
+	if _, err := storage.CreateObject(ctx, "", "pending.txt", 0644); err != nil {
+		t.Fatalf("CreateObject failed: %v", err)
+	}
+
+	// Simulate a run interrupted after completing the directory
+	if err := fs.SetAttributes(ctx, "/done", map[string]string{data.AttributeReindex: "completed"}); err != nil {
+		t.Fatalf("SetAttributes failed: %v", err)
+	}
+
+	report, err := fs.Reindex(ctx, "/", &data.ReindexOptions{Resume: true})
+	if err != nil {
+		t.Fatalf("Reindex failed: %v", err)
+	}
+	if report.Skipped != 2 || report.Created != 1 || report.Updated != 1 {
+		t.Errorf("Unexpected report: %+v", report)
+	}
+
+	if exists, _ := metadata.ExistsMeta(ctx, "", "pending.txt"); !exists {
+		t.Error("Expected metadata for pending.txt")
+	}
+	if meta, err := fs.StatMetadata(ctx, "/done"); err != nil || meta.HasAttribute(data.AttributeReindex) {
+		t.Errorf("Expected reindex marker to be removed, got %+v (%v)", meta, err)
+	}
+}
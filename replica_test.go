@@ -0,0 +1,184 @@
+package vfs_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"github.com/mwantia/vfs"
+	"github.com/mwantia/vfs/data"
+	"github.com/mwantia/vfs/log"
+	"github.com/mwantia/vfs/mount"
+	"github.com/mwantia/vfs/mount/backend"
+	"github.com/mwantia/vfs/mount/backend/ephemeral"
+	"github.com/mwantia/vfs/mount/backend/replica"
+)
+
+var errReplicaUnavailable = errors.New("replica unavailable")
+
+// flakyBackend simulates a replica, which fails all operations while unavailable.
+type flakyBackend struct {
+	*ephemeral.EphemeralBackend
+	unavailable atomic.Bool
+}
+
+func (fb *flakyBackend) CreateObject(ctx context.Context, namespace, key string, mode data.FileMode) (*data.FileStat, error) {
+	if fb.unavailable.Load() {
+		return nil, errReplicaUnavailable
+	}
+	return fb.EphemeralBackend.CreateObject(ctx, namespace, key, mode)
+}
+
+func (fb *flakyBackend) ReadObject(ctx context.Context, namespace, key string, offset int64, dat []byte) (int, error) {
+	if fb.unavailable.Load() {
+		return 0, errReplicaUnavailable
+	}
+	return fb.EphemeralBackend.ReadObject(ctx, namespace, key, offset, dat)
+}
+
+func (fb *flakyBackend) WriteObject(ctx context.Context, namespace, key string, offset int64, dat []byte) (int, error) {
+	if fb.unavailable.Load() {
+		return 0, errReplicaUnavailable
+	}
+	return fb.EphemeralBackend.WriteObject(ctx, namespace, key, offset, dat)
+}
+
+func (fb *flakyBackend) HeadObject(ctx context.Context, namespace, key string) (*data.FileStat, error) {
+	if fb.unavailable.Load() {
+		return nil, errReplicaUnavailable
+	}
+	return fb.EphemeralBackend.HeadObject(ctx, namespace, key)
+}
+
+func (fb *flakyBackend) TruncateObject(ctx context.Context, namespace, key string, size int64) error {
+	if fb.unavailable.Load() {
+		return errReplicaUnavailable
+	}
+	return fb.EphemeralBackend.TruncateObject(ctx, namespace, key, size)
+}
+
+// TestReplica_DegradeAndResync verifies that writes succeed with an unavailable replica and that it is repaired by a resync.
+func TestReplica_DegradeAndResync(t *testing.T) {
+	ctx := t.Context()
+	fs, err := vfs.NewVirtualFileSystem(vfs.WithLogLevel(log.Error))
+	if err != nil {
+		t.Fatalf("Failed to initialize vfs: %v", err)
+	}
+	defer fs.Shutdown(ctx)
+
+	flaky := &flakyBackend{EphemeralBackend: ephemeral.NewEphemeralBackend()}
+	healthy := ephemeral.NewEphemeralBackend()
+	storage, err := replica.NewReplicaBackend([]backend.ObjectStorageBackend{flaky, healthy, ephemeral.NewEphemeralBackend()}, &replica.ReplicaBackendConfig{
+		ResyncInterval: -1,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create replica backend: %v", err)
+	}
+	if err := fs.Mount(ctx, "/", storage, mount.WithMetadata(ephemeral.NewEphemeralBackend())); err != nil {
+		t.Fatalf("Failed to mount /: %v", err)
+	}
+
+	if err := fs.CreateDirectory(ctx, "/docs"); err != nil {
+		t.Fatalf("CreateDirectory failed: %v", err)
+	}
+	writeTestFile(t, fs, "/docs/a.txt", []byte("original"))
+
+	// Writes still reach the quorum of two replicas
+	flaky.unavailable.Store(true)
+	if _, err := fs.WriteFile(ctx, "/docs/a.txt", 0, []byte("modified")); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	writeTestFile(t, fs, "/b.txt", []byte("written while degraded"))
+
+	if status := storage.Status(); !status[0].Degraded || status[1].Degraded || status[2].Degraded {
+		t.Errorf("Expected only the first replica to be degraded, got %+v", status)
+	}
+	if result, err := fs.ReadFile(ctx, "/docs/a.txt", 0, 8); err != nil || string(result) != "modified" {
+		t.Errorf("Expected reads to be served by a healthy replica, got %q (%v)", result, err)
+	}
+
+	// Objects only known to the degraded replica are removed by the resync
+	flaky.unavailable.Store(false)
+	if _, err := flaky.CreateObject(ctx, "", "extra.txt", 0644); err != nil {
+		t.Fatalf("CreateObject failed: %v", err)
+	}
+
+	report, err := storage.Resync(ctx)
+	if err != nil {
+		t.Fatalf("Resync failed: %v", err)
+	}
+	if report.Repaired != 2 || report.Removed != 1 || len(report.Recovered) != 1 {
+		t.Errorf("Unexpected resync report: %+v", report)
+	}
+	if status := storage.Status(); status[0].Degraded {
+		t.Errorf("Expected first replica to be healthy after resync, got %+v", status[0])
+	}
+
+	expected := map[string]string{
+		"docs/a.txt": "modified",
+		"b.txt":      "written while degraded",
+	}
+	for key, content := range expected {
+		buffer := make([]byte, len(content)+1)
+		if n, err := flaky.ReadObject(ctx, "", key, 0, buffer); (err != nil && n == 0) || string(buffer[:n]) != content {
+			t.Errorf("Expected %q within repaired replica for %s, got %q (%v)", content, key, buffer[:n], err)
+		}
+	}
+	if _, err := flaky.HeadObject(ctx, "", "extra.txt"); err != data.ErrNotExist {
+		t.Errorf("Expected extra object to be removed, got %v", err)
+	}
+
+	// Replicas already in sync are left untouched
+	if report, err := storage.Resync(ctx); err != nil || report.Repaired != 0 || report.Removed != 0 {
+		t.Errorf("Expected no repairs for replicas in sync, got %+v (%v)", report, err)
+	}
+}
+
+// TestReplica_WriteQuorum verifies that writes fail without quorum, while semantic errors are not treated as failures.
+func TestReplica_WriteQuorum(t *testing.T) {
+	ctx := t.Context()
+
+	flaky := &flakyBackend{EphemeralBackend: ephemeral.NewEphemeralBackend()}
+	storage, err := replica.NewReplicaBackend([]backend.ObjectStorageBackend{ephemeral.NewEphemeralBackend(), flaky}, &replica.ReplicaBackendConfig{
+		WriteQuorum:    2,
+		ResyncInterval: -1,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create replica backend: %v", err)
+	}
+	if err := storage.Open(ctx); err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer storage.Close(ctx)
+
+	if _, err := storage.HeadObject(ctx, "", "missing.txt"); err != data.ErrNotExist {
+		t.Errorf("Expected ErrNotExist, got %v", err)
+	}
+	if err := storage.DeleteObject(ctx, "", "missing.txt", false); err != data.ErrNotExist {
+		t.Errorf("Expected ErrNotExist, got %v", err)
+	}
+	if status := storage.Status(); status[0].Degraded || status[1].Degraded {
+		t.Errorf("Expected semantic errors to keep replicas healthy, got %+v", status)
+	}
+
+	flaky.unavailable.Store(true)
+	if _, err := storage.CreateObject(ctx, "", "file.txt", 0644); !errors.Is(err, replica.ErrWriteQuorum) {
+		t.Errorf("Expected ErrWriteQuorum, got %v", err)
+	}
+	if status := storage.Status(); !status[1].Degraded || status[1].Error == "" {
+		t.Errorf("Expected failing replica to be degraded, got %+v", status[1])
+	}
+
+	// Degraded replicas no longer acknowledge writes until they have been repaired
+	flaky.unavailable.Store(false)
+	if _, err := storage.WriteObject(ctx, "", "file.txt", 0, []byte("content")); !errors.Is(err, replica.ErrWriteQuorum) {
+		t.Errorf("Expected ErrWriteQuorum for degraded replica, got %v", err)
+	}
+	if _, err := storage.Resync(ctx); err != nil {
+		t.Fatalf("Resync failed: %v", err)
+	}
+	if _, err := storage.WriteObject(ctx, "", "file.txt", 0, []byte("content")); err != nil {
+		t.Errorf("Expected write to succeed after resync, got %v", err)
+	}
+}
@@ -0,0 +1,51 @@
+package vfs
+
+import (
+	"context"
+
+	"github.com/mwantia/vfs/data"
+)
+
+// Tier moves every file of the mount containing path to the tier selected by the tier policy of the mount.
+// A nil opts moves all files.
+func (vfs *virtualFileSystemImpl) Tier(ctx context.Context, path string, opts *data.TierOptions) (*data.TierReport, error) {
+	return intercept(ctx, vfs, vfs.newOperation(OperationTier, path), func(ctx context.Context, op *Operation) (*data.TierReport, error) {
+		return vfs.tier(ctx, op.Path, opts)
+	})
+}
+
+// tier implements Tier, after the operation passed all interceptors.
+func (vfs *virtualFileSystemImpl) tier(ctx context.Context, path string, opts *data.TierOptions) (*data.TierReport, error) {
+	if opts == nil {
+		opts = &data.TierOptions{}
+	}
+
+	// Always start with an absolute path
+	absolute, err := vfs.toAbsolutePath(path)
+	if err != nil {
+		vfs.log.Error("Tier: failed to convert path to absolute: %s - %v", path, err)
+		return nil, err
+	}
+
+	vfs.log.Debug("Tier: path=%s dryrun=%v", absolute, opts.DryRun)
+
+	mnt, err := vfs.getMountFromPath(absolute)
+	if err != nil {
+		vfs.log.Error("Tier: no mount found for path: %s - %v", absolute, err)
+		return nil, err
+	}
+	if mnt.Options.IsReadOnly && !opts.DryRun {
+		vfs.log.Error("Tier: cannot move files of read-only mount at %s", mnt.Path)
+		return nil, data.ErrReadOnly
+	}
+
+	report, err := mnt.Tier(ctx, opts)
+	if err != nil {
+		vfs.log.Error("Tier: failed to apply tier policy to %s - %v", mnt.Path, err)
+		return nil, err
+	}
+
+	vfs.log.Info("Tier: checked %d file(s) in %s, promoted %d and demoted %d (%d bytes)",
+		report.Checked, mnt.Path, report.Promoted, report.Demoted, report.Bytes)
+	return report, nil
+}
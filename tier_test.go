@@ -0,0 +1,177 @@
+package vfs_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mwantia/vfs"
+	"github.com/mwantia/vfs/data"
+	"github.com/mwantia/vfs/log"
+	"github.com/mwantia/vfs/mount"
+	"github.com/mwantia/vfs/mount/backend/ephemeral"
+	"github.com/mwantia/vfs/mount/backend/tier"
+)
+
+// TestTier_Policy verifies that files are moved between tiers by access time, size and attributes and promoted on read.
+func TestTier_Policy(t *testing.T) {
+	ctx := t.Context()
+	fs, err := vfs.NewVirtualFileSystem(vfs.WithLogLevel(log.Error))
+	if err != nil {
+		t.Fatalf("Failed to initialize vfs: %v", err)
+	}
+	defer fs.Shutdown(ctx)
+
+	hot, cold := ephemeral.NewEphemeralBackend(), ephemeral.NewEphemeralBackend()
+	storage, err := tier.NewTierBackend(hot, cold)
+	if err != nil {
+		t.Fatalf("Failed to create tier backend: %v", err)
+	}
+	policy := &data.TierPolicy{
+		Rules: []data.TierRule{
+			{Tier: data.TierCold, MinIdle: 24 * time.Hour},
+			{Tier: data.TierCold, MinSize: 1024},
+			{Tier: data.TierCold, Attributes: map[string]string{"user.class": "archive"}},
+		},
+	}
+	if err := fs.Mount(ctx, "/", storage, mount.WithMetadata(ephemeral.NewEphemeralBackend()), mount.WithTierPolicy(policy)); err != nil {
+		t.Fatalf("Failed to mount /: %v", err)
+	}
+
+	if err := fs.CreateDirectory(ctx, "/docs"); err != nil {
+		t.Fatalf("CreateDirectory failed: %v", err)
+	}
+	writeTestFile(t, fs, "/docs/idle.txt", []byte("idle"))
+	writeTestFile(t, fs, "/docs/recent.txt", []byte("recent"))
+	writeTestFile(t, fs, "/large.bin", bytes.Repeat([]byte("x"), 2048))
+	writeTestFile(t, fs, "/archived.txt", []byte("archived"))
+
+	if err := fs.SetTimes(ctx, "/docs/idle.txt", time.Now().Add(-48*time.Hour), time.Time{}); err != nil {
+		t.Fatalf("SetTimes failed: %v", err)
+	}
+	if err := fs.SetAttributes(ctx, "/archived.txt", map[string]string{"user.class": "archive"}); err != nil {
+		t.Fatalf("SetAttributes failed: %v", err)
+	}
+
+	report, err := fs.Tier(ctx, "/", &data.TierOptions{DryRun: true})
+	if err != nil || report.Checked != 4 || report.Demoted != 3 || report.Bytes != 2048+4+8 {
+		t.Errorf("Unexpected dry run report: %+v (%v)", report, err)
+	}
+	if _, err := cold.HeadObject(ctx, "", "docs/idle.txt"); err != data.ErrNotExist {
+		t.Errorf("Expected dry run to keep files on hot tier, got %v", err)
+	}
+
+	if report, err := fs.Tier(ctx, "/", nil); err != nil || report.Demoted != 3 || report.Promoted != 0 {
+		t.Fatalf("Unexpected tier report: %+v (%v)", report, err)
+	}
+
+	expected := map[string]data.StorageTier{
+		"docs/idle.txt":   data.TierCold,
+		"docs/recent.txt": data.TierHot,
+		"large.bin":       data.TierCold,
+		"archived.txt":    data.TierCold,
+	}
+	for key, expectedTier := range expected {
+		if current, err := storage.GetTier(ctx, "", key); err != nil || current != expectedTier {
+			t.Errorf("Expected %s on %s tier, got %s (%v)", key, expectedTier, current, err)
+		}
+		meta, err := fs.StatMetadata(ctx, "/"+key)
+		if err != nil || meta.GetAttribute(data.AttributeTier, "") != string(expectedTier) {
+			t.Errorf("Expected tier attribute %s for %s, got %+v (%v)", expectedTier, key, meta, err)
+		}
+	}
+	if _, err := hot.HeadObject(ctx, "", "docs/idle.txt"); err != data.ErrNotExist {
+		t.Errorf("Expected demoted file to be removed from hot tier, got %v", err)
+	}
+
+	// Reading an idle file promotes it, while large files remain on the cold tier
+	if result, err := fs.ReadFile(ctx, "/docs/idle.txt", 0, 4); err != nil || string(result) != "idle" {
+		t.Fatalf("Unexpected content of demoted file: %q (%v)", result, err)
+	}
+	if result, err := fs.ReadFile(ctx, "/large.bin", 0, 4); err != nil || string(result) != "xxxx" {
+		t.Fatalf("Unexpected content of large file: %q (%v)", result, err)
+	}
+	if current, _ := storage.GetTier(ctx, "", "docs/idle.txt"); current != data.TierHot {
+		t.Errorf("Expected idle file to be promoted on read, got %s", current)
+	}
+	if current, _ := storage.GetTier(ctx, "", "large.bin"); current != data.TierCold {
+		t.Errorf("Expected large file to remain on cold tier, got %s", current)
+	}
+	if meta, err := fs.StatMetadata(ctx, "/docs/idle.txt"); err != nil || meta.GetAttribute(data.AttributeTier, "") != string(data.TierHot) || time.Since(meta.AccessTime) > time.Minute {
+		t.Errorf("Expected promoted file to be accessed on hot tier, got %+v (%v)", meta, err)
+	}
+
+	// Files are only moved once
+	var buffer bytes.Buffer
+	if code, err := fs.Execute(ctx, &buffer, "tier", "/"); err != nil || code != 0 {
+		t.Fatalf("tier failed with %d: %v", code, err)
+	}
+	if output := buffer.String(); !strings.Contains(output, "checked 4 file(s), moved 0 to hot and 0 to cold tier") {
+		t.Errorf("Unexpected tier output: %q", output)
+	}
+
+	entries, err := fs.ReadDirectory(ctx, "/docs")
+	if err != nil || len(entries) != 2 {
+		t.Errorf("Expected both files listed across tiers, got %d (%v)", len(entries), err)
+	}
+}
+
+// TestTier_RequiresTieringBackend verifies that tier policies are rejected for backends without tiers.
+func TestTier_RequiresTieringBackend(t *testing.T) {
+	ctx := t.Context()
+	fs, err := vfs.NewVirtualFileSystem(vfs.WithLogLevel(log.Error))
+	if err != nil {
+		t.Fatalf("Failed to initialize vfs: %v", err)
+	}
+	defer fs.Shutdown(ctx)
+
+	storage := ephemeral.NewEphemeralBackend()
+	if err := fs.Mount(ctx, "/", storage, mount.WithMetadata(storage), mount.WithTierPolicy(&data.TierPolicy{})); err == nil {
+		t.Error("Expected mount with tier policy to fail without tiering backend")
+	}
+	if err := fs.Mount(ctx, "/", storage, mount.WithTierPolicy(&data.TierPolicy{Default: "warm"})); err == nil {
+		t.Error("Expected mount with unknown tier to fail")
+	}
+}
+
+// TestTier_ReadRefreshesAccessTime verifies that reading an idle file records its access, so it is not demoted afterwards.
+func TestTier_ReadRefreshesAccessTime(t *testing.T) {
+	ctx := t.Context()
+	fs, err := vfs.NewVirtualFileSystem(vfs.WithLogLevel(log.Error))
+	if err != nil {
+		t.Fatalf("Failed to initialize vfs: %v", err)
+	}
+	defer fs.Shutdown(ctx)
+
+	storage, err := tier.NewTierBackend(ephemeral.NewEphemeralBackend(), ephemeral.NewEphemeralBackend())
+	if err != nil {
+		t.Fatalf("Failed to create tier backend: %v", err)
+	}
+	policy := &data.TierPolicy{
+		Rules: []data.TierRule{
+			{Tier: data.TierCold, MinIdle: time.Hour},
+		},
+	}
+	if err := fs.Mount(ctx, "/", storage, mount.WithMetadata(ephemeral.NewEphemeralBackend()), mount.WithTierPolicy(policy)); err != nil {
+		t.Fatalf("Failed to mount /: %v", err)
+	}
+
+	writeTestFile(t, fs, "/idle.txt", []byte("idle"))
+	if _, err := fs.Tier(ctx, "/", nil); err != nil {
+		t.Fatalf("Tier failed: %v", err)
+	}
+	if err := fs.SetTimes(ctx, "/idle.txt", time.Now().Add(-2*time.Hour), time.Time{}); err != nil {
+		t.Fatalf("SetTimes failed: %v", err)
+	}
+	if result, err := fs.ReadFile(ctx, "/idle.txt", 0, 4); err != nil || string(result) != "idle" {
+		t.Fatalf("Unexpected content: %q (%v)", result, err)
+	}
+
+	if report, err := fs.Tier(ctx, "/", nil); err != nil || report.Checked != 1 || report.Demoted != 0 {
+		t.Errorf("Expected recently read file to remain on hot tier, got %+v (%v)", report, err)
+	}
+	if current, err := storage.GetTier(ctx, "", "idle.txt"); err != nil || current != data.TierHot {
+		t.Errorf("Expected file on hot tier, got %s (%v)", current, err)
+	}
+}
@@ -0,0 +1,729 @@
+package vfs
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"maps"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mwantia/vfs/data"
+	"github.com/mwantia/vfs/data/errors"
+	"github.com/mwantia/vfs/mount"
+	"github.com/mwantia/vfs/mount/backend"
+)
+
+// transactionImpl stages all changes of a transaction in memory until they are committed.
+// Nothing is written to the backends before Commit, so concurrent readers never observe partial state.
+type transactionImpl struct {
+	mu      sync.Mutex
+	vfs     *virtualFileSystemImpl
+	mnt     *mount.Mount
+	entries map[string]*transactionEntry // Staged entries keyed by mount-relative key
+	done    bool
+}
+
+// transactionEntry describes the staged state of a single key.
+type transactionEntry struct {
+	base      *data.Metadata // Metadata before the transaction (nil if the key didn't exist)
+	meta      *data.Metadata // Staged metadata (nil if the key has been removed)
+	content   []byte         // Complete staged content (only valid if written)
+	written   bool           // Content has been modified
+	recreated bool           // Key has been removed and created again
+}
+
+// Begin starts a new transaction for the mount at mountPath.
+// All changes are staged until Commit applies them atomically, so readers never observe partial state.
+// Returns data.ErrNotSupported if the backends of the mount can't apply changes atomically.
+func (vfs *virtualFileSystemImpl) Begin(ctx context.Context, mountPath string) (Transaction, error) {
+	return intercept(ctx, vfs, vfs.newOperation(OperationBegin, mountPath), func(ctx context.Context, op *Operation) (Transaction, error) {
+		return vfs.begin(ctx, op.Path)
+	})
+}
+
+// begin implements Begin, after the operation passed all interceptors.
+func (vfs *virtualFileSystemImpl) begin(ctx context.Context, mountPath string) (Transaction, error) {
+	// Always start with an absolute path
+	absolute, err := vfs.toAbsolutePath(mountPath)
+	if err != nil {
+		vfs.log.Error("Begin: failed to convert path to absolute: %s - %v", mountPath, err)
+		return nil, err
+	}
+
+	vfs.log.Debug("Begin: path=%s", absolute)
+
+	vfs.mu.RLock()
+	mnt, exists := vfs.mnts[absolute]
+	vfs.mu.RUnlock()
+
+	if !exists {
+		vfs.log.Error("Begin: no mount found at %s", absolute)
+		return nil, data.ErrNotExist
+	}
+	// Fail if mount is readonly
+	if mnt.Options.IsReadOnly {
+		vfs.log.Error("Begin: cannot start transaction on read-only mount at %s", mnt.Path)
+		return nil, data.ErrReadOnly
+	}
+	if !mnt.CanTransact() {
+		vfs.log.Error("Begin: mount at %s doesn't support transactions", mnt.Path)
+		return nil, data.ErrNotSupported
+	}
+
+	vfs.log.Info("Begin: started transaction for %s", absolute)
+	return &transactionImpl{
+		vfs:     vfs,
+		mnt:     mnt,
+		entries: make(map[string]*transactionEntry),
+	}, nil
+}
+
+// Path returns the path of the mount this transaction belongs to.
+func (tx *transactionImpl) Path() string {
+	return tx.mnt.Path
+}
+
+// StatMetadata returns the staged file information for the given path.
+func (tx *transactionImpl) StatMetadata(ctx context.Context, path string) (*data.Metadata, error) {
+	return intercept(ctx, tx.vfs, tx.vfs.newOperation(OperationStatMetadata, path), func(ctx context.Context, op *Operation) (*data.Metadata, error) {
+		tx.mu.Lock()
+		defer tx.mu.Unlock()
+
+		absolute, relative, err := tx.resolve("StatMetadata", op.Path)
+		if err != nil {
+			return nil, err
+		}
+
+		return tx.lookup(ctx, absolute, relative)
+	})
+}
+
+// ReadFile reads size bytes from the staged file at path starting at offset.
+func (tx *transactionImpl) ReadFile(ctx context.Context, path string, offset, size int64) ([]byte, error) {
+	return intercept(ctx, tx.vfs, tx.vfs.newOperation(OperationReadFile, path), func(ctx context.Context, op *Operation) ([]byte, error) {
+		tx.mu.Lock()
+		defer tx.mu.Unlock()
+
+		absolute, relative, err := tx.resolve("ReadFile", op.Path)
+		if err != nil {
+			return nil, err
+		}
+
+		entry, staged := tx.entries[relative]
+		if !staged || (entry.meta != nil && !entry.written && !entry.recreated) {
+			return tx.vfs.readFile(ctx, absolute, offset, size)
+		}
+		if entry.meta == nil {
+			return nil, data.ErrNotExist
+		}
+		if entry.meta.Mode.IsDir() {
+			return nil, data.ErrIsDirectory
+		}
+		if size <= 0 || offset < 0 || offset+size > int64(len(entry.content)) {
+			tx.vfs.log.Error("ReadFile: read range exceeds staged file size for %s (offset=%d size=%d filesize=%d)", absolute, offset, size, len(entry.content))
+			return nil, fmt.Errorf("vfs: size out of range")
+		}
+
+		return bytes.Clone(entry.content[offset : offset+size]), nil
+	})
+}
+
+// WriteFile stages writing data to the file at path starting at offset.
+// The file is created, if it doesn't exist yet.
+func (tx *transactionImpl) WriteFile(ctx context.Context, path string, offset int64, buffer []byte) (int, error) {
+	return intercept(ctx, tx.vfs, tx.vfs.newOperation(OperationWriteFile, path), func(ctx context.Context, op *Operation) (int, error) {
+		tx.mu.Lock()
+		defer tx.mu.Unlock()
+
+		absolute, relative, err := tx.resolve("WriteFile", op.Path)
+		if err != nil {
+			return 0, err
+		}
+		if offset < 0 {
+			return 0, data.ErrInvalid
+		}
+
+		entry, err := tx.entry(ctx, absolute, relative)
+		if err != nil {
+			return 0, err
+		}
+		if entry.meta == nil {
+			if err := tx.checkParent(ctx, absolute, relative); err != nil {
+				return 0, err
+			}
+			tx.create(entry, data.NewFileMetadata(relative, 0, 0644))
+		}
+		if entry.meta.Mode.IsDir() {
+			tx.vfs.log.Error("WriteFile: cannot write to directory %s", absolute)
+			return 0, data.ErrIsDirectory
+		}
+		if err := tx.load(ctx, absolute, entry); err != nil {
+			return 0, err
+		}
+
+		size := max(offset+int64(len(buffer)), int64(len(entry.content)))
+		if err := tx.vfs.validateObjectSize(tx.mnt, size); err != nil {
+			return 0, err
+		}
+		if size > int64(len(entry.content)) {
+			entry.content = append(entry.content, make([]byte, size-int64(len(entry.content)))...)
+		}
+
+		n := copy(entry.content[offset:], buffer)
+		entry.meta.Size = size
+		entry.meta.ModifyTime = time.Now()
+
+		tx.vfs.log.Debug("WriteFile: staged %d bytes for %s at offset %d", n, absolute, offset)
+		return n, nil
+	})
+}
+
+// ReadDirectory returns the staged entries of the directory at path.
+func (tx *transactionImpl) ReadDirectory(ctx context.Context, path string) ([]*data.Metadata, error) {
+	return intercept(ctx, tx.vfs, tx.vfs.newOperation(OperationReadDirectory, path), func(ctx context.Context, op *Operation) ([]*data.Metadata, error) {
+		tx.mu.Lock()
+		defer tx.mu.Unlock()
+
+		absolute, relative, err := tx.resolve("ReadDirectory", op.Path)
+		if err != nil {
+			return nil, err
+		}
+
+		return tx.readDirectory(ctx, absolute, relative)
+	})
+}
+
+// CreateDirectory stages creating a new directory at the specified path.
+func (tx *transactionImpl) CreateDirectory(ctx context.Context, path string) error {
+	return tx.vfs.interceptError(ctx, tx.vfs.newOperation(OperationCreateDirectory, path), func(ctx context.Context, op *Operation) error {
+		tx.mu.Lock()
+		defer tx.mu.Unlock()
+
+		absolute, relative, err := tx.resolve("CreateDirectory", op.Path)
+		if err != nil {
+			return err
+		}
+
+		entry, err := tx.entry(ctx, absolute, relative)
+		if err != nil {
+			return err
+		}
+		if entry.meta != nil {
+			tx.vfs.log.Error("CreateDirectory: %s already exists", absolute)
+			return data.ErrExist
+		}
+		if err := tx.checkParent(ctx, absolute, relative); err != nil {
+			return err
+		}
+
+		tx.create(entry, data.NewDirectoryMetadata(relative, data.ModeDir|0755))
+		tx.vfs.log.Debug("CreateDirectory: staged directory %s", absolute)
+		return nil
+	})
+}
+
+// RemoveDirectory stages removing the empty directory at the specified path.
+func (tx *transactionImpl) RemoveDirectory(ctx context.Context, path string) error {
+	return tx.vfs.interceptError(ctx, tx.vfs.newOperation(OperationRemoveDirectory, path), func(ctx context.Context, op *Operation) error {
+		tx.mu.Lock()
+		defer tx.mu.Unlock()
+
+		absolute, relative, err := tx.resolve("RemoveDirectory", op.Path)
+		if err != nil {
+			return err
+		}
+
+		entry, err := tx.entry(ctx, absolute, relative)
+		if err != nil {
+			return err
+		}
+		if entry.meta == nil {
+			return data.ErrNotExist
+		}
+		if !entry.meta.Mode.IsDir() {
+			return data.ErrNotDirectory
+		}
+
+		children, err := tx.readDirectory(ctx, absolute, relative)
+		if err != nil {
+			return err
+		}
+		if len(children) > 0 {
+			tx.vfs.log.Error("RemoveDirectory: directory %s is not empty", absolute)
+			return data.ErrDirectoryNotEmpty
+		}
+
+		tx.remove(entry)
+		tx.vfs.log.Debug("RemoveDirectory: staged removal of %s", absolute)
+		return nil
+	})
+}
+
+// UnlinkFile stages removing the file at the specified path.
+func (tx *transactionImpl) UnlinkFile(ctx context.Context, path string) error {
+	return tx.vfs.interceptError(ctx, tx.vfs.newOperation(OperationUnlinkFile, path), func(ctx context.Context, op *Operation) error {
+		tx.mu.Lock()
+		defer tx.mu.Unlock()
+
+		absolute, relative, err := tx.resolve("UnlinkFile", op.Path)
+		if err != nil {
+			return err
+		}
+
+		entry, err := tx.entry(ctx, absolute, relative)
+		if err != nil {
+			return err
+		}
+		if entry.meta == nil {
+			return data.ErrNotExist
+		}
+		if entry.meta.Mode.IsDir() {
+			return data.ErrIsDirectory
+		}
+
+		tx.remove(entry)
+		tx.vfs.log.Debug("UnlinkFile: staged removal of %s", absolute)
+		return nil
+	})
+}
+
+// Chmod stages changing the permission bits of the file or directory at path.
+func (tx *transactionImpl) Chmod(ctx context.Context, path string, mode data.FileMode) error {
+	return tx.vfs.interceptError(ctx, tx.vfs.newOperation(OperationChmod, path), func(ctx context.Context, op *Operation) error {
+		return tx.updateMetadata(ctx, "Chmod", op.Path, func(meta *data.Metadata) {
+			meta.Mode = (meta.Mode &^ data.ModePerm) | mode.Perm()
+		})
+	})
+}
+
+// SetAttributes stages merging the specified attributes into the existing attributes at path.
+func (tx *transactionImpl) SetAttributes(ctx context.Context, path string, attributes map[string]string) error {
+	return tx.vfs.interceptError(ctx, tx.vfs.newOperation(OperationSetAttributes, path), func(ctx context.Context, op *Operation) error {
+		for key := range attributes {
+			if key == "" {
+				return errors.AttributeInvalid(nil, key)
+			}
+		}
+
+		return tx.updateMetadata(ctx, "SetAttributes", op.Path, func(meta *data.Metadata) {
+			merged := make(map[string]string, len(meta.Attributes)+len(attributes))
+			maps.Copy(merged, meta.Attributes)
+			maps.Copy(merged, attributes)
+			meta.Attributes = merged
+		})
+	})
+}
+
+// Commit applies all staged changes atomically and ends the transaction.
+// If applying fails, none of the changes are visible and the transaction is ended as well.
+func (tx *transactionImpl) Commit(ctx context.Context) error {
+	return tx.vfs.interceptError(ctx, tx.vfs.newOperation(OperationCommit, tx.mnt.Path), func(ctx context.Context, op *Operation) error {
+		tx.mu.Lock()
+		defer tx.mu.Unlock()
+
+		if tx.done {
+			return data.ErrTransactionDone
+		}
+		tx.done = true
+
+		tx.vfs.mu.RLock()
+		current, mounted := tx.vfs.mnts[tx.mnt.Path]
+		tx.vfs.mu.RUnlock()
+
+		if !mounted || current != tx.mnt {
+			tx.vfs.log.Error("Commit: mount at %s has been unmounted", tx.mnt.Path)
+			return data.ErrNotExist
+		}
+
+		changes := tx.changes()
+		if len(changes) == 0 {
+			tx.vfs.log.Info("Commit: no changes staged for %s", tx.mnt.Path)
+			return nil
+		}
+
+		usage := tx.usage()
+		if err := tx.mnt.ChargeQuota(usage...); err != nil {
+			tx.vfs.log.Error("Commit: quota validation failed for %s - %v", tx.mnt.Path, err)
+			return err
+		}
+
+		if err := tx.mnt.ApplyChanges(ctx, changes); err != nil {
+			tx.mnt.RefundQuota(usage...)
+			if err == data.ErrTransactionConflict {
+				tx.vfs.log.Error("Commit: staged keys of %s have been changed outside of the transaction", tx.mnt.Path)
+				return err
+			}
+			tx.vfs.log.Error("Commit: failed to apply %d change(s) to %s - %v", len(changes), tx.mnt.Path, err)
+			return err
+		}
+
+		tx.notify(changes)
+
+		tx.vfs.log.Info("Commit: successfully applied %d change(s) to %s", len(changes), tx.mnt.Path)
+		return nil
+	})
+}
+
+// Rollback discards all staged changes and ends the transaction.
+// Returns data.ErrTransactionDone if the transaction has already been ended.
+func (tx *transactionImpl) Rollback(ctx context.Context) error {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+
+	if tx.done {
+		return data.ErrTransactionDone
+	}
+
+	tx.done = true
+	tx.entries = nil
+
+	tx.vfs.log.Info("Rollback: discarded transaction for %s", tx.mnt.Path)
+	return nil
+}
+
+// resolve converts path into its absolute path and mount-relative key.
+// Paths outside of the transaction mount, including nested mounts, are rejected.
+func (tx *transactionImpl) resolve(op string, path string) (string, string, error) {
+	if tx.done {
+		return "", "", data.ErrTransactionDone
+	}
+
+	absolute, err := tx.vfs.toAbsolutePath(path)
+	if err != nil {
+		tx.vfs.log.Error("%s: failed to convert path to absolute: %s - %v", op, path, err)
+		return "", "", err
+	}
+
+	mnt, err := tx.vfs.getMountFromPath(absolute)
+	if err != nil {
+		tx.vfs.log.Error("%s: no mount found for path: %s - %v", op, absolute, err)
+		return "", "", err
+	}
+	if mnt != tx.mnt {
+		tx.vfs.log.Error("%s: path %s is outside of the transaction mount at %s", op, absolute, tx.mnt.Path)
+		return "", "", data.ErrCrossMount
+	}
+
+	return absolute, tx.vfs.getPrefixRelativePath(mnt, absolute), nil
+}
+
+// lookup returns the staged metadata at relative, falling back to the committed metadata.
+func (tx *transactionImpl) lookup(ctx context.Context, absolute, relative string) (*data.Metadata, error) {
+	if entry, staged := tx.entries[relative]; staged {
+		if entry.meta == nil {
+			return nil, data.ErrNotExist
+		}
+		return entry.meta.Clone(), nil
+	}
+
+	return tx.vfs.statMetadata(ctx, absolute)
+}
+
+// entry returns the staged entry at relative, creating it from the committed metadata if required.
+func (tx *transactionImpl) entry(ctx context.Context, absolute, relative string) (*transactionEntry, error) {
+	if relative == "" {
+		// The mount root can't be modified
+		return nil, data.ErrInvalid
+	}
+	if entry, staged := tx.entries[relative]; staged {
+		return entry, nil
+	}
+
+	base, err := tx.vfs.statMetadata(ctx, absolute)
+	if err != nil && err != data.ErrNotExist {
+		return nil, err
+	}
+
+	entry := &transactionEntry{}
+	if base != nil {
+		// Backends may return their stored metadata, which is modified by changes outside of the transaction
+		entry.base = base.Clone()
+		entry.meta = base.Clone()
+	}
+
+	tx.entries[relative] = entry
+	return entry, nil
+}
+
+// checkParent verifies that the staged parent of relative exists and is a directory.
+func (tx *transactionImpl) checkParent(ctx context.Context, absolute, relative string) error {
+	parent := parentKey(relative)
+	if parent == "" {
+		return nil
+	}
+
+	meta, err := tx.lookup(ctx, path.Dir(absolute), parent)
+	if err != nil {
+		return err
+	}
+	if !meta.Mode.IsDir() {
+		return data.ErrNotDirectory
+	}
+
+	return nil
+}
+
+// create stages meta as a new key for entry.
+func (tx *transactionImpl) create(entry *transactionEntry, meta *data.Metadata) {
+	entry.meta = meta
+	entry.content = []byte{}
+	entry.written = !meta.Mode.IsDir()
+	entry.recreated = entry.base != nil
+}
+
+// remove stages removing the key of entry.
+func (tx *transactionImpl) remove(entry *transactionEntry) {
+	entry.meta = nil
+	entry.content = nil
+	entry.written = false
+	entry.recreated = false
+}
+
+// load reads the committed content of entry, so it can be modified.
+func (tx *transactionImpl) load(ctx context.Context, absolute string, entry *transactionEntry) error {
+	if entry.written || entry.recreated {
+		return nil
+	}
+
+	entry.content = []byte{}
+	if entry.base.Size > 0 {
+		content, err := tx.vfs.readFile(ctx, absolute, 0, entry.base.Size)
+		if err != nil {
+			return err
+		}
+		entry.content = content
+	}
+
+	entry.written = true
+	return nil
+}
+
+// readDirectory merges the committed entries of the directory at relative with all staged entries.
+func (tx *transactionImpl) readDirectory(ctx context.Context, absolute, relative string) ([]*data.Metadata, error) {
+	meta, err := tx.lookup(ctx, absolute, relative)
+	if err != nil {
+		return nil, err
+	}
+	if !meta.Mode.IsDir() {
+		return nil, data.ErrNotDirectory
+	}
+
+	children := make(map[string]*data.Metadata)
+	// Recreated directories don't contain any of the committed entries
+	if entry, staged := tx.entries[relative]; !staged || !entry.recreated {
+		metas, err := tx.vfs.readDirectory(ctx, absolute)
+		if err != nil {
+			return nil, err
+		}
+		for _, meta := range metas {
+			children[path.Join(relative, path.Base(meta.Key))] = meta
+		}
+	}
+
+	for key, entry := range tx.entries {
+		if key == "" || parentKey(key) != relative {
+			continue
+		}
+		if entry.meta == nil {
+			delete(children, key)
+			continue
+		}
+
+		meta := entry.meta.Clone()
+		meta.Key = path.Base(key)
+		children[key] = meta
+	}
+
+	metas := make([]*data.Metadata, 0, len(children))
+	for _, meta := range children {
+		metas = append(metas, meta)
+	}
+	sort.Slice(metas, func(i, j int) bool {
+		return metas[i].Key < metas[j].Key
+	})
+
+	return metas, nil
+}
+
+// updateMetadata applies update to the staged metadata at path.
+func (tx *transactionImpl) updateMetadata(ctx context.Context, op string, path string, update func(meta *data.Metadata)) error {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+
+	absolute, relative, err := tx.resolve(op, path)
+	if err != nil {
+		return err
+	}
+	// Metadata can only be persisted with a metadata backend
+	if tx.mnt.Metadata == nil {
+		tx.vfs.log.Error("%s: mount at %s has no metadata backend", op, tx.mnt.Path)
+		return errors.BackendUnsupported(nil, tx.mnt.ObjectStorage.Name())
+	}
+
+	entry, err := tx.entry(ctx, absolute, relative)
+	if err != nil {
+		return err
+	}
+	if entry.meta == nil {
+		return data.ErrNotExist
+	}
+
+	update(entry.meta)
+	tx.vfs.log.Debug("%s: staged metadata update for %s", op, absolute)
+	return nil
+}
+
+// changes converts all staged entries into the ordered list of changes applied by the backend.
+// Removals are applied deepest first, followed by new directories, written files and metadata updates.
+func (tx *transactionImpl) changes() []*backend.Change {
+	keys := make([]string, 0, len(tx.entries))
+	for key := range tx.entries {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var deletes, mkdirs, writes, updates []*backend.Change
+	for _, key := range keys {
+		entry := tx.entries[key]
+		// Changes are based on the state read while staging, so the backend rejects them if the key changed since
+		base := &backend.ChangeBase{Metadata: entry.base}
+
+		if entry.base != nil && (entry.meta == nil || entry.recreated) {
+			deletes = append(deletes, &backend.Change{Type: backend.ChangeDelete, Key: key, Base: base})
+		}
+		if entry.meta == nil {
+			continue
+		}
+
+		meta := entry.meta.Clone()
+		meta.Key = key
+
+		switch {
+		case meta.Mode.IsDir() && (entry.base == nil || entry.recreated):
+			mkdirs = append(mkdirs, &backend.Change{Type: backend.ChangeMkdir, Key: key, Metadata: meta, Base: base})
+		case !meta.Mode.IsDir() && entry.written:
+			writes = append(writes, &backend.Change{Type: backend.ChangeWrite, Key: key, Metadata: meta, Content: entry.content, Base: base})
+		}
+
+		if update := tx.metadataUpdate(entry, meta); update != nil {
+			updates = append(updates, &backend.Change{Type: backend.ChangeUpdate, Key: key, Update: update, Base: base})
+		}
+	}
+
+	// Nested keys have to be removed before their parents
+	sort.SliceStable(deletes, func(i, j int) bool {
+		return strings.Count(deletes[i].Key, "/") > strings.Count(deletes[j].Key, "/")
+	})
+
+	changes := make([]*backend.Change, 0, len(deletes)+len(mkdirs)+len(writes)+len(updates))
+	changes = append(changes, deletes...)
+	changes = append(changes, mkdirs...)
+	changes = append(changes, writes...)
+	changes = append(changes, updates...)
+
+	return changes
+}
+
+// usage returns the change of storage used by all staged entries.
+// Replaced entries release their previous usage, while staged entries are charged with their final size.
+func (tx *transactionImpl) usage() []mount.QuotaDelta {
+	var deltas []mount.QuotaDelta
+	for _, entry := range tx.entries {
+		if entry.base != nil && (entry.meta == nil || entry.recreated || entry.written) {
+			deltas = append(deltas, mount.QuotaDelta{UID: entry.base.UID, Bytes: -entry.base.Size, Objects: -1})
+		}
+		if entry.meta == nil || (entry.base != nil && !entry.recreated && !entry.written) {
+			continue
+		}
+
+		size := entry.meta.Size
+		if entry.written {
+			size = int64(len(entry.content))
+		}
+		deltas = append(deltas, mount.QuotaDelta{UID: entry.meta.UID, Bytes: size, Objects: 1})
+	}
+
+	return deltas
+}
+
+// metadataUpdate returns the update required for entry after its content has been applied.
+// Existing keys receive their staged mode and attributes, while written files receive their new checksum.
+func (tx *transactionImpl) metadataUpdate(entry *transactionEntry, meta *data.Metadata) *data.MetadataUpdate {
+	if tx.mnt.Metadata == nil {
+		return nil
+	}
+
+	update := &data.MetadataUpdate{
+		Metadata: &data.Metadata{
+			Mode:       meta.Mode,
+			Attributes: maps.Clone(meta.Attributes),
+		},
+	}
+
+	if entry.base != nil && !entry.recreated {
+		if meta.Mode != entry.base.Mode {
+			update.Mask |= data.MetadataUpdateMode
+		}
+		if !maps.Equal(meta.Attributes, entry.base.Attributes) {
+			update.Mask |= data.MetadataUpdateAttributes
+		}
+	}
+
+	if entry.written && tx.mnt.Options.Checksum != "" {
+		checksum, err := data.ComputeChecksum(bytes.NewReader(entry.content), tx.mnt.Options.Checksum)
+		if err == nil {
+			if update.Metadata.Attributes == nil {
+				update.Metadata.Attributes = make(map[string]string)
+			}
+			update.Metadata.Attributes[data.AttributeChecksum] = checksum.String()
+			update.Mask |= data.MetadataUpdateStorageHash
+		}
+	}
+
+	if update.Mask == 0 {
+		return nil
+	}
+
+	return update
+}
+
+// notify emits the events of all applied changes.
+func (tx *transactionImpl) notify(changes []*backend.Change) {
+	for _, change := range changes {
+		absolute, _ := tx.mnt.ToAbsolutePath(change.Key)
+		entry := tx.entries[change.Key]
+
+		switch change.Type {
+		case backend.ChangeDelete:
+			if entry.base.Mode.IsDir() {
+				tx.mnt.Notify(data.EventRmdir, absolute, "")
+			} else {
+				tx.mnt.Notify(data.EventUnlink, absolute, "")
+			}
+		case backend.ChangeMkdir:
+			tx.mnt.Notify(data.EventMkdir, absolute, "")
+		case backend.ChangeWrite:
+			if entry.base == nil || entry.recreated {
+				tx.mnt.Notify(data.EventCreate, absolute, "")
+			}
+			tx.mnt.Notify(data.EventWrite, absolute, "")
+		case backend.ChangeUpdate:
+			if change.Update.Mask&^data.MetadataUpdateStorageHash != 0 {
+				tx.mnt.Notify(data.EventAttrib, absolute, "")
+			}
+		}
+	}
+}
+
+// parentKey returns the mount-relative key of the parent of key.
+func parentKey(key string) string {
+	if parent := path.Dir(key); parent != "." {
+		return parent
+	}
+
+	return ""
+}
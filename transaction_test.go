@@ -0,0 +1,344 @@
+package vfs_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/mwantia/vfs"
+	"github.com/mwantia/vfs/data"
+	"github.com/mwantia/vfs/log"
+	"github.com/mwantia/vfs/mount"
+	"github.com/mwantia/vfs/mount/backend"
+	"github.com/mwantia/vfs/mount/backend/direct"
+	"github.com/mwantia/vfs/mount/backend/ephemeral"
+	"github.com/mwantia/vfs/mount/backend/sqlite"
+)
+
+// TestAllMounts_Transactions verifies isolation, commit, rollback and atomic failures of transactions across all backend implementations.
+func TestAllMounts_Transactions(t *testing.T) {
+	factories := GetTestMountFactories()
+	// Separate metadata backends can't be committed atomically
+	unsupported := map[string]bool{
+		"sqlite-metadata": true,
+		"direct-metadata": true,
+	}
+
+	for name, factory := range factories {
+		t.Run(name, func(tst *testing.T) {
+			ctx := tst.Context()
+			fs, err := vfs.NewVirtualFileSystem(vfs.WithLogLevel(log.Error))
+			if err != nil {
+				tst.Fatalf("Failed to initialize vfs: %v", err)
+			}
+
+			if err := factory(tst, fs); err != nil {
+				tst.Fatalf("Failed to mount: %v", err)
+			}
+			defer fs.Unmount(ctx, "/", false)
+
+			if unsupported[name] {
+				if _, err := fs.Begin(ctx, "/"); !errors.Is(err, data.ErrNotSupported) {
+					tst.Fatalf("Expected ErrNotSupported, got %v", err)
+				}
+				return
+			}
+
+			writeTestFile(tst, fs, "/existing.txt", []byte("old"))
+			if err := fs.CreateDirectory(ctx, "/old"); err != nil {
+				tst.Fatalf("MkDir /old failed: %v", err)
+			}
+			writeTestFile(tst, fs, "/old/file.txt", []byte("remove"))
+
+			tx, err := fs.Begin(ctx, "/")
+			if err != nil {
+				tst.Fatalf("Begin failed: %v", err)
+			}
+
+			if err := tx.CreateDirectory(ctx, "/manifest"); err != nil {
+				tst.Fatalf("Tx MkDir /manifest failed: %v", err)
+			}
+			if _, err := tx.WriteFile(ctx, "/manifest/blob", 0, []byte("blob")); err != nil {
+				tst.Fatalf("Tx Write /manifest/blob failed: %v", err)
+			}
+			if _, err := tx.WriteFile(ctx, "/manifest/index", 0, []byte("index")); err != nil {
+				tst.Fatalf("Tx Write /manifest/index failed: %v", err)
+			}
+			if _, err := tx.WriteFile(ctx, "/existing.txt", 1, []byte("NEW")); err != nil {
+				tst.Fatalf("Tx Write /existing.txt failed: %v", err)
+			}
+			if err := tx.RemoveDirectory(ctx, "/old"); !errors.Is(err, data.ErrDirectoryNotEmpty) {
+				tst.Errorf("Expected ErrDirectoryNotEmpty for /old, got %v", err)
+			}
+			if err := tx.UnlinkFile(ctx, "/old/file.txt"); err != nil {
+				tst.Fatalf("Tx Unlink /old/file.txt failed: %v", err)
+			}
+			if err := tx.RemoveDirectory(ctx, "/old"); err != nil {
+				tst.Fatalf("Tx RmDir /old failed: %v", err)
+			}
+
+			// The transaction observes its own changes
+			got, err := tx.ReadFile(ctx, "/existing.txt", 0, 4)
+			if err != nil || string(got) != "oNEW" {
+				tst.Errorf("Expected staged content 'oNEW', got '%s': %v", got, err)
+			}
+			entries, err := tx.ReadDirectory(ctx, "/")
+			if err != nil {
+				tst.Fatalf("Tx ReadDirectory / failed: %v", err)
+			}
+			names := make([]string, 0, len(entries))
+			for _, entry := range entries {
+				names = append(names, entry.Key)
+			}
+			if len(names) != 2 || names[0] != "existing.txt" || names[1] != "manifest" {
+				tst.Errorf("Expected staged entries [existing.txt manifest], got %v", names)
+			}
+
+			// Readers outside of the transaction only observe committed state
+			if _, err := fs.StatMetadata(ctx, "/manifest"); !errors.Is(err, data.ErrNotExist) {
+				tst.Errorf("Expected /manifest to be invisible before commit, got %v", err)
+			}
+			got, err = fs.ReadFile(ctx, "/existing.txt", 0, 3)
+			if err != nil || string(got) != "old" {
+				tst.Errorf("Expected committed content 'old', got '%s': %v", got, err)
+			}
+			if _, err := fs.StatMetadata(ctx, "/old/file.txt"); err != nil {
+				tst.Errorf("Expected /old/file.txt to remain before commit: %v", err)
+			}
+
+			if err := tx.Commit(ctx); err != nil {
+				tst.Fatalf("Commit failed: %v", err)
+			}
+			if err := tx.Commit(ctx); !errors.Is(err, data.ErrTransactionDone) {
+				tst.Errorf("Expected ErrTransactionDone for second commit, got %v", err)
+			}
+
+			for path, expected := range map[string]string{
+				"/manifest/blob":  "blob",
+				"/manifest/index": "index",
+				"/existing.txt":   "oNEW",
+			} {
+				got, err := fs.ReadFile(ctx, path, 0, int64(len(expected)))
+				if err != nil || string(got) != expected {
+					tst.Errorf("Expected '%s' in %s after commit, got '%s': %v", expected, path, got, err)
+				}
+			}
+			if _, err := fs.StatMetadata(ctx, "/old"); !errors.Is(err, data.ErrNotExist) {
+				tst.Errorf("Expected /old to be removed after commit, got %v", err)
+			}
+
+			// Rolled back changes are discarded
+			tx, err = fs.Begin(ctx, "/")
+			if err != nil {
+				tst.Fatalf("Begin failed: %v", err)
+			}
+			if _, err := tx.WriteFile(ctx, "/discard.txt", 0, []byte("discard")); err != nil {
+				tst.Fatalf("Tx Write /discard.txt failed: %v", err)
+			}
+			if err := tx.Rollback(ctx); err != nil {
+				tst.Fatalf("Rollback failed: %v", err)
+			}
+			if _, err := tx.WriteFile(ctx, "/discard.txt", 0, []byte("discard")); !errors.Is(err, data.ErrTransactionDone) {
+				tst.Errorf("Expected ErrTransactionDone after rollback, got %v", err)
+			}
+			if _, err := fs.StatMetadata(ctx, "/discard.txt"); !errors.Is(err, data.ErrNotExist) {
+				tst.Errorf("Expected /discard.txt to not exist after rollback, got %v", err)
+			}
+
+			// Changes made outside of the transaction since staging are never overwritten
+			tx, err = fs.Begin(ctx, "/")
+			if err != nil {
+				tst.Fatalf("Begin failed: %v", err)
+			}
+			if _, err := tx.WriteFile(ctx, "/existing.txt", 0, []byte("lost")); err != nil {
+				tst.Fatalf("Tx Write /existing.txt failed: %v", err)
+			}
+			if _, err := fs.WriteFile(ctx, "/existing.txt", 4, []byte("+")); err != nil {
+				tst.Fatalf("Write /existing.txt failed: %v", err)
+			}
+			if err := tx.Commit(ctx); !errors.Is(err, data.ErrTransactionConflict) {
+				tst.Errorf("Expected ErrTransactionConflict for concurrent write, got %v", err)
+			}
+			got, err = fs.ReadFile(ctx, "/existing.txt", 0, 5)
+			if err != nil || string(got) != "oNEW+" {
+				tst.Errorf("Expected concurrent write to be kept, got '%s': %v", got, err)
+			}
+
+			tx, err = fs.Begin(ctx, "/")
+			if err != nil {
+				tst.Fatalf("Begin failed: %v", err)
+			}
+			if _, err := tx.WriteFile(ctx, "/created.txt", 0, []byte("staged")); err != nil {
+				tst.Fatalf("Tx Write /created.txt failed: %v", err)
+			}
+			writeTestFile(tst, fs, "/created.txt", []byte("concurrent"))
+			if err := tx.Commit(ctx); !errors.Is(err, data.ErrTransactionConflict) {
+				tst.Errorf("Expected ErrTransactionConflict for concurrent create, got %v", err)
+			}
+
+			// A failing change reverts all changes applied before
+			tx, err = fs.Begin(ctx, "/")
+			if err != nil {
+				tst.Fatalf("Begin failed: %v", err)
+			}
+			if err := tx.UnlinkFile(ctx, "/existing.txt"); err != nil {
+				tst.Fatalf("Tx Unlink /existing.txt failed: %v", err)
+			}
+			if err := fs.CreateDirectory(ctx, "/parent"); err != nil {
+				tst.Fatalf("MkDir /parent failed: %v", err)
+			}
+			if err := tx.CreateDirectory(ctx, "/parent/child"); err != nil {
+				tst.Fatalf("Tx MkDir /parent/child failed: %v", err)
+			}
+			if err := fs.RemoveDirectory(ctx, "/parent", false); err != nil {
+				tst.Fatalf("RmDir /parent failed: %v", err)
+			}
+			if err := tx.Commit(ctx); err == nil || errors.Is(err, data.ErrTransactionConflict) {
+				tst.Fatalf("Expected commit to fail for missing parent directory, got %v", err)
+			}
+			got, err = fs.ReadFile(ctx, "/existing.txt", 0, 5)
+			if err != nil || string(got) != "oNEW+" {
+				tst.Errorf("Expected /existing.txt to be restored after failed commit, got '%s': %v", got, err)
+			}
+		})
+	}
+}
+
+// TestTransaction_Metadata verifies staged metadata updates, checksums and mount boundaries of transactions.
+func TestTransaction_Metadata(t *testing.T) {
+	ctx := t.Context()
+	fs, err := vfs.NewVirtualFileSystem(vfs.WithLogLevel(log.Error))
+	if err != nil {
+		t.Fatalf("Failed to initialize vfs: %v", err)
+	}
+	defer fs.Shutdown(ctx)
+
+	storage := ephemeral.NewEphemeralBackend()
+	if err := fs.Mount(ctx, "/", storage, mount.WithMetadata(storage), mount.WithChecksum(data.ChecksumSHA256)); err != nil {
+		t.Fatalf("Failed to mount /: %v", err)
+	}
+	if err := fs.Mount(ctx, "/nested", ephemeral.NewEphemeralBackend()); err != nil {
+		t.Fatalf("Failed to mount /nested: %v", err)
+	}
+	writeTestFile(t, fs, "/file.txt", []byte("content"))
+
+	tx, err := fs.Begin(ctx, "/")
+	if err != nil {
+		t.Fatalf("Begin failed: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.WriteFile(ctx, "/nested/file.txt", 0, []byte("outside")); !errors.Is(err, data.ErrCrossMount) {
+		t.Errorf("Expected ErrCrossMount for nested mount, got %v", err)
+	}
+	if err := tx.Chmod(ctx, "/file.txt", 0600); err != nil {
+		t.Fatalf("Tx Chmod failed: %v", err)
+	}
+	if err := tx.SetAttributes(ctx, "/file.txt", map[string]string{"owner": "manifest"}); err != nil {
+		t.Fatalf("Tx SetAttributes failed: %v", err)
+	}
+	content := []byte("manifest")
+	if _, err := tx.WriteFile(ctx, "/manifest.json", 0, content); err != nil {
+		t.Fatalf("Tx Write failed: %v", err)
+	}
+
+	if meta, err := fs.StatMetadata(ctx, "/file.txt"); err != nil || meta.Mode.Perm() == 0600 || meta.HasAttribute("owner") {
+		t.Errorf("Expected metadata to be unchanged before commit: %v", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+
+	meta, err := fs.StatMetadata(ctx, "/file.txt")
+	if err != nil {
+		t.Fatalf("Stat /file.txt failed: %v", err)
+	}
+	if meta.Mode.Perm() != 0600 || meta.Attributes["owner"] != "manifest" {
+		t.Errorf("Expected committed mode 0600 and owner attribute, got %s %v", meta.Mode, meta.Attributes)
+	}
+
+	checksum, err := data.ComputeChecksum(bytes.NewReader(content), data.ChecksumSHA256)
+	if err != nil {
+		t.Fatalf("ComputeChecksum failed: %v", err)
+	}
+	meta, err = fs.StatMetadata(ctx, "/manifest.json")
+	if err != nil {
+		t.Fatalf("Stat /manifest.json failed: %v", err)
+	}
+	if meta.Size != int64(len(content)) || meta.Attributes[data.AttributeChecksum] != checksum.String() {
+		t.Errorf("Expected size %d and checksum %s, got %d %v", len(content), checksum, meta.Size, meta.Attributes)
+	}
+}
+
+// TestTransaction_ApplyChangesConflict verifies that backends reject changes based on outdated state within the same
+// critical section they are applied in, so writes made right before applying are never overwritten.
+func TestTransaction_ApplyChangesConflict(t *testing.T) {
+	ctx := t.Context()
+	path := t.TempDir()
+
+	for _, name := range []string{"ephemeral", "sqlite", "direct"} {
+		t.Run(name, func(tst *testing.T) {
+			var storage backend.ObjectStorageBackend
+			var err error
+			switch name {
+			case "ephemeral":
+				storage = ephemeral.NewEphemeralBackend()
+			case "sqlite":
+				storage, err = sqlite.NewSQLiteBackend(":memory:")
+			case "direct":
+				storage, err = direct.NewDirectBackend(path)
+			}
+			if err != nil {
+				tst.Fatalf("Failed to create backend: %v", err)
+			}
+			if err := storage.Open(ctx); err != nil {
+				tst.Fatalf("Failed to open backend: %v", err)
+			}
+			defer storage.Close(ctx)
+
+			if _, err := storage.CreateObject(ctx, "", "file.txt", 0644); err != nil {
+				tst.Fatalf("CreateObject failed: %v", err)
+			}
+			if _, err := storage.WriteObject(ctx, "", "file.txt", 0, []byte("base")); err != nil {
+				tst.Fatalf("WriteObject failed: %v", err)
+			}
+			stat, err := storage.HeadObject(ctx, "", "file.txt")
+			if err != nil {
+				tst.Fatalf("HeadObject failed: %v", err)
+			}
+			base := &backend.ChangeBase{Metadata: stat.ToMetadata()}
+
+			// Written after staging, but before the changes are applied
+			if _, err := storage.WriteObject(ctx, "", "file.txt", 4, []byte("!!")); err != nil {
+				tst.Fatalf("WriteObject failed: %v", err)
+			}
+
+			changes := []*backend.Change{
+				{Type: backend.ChangeWrite, Key: "file.txt", Content: []byte("lost"), Metadata: stat.ToMetadata(), Base: base},
+				{Type: backend.ChangeMkdir, Key: "dir", Metadata: &data.Metadata{Mode: data.ModeDir | 0755}, Base: &backend.ChangeBase{}},
+			}
+			transactional := storage.(backend.TransactionBackend)
+			if err := transactional.ApplyChanges(ctx, "", changes); !errors.Is(err, data.ErrTransactionConflict) {
+				tst.Fatalf("Expected ErrTransactionConflict, got %v", err)
+			}
+
+			buffer := make([]byte, 6)
+			if n, _ := storage.ReadObject(ctx, "", "file.txt", 0, buffer); string(buffer[:n]) != "base!!" {
+				tst.Errorf("Expected concurrent write to be kept, got %q", buffer[:n])
+			}
+			if _, err := storage.HeadObject(ctx, "", "dir"); err != data.ErrNotExist {
+				tst.Errorf("Expected no change to be applied, got %v", err)
+			}
+
+			// Changes based on the current state are applied
+			stat, err = storage.HeadObject(ctx, "", "file.txt")
+			if err != nil {
+				tst.Fatalf("HeadObject failed: %v", err)
+			}
+			changes[0].Base = &backend.ChangeBase{Metadata: stat.ToMetadata()}
+			if err := transactional.ApplyChanges(ctx, "", changes); err != nil {
+				tst.Fatalf("ApplyChanges failed: %v", err)
+			}
+		})
+	}
+}
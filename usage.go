@@ -0,0 +1,41 @@
+package vfs
+
+import (
+	"context"
+
+	"github.com/mwantia/vfs/data"
+)
+
+// Usage returns the storage used by the mount containing path together with its quotas.
+// Usage is tracked incrementally for mounts with quotas, otherwise it's measured by walking the mount.
+func (vfs *virtualFileSystemImpl) Usage(ctx context.Context, path string) (*data.Usage, error) {
+	return intercept(ctx, vfs, vfs.newOperation(OperationUsage, path), func(ctx context.Context, op *Operation) (*data.Usage, error) {
+		return vfs.usage(ctx, op.Path)
+	})
+}
+
+// usage implements Usage, after the operation passed all interceptors.
+func (vfs *virtualFileSystemImpl) usage(ctx context.Context, path string) (*data.Usage, error) {
+	// Always start with an absolute path
+	absolute, err := vfs.toAbsolutePath(path)
+	if err != nil {
+		vfs.log.Error("Usage: failed to convert path to absolute: %s - %v", path, err)
+		return nil, err
+	}
+
+	vfs.log.Debug("Usage: path=%s", absolute)
+
+	mnt, err := vfs.getMountFromPath(absolute)
+	if err != nil {
+		vfs.log.Error("Usage: no mount found for path: %s - %v", absolute, err)
+		return nil, err
+	}
+
+	usage, err := mnt.Usage(ctx)
+	if err != nil {
+		vfs.log.Error("Usage: failed to determine usage of %s - %v", mnt.Path, err)
+		return nil, err
+	}
+
+	return usage, nil
+}
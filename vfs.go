@@ -2,13 +2,13 @@ package vfs
 
 import (
 	"context"
-	"fmt"
 	"sync"
 
 	"github.com/mwantia/vfs/cmd"
 	"github.com/mwantia/vfs/cmd/builtin"
 	"github.com/mwantia/vfs/data"
 	"github.com/mwantia/vfs/data/errors"
+	"github.com/mwantia/vfs/fstab"
 	"github.com/mwantia/vfs/log"
 	"github.com/mwantia/vfs/mount"
 )
@@ -17,10 +17,21 @@ import (
 // file operations to the appropriate mount handlers. It provides a Unix-like filesystem
 // abstraction with support for nested mounts and thread-safe operations.
 type virtualFileSystemImpl struct {
-	mu   sync.RWMutex
-	log  *log.Logger
-	cmds map[string]cmd.Command
-	mnts map[string]*mount.Mount
+	mu      sync.RWMutex
+	gate    sync.RWMutex // Held by all operations, so backends can be switched while no operation is running
+	log     *log.Logger
+	cmds    map[string]cmd.Command
+	mnts    map[string]*mount.Mount
+	watches *watchHub
+
+	interceptors []Interceptor
+	table        *fstab.Table
+	entries      map[string]*fstab.Entry
+	quotas       map[string]*mount.QuotaCounter // Usage shared by all mounts of a namespace
+	limits       data.PathLimits
+
+	gcCancel context.CancelFunc // Stops the scheduled garbage collection (nil if not scheduled)
+	gcDone   <-chan struct{}
 }
 
 // NewVfs creates a new VirtualFileSystem instance with no initial mounts.
@@ -33,9 +44,20 @@ func NewVirtualFileSystem(opts ...VirtualFileSystemOption) (VirtualFileSystem, e
 	}
 
 	vfs := &virtualFileSystemImpl{
-		log:  log.NewLogger("vfs", options.LogLevel, options.LogFile, options.NoTerminalLog),
-		cmds: make(map[string]cmd.Command),
-		mnts: make(map[string]*mount.Mount),
+		log:     log.NewLogger("vfs", options.LogLevel, options.LogFile, options.NoTerminalLog),
+		cmds:    make(map[string]cmd.Command),
+		mnts:    make(map[string]*mount.Mount),
+		watches: newWatchHub(options.WatchBufferSize),
+
+		interceptors: options.Interceptors,
+		table:        options.MountTable,
+		entries:      make(map[string]*fstab.Entry),
+		quotas:       make(map[string]*mount.QuotaCounter),
+		limits:       options.PathLimits,
+	}
+
+	for namespace, quota := range options.NamespaceQuotas {
+		vfs.quotas[namespace] = mount.NewQuotaCounter(quota)
 	}
 
 	vfs.log.Info("VFS initialized with log level: %s", options.LogLevel)
@@ -44,11 +66,11 @@ func NewVirtualFileSystem(opts ...VirtualFileSystemOption) (VirtualFileSystem, e
 		return nil, err
 	}
 
-	return vfs, nil
-}
+	if options.GCInterval > 0 {
+		vfs.startGarbageCollector(options.GCInterval, options.GCOptions)
+	}
 
-func (vfs *virtualFileSystemImpl) Populate(ctx context.Context) error {
-	return fmt.Errorf("vfs: not implemented")
+	return vfs, nil
 }
 
 // Shutdown unmounts all mounted filesystems and releases all resources.
@@ -57,6 +79,9 @@ func (vfs *virtualFileSystemImpl) Populate(ctx context.Context) error {
 func (vfs *virtualFileSystemImpl) Shutdown(ctx context.Context) error {
 	vfs.log.Info("Shutdown: shutting down VFS, unmounting all mounts")
 
+	// Garbage collection has to be stopped first, since it requires access to the mounts
+	vfs.stopGarbageCollector()
+
 	vfs.mu.Lock()
 	defer vfs.mu.Unlock()
 
@@ -94,9 +119,13 @@ func (vfs *virtualFileSystemImpl) Shutdown(ctx context.Context) error {
 				unmountedCount++
 			}
 			delete(vfs.mnts, path)
+			delete(vfs.entries, path)
 		}
 	}
 
+	// Release all watchers, so their channels get closed
+	vfs.watches.closeAll()
+
 	if failedCount > 0 {
 		vfs.log.Warn("Shutdown: VFS closed with %d successful and %d failed unmounts", unmountedCount, failedCount)
 	} else {
@@ -110,68 +139,100 @@ func (vfs *virtualFileSystemImpl) Shutdown(ctx context.Context) error {
 func (vfs *virtualFileSystemImpl) initBuiltinCommands() error {
 	errs := errors.Errors{}
 
+	errs.Add(vfs.RegisterCommand(&builtin.HelpCommand{}))
 	errs.Add(vfs.RegisterCommand(&builtin.LsCommand{}))
+	errs.Add(vfs.RegisterCommand(&builtin.Sha256sumCommand{}))
+	errs.Add(vfs.RegisterCommand(&builtin.VerifyCommand{}))
+	errs.Add(vfs.RegisterCommand(&builtin.ChmodCommand{}))
+	errs.Add(vfs.RegisterCommand(&builtin.ChownCommand{}))
+	errs.Add(vfs.RegisterCommand(&builtin.GetattrCommand{}))
+	errs.Add(vfs.RegisterCommand(&builtin.SetattrCommand{}))
+	errs.Add(vfs.RegisterCommand(&builtin.TagCommand{}))
+	errs.Add(vfs.RegisterCommand(&builtin.HeadCommand{}))
+	errs.Add(vfs.RegisterCommand(&builtin.TailCommand{}))
+	errs.Add(vfs.RegisterCommand(&builtin.GrepCommand{}))
+	errs.Add(vfs.RegisterCommand(&builtin.EchoCommand{}))
+	errs.Add(vfs.RegisterCommand(&builtin.MkdirCommand{}))
+	errs.Add(vfs.RegisterCommand(&builtin.CpCommand{}))
+	errs.Add(vfs.RegisterCommand(&builtin.SourceCommand{}))
+	errs.Add(vfs.RegisterCommand(&builtin.RunCommand{}))
+	errs.Add(vfs.RegisterCommand(&builtin.MountCommand{}))
+	errs.Add(vfs.RegisterCommand(&builtin.UmountCommand{}))
+	errs.Add(vfs.RegisterCommand(&builtin.DfCommand{}))
+	errs.Add(vfs.RegisterCommand(&builtin.GcCommand{}))
+	errs.Add(vfs.RegisterCommand(&builtin.FsckCommand{}))
+	errs.Add(vfs.RegisterCommand(&builtin.MigrateCommand{}))
+	errs.Add(vfs.RegisterCommand(&builtin.TierCommand{}))
 
 	return errs.Errors()
 }
 
-// renameFile performs a copy-and-delete rename for a single file.
-func (vfs *virtualFileSystemImpl) renameFile(ctx context.Context, oldPath string, newPath string, oldStat *data.Metadata) error {
-	// Handle empty files specially
-	if oldStat.Size == 0 {
-		vfs.log.Debug("renameFile: creating empty file at %s", newPath)
-		// Just create an empty file at the destination
-		_, err := vfs.OpenFile(ctx, newPath, data.AccessModeCreate|data.AccessModeWrite)
-		if err != nil {
-			vfs.log.Error("renameFile: failed to create destination file %s - %v", newPath, err)
-			return err
-		}
-		if err := vfs.CloseFile(ctx, newPath, false); err != nil {
-			vfs.log.Error("renameFile: failed to close destination file %s - %v", newPath, err)
-			return err
-		}
-	} else {
-		// Read entire file content
-		vfs.log.Debug("renameFile: reading %d bytes from %s", oldStat.Size, oldPath)
-		contents, err := vfs.ReadFile(ctx, oldPath, 0, oldStat.Size)
-		if err != nil {
-			vfs.log.Error("renameFile: failed to read source file %s - %v", oldPath, err)
-			return err
-		}
+// renameNative pushes the rename down to the backends, if both paths share the same mount.
+// Returns false if the rename has to fall back to copy-and-delete.
+func (vfs *virtualFileSystemImpl) renameNative(ctx context.Context, oldPath string, newPath string) (bool, error) {
+	oldMnt, err := vfs.getMountFromPath(oldPath)
+	if err != nil {
+		return false, err
+	}
+	newMnt, err := vfs.getMountFromPath(newPath)
+	if err != nil {
+		return false, err
+	}
 
-		// Create destination file
-		vfs.log.Debug("renameFile: creating destination file %s", newPath)
-		_, err = vfs.OpenFile(ctx, newPath, data.AccessModeCreate|data.AccessModeWrite)
-		if err != nil {
-			vfs.log.Error("renameFile: failed to create destination file %s - %v", newPath, err)
-			return err
-		}
+	if oldMnt != newMnt || !oldMnt.CanRename() {
+		return false, nil
+	}
 
-		// Write data to destination
-		vfs.log.Debug("renameFile: writing %d bytes to %s", len(contents), newPath)
-		n, err := vfs.WriteFile(ctx, newPath, 0, contents)
-		if err != nil {
-			vfs.log.Error("renameFile: failed to write to destination file %s - %v", newPath, err)
-			// Clean up partial file
-			vfs.CloseFile(ctx, newPath, true)
-			vfs.UnlinkFile(ctx, newPath)
-			return err
-		}
+	// Nested mounts would be moved along with their parent directory
+	vfs.mu.RLock()
+	nested := vfs.hasChildMounts(oldPath)
+	vfs.mu.RUnlock()
 
-		vfs.log.Debug("renameFile: wrote %d bytes to %s", n, newPath)
+	if nested {
+		vfs.log.Debug("renameNative: %s contains nested mounts, falling back to copy-and-delete", oldPath)
+		return false, nil
+	}
 
-		// Close destination file
-		if err := vfs.CloseFile(ctx, newPath, false); err != nil {
-			vfs.log.Error("renameFile: failed to close destination file %s - %v", newPath, err)
-			return err
+	if oldMnt.Options.IsReadOnly {
+		vfs.log.Error("renameNative: cannot rename on read-only mount at %s", oldMnt.Path)
+		return false, data.ErrReadOnly
+	}
+
+	oldKey := vfs.getPrefixRelativePath(oldMnt, oldPath)
+	newKey := vfs.getPrefixRelativePath(oldMnt, newPath)
+
+	vfs.log.Debug("renameNative: renaming %s to %s on mount %s", oldKey, newKey, oldMnt.Path)
+	if err := oldMnt.Rename(ctx, oldKey, newKey); err != nil {
+		if err == data.ErrNotSupported {
+			return false, nil
 		}
+		vfs.log.Error("renameNative: failed to rename %s to %s - %v", oldPath, newPath, err)
+		return false, err
+	}
+
+	vfs.log.Info("renameNative: successfully renamed %s to %s", oldPath, newPath)
+	return true, nil
+}
+
+// renameFile performs a streamed copy-and-delete rename for a single file.
+// The destination is removed again, if the source couldn't be fully copied or deleted.
+func (vfs *virtualFileSystemImpl) renameFile(ctx context.Context, oldPath string, newPath string, oldStat *data.Metadata) error {
+	vfs.log.Debug("renameFile: copying %d bytes from %s to %s", oldStat.Size, oldPath, newPath)
+	if err := vfs.copyContent(ctx, oldPath, newPath, 0, oldStat.Size, &data.CopyOptions{}); err != nil {
+		vfs.log.Error("renameFile: failed to copy %s to %s - %v", oldPath, newPath, err)
+		// Clean up partial file
+		vfs.UnlinkFile(ctx, newPath)
+		return err
 	}
 
 	// Delete source file
 	vfs.log.Debug("renameFile: deleting source file %s", oldPath)
 	if err := vfs.UnlinkFile(ctx, oldPath); err != nil {
 		vfs.log.Error("renameFile: failed to delete source file %s - %v", oldPath, err)
-		// Note: destination file exists, but source couldn't be deleted - partial state
+		// Remove the copy, so the source stays the only version
+		if err := vfs.UnlinkFile(ctx, newPath); err != nil {
+			vfs.log.Error("renameFile: failed to roll back destination file %s - %v", newPath, err)
+		}
 		return err
 	}
 
@@ -180,6 +241,7 @@ func (vfs *virtualFileSystemImpl) renameFile(ctx context.Context, oldPath string
 }
 
 // renameDirectory performs a recursive copy-and-delete rename for a directory.
+// If any entry fails, all entries moved so far are moved back and the destination is removed.
 func (vfs *virtualFileSystemImpl) renameDirectory(ctx context.Context, oldPath string, newPath string) error {
 	// Create the destination directory
 	vfs.log.Debug("renameDirectory: creating destination directory %s", newPath)
@@ -193,12 +255,14 @@ func (vfs *virtualFileSystemImpl) renameDirectory(ctx context.Context, oldPath s
 	entries, err := vfs.ReadDirectory(ctx, oldPath)
 	if err != nil {
 		vfs.log.Error("renameDirectory: failed to read source directory %s - %v", oldPath, err)
+		vfs.rollbackRenameDirectory(ctx, oldPath, newPath, nil)
 		return err
 	}
 
 	vfs.log.Debug("renameDirectory: found %d entries in %s", len(entries), oldPath)
 
 	// Recursively rename each entry
+	moved := make([]string, 0, len(entries))
 	for _, entry := range entries {
 		// Skip mount points
 		if entry.Mode&data.ModeMount != 0 {
@@ -214,17 +278,34 @@ func (vfs *virtualFileSystemImpl) renameDirectory(ctx context.Context, oldPath s
 		// Recursively rename subdirectories and files
 		if err := vfs.Rename(ctx, oldEntryPath, newEntryPath); err != nil {
 			vfs.log.Error("renameDirectory: failed to rename entry %s - %v", entry.Key, err)
+			vfs.rollbackRenameDirectory(ctx, oldPath, newPath, moved)
 			return err
 		}
+
+		moved = append(moved, entry.Key)
 	}
 
 	// Remove the empty source directory
 	vfs.log.Debug("renameDirectory: removing source directory %s", oldPath)
 	if err := vfs.RemoveDirectory(ctx, oldPath, false); err != nil {
 		vfs.log.Error("renameDirectory: failed to remove source directory %s - %v", oldPath, err)
+		vfs.rollbackRenameDirectory(ctx, oldPath, newPath, moved)
 		return err
 	}
 
 	vfs.log.Info("renameDirectory: successfully renamed directory %s to %s", oldPath, newPath)
 	return nil
 }
+
+// rollbackRenameDirectory moves all already moved entries back in reverse order and removes the destination.
+func (vfs *virtualFileSystemImpl) rollbackRenameDirectory(ctx context.Context, oldPath string, newPath string, moved []string) {
+	for i := len(moved) - 1; i >= 0; i-- {
+		if err := vfs.Rename(ctx, newPath+"/"+moved[i], oldPath+"/"+moved[i]); err != nil {
+			vfs.log.Error("renameDirectory: failed to roll back entry %s - %v", moved[i], err)
+		}
+	}
+
+	if err := vfs.RemoveDirectory(ctx, newPath, false); err != nil {
+		vfs.log.Error("renameDirectory: failed to remove destination directory %s - %v", newPath, err)
+	}
+}
@@ -536,3 +536,128 @@ func TestAllMounts_EmptyDirectoryOperations(t *testing.T) {
 		})
 	}
 }
+
+// TestAllMounts_RenameOperations verifies same-mount renames of files and directories across all backend implementations.
+func TestAllMounts_RenameOperations(t *testing.T) {
+	factories := GetTestMountFactories()
+
+	for name, factory := range factories {
+		t.Run(name, func(tst *testing.T) {
+			ctx := tst.Context()
+			fs, err := vfs.NewVirtualFileSystem(vfs.WithLogLevel(log.Debug))
+			if err != nil {
+				tst.Fatalf("Failed to initialize vfs: %v", err)
+			}
+
+			if err := factory(tst, fs); err != nil {
+				tst.Fatalf("Failed to mount: %v", err)
+			}
+			defer fs.Unmount(ctx, "/", false)
+
+			if err := fs.CreateDirectory(ctx, "/src"); err != nil {
+				tst.Fatalf("MkDir /src failed: %v", err)
+			}
+			if err := fs.CreateDirectory(ctx, "/src/sub"); err != nil {
+				tst.Fatalf("MkDir /src/sub failed: %v", err)
+			}
+
+			for path, content := range map[string]string{"/src/a.txt": "alpha", "/src/sub/b.txt": "beta", "/single.txt": "single"} {
+				streamer, err := fs.OpenFile(ctx, path, data.AccessModeWrite|data.AccessModeCreate)
+				if err != nil {
+					tst.Fatalf("Open %s failed: %v", path, err)
+				}
+				streamer.Write([]byte(content))
+				streamer.Close()
+			}
+
+			if err := fs.Rename(ctx, "/single.txt", "/renamed.txt"); err != nil {
+				tst.Fatalf("Rename file failed: %v", err)
+			}
+			if err := fs.Rename(ctx, "/src", "/dst"); err != nil {
+				tst.Fatalf("Rename directory failed: %v", err)
+			}
+
+			for _, path := range []string{"/single.txt", "/src", "/src/a.txt", "/src/sub/b.txt"} {
+				if _, err := fs.StatMetadata(ctx, path); err != data.ErrNotExist {
+					tst.Errorf("Expected ErrNotExist for %s after rename, got %v", path, err)
+				}
+			}
+
+			for path, expected := range map[string]string{"/renamed.txt": "single", "/dst/a.txt": "alpha", "/dst/sub/b.txt": "beta"} {
+				got, err := fs.ReadFile(ctx, path, 0, int64(len(expected)))
+				if err != nil {
+					tst.Errorf("Read %s failed: %v", path, err)
+					continue
+				}
+				if string(got) != expected {
+					tst.Errorf("Expected %q in %s, got %q", expected, path, got)
+				}
+			}
+
+			entries, err := fs.ReadDirectory(ctx, "/dst")
+			if err != nil {
+				tst.Fatalf("ReadDirectory /dst failed: %v", err)
+			}
+			if len(entries) != 2 {
+				tst.Errorf("Expected 2 entries in /dst, got %d", len(entries))
+			}
+
+			if err := fs.Rename(ctx, "/renamed.txt", "/dst/a.txt"); err != data.ErrExist {
+				tst.Errorf("Expected ErrExist for existing destination, got %v", err)
+			}
+			if err := fs.Rename(ctx, "/dst", "/dst/sub/inner"); err != data.ErrInvalid {
+				tst.Errorf("Expected ErrInvalid for rename into own subtree, got %v", err)
+			}
+		})
+	}
+}
+
+// TestRename_AcrossMounts verifies the streamed copy-and-delete fallback between different mounts.
+func TestRename_AcrossMounts(t *testing.T) {
+	ctx := t.Context()
+	fs, err := vfs.NewVirtualFileSystem(vfs.WithLogLevel(log.Error))
+	if err != nil {
+		t.Fatalf("Failed to initialize vfs: %v", err)
+	}
+	defer fs.Shutdown(ctx)
+
+	if err := fs.Mount(ctx, "/", ephemeral.NewEphemeralBackend()); err != nil {
+		t.Fatalf("Failed to mount /: %v", err)
+	}
+	storage, err := direct.NewDirectBackend(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create direct backend: %v", err)
+	}
+	if err := fs.Mount(ctx, "/ext", storage); err != nil {
+		t.Fatalf("Failed to mount /ext: %v", err)
+	}
+
+	// Larger than a single copy chunk, so the content is streamed in multiple parts
+	content := bytes.Repeat([]byte("0123456789abcdef"), 128*1024)
+
+	if err := fs.CreateDirectory(ctx, "/dir"); err != nil {
+		t.Fatalf("MkDir /dir failed: %v", err)
+	}
+	streamer, err := fs.OpenFile(ctx, "/dir/large.bin", data.AccessModeWrite|data.AccessModeCreate)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	streamer.Write(content)
+	streamer.Close()
+
+	if err := fs.Rename(ctx, "/dir", "/ext/dir"); err != nil {
+		t.Fatalf("Rename across mounts failed: %v", err)
+	}
+
+	if _, err := fs.StatMetadata(ctx, "/dir"); err != data.ErrNotExist {
+		t.Errorf("Expected ErrNotExist for /dir after rename, got %v", err)
+	}
+
+	got, err := fs.ReadFile(ctx, "/ext/dir/large.bin", 0, int64(len(content)))
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if !bytes.Equal(got, content) {
+		t.Errorf("Expected %d bytes to be copied unchanged, got %d", len(content), len(got))
+	}
+}
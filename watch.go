@@ -0,0 +1,197 @@
+package vfs
+
+import (
+	"context"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/mwantia/vfs/data"
+)
+
+// Watch returns a channel receiving all events for path, matching the filter (0 matches all events).
+// Without recursive, only events for path itself and its direct children are reported.
+// The channel is closed once ctx has been cancelled or the VFS has been shut down.
+func (vfs *virtualFileSystemImpl) Watch(ctx context.Context, path string, recursive bool, filter data.EventType) (<-chan data.Event, error) {
+	return intercept(ctx, vfs, vfs.newOperation(OperationWatch, path), func(ctx context.Context, op *Operation) (<-chan data.Event, error) {
+		return vfs.watch(ctx, op.Path, recursive, filter)
+	})
+}
+
+// watch implements Watch, after the operation passed all interceptors.
+func (vfs *virtualFileSystemImpl) watch(ctx context.Context, path string, recursive bool, filter data.EventType) (<-chan data.Event, error) {
+	// Always start with an absolute path
+	absolute, err := vfs.toAbsolutePath(path)
+	if err != nil {
+		vfs.log.Error("Watch: failed to convert path to absolute: %s - %v", path, err)
+		return nil, err
+	}
+
+	vfs.log.Debug("Watch: path=%s recursive=%v filter=%s", absolute, recursive, filter)
+
+	w := vfs.watches.subscribe(absolute, recursive, filter)
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-w.done:
+		}
+		vfs.watches.unsubscribe(w)
+		vfs.log.Debug("Watch: stopped watching %s", absolute)
+	}()
+
+	vfs.log.Info("Watch: started watching %s", absolute)
+	return w.events, nil
+}
+
+// watchHub distributes published events to all matching watchers.
+type watchHub struct {
+	mu         sync.RWMutex
+	bufferSize int
+	watchers   map[*watcher]struct{}
+}
+
+// watcher holds the buffered event channel for a single subscription.
+// Events are never blocking the publisher: if the buffer is full, events are dropped
+// and reported with an overflow event as soon as the buffer has space again.
+type watcher struct {
+	mu        sync.Mutex
+	path      string
+	recursive bool
+	filter    data.EventType
+	events    chan data.Event
+	done      chan struct{}
+	dropped   uint64
+	closed    bool
+}
+
+func newWatchHub(bufferSize int) *watchHub {
+	return &watchHub{
+		bufferSize: bufferSize,
+		watchers:   make(map[*watcher]struct{}),
+	}
+}
+
+// subscribe registers a new watcher for watchPath.
+func (wh *watchHub) subscribe(watchPath string, recursive bool, filter data.EventType) *watcher {
+	w := &watcher{
+		path:      path.Clean(watchPath),
+		recursive: recursive,
+		filter:    filter,
+		events:    make(chan data.Event, wh.bufferSize),
+		done:      make(chan struct{}),
+	}
+
+	wh.mu.Lock()
+	defer wh.mu.Unlock()
+
+	wh.watchers[w] = struct{}{}
+	return w
+}
+
+// unsubscribe removes the watcher and closes its channel.
+func (wh *watchHub) unsubscribe(w *watcher) {
+	wh.mu.Lock()
+	delete(wh.watchers, w)
+	wh.mu.Unlock()
+
+	w.close()
+}
+
+// closeAll removes all watchers and closes their channels.
+func (wh *watchHub) closeAll() {
+	wh.mu.Lock()
+	watchers := wh.watchers
+	wh.watchers = make(map[*watcher]struct{})
+	wh.mu.Unlock()
+
+	for w := range watchers {
+		w.close()
+	}
+}
+
+// publish delivers the event to every matching watcher without blocking.
+func (wh *watchHub) publish(event data.Event) {
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+
+	wh.mu.RLock()
+	defer wh.mu.RUnlock()
+
+	for w := range wh.watchers {
+		if w.matches(event) {
+			w.send(event)
+		}
+	}
+}
+
+// matches checks if the event is relevant for this watcher.
+func (w *watcher) matches(event data.Event) bool {
+	// Overflows are always reported, since watchers can't know which events got lost
+	if event.Type == data.EventOverflow {
+		return true
+	}
+
+	if !event.Type.Matches(w.filter) {
+		return false
+	}
+
+	return w.matchesPath(event.Path) || (event.OldPath != "" && w.matchesPath(event.OldPath))
+}
+
+// matchesPath checks if p is the watched path, a direct child or any descendant (if recursive).
+func (w *watcher) matchesPath(p string) bool {
+	if p == w.path {
+		return true
+	}
+
+	if w.recursive {
+		return w.path == "/" || data.HasPrefix(p, w.path+"/")
+	}
+
+	return path.Dir(p) == w.path
+}
+
+// send queues the event, dropping it if the buffer is full.
+func (w *watcher) send(event data.Event) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return
+	}
+
+	if w.dropped > 0 {
+		overflow := data.Event{
+			Type:    data.EventOverflow,
+			Time:    time.Now(),
+			Dropped: w.dropped,
+		}
+
+		select {
+		case w.events <- overflow:
+			w.dropped = 0
+		default:
+			w.dropped++
+			return
+		}
+	}
+
+	select {
+	case w.events <- event:
+	default:
+		w.dropped++
+	}
+}
+
+// close closes the event channel once.
+func (w *watcher) close() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if !w.closed {
+		w.closed = true
+		close(w.events)
+		close(w.done)
+	}
+}
@@ -0,0 +1,332 @@
+package vfs_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/mwantia/vfs"
+	"github.com/mwantia/vfs/data"
+	"github.com/mwantia/vfs/log"
+	"github.com/mwantia/vfs/mount"
+	"github.com/mwantia/vfs/mount/backend/direct"
+	"github.com/mwantia/vfs/mount/backend/ephemeral"
+)
+
+// expectEvent waits for the next event and validates its type and path.
+func expectEvent(t *testing.T, events <-chan data.Event, eventType data.EventType, path string) data.Event {
+	t.Helper()
+
+	select {
+	case event, ok := <-events:
+		if !ok {
+			t.Fatalf("Expected %s %s, but channel has been closed", eventType, path)
+		}
+		if event.Type != eventType || event.Path != path {
+			t.Fatalf("Expected %s %s, got %s", eventType, path, event)
+		}
+		return event
+	case <-time.After(2 * time.Second):
+		t.Fatalf("Timed out waiting for %s %s", eventType, path)
+	}
+
+	return data.Event{}
+}
+
+// expectNoEvent validates that no event is pending.
+func expectNoEvent(t *testing.T, events <-chan data.Event) {
+	t.Helper()
+
+	select {
+	case event := <-events:
+		t.Fatalf("Expected no event, got %s", event)
+	default:
+	}
+}
+
+func TestWatch_Operations(t *testing.T) {
+	ctx := t.Context()
+	fs, err := vfs.NewVirtualFileSystem(vfs.WithLogLevel(log.Error))
+	if err != nil {
+		t.Fatalf("Failed to initialize vfs: %v", err)
+	}
+
+	storage := ephemeral.NewEphemeralBackend()
+	if err := fs.Mount(ctx, "/", storage, mount.WithMetadata(storage)); err != nil {
+		t.Fatalf("Failed to mount: %v", err)
+	}
+	defer fs.Unmount(ctx, "/", false)
+
+	events, err := fs.Watch(ctx, "/", true, 0)
+	if err != nil {
+		t.Fatalf("Watch failed: %v", err)
+	}
+
+	if err := fs.CreateDirectory(ctx, "/data"); err != nil {
+		t.Fatalf("CreateDirectory failed: %v", err)
+	}
+	expectEvent(t, events, data.EventMkdir, "/data")
+
+	streamer, err := fs.OpenFile(ctx, "/data/file.txt", data.AccessModeWrite|data.AccessModeCreate|data.AccessModeTrunc)
+	if err != nil {
+		t.Fatalf("OpenFile failed: %v", err)
+	}
+	expectEvent(t, events, data.EventCreate, "/data/file.txt")
+	expectEvent(t, events, data.EventTruncate, "/data/file.txt")
+
+	if _, err := streamer.Write([]byte("hello")); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	expectEvent(t, events, data.EventWrite, "/data/file.txt")
+
+	if err := streamer.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	expectEvent(t, events, data.EventCloseWrite, "/data/file.txt")
+
+	if _, err := fs.WriteFile(ctx, "/data/file.txt", 5, []byte(" world")); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	expectEvent(t, events, data.EventWrite, "/data/file.txt")
+
+	if err := fs.Chmod(ctx, "/data/file.txt", 0600); err != nil {
+		t.Fatalf("Chmod failed: %v", err)
+	}
+	expectEvent(t, events, data.EventAttrib, "/data/file.txt")
+
+	if err := fs.UnlinkFile(ctx, "/data/file.txt"); err != nil {
+		t.Fatalf("UnlinkFile failed: %v", err)
+	}
+	expectEvent(t, events, data.EventUnlink, "/data/file.txt")
+
+	if err := fs.RemoveDirectory(ctx, "/data", false); err != nil {
+		t.Fatalf("RemoveDirectory failed: %v", err)
+	}
+	expectEvent(t, events, data.EventRmdir, "/data")
+	expectNoEvent(t, events)
+}
+
+func TestWatch_FilterAndPaths(t *testing.T) {
+	ctx := t.Context()
+	fs, err := vfs.NewVirtualFileSystem(vfs.WithLogLevel(log.Error))
+	if err != nil {
+		t.Fatalf("Failed to initialize vfs: %v", err)
+	}
+
+	storage := ephemeral.NewEphemeralBackend()
+	if err := fs.Mount(ctx, "/", storage, mount.WithMetadata(storage)); err != nil {
+		t.Fatalf("Failed to mount: %v", err)
+	}
+	defer fs.Unmount(ctx, "/", false)
+
+	for _, dir := range []string{"/a", "/a/b", "/c"} {
+		if err := fs.CreateDirectory(ctx, dir); err != nil {
+			t.Fatalf("CreateDirectory failed: %v", err)
+		}
+	}
+
+	direct, err := fs.Watch(ctx, "/a", false, 0)
+	if err != nil {
+		t.Fatalf("Watch failed: %v", err)
+	}
+	renames, err := fs.Watch(ctx, "/", true, data.EventRename|data.EventMount|data.EventUnmount)
+	if err != nil {
+		t.Fatalf("Watch failed: %v", err)
+	}
+
+	// Nested directories are not reported without recursive watch
+	if err := fs.CreateDirectory(ctx, "/a/b/deep"); err != nil {
+		t.Fatalf("CreateDirectory failed: %v", err)
+	}
+	if err := fs.CreateDirectory(ctx, "/a/child"); err != nil {
+		t.Fatalf("CreateDirectory failed: %v", err)
+	}
+	expectEvent(t, direct, data.EventMkdir, "/a/child")
+
+	streamer, err := fs.OpenFile(ctx, "/c/file.txt", data.AccessModeWrite|data.AccessModeCreate)
+	if err != nil {
+		t.Fatalf("OpenFile failed: %v", err)
+	}
+	if _, err := streamer.Write([]byte("content")); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if err := streamer.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	// Renames are reported for both the old and the new location
+	if err := fs.Rename(ctx, "/c/file.txt", "/a/file.txt"); err != nil {
+		t.Fatalf("Rename failed: %v", err)
+	}
+	event := expectEvent(t, renames, data.EventRename, "/a/file.txt")
+	if event.OldPath != "/c/file.txt" {
+		t.Errorf("Expected old path /c/file.txt, got %s", event.OldPath)
+	}
+
+	for {
+		event := <-direct
+		if event.Type == data.EventRename {
+			if event.Path != "/a/file.txt" || event.OldPath != "/c/file.txt" {
+				t.Errorf("Unexpected rename event %s", event)
+			}
+			break
+		}
+		if event.Path != "/a/file.txt" {
+			t.Fatalf("Unexpected event %s", event)
+		}
+	}
+	expectNoEvent(t, direct)
+
+	nested := ephemeral.NewEphemeralBackend()
+	if err := fs.Mount(ctx, "/c/nested", nested); err != nil {
+		t.Fatalf("Failed to mount: %v", err)
+	}
+	expectEvent(t, renames, data.EventMount, "/c/nested")
+
+	if err := fs.Unmount(ctx, "/c/nested", false); err != nil {
+		t.Fatalf("Failed to unmount: %v", err)
+	}
+	expectEvent(t, renames, data.EventUnmount, "/c/nested")
+	expectNoEvent(t, renames)
+}
+
+func TestWatch_OverflowAndCancel(t *testing.T) {
+	ctx := t.Context()
+	fs, err := vfs.NewVirtualFileSystem(vfs.WithLogLevel(log.Error), vfs.WithWatchBufferSize(2))
+	if err != nil {
+		t.Fatalf("Failed to initialize vfs: %v", err)
+	}
+
+	storage := ephemeral.NewEphemeralBackend()
+	if err := fs.Mount(ctx, "/", storage, mount.WithMetadata(storage)); err != nil {
+		t.Fatalf("Failed to mount: %v", err)
+	}
+	defer fs.Unmount(ctx, "/", false)
+
+	watchCtx, cancel := context.WithCancel(ctx)
+	events, err := fs.Watch(watchCtx, "/", true, data.EventMkdir)
+	if err != nil {
+		t.Fatalf("Watch failed: %v", err)
+	}
+
+	for _, dir := range []string{"/1", "/2", "/3", "/4", "/5"} {
+		if err := fs.CreateDirectory(ctx, dir); err != nil {
+			t.Fatalf("CreateDirectory failed: %v", err)
+		}
+	}
+
+	expectEvent(t, events, data.EventMkdir, "/1")
+	expectEvent(t, events, data.EventMkdir, "/2")
+	expectNoEvent(t, events)
+
+	if err := fs.CreateDirectory(ctx, "/6"); err != nil {
+		t.Fatalf("CreateDirectory failed: %v", err)
+	}
+
+	overflow := expectEvent(t, events, data.EventOverflow, "")
+	if overflow.Dropped != 3 {
+		t.Errorf("Expected 3 dropped events, got %d", overflow.Dropped)
+	}
+	expectEvent(t, events, data.EventMkdir, "/6")
+
+	cancel()
+	select {
+	case _, ok := <-events:
+		if ok {
+			t.Fatalf("Expected channel to be closed after cancel")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("Timed out waiting for channel to be closed")
+	}
+}
+
+func TestWatch_DirectBackend(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("direct backend changes are only observed on linux")
+	}
+
+	ctx := t.Context()
+	fs, err := vfs.NewVirtualFileSystem(vfs.WithLogLevel(log.Error))
+	if err != nil {
+		t.Fatalf("Failed to initialize vfs: %v", err)
+	}
+
+	root := t.TempDir()
+	storage, err := direct.NewDirectBackend(root)
+	if err != nil {
+		t.Fatalf("Failed to create backend: %v", err)
+	}
+	if err := fs.Mount(ctx, "/ext", storage, mount.WithBackendWatch()); err != nil {
+		t.Fatalf("Failed to mount: %v", err)
+	}
+	defer fs.Unmount(ctx, "/ext", true)
+
+	events, err := fs.Watch(ctx, "/ext", true, data.EventCreate|data.EventMkdir|data.EventRename|data.EventUnlink)
+	if err != nil {
+		t.Fatalf("Watch failed: %v", err)
+	}
+
+	if err := os.Mkdir(filepath.Join(root, "sub"), 0755); err != nil {
+		t.Fatalf("Mkdir failed: %v", err)
+	}
+	event := expectEvent(t, events, data.EventMkdir, "/ext/sub")
+	if !event.External || event.Mount != "/ext" {
+		t.Errorf("Expected external event for mount /ext, got %+v", event)
+	}
+
+	// Directories created externally are watched as well
+	if err := os.WriteFile(filepath.Join(root, "sub", "file.txt"), []byte("external"), 0644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	expectEvent(t, events, data.EventCreate, "/ext/sub/file.txt")
+
+	if err := os.Rename(filepath.Join(root, "sub", "file.txt"), filepath.Join(root, "moved.txt")); err != nil {
+		t.Fatalf("Rename failed: %v", err)
+	}
+	event = expectEvent(t, events, data.EventRename, "/ext/moved.txt")
+	if event.OldPath != "/ext/sub/file.txt" {
+		t.Errorf("Expected old path /ext/sub/file.txt, got %s", event.OldPath)
+	}
+
+	if err := os.Remove(filepath.Join(root, "moved.txt")); err != nil {
+		t.Fatalf("Remove failed: %v", err)
+	}
+	expectEvent(t, events, data.EventUnlink, "/ext/moved.txt")
+}
+
+// TestWatch_DirectBackendDefault verifies that operations of the VFS are reported once, unless the backend is watched.
+func TestWatch_DirectBackendDefault(t *testing.T) {
+	ctx := t.Context()
+	fs, err := vfs.NewVirtualFileSystem(vfs.WithLogLevel(log.Error))
+	if err != nil {
+		t.Fatalf("Failed to initialize vfs: %v", err)
+	}
+
+	storage, err := direct.NewDirectBackend(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create backend: %v", err)
+	}
+	if err := fs.Mount(ctx, "/ext", storage); err != nil {
+		t.Fatalf("Failed to mount: %v", err)
+	}
+	defer fs.Unmount(ctx, "/ext", true)
+
+	events, err := fs.Watch(ctx, "/ext", true, data.EventMkdir)
+	if err != nil {
+		t.Fatalf("Watch failed: %v", err)
+	}
+
+	if err := fs.CreateDirectory(ctx, "/ext/sub"); err != nil {
+		t.Fatalf("CreateDirectory failed: %v", err)
+	}
+	if event := expectEvent(t, events, data.EventMkdir, "/ext/sub"); event.External {
+		t.Errorf("Expected internal event, got %+v", event)
+	}
+
+	// External events would be delivered shortly after the operation
+	time.Sleep(100 * time.Millisecond)
+	expectNoEvent(t, events)
+}